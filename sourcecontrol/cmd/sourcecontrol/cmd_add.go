@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/utkarsh5026/SourceControl/cmd/ui"
+	"github.com/utkarsh5026/SourceControl/pkg/index"
+	"github.com/utkarsh5026/SourceControl/pkg/patch"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/sourcerepo"
+	"github.com/utkarsh5026/SourceControl/pkg/store"
+)
+
+func newAddCmd() *cobra.Command {
+	var opts index.AddOptions
+	var interactive bool
+
+	cmd := &cobra.Command{
+		Use:   "add [pathspec...]",
+		Short: "Add file contents to the staging area",
+		Long: `Add file contents to the staging area (index).
+This stages changes for the next commit.
+
+A pathspec may be a file, a directory (added recursively) or a glob such
+as '*.go'. Ignored files are skipped unless --force is given.`,
+		Example: `  # Stage everything in the current directory
+  srcc add .
+
+  # Stage all Go files
+  srcc add '*.go'
+
+  # Stage modifications and deletions of tracked files only
+  srcc add -u
+
+  # Choose which hunks of main.go to stage
+  srcc add -p main.go
+
+  # The same, answering from a script
+  printf 'y\nn\n' | srcc add -p main.go`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := findRepository()
+			if err != nil {
+				return err
+			}
+
+			if interactive {
+				if opts.All || opts.Update || opts.Force || opts.DryRun {
+					return fmt.Errorf("--patch cannot be combined with --all, --update, --force or --dry-run")
+				}
+				return runAddPatch(repo, args)
+			}
+
+			repoRoot := repo.WorkingDirectory()
+			indexMgr := index.NewManager(repoRoot)
+			if err := indexMgr.Initialize(); err != nil {
+				return fmt.Errorf("failed to initialize index: %w", err)
+			}
+
+			objectStore := store.NewFileObjectStore()
+			objectStore.Initialize(repo.WorkingDirectory())
+
+			result, err := indexMgr.AddWithOptions(context.Background(), args, objectStore, opts)
+			if err != nil {
+				return fmt.Errorf("failed to add files: %w", err)
+			}
+
+			if opts.DryRun {
+				for _, path := range append(result.Added, result.Modified...) {
+					fmt.Printf("add '%s'\n", path)
+				}
+				for _, path := range result.Removed {
+					fmt.Printf("remove '%s'\n", path)
+				}
+			} else {
+				for _, path := range result.Added {
+					fmt.Printf("%s %s\n", ui.Green("added:"), path)
+				}
+				for _, path := range result.Modified {
+					fmt.Printf("%s %s\n", ui.Yellow("modified:"), path)
+				}
+				for _, path := range result.Removed {
+					fmt.Printf("%s %s\n", ui.Red("removed:"), path)
+				}
+			}
+			for _, failure := range result.Failed {
+				fmt.Printf("%s %s: %s\n", ui.Red("failed:"), failure.Path, failure.Reason)
+			}
+
+			if len(result.Ignored) > 0 {
+				fmt.Println("The following paths are ignored by one of your ignore files:")
+				for _, path := range result.Ignored {
+					fmt.Println(path)
+				}
+				fmt.Println(ui.Yellow("hint: Use -f if you really want to add them."))
+			}
+			if len(result.Ignored) > 0 || len(result.Failed) > 0 {
+				return fmt.Errorf("some paths could not be added")
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&opts.All, "all", "A", false, "Stage all changes, including new files and deletions")
+	cmd.Flags().BoolVarP(&opts.Update, "update", "u", false, "Stage modifications and deletions of tracked files only")
+	cmd.Flags().BoolVarP(&opts.Force, "force", "f", false, "Allow adding otherwise ignored files")
+	cmd.Flags().BoolVarP(&interactive, "patch", "p", false, "Interactively choose hunks of tracked files to stage")
+	cmd.Flags().BoolVarP(&opts.DryRun, "dry-run", "n", false, "Show what would be added without adding it")
+
+	return cmd
+}
+
+// runAddPatch stages hunks chosen interactively, as add -p does.
+func runAddPatch(repo *sourcerepo.SourceRepository, pathspecs []string) error {
+	opts, err := patchOptions(repo, pathspecs)
+	if err != nil {
+		return err
+	}
+
+	var result *patch.Result
+	indexPath := repo.SourceDirectory().IndexPath().ToAbsolutePath()
+	err = index.Update(indexPath, func(idx *index.Index) error {
+		result, err = patch.Add(idx, repo.WorkingDirectory(), repo.ObjectStore(), opts)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if result.Offered == 0 {
+		fmt.Println("No changes.")
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/plumbing"
+	"github.com/utkarsh5026/SourceControl/pkg/store"
+)
+
+func newCatFileCmd() *cobra.Command {
+	var showType bool
+	var showSize bool
+	var exists bool
+	var pretty bool
+	var batch bool
+	var batchCheck bool
+
+	cmd := &cobra.Command{
+		Use:   "cat-file (-t | -s | -e | -p | <type>) <object>",
+		Short: "Print the content, type or size of repository objects",
+		Long: `Print information about objects in the object database.
+
+<object> may be a full or abbreviated hash, a ref, or a revision such as
+HEAD~1, v1.0^{tree} or HEAD:path/to/file.
+
+With --batch or --batch-check, object names are read from standard input,
+one per line, and for each one a line "<hash> <type> <size>" is written
+(followed by the content and a newline with --batch), or "<name> missing".
+Output matches git cat-file byte for byte.
+
+Examples:
+  # Show an object's type and size
+  srcc cat-file -t HEAD
+  srcc cat-file -s HEAD:README.md
+
+  # Pretty-print a tree
+  srcc cat-file -p HEAD^{tree}
+
+  # Print a blob's content, peeling through tags and commits as needed
+  srcc cat-file blob v1.0:README.md
+
+  # Check many objects at once
+  git rev-list --objects --all | cut -d' ' -f1 | srcc cat-file --batch-check`,
+		Args: cobra.RangeArgs(0, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := findRepository()
+			if err != nil {
+				return err
+			}
+			resolver := plumbing.NewResolver(repo)
+			objectStore := repo.ObjectStore()
+
+			if batch || batchCheck {
+				if len(args) > 0 {
+					return fmt.Errorf("--batch and --batch-check take object names on standard input")
+				}
+				return plumbing.Batch(os.Stdin, os.Stdout, resolver, plumbing.BatchOptions{Contents: batch})
+			}
+
+			modes := 0
+			for _, set := range []bool{showType, showSize, exists, pretty} {
+				if set {
+					modes++
+				}
+			}
+			switch {
+			case modes > 1:
+				return fmt.Errorf("only one of -t, -s, -e and -p may be given")
+			case modes == 1 && len(args) != 1, modes == 0 && len(args) != 2:
+				return fmt.Errorf("usage: %s", cmd.Use)
+			}
+
+			name := args[len(args)-1]
+			hash, err := resolver.Resolve(name)
+			if exists {
+				if err != nil {
+					os.Exit(1)
+				}
+				if has, err := objectStore.HasObject(hash); err != nil || !has {
+					os.Exit(1)
+				}
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case modes == 0:
+				// cat-file <type> <object> peels tags and commits to the type asked for
+				hash, err = resolver.Peel(hash, objects.ObjectType(args[0]))
+				if err != nil {
+					return err
+				}
+				return catFileError(name, plumbing.WriteContent(os.Stdout, objectStore, hash))
+			case pretty:
+				return catFileError(name, plumbing.PrettyPrint(os.Stdout, objectStore, hash))
+			}
+
+			info, err := plumbing.Stat(objectStore, hash)
+			if err != nil {
+				return catFileError(name, err)
+			}
+			if showType {
+				fmt.Println(info.Type)
+			} else {
+				fmt.Println(info.Size.Int64())
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&showType, "type", "t", false, "Show the object's type")
+	cmd.Flags().BoolVarP(&showSize, "size", "s", false, "Show the object's size")
+	cmd.Flags().BoolVarP(&exists, "exists", "e", false, "Exit with status 0 if the object exists and is valid, 1 otherwise")
+	cmd.Flags().BoolVarP(&pretty, "pretty", "p", false, "Pretty-print the object's content")
+	cmd.Flags().BoolVar(&batch, "batch", false, "Print info and content of objects named on standard input")
+	cmd.Flags().BoolVar(&batchCheck, "batch-check", false, "Print info of objects named on standard input")
+
+	return cmd
+}
+
+// catFileError rewords a missing object error the way git reports it.
+func catFileError(name string, err error) error {
+	if errors.Is(err, store.ErrObjectNotFound) {
+		return fmt.Errorf("Not a valid object name %s", name)
+	}
+	return err
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/utkarsh5026/SourceControl/cmd/ui"
+	"github.com/utkarsh5026/SourceControl/pkg/clone"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+)
+
+func newCloneCmd() *cobra.Command {
+	var shared bool
+	var references []string
+	var noCheckout bool
+
+	cmd := &cobra.Command{
+		Use:   "clone <repository> [<directory>]",
+		Short: "Clone a local repository into a new directory",
+		Long: `Create a new repository from an existing one on the local filesystem.
+
+The branches of the source become remote-tracking branches of "origin",
+tags are copied, and the branch checked out in the source is checked out
+in the clone.
+
+By default every object is copied. With --shared the clone copies nothing
+and reads the source's objects through .git/objects/info/alternates; with
+--reference the objects of another repository are borrowed the same way
+and only the objects it lacks are copied.
+
+A clone that borrows objects breaks if those objects are removed from the
+repository it borrows from.
+
+Examples:
+  # Copy a repository
+  srcc clone ../project
+
+  # Share objects with the source
+  srcc clone --shared ../project project-work
+
+  # Borrow objects from a local mirror
+  srcc clone --reference ~/mirrors/project ../project`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			source, err := scpath.NewRepositoryPath(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid source: %w", err)
+			}
+
+			target := cloneDirectoryName(args[0])
+			if len(args) == 2 {
+				target = args[1]
+			}
+			destination, err := scpath.NewRepositoryPath(target)
+			if err != nil {
+				return fmt.Errorf("invalid destination: %w", err)
+			}
+
+			opts := clone.Options{
+				Source:      source,
+				Destination: destination,
+				Shared:      shared,
+				NoCheckout:  noCheckout,
+			}
+			for _, ref := range references {
+				path, err := scpath.NewRepositoryPath(ref)
+				if err != nil {
+					return fmt.Errorf("invalid reference: %w", err)
+				}
+				opts.References = append(opts.References, path)
+			}
+
+			fmt.Printf("Cloning into '%s'...\n", target)
+			result, err := clone.Clone(context.Background(), opts)
+			if err != nil {
+				return fmt.Errorf("clone failed: %w", err)
+			}
+
+			if result.Head == "" {
+				fmt.Println(ui.Yellow("warning: You appear to have cloned an empty repository."))
+				return nil
+			}
+			for _, alt := range result.Alternates {
+				fmt.Printf("borrowing objects from %s\n", alt)
+			}
+			fmt.Printf("%d objects copied\n", result.ObjectsCopied)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&shared, "shared", "s", false, "Borrow objects from the source instead of copying them")
+	cmd.Flags().StringArrayVar(&references, "reference", nil, "Borrow objects from this repository (may be repeated)")
+	cmd.Flags().BoolVarP(&noCheckout, "no-checkout", "n", false, "Do not check out HEAD after cloning")
+	cmd.MarkFlagsMutuallyExclusive("shared", "reference")
+
+	return cmd
+}
+
+// cloneDirectoryName derives the default target directory from the source
+// path, dropping a trailing .git as git clone does.
+func cloneDirectoryName(source string) string {
+	name := filepath.Base(filepath.Clean(source))
+	if name == ".git" {
+		name = filepath.Base(filepath.Dir(filepath.Clean(source)))
+	}
+	return strings.TrimSuffix(name, ".git")
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/plumbing"
+)
+
+func newCommitTreeCmd() *cobra.Command {
+	var parents []string
+	var messages []string
+
+	cmd := &cobra.Command{
+		Use:   "commit-tree <tree> [-p <parent>]... [-m <message>]...",
+		Short: "Create a new commit object",
+		Long: `Create a commit for an existing tree and print its name. No branch is updated.
+
+Each -m gives a paragraph of the message; without -m the message is read
+from standard input. The author and committer come from GIT_AUTHOR_NAME,
+GIT_AUTHOR_EMAIL, GIT_AUTHOR_DATE (and the GIT_COMMITTER_ equivalents) or
+from user.name and user.email.
+
+Examples:
+  srcc commit-tree $(srcc write-tree) -p HEAD -m "Snapshot"
+  echo "Root commit" | srcc commit-tree 4b825dc`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := findRepository()
+			if err != nil {
+				return err
+			}
+			resolver := plumbing.NewResolver(repo)
+
+			hash, err := resolver.Resolve(args[0])
+			if err != nil {
+				return err
+			}
+			treeHash, err := resolver.Peel(hash, objects.TreeType)
+			if err != nil {
+				return err
+			}
+
+			opts := plumbing.CommitTreeOptions{}
+			for _, name := range parents {
+				parent, err := resolver.Resolve(name)
+				if err != nil {
+					return err
+				}
+				if parent, err = resolver.Peel(parent, objects.CommitType); err != nil {
+					return err
+				}
+				opts.Parents = append(opts.Parents, parent)
+			}
+
+			if len(messages) > 0 {
+				opts.Message = strings.Join(messages, "\n\n") + "\n"
+			} else {
+				data, err := io.ReadAll(os.Stdin)
+				if err != nil {
+					return fmt.Errorf("failed to read commit message: %w", err)
+				}
+				opts.Message = string(data)
+			}
+
+			commitHash, err := plumbing.CommitTree(context.Background(), repo, treeHash, opts)
+			if err != nil {
+				return err
+			}
+			fmt.Println(commitHash)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArrayVarP(&parents, "parent", "p", nil, "Parent commit (may be repeated)")
+	cmd.Flags().StringArrayVarP(&messages, "message", "m", nil, "Message paragraph (may be repeated)")
+
+	return cmd
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/utkarsh5026/SourceControl/cmd/ui"
+	"github.com/utkarsh5026/SourceControl/pkg/fsck"
+)
+
+func newFsckCmd() *cobra.Command {
+	var strict bool
+	var unreachable bool
+	var noDangling bool
+
+	cmd := &cobra.Command{
+		Use:   "fsck",
+		Short: "Verify the integrity of the object database",
+		Long: `Check every stored object and the connectivity of the repository.
+
+Every loose and packed object is re-hashed and checked for malformed
+content: unsorted or duplicate tree entries, unknown file modes, and commit
+or tag headers out of order. Pack checksums are verified. History is then
+followed from every ref, HEAD and the index to find missing objects.
+
+Dangling objects (unreachable objects nothing else points to) are listed
+but are not errors.
+
+Exit status is 0 when no problems are found, otherwise the sum of:
+  1  corrupt or malformed objects
+  2  missing reachable objects
+  4  damaged packs
+  8  refs or index entries naming missing objects
+
+Examples:
+  # Check the repository
+  srcc fsck
+
+  # Also list unreachable objects that are referenced by other unreachable objects
+  srcc fsck --unreachable
+
+  # Treat warnings such as zero-padded file modes as errors
+  srcc fsck --strict`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := findRepository()
+			if err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+			checker := fsck.NewChecker(repo)
+			if err := checker.Initialize(ctx); err != nil {
+				return fmt.Errorf("failed to initialize fsck: %w", err)
+			}
+
+			report, err := checker.Check(ctx, fsck.Options{Strict: strict})
+			if err != nil {
+				return fmt.Errorf("fsck failed: %w", err)
+			}
+
+			for _, p := range report.Problems {
+				if p.Kind == fsck.ProblemWarning && !strict {
+					fmt.Fprintln(os.Stderr, ui.Yellow(p.String()))
+				} else {
+					fmt.Fprintln(os.Stderr, ui.Red(p.String()))
+				}
+			}
+
+			switch {
+			case unreachable:
+				for _, obj := range report.Unreachable {
+					fmt.Printf("unreachable %s %s\n", obj.Type, obj.Hash)
+				}
+			case !noDangling:
+				for _, obj := range report.Dangling {
+					fmt.Printf("dangling %s %s\n", obj.Type, obj.Hash)
+				}
+			}
+
+			if code := report.ExitCode(); code != 0 {
+				os.Exit(code)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&strict, "strict", false, "Treat warnings as errors")
+	cmd.Flags().BoolVar(&unreachable, "unreachable", false, "List every unreachable object, not only dangling ones")
+	cmd.Flags().BoolVar(&noDangling, "no-dangling", false, "Do not list dangling objects")
+
+	return cmd
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/utkarsh5026/SourceControl/cmd/ui"
+	"github.com/utkarsh5026/SourceControl/pkg/gc"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/sourcerepo"
+	"github.com/utkarsh5026/SourceControl/pkg/store"
+)
+
+func newGCCmd() *cobra.Command {
+	var auto bool
+	var window int
+	var depth int
+	var prune string
+
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Short: "Pack objects and remove redundant loose files",
+		Long: `Optimize the repository by packing objects into a single packfile.
+
+All objects reachable from branches, tags and HEAD, together with every
+object that was already packed, are written into one delta-compressed pack.
+Old packs and loose objects that are now stored in the pack are deleted.
+Unreachable loose objects older than the gc.pruneExpire grace period
+(default 2.weeks.ago) are pruned; see "srcc prune".
+
+With --auto, gc only runs when the number of loose objects exceeds the
+gc.auto setting (default 6700; 0 disables it). srcc commit runs
+"gc --auto" after every commit.
+
+Examples:
+  # Pack the repository
+  srcc gc
+
+  # Pack only if there are many loose objects
+  srcc gc --auto
+
+  # Prune every unreachable object immediately
+  srcc gc --prune=now`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := findRepository()
+			if err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+			mgr := gc.NewManager(repo)
+			if err := mgr.Initialize(ctx); err != nil {
+				return fmt.Errorf("failed to initialize gc: %w", err)
+			}
+
+			result, err := mgr.Run(ctx, gc.Options{
+				Auto:  auto,
+				Pack:  store.PackWriterOptions{Window: window, Depth: depth},
+				Prune: prune,
+			})
+			if err != nil {
+				return fmt.Errorf("gc failed: %w", err)
+			}
+
+			printPackResult(result)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&auto, "auto", false, "Only run if there are more loose objects than gc.auto")
+	cmd.Flags().IntVar(&window, "window", store.DefaultPackWindow, "Number of objects tried as delta bases")
+	cmd.Flags().IntVar(&depth, "depth", store.DefaultPackDepth, "Maximum delta chain length")
+	cmd.Flags().StringVar(&prune, "prune", "", "Prune unreachable objects older than this date (default gc.pruneExpire)")
+
+	return cmd
+}
+
+func newPruneCmd() *cobra.Command {
+	var expire string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove unreachable loose objects",
+		Long: `Delete loose objects that cannot be reached from any branch, tag, HEAD,
+ORIG_HEAD, MERGE_HEAD or the index.
+
+Only objects last written before the --expire date are removed, so objects
+a concurrent command has just written are safe; so is anything those recent
+objects refer to. Without --expire the gc.pruneExpire setting is used
+(default 2.weeks.ago). Packed objects are never pruned.
+
+Dates may be relative ("2.weeks.ago", "3 days ago"), absolute
+("2024-01-31"), "now" or "never".
+
+Examples:
+  # List what would be removed
+  srcc prune --dry-run
+
+  # Remove unreachable objects older than a day
+  srcc prune --expire=1.day.ago
+
+  # Remove every unreachable loose object
+  srcc prune --expire=now`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := findRepository()
+			if err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+			mgr := gc.NewManager(repo)
+			if err := mgr.Initialize(ctx); err != nil {
+				return fmt.Errorf("failed to initialize prune: %w", err)
+			}
+
+			expireAt, err := mgr.PruneExpire(expire)
+			if err != nil {
+				return err
+			}
+
+			result, err := mgr.Prune(ctx, gc.PruneOptions{Expire: expireAt, DryRun: dryRun})
+			if err != nil {
+				return fmt.Errorf("prune failed: %w", err)
+			}
+
+			if dryRun {
+				for _, obj := range result.Pruned {
+					fmt.Printf("%s %s\n", obj.Hash, obj.Type)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&expire, "expire", "", "Only prune objects older than this date (default gc.pruneExpire)")
+	cmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "List the objects that would be removed without removing them")
+
+	return cmd
+}
+
+func newRepackCmd() *cobra.Command {
+	var removeRedundant bool
+	var window int
+	var depth int
+
+	cmd := &cobra.Command{
+		Use:   "repack",
+		Short: "Pack objects into a delta-compressed packfile",
+		Long: `Write all reachable and already-packed objects into a new packfile.
+
+Without -d the old packs and loose objects are left in place; with -d they
+are removed once the new pack has been written.
+
+Examples:
+  # Write a new pack, keeping existing files
+  srcc repack
+
+  # Replace existing packs and loose objects
+  srcc repack -d
+
+  # Search harder for deltas
+  srcc repack -d --window=50 --depth=100`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := findRepository()
+			if err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+			mgr := gc.NewManager(repo)
+			if err := mgr.Initialize(ctx); err != nil {
+				return fmt.Errorf("failed to initialize repack: %w", err)
+			}
+
+			result, err := mgr.Repack(ctx, gc.RepackOptions{
+				Pack:            store.PackWriterOptions{Window: window, Depth: depth},
+				RemoveRedundant: removeRedundant,
+			})
+			if err != nil {
+				return fmt.Errorf("repack failed: %w", err)
+			}
+
+			printPackResult(result)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&removeRedundant, "delete", "d", false, "Remove redundant packs and loose objects")
+	cmd.Flags().IntVar(&window, "window", store.DefaultPackWindow, "Number of objects tried as delta bases")
+	cmd.Flags().IntVar(&depth, "depth", store.DefaultPackDepth, "Maximum delta chain length")
+
+	return cmd
+}
+
+// printPackResult reports the outcome of a gc or repack.
+func printPackResult(result *gc.Result) {
+	if result.Skipped {
+		return
+	}
+	if result.Pack == nil {
+		fmt.Println("Nothing to pack")
+		return
+	}
+
+	fmt.Printf("%s %s\n", ui.Green("packed:"), result.Pack.PackPath.Base())
+	fmt.Printf("%d objects, %d deltas\n", result.Pack.Objects, result.Pack.Deltas)
+	if result.PacksRemoved > 0 || result.LooseRemoved > 0 {
+		fmt.Printf("removed %d old packs and %d loose objects\n", result.PacksRemoved, result.LooseRemoved)
+	}
+	if result.Pruned > 0 {
+		fmt.Printf("pruned %d unreachable objects\n", result.Pruned)
+	}
+}
+
+// runAutoGC packs the repository if it has accumulated more loose objects
+// than gc.auto allows. Failures are reported but never fail the calling command.
+func runAutoGC(repo *sourcerepo.SourceRepository) {
+	ctx := context.Background()
+	mgr := gc.NewManager(repo)
+	if err := mgr.Initialize(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: auto gc: %v\n", err)
+		return
+	}
+
+	needed, err := mgr.NeedsAuto()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: auto gc: %v\n", err)
+		return
+	}
+	if !needed {
+		return
+	}
+
+	fmt.Println("Auto packing the repository for optimum performance.")
+	if _, err := mgr.Run(ctx, gc.Options{Pack: store.DefaultPackWriterOptions()}); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: auto gc: %v\n", err)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/plumbing"
+	"github.com/utkarsh5026/SourceControl/pkg/store"
+)
+
+func newHashObjectCmd() *cobra.Command {
+	var write bool
+	var stdin bool
+	var objType string
+
+	cmd := &cobra.Command{
+		Use:   "hash-object [-w] [-t <type>] [--stdin] [<file>...]",
+		Short: "Compute object IDs and optionally create objects from files",
+		Long: `Compute the object ID of each file, or of standard input with --stdin,
+and print one per line. With -w the objects are also written to the object
+database.
+
+Trees, commits and tags given with -t are checked for well-formedness before
+they are hashed.
+
+Examples:
+  # Hash a file without storing it
+  srcc hash-object README.md
+
+  # Store content read from standard input
+  echo 'hello' | srcc hash-object -w --stdin
+
+  # Store a hand-written commit
+  srcc hash-object -w -t commit --stdin < commit.txt`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !stdin && len(args) == 0 {
+				return fmt.Errorf("usage: %s", cmd.Use)
+			}
+
+			// Hashing without -w works outside a repository, as in git
+			var objectStore store.ObjectStore = store.NewMemoryObjectStore()
+			repo, err := findRepository()
+			switch {
+			case err == nil && write:
+				objectStore = repo.ObjectStore()
+			case err == nil:
+				objectStore = store.NewMemoryObjectStoreWithAlgorithm(repo.HashAlgorithm())
+			case write:
+				return err
+			}
+
+			opts := plumbing.HashOptions{Type: objects.ObjectType(objType), Write: write}
+
+			if stdin {
+				hash, err := plumbing.HashObject(objectStore, os.Stdin, -1, opts)
+				if err != nil {
+					return err
+				}
+				fmt.Println(hash)
+			}
+
+			for _, path := range args {
+				hash, err := hashFile(objectStore, path, opts)
+				if err != nil {
+					return err
+				}
+				fmt.Println(hash)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&write, "write", "w", false, "Write the object into the object database")
+	cmd.Flags().BoolVar(&stdin, "stdin", false, "Read the object from standard input")
+	cmd.Flags().StringVarP(&objType, "type", "t", string(objects.BlobType), "Type of object to create")
+
+	return cmd
+}
+
+// hashFile hashes the content of the file at path, streaming it if it is a blob.
+func hashFile(objectStore store.ObjectStore, path string, opts plumbing.HashOptions) (objects.ObjectHash, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("could not open '%s': %w", path, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	return plumbing.HashObject(objectStore, file, info.Size(), opts)
+}
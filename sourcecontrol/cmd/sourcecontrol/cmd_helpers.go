@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/utkarsh5026/SourceControl/pkg/index"
+	"github.com/utkarsh5026/SourceControl/pkg/plumbing"
+	"github.com/utkarsh5026/SourceControl/pkg/refs/branch"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/sourcerepo"
+	"github.com/utkarsh5026/SourceControl/pkg/store"
+)
+
+// findRepository finds the repository starting from current directory
+func findRepository() (*sourcerepo.SourceRepository, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	dir := cwd
+	for {
+		sourceDir := filepath.Join(dir, scpath.SourceDir)
+		if info, err := os.Stat(sourceDir); err == nil && info.IsDir() {
+			repoPath, err := scpath.NewRepositoryPath(dir)
+			if err != nil {
+				return nil, fmt.Errorf("invalid repository path: %w", err)
+			}
+			return sourcerepo.Open(repoPath)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, fmt.Errorf("not a sourcecontrol repository (or any parent up to mount point)")
+		}
+		dir = parent
+	}
+}
+
+// enableObjectCache caches parsed objects for commands that walk history and
+// returns a function that logs the cache statistics once the command is done.
+func enableObjectCache(repo *sourcerepo.SourceRepository) func() {
+	return repo.EnableObjectCache(store.DefaultObjectCacheSize).LogStats
+}
+
+// headEntries returns the entries of the tree HEAD points to, keyed by path,
+// or an empty map on an unborn branch.
+func headEntries(repo *sourcerepo.SourceRepository) (map[string]*index.Entry, error) {
+	treeHash, err := plumbing.NewResolver(repo).Resolve("HEAD^{tree}")
+	if err != nil {
+		return make(map[string]*index.Entry), nil
+	}
+	entries, err := plumbing.FlattenTree(repo.ObjectStore(), treeHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HEAD tree: %w", err)
+	}
+	return entries, nil
+}
+
+// getCurrentBranchName gets the current branch name or returns detached HEAD info
+func getCurrentBranchName(repo *sourcerepo.SourceRepository) (string, error) {
+	mgr := branch.NewManager(repo)
+
+	// Check if we're in detached HEAD state
+	detached, err := mgr.IsDetached()
+	if err != nil {
+		return "", fmt.Errorf("check detached state: %w", err)
+	}
+
+	if detached {
+		// Get current commit SHA
+		commitSHA, err := mgr.CurrentCommit()
+		if err != nil {
+			return "", fmt.Errorf("get current commit: %w", err)
+		}
+		return fmt.Sprintf("HEAD detached at %s", commitSHA.Short()), nil
+	}
+
+	// Get current branch name
+	branchName, err := mgr.CurrentBranch()
+	if err != nil {
+		return "", fmt.Errorf("get current branch: %w", err)
+	}
+
+	return branchName, nil
+}
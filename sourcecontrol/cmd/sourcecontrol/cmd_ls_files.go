@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/utkarsh5026/SourceControl/pkg/index"
+	"github.com/utkarsh5026/SourceControl/pkg/plumbing"
+)
+
+func newLsFilesCmd() *cobra.Command {
+	var opts plumbing.LsFilesOptions
+
+	cmd := &cobra.Command{
+		Use:   "ls-files [-s] [-u] [-z]",
+		Short: "Show information about files in the index",
+		Long: `List the paths in the index, in index order.
+
+With --stage each line also shows the entry's mode, object and stage:
+  <mode> <object> <stage>\t<path>
+With --unmerged only the conflict stages of unmerged paths are shown.
+
+Paths with special characters are quoted as git does unless -z is given.
+
+Examples:
+  srcc ls-files
+  srcc ls-files --stage
+  srcc ls-files -u -z | xargs -0 ...`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := findRepository()
+			if err != nil {
+				return err
+			}
+			idx, err := index.Read(repo.SourceDirectory().IndexPath().ToAbsolutePath())
+			if err != nil {
+				return err
+			}
+			return plumbing.ListFiles(os.Stdout, idx, opts)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&opts.Stage, "stage", "s", false, "Show mode, object name and stage number")
+	cmd.Flags().BoolVarP(&opts.Unmerged, "unmerged", "u", false, "Show only unmerged entries (implies --stage)")
+	cmd.Flags().BoolVarP(&opts.NullTerminated, "null", "z", false, "Terminate lines with NUL and do not quote paths")
+
+	return cmd
+}
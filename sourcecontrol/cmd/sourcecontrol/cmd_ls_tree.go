@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/plumbing"
+)
+
+func newLsTreeCmd() *cobra.Command {
+	var opts plumbing.LsTreeOptions
+
+	cmd := &cobra.Command{
+		Use:   "ls-tree [-r] [-t] [--name-only] [-z] <tree-ish>",
+		Short: "List the contents of a tree object",
+		Long: `List the entries of a tree, one "<mode> <type> <object>\t<name>" line each.
+
+<tree-ish> may be a tree, or a commit or tag that leads to one.
+
+Examples:
+  srcc ls-tree HEAD
+  srcc ls-tree -r --name-only HEAD
+  srcc ls-tree -r -t v1.0`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := findRepository()
+			if err != nil {
+				return err
+			}
+			resolver := plumbing.NewResolver(repo)
+			hash, err := resolver.Resolve(args[0])
+			if err != nil {
+				return err
+			}
+			treeHash, err := resolver.Peel(hash, objects.TreeType)
+			if err != nil {
+				return err
+			}
+			return plumbing.ListTree(os.Stdout, repo.ObjectStore(), treeHash, opts)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&opts.Recursive, "recursive", "r", false, "Recurse into subtrees")
+	cmd.Flags().BoolVarP(&opts.ShowTrees, "trees", "t", false, "Show trees when recursing")
+	cmd.Flags().BoolVar(&opts.NameOnly, "name-only", false, "List only paths")
+	cmd.Flags().BoolVarP(&opts.NullTerminated, "null", "z", false, "Terminate lines with NUL and do not quote paths")
+
+	return cmd
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/utkarsh5026/SourceControl/cmd/ui"
+	"github.com/utkarsh5026/SourceControl/pkg/store"
+)
+
+func newMigrateObjectsCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "migrate-objects",
+		Short: "Rewrite legacy loose objects in git's zlib format",
+		Long: `Rewrite loose objects written by older versions of srcc.
+
+Earlier releases compressed objects with raw DEFLATE instead of the zlib
+format git expects, so git could not read those repositories. This command
+scans .git/objects, re-encodes every legacy object in place and leaves
+objects that are already in zlib format untouched. Each object is verified
+against its hash before it is rewritten.
+
+The migration is safe to interrupt and to run more than once.
+
+Examples:
+  # Migrate the current repository
+  srcc migrate-objects
+
+  # Show which objects would be rewritten
+  srcc migrate-objects --dry-run`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := findRepository()
+			if err != nil {
+				return err
+			}
+
+			objectStore := store.NewFileObjectStore()
+			if err := objectStore.Initialize(repo.WorkingDirectory()); err != nil {
+				return fmt.Errorf("failed to initialize object store: %w", err)
+			}
+
+			result, err := objectStore.MigrateLegacyObjects(dryRun)
+			if err != nil {
+				return fmt.Errorf("failed to migrate objects: %w", err)
+			}
+
+			verb := "migrated:"
+			if dryRun {
+				verb = "would migrate:"
+			}
+			for _, hash := range result.Migrated {
+				fmt.Printf("%s %s\n", ui.Green(verb), hash)
+			}
+			for _, failure := range result.Failed {
+				fmt.Printf("%s %s: %v\n", ui.Red("failed:"), failure.Hash, failure.Error)
+			}
+
+			fmt.Printf("%d objects scanned, %d legacy, %d failed\n",
+				result.Scanned, len(result.Migrated), len(result.Failed))
+
+			if len(result.Failed) > 0 {
+				return fmt.Errorf("%d objects could not be migrated", len(result.Failed))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "Report legacy objects without rewriting them")
+
+	return cmd
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/utkarsh5026/SourceControl/pkg/index"
+)
+
+func newMvCmd() *cobra.Command {
+	var opts index.MoveOptions
+
+	cmd := &cobra.Command{
+		Use:   "mv [-f] <source>... <destination>",
+		Short: "Move or rename a file or directory",
+		Long: `Move or rename tracked files and directories.
+
+With one source, the destination is its new name, or an existing directory
+to move it into. With several, the destination must be an existing
+directory. The working tree and the index are updated together, and the
+moved files keep their staged content.`,
+		Example: `  # Rename a file
+  srcc mv old.go new.go
+
+  # Move files into a directory
+  srcc mv a.go b.go pkg/
+
+  # Replace an existing file
+  srcc mv -f draft.md README.md`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := findRepository()
+			if err != nil {
+				return err
+			}
+
+			indexMgr := index.NewManager(repo.WorkingDirectory())
+			if err := indexMgr.Initialize(); err != nil {
+				return fmt.Errorf("failed to initialize index: %w", err)
+			}
+
+			result, err := indexMgr.Move(args[:len(args)-1], args[len(args)-1], opts)
+			if err != nil {
+				return err
+			}
+
+			if opts.DryRun {
+				for _, rename := range result.Moved {
+					fmt.Printf("Renaming %s to %s\n", rename.From, rename.To)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&opts.Force, "force", "f", false, "Overwrite an existing destination file")
+	cmd.Flags().BoolVarP(&opts.DryRun, "dry-run", "n", false, "Show what would be moved without moving it")
+
+	return cmd
+}
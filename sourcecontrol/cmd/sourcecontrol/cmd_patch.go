@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/utkarsh5026/SourceControl/pkg/index"
+	"github.com/utkarsh5026/SourceControl/pkg/patch"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/sourcerepo"
+)
+
+// patchOptions returns the options of an add -p or reset -p session: answers
+// are read from stdin, which may be a terminal or a script, and hunks are
+// edited in the user's editor.
+func patchOptions(repo *sourcerepo.SourceRepository, pathspecs []string) (patch.Options, error) {
+	specs, err := index.ParsePathspecs(pathspecs)
+	if err != nil {
+		return patch.Options{}, err
+	}
+
+	return patch.Options{
+		Pathspecs: specs,
+		Input:     os.Stdin,
+		Output:    os.Stdout,
+		Edit: func(text string) (string, error) {
+			return editText(repo, "ADD_EDIT.patch", text)
+		},
+	}, nil
+}
+
+// editText opens text in the user's editor, taken from GIT_EDITOR, VISUAL
+// or EDITOR, and returns it once the editor exits. The text is kept in the
+// named file of the repository directory while it is edited.
+func editText(repo *sourcerepo.SourceRepository, name, text string) (string, error) {
+	path := filepath.Join(repo.SourceDirectory().String(), name)
+	if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+		return "", err
+	}
+	defer os.Remove(path)
+
+	editor := "vi"
+	for _, variable := range []string{"GIT_EDITOR", "VISUAL", "EDITOR"} {
+		if value := strings.TrimSpace(os.Getenv(variable)); value != "" {
+			editor = value
+			break
+		}
+	}
+
+	cmd := editorCommand(editor, path)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("there was a problem with the editor '%s': %w", editor, err)
+	}
+
+	edited, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(edited), nil
+}
+
+// editorCommand returns the command that runs editor on path. As in Git, the
+// editor is a shell command, so it may carry arguments and quoting, and path
+// is passed to it as an argument of its own. Windows has no sh to run it
+// with, so there the editor is split at whitespace into the program and its
+// arguments.
+func editorCommand(editor, path string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		fields := strings.Fields(editor)
+		return exec.Command(fields[0], append(fields[1:], path)...)
+	}
+	return exec.Command("sh", "-c", editor+` "$@"`, editor, path)
+}
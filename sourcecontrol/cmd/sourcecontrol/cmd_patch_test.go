@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestEditText_EditorWithArguments(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test editor is a shell script")
+	}
+	h := NewTestHelper(t)
+	repo := h.InitRepo()
+
+	// The editor is a script in a directory with a space in its name,
+	// quoted and followed by an argument, as Git accepts it
+	dir := filepath.Join(t.TempDir(), "my editor")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	script := filepath.Join(dir, "edit.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nprintf '%s\\n' \"$1\" >> \"$2\"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GIT_EDITOR", "sh '"+script+"' 'two words'")
+
+	edited, err := editText(repo, "TEST_EDIT.patch", "original\n")
+	if err != nil {
+		t.Fatalf("editText() failed: %v", err)
+	}
+	if want := "original\ntwo words\n"; edited != want {
+		t.Errorf("editText() = %q, want %q", edited, want)
+	}
+	if _, err := os.Stat(filepath.Join(repo.SourceDirectory().String(), "TEST_EDIT.patch")); !os.IsNotExist(err) {
+		t.Errorf("the edited file was left behind: %v", err)
+	}
+}
+
+func TestEditorCommand_SplitsArguments(t *testing.T) {
+	cmd := editorCommand("code --wait", "/repo/.git/ADD_EDIT.patch")
+	args := cmd.Args
+	if runtime.GOOS == "windows" {
+		if len(args) != 3 || args[1] != "--wait" || args[2] != "/repo/.git/ADD_EDIT.patch" {
+			t.Errorf("Args = %q, want code --wait and the path", args)
+		}
+		return
+	}
+	if len(args) != 5 || args[0] != "sh" || args[2] != `code --wait "$@"` || args[4] != "/repo/.git/ADD_EDIT.patch" {
+		t.Errorf("Args = %q, want the editor run by sh with the path as its argument", args)
+	}
+}
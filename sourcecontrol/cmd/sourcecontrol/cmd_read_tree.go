@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/utkarsh5026/SourceControl/pkg/index"
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/plumbing"
+)
+
+func newReadTreeCmd() *cobra.Command {
+	var opts plumbing.ReadTreeOptions
+
+	cmd := &cobra.Command{
+		Use:   "read-tree [-m] <tree-ish> [<tree-ish> [<tree-ish>]]",
+		Short: "Read tree information into the index",
+		Long: `Replace the index with the contents of a tree, or merge trees into it with -m.
+
+The working directory is not touched.
+
+  read-tree <tree>                 the index becomes <tree>
+  read-tree -m <tree>              the same, keeping cached stat information
+  read-tree -m <head> <next>       move the index from <head> to <next>,
+                                   keeping changes staged on top of <head>
+  read-tree -m <base> <ours> <theirs>
+                                   three-way merge; paths changed on only one
+                                   side are resolved, the rest are left as
+                                   conflict stages 1, 2 and 3
+
+Examples:
+  srcc read-tree HEAD
+  srcc read-tree -m $(srcc merge-base HEAD topic) HEAD topic`,
+		Args: cobra.RangeArgs(1, 3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := findRepository()
+			if err != nil {
+				return err
+			}
+			resolver := plumbing.NewResolver(repo)
+
+			trees := make([]objects.ObjectHash, len(args))
+			for i, name := range args {
+				hash, err := resolver.Resolve(name)
+				if err != nil {
+					return err
+				}
+				if trees[i], err = resolver.Peel(hash, objects.TreeType); err != nil {
+					return err
+				}
+			}
+
+			indexPath := repo.SourceDirectory().IndexPath().ToAbsolutePath()
+			return index.Update(indexPath, func(idx *index.Index) error {
+				return plumbing.ReadTree(repo.ObjectStore(), idx, trees, opts)
+			})
+		},
+	}
+
+	cmd.Flags().BoolVarP(&opts.Merge, "merge", "m", false, "Merge the trees into the index")
+
+	return cmd
+}
+
+func newWriteTreeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "write-tree",
+		Short: "Create a tree object from the current index",
+		Long: `Write tree objects for the contents of the index and print the root tree's name.
+
+The index must not have unmerged entries.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := findRepository()
+			if err != nil {
+				return err
+			}
+			var hash objects.ObjectHash
+			indexPath := repo.SourceDirectory().IndexPath().ToAbsolutePath()
+			err = index.Update(indexPath, func(idx *index.Index) error {
+				hash, err = plumbing.WriteTree(context.Background(), repo, idx)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Println(hash)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/plumbing"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/sourcerepo"
+	"github.com/utkarsh5026/SourceControl/pkg/workdir"
+)
+
+func newRestoreCmd() *cobra.Command {
+	var source string
+	var opts workdir.RestoreOptions
+
+	cmd := &cobra.Command{
+		Use:   "restore [-s <tree-ish>] [-S] [-W] <pathspec>...",
+		Short: "Restore working tree files",
+		Long: `Restore files in the working tree from the index, or from another tree
+with --source. With --staged the index is restored instead, from HEAD
+unless --source is given; use both --staged and --worktree to restore both.
+
+Files that are tracked but missing from the source are removed.`,
+		Example: `  # Discard changes to a file
+  srcc restore README.md
+
+  # Unstage a file, keeping the changes in the working tree
+  srcc restore --staged README.md
+
+  # Restore a directory, staged and on disk, as it was two commits ago
+  srcc restore -s HEAD~2 -SW docs/`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := findRepository()
+			if err != nil {
+				return err
+			}
+
+			if source == "" && opts.Staged {
+				source = "HEAD"
+			}
+			if source != "" {
+				if opts.Source, err = resolveTree(repo, source); err != nil {
+					return err
+				}
+			}
+
+			_, err = workdir.NewManager(repo).Restore(context.Background(), args, opts)
+			return err
+		},
+	}
+
+	cmd.Flags().StringVarP(&source, "source", "s", "", "Restore from this tree-ish")
+	cmd.Flags().BoolVarP(&opts.Staged, "staged", "S", false, "Restore the index")
+	cmd.Flags().BoolVarP(&opts.Worktree, "worktree", "W", false, "Restore the working tree (the default)")
+
+	return cmd
+}
+
+// resolveTree resolves rev to the tree it names or points to.
+func resolveTree(repo *sourcerepo.SourceRepository, rev string) (objects.ObjectHash, error) {
+	tree, err := plumbing.NewResolver(repo).Resolve(rev + "^{tree}")
+	if err != nil {
+		return "", fmt.Errorf("could not resolve %s: %w", rev, err)
+	}
+	return tree, nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/utkarsh5026/SourceControl/cmd/ui"
+	"github.com/utkarsh5026/SourceControl/pkg/index"
+)
+
+func newRmCmd() *cobra.Command {
+	var opts index.RemoveOptions
+
+	cmd := &cobra.Command{
+		Use:   "rm [--cached] [-r] [-f] <pathspec>...",
+		Short: "Remove files from the working tree and from the index",
+		Long: `Remove tracked files from the index and the working tree.
+
+A file is only removed if its working tree copy and staged content both
+match HEAD, so that no changes are lost; --force skips this check. With
+--cached the file is kept in the working tree and only unstaged.`,
+		Example: `  # Stop tracking a file but keep it on disk
+  srcc rm --cached secrets.env
+
+  # Remove a directory and everything tracked below it
+  srcc rm -r build/
+
+  # Show which files would be removed
+  srcc rm -n '*.tmp'`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := findRepository()
+			if err != nil {
+				return err
+			}
+
+			if opts.Head, err = headEntries(repo); err != nil {
+				return err
+			}
+
+			indexMgr := index.NewManager(repo.WorkingDirectory())
+			if err := indexMgr.Initialize(); err != nil {
+				return fmt.Errorf("failed to initialize index: %w", err)
+			}
+
+			result, err := indexMgr.RemoveWithOptions(args, opts)
+			if err != nil {
+				return fmt.Errorf("failed to remove files: %w", err)
+			}
+
+			if len(result.Failed) > 0 {
+				hint := false
+				for _, failure := range result.Failed {
+					fmt.Printf("%s '%s' %s\n", ui.Red("error:"), failure.Path, failure.Reason)
+					switch failure.Reason {
+					case index.ReasonLocalChanges, index.ReasonStagedChanges, index.ReasonStagedAndLocal:
+						hint = true
+					}
+				}
+				if hint {
+					fmt.Println(ui.Yellow("(use --cached to keep the file, or -f to force removal)"))
+				}
+				return fmt.Errorf("no files were removed")
+			}
+
+			for _, path := range result.Removed {
+				fmt.Printf("rm '%s'\n", path)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.Cached, "cached", false, "Only remove from the index, keeping the working tree files")
+	cmd.Flags().BoolVarP(&opts.Recursive, "recursive", "r", false, "Allow recursive removal when a directory is given")
+	cmd.Flags().BoolVarP(&opts.Force, "force", "f", false, "Override the up-to-date check")
+	cmd.Flags().BoolVarP(&opts.DryRun, "dry-run", "n", false, "Show what would be removed without removing it")
+
+	return cmd
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/utkarsh5026/SourceControl/cmd/ui"
+	"github.com/utkarsh5026/SourceControl/pkg/index"
+	"github.com/utkarsh5026/SourceControl/pkg/merge"
+	"github.com/utkarsh5026/SourceControl/pkg/plumbing"
+	"github.com/utkarsh5026/SourceControl/pkg/refs/branch"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/sourcerepo"
+)
+
+// unmergedLabels describes how an unmerged path conflicts, by its status
+// codes.
+var unmergedLabels = map[string]string{
+	"DD": "both deleted:",
+	"AU": "added by us:",
+	"UD": "deleted by them:",
+	"UA": "added by them:",
+	"DU": "deleted by us:",
+	"AA": "both added:",
+	"UU": "both modified:",
+}
+
+func newStatusCmd() *cobra.Command {
+	var short, nullTerminated, showBranch, showIgnored bool
+	var porcelain, untracked string
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the working directory status",
+		Long: `Show the status of the working directory and staging area.
+Displays the changes staged for the next commit (the index compared with
+HEAD), unmerged paths, changes not yet staged (the working tree compared
+with the index), and untracked files.
+
+--short and --porcelain print one line per path for scripts and editors;
+--porcelain=v2 adds each path's modes and hashes.`,
+		Example: `  # Compact output, with the branch
+  srcc status -sb
+
+  # Machine-readable output, NUL-terminated
+  srcc status --porcelain=v2 -z
+
+  # List every untracked file, and ignored ones too
+  srcc status -uall --ignored`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var opts index.StatusOptions
+			switch untracked {
+			case "no":
+				opts.Untracked = index.UntrackedNo
+			case "normal":
+				opts.Untracked = index.UntrackedNormal
+			case "all":
+				opts.Untracked = index.UntrackedAll
+			default:
+				return fmt.Errorf("invalid untracked files mode '%s' (use no, normal or all)", untracked)
+			}
+			opts.Ignored = showIgnored
+
+			format := plumbing.StatusShort
+			machine := short || nullTerminated || cmd.Flags().Changed("porcelain")
+			switch porcelain {
+			case "", "v1", "1":
+				if porcelain != "" {
+					format = plumbing.StatusPorcelainV1
+				}
+			case "v2", "2":
+				format = plumbing.StatusPorcelainV2
+			default:
+				return fmt.Errorf("unsupported porcelain version '%s'", porcelain)
+			}
+
+			repo, err := findRepository()
+			if err != nil {
+				return err
+			}
+			if opts.Head, err = headEntries(repo); err != nil {
+				return err
+			}
+
+			indexMgr := index.NewManager(repo.WorkingDirectory())
+			if err := indexMgr.Initialize(); err != nil {
+				return fmt.Errorf("failed to initialize index: %w", err)
+			}
+			status, err := indexMgr.StatusWithOptions(opts)
+			if err != nil {
+				return fmt.Errorf("failed to get status: %w", err)
+			}
+
+			if machine {
+				statusOpts := plumbing.StatusOptions{
+					Format:         format,
+					NullTerminated: nullTerminated,
+					HashAlgorithm:  repo.ObjectStore().HashAlgorithm(),
+				}
+				if showBranch {
+					statusOpts.Branch = statusBranch(repo)
+				}
+				return plumbing.WriteStatus(os.Stdout, status, statusOpts)
+			}
+
+			printLongStatus(repo, status, opts.Untracked != index.UntrackedNo)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&short, "short", "s", false, "Give the output in the short format")
+	cmd.Flags().StringVar(&porcelain, "porcelain", "", "Give the output in a stable format for scripts (v1 or v2)")
+	cmd.Flags().Lookup("porcelain").NoOptDefVal = "v1"
+	cmd.Flags().BoolVarP(&nullTerminated, "null", "z", false, "Terminate entries with NUL and do not quote paths; implies --porcelain")
+	cmd.Flags().BoolVarP(&showBranch, "branch", "b", false, "Show the branch in the short and porcelain formats")
+	cmd.Flags().BoolVar(&showIgnored, "ignored", false, "Show ignored files as well")
+	cmd.Flags().StringVarP(&untracked, "untracked-files", "u", "normal", "Show untracked files: no, normal or all (e.g. -uno)")
+
+	return cmd
+}
+
+// statusBranch describes HEAD for the --branch header.
+func statusBranch(repo *sourcerepo.SourceRepository) *plumbing.StatusBranch {
+	info := &plumbing.StatusBranch{}
+	mgr := branch.NewManager(repo)
+	if detached, err := mgr.IsDetached(); err == nil && !detached {
+		info.Name, _ = mgr.CurrentBranch()
+	}
+	if commit, err := plumbing.NewResolver(repo).Resolve("HEAD"); err == nil {
+		info.Commit = commit
+	}
+	return info
+}
+
+// printLongStatus prints status in the human-readable format, section by
+// section.
+func printLongStatus(repo *sourcerepo.SourceRepository, status *index.StatusResult, showUntracked bool) {
+	branchName, err := getCurrentBranchName(repo)
+	if err != nil {
+		branchName = branch.DefaultBranch
+	}
+
+	fmt.Println(ui.Header(" Repository Status "))
+	fmt.Println(ui.BranchInfo(branchName))
+	fmt.Println()
+
+	if merge.NewMergeState(repo).InProgress() {
+		if len(status.Unmerged) > 0 {
+			fmt.Println(ui.Yellow("You have unmerged paths."))
+			fmt.Println(`  (fix conflicts and run "srcc commit")`)
+		} else {
+			fmt.Println(ui.Yellow("All conflicts fixed but you are still merging."))
+			fmt.Println(`  (use "srcc commit" to conclude merge)`)
+		}
+		fmt.Println()
+	}
+
+	staged := len(status.Staged.Added) + len(status.Staged.Modified) + len(status.Staged.Deleted)
+	if staged > 0 {
+		fmt.Println(ui.Section("Changes to be committed:"))
+		for _, entry := range status.Entries {
+			switch {
+			case entry.IsUnmerged():
+			case entry.Staged == index.StatusAdded:
+				fmt.Println(ui.FormatAdded(statusLine("new file:", entry.Path)))
+			case entry.Staged == index.StatusDeleted:
+				fmt.Println(ui.FormatDeleted(statusLine("deleted:", entry.Path)))
+			case entry.Staged == index.StatusTypeChanged:
+				fmt.Println(ui.FormatModified(statusLine("typechange:", entry.Path)))
+			case entry.Staged == index.StatusModified:
+				fmt.Println(ui.FormatModified(statusLine("modified:", entry.Path)))
+			}
+		}
+		fmt.Println()
+	}
+
+	if len(status.Unmerged) > 0 {
+		fmt.Println(ui.Section("Unmerged paths:"))
+		for _, entry := range status.Entries {
+			if entry.IsUnmerged() {
+				label := unmergedLabels[string([]byte{entry.Staged, entry.Unstaged})]
+				fmt.Println(ui.FormatDeleted(statusLine(label, entry.Path)))
+			}
+		}
+		fmt.Println()
+	}
+
+	unstaged := 0
+	for _, entry := range status.Entries {
+		if !entry.IsUnmerged() && entry.Unstaged != index.StatusUnmodified {
+			unstaged++
+		}
+	}
+	if unstaged > 0 {
+		fmt.Println(ui.Section("Changes not staged for commit:"))
+		for _, entry := range status.Entries {
+			switch {
+			case entry.IsUnmerged():
+			case entry.Unstaged == index.StatusAdded:
+				fmt.Println(ui.FormatAdded(statusLine("new file:", entry.Path)))
+			case entry.Unstaged == index.StatusDeleted:
+				fmt.Println(ui.FormatDeleted(statusLine("deleted:", entry.Path)))
+			case entry.Unstaged == index.StatusTypeChanged:
+				fmt.Println(ui.FormatModified(statusLine("typechange:", entry.Path)))
+			case entry.Unstaged == index.StatusModified && entry.WorktreeMode.IsGitlink():
+				fmt.Println(ui.FormatModified(statusLine("modified:", entry.Path+" (new commits)")))
+			case entry.Unstaged == index.StatusModified:
+				fmt.Println(ui.FormatModified(statusLine("modified:", entry.Path)))
+			}
+		}
+		fmt.Println()
+	}
+
+	if len(status.Untracked) > 0 {
+		fmt.Println(ui.Section("Untracked files:"))
+		for _, path := range status.Untracked {
+			fmt.Println(ui.FormatUntracked(path))
+		}
+		fmt.Println()
+	}
+
+	if len(status.Ignored) > 0 {
+		fmt.Println(ui.Section("Ignored files:"))
+		for _, path := range status.Ignored {
+			fmt.Println(ui.FormatUntracked(path))
+		}
+		fmt.Println()
+	}
+
+	switch {
+	case staged > 0 || len(status.Unmerged) > 0:
+	case unstaged > 0:
+		fmt.Println(ui.Yellow("  💡 Use 'srcc add <file>' to stage changes for commit"))
+	case len(status.Untracked) > 0:
+		fmt.Println(ui.Yellow("  💡 Nothing added to commit but untracked files present (use 'srcc add' to track)"))
+	case showUntracked:
+		fmt.Println(ui.Green(fmt.Sprintf("  %s  Working tree clean - nothing to commit", ui.IconCheck)))
+	default:
+		fmt.Println(ui.Green(fmt.Sprintf("  %s  Nothing to commit (untracked files not listed)", ui.IconCheck)))
+	}
+}
+
+// statusLine pads label so that paths line up, as git status does.
+func statusLine(label, path string) string {
+	return fmt.Sprintf("%-16s %s", label, path)
+}
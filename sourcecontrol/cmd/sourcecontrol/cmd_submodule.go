@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/utkarsh5026/SourceControl/pkg/submodule"
+)
+
+func newSubmoduleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "submodule",
+		Short: "Initialize, update or inspect submodules",
+		Long: `Manage repositories nested in the working tree as submodules. Each is
+recorded in .gitmodules and staged as a gitlink to the commit checked out
+in it. Only local repositories can be used as sources.`,
+	}
+	cmd.AddCommand(newSubmoduleAddCmd(), newSubmoduleInitCmd(), newSubmoduleUpdateCmd(),
+		newSubmoduleStatusCmd(), newSubmoduleForeachCmd())
+	return cmd
+}
+
+func newSubmoduleAddCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "add <repository> [<path>]",
+		Short: "Clone a repository and add it as a submodule",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := findRepository()
+			if err != nil {
+				return err
+			}
+			path := ""
+			if len(args) == 2 {
+				path = args[1]
+			}
+			_, err = submodule.NewManager(repo).Add(context.Background(), args[0], path)
+			return err
+		},
+	}
+}
+
+func newSubmoduleInitCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "init [<path>...]",
+		Short: "Record the URLs of submodules in the configuration",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := findRepository()
+			if err != nil {
+				return err
+			}
+			subs, err := submodule.NewManager(repo).Init(args)
+			for _, s := range subs {
+				fmt.Printf("Submodule '%s' (%s) registered for path '%s'\n", s.Name, s.URL, s.Path)
+			}
+			return err
+		},
+	}
+}
+
+func newSubmoduleUpdateCmd() *cobra.Command {
+	var init bool
+	cmd := &cobra.Command{
+		Use:   "update [--init] [<path>...]",
+		Short: "Check out the recorded commit of each submodule",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := findRepository()
+			if err != nil {
+				return err
+			}
+			updated, err := submodule.NewManager(repo).Update(context.Background(), args, init)
+			for _, u := range updated {
+				if u.Cloned {
+					fmt.Printf("Cloned into '%s'\n", u.Path)
+				}
+				fmt.Printf("Submodule path '%s': checked out '%s'\n", u.Path, u.Commit)
+			}
+			return err
+		},
+	}
+	cmd.Flags().BoolVar(&init, "init", false, "Initialize submodules that are not yet")
+	return cmd
+}
+
+func newSubmoduleStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status [<path>...]",
+		Short: "Show the commit checked out in each submodule",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := findRepository()
+			if err != nil {
+				return err
+			}
+			statuses, err := submodule.NewManager(repo).Status(args)
+			if err != nil {
+				return err
+			}
+			for _, s := range statuses {
+				commit := s.Head
+				if commit == "" {
+					commit = s.Recorded
+				}
+				fmt.Printf("%c%s %s\n", s.Code, commit, s.Path)
+			}
+			return nil
+		},
+	}
+}
+
+func newSubmoduleForeachCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "foreach <command>",
+		Short: "Run a shell command in each checked out submodule",
+		Long: `Run a shell command in each checked out submodule. The command can use
+$name, $sm_path, $displaypath, $sha1 and $toplevel.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := findRepository()
+			if err != nil {
+				return err
+			}
+			return submodule.NewManager(repo).Foreach(context.Background(), args[0],
+				submodule.ForeachOptions{Stdout: os.Stdout, Stderr: os.Stderr})
+		},
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/utkarsh5026/SourceControl/pkg/index"
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/plumbing"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/sourcerepo"
+)
+
+func newUpdateIndexCmd() *cobra.Command {
+	var opts plumbing.UpdateIndexOptions
+	var cacheInfo []string
+	var indexInfo, refresh, quiet bool
+
+	cmd := &cobra.Command{
+		Use:   "update-index [--add] [--remove | --force-remove] [--info-only] [--cacheinfo <mode>,<object>,<path>]... [--index-info] [--refresh [-q]] [<file>...]",
+		Short: "Register file contents in the working tree to the index",
+		Long: `Stage the working directory's version of each file.
+
+  --add               allow files not yet in the index
+  --remove            remove files that no longer exist
+  --force-remove      remove files from the index even if they exist
+  --info-only         record object IDs without writing or checking objects
+  --cacheinfo m,o,p   stage object o with mode m at path p without a file;
+                      also accepted as --cacheinfo m o p, taking o and p
+                      from the first arguments
+  --index-info        read "<mode> <object>\t<path>" lines (the output of
+                      ls-tree or ls-files --stage) from standard input
+  --refresh           re-read the stat information of files whose content
+                      is unchanged, reporting those that need updating
+
+Examples:
+  srcc update-index --add README.md
+  srcc update-index --cacheinfo 100644,ce01362,README.md
+  srcc update-index --add --cacheinfo 100644 ce01362 NEWS.md
+  srcc ls-tree -r HEAD | srcc update-index --index-info
+  srcc update-index --refresh`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := findRepository()
+			if err != nil {
+				return err
+			}
+			infos, args, err := splitCacheInfo(cacheInfo, args)
+			if err != nil {
+				return err
+			}
+			paths := make([]scpath.RelativePath, len(args))
+			for i, arg := range args {
+				if paths[i], err = repoRelativePath(repo, arg); err != nil {
+					return err
+				}
+			}
+
+			var refreshed *index.RefreshResult
+			indexPath := repo.SourceDirectory().IndexPath().ToAbsolutePath()
+			err = index.Update(indexPath, func(idx *index.Index) error {
+				for _, info := range infos {
+					if err := applyCacheInfo(repo, idx, info, opts); err != nil {
+						return err
+					}
+				}
+				if indexInfo {
+					if err := plumbing.IndexInfo(idx, os.Stdin, repo.HashAlgorithm()); err != nil {
+						return err
+					}
+				}
+				if err := plumbing.UpdateIndex(repo, idx, paths, opts); err != nil {
+					return err
+				}
+				if refresh {
+					refreshed = idx.Refresh(repo.WorkingDirectory())
+				}
+				return nil
+			})
+			if err != nil || refreshed == nil || quiet {
+				return err
+			}
+
+			for _, path := range refreshed.NeedsMerge {
+				fmt.Printf("%s: needs merge\n", path)
+			}
+			for _, path := range refreshed.NeedsUpdate {
+				fmt.Printf("%s: needs update\n", path)
+			}
+			if len(refreshed.NeedsMerge)+len(refreshed.NeedsUpdate) > 0 {
+				os.Exit(1)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.Add, "add", false, "Add files not yet in the index")
+	cmd.Flags().BoolVar(&opts.Remove, "remove", false, "Remove files missing from the working directory")
+	cmd.Flags().BoolVar(&opts.ForceRemove, "force-remove", false, "Remove files from the index even if they exist")
+	cmd.Flags().BoolVar(&opts.InfoOnly, "info-only", false, "Record object IDs without writing or checking objects")
+	cmd.Flags().StringArrayVar(&cacheInfo, "cacheinfo", nil, "Stage <mode>,<object>,<path> directly")
+	cmd.Flags().BoolVar(&indexInfo, "index-info", false, "Read index entries from standard input")
+	cmd.Flags().BoolVar(&refresh, "refresh", false, "Refresh the stat information of unchanged files")
+	cmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "With --refresh, do not report files that need updating")
+
+	return cmd
+}
+
+// splitCacheInfo turns the --cacheinfo values into <mode>,<object>,<path>
+// triples. A value without a comma is the mode of Git's older form,
+// --cacheinfo <mode> <object> <path>; its object and path are taken, in
+// order, from the front of args, and the remaining args are returned.
+func splitCacheInfo(values, args []string) ([][3]string, []string, error) {
+	infos := make([][3]string, 0, len(values))
+	for _, value := range values {
+		if !strings.Contains(value, ",") {
+			if len(args) < 2 {
+				return nil, nil, fmt.Errorf("--cacheinfo %s expects <object> <path> to follow", value)
+			}
+			infos = append(infos, [3]string{value, args[0], args[1]})
+			args = args[2:]
+			continue
+		}
+		parts := strings.SplitN(value, ",", 3)
+		if len(parts) != 3 {
+			return nil, nil, fmt.Errorf("--cacheinfo expects <mode>,<object>,<path>, got %q", value)
+		}
+		infos = append(infos, [3]string(parts))
+	}
+	return infos, args, nil
+}
+
+// applyCacheInfo stages one --cacheinfo <mode>,<object>,<path> triple.
+func applyCacheInfo(repo *sourcerepo.SourceRepository, idx *index.Index, info [3]string, opts plumbing.UpdateIndexOptions) error {
+	mode, err := objects.FromOctalString(info[0])
+	if err != nil {
+		return err
+	}
+	hash, err := objects.ParseObjectHash(info[1])
+	if err != nil {
+		return err
+	}
+	return plumbing.CacheInfo(repo.ObjectStore(), idx, mode, hash, scpath.RelativePath(info[2]), opts)
+}
+
+// repoRelativePath turns a path given on the command line, relative to the
+// current directory, into a path relative to the repository root.
+func repoRelativePath(repo *sourcerepo.SourceRepository, arg string) (scpath.RelativePath, error) {
+	abs, err := filepath.Abs(arg)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(repo.WorkingDirectory().String(), abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s: outside repository", arg)
+	}
+	return scpath.RelativePath(filepath.ToSlash(rel)), nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/utkarsh5026/SourceControl/cmd/ui"
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/sourcerepo"
+)
+
+func newInitCmd() *cobra.Command {
+	var bare bool
+	var objectFormat string
+
+	cmd := &cobra.Command{
+		Use:   "init [path]",
+		Short: "Initialize a new SourceControl repository",
+		Long: `Initialize a new SourceControl repository in the current directory or specified path.
+This creates a .git directory with all necessary subdirectories and files.
+
+Use --object-format=sha256 to name objects with SHA-256 instead of SHA-1.
+The choice is recorded in the repository config and cannot be changed later.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "."
+			if len(args) > 0 {
+				path = args[0]
+			}
+
+			absPath, err := filepath.Abs(path)
+			if err != nil {
+				return fmt.Errorf("failed to resolve path: %w", err)
+			}
+
+			repoPath, err := scpath.NewRepositoryPath(absPath)
+			if err != nil {
+				return fmt.Errorf("invalid path: %w", err)
+			}
+
+			algorithm, err := objects.ParseHashAlgorithm(objectFormat)
+			if err != nil {
+				return err
+			}
+
+			repo := sourcerepo.NewSourceRepository()
+			if err := repo.InitializeWithHashAlgorithm(repoPath, algorithm); err != nil {
+				return fmt.Errorf("failed to initialize repository: %w", err)
+			}
+
+			message := "Initialized empty SourceControl repository in"
+			if bare {
+				message = "Initialized empty bare SourceControl repository in"
+			}
+
+			displayPath := fmt.Sprintf("%s/%s", absPath, scpath.SourceDir)
+			fmt.Println(ui.SuccessMessage(message, displayPath))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&bare, "bare", false, "Create a bare repository")
+	cmd.Flags().StringVar(&objectFormat, "object-format", objects.DefaultHashAlgorithm.String(), "Hash algorithm for object names (sha1 or sha256)")
+
+	return cmd
+}
@@ -0,0 +1,381 @@
+// Package clone creates a new repository from an existing local one, either
+// copying its objects or borrowing them through alternates.
+package clone
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/utkarsh5026/SourceControl/pkg/common/logger"
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/refs"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/sourcerepo"
+	"github.com/utkarsh5026/SourceControl/pkg/store"
+	"github.com/utkarsh5026/SourceControl/pkg/workdir"
+)
+
+// DefaultRemote is the name the source repository is recorded under.
+const DefaultRemote = "origin"
+
+// ErrDestinationNotEmpty is returned when the clone target exists and
+// contains files.
+var ErrDestinationNotEmpty = errors.New("destination path already exists and is not an empty directory")
+
+// Options configures a clone.
+type Options struct {
+	// Source is the working directory of the repository to clone
+	Source scpath.RepositoryPath
+
+	// Destination is where the new repository is created
+	Destination scpath.RepositoryPath
+
+	// Shared borrows every object from Source through an alternate instead
+	// of copying it (git clone --shared)
+	Shared bool
+
+	// References are repositories whose objects are borrowed through
+	// alternates; only objects they lack are copied from Source
+	// (git clone --reference)
+	References []scpath.RepositoryPath
+
+	// NoCheckout leaves the working directory empty
+	NoCheckout bool
+}
+
+// Result describes a finished clone.
+type Result struct {
+	// Repository is the new repository
+	Repository *sourcerepo.SourceRepository
+
+	// Branch is the branch checked out, empty if HEAD is detached
+	Branch string
+
+	// Head is the commit checked out, empty if Source has no commits
+	Head objects.ObjectHash
+
+	// ObjectsCopied counts objects written to the new repository's own
+	// object directory (loose objects and packed objects alike)
+	ObjectsCopied int
+
+	// Alternates lists the object directories the new repository borrows from
+	Alternates []scpath.AbsolutePath
+}
+
+// Clone creates a repository at opts.Destination from the local repository
+// at opts.Source.
+//
+// Objects are made available in one of three ways:
+//
+//	default      copy loose objects and packs from Source
+//	--shared     borrow everything from Source's object directory
+//	--reference  borrow from the reference repositories, copy the rest
+//
+// The branches of Source become remote-tracking branches of DefaultRemote,
+// tags are copied as they are, and the branch Source's HEAD points to is
+// created locally and checked out.
+//
+// Parameters:
+//   - ctx: Context for cancellation of the checkout
+//   - opts: The source, destination and object sharing mode
+//
+// Returns:
+//   - *Result: The new repository and what was done to create it
+//   - error: If Source is not a repository, Destination is not empty, or any
+//     step fails
+func Clone(ctx context.Context, opts Options) (*Result, error) {
+	log := logger.With("component", "clone")
+
+	src, err := sourcerepo.Open(opts.Source)
+	if err != nil {
+		return nil, fmt.Errorf("open source: %w", err)
+	}
+	srcStore, ok := src.ObjectStore().(*store.FileObjectStore)
+	if !ok {
+		return nil, fmt.Errorf("source repository does not use a file object store")
+	}
+
+	if err := checkDestination(opts.Destination); err != nil {
+		return nil, err
+	}
+
+	dst := sourcerepo.NewSourceRepository()
+	if err := dst.InitializeWithHashAlgorithm(opts.Destination, src.HashAlgorithm()); err != nil {
+		return nil, fmt.Errorf("initialize destination: %w", err)
+	}
+	dstStore := dst.ObjectStore().(*store.FileObjectStore)
+
+	result := &Result{Repository: dst}
+	if err := setUpObjects(srcStore, dstStore, opts, result); err != nil {
+		return nil, err
+	}
+	result.Alternates = dstStore.Alternates()
+
+	branch, head, err := copyRefs(src, dst)
+	if err != nil {
+		return nil, err
+	}
+	result.Branch, result.Head = branch, head
+
+	if err := writeRemoteConfig(dst, opts.Source, branch); err != nil {
+		return nil, err
+	}
+
+	log.Info("cloned repository",
+		"source", opts.Source,
+		"shared", opts.Shared,
+		"references", len(opts.References),
+		"copied", result.ObjectsCopied)
+
+	if head == "" || opts.NoCheckout {
+		return result, nil
+	}
+
+	wd := workdir.NewManager(dst)
+	if _, err := wd.UpdateToCommit(ctx, head, workdir.WithForce()); err != nil {
+		return nil, fmt.Errorf("checkout %s: %w", head.Short(), err)
+	}
+	return result, nil
+}
+
+// checkDestination fails unless path is missing or an empty directory.
+func checkDestination(path scpath.RepositoryPath) error {
+	entries, err := os.ReadDir(path.String())
+	if os.IsNotExist(err) {
+		return os.MkdirAll(path.String(), 0755)
+	}
+	if err != nil {
+		return fmt.Errorf("check destination: %w", err)
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("%w: %s", ErrDestinationNotEmpty, path)
+	}
+	return nil
+}
+
+// setUpObjects gives the new repository access to every object of the
+// source, according to the sharing mode.
+func setUpObjects(src, dst *store.FileObjectStore, opts Options, result *Result) error {
+	if opts.Shared {
+		return dst.AddAlternate(src.GetObjectsPath().ToAbsolutePath())
+	}
+
+	for _, ref := range opts.References {
+		refRepo, err := sourcerepo.Open(ref)
+		if err != nil {
+			return fmt.Errorf("reference repository %s: %w", ref, err)
+		}
+		if err := dst.AddAlternate(refRepo.ObjectsPath().ToAbsolutePath()); err != nil {
+			return fmt.Errorf("reference repository %s: %w", ref, err)
+		}
+	}
+
+	// Objects the source itself borrows must stay reachable from the clone
+	for _, alt := range src.Alternates() {
+		if err := dst.AddAlternate(alt); err != nil {
+			return err
+		}
+	}
+
+	if len(opts.References) == 0 {
+		copied, err := copyObjectFiles(src, dst)
+		result.ObjectsCopied = copied
+		return err
+	}
+
+	copied, err := copyMissingObjects(src, dst)
+	result.ObjectsCopied = copied
+	return err
+}
+
+// copyObjectFiles copies the source's loose object files and packs as they
+// are, which is much faster than decoding and re-encoding every object.
+func copyObjectFiles(src, dst *store.FileObjectStore) (int, error) {
+	srcDir := src.GetObjectsPath().String()
+	dstDir := dst.GetObjectsPath().String()
+
+	loose, err := src.LooseObjects()
+	if err != nil {
+		return 0, err
+	}
+	copied := 0
+	for _, hash := range loose {
+		rel := filepath.Join(hash.String()[:2], hash.String()[2:])
+		if err := copyFile(filepath.Join(srcDir, rel), filepath.Join(dstDir, rel), 0444); err != nil {
+			return copied, fmt.Errorf("copy object %s: %w", hash.Short(), err)
+		}
+		copied++
+	}
+
+	packs, err := src.Packs()
+	if err != nil {
+		return copied, err
+	}
+	for _, p := range packs {
+		packPath := p.Path().String()
+		idxPath := strings.TrimSuffix(packPath, ".pack") + ".idx"
+		for _, path := range []string{packPath, idxPath} {
+			target := filepath.Join(dstDir, scpath.PackDir, filepath.Base(path))
+			if err := copyFile(path, target, 0444); err != nil {
+				return copied, fmt.Errorf("copy pack %s: %w", filepath.Base(path), err)
+			}
+		}
+		copied += p.Index().Count()
+	}
+
+	return copied, nil
+}
+
+// copyMissingObjects copies, one by one, the source objects that the
+// destination cannot already read through its alternates.
+func copyMissingObjects(src, dst *store.FileObjectStore) (int, error) {
+	hashes, err := src.LooseObjects()
+	if err != nil {
+		return 0, err
+	}
+	packs, err := src.Packs()
+	if err != nil {
+		return 0, err
+	}
+	for _, p := range packs {
+		hashes = append(hashes, p.Index().Hashes()...)
+	}
+
+	copied := 0
+	for _, hash := range hashes {
+		if has, err := dst.HasObject(hash); err != nil {
+			return copied, err
+		} else if has {
+			continue
+		}
+
+		obj, err := src.ReadObject(hash)
+		if err != nil {
+			return copied, fmt.Errorf("read object %s: %w", hash.Short(), err)
+		}
+		if obj == nil {
+			continue
+		}
+		if _, err := dst.WriteObject(obj); err != nil {
+			return copied, fmt.Errorf("write object %s: %w", hash.Short(), err)
+		}
+		copied++
+	}
+	return copied, nil
+}
+
+// copyRefs copies tags, records the source's branches as remote-tracking
+// branches, and points HEAD at a local copy of the source's current branch.
+// It returns that branch (empty if the source HEAD is detached) and the
+// commit it points to (empty if it has no commits yet).
+func copyRefs(src, dst *sourcerepo.SourceRepository) (string, objects.ObjectHash, error) {
+	srcRefs := refs.NewRefManager(src)
+	dstRefs := refs.NewRefManager(dst)
+
+	all, err := srcRefs.ListRefs()
+	if err != nil {
+		return "", "", err
+	}
+	for _, ref := range all {
+		hash, err := srcRefs.ResolveToSHA(ref)
+		if err != nil {
+			continue
+		}
+
+		target := ref
+		if ref.IsBranch() {
+			if target, err = refs.NewRemoteRef(DefaultRemote, ref.ShortName()); err != nil {
+				return "", "", err
+			}
+		} else if !ref.IsTag() {
+			continue
+		}
+		if err := dstRefs.UpdateRef(target, hash); err != nil {
+			return "", "", fmt.Errorf("copy ref %s: %w", ref, err)
+		}
+	}
+
+	headContent, err := srcRefs.ReadRef(refs.RefPath(scpath.HeadFile))
+	if err != nil {
+		return "", "", err
+	}
+
+	symbolic, isSymbolic := strings.CutPrefix(strings.TrimSpace(headContent), refs.SymbolicRefPrefix)
+	if !isSymbolic {
+		head, err := objects.NewObjectHashFromString(strings.TrimSpace(headContent))
+		if err != nil {
+			return "", "", fmt.Errorf("invalid HEAD in source: %w", err)
+		}
+		return "", head, dstRefs.UpdateRef(refs.RefPath(scpath.HeadFile), head)
+	}
+
+	branchRef := refs.RefPath(symbolic)
+	headFile := dst.SourceDirectory().HeadPath().ToAbsolutePath()
+	if err := os.WriteFile(headFile.String(), []byte(refs.SymbolicRefPrefix+symbolic+"\n"), 0644); err != nil {
+		return "", "", fmt.Errorf("write HEAD: %w", err)
+	}
+
+	head, err := srcRefs.ResolveToSHA(branchRef)
+	if err != nil {
+		// The source has no commits yet: HEAD names an unborn branch
+		return branchRef.ShortName(), "", nil
+	}
+	if err := dstRefs.UpdateRef(branchRef, head); err != nil {
+		return "", "", err
+	}
+	return branchRef.ShortName(), head, nil
+}
+
+// writeRemoteConfig records the source as DefaultRemote in .git/config and
+// makes branch track it, so the clone can be inspected with git remote -v.
+func writeRemoteConfig(dst *sourcerepo.SourceRepository, source scpath.RepositoryPath, branch string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[remote %q]\n", DefaultRemote)
+	fmt.Fprintf(&b, "    url = %s\n", filepath.ToSlash(source.String()))
+	fmt.Fprintf(&b, "    fetch = +refs/heads/*:refs/remotes/%s/*\n", DefaultRemote)
+	if branch != "" {
+		fmt.Fprintf(&b, "[branch %q]\n", branch)
+		fmt.Fprintf(&b, "    remote = %s\n", DefaultRemote)
+		fmt.Fprintf(&b, "    merge = refs/heads/%s\n", branch)
+	}
+
+	configPath := dst.SourceDirectory().ConfigPath().String()
+	file, err := os.OpenFile(configPath, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+	if _, err := file.WriteString(b.String()); err != nil {
+		file.Close()
+		return fmt.Errorf("write config: %w", err)
+	}
+	return file.Close()
+}
+
+// copyFile copies src to dst, creating dst's directory, and leaves dst with
+// the given permissions.
+func copyFile(src, dst string, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
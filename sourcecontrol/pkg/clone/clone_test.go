@@ -0,0 +1,214 @@
+package clone
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/blob"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/commit"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/tree"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/refs"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/sourcerepo"
+	"github.com/utkarsh5026/SourceControl/pkg/store"
+)
+
+func setupTestRepo(t *testing.T) *sourcerepo.SourceRepository {
+	t.Helper()
+
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", t.TempDir())
+
+	repo := sourcerepo.NewSourceRepository()
+	if err := repo.Initialize(scpath.RepositoryPath(t.TempDir())); err != nil {
+		t.Fatalf("failed to initialize repo: %v", err)
+	}
+	return repo
+}
+
+// writeCommit commits a single file with the given content on master.
+func writeCommit(t *testing.T, repo *sourcerepo.SourceRepository, content string) objects.ObjectHash {
+	t.Helper()
+
+	blobHash, err := repo.WriteObject(blob.NewBlob([]byte(content)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, err := tree.NewTreeEntry(objects.FileModeRegular, "file.txt", blobHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	treeHash, err := repo.WriteObject(tree.NewTree([]*tree.TreeEntry{entry}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	person, err := commit.NewCommitPerson("Test User", "test@example.com", time.Unix(1700000000, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := commit.NewCommitBuilder().
+		TreeHash(treeHash).
+		Author(person).
+		Committer(person).
+		Message("commit").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := repo.WriteObject(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := refs.NewRefManager(repo).UpdateRef("refs/heads/master", hash); err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
+
+func localObjects(t *testing.T, repo *sourcerepo.SourceRepository) int {
+	t.Helper()
+	count, err := repo.ObjectStore().(*store.FileObjectStore).ObjectCount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func checkCheckout(t *testing.T, result *Result, want objects.ObjectHash, content string) {
+	t.Helper()
+
+	if result.Branch != "master" || result.Head != want {
+		t.Errorf("cloned %s at %s, want master at %s", result.Branch, result.Head, want)
+	}
+	data, err := os.ReadFile(filepath.Join(result.Repository.WorkingDirectory().String(), "file.txt"))
+	if err != nil {
+		t.Fatalf("file.txt not checked out: %v", err)
+	}
+	if string(data) != content {
+		t.Errorf("file.txt = %q, want %q", data, content)
+	}
+
+	rm := refs.NewRefManager(result.Repository)
+	if hash, err := rm.ResolveToSHA("refs/remotes/origin/master"); err != nil || hash != want {
+		t.Errorf("origin/master = %s, %v; want %s", hash, err, want)
+	}
+	if hash, err := rm.ResolveToSHA(refs.RefPath(scpath.HeadFile)); err != nil || hash != want {
+		t.Errorf("HEAD = %s, %v; want %s", hash, err, want)
+	}
+}
+
+func TestClone_Copy(t *testing.T) {
+	src := setupTestRepo(t)
+	head := writeCommit(t, src, "hello\n")
+	dest := scpath.RepositoryPath(filepath.Join(t.TempDir(), "copy"))
+
+	result, err := Clone(context.Background(), Options{Source: src.WorkingDirectory(), Destination: dest})
+	if err != nil {
+		t.Fatalf("Clone() failed: %v", err)
+	}
+
+	checkCheckout(t, result, head, "hello\n")
+	if result.ObjectsCopied != 3 || localObjects(t, result.Repository) != 3 {
+		t.Errorf("copied %d objects, want 3", result.ObjectsCopied)
+	}
+	if len(result.Alternates) != 0 {
+		t.Errorf("Alternates = %v, want none", result.Alternates)
+	}
+
+	config, err := os.ReadFile(result.Repository.SourceDirectory().ConfigPath().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(config), `[remote "origin"]`) || !strings.Contains(string(config), `[branch "master"]`) {
+		t.Errorf("config does not record origin:\n%s", config)
+	}
+}
+
+func TestClone_Shared(t *testing.T) {
+	src := setupTestRepo(t)
+	head := writeCommit(t, src, "shared\n")
+	dest := scpath.RepositoryPath(filepath.Join(t.TempDir(), "shared"))
+
+	result, err := Clone(context.Background(), Options{Source: src.WorkingDirectory(), Destination: dest, Shared: true})
+	if err != nil {
+		t.Fatalf("Clone() failed: %v", err)
+	}
+
+	checkCheckout(t, result, head, "shared\n")
+	if result.ObjectsCopied != 0 || localObjects(t, result.Repository) != 0 {
+		t.Errorf("shared clone has local objects")
+	}
+	if len(result.Alternates) != 1 {
+		t.Fatalf("Alternates = %v, want the source objects directory", result.Alternates)
+	}
+
+	// Reopening the clone must find the objects through objects/info/alternates
+	reopened, err := sourcerepo.Open(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reopened.ReadCommitObject(head); err != nil {
+		t.Errorf("reopened shared clone cannot read HEAD: %v", err)
+	}
+}
+
+func TestClone_Reference(t *testing.T) {
+	reference := setupTestRepo(t)
+	writeCommit(t, reference, "base\n")
+
+	src := setupTestRepo(t)
+	writeCommit(t, src, "base\n")
+	head := writeCommit(t, src, "base\nmore\n")
+
+	dest := scpath.RepositoryPath(filepath.Join(t.TempDir(), "reference"))
+	result, err := Clone(context.Background(), Options{
+		Source:      src.WorkingDirectory(),
+		Destination: dest,
+		References:  []scpath.RepositoryPath{reference.WorkingDirectory()},
+	})
+	if err != nil {
+		t.Fatalf("Clone() failed: %v", err)
+	}
+
+	checkCheckout(t, result, head, "base\nmore\n")
+
+	// The reference already has the first blob, tree and commit
+	if result.ObjectsCopied != 3 || localObjects(t, result.Repository) != 3 {
+		t.Errorf("copied %d objects, want 3", result.ObjectsCopied)
+	}
+}
+
+func TestClone_EmptySource(t *testing.T) {
+	src := setupTestRepo(t)
+	dest := scpath.RepositoryPath(filepath.Join(t.TempDir(), "empty"))
+
+	result, err := Clone(context.Background(), Options{Source: src.WorkingDirectory(), Destination: dest})
+	if err != nil {
+		t.Fatalf("Clone() failed: %v", err)
+	}
+	if result.Head != "" || result.Branch != "master" {
+		t.Errorf("cloned %s at %q, want unborn master", result.Branch, result.Head)
+	}
+}
+
+func TestClone_DestinationNotEmpty(t *testing.T) {
+	src := setupTestRepo(t)
+	writeCommit(t, src, "hello\n")
+
+	dest := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dest, "existing"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Clone(context.Background(), Options{Source: src.WorkingDirectory(), Destination: scpath.RepositoryPath(dest)})
+	if !errors.Is(err, ErrDestinationNotEmpty) {
+		t.Errorf("Clone() error = %v, want ErrDestinationNotEmpty", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/utkarsh5026/SourceControl/pkg/index"
 	"github.com/utkarsh5026/SourceControl/pkg/objects"
 )
 
@@ -15,12 +16,16 @@ import (
 //   - subdirs: Child directories (name -> directoryNode)
 //
 // This structure is built up from flat paths and then recursively converted
-// into Git tree objects.
+// into Git tree objects. Once a node's tree is written, cacheTree records it
+// for the index's cache-tree extension.
 type directoryNode struct {
-	name    string                        // Directory name (empty for root)
-	files   map[string]objects.ObjectHash // filename -> blob SHA
-	modes   map[string]objects.FileMode   // filename -> file mode
-	subdirs map[string]*directoryNode     // dirname -> subdirectory node
+	name       string                        // Directory name (empty for root)
+	files      map[string]objects.ObjectHash // filename -> blob SHA
+	modes      map[string]objects.FileMode   // filename -> file mode
+	subdirs    map[string]*directoryNode     // dirname -> subdirectory node
+	entryCount int                           // index entries at or below this directory
+	incomplete bool                          // holds an intent-to-add entry, so its tree is not cacheable
+	cacheTree  *index.CacheTree              // cache tree node for the written tree
 }
 
 // newDirectoryNode creates a new directory node with initialized maps
@@ -40,6 +45,7 @@ func newDirectoryNode(name string) *directoryNode {
 //  2. If only one part, add as file to current directory
 //  3. Otherwise, create/get subdirectory "src" and recurse with "utils/helper.go"
 func (dn *directoryNode) addEntry(path string, sha objects.ObjectHash, mode objects.FileMode) {
+	dn.entryCount++
 	parts := strings.Split(filepath.ToSlash(path), "/")
 
 	if len(parts) == 1 {
@@ -54,6 +60,16 @@ func (dn *directoryNode) addEntry(path string, sha objects.ObjectHash, mode obje
 	subdir.addEntry(restOfPath, sha, mode)
 }
 
+// markIncomplete flags every existing directory on path as holding an entry
+// that is left out of its tree. No directories are created.
+func (dn *directoryNode) markIncomplete(path string) {
+	dn.incomplete = true
+	dir, rest, isDir := strings.Cut(filepath.ToSlash(path), "/")
+	if subdir, exists := dn.subdirs[dir]; isDir && exists {
+		subdir.markIncomplete(rest)
+	}
+}
+
 // addFile adds a file to this directory node
 func (dn *directoryNode) addFile(name string, sha objects.ObjectHash, mode objects.FileMode) {
 	dn.files[name] = sha
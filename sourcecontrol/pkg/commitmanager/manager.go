@@ -15,6 +15,7 @@ import (
 	"github.com/utkarsh5026/SourceControl/pkg/objects/commit"
 	"github.com/utkarsh5026/SourceControl/pkg/refs/branch"
 	"github.com/utkarsh5026/SourceControl/pkg/repository/refs"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
 	"github.com/utkarsh5026/SourceControl/pkg/repository/sourcerepo"
 )
 
@@ -106,8 +107,8 @@ func (m *Manager) Initialize(ctx context.Context) error {
 //
 // This method performs the complete commit creation workflow:
 //  1. Validates the commit options
-//  2. Reads the index to get staged changes
-//  3. Builds a tree from the index
+//  2. Locks and reads the index to get staged changes
+//  3. Builds a tree from the index and saves its cache tree
 //  4. Determines parent commits
 //  5. Creates the commit object
 //  6. Updates the current branch reference
@@ -123,6 +124,12 @@ func (m *Manager) CreateCommit(ctx context.Context, options CommitOptions) (*com
 		return nil, err
 	}
 
+	lock, err := index.LockIndex(m.indexPath())
+	if err != nil {
+		return nil, NewCommitError("lock index", err, "")
+	}
+	defer lock.Rollback()
+
 	idx, err := m.readIndex(options.AllowEmpty)
 	if err != nil {
 		return nil, err
@@ -133,6 +140,12 @@ func (m *Manager) CreateCommit(ctx context.Context, options CommitOptions) (*com
 		return nil, NewCommitError("build tree", err, "")
 	}
 
+	// Keep the trees just written in the index's cache tree, so that the
+	// next commit only rebuilds the directories that change
+	if err := lock.Commit(idx); err != nil {
+		m.logger.Warn("failed to save cache tree", "error", err)
+	}
+
 	parentSHAs, err := m.getParentCommits(ctx, options.Amend)
 	if err != nil {
 		return nil, NewCommitError("get parents", err, "")
@@ -163,9 +176,14 @@ func (m *Manager) CreateCommit(ctx context.Context, options CommitOptions) (*com
 	return commitObj, nil
 }
 
+// indexPath returns the path of the repository's index file.
+func (m *Manager) indexPath() scpath.AbsolutePath {
+	return m.repo.SourceDirectory().IndexPath().ToAbsolutePath()
+}
+
 func (m *Manager) readIndex(allowEmpty bool) (*index.Index, error) {
-	indexPath := m.repo.SourceDirectory().IndexPath()
-	idx, err := index.Read(indexPath.ToAbsolutePath())
+	indexPath := m.indexPath()
+	idx, err := index.Read(indexPath)
 	if err != nil {
 		m.logger.Error("failed to read index", "error", err, "path", indexPath)
 		return nil, NewCommitError("read index", err, "")
@@ -50,9 +50,12 @@ func NewTreeBuilder(repo *sourcerepo.SourceRepository) *TreeBuilder {
 //
 // Process:
 //  1. Creates an in-memory directory tree from flat index entries
-//  2. Recursively converts the tree into Git-style tree objects
-//  3. Returns the root tree's SHA hash
+//  2. Recursively converts the tree into Git-style tree objects, reusing
+//     the hash in idx.CacheTree for every directory whose entries have not
+//     changed since it was recorded
+//  3. Records the trees in idx.CacheTree and returns the root tree's SHA hash
 //
+// The index must be written afterwards for the cache tree to be kept.
 // Returns an empty tree if the index contains no entries.
 func (tb *TreeBuilder) BuildFromIndex(ctx context.Context, idx *index.Index) (objects.ObjectHash, error) {
 	if err := tb.checkContext(ctx); err != nil {
@@ -61,23 +64,39 @@ func (tb *TreeBuilder) BuildFromIndex(ctx context.Context, idx *index.Index) (ob
 
 	if idx.Count() == 0 {
 		t := tree.NewEmptyTree()
-		return tb.repo.WriteObject(t)
+		treeSHA, err := tb.repo.WriteObject(t)
+		if err != nil {
+			return "", err
+		}
+		idx.CacheTree = index.NewCacheTree("", 0, treeSHA)
+		return treeSHA, nil
 	}
 
 	root := tb.buildDirectoryTree(idx)
-	treeSHA, err := tb.buildTree(ctx, root)
+	treeSHA, err := tb.buildTree(ctx, root, idx.CacheTree)
 	if err != nil {
 		return "", fmt.Errorf("build tree: %w", err)
 	}
 
+	idx.CacheTree = root.cacheTree
 	return treeSHA, nil
 }
 
-// buildDirectoryTree constructs an in-memory directory tree from index entries
+// buildDirectoryTree constructs an in-memory directory tree from index entries,
+// keeping each entry's executable bit, symlink or gitlink type. Intent-to-add
+// entries have no content yet and are left out, which keeps the directories
+// holding them out of the cache tree.
 func (tb *TreeBuilder) buildDirectoryTree(idx *index.Index) *directoryNode {
 	root := newDirectoryNode("")
 	for _, entry := range idx.Entries {
-		root.addEntry(entry.Path.String(), entry.BlobHash, objects.FileModeRegular)
+		if !entry.IntentToAdd {
+			root.addEntry(entry.Path.String(), entry.BlobHash, entry.TreeMode())
+		}
+	}
+	for _, entry := range idx.Entries {
+		if entry.IntentToAdd {
+			root.markIncomplete(entry.Path.String())
+		}
 	}
 	return root
 }
@@ -98,12 +117,20 @@ func (tb *TreeBuilder) checkContext(ctx context.Context) error {
 //  1. Create entries for all files in this directory
 //  2. Recursively process subdirectories and create entries for them
 //
+// If cached, the node's entry in the previous cache tree, is still valid its
+// tree is reused without writing anything.
+//
 // Returns the SHA hash of the created tree object.
-func (tb *TreeBuilder) buildTree(ctx context.Context, node *directoryNode) (objects.ObjectHash, error) {
+func (tb *TreeBuilder) buildTree(ctx context.Context, node *directoryNode, cached *index.CacheTree) (objects.ObjectHash, error) {
 	if err := tb.checkContext(ctx); err != nil {
 		return "", err
 	}
 
+	if tb.canReuse(node, cached) {
+		node.cacheTree = cached
+		return cached.Hash, nil
+	}
+
 	entries := make([]*tree.TreeEntry, 0, len(node.files)+len(node.subdirs))
 
 	fileEntries, err := tb.buildFileEntries(node)
@@ -112,12 +139,41 @@ func (tb *TreeBuilder) buildTree(ctx context.Context, node *directoryNode) (obje
 	}
 	entries = append(entries, fileEntries...)
 
-	subdirEntries, err := tb.buildSubdirectoryEntries(ctx, node)
+	subdirEntries, err := tb.buildSubdirectoryEntries(ctx, node, cached)
 	if err != nil {
 		return "", err
 	}
 	entries = append(entries, subdirEntries...)
-	return tb.writeTreeObject(entries)
+
+	treeSHA, err := tb.writeTreeObject(entries)
+	if err != nil {
+		return "", err
+	}
+	node.cacheTree = newCacheTree(node, treeSHA)
+	return treeSHA, nil
+}
+
+// canReuse reports whether the cached tree can stand in for node: it must
+// still be valid, cover as many entries and exist in the object store.
+func (tb *TreeBuilder) canReuse(node *directoryNode, cached *index.CacheTree) bool {
+	if node.incomplete || !cached.Valid() || cached.EntryCount != node.entryCount {
+		return false
+	}
+	exists, err := tb.repo.ObjectStore().HasObject(cached.Hash)
+	return err == nil && exists
+}
+
+// newCacheTree records the tree written for node, and those of its
+// subdirectories, as a cache tree node.
+func newCacheTree(node *directoryNode, treeSHA objects.ObjectHash) *index.CacheTree {
+	cacheTree := index.NewCacheTree(node.name, node.entryCount, treeSHA)
+	if node.incomplete {
+		cacheTree = index.NewCacheTree(node.name, -1, "")
+	}
+	for _, subdir := range node.subdirs {
+		cacheTree.AddSubtree(subdir.cacheTree)
+	}
+	return cacheTree
 }
 
 // buildFileEntries creates tree entries for all files in the directory node
@@ -143,26 +199,26 @@ func (tb *TreeBuilder) buildFileEntries(node *directoryNode) ([]*tree.TreeEntry,
 //   - For directories with < 3 subdirectories: Uses sequential processing (lower overhead)
 //
 // This approach balances parallelism benefits with goroutine overhead.
-func (tb *TreeBuilder) buildSubdirectoryEntries(ctx context.Context, node *directoryNode) ([]*tree.TreeEntry, error) {
+func (tb *TreeBuilder) buildSubdirectoryEntries(ctx context.Context, node *directoryNode, cached *index.CacheTree) ([]*tree.TreeEntry, error) {
 	if len(node.subdirs) == 0 {
 		return []*tree.TreeEntry{}, nil
 	}
 
 	// For small numbers of subdirectories, sequential processing is more efficient
 	if len(node.subdirs) < concurrencyThreshold {
-		return tb.buildSubdirectoriesSequential(ctx, node)
+		return tb.buildSubdirectoriesSequential(ctx, node, cached)
 	}
 
 	// For larger numbers, use concurrent processing
-	return tb.buildSubdirectoriesConcurrent(ctx, node)
+	return tb.buildSubdirectoriesConcurrent(ctx, node, cached)
 }
 
 // buildSubdirectoriesSequential processes subdirectories one at a time
-func (tb *TreeBuilder) buildSubdirectoriesSequential(ctx context.Context, node *directoryNode) ([]*tree.TreeEntry, error) {
+func (tb *TreeBuilder) buildSubdirectoriesSequential(ctx context.Context, node *directoryNode, cached *index.CacheTree) ([]*tree.TreeEntry, error) {
 	entries := make([]*tree.TreeEntry, 0, len(node.subdirs))
 
 	for name, subdir := range node.subdirs {
-		entry, err := tb.buildSubdirectoryEntry(ctx, name, subdir)
+		entry, err := tb.buildSubdirectoryEntry(ctx, name, subdir, cached.Subtree(name))
 		if err != nil {
 			return nil, err
 		}
@@ -173,7 +229,7 @@ func (tb *TreeBuilder) buildSubdirectoriesSequential(ctx context.Context, node *
 }
 
 // buildSubdirectoriesConcurrent processes subdirectories in parallel using worker pool
-func (tb *TreeBuilder) buildSubdirectoriesConcurrent(ctx context.Context, node *directoryNode) ([]*tree.TreeEntry, error) {
+func (tb *TreeBuilder) buildSubdirectoriesConcurrent(ctx context.Context, node *directoryNode, cached *index.CacheTree) ([]*tree.TreeEntry, error) {
 	// Create a worker pool for processing subdirectories
 	workerPool := pool.NewWorkerPool[*directoryNode, *tree.TreeEntry]()
 
@@ -182,7 +238,7 @@ func (tb *TreeBuilder) buildSubdirectoriesConcurrent(ctx context.Context, node *
 		ctx,
 		node.subdirs,
 		func(ctx context.Context, subdir *directoryNode) (*tree.TreeEntry, error) {
-			return tb.buildSubdirectoryEntry(ctx, subdir.name, subdir)
+			return tb.buildSubdirectoryEntry(ctx, subdir.name, subdir, cached.Subtree(subdir.name))
 		},
 	)
 	if err != nil {
@@ -199,8 +255,8 @@ func (tb *TreeBuilder) buildSubdirectoriesConcurrent(ctx context.Context, node *
 }
 
 // buildSubdirectoryEntry builds a single subdirectory tree and creates its entry
-func (tb *TreeBuilder) buildSubdirectoryEntry(ctx context.Context, name string, subdir *directoryNode) (*tree.TreeEntry, error) {
-	subTreeSHA, err := tb.buildTree(ctx, subdir)
+func (tb *TreeBuilder) buildSubdirectoryEntry(ctx context.Context, name string, subdir *directoryNode, cached *index.CacheTree) (*tree.TreeEntry, error) {
+	subTreeSHA, err := tb.buildTree(ctx, subdir, cached)
 	if err != nil {
 		return nil, fmt.Errorf("build subdirectory %s: %w", name, err)
 	}
@@ -580,3 +580,90 @@ func TestBuildTree_Integration(t *testing.T) {
 		t.Errorf("Expected 4 files at root level, got %d", totalCount)
 	}
 }
+
+func TestBuildFromIndex_ReusesCacheTree(t *testing.T) {
+	repo, tempDir := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	tb := NewTreeBuilder(repo)
+	ctx := context.Background()
+
+	idx := index.NewIndex()
+	stage := func(path, content string) {
+		blobSHA, err := repo.WriteObject(blob.NewBlob([]byte(content)))
+		if err != nil {
+			t.Fatalf("Failed to write blob for %s: %v", path, err)
+		}
+		entry := index.NewEntry(scpath.RelativePath(path))
+		entry.BlobHash = blobSHA
+		entry.Mode = objects.FileModeRegular
+		idx.Add(entry)
+	}
+	stage("README.md", "# Project")
+	stage("docs/guide.md", "guide")
+	stage("src/main.go", "package main")
+
+	if _, err := tb.BuildFromIndex(ctx, idx); err != nil {
+		t.Fatalf("BuildFromIndex failed: %v", err)
+	}
+	if !idx.CacheTree.Valid() || idx.CacheTree.EntryCount != 3 {
+		t.Fatalf("Expected a valid cache tree over 3 entries, got %+v", idx.CacheTree)
+	}
+	if docs := idx.CacheTree.Subtree("docs"); !docs.Valid() || docs.EntryCount != 1 {
+		t.Fatalf("Expected a valid docs cache tree over 1 entry, got %+v", docs)
+	}
+
+	// A valid cached tree is trusted: point docs at the src tree, which
+	// exists and holds one entry, to see that docs is not rebuilt
+	idx.CacheTree.Subtree("docs").Hash = idx.CacheTree.Subtree("src").Hash
+	stage("src/utils.go", "package main")
+
+	treeSHA, err := tb.BuildFromIndex(ctx, idx)
+	if err != nil {
+		t.Fatalf("BuildFromIndex failed: %v", err)
+	}
+	treeObj, err := repo.ReadTreeObject(treeSHA)
+	if err != nil {
+		t.Fatalf("Failed to read tree object: %v", err)
+	}
+
+	hashes := make(map[string]objects.ObjectHash)
+	for _, e := range treeObj.Entries() {
+		hashes[e.Name().String()] = e.SHA()
+	}
+	if hashes["docs"] != idx.CacheTree.Subtree("docs").Hash {
+		t.Error("Expected docs to reuse its cached tree")
+	}
+	if src := idx.CacheTree.Subtree("src"); !src.Valid() || src.EntryCount != 2 || hashes["src"] != src.Hash {
+		t.Errorf("Expected src to be rebuilt with 2 entries, got %+v", src)
+	}
+}
+
+func TestBuildFromIndex_IntentToAddInvalidatesCacheTree(t *testing.T) {
+	repo, tempDir := setupTestRepo(t)
+	defer os.RemoveAll(tempDir)
+
+	blobSHA, err := repo.WriteObject(blob.NewBlob([]byte("content")))
+	if err != nil {
+		t.Fatalf("Failed to write blob: %v", err)
+	}
+	idx := index.NewIndex()
+	for _, path := range []string{"docs/guide.md", "src/main.go", "src/new.go"} {
+		entry := index.NewEntry(scpath.RelativePath(path))
+		entry.BlobHash = blobSHA
+		entry.Mode = objects.FileModeRegular
+		entry.IntentToAdd = path == "src/new.go"
+		idx.Add(entry)
+	}
+
+	if _, err := NewTreeBuilder(repo).BuildFromIndex(context.Background(), idx); err != nil {
+		t.Fatalf("BuildFromIndex failed: %v", err)
+	}
+
+	if idx.CacheTree.Valid() || idx.CacheTree.Subtree("src").Valid() {
+		t.Error("Directories holding an intent-to-add entry should not be cached")
+	}
+	if !idx.CacheTree.Subtree("docs").Valid() {
+		t.Error("Expected docs to be cached")
+	}
+}
@@ -217,7 +217,7 @@ func (wp *WorkerPool[T, R]) ProcessMap(
 					if !ok {
 						return nil
 					}
-					result, err := wp.processWithRecovery(ctx, task.task, processFn)
+					result, err := processWithRecovery(ctx, task.task, processFn)
 					select {
 					case resultChan <- keyedResult{key: task.key, value: result, err: err}:
 					case <-ctx.Done():
@@ -294,7 +294,19 @@ func (wp *WorkerPool[T, R]) ProcessStream(
 	taskChan <-chan T,
 	processFn ProcessFunc[T, R],
 ) (<-chan R, <-chan error) {
-	resultChan := make(chan R, wp.taskBuffer)
+	return processStream(ctx, wp.workerCount, wp.taskBuffer, taskChan, processFn)
+}
+
+// processStream is ProcessStream for a pool of workerCount workers and a
+// result buffer of taskBuffer. It is a function rather than a method so
+// that ProcessOrdered can stream tasks of another type through it.
+func processStream[T any, R any](
+	ctx context.Context,
+	workerCount, taskBuffer int,
+	taskChan <-chan T,
+	processFn ProcessFunc[T, R],
+) (<-chan R, <-chan error) {
+	resultChan := make(chan R, taskBuffer)
 	errChan := make(chan error, 1)
 
 	go func() {
@@ -303,7 +315,7 @@ func (wp *WorkerPool[T, R]) ProcessStream(
 
 		g, ctx := errgroup.WithContext(ctx)
 
-		for i := 0; i < wp.workerCount; i++ {
+		for i := 0; i < workerCount; i++ {
 			g.Go(func() error {
 				for {
 					select {
@@ -311,7 +323,7 @@ func (wp *WorkerPool[T, R]) ProcessStream(
 						if !ok {
 							return nil
 						}
-						result, err := wp.processWithRecovery(ctx, task, processFn)
+						result, err := processWithRecovery(ctx, task, processFn)
 						if err != nil {
 							return err
 						}
@@ -335,6 +347,85 @@ func (wp *WorkerPool[T, R]) ProcessStream(
 	return resultChan, errChan
 }
 
+// ProcessOrdered processes tasks through ProcessStream and hands each result
+// to emit in the order of tasks, whatever order the workers finish in.
+//
+// At most twice the worker count plus the task buffer of tasks are in
+// flight or waiting for an earlier result at any time, so memory stays
+// bounded however many tasks there are and however large their results.
+// emit is called from the calling goroutine only.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - tasks: Tasks to process
+//   - processFn: Function to process each task
+//   - emit: Called with each task's index and result, in order
+//
+// Returns:
+//   - error: The first error from processFn or emit, or the context's
+//     error; no more results are emitted after it
+func (wp *WorkerPool[T, R]) ProcessOrdered(
+	ctx context.Context,
+	tasks []T,
+	processFn ProcessFunc[T, R],
+	emit func(index int, result R) error,
+) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// A slot is taken for each task sent and given back once its result is
+	// emitted, which bounds the results waiting for an earlier one
+	slots := make(chan struct{}, 2*wp.workerCount+wp.taskBuffer)
+	taskChan := make(chan indexedTask[T])
+	go func() {
+		defer close(taskChan)
+		for idx, task := range tasks {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case taskChan <- indexedTask[T]{index: idx, task: task}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	workers := min(wp.workerCount, len(tasks))
+	resultChan, errChan := processStream(ctx, workers, wp.taskBuffer, taskChan, func(ctx context.Context, task indexedTask[T]) (Result[R], error) {
+		value, err := processFn(ctx, task.task)
+		return Result[R]{Value: value, Index: task.index}, err
+	})
+
+	pending := make(map[int]R)
+	next := 0
+	for result := range resultChan {
+		pending[result.Index] = result.Value
+		for value, ok := pending[next]; ok; value, ok = pending[next] {
+			delete(pending, next)
+			if err := emit(next, value); err != nil {
+				cancel()
+				for range resultChan {
+				}
+				return err
+			}
+			next++
+			<-slots
+		}
+	}
+
+	if err := <-errChan; err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
 // indexedTask wraps a task with its original index
 type indexedTask[T any] struct {
 	index int
@@ -355,7 +446,7 @@ func (wp *WorkerPool[T, R]) worker(
 			if !ok {
 				return nil
 			}
-			result, err := wp.processWithRecovery(ctx, task.task, processFn)
+			result, err := processWithRecovery(ctx, task.task, processFn)
 			select {
 			case resultChan <- Result[R]{Value: result, Error: err, Index: task.index}:
 			case <-ctx.Done():
@@ -372,7 +463,7 @@ func (wp *WorkerPool[T, R]) worker(
 
 // processWithRecovery executes a task with panic recovery.
 // If a panic occurs, it's converted to an error to prevent crashing the worker.
-func (wp *WorkerPool[T, R]) processWithRecovery(
+func processWithRecovery[T any, R any](
 	ctx context.Context,
 	task T,
 	processFn ProcessFunc[T, R],
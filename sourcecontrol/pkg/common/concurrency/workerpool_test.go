@@ -536,3 +536,92 @@ func findSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestWorkerPool_ProcessOrdered_OrderAndBound(t *testing.T) {
+	pool := NewWorkerPool[int, int](WithWorkerCount(4), WithTaskBuffer(2))
+
+	tasks := make([]int, 200)
+	for i := range tasks {
+		tasks[i] = i
+	}
+
+	// Task 0 finishes last, so every other result has to wait for it
+	var started, emitted, maxAhead atomic.Int64
+	processFn := func(ctx context.Context, task int) (int, error) {
+		if ahead := started.Add(1) - emitted.Load(); ahead > maxAhead.Load() {
+			maxAhead.Store(ahead)
+		}
+		if task == 0 {
+			time.Sleep(20 * time.Millisecond)
+		}
+		return task * 2, nil
+	}
+
+	var results []int
+	err := pool.ProcessOrdered(context.Background(), tasks, processFn, func(index, result int) error {
+		if index != len(results) {
+			t.Errorf("emitted index %d, want %d", index, len(results))
+		}
+		results = append(results, result)
+		emitted.Add(1)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(results) != len(tasks) {
+		t.Fatalf("expected %d results, got %d", len(tasks), len(results))
+	}
+	for i, result := range results {
+		if result != i*2 {
+			t.Errorf("index %d: expected %d, got %d (order not preserved)", i, i*2, result)
+		}
+	}
+	if limit := int64(2*4 + 2); maxAhead.Load() > limit {
+		t.Errorf("%d tasks started ahead of emitted results, want at most %d", maxAhead.Load(), limit)
+	}
+}
+
+func TestWorkerPool_ProcessOrdered_ErrorHandling(t *testing.T) {
+	pool := NewWorkerPool[int, int](WithWorkerCount(4))
+
+	tasks := make([]int, 50)
+	for i := range tasks {
+		tasks[i] = i
+	}
+
+	processErr := errors.New("processing error")
+	err := pool.ProcessOrdered(context.Background(), tasks, func(ctx context.Context, task int) (int, error) {
+		if task == 10 {
+			return 0, processErr
+		}
+		return task, nil
+	}, func(index, result int) error {
+		if index >= 10 {
+			t.Errorf("result %d emitted after the failed task", index)
+		}
+		return nil
+	})
+	if !errors.Is(err, processErr) {
+		t.Errorf("expected error %v, got %v", processErr, err)
+	}
+
+	emitErr := errors.New("emit error")
+	var emitted int
+	err = pool.ProcessOrdered(context.Background(), tasks, func(ctx context.Context, task int) (int, error) {
+		return task, nil
+	}, func(index, result int) error {
+		emitted++
+		if index == 5 {
+			return emitErr
+		}
+		return nil
+	})
+	if !errors.Is(err, emitErr) {
+		t.Errorf("expected error %v, got %v", emitErr, err)
+	}
+	if emitted != 6 {
+		t.Errorf("expected 6 results emitted, got %d", emitted)
+	}
+}
@@ -2,6 +2,7 @@ package fileops
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 
@@ -29,6 +30,59 @@ func AtomicWrite(targetPath scpath.AbsolutePath, data []byte, mode os.FileMode)
 	return renameTempFile(tmpFile.Name(), targetPath.String(), mode)
 }
 
+// AtomicWriteFrom is AtomicWrite for content that is streamed from r rather
+// than held in memory, such as large blobs being checked out.
+func AtomicWriteFrom(targetPath scpath.AbsolutePath, r io.Reader, mode os.FileMode) error {
+	dir := filepath.Dir(targetPath.String())
+	tmpFile, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+
+	defer func() {
+		tmpFile.Close()
+		os.Remove(tmpFile.Name())
+	}()
+
+	if _, err := io.Copy(tmpFile, r); err != nil {
+		return fmt.Errorf("write temp file: %w", err)
+	}
+	if err := tmpFile.Sync(); err != nil {
+		return fmt.Errorf("write temp file: sync: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("write temp file: close: %w", err)
+	}
+
+	return renameTempFile(tmpFile.Name(), targetPath.String(), mode)
+}
+
+// AtomicSymlink replaces whatever is at targetPath with a symbolic link to
+// linkTarget. The link is created under a temporary name in the same
+// directory and renamed into place, so targetPath is never missing.
+func AtomicSymlink(targetPath scpath.AbsolutePath, linkTarget string) error {
+	dir := filepath.Dir(targetPath.String())
+	tmpFile, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	tmpFile.Close()
+
+	// Reuse the unique name for the link
+	if err := os.Remove(tmpPath); err != nil {
+		return fmt.Errorf("remove temp file: %w", err)
+	}
+	if err := os.Symlink(linkTarget, tmpPath); err != nil {
+		return fmt.Errorf("symlink: %w", err)
+	}
+	if err := os.Rename(tmpPath, targetPath.String()); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("rename: %w", err)
+	}
+	return nil
+}
+
 // writeTempFile writes the provided data to the supplied temporary file,
 // synchronizes it to underlying storage using fsync, and then closes the file.
 // It returns any encountered error wrapped with context.
@@ -331,3 +331,30 @@ func TestAtomicWrite_ConcurrentWrites(t *testing.T) {
 		t.Errorf("Expected %d files, found %d", numWrites, len(entries))
 	}
 }
+
+func TestAtomicSymlink_ReplacesFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symbolic links needs extra privileges on Windows")
+	}
+	tmpDir := t.TempDir()
+	targetPath := filepath.Join(tmpDir, "link")
+	if err := os.WriteFile(targetPath, []byte("plain file"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if err := AtomicSymlink(scpath.AbsolutePath(targetPath), "../elsewhere"); err != nil {
+		t.Fatalf("AtomicSymlink failed: %v", err)
+	}
+
+	target, err := os.Readlink(targetPath)
+	if err != nil {
+		t.Fatalf("Readlink failed: %v", err)
+	}
+	if target != "../elsewhere" {
+		t.Errorf("Link target = %q, want %q", target, "../elsewhere")
+	}
+	entries, _ := os.ReadDir(tmpDir)
+	if len(entries) != 1 {
+		t.Errorf("Directory has %d entries, want the link only", len(entries))
+	}
+}
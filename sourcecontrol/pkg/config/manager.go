@@ -527,7 +527,7 @@ func (m *Manager) getUserConfigPath() scpath.AbsolutePath {
 //   - Core repository settings
 //   - Branch initialization defaults
 //   - UI and display settings
-//   - Platform-specific settings (line endings, case sensitivity)
+//   - Platform-specific settings (line endings, case sensitivity, symlinks)
 func (m *Manager) loadBuiltinDefaults() {
 	// Core repository settings
 	m.builtinDefaults["core.repositoryformatversion"] = "0"
@@ -540,6 +540,10 @@ func (m *Manager) loadBuiltinDefaults() {
 	m.builtinDefaults["pull.rebase"] = "false"
 	m.builtinDefaults["push.default"] = "simple"
 
+	// Maintenance settings
+	m.builtinDefaults["gc.auto"] = "6700"
+	m.builtinDefaults["gc.pruneexpire"] = "2.weeks.ago"
+
 	// UI and display settings
 	m.builtinDefaults["color.ui"] = "auto"
 	m.builtinDefaults["diff.renames"] = "true"
@@ -548,9 +552,11 @@ func (m *Manager) loadBuiltinDefaults() {
 	if runtime.GOOS == "windows" {
 		m.builtinDefaults["core.ignorecase"] = "true"
 		m.builtinDefaults["core.autocrlf"] = "true"
+		m.builtinDefaults["core.symlinks"] = "false"
 	} else {
 		m.builtinDefaults["core.ignorecase"] = "false"
 		m.builtinDefaults["core.autocrlf"] = "input"
+		m.builtinDefaults["core.symlinks"] = "true"
 	}
 }
 
@@ -39,6 +39,20 @@ func (tc *TypedConfig) FileMode() bool {
 	return val
 }
 
+// Symlinks returns whether symbolic links are checked out as links
+// (core.symlinks), rather than as plain files holding the link's target
+func (tc *TypedConfig) Symlinks() bool {
+	entry := tc.manager.Get("core.symlinks")
+	if entry == nil {
+		return true
+	}
+	val, err := entry.AsBoolean()
+	if err != nil {
+		return true
+	}
+	return val
+}
+
 // Bare returns whether the repository is bare
 func (tc *TypedConfig) Bare() bool {
 	entry := tc.manager.Get("core.bare")
@@ -87,6 +101,21 @@ func (tc *TypedConfig) LogAllRefUpdates() bool {
 	return val
 }
 
+// Parallelism returns the largest number of workers to check or hash files
+// with at once (core.parallelism), or 0 when it is unset or not positive,
+// meaning one per CPU.
+func (tc *TypedConfig) Parallelism() int {
+	entry := tc.manager.Get("core.parallelism")
+	if entry == nil {
+		return 0
+	}
+	val, err := entry.AsInt()
+	if err != nil || val < 0 {
+		return 0
+	}
+	return val
+}
+
 // User configuration
 
 // UserName returns the configured user name
@@ -118,6 +147,48 @@ func (tc *TypedConfig) DefaultBranch() string {
 	return entry.AsString()
 }
 
+// Maintenance configuration
+
+// GCAuto returns the number of loose objects above which an automatic gc
+// packs the repository. Zero or a negative value disables automatic gc.
+func (tc *TypedConfig) GCAuto() int {
+	entry := tc.manager.Get("gc.auto")
+	if entry == nil {
+		return 6700
+	}
+	val, err := entry.AsInt()
+	if err != nil {
+		return 6700
+	}
+	return val
+}
+
+// GCPruneExpire returns how long gc keeps unreachable loose objects, as an
+// expiry date such as "2.weeks.ago", "now" or "never".
+func (tc *TypedConfig) GCPruneExpire() string {
+	entry := tc.manager.Get("gc.pruneexpire")
+	if entry == nil || entry.AsString() == "" {
+		return "2.weeks.ago"
+	}
+	return entry.AsString()
+}
+
+// Index configuration
+
+// IndexVersion returns the index file format version to write
+// (index.version), or 0 when it is not set.
+func (tc *TypedConfig) IndexVersion() int {
+	entry := tc.manager.Get("index.version")
+	if entry == nil {
+		return 0
+	}
+	val, err := entry.AsInt()
+	if err != nil {
+		return 0
+	}
+	return val
+}
+
 // Color configuration
 
 // ColorUI returns the color UI setting
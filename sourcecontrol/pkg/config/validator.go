@@ -58,6 +58,8 @@ func (v *Validator) validateBySection(section, subsection, name, value string) e
 		return v.validatePush(name, value)
 	case "init":
 		return v.validateInit(name, value)
+	case "gc":
+		return v.validateGC(name, value)
 	default:
 		// Unknown sections are allowed (extensibility)
 		return nil
@@ -67,9 +69,9 @@ func (v *Validator) validateBySection(section, subsection, name, value string) e
 // validateCore validates core.* configuration values
 func (v *Validator) validateCore(name, value string) error {
 	switch name {
-	case "repositoryformatversion":
-		return v.validateInt(value, "core.repositoryformatversion")
-	case "filemode", "bare", "logallrefupdates", "ignorecase":
+	case "repositoryformatversion", "parallelism":
+		return v.validateInt(value, "core."+name)
+	case "filemode", "symlinks", "bare", "logallrefupdates", "ignorecase":
 		return v.validateBoolean(value, "core."+name)
 	case "autocrlf":
 		return v.validateAutoCRLF(value)
@@ -175,6 +177,16 @@ func (v *Validator) validateInit(name, value string) error {
 	}
 }
 
+// validateGC validates gc.* configuration values
+func (v *Validator) validateGC(name, value string) error {
+	switch name {
+	case "auto":
+		return v.validateInt(value, "gc.auto")
+	default:
+		return nil
+	}
+}
+
 // Helper validation functions
 
 func (v *Validator) validateInt(value, key string) error {
@@ -0,0 +1,143 @@
+// Package diff computes line-based differences between two versions of a
+// file and represents them as unified diff hunks.
+//
+// Lines keep their terminating newline, so that a file without one at the
+// end can be told apart from one with it, and applying hunks reproduces the
+// exact bytes of a file.
+package diff
+
+import "strings"
+
+// Op is the kind of an edit.
+type Op int
+
+const (
+	// Equal keeps a line that both versions share
+	Equal Op = iota
+	// Delete removes a line of the old version
+	Delete
+	// Insert adds a line of the new version
+	Insert
+)
+
+// Edit is one step of an edit script turning the old version into the new.
+type Edit struct {
+	Op  Op
+	Old int // index into the old lines; unused for Insert
+	New int // index into the new lines; unused for Delete
+}
+
+// SplitLines splits content into lines, each keeping its trailing newline.
+// The last line lacks one if content does not end with a newline.
+func SplitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Lines returns a shortest edit script turning old into new, computed with
+// Myers' algorithm. Edits are in order: those for a line of old or new come
+// after those for the lines before it.
+func Lines(old, new []string) []Edit {
+	prefix := 0
+	for prefix < len(old) && prefix < len(new) && old[prefix] == new[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(old)-prefix && suffix < len(new)-prefix &&
+		old[len(old)-1-suffix] == new[len(new)-1-suffix] {
+		suffix++
+	}
+
+	edits := make([]Edit, 0, len(old)+len(new)-prefix-suffix)
+	for i := 0; i < prefix; i++ {
+		edits = append(edits, Edit{Op: Equal, Old: i, New: i})
+	}
+	for _, e := range myers(old[prefix:len(old)-suffix], new[prefix:len(new)-suffix]) {
+		e.Old += prefix
+		e.New += prefix
+		edits = append(edits, e)
+	}
+	for i := suffix; i > 0; i-- {
+		edits = append(edits, Edit{Op: Equal, Old: len(old) - i, New: len(new) - i})
+	}
+	return edits
+}
+
+// myers runs the greedy O(ND) algorithm and backtracks through the furthest
+// reaching paths it recorded for each edit distance.
+func myers(a, b []string) []Edit {
+	n, m := len(a), len(b)
+	max := n + m
+	if max == 0 {
+		return nil
+	}
+
+	// v[k+max] is the furthest x reached on diagonal k = x - y; trace[d]
+	// holds v as it was before the paths of distance d were extended.
+	v := make([]int, 2*max+2)
+	var trace [][]int
+
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[k-1+max] < v[k+1+max]) {
+				x = v[k+1+max]
+			} else {
+				x = v[k-1+max] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[k+max] = x
+			if x >= n && y >= m {
+				return backtrack(trace, n, m, max)
+			}
+		}
+	}
+	return nil
+}
+
+func backtrack(trace [][]int, x, y, max int) []Edit {
+	var edits []Edit
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[k-1+max] < v[k+1+max]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[prevK+max]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			edits = append(edits, Edit{Op: Equal, Old: x, New: y})
+		}
+		if d > 0 {
+			if x == prevX {
+				edits = append(edits, Edit{Op: Insert, Old: x, New: y - 1})
+			} else {
+				edits = append(edits, Edit{Op: Delete, Old: x - 1, New: y})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
@@ -0,0 +1,224 @@
+package diff
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// applyEdits rebuilds new from old and an edit script, checking that the
+// script only keeps lines the two share.
+func applyEdits(t *testing.T, old, new []string, edits []Edit) []string {
+	t.Helper()
+	var result []string
+	for _, e := range edits {
+		switch e.Op {
+		case Equal:
+			if old[e.Old] != new[e.New] {
+				t.Fatalf("Equal edit for different lines %q and %q", old[e.Old], new[e.New])
+			}
+			result = append(result, old[e.Old])
+		case Insert:
+			result = append(result, new[e.New])
+		}
+	}
+	return result
+}
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		old     string
+		new     string
+		changes int
+	}{
+		{"identical", "a\nb\nc\n", "a\nb\nc\n", 0},
+		{"empty to content", "", "a\nb\n", 2},
+		{"content to empty", "a\nb\n", "", 2},
+		{"single change", "a\nb\nc\n", "a\nx\nc\n", 2},
+		{"insertion", "a\nc\n", "a\nb\nc\n", 1},
+		{"moved line", "a\nb\nc\nd\n", "b\nc\nd\na\n", 2},
+		{"missing final newline", "a\nb", "a\nb\n", 2},
+		{"classic", "a\nb\nc\na\nb\nb\na\n", "c\nb\na\nb\na\nc\n", 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old, new := SplitLines(tt.old), SplitLines(tt.new)
+			edits := Lines(old, new)
+
+			if got := strings.Join(applyEdits(t, old, new, edits), ""); got != tt.new {
+				t.Errorf("edits produce %q, want %q", got, tt.new)
+			}
+			changes := 0
+			for _, e := range edits {
+				if e.Op != Equal {
+					changes++
+				}
+			}
+			if changes != tt.changes {
+				t.Errorf("got %d changes, want %d (shortest)", changes, tt.changes)
+			}
+		})
+	}
+}
+
+func TestHunks(t *testing.T) {
+	old := SplitLines("1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n")
+	new := SplitLines("1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n")
+
+	hunks := Hunks(old, new, 3)
+	if len(hunks) != 2 {
+		t.Fatalf("got %d hunks, want 2", len(hunks))
+	}
+
+	want := "@@ -1,6 +1,6 @@\n 1\n 2\n-3\n+three\n 4\n 5\n 6\n"
+	if got := hunks[0].String(); got != want {
+		t.Errorf("first hunk:\n%s\nwant:\n%s", got, want)
+	}
+	want = "@@ -10,3 +10,4 @@\n 10\n 11\n 12\n+13\n"
+	if got := hunks[1].String(); got != want {
+		t.Errorf("second hunk:\n%s\nwant:\n%s", got, want)
+	}
+
+	// With more context the changes share one hunk
+	if hunks := Hunks(old, new, 5); len(hunks) != 1 {
+		t.Errorf("got %d hunks with context 5, want 1", len(hunks))
+	}
+}
+
+func TestHunk_EmptySides(t *testing.T) {
+	hunks := Hunks(nil, SplitLines("a\nb"), 3)
+	want := "@@ -0,0 +1,2 @@\n+a\n+b\n" + NoNewlineMarker + "\n"
+	if len(hunks) != 1 || hunks[0].String() != want {
+		t.Fatalf("hunks = %v, want one hunk:\n%s", hunks, want)
+	}
+
+	parsed, err := ParseHunk(want)
+	if err != nil {
+		t.Fatalf("ParseHunk() failed: %v", err)
+	}
+	if parsed.String() != want {
+		t.Errorf("ParseHunk() round trip:\n%s\nwant:\n%s", parsed, want)
+	}
+
+	if got := hunks[0].Reverse().Header(); got != "@@ -1,2 +0,0 @@" {
+		t.Errorf("Reverse() header = %q", got)
+	}
+}
+
+func TestHunk_Split(t *testing.T) {
+	old := SplitLines("a\nb\nc\nd\ne\n")
+	new := SplitLines("A\nb\nc\nd\nE\n")
+
+	hunks := Hunks(old, new, 3)
+	if len(hunks) != 1 || !hunks[0].CanSplit() {
+		t.Fatalf("expected one splittable hunk, got %v", hunks)
+	}
+
+	parts := hunks[0].Split()
+	if len(parts) != 2 {
+		t.Fatalf("Split() gave %d hunks, want 2", len(parts))
+	}
+	if got, want := parts[0].String(), "@@ -1,4 +1,4 @@\n-a\n+A\n b\n c\n d\n"; got != want {
+		t.Errorf("first part:\n%s\nwant:\n%s", got, want)
+	}
+	if got, want := parts[1].String(), "@@ -2,4 +2,4 @@\n b\n c\n d\n-e\n+E\n"; got != want {
+		t.Errorf("second part:\n%s\nwant:\n%s", got, want)
+	}
+	if parts[0].CanSplit() {
+		t.Error("a split part should not be splittable")
+	}
+
+	// Each part applies on its own, and both together
+	for _, tc := range []struct {
+		hunks []*Hunk
+		want  string
+	}{
+		{parts[:1], "A\nb\nc\nd\ne\n"},
+		{parts[1:], "a\nb\nc\nd\nE\n"},
+		{[]*Hunk{parts[1], parts[0]}, "A\nb\nc\nd\nE\n"},
+	} {
+		result, err := Apply(old, tc.hunks)
+		if err != nil {
+			t.Fatalf("Apply() failed: %v", err)
+		}
+		if got := strings.Join(result, ""); got != tc.want {
+			t.Errorf("Apply() = %q, want %q", got, tc.want)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	old := SplitLines("one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\n")
+	new := SplitLines("zero\none\ntwo\nthree\nfour\n5\nsix\nseven\neight\nnine\nten")
+
+	hunks := Hunks(old, new, 1)
+	if len(hunks) != 3 {
+		t.Fatalf("got %d hunks, want 3", len(hunks))
+	}
+
+	all, err := Apply(old, hunks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(all, ""); got != strings.Join(new, "") {
+		t.Errorf("Apply(all) = %q", got)
+	}
+
+	some, err := Apply(old, []*Hunk{hunks[0], hunks[2]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(some, ""), "zero\none\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten"; got != want {
+		t.Errorf("Apply(some) = %q, want %q", got, want)
+	}
+
+	// Reversed hunks turn new back into old
+	reversed := make([]*Hunk, len(hunks))
+	for i, h := range hunks {
+		reversed[i] = h.Reverse()
+	}
+	back, err := Apply(new, reversed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(back, ""); got != strings.Join(old, "") {
+		t.Errorf("Apply(reversed) = %q", got)
+	}
+
+	if _, err := Apply(SplitLines("other\n"), hunks[1:2]); !errors.Is(err, ErrDoesNotApply) {
+		t.Errorf("Apply() to other content err = %v, want ErrDoesNotApply", err)
+	}
+}
+
+func TestParseHunk_Edited(t *testing.T) {
+	old := SplitLines("a\nb\nc\n")
+
+	// A hand-edited hunk with stale counts: one of two additions dropped
+	edited := "@@ -1,3 +1,5 @@\n a\n+new\n b\n\n"
+	h, err := ParseHunk(edited)
+	if err != nil {
+		t.Fatalf("ParseHunk() failed: %v", err)
+	}
+	if h.OldLines != 3 || h.NewLines != 4 {
+		t.Errorf("counts = -%d +%d, want -3 +4", h.OldLines, h.NewLines)
+	}
+
+	result, err := Apply(SplitLines("a\nb\n\n"), []*Hunk{h})
+	if err != nil {
+		t.Fatalf("Apply() failed: %v", err)
+	}
+	if got, want := strings.Join(result, ""), "a\nnew\nb\n\n"; got != want {
+		t.Errorf("Apply() = %q, want %q", got, want)
+	}
+	if _, err := Apply(old, []*Hunk{h}); err == nil {
+		t.Error("edited hunk should not apply where its context differs")
+	}
+
+	for _, bad := range []string{"not a header\n a\n", "@@ -1 +1 @@\n?a\n"} {
+		if _, err := ParseHunk(bad); err == nil {
+			t.Errorf("ParseHunk(%q) should fail", bad)
+		}
+	}
+}
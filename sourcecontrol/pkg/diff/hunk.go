@@ -0,0 +1,363 @@
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrDoesNotApply is returned by Apply when a hunk's context or deleted
+// lines are not found where the hunk expects them.
+var ErrDoesNotApply = errors.New("patch does not apply")
+
+// NoNewlineMarker follows a hunk line that has no terminating newline.
+const NoNewlineMarker = `\ No newline at end of file`
+
+// Line is one line of a hunk: Equal for context, Delete or Insert for a
+// change.
+type Line struct {
+	Op   Op
+	Text string // including its newline, if it has one
+}
+
+// Hunk is a group of nearby changes together with the unchanged lines
+// around them, as in a unified diff.
+//
+// Starts are 1-based line numbers. As in unified diffs, a side with no lines
+// has the number of the line before the hunk as its start, 0 at the top of
+// the file.
+type Hunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	Lines    []Line
+}
+
+// Hunks computes the differences between old and new as hunks with up to
+// context unchanged lines around each change. Changes separated by no more
+// than twice that many unchanged lines share a hunk.
+func Hunks(old, new []string, context int) []*Hunk {
+	edits := Lines(old, new)
+
+	var hunks []*Hunk
+	for i := 0; i < len(edits); {
+		if edits[i].Op == Equal {
+			i++
+			continue
+		}
+
+		start := max(i-context, 0)
+		end := i
+		for {
+			for end < len(edits) && edits[end].Op != Equal {
+				end++
+			}
+			next := end
+			for next < len(edits) && edits[next].Op == Equal {
+				next++
+			}
+			if next < len(edits) && next-end <= 2*context {
+				end = next
+				continue
+			}
+			end = min(end+context, len(edits))
+			break
+		}
+
+		hunks = append(hunks, newHunk(edits[start:end], old, new))
+		i = end
+	}
+	return hunks
+}
+
+// newHunk builds the hunk covering a run of edits.
+func newHunk(edits []Edit, old, new []string) *Hunk {
+	h := &Hunk{Lines: make([]Line, 0, len(edits))}
+	for _, e := range edits {
+		if e.Op == Insert {
+			h.Lines = append(h.Lines, Line{Op: Insert, Text: new[e.New]})
+		} else {
+			h.Lines = append(h.Lines, Line{Op: e.Op, Text: old[e.Old]})
+		}
+	}
+	h.setStarts(edits[0].Old, edits[0].New)
+	return h
+}
+
+// setStarts counts the hunk's lines and sets its starts from the 0-based
+// indexes of the first old and new lines it covers.
+func (h *Hunk) setStarts(oldIndex, newIndex int) {
+	h.OldLines, h.NewLines = 0, 0
+	for _, line := range h.Lines {
+		if line.Op != Insert {
+			h.OldLines++
+		}
+		if line.Op != Delete {
+			h.NewLines++
+		}
+	}
+	h.OldStart = startOf(oldIndex, h.OldLines)
+	h.NewStart = startOf(newIndex, h.NewLines)
+}
+
+func startOf(index, count int) int {
+	if count == 0 {
+		return index
+	}
+	return index + 1
+}
+
+// oldIndex returns the 0-based index of the first old line the hunk covers,
+// or of the line it inserts before if it covers none.
+func (h *Hunk) oldIndex() int {
+	if h.OldLines == 0 {
+		return h.OldStart
+	}
+	return h.OldStart - 1
+}
+
+func (h *Hunk) newIndex() int {
+	if h.NewLines == 0 {
+		return h.NewStart
+	}
+	return h.NewStart - 1
+}
+
+// Header returns the hunk's "@@ -l,s +l,s @@" line, without a newline.
+func (h *Hunk) Header() string {
+	return fmt.Sprintf("@@ -%s +%s @@", formatRange(h.OldStart, h.OldLines), formatRange(h.NewStart, h.NewLines))
+}
+
+func formatRange(start, count int) string {
+	if count == 1 {
+		return strconv.Itoa(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// String formats the hunk as it appears in a unified diff.
+func (h *Hunk) String() string {
+	var b strings.Builder
+	b.WriteString(h.Header())
+	b.WriteByte('\n')
+	for _, line := range h.Lines {
+		b.WriteString(line.String())
+	}
+	return b.String()
+}
+
+// String formats the line with its ' ', '-' or '+' prefix, followed by
+// NoNewlineMarker if the text has no newline.
+func (l Line) String() string {
+	prefix := " "
+	switch l.Op {
+	case Delete:
+		prefix = "-"
+	case Insert:
+		prefix = "+"
+	}
+	if strings.HasSuffix(l.Text, "\n") {
+		return prefix + l.Text
+	}
+	return prefix + l.Text + "\n" + NoNewlineMarker + "\n"
+}
+
+// CanSplit reports whether Split would divide the hunk, that is, whether
+// unchanged lines separate some of its changes.
+func (h *Hunk) CanSplit() bool {
+	return len(h.changeGroups()) > 1
+}
+
+// Split divides the hunk at the unchanged lines between its changes. Each
+// resulting hunk holds one group of adjacent changes and all the unchanged
+// lines on either side of it, so neighbouring hunks share context.
+func (h *Hunk) Split() []*Hunk {
+	groups := h.changeGroups()
+	if len(groups) < 2 {
+		return []*Hunk{h}
+	}
+
+	hunks := make([]*Hunk, 0, len(groups))
+	for i := range groups {
+		from, to := 0, len(h.Lines)
+		if i > 0 {
+			from = groups[i-1][1]
+		}
+		if i < len(groups)-1 {
+			to = groups[i+1][0]
+		}
+
+		oldIndex, newIndex := h.oldIndex(), h.newIndex()
+		for _, line := range h.Lines[:from] {
+			if line.Op != Insert {
+				oldIndex++
+			}
+			if line.Op != Delete {
+				newIndex++
+			}
+		}
+
+		part := &Hunk{Lines: append([]Line(nil), h.Lines[from:to]...)}
+		part.setStarts(oldIndex, newIndex)
+		hunks = append(hunks, part)
+	}
+	return hunks
+}
+
+// changeGroups returns the [start, end) line ranges of each run of changed
+// lines.
+func (h *Hunk) changeGroups() [][2]int {
+	var groups [][2]int
+	for i := 0; i < len(h.Lines); {
+		if h.Lines[i].Op == Equal {
+			i++
+			continue
+		}
+		start := i
+		for i < len(h.Lines) && h.Lines[i].Op != Equal {
+			i++
+		}
+		groups = append(groups, [2]int{start, i})
+	}
+	return groups
+}
+
+// Reverse returns the hunk that undoes h: its deletions become insertions
+// and the other way round.
+func (h *Hunk) Reverse() *Hunk {
+	r := &Hunk{
+		OldStart: h.NewStart,
+		OldLines: h.NewLines,
+		NewStart: h.OldStart,
+		NewLines: h.OldLines,
+		Lines:    make([]Line, len(h.Lines)),
+	}
+	for i, line := range h.Lines {
+		switch line.Op {
+		case Delete:
+			line.Op = Insert
+		case Insert:
+			line.Op = Delete
+		}
+		r.Lines[i] = line
+	}
+	return r
+}
+
+// ParseHunk parses a hunk formatted as by Hunk.String. The line counts of
+// the header are ignored and recomputed from the lines that follow, so a
+// hunk whose lines were edited by hand parses as long as its starts are
+// unchanged. An empty line is taken as an empty context line.
+func ParseHunk(text string) (*Hunk, error) {
+	header, body, _ := strings.Cut(text, "\n")
+	oldIndex, newIndex, err := parseHeader(header)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &Hunk{}
+	for _, raw := range SplitLines(body) {
+		switch raw[0] {
+		case ' ':
+			h.Lines = append(h.Lines, Line{Op: Equal, Text: raw[1:]})
+		case '-':
+			h.Lines = append(h.Lines, Line{Op: Delete, Text: raw[1:]})
+		case '+':
+			h.Lines = append(h.Lines, Line{Op: Insert, Text: raw[1:]})
+		case '\n':
+			h.Lines = append(h.Lines, Line{Op: Equal, Text: "\n"})
+		case '\\':
+			if len(h.Lines) == 0 {
+				return nil, fmt.Errorf("unexpected %q", strings.TrimSuffix(raw, "\n"))
+			}
+			last := &h.Lines[len(h.Lines)-1]
+			last.Text = strings.TrimSuffix(last.Text, "\n")
+		default:
+			return nil, fmt.Errorf("invalid hunk line %q", strings.TrimSuffix(raw, "\n"))
+		}
+	}
+
+	h.setStarts(oldIndex, newIndex)
+	return h, nil
+}
+
+// parseHeader returns the 0-based indexes of the first old and new lines of
+// a hunk header.
+func parseHeader(header string) (oldIndex, newIndex int, err error) {
+	var oldRange, newRange string
+	if _, err := fmt.Sscanf(header, "@@ -%s +%s @@", &oldRange, &newRange); err != nil {
+		return 0, 0, fmt.Errorf("invalid hunk header %q", header)
+	}
+	if oldIndex, err = parseRange(oldRange); err != nil {
+		return 0, 0, fmt.Errorf("invalid hunk header %q", header)
+	}
+	if newIndex, err = parseRange(newRange); err != nil {
+		return 0, 0, fmt.Errorf("invalid hunk header %q", header)
+	}
+	return oldIndex, newIndex, nil
+}
+
+func parseRange(r string) (int, error) {
+	startText, countText, hasCount := strings.Cut(r, ",")
+	start, err := strconv.Atoi(startText)
+	if err != nil {
+		return 0, err
+	}
+	count := 1
+	if hasCount {
+		if count, err = strconv.Atoi(countText); err != nil {
+			return 0, err
+		}
+	}
+	if count == 0 {
+		return start, nil
+	}
+	return start - 1, nil
+}
+
+// Apply applies hunks to base, the lines of the old version they were
+// computed against, and returns the resulting lines. The hunks may be any
+// subset of those describing a change, including ones produced by Split
+// whose context overlaps; their changes must not overlap.
+func Apply(base []string, hunks []*Hunk) ([]string, error) {
+	sorted := append([]*Hunk(nil), hunks...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].oldIndex() < sorted[j].oldIndex() })
+
+	result := make([]string, 0, len(base))
+	pos := 0 // base lines before pos have been copied or deleted
+	for _, h := range sorted {
+		at := h.oldIndex()
+		for _, line := range h.Lines {
+			if line.Op != Insert && (at >= len(base) || base[at] != line.Text) {
+				return nil, fmt.Errorf("%w: hunk %s does not match line %d", ErrDoesNotApply, h.Header(), at+1)
+			}
+
+			switch line.Op {
+			case Equal:
+				if at >= pos {
+					result = append(result, base[pos:at+1]...)
+					pos = at + 1
+				}
+				at++
+			case Delete:
+				if at < pos {
+					return nil, fmt.Errorf("%w: hunk %s overlaps another", ErrDoesNotApply, h.Header())
+				}
+				result = append(result, base[pos:at]...)
+				pos = at + 1
+				at++
+			case Insert:
+				if at < pos {
+					return nil, fmt.Errorf("%w: hunk %s overlaps another", ErrDoesNotApply, h.Header())
+				}
+				result = append(result, base[pos:at]...)
+				pos = at
+				result = append(result, line.Text)
+			}
+		}
+	}
+	return append(result, base[pos:]...), nil
+}
@@ -0,0 +1,499 @@
+// Package fsck verifies the integrity of a repository: that every stored
+// object matches its hash and is well formed, and that everything reachable
+// from the refs, HEAD and the index is actually present.
+package fsck
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"github.com/utkarsh5026/SourceControl/pkg/common/logger"
+	"github.com/utkarsh5026/SourceControl/pkg/index"
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/refs"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/sourcerepo"
+	"github.com/utkarsh5026/SourceControl/pkg/store"
+)
+
+// Exit status bits, the same as git fsck uses. A clean repository exits 0;
+// dangling and unreachable objects are reported but are not errors.
+const (
+	ExitObject    = 1 << 0 // corrupt or malformed objects
+	ExitReachable = 1 << 1 // reachable objects are missing
+	ExitPack      = 1 << 2 // damaged packs
+	ExitRefs      = 1 << 3 // refs that are unreadable or point nowhere
+)
+
+// ProblemKind classifies a problem found by Check.
+type ProblemKind int
+
+const (
+	// ProblemCorrupt is an object that cannot be read or does not hash to
+	// its name
+	ProblemCorrupt ProblemKind = iota
+
+	// ProblemInvalid is an object whose content is malformed: unsorted or
+	// duplicate tree entries, bad commit or tag headers, or a link to an
+	// object of the wrong type
+	ProblemInvalid
+
+	// ProblemWarning is questionable content Git still accepts, such as a
+	// zero-padded file mode. It only fails the check in strict mode.
+	ProblemWarning
+
+	// ProblemMissing is an object that is reachable but not stored
+	ProblemMissing
+
+	// ProblemBadPack is a pack whose checksum does not match
+	ProblemBadPack
+
+	// ProblemBadRef is a ref, HEAD or index entry that cannot be read or
+	// names an object that does not exist
+	ProblemBadRef
+)
+
+// Problem is a single finding of Check.
+type Problem struct {
+	Kind ProblemKind
+
+	// Hash is the object the problem is about; empty for refs and packs
+	Hash objects.ObjectHash
+
+	// Type is the object's type, when known
+	Type objects.ObjectType
+
+	// From names what refers to a missing object: an object hash, a ref
+	// name or "index"
+	From string
+
+	Message string
+}
+
+// String formats the problem the way git fsck prints it.
+func (p Problem) String() string {
+	name := string(p.Type)
+	if name == "" {
+		name = "object"
+	}
+
+	switch p.Kind {
+	case ProblemCorrupt, ProblemInvalid:
+		return fmt.Sprintf("error in %s %s: %s", name, p.Hash, p.Message)
+	case ProblemWarning:
+		return fmt.Sprintf("warning in %s %s: %s", name, p.Hash, p.Message)
+	case ProblemMissing:
+		if p.From != "" {
+			return fmt.Sprintf("broken link from %s\n              to %s %s", p.From, name, p.Hash)
+		}
+		return fmt.Sprintf("missing %s %s", name, p.Hash)
+	default:
+		return "error: " + p.Message
+	}
+}
+
+// Object identifies a stored object.
+type Object struct {
+	Hash objects.ObjectHash
+	Type objects.ObjectType
+}
+
+// Options configures a check.
+type Options struct {
+	// Strict makes warnings count as errors (git fsck --strict)
+	Strict bool
+}
+
+// Report is the outcome of a check.
+type Report struct {
+	// Checked is the number of stored objects that were verified
+	Checked int
+
+	// Problems lists everything wrong with the repository, in the order
+	// it was found
+	Problems []Problem
+
+	// Unreachable lists stored objects that nothing reachable refers to
+	Unreachable []Object
+
+	// Dangling is the subset of Unreachable that no other object refers to
+	// either: the tips of abandoned history, and the objects to look at
+	// when recovering lost work
+	Dangling []Object
+
+	strict bool
+}
+
+// ExitCode returns the process exit status for the report: 0 for a healthy
+// repository, otherwise a combination of the Exit bits.
+func (r *Report) ExitCode() int {
+	code := 0
+	for _, p := range r.Problems {
+		switch p.Kind {
+		case ProblemCorrupt, ProblemInvalid:
+			code |= ExitObject
+		case ProblemWarning:
+			if r.strict {
+				code |= ExitObject
+			}
+		case ProblemMissing:
+			code |= ExitReachable
+		case ProblemBadPack:
+			code |= ExitPack
+		case ProblemBadRef:
+			code |= ExitRefs
+		}
+	}
+	return code
+}
+
+// link is a reference from one object to another, with the type the
+// referring object expects the target to have.
+type link struct {
+	hash objects.ObjectHash
+	typ  objects.ObjectType
+}
+
+// Checker verifies a repository.
+//
+// A check runs in two phases. First every loose and packed object is read,
+// re-hashed and validated, and the objects it links to are recorded:
+//
+//	tag    → its target (of the type the tag declares)
+//	commit → its tree and parents
+//	tree   → its entries (gitlinks point into another repository and are skipped)
+//
+// Then the links are followed from every ref, HEAD and every index entry.
+// Links to objects that are not stored anywhere, including alternates, are
+// missing; stored objects never reached are unreachable.
+//
+// Thread Safety:
+// Checker is not thread-safe, but only reads the repository.
+type Checker struct {
+	repo        *sourcerepo.SourceRepository
+	objectStore *store.FileObjectStore
+	refManager  *refs.RefManager
+	logger      *slog.Logger
+
+	report *Report
+	types  map[objects.ObjectHash]objects.ObjectType
+	links  map[objects.ObjectHash][]link
+}
+
+// NewChecker creates a checker for the repository.
+func NewChecker(repo *sourcerepo.SourceRepository) *Checker {
+	return &Checker{
+		repo:        repo,
+		objectStore: store.NewFileObjectStore(),
+		refManager:  refs.NewRefManager(repo),
+		logger:      logger.With("component", "fsck"),
+	}
+}
+
+// Initialize opens the repository's object store.
+func (c *Checker) Initialize(ctx context.Context) error {
+	if err := c.objectStore.Initialize(c.repo.WorkingDirectory()); err != nil {
+		return fmt.Errorf("failed to initialize object store: %w", err)
+	}
+	return nil
+}
+
+// Check verifies the whole repository.
+//
+// Parameters:
+//   - ctx: Context for cancellation
+//   - opts: Check options
+//
+// Returns:
+//   - *Report: Everything found; problems do not make Check fail
+//   - error: If the object directory cannot be listed or ctx is cancelled
+func (c *Checker) Check(ctx context.Context, opts Options) (*Report, error) {
+	c.report = &Report{strict: opts.Strict}
+	c.types = make(map[objects.ObjectHash]objects.ObjectType)
+	c.links = make(map[objects.ObjectHash][]link)
+
+	if err := c.checkLoose(ctx); err != nil {
+		return nil, err
+	}
+	if err := c.checkPacks(ctx); err != nil {
+		return nil, err
+	}
+
+	roots, err := c.roots()
+	if err != nil {
+		return nil, err
+	}
+	reachable, err := c.connectivity(ctx, roots)
+	if err != nil {
+		return nil, err
+	}
+	c.findUnreachable(reachable)
+
+	c.logger.Info("fsck finished",
+		"checked", c.report.Checked,
+		"problems", len(c.report.Problems),
+		"dangling", len(c.report.Dangling))
+
+	return c.report, nil
+}
+
+func (c *Checker) addProblem(p Problem) {
+	c.report.Problems = append(c.report.Problems, p)
+}
+
+// corrupt reports an object that cannot be trusted. It is still recorded,
+// with no type or links, so that reaching it is not reported a second time
+// as a missing object.
+func (c *Checker) corrupt(p Problem) {
+	p.Kind = ProblemCorrupt
+	c.addProblem(p)
+	c.types[p.Hash] = ""
+}
+
+// checkLoose verifies every loose object.
+func (c *Checker) checkLoose(ctx context.Context) error {
+	hashes, err := c.objectStore.LooseObjects()
+	if err != nil {
+		return err
+	}
+
+	for _, hash := range hashes {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		serialized, err := c.objectStore.ReadLooseObject(hash)
+		if err != nil {
+			c.corrupt(Problem{Hash: hash, Message: err.Error()})
+			continue
+		}
+		c.verify(hash, serialized)
+	}
+	return nil
+}
+
+// checkPacks verifies every pack's checksum and every object in it.
+func (c *Checker) checkPacks(ctx context.Context) error {
+	packs, err := c.objectStore.Packs()
+	if err != nil {
+		return err
+	}
+
+	for _, p := range packs {
+		if err := p.VerifyChecksum(); err != nil {
+			c.addProblem(Problem{Kind: ProblemBadPack, Message: err.Error()})
+		}
+
+		for _, hash := range p.Index().Hashes() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if _, seen := c.types[hash]; seen {
+				continue
+			}
+			serialized, err := c.objectStore.ReadPackedObject(p, hash)
+			if err != nil {
+				c.corrupt(Problem{Hash: hash, Message: fmt.Sprintf("in pack %s: %v", p.Path().Base(), err)})
+				continue
+			}
+			c.verify(hash, serialized)
+		}
+	}
+	return nil
+}
+
+// verify re-hashes one stored object, validates its content and records
+// its type and links.
+func (c *Checker) verify(hash objects.ObjectHash, serialized objects.SerializedObject) {
+	c.report.Checked++
+
+	objType, size, contentStart, err := serialized.ParseHeader()
+	if err != nil {
+		c.corrupt(Problem{Hash: hash, Message: err.Error()})
+		return
+	}
+	if got := hash.Algorithm().Sum(serialized); got != hash {
+		c.corrupt(Problem{Hash: hash, Type: objType,
+			Message: fmt.Sprintf("hash mismatch, content hashes to %s", got)})
+		return
+	}
+	if int64(len(serialized)-contentStart) != size.Int64() {
+		c.corrupt(Problem{Hash: hash, Type: objType,
+			Message: fmt.Sprintf("header declares %d bytes but object has %d", size.Int64(), len(serialized)-contentStart)})
+		return
+	}
+
+	c.types[hash] = objType
+	links, findings := validate(objType, serialized, hash.Algorithm())
+	for _, f := range findings {
+		f.Hash, f.Type = hash, objType
+		c.addProblem(f)
+	}
+	if len(links) > 0 {
+		c.links[hash] = links
+	}
+}
+
+// root is a starting point of the connectivity walk.
+type root struct {
+	hash objects.ObjectHash
+	from string
+}
+
+// roots collects the objects named by every ref, HEAD and the index.
+func (c *Checker) roots() ([]root, error) {
+	var result []root
+
+	refPaths, err := c.refManager.ListRefs()
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range refPaths {
+		hash, err := c.refManager.ResolveToSHA(ref)
+		if err != nil {
+			c.addProblem(Problem{Kind: ProblemBadRef, Message: fmt.Sprintf("%s: invalid ref: %v", ref, err)})
+			continue
+		}
+		result = append(result, root{hash: hash, from: ref.String()})
+	}
+
+	packed, err := c.refManager.PackedRefs()
+	if err != nil {
+		c.addProblem(Problem{Kind: ProblemBadRef, Message: err.Error()})
+	}
+	for _, ref := range packed {
+		result = append(result, root{hash: ref.Hash, from: ref.Ref.String()})
+	}
+
+	// An unborn branch is fine; a HEAD that names a bad object is not
+	if hash, err := c.refManager.ResolveToSHA(refs.RefHEAD); err == nil {
+		result = append(result, root{hash: hash, from: "HEAD"})
+	}
+
+	idx, err := index.Read(c.repo.SourceDirectory().IndexPath().ToAbsolutePath())
+	if err != nil {
+		c.addProblem(Problem{Kind: ProblemBadRef, Message: fmt.Sprintf("index: %v", err)})
+		return result, nil
+	}
+	for _, entry := range idx.Entries {
+		if entry.Mode.IsGitlink() {
+			continue
+		}
+		result = append(result, root{hash: entry.BlobHash, from: "index " + entry.Path.String()})
+	}
+
+	return result, nil
+}
+
+// connectivity follows links from the roots and returns every object
+// reached. Objects that were not verified locally, because they live in an
+// alternate, are read through the store to find their links.
+func (c *Checker) connectivity(ctx context.Context, roots []root) (map[objects.ObjectHash]bool, error) {
+	reached := make(map[objects.ObjectHash]bool)
+
+	type pending struct {
+		link
+		from string
+	}
+	var stack []pending
+	for i := len(roots) - 1; i >= 0; i-- {
+		stack = append(stack, pending{link: link{hash: roots[i].hash}, from: roots[i].from})
+	}
+
+	for len(stack) > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		item := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		objType, present := c.types[item.hash]
+		if !present {
+			objType, present = c.loadExternal(item.hash)
+		}
+		if !present {
+			kind := ProblemMissing
+			if item.typ == "" {
+				// Named directly by a ref or the index
+				kind = ProblemBadRef
+			}
+			c.addProblem(Problem{Kind: kind, Hash: item.hash, Type: item.typ, From: item.from,
+				Message: fmt.Sprintf("%s: invalid object %s", item.from, item.hash)})
+			continue
+		}
+		if item.typ != "" && objType != "" && item.typ != objType {
+			c.addProblem(Problem{Kind: ProblemInvalid, Hash: item.hash, Type: objType,
+				Message: fmt.Sprintf("%s links to it as a %s", item.from, item.typ)})
+		}
+
+		if reached[item.hash] {
+			continue
+		}
+		reached[item.hash] = true
+
+		links := c.links[item.hash]
+		for i := len(links) - 1; i >= 0; i-- {
+			stack = append(stack, pending{
+				link: links[i],
+				from: fmt.Sprintf("%s %s", objType, item.hash),
+			})
+		}
+	}
+
+	return reached, nil
+}
+
+// loadExternal reads an object that is not stored locally, normally one
+// borrowed from an alternate, and records its type and links. Borrowed
+// objects are not re-hashed: they belong to, and are checked with, the
+// repository that owns them.
+func (c *Checker) loadExternal(hash objects.ObjectHash) (objects.ObjectType, bool) {
+	if has, err := c.objectStore.HasObject(hash); err != nil || !has {
+		return "", false
+	}
+
+	obj, err := c.objectStore.ReadObject(hash)
+	if err != nil || obj == nil {
+		return "", false
+	}
+	content, err := obj.Content()
+	if err != nil {
+		return "", false
+	}
+
+	serialized := objects.NewSerializedObject(obj.Type(), content.Bytes())
+	links, _ := validate(obj.Type(), serialized, hash.Algorithm())
+	c.types[hash] = obj.Type()
+	c.links[hash] = links
+	return obj.Type(), true
+}
+
+// findUnreachable lists the stored objects that were not reached, and the
+// dangling ones among them.
+func (c *Checker) findUnreachable(reached map[objects.ObjectHash]bool) {
+	referenced := make(map[objects.ObjectHash]bool)
+	for _, links := range c.links {
+		for _, l := range links {
+			referenced[l.hash] = true
+		}
+	}
+
+	for hash, objType := range c.types {
+		// Corrupt objects have no type and were reported already
+		if reached[hash] || objType == "" {
+			continue
+		}
+		obj := Object{Hash: hash, Type: objType}
+		c.report.Unreachable = append(c.report.Unreachable, obj)
+		if !referenced[hash] {
+			c.report.Dangling = append(c.report.Dangling, obj)
+		}
+	}
+
+	byHash := func(list []Object) func(i, j int) bool {
+		return func(i, j int) bool { return list[i].Hash < list[j].Hash }
+	}
+	sort.Slice(c.report.Unreachable, byHash(c.report.Unreachable))
+	sort.Slice(c.report.Dangling, byHash(c.report.Dangling))
+}
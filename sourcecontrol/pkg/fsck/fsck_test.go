@@ -0,0 +1,430 @@
+package fsck
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/utkarsh5026/SourceControl/pkg/gc"
+	"github.com/utkarsh5026/SourceControl/pkg/index"
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/blob"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/commit"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/tree"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/refs"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/sourcerepo"
+	"github.com/utkarsh5026/SourceControl/pkg/store"
+)
+
+func setupTestRepo(t *testing.T) *sourcerepo.SourceRepository {
+	t.Helper()
+
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", t.TempDir())
+
+	repo := sourcerepo.NewSourceRepository()
+	if err := repo.Initialize(scpath.RepositoryPath(t.TempDir())); err != nil {
+		t.Fatalf("failed to initialize repo: %v", err)
+	}
+	return repo
+}
+
+// commitFile writes a commit holding a single file, with the given parents,
+// and returns it along with its tree and blob.
+func commitFile(t *testing.T, repo *sourcerepo.SourceRepository, content string, parents ...objects.ObjectHash) (c, tr, b objects.ObjectHash) {
+	t.Helper()
+
+	b, err := repo.WriteObject(blob.NewBlob([]byte(content)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, err := tree.NewTreeEntry(objects.FileModeRegular, "file.txt", b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr, err = repo.WriteObject(tree.NewTree([]*tree.TreeEntry{entry}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	person, err := commit.NewCommitPerson("Test User", "test@example.com", time.Unix(1700000000, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	built, err := commit.NewCommitBuilder().
+		TreeHash(tr).
+		ParentHashes(parents...).
+		Author(person).
+		Committer(person).
+		Message(content).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err = repo.WriteObject(built)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, tr, b
+}
+
+func setBranch(t *testing.T, repo *sourcerepo.SourceRepository, hash objects.ObjectHash) {
+	t.Helper()
+	if err := refs.NewRefManager(repo).UpdateRef("refs/heads/master", hash); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeLoose stores serialized at the loose path of hash, which need not be
+// the hash of serialized.
+func writeLoose(t *testing.T, repo *sourcerepo.SourceRepository, hash objects.ObjectHash, serialized []byte) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write(serialized)
+	zw.Close()
+
+	path := repo.ObjectsPath().ObjectFilePath(hash.String()).String()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(path)
+	if err := os.WriteFile(path, buf.Bytes(), 0444); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeRaw stores serialized under its own hash.
+func writeRaw(t *testing.T, repo *sourcerepo.SourceRepository, serialized []byte) objects.ObjectHash {
+	t.Helper()
+	hash := objects.SHA1.Sum(serialized)
+	writeLoose(t, repo, hash, serialized)
+	return hash
+}
+
+func runCheck(t *testing.T, repo *sourcerepo.SourceRepository, opts Options) *Report {
+	t.Helper()
+
+	checker := NewChecker(repo)
+	if err := checker.Initialize(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	report, err := checker.Check(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Check() failed: %v", err)
+	}
+	return report
+}
+
+func hasProblem(report *Report, kind ProblemKind, text string) bool {
+	for _, p := range report.Problems {
+		if p.Kind == kind && strings.Contains(p.String(), text) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCheck_Clean(t *testing.T) {
+	repo := setupTestRepo(t)
+	first, _, _ := commitFile(t, repo, "one\n")
+	second, _, _ := commitFile(t, repo, "two\n", first)
+	setBranch(t, repo, second)
+
+	report := runCheck(t, repo, Options{Strict: true})
+	if code := report.ExitCode(); code != 0 {
+		t.Fatalf("ExitCode() = %d, problems: %v", code, report.Problems)
+	}
+	if report.Checked != 6 {
+		t.Errorf("Checked = %d, want 6", report.Checked)
+	}
+	if len(report.Unreachable) != 0 {
+		t.Errorf("Unreachable = %v, want none", report.Unreachable)
+	}
+}
+
+func TestCheck_Dangling(t *testing.T) {
+	repo := setupTestRepo(t)
+	first, _, _ := commitFile(t, repo, "one\n")
+	setBranch(t, repo, first)
+
+	// An abandoned commit: it and its tree and blob are unreachable, but
+	// only the commit is dangling since it refers to the other two
+	lost, lostTree, lostBlob := commitFile(t, repo, "lost\n", first)
+
+	report := runCheck(t, repo, Options{})
+	if code := report.ExitCode(); code != 0 {
+		t.Fatalf("ExitCode() = %d, want 0: dangling objects are not errors", code)
+	}
+	if len(report.Dangling) != 1 || report.Dangling[0].Hash != lost || report.Dangling[0].Type != objects.CommitType {
+		t.Errorf("Dangling = %v, want commit %s", report.Dangling, lost)
+	}
+	unreachable := make(map[objects.ObjectHash]bool)
+	for _, obj := range report.Unreachable {
+		unreachable[obj.Hash] = true
+	}
+	if len(unreachable) != 3 || !unreachable[lost] || !unreachable[lostTree] || !unreachable[lostBlob] {
+		t.Errorf("Unreachable = %v, want the lost commit, tree and blob", report.Unreachable)
+	}
+}
+
+func TestCheck_PackedRef(t *testing.T) {
+	repo := setupTestRepo(t)
+	first, _, _ := commitFile(t, repo, "one\n")
+	setBranch(t, repo, first)
+
+	// A branch that only exists in packed-refs still makes its commit reachable
+	packedOnly, _, _ := commitFile(t, repo, "packed\n", first)
+	content := "# pack-refs with: peeled fully-peeled sorted \n" + packedOnly.String() + " refs/heads/packed\n"
+	packedPath := repo.SourceDirectory().PackedRefsPath().String()
+	if err := os.WriteFile(packedPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report := runCheck(t, repo, Options{})
+	if code := report.ExitCode(); code != 0 {
+		t.Fatalf("ExitCode() = %d, problems: %v", code, report.Problems)
+	}
+	if len(report.Dangling) != 0 || len(report.Unreachable) != 0 {
+		t.Errorf("Dangling = %v, Unreachable = %v; want none", report.Dangling, report.Unreachable)
+	}
+}
+
+func TestCheck_CorruptObject(t *testing.T) {
+	repo := setupTestRepo(t)
+	c, _, b := commitFile(t, repo, "one\n")
+	setBranch(t, repo, c)
+
+	writeLoose(t, repo, b, objects.NewSerializedObject(objects.BlobType, []byte("tampered\n")))
+
+	report := runCheck(t, repo, Options{})
+	if report.ExitCode() != ExitObject {
+		t.Fatalf("ExitCode() = %d, want %d; problems: %v", report.ExitCode(), ExitObject, report.Problems)
+	}
+	if !hasProblem(report, ProblemCorrupt, "hash mismatch") {
+		t.Errorf("hash mismatch not reported: %v", report.Problems)
+	}
+}
+
+func TestCheck_MissingObject(t *testing.T) {
+	repo := setupTestRepo(t)
+	first, _, _ := commitFile(t, repo, "one\n")
+	second, tr, _ := commitFile(t, repo, "two\n", first)
+	setBranch(t, repo, second)
+
+	if err := os.Remove(repo.ObjectsPath().ObjectFilePath(tr.String()).String()); err != nil {
+		t.Fatal(err)
+	}
+
+	report := runCheck(t, repo, Options{})
+	if report.ExitCode() != ExitReachable {
+		t.Fatalf("ExitCode() = %d, want %d; problems: %v", report.ExitCode(), ExitReachable, report.Problems)
+	}
+	if !hasProblem(report, ProblemMissing, "broken link from commit "+second.String()) {
+		t.Errorf("broken link not reported: %v", report.Problems)
+	}
+}
+
+func TestCheck_BadRefAndIndex(t *testing.T) {
+	repo := setupTestRepo(t)
+	c, _, _ := commitFile(t, repo, "one\n")
+	setBranch(t, repo, c)
+
+	missing := objects.SHA1.Sum([]byte("not stored"))
+	if err := refs.NewRefManager(repo).UpdateRef("refs/heads/broken", missing); err != nil {
+		t.Fatal(err)
+	}
+
+	idx := index.NewIndex()
+	entry := index.NewEntry("gone.txt")
+	entry.BlobHash = objects.SHA1.Sum([]byte("also not stored"))
+	idx.Add(entry)
+	if err := idx.Write(repo.SourceDirectory().IndexPath().ToAbsolutePath()); err != nil {
+		t.Fatal(err)
+	}
+
+	report := runCheck(t, repo, Options{})
+	if report.ExitCode() != ExitRefs {
+		t.Fatalf("ExitCode() = %d, want %d; problems: %v", report.ExitCode(), ExitRefs, report.Problems)
+	}
+	if !hasProblem(report, ProblemBadRef, "refs/heads/broken") || !hasProblem(report, ProblemBadRef, "index gone.txt") {
+		t.Errorf("bad ref and index entry not both reported: %v", report.Problems)
+	}
+}
+
+func TestCheck_InvalidObjects(t *testing.T) {
+	blobHash := objects.SHA1.Sum(objects.NewSerializedObject(objects.BlobType, []byte("x")))
+	raw, err := blobHash.Raw()
+	if err != nil {
+		t.Fatal(err)
+	}
+	treeEntry := func(mode, name string) []byte {
+		return append([]byte(mode+" "+name+"\x00"), raw...)
+	}
+	join := func(entries ...[]byte) []byte {
+		return bytes.Join(entries, nil)
+	}
+
+	person := "Test User <test@example.com> 1700000000 +0000"
+	treeHash := objects.SHA1.Sum(objects.NewSerializedObject(objects.TreeType, nil))
+
+	tests := []struct {
+		name    string
+		objType objects.ObjectType
+		content []byte
+		kind    ProblemKind
+		message string
+	}{
+		{
+			name:    "unsorted tree",
+			objType: objects.TreeType,
+			content: join(treeEntry("100644", "b"), treeEntry("100644", "a")),
+			kind:    ProblemInvalid,
+			message: "not properly sorted",
+		},
+		{
+			name:    "directory sorts as if it ended in a slash",
+			objType: objects.TreeType,
+			content: join(treeEntry("40000", "foo"), treeEntry("100644", "foo.c")),
+			kind:    ProblemInvalid,
+			message: "not properly sorted",
+		},
+		{
+			name:    "duplicate entries",
+			objType: objects.TreeType,
+			content: join(treeEntry("100644", "a"), treeEntry("100644", "a")),
+			kind:    ProblemInvalid,
+			message: "duplicate file entries",
+		},
+		{
+			name:    "zero-padded mode",
+			objType: objects.TreeType,
+			content: join(treeEntry("040000", "dir")),
+			kind:    ProblemWarning,
+			message: "zero-padded",
+		},
+		{
+			name:    "bad mode",
+			objType: objects.TreeType,
+			content: join(treeEntry("100664", "file")),
+			kind:    ProblemWarning,
+			message: "bad file mode 100664",
+		},
+		{
+			name:    "truncated tree",
+			objType: objects.TreeType,
+			content: []byte("100644 file\x00abc"),
+			kind:    ProblemInvalid,
+			message: "malformed tree entry",
+		},
+		{
+			name:    "commit without tree first",
+			objType: objects.CommitType,
+			content: []byte("author " + person + "\ntree " + treeHash.String() + "\ncommitter " + person + "\n\nmsg\n"),
+			kind:    ProblemInvalid,
+			message: "expected 'tree' line",
+		},
+		{
+			name:    "commit with bad author",
+			objType: objects.CommitType,
+			content: []byte("tree " + treeHash.String() + "\nauthor nobody\ncommitter " + person + "\n\nmsg\n"),
+			kind:    ProblemInvalid,
+			message: "invalid author",
+		},
+		{
+			name:    "tag without tagger",
+			objType: objects.TagType,
+			content: []byte("object " + treeHash.String() + "\ntype tree\ntag v1\n\nmsg\n"),
+			kind:    ProblemInvalid,
+			message: "expected 'tagger' line",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := setupTestRepo(t)
+			hash := writeRaw(t, repo, objects.NewSerializedObject(tt.objType, tt.content))
+
+			report := runCheck(t, repo, Options{})
+			if !hasProblem(report, tt.kind, tt.message) {
+				t.Fatalf("problem %q not reported for %s: %v", tt.message, hash.Short(), report.Problems)
+			}
+
+			strict := runCheck(t, repo, Options{Strict: true})
+			if strict.ExitCode()&ExitObject == 0 {
+				t.Errorf("strict ExitCode() = %d, want the object bit set", strict.ExitCode())
+			}
+			if tt.kind == ProblemWarning && report.ExitCode() != 0 {
+				t.Errorf("ExitCode() = %d for a warning, want 0 outside strict mode", report.ExitCode())
+			}
+		})
+	}
+}
+
+func TestCheck_Packs(t *testing.T) {
+	repo := setupTestRepo(t)
+	first, _, _ := commitFile(t, repo, "one\n")
+	second, _, _ := commitFile(t, repo, "two\n", first)
+	setBranch(t, repo, second)
+
+	mgr := gc.NewManager(repo)
+	if err := mgr.Initialize(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	result, err := mgr.Run(context.Background(), gc.Options{Pack: store.DefaultPackWriterOptions()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := runCheck(t, repo, Options{})
+	if report.ExitCode() != 0 || report.Checked != 6 {
+		t.Fatalf("packed repository: ExitCode() = %d, Checked = %d; problems: %v",
+			report.ExitCode(), report.Checked, report.Problems)
+	}
+
+	// Flip a byte inside the pack's object data
+	packPath := result.Pack.PackPath.String()
+	data, err := os.ReadFile(packPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len(data)/2] ^= 0xff
+	os.Chmod(packPath, 0644)
+	if err := os.WriteFile(packPath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report = runCheck(t, repo, Options{})
+	if report.ExitCode()&ExitPack == 0 {
+		t.Errorf("ExitCode() = %d, want the pack bit set; problems: %v", report.ExitCode(), report.Problems)
+	}
+}
+
+func TestValidateTree_Directories(t *testing.T) {
+	sub := objects.SHA1.Sum(objects.NewSerializedObject(objects.TreeType, nil))
+	file := objects.SHA1.Sum(objects.NewSerializedObject(objects.BlobType, nil))
+	subRaw, _ := sub.Raw()
+	fileRaw, _ := file.Raw()
+
+	// "foo.c" sorts before the directory "foo", which compares as "foo/"
+	content := bytes.Join([][]byte{
+		append([]byte("100644 foo.c\x00"), fileRaw...),
+		append([]byte("40000 foo\x00"), subRaw...),
+	}, nil)
+
+	links, problems := validateTree(content, objects.SHA1)
+	if len(problems) != 0 {
+		t.Fatalf("validateTree() problems = %v, want none", problems)
+	}
+	want := []link{{hash: file, typ: objects.BlobType}, {hash: sub, typ: objects.TreeType}}
+	if len(links) != 2 || links[0] != want[0] || links[1] != want[1] {
+		t.Errorf("validateTree() links = %v, want %v", links, want)
+	}
+}
@@ -0,0 +1,187 @@
+package fsck
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/commit"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/tag"
+)
+
+// validModes are the tree entry modes Git writes. Anything else is accepted
+// by Git with a warning, as old versions wrote e.g. 100664.
+var validModes = map[string]bool{
+	"100644": true,
+	"100755": true,
+	"120000": true,
+	"160000": true,
+	"40000":  true,
+}
+
+// validate checks the content of an object that already matched its hash
+// and returns the objects it links to along with any problems. The
+// problems carry only Kind and Message; the caller fills in the object.
+func validate(objType objects.ObjectType, serialized objects.SerializedObject, algorithm objects.HashAlgorithm) ([]link, []Problem) {
+	_, _, contentStart, err := serialized.ParseHeader()
+	if err != nil {
+		return nil, []Problem{{Kind: ProblemCorrupt, Message: err.Error()}}
+	}
+	content := serialized[contentStart:]
+
+	switch objType {
+	case objects.TreeType:
+		return validateTree(content, algorithm)
+	case objects.CommitType:
+		return validateCommit(serialized, content)
+	case objects.TagType:
+		return validateTag(serialized, content)
+	default:
+		return nil, nil
+	}
+}
+
+// treeEntry is a tree entry as stored, before any normalization.
+type treeEntry struct {
+	mode string
+	name string
+	hash objects.ObjectHash
+}
+
+func (e treeEntry) isDir() bool {
+	return e.mode == "40000" || e.mode == "040000"
+}
+
+// sortKey is the name tree entries are ordered by: Git compares directory
+// names as if they ended in '/', so "foo.c" sorts before the directory "foo".
+func (e treeEntry) sortKey() string {
+	if e.isDir() {
+		return e.name + "/"
+	}
+	return e.name
+}
+
+// validateTree parses tree content entry by entry and checks modes, names
+// and ordering.
+func validateTree(content []byte, algorithm objects.HashAlgorithm) ([]link, []Problem) {
+	var links []link
+	var problems []Problem
+	warn := func(format string, args ...any) {
+		problems = append(problems, Problem{Kind: ProblemWarning, Message: fmt.Sprintf(format, args...)})
+	}
+
+	var prev *treeEntry
+	for len(content) > 0 {
+		space := bytes.IndexByte(content, objects.SpaceByte)
+		nul := bytes.IndexByte(content, objects.NullByte)
+		if space <= 0 || nul < space || nul+1+algorithm.Size() > len(content) {
+			problems = append(problems, Problem{Kind: ProblemInvalid, Message: "malformed tree entry"})
+			return links, problems
+		}
+
+		entry := treeEntry{
+			mode: string(content[:space]),
+			name: string(content[space+1 : nul]),
+			hash: objects.NewObjectHashFromRaw(content[nul+1 : nul+1+algorithm.Size()]),
+		}
+		content = content[nul+1+algorithm.Size():]
+
+		switch {
+		case entry.mode == "040000":
+			warn("contains zero-padded file modes")
+		case !validModes[entry.mode]:
+			warn("contains bad file mode %s for %q", entry.mode, entry.name)
+		}
+
+		switch {
+		case entry.name == "":
+			warn("contains empty pathname")
+		case strings.Contains(entry.name, "/"):
+			warn("contains full pathnames")
+		case entry.name == "." || entry.name == "..":
+			warn("contains '.' or '..'")
+		case strings.EqualFold(entry.name, ".git"):
+			warn("contains '.git'")
+		}
+
+		if prev != nil {
+			switch {
+			case prev.name == entry.name:
+				problems = append(problems, Problem{Kind: ProblemInvalid,
+					Message: fmt.Sprintf("contains duplicate file entries for %q", entry.name)})
+			case prev.sortKey() > entry.sortKey():
+				problems = append(problems, Problem{Kind: ProblemInvalid,
+					Message: fmt.Sprintf("not properly sorted: %q before %q", prev.name, entry.name)})
+			}
+		}
+		prev = &entry
+
+		switch {
+		case entry.mode == "160000":
+			// Commits of another repository
+		case entry.isDir():
+			links = append(links, link{hash: entry.hash, typ: objects.TreeType})
+		default:
+			links = append(links, link{hash: entry.hash, typ: objects.BlobType})
+		}
+	}
+
+	return links, problems
+}
+
+// headerOrder checks that the header lines of a commit or tag start with
+// the given keys in order. Keys ending in '*' may repeat or be absent.
+func headerOrder(content []byte, keys ...string) error {
+	header, _, _ := bytes.Cut(content, []byte("\n\n"))
+	lines := strings.Split(string(header), "\n")
+
+	i := 0
+	for _, key := range keys {
+		repeat := strings.HasSuffix(key, "*")
+		key = strings.TrimSuffix(key, "*")
+
+		if !repeat {
+			if i >= len(lines) || !strings.HasPrefix(lines[i], key+" ") {
+				return fmt.Errorf("invalid format - expected '%s' line", key)
+			}
+			i++
+			continue
+		}
+		for i < len(lines) && strings.HasPrefix(lines[i], key+" ") {
+			i++
+		}
+	}
+	return nil
+}
+
+// validateCommit checks a commit's header order and fields.
+func validateCommit(serialized objects.SerializedObject, content []byte) ([]link, []Problem) {
+	if err := headerOrder(content, "tree", "parent*", "author", "committer"); err != nil {
+		return nil, []Problem{{Kind: ProblemInvalid, Message: err.Error()}}
+	}
+
+	c, err := commit.ParseCommit(serialized)
+	if err != nil {
+		return nil, []Problem{{Kind: ProblemInvalid, Message: err.Error()}}
+	}
+
+	links := []link{{hash: c.TreeSHA, typ: objects.TreeType}}
+	for _, parent := range c.ParentSHAs {
+		links = append(links, link{hash: parent, typ: objects.CommitType})
+	}
+	return links, nil
+}
+
+// validateTag checks an annotated tag's header order and fields.
+func validateTag(serialized objects.SerializedObject, content []byte) ([]link, []Problem) {
+	if err := headerOrder(content, "object", "type", "tag", "tagger"); err != nil {
+		return nil, []Problem{{Kind: ProblemInvalid, Message: err.Error()}}
+	}
+
+	t, err := tag.ParseTag(serialized)
+	if err != nil {
+		return nil, []Problem{{Kind: ProblemInvalid, Message: err.Error()}}
+	}
+	return []link{{hash: t.ObjectSHA, typ: t.ObjectType}}, nil
+}
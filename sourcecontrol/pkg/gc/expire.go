@@ -0,0 +1,79 @@
+package gc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultPruneExpire is the grace period gc gives unreachable objects when
+// gc.pruneExpire is not set.
+const DefaultPruneExpire = "2.weeks.ago"
+
+// expireUnits maps the units accepted in relative expiry dates to their length.
+var expireUnits = map[string]time.Duration{
+	"second": time.Second,
+	"minute": time.Minute,
+	"hour":   time.Hour,
+	"day":    24 * time.Hour,
+	"week":   7 * 24 * time.Hour,
+	"month":  30 * 24 * time.Hour,
+	"year":   365 * 24 * time.Hour,
+}
+
+// expireLayouts are the absolute date formats ParseExpire accepts.
+var expireLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// ParseExpire parses an expiry date in the forms git prune --expire accepts:
+//
+//	now                          everything expires
+//	never, false                 nothing expires (returns the zero time)
+//	2.weeks.ago, 3 days ago      relative to now
+//	2024-01-31, 2024-01-31T12:00:00Z
+//	@1700000000                  seconds since the epoch
+//
+// Objects last written before the returned time have expired.
+func ParseExpire(value string, now time.Time) (time.Time, error) {
+	v := strings.ToLower(strings.TrimSpace(value))
+
+	switch v {
+	case "now", "all":
+		return now, nil
+	case "never", "false":
+		return time.Time{}, nil
+	}
+
+	if seconds, ok := strings.CutPrefix(v, "@"); ok {
+		n, err := strconv.ParseInt(seconds, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid expiry date %q", value)
+		}
+		return time.Unix(n, 0), nil
+	}
+
+	if rel, ok := strings.CutSuffix(strings.ReplaceAll(v, ".", " "), " ago"); ok {
+		fields := strings.Fields(rel)
+		if len(fields) != 2 {
+			return time.Time{}, fmt.Errorf("invalid expiry date %q", value)
+		}
+		n, err := strconv.Atoi(fields[0])
+		unit, known := expireUnits[strings.TrimSuffix(fields[1], "s")]
+		if err != nil || n < 0 || !known {
+			return time.Time{}, fmt.Errorf("invalid expiry date %q", value)
+		}
+		return now.Add(-time.Duration(n) * unit), nil
+	}
+
+	for _, layout := range expireLayouts {
+		if t, err := time.ParseInLocation(layout, strings.TrimSpace(value), time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid expiry date %q", value)
+}
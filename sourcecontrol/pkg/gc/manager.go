@@ -0,0 +1,312 @@
+// Package gc implements repository maintenance: packing objects into
+// delta-compressed packfiles and removing the loose copies they replace.
+package gc
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/utkarsh5026/SourceControl/pkg/common/logger"
+	"github.com/utkarsh5026/SourceControl/pkg/config"
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/sourcerepo"
+	"github.com/utkarsh5026/SourceControl/pkg/store"
+)
+
+// RepackOptions configures a repack.
+type RepackOptions struct {
+	// Pack controls the delta search window and chain depth
+	Pack store.PackWriterOptions
+
+	// RemoveRedundant deletes packs and loose objects made redundant by
+	// the new pack (git repack -d)
+	RemoveRedundant bool
+}
+
+// Options configures a gc run.
+type Options struct {
+	// Auto only runs when the number of loose objects exceeds gc.auto
+	Auto bool
+
+	// Pack controls the delta search window and chain depth
+	Pack store.PackWriterOptions
+
+	// Prune is the expiry date for unreachable loose objects, in any form
+	// ParseExpire accepts. Empty uses gc.pruneExpire.
+	Prune string
+}
+
+// Result describes what a repack or gc did.
+type Result struct {
+	// Skipped is set when an automatic gc found nothing to do
+	Skipped bool
+
+	// Pack is the newly written pack, nil if the repository has no objects
+	Pack *store.PackWriteResult
+
+	// PacksRemoved is the number of old packs replaced by the new one
+	PacksRemoved int
+
+	// LooseRemoved is the number of loose objects deleted because they are packed
+	LooseRemoved int
+
+	// Pruned is the number of expired unreachable loose objects gc deleted
+	Pruned int
+}
+
+// Manager packs a repository's objects.
+//
+// A repack collects every object reachable from the Walker's roots, plus every
+// object already stored in a pack (so nothing that was packed before can be
+// lost, reachable or not), and writes them into a single new pack. Objects
+// borrowed from alternates are left out. Loose objects that are not
+// reachable are left alone; removing them is the job of Prune, which applies
+// an expiry grace period.
+//
+// Thread Safety:
+// Manager is not thread-safe, and a repack must not run concurrently with
+// another repack of the same repository.
+type Manager struct {
+	repo          *sourcerepo.SourceRepository
+	objectStore   *store.FileObjectStore
+	walker        *Walker
+	configManager *config.Manager
+	typedConfig   *config.TypedConfig
+	logger        *slog.Logger
+}
+
+// NewManager creates a gc manager for the repository.
+func NewManager(repo *sourcerepo.SourceRepository) *Manager {
+	configMgr := config.NewManager(repo.WorkingDirectory())
+
+	return &Manager{
+		repo:          repo,
+		objectStore:   store.NewFileObjectStore(),
+		walker:        NewWalker(repo),
+		configManager: configMgr,
+		typedConfig:   config.NewTypedConfig(configMgr),
+		logger:        logger.With("component", "gc"),
+	}
+}
+
+// Initialize loads configuration and opens the object store.
+func (m *Manager) Initialize(ctx context.Context) error {
+	if err := m.configManager.Load(ctx); err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	if err := m.objectStore.Initialize(m.repo.WorkingDirectory()); err != nil {
+		return fmt.Errorf("init object store: %w", err)
+	}
+
+	return nil
+}
+
+// NeedsAuto reports whether an automatic gc should run, i.e. whether the
+// number of loose objects exceeds gc.auto. It is always false for SHA-256
+// repositories.
+func (m *Manager) NeedsAuto() (bool, error) {
+	threshold := m.typedConfig.GCAuto()
+	if threshold <= 0 {
+		return false, nil
+	}
+
+	// Objects of SHA-256 repositories cannot be packed, so there is never
+	// anything for an automatic gc to do
+	if m.objectStore.HashAlgorithm() != objects.SHA1 {
+		return false, nil
+	}
+
+	count, err := m.objectStore.LooseObjectCount()
+	if err != nil {
+		return false, err
+	}
+
+	return count > threshold, nil
+}
+
+// Run performs a full gc: it repacks everything into one pack, removes the
+// packs and loose objects that became redundant, and prunes unreachable
+// loose objects older than the grace period. With opts.Auto it does nothing
+// unless NeedsAuto reports true.
+func (m *Manager) Run(ctx context.Context, opts Options) (*Result, error) {
+	if opts.Auto {
+		needed, err := m.NeedsAuto()
+		if err != nil {
+			return nil, err
+		}
+		if !needed {
+			return &Result{Skipped: true}, nil
+		}
+	}
+
+	expire, err := m.PruneExpire(opts.Prune)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := m.Repack(ctx, RepackOptions{Pack: opts.Pack, RemoveRedundant: true})
+	if err != nil {
+		return result, err
+	}
+
+	pruned, err := m.Prune(ctx, PruneOptions{Expire: expire})
+	if err != nil {
+		return result, fmt.Errorf("prune: %w", err)
+	}
+	result.Pruned = len(pruned.Pruned)
+	return result, nil
+}
+
+// PruneExpire parses an expiry date for Prune, falling back to gc.pruneExpire
+// when value is empty.
+func (m *Manager) PruneExpire(value string) (time.Time, error) {
+	if value == "" {
+		value = m.typedConfig.GCPruneExpire()
+	}
+	return ParseExpire(value, time.Now())
+}
+
+// Repack writes all reachable and already-packed objects into a new pack.
+func (m *Manager) Repack(ctx context.Context, opts RepackOptions) (*Result, error) {
+	if algo := m.objectStore.HashAlgorithm(); algo != objects.SHA1 {
+		return nil, fmt.Errorf("%w: repository uses %s", store.ErrPackObjectFormat, algo)
+	}
+
+	packObjects, err := m.collectObjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	if len(packObjects) == 0 {
+		m.logger.Info("nothing to pack")
+		return result, nil
+	}
+
+	oldPacks, err := m.objectStore.Packs()
+	if err != nil {
+		return nil, err
+	}
+	// Copy: the store's slice changes as packs are added and removed
+	oldPacks = append([]*store.Packfile(nil), oldPacks...)
+
+	packDir := m.repo.SourceDirectory().ObjectsPath().Join(scpath.PackDir).ToAbsolutePath()
+	written, err := store.WritePack(packDir, packObjects, m.loadPackObject, opts.Pack)
+	if err != nil {
+		return nil, fmt.Errorf("write pack: %w", err)
+	}
+	result.Pack = written
+
+	m.logger.Info("wrote pack",
+		"pack", written.PackPath.Base(),
+		"objects", written.Objects,
+		"deltas", written.Deltas)
+
+	if !opts.RemoveRedundant {
+		return result, nil
+	}
+
+	for _, p := range oldPacks {
+		if p.Path() == written.PackPath {
+			continue
+		}
+		if err := m.objectStore.RemovePack(p); err != nil {
+			return result, err
+		}
+		result.PacksRemoved++
+	}
+
+	removed, err := m.objectStore.PrunePacked()
+	result.LooseRemoved = removed
+	if err != nil {
+		return result, fmt.Errorf("remove packed loose objects: %w", err)
+	}
+
+	return result, nil
+}
+
+// loadPackObject returns an object's content for WritePack. It is packed as
+// stored: parsing and serializing again could change the bytes, and with
+// them the hash.
+func (m *Manager) loadPackObject(hash objects.ObjectHash) ([]byte, error) {
+	serialized, err := m.objectStore.ReadSerialized(hash)
+	if err != nil {
+		return nil, err
+	}
+	if serialized == nil {
+		return nil, fmt.Errorf("missing object %s", hash)
+	}
+	_, _, contentStart, err := serialized.ParseHeader()
+	if err != nil {
+		return nil, err
+	}
+	return serialized[contentStart:], nil
+}
+
+// collectObjects gathers the objects for the new pack: everything reachable
+// first (which also provides path hints for delta selection), then any
+// packed object the walk did not reach.
+func (m *Manager) collectObjects(ctx context.Context) ([]*store.PackObject, error) {
+	roots, err := m.walker.Roots()
+	if err != nil {
+		return nil, fmt.Errorf("collect roots: %w", err)
+	}
+
+	reachable, err := m.walker.Walk(ctx, roots)
+	if err != nil {
+		return nil, fmt.Errorf("walk objects: %w", err)
+	}
+
+	included := make(map[objects.ObjectHash]bool, len(reachable))
+	var result []*store.PackObject
+	add := func(hash objects.ObjectHash, path string) error {
+		if included[hash] {
+			return nil
+		}
+		// Objects borrowed from alternates stay there (git repack -l)
+		if local, err := m.objectStore.HasLocalObject(hash); err != nil {
+			return fmt.Errorf("read object %s: %w", hash.Short(), err)
+		} else if !local {
+			return nil
+		}
+		// Only the header is read here; WritePack loads the content when
+		// it needs it, through loadPackObject
+		objType, size, err := m.objectStore.ReadObjectInfo(hash)
+		if err != nil {
+			return fmt.Errorf("read object %s: %w", hash.Short(), err)
+		}
+
+		included[hash] = true
+		result = append(result, &store.PackObject{
+			Hash: hash,
+			Type: objType,
+			Size: size,
+			Path: path,
+		})
+		return nil
+	}
+
+	for _, r := range reachable {
+		if err := add(r.Hash, r.Path); err != nil {
+			return nil, err
+		}
+	}
+
+	packs, err := m.objectStore.Packs()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range packs {
+		for _, hash := range p.Index().Hashes() {
+			if err := add(hash, ""); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return result, nil
+}
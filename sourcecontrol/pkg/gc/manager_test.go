@@ -0,0 +1,267 @@
+package gc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/utkarsh5026/SourceControl/pkg/config"
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/blob"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/commit"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/tree"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/refs"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/sourcerepo"
+	"github.com/utkarsh5026/SourceControl/pkg/store"
+)
+
+// setupTestRepo creates an empty repository with an isolated HOME so the
+// user's own configuration does not leak into the tests.
+func setupTestRepo(t *testing.T) *sourcerepo.SourceRepository {
+	t.Helper()
+
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", t.TempDir())
+
+	repo := sourcerepo.NewSourceRepository()
+	if err := repo.Initialize(scpath.RepositoryPath(t.TempDir())); err != nil {
+		t.Fatalf("failed to initialize repo: %v", err)
+	}
+	return repo
+}
+
+// writeHistory creates n commits on master, each changing file.txt slightly.
+func writeHistory(t *testing.T, repo *sourcerepo.SourceRepository, n int) []objects.ObjectHash {
+	t.Helper()
+
+	var body strings.Builder
+	for i := 0; i < 300; i++ {
+		fmt.Fprintf(&body, "line %d of the file under test\n", i)
+	}
+
+	person, err := commit.NewCommitPerson("Test User", "test@example.com", time.Unix(1700000000, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var commits []objects.ObjectHash
+	var parent objects.ObjectHash
+	for i := 0; i < n; i++ {
+		blobHash, err := repo.WriteObject(blob.NewBlob([]byte(body.String() + fmt.Sprintf("revision %d\n", i))))
+		if err != nil {
+			t.Fatal(err)
+		}
+		entry, err := tree.NewTreeEntry(objects.FileModeRegular, "file.txt", blobHash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		treeHash, err := repo.WriteObject(tree.NewTree([]*tree.TreeEntry{entry}))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		builder := commit.NewCommitBuilder().
+			TreeHash(treeHash).
+			Author(person).
+			Committer(person).
+			Message(fmt.Sprintf("commit %d", i))
+		if parent != "" {
+			builder = builder.ParentHash(parent)
+		}
+		c, err := builder.Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		parent, err = repo.WriteObject(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		commits = append(commits, parent)
+	}
+
+	if err := refs.NewRefManager(repo).UpdateRef("refs/heads/master", parent); err != nil {
+		t.Fatal(err)
+	}
+	return commits
+}
+
+func newObjectStore(t *testing.T, repo *sourcerepo.SourceRepository) *store.FileObjectStore {
+	t.Helper()
+	fs := store.NewFileObjectStore()
+	if err := fs.Initialize(repo.WorkingDirectory()); err != nil {
+		t.Fatal(err)
+	}
+	return fs
+}
+
+func TestWalker_Walk(t *testing.T) {
+	repo := setupTestRepo(t)
+	commits := writeHistory(t, repo, 3)
+
+	w := NewWalker(repo)
+	roots, err := w.Roots()
+	if err != nil {
+		t.Fatalf("Roots() failed: %v", err)
+	}
+	if len(roots) != 1 || roots[0] != commits[2] {
+		t.Fatalf("Roots() = %v, want [%s]", roots, commits[2])
+	}
+
+	reachable, err := w.Walk(context.Background(), roots)
+	if err != nil {
+		t.Fatalf("Walk() failed: %v", err)
+	}
+
+	// 3 commits + 3 trees + 3 blobs
+	if len(reachable) != 9 {
+		t.Fatalf("Walk() found %d objects, want 9", len(reachable))
+	}
+	for _, obj := range reachable {
+		if obj.Type == objects.BlobType && obj.Path != "file.txt" {
+			t.Errorf("blob %s has path %q, want file.txt", obj.Hash.Short(), obj.Path)
+		}
+	}
+}
+
+func TestWalker_MissingObject(t *testing.T) {
+	repo := setupTestRepo(t)
+	commits := writeHistory(t, repo, 2)
+
+	c, err := repo.ReadCommitObject(commits[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := repo.ObjectsPath().ObjectFilePath(c.TreeSHA.String())
+	if err := os.Remove(path.String()); err != nil {
+		t.Fatal(err)
+	}
+
+	w := NewWalker(repo)
+	if _, err := w.Walk(context.Background(), []objects.ObjectHash{commits[1]}); err == nil {
+		t.Error("Walk() should fail when an object is missing")
+	}
+}
+
+func TestManager_Run(t *testing.T) {
+	repo := setupTestRepo(t)
+	commits := writeHistory(t, repo, 10)
+
+	unreachable, err := repo.WriteObject(blob.NewBlob([]byte("dangling\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	mgr := NewManager(repo)
+	if err := mgr.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize() failed: %v", err)
+	}
+
+	result, err := mgr.Run(ctx, Options{Pack: store.DefaultPackWriterOptions()})
+	if err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+	if result.Pack == nil {
+		t.Fatal("Run() wrote no pack")
+	}
+	if result.Pack.Objects != 30 {
+		t.Errorf("packed %d objects, want 30", result.Pack.Objects)
+	}
+	if result.Pack.Deltas == 0 {
+		t.Error("expected file revisions to be stored as deltas")
+	}
+	if result.LooseRemoved != 30 {
+		t.Errorf("LooseRemoved = %d, want 30", result.LooseRemoved)
+	}
+
+	fs := newObjectStore(t, repo)
+	loose, err := fs.LooseObjects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loose) != 1 || loose[0] != unreachable {
+		t.Errorf("loose objects after gc = %v, want only the unreachable blob", loose)
+	}
+
+	// Writing an object that is already packed leaves no loose copy behind
+	packed, err := repo.ReadCommitObject(commits[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.WriteObject(packed); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := fs.LooseObjectCount(); err != nil || n != 1 {
+		t.Errorf("LooseObjectCount() after rewriting a packed commit = %d, %v; want 1", n, err)
+	}
+
+	for _, hash := range commits {
+		if c, err := repo.ReadCommitObject(hash); err != nil || c == nil {
+			t.Errorf("ReadCommitObject(%s) after gc = %v, %v", hash.Short(), c, err)
+		}
+	}
+
+	// A second gc replaces the first pack and must not lose anything.
+	second, err := mgr.Run(ctx, Options{Pack: store.DefaultPackWriterOptions()})
+	if err != nil {
+		t.Fatalf("second Run() failed: %v", err)
+	}
+	packs, err := fs.Packs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(packs) != 1 || packs[0].Path() != second.Pack.PackPath {
+		t.Errorf("expected exactly the new pack to remain, got %d packs", len(packs))
+	}
+
+	if _, err := exec.LookPath("git"); err == nil {
+		cmd := exec.Command("git", "fsck", "--full", "--no-dangling")
+		cmd.Dir = repo.WorkingDirectory().String()
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Errorf("git fsck failed after gc: %v\n%s", err, out)
+		}
+	}
+}
+
+func TestManager_RunAuto(t *testing.T) {
+	repo := setupTestRepo(t)
+	writeHistory(t, repo, 3)
+
+	ctx := context.Background()
+	mgr := NewManager(repo)
+	if err := mgr.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize() failed: %v", err)
+	}
+
+	result, err := mgr.Run(ctx, Options{Auto: true, Pack: store.DefaultPackWriterOptions()})
+	if err != nil {
+		t.Fatalf("Run(auto) failed: %v", err)
+	}
+	if !result.Skipped {
+		t.Error("auto gc should be skipped below the default threshold")
+	}
+
+	if err := mgr.configManager.Set("gc.auto", "5", config.RepositoryLevel); err != nil {
+		t.Fatalf("failed to set gc.auto: %v", err)
+	}
+
+	result, err = mgr.Run(ctx, Options{Auto: true, Pack: store.DefaultPackWriterOptions()})
+	if err != nil {
+		t.Fatalf("Run(auto) failed: %v", err)
+	}
+	if result.Skipped || result.Pack == nil {
+		t.Error("auto gc should run once loose objects exceed gc.auto")
+	}
+
+	if err := mgr.configManager.Set("gc.auto", "0", config.RepositoryLevel); err != nil {
+		t.Fatalf("failed to set gc.auto: %v", err)
+	}
+	if needed, _ := mgr.NeedsAuto(); needed {
+		t.Error("gc.auto=0 should disable automatic gc")
+	}
+}
@@ -0,0 +1,155 @@
+package gc
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+)
+
+// PruneOptions configures a prune.
+type PruneOptions struct {
+	// Expire is the end of the grace period: only unreachable loose objects
+	// written before it are removed. The zero time removes nothing.
+	Expire time.Time
+
+	// DryRun lists what would be removed without removing it
+	DryRun bool
+}
+
+// PrunedObject is an unreachable loose object removed (or, in a dry run,
+// selected for removal) by Prune.
+type PrunedObject struct {
+	Hash    objects.ObjectHash
+	Type    objects.ObjectType
+	ModTime time.Time
+}
+
+// PruneResult describes what a prune did.
+type PruneResult struct {
+	// Pruned lists the expired unreachable objects, sorted by hash
+	Pruned []PrunedObject
+
+	// Kept is the number of unreachable objects still inside the grace
+	// period, together with the objects they refer to
+	Kept int
+}
+
+// Prune removes loose objects that cannot be reached from any ref, HEAD,
+// ORIG_HEAD, MERGE_HEAD or the index and were written before opts.Expire.
+//
+// The grace period protects objects another command has just written but
+// not yet referenced: a commit being created writes its blobs and trees
+// before the ref that makes them reachable. For the same reason an expired
+// object that a recent unreachable object refers to is kept as well:
+//
+//	ref ──▶ C2 ──▶ C1          reachable: kept
+//	        C3 (new) ──▶ T1    C3 recent: kept, and T1 with it
+//	        C0 (old)           expired: pruned
+//
+// Packed objects are never pruned; gc leaves every packed object in the
+// new pack.
+//
+// Parameters:
+//   - ctx: Context for cancellation
+//   - opts: The expiry date and whether to only report
+//
+// Returns:
+//   - *PruneResult: The objects pruned and how many were kept
+//   - error: If the reachable set cannot be computed, in which case nothing
+//     is removed
+func (m *Manager) Prune(ctx context.Context, opts PruneOptions) (*PruneResult, error) {
+	roots, err := m.walker.Roots()
+	if err != nil {
+		return nil, fmt.Errorf("collect roots: %w", err)
+	}
+	reachable, err := m.walker.Walk(ctx, roots)
+	if err != nil {
+		return nil, fmt.Errorf("walk objects: %w", err)
+	}
+
+	keep := make(map[objects.ObjectHash]bool, len(reachable))
+	for _, obj := range reachable {
+		keep[obj.Hash] = true
+	}
+
+	loose, err := m.objectStore.LooseObjects()
+	if err != nil {
+		return nil, err
+	}
+
+	var recent []objects.ObjectHash
+	var expired []PrunedObject
+	for _, hash := range loose {
+		if keep[hash] {
+			continue
+		}
+		modTime, err := m.objectStore.LooseObjectModTime(hash)
+		if err != nil {
+			return nil, err
+		}
+		if opts.Expire.IsZero() || modTime.After(opts.Expire) {
+			recent = append(recent, hash)
+			continue
+		}
+		expired = append(expired, PrunedObject{Hash: hash, ModTime: modTime})
+	}
+
+	// Everything a recent object refers to survives with it
+	protected, err := m.walker.WalkPresent(ctx, recent, keep)
+	if err != nil {
+		return nil, fmt.Errorf("walk recent objects: %w", err)
+	}
+	for _, obj := range protected {
+		keep[obj.Hash] = true
+	}
+
+	result := &PruneResult{Kept: len(recent)}
+	for _, obj := range expired {
+		if keep[obj.Hash] {
+			result.Kept++
+			continue
+		}
+		obj.Type = m.looseObjectType(obj.Hash)
+		result.Pruned = append(result.Pruned, obj)
+	}
+	sort.Slice(result.Pruned, func(i, j int) bool {
+		return result.Pruned[i].Hash < result.Pruned[j].Hash
+	})
+
+	if opts.DryRun {
+		return result, nil
+	}
+
+	for _, obj := range result.Pruned {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		if err := m.objectStore.RemoveLooseObject(obj.Hash); err != nil {
+			return result, err
+		}
+	}
+
+	m.logger.Info("pruned unreachable objects",
+		"pruned", len(result.Pruned),
+		"kept", result.Kept)
+
+	return result, nil
+}
+
+// looseObjectType returns the type recorded in a loose object's header, or
+// an empty type if the object cannot be read. Unreadable objects are still
+// pruned: nothing can use them.
+func (m *Manager) looseObjectType(hash objects.ObjectHash) objects.ObjectType {
+	serialized, err := m.objectStore.ReadLooseObject(hash)
+	if err != nil || serialized == nil {
+		return ""
+	}
+	objType, _, _, err := serialized.ParseHeader()
+	if err != nil {
+		return ""
+	}
+	return objType
+}
@@ -0,0 +1,262 @@
+package gc
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/utkarsh5026/SourceControl/pkg/index"
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/blob"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/tree"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/sourcerepo"
+	"github.com/utkarsh5026/SourceControl/pkg/store"
+)
+
+// age sets a loose object's modification time to d in the past.
+func age(t *testing.T, repo *sourcerepo.SourceRepository, hash objects.ObjectHash, d time.Duration) {
+	t.Helper()
+	path := repo.ObjectsPath().ObjectFilePath(hash.String()).String()
+	when := time.Now().Add(-d)
+	if err := os.Chtimes(path, when, when); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeBlob(t *testing.T, repo *sourcerepo.SourceRepository, content string) objects.ObjectHash {
+	t.Helper()
+	hash, err := repo.WriteObject(blob.NewBlob([]byte(content)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
+
+func newManager(t *testing.T, repo *sourcerepo.SourceRepository) *Manager {
+	t.Helper()
+	mgr := NewManager(repo)
+	if err := mgr.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize() failed: %v", err)
+	}
+	return mgr
+}
+
+func TestWalker_RootsIncludeIndexAndPseudoRefs(t *testing.T) {
+	repo := setupTestRepo(t)
+	commits := writeHistory(t, repo, 3)
+
+	staged := writeBlob(t, repo, "staged only\n")
+	idx := index.NewIndex()
+	entry := index.NewEntry("staged.txt")
+	entry.BlobHash = staged
+	idx.Add(entry)
+	if err := idx.Write(repo.SourceDirectory().IndexPath().ToAbsolutePath()); err != nil {
+		t.Fatal(err)
+	}
+
+	gitDir := repo.SourceDirectory().String()
+	if err := os.WriteFile(filepath.Join(gitDir, "ORIG_HEAD"), []byte(commits[0].String()+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mergeHead := commits[1].String() + "\n" + commits[0].String() + "\n"
+	if err := os.WriteFile(filepath.Join(gitDir, "MERGE_HEAD"), []byte(mergeHead), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	roots, err := NewWalker(repo).Roots()
+	if err != nil {
+		t.Fatalf("Roots() failed: %v", err)
+	}
+
+	want := map[objects.ObjectHash]bool{commits[2]: true, commits[1]: true, commits[0]: true, staged: true}
+	if len(roots) != len(want) {
+		t.Fatalf("Roots() = %v, want %d distinct roots", roots, len(want))
+	}
+	for _, root := range roots {
+		if !want[root] {
+			t.Errorf("unexpected root %s", root)
+		}
+	}
+}
+
+func TestManager_PruneKeepsPackedRefs(t *testing.T) {
+	repo := setupTestRepo(t)
+	commits := writeHistory(t, repo, 2)
+
+	// Move the second commit onto a branch that only exists in packed-refs
+	branch := filepath.Join(repo.SourceDirectory().RefsPath().String(), "heads", "master")
+	if err := os.WriteFile(branch, []byte(commits[0].String()+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	content := "# pack-refs with: peeled fully-peeled sorted \n" + commits[1].String() + " refs/heads/packed\n"
+	if err := os.WriteFile(repo.SourceDirectory().PackedRefsPath().String(), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	roots, err := NewWalker(repo).Roots()
+	if err != nil {
+		t.Fatalf("Roots() failed: %v", err)
+	}
+	found := false
+	for _, root := range roots {
+		found = found || root == commits[1]
+	}
+	if !found {
+		t.Errorf("Roots() = %v, want the packed branch's commit %s", roots, commits[1].Short())
+	}
+
+	result, err := newManager(t, repo).Prune(context.Background(), PruneOptions{Expire: time.Now().Add(time.Second)})
+	if err != nil {
+		t.Fatalf("Prune() failed: %v", err)
+	}
+	if len(result.Pruned) != 0 {
+		t.Errorf("Pruned = %v, want nothing", result.Pruned)
+	}
+	if _, err := repo.ReadCommitObject(commits[1]); err != nil {
+		t.Errorf("commit reachable from a packed ref was pruned: %v", err)
+	}
+}
+
+func TestManager_Prune(t *testing.T) {
+	repo := setupTestRepo(t)
+	commits := writeHistory(t, repo, 2)
+	for _, c := range commits {
+		age(t, repo, c, 30*24*time.Hour)
+	}
+
+	oldBlob := writeBlob(t, repo, "old and unreachable\n")
+	age(t, repo, oldBlob, 30*24*time.Hour)
+	newBlob := writeBlob(t, repo, "new and unreachable\n")
+
+	// A recent tree keeps the old blob it refers to alive
+	referencedBlob := writeBlob(t, repo, "old but referenced by a new tree\n")
+	age(t, repo, referencedBlob, 30*24*time.Hour)
+	entry, err := tree.NewTreeEntry(objects.FileModeRegular, "kept.txt", referencedBlob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newTree, err := repo.WriteObject(tree.NewTree([]*tree.TreeEntry{entry}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	mgr := newManager(t, repo)
+	expire, err := ParseExpire("2.weeks.ago", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dry, err := mgr.Prune(ctx, PruneOptions{Expire: expire, DryRun: true})
+	if err != nil {
+		t.Fatalf("Prune(dry run) failed: %v", err)
+	}
+	if len(dry.Pruned) != 1 || dry.Pruned[0].Hash != oldBlob || dry.Pruned[0].Type != objects.BlobType {
+		t.Fatalf("dry run selected %v, want only blob %s", dry.Pruned, oldBlob)
+	}
+	if dry.Kept != 3 {
+		t.Errorf("Kept = %d, want 3 (new blob, new tree and the blob it refers to)", dry.Kept)
+	}
+	fs := newObjectStore(t, repo)
+	if has, _ := fs.HasObject(oldBlob); !has {
+		t.Fatal("dry run removed an object")
+	}
+
+	result, err := mgr.Prune(ctx, PruneOptions{Expire: expire})
+	if err != nil {
+		t.Fatalf("Prune() failed: %v", err)
+	}
+	if len(result.Pruned) != 1 {
+		t.Fatalf("Pruned = %v, want one object", result.Pruned)
+	}
+	if has, _ := fs.HasObject(oldBlob); has {
+		t.Error("expired unreachable blob survived prune")
+	}
+	for _, hash := range []objects.ObjectHash{newBlob, newTree, referencedBlob} {
+		if has, _ := fs.HasObject(hash); !has {
+			t.Errorf("object %s inside the grace period was pruned", hash.Short())
+		}
+	}
+	for _, c := range commits {
+		if _, err := repo.ReadCommitObject(c); err != nil {
+			t.Errorf("reachable commit %s was pruned: %v", c.Short(), err)
+		}
+	}
+
+	// --expire=now removes the rest; never removes nothing
+	never, err := mgr.Prune(ctx, PruneOptions{})
+	if err != nil || len(never.Pruned) != 0 {
+		t.Errorf("Prune(never) = %v, %v; want nothing pruned", never, err)
+	}
+	all, err := mgr.Prune(ctx, PruneOptions{Expire: time.Now().Add(time.Second)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all.Pruned) != 3 {
+		t.Errorf("Prune(now) pruned %d objects, want 3", len(all.Pruned))
+	}
+}
+
+func TestManager_RunPrunes(t *testing.T) {
+	repo := setupTestRepo(t)
+	writeHistory(t, repo, 2)
+	old := writeBlob(t, repo, "old\n")
+	age(t, repo, old, 30*24*time.Hour)
+	recent := writeBlob(t, repo, "recent\n")
+
+	result, err := newManager(t, repo).Run(context.Background(), Options{Pack: store.DefaultPackWriterOptions()})
+	if err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+	if result.Pruned != 1 {
+		t.Errorf("Pruned = %d, want 1", result.Pruned)
+	}
+
+	fs := newObjectStore(t, repo)
+	if has, _ := fs.HasObject(old); has {
+		t.Error("gc kept an unreachable object past gc.pruneExpire")
+	}
+	if has, _ := fs.HasObject(recent); !has {
+		t.Error("gc pruned an object inside the grace period")
+	}
+}
+
+func TestParseExpire(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "now", want: now},
+		{value: "never", want: time.Time{}},
+		{value: "2.weeks.ago", want: now.Add(-14 * 24 * time.Hour)},
+		{value: "3 days ago", want: now.Add(-72 * time.Hour)},
+		{value: "1.hour.ago", want: now.Add(-time.Hour)},
+		{value: "2024-01-31T10:00:00Z", want: time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC)},
+		{value: "@1700000000", want: time.Unix(1700000000, 0)},
+		{value: "2.fortnights.ago", wantErr: true},
+		{value: "yesterday-ish", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseExpire(tt.value, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseExpire(%q) = %v, want error", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseExpire(%q) failed: %v", tt.value, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseExpire(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
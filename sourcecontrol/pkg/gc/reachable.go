@@ -0,0 +1,239 @@
+package gc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/utkarsh5026/SourceControl/pkg/index"
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/commit"
+	tagobj "github.com/utkarsh5026/SourceControl/pkg/objects/tag"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/tree"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/refs"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/sourcerepo"
+	"github.com/utkarsh5026/SourceControl/pkg/store"
+)
+
+// ReachableObject is an object found while walking history.
+type ReachableObject struct {
+	Hash objects.ObjectHash
+	Type objects.ObjectType
+
+	// Path is where a blob or tree was found, relative to the repository
+	// root. It is empty for commits, tags and root trees.
+	Path string
+}
+
+// Walker enumerates every object reachable from a set of starting points:
+// tags lead to their target, commits to their tree and parents, and trees
+// to their entries. Submodule entries (gitlinks) point into another
+// repository and are not followed. Blobs listed in trees are only checked
+// for existence, never read.
+type Walker struct {
+	repo       *sourcerepo.SourceRepository
+	store      store.ObjectStore
+	refManager *refs.RefManager
+}
+
+// NewWalker creates a walker reading objects from the repository's store.
+func NewWalker(repo *sourcerepo.SourceRepository) *Walker {
+	return &Walker{
+		repo:       repo,
+		store:      repo.ObjectStore(),
+		refManager: refs.NewRefManager(repo),
+	}
+}
+
+// Roots returns the objects that must be kept, without duplicates: the
+// targets of every loose and packed ref and of HEAD, ORIG_HEAD and
+// MERGE_HEAD, and every blob staged in the index. A HEAD pointing at an
+// unborn branch contributes nothing, and gitlink entries in the index name
+// commits of another repository and are skipped.
+func (w *Walker) Roots() ([]objects.ObjectHash, error) {
+	refPaths, err := w.refManager.ListRefs()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[objects.ObjectHash]bool)
+	var roots []objects.ObjectHash
+	add := func(hash objects.ObjectHash) {
+		if !seen[hash] {
+			seen[hash] = true
+			roots = append(roots, hash)
+		}
+	}
+
+	for _, ref := range refPaths {
+		hash, err := w.refManager.ResolveToSHA(ref)
+		if err != nil {
+			return nil, fmt.Errorf("resolve %s: %w", ref, err)
+		}
+		add(hash)
+	}
+
+	packed, err := w.refManager.PackedRefs()
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range packed {
+		add(ref.Hash)
+	}
+
+	if hash, err := w.refManager.ResolveToSHA(refs.RefHEAD); err == nil {
+		add(hash)
+	}
+
+	for _, ref := range []refs.RefPath{refs.RefOrigHead, refs.RefMergeHead} {
+		hashes, err := w.readPseudoRef(ref)
+		if err != nil {
+			return nil, err
+		}
+		for _, hash := range hashes {
+			add(hash)
+		}
+	}
+
+	idx, err := index.Read(w.repo.SourceDirectory().IndexPath().ToAbsolutePath())
+	if err != nil {
+		return nil, fmt.Errorf("read index: %w", err)
+	}
+	for _, entry := range idx.Entries {
+		if !entry.Mode.IsGitlink() {
+			add(entry.BlobHash)
+		}
+	}
+
+	return roots, nil
+}
+
+// readPseudoRef returns the hashes listed in a file such as MERGE_HEAD,
+// which may hold one per line (an octopus merge records every head being
+// merged). A missing file lists nothing.
+func (w *Walker) readPseudoRef(ref refs.RefPath) ([]objects.ObjectHash, error) {
+	data, err := os.ReadFile(w.repo.SourceDirectory().Join(ref.String()).String())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", ref, err)
+	}
+
+	var hashes []objects.ObjectHash
+	for _, line := range strings.Split(string(data), "\n") {
+		field, _, _ := strings.Cut(strings.TrimSpace(line), " ")
+		if field == "" {
+			continue
+		}
+		hash, err := objects.NewObjectHashFromString(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", ref, err)
+		}
+		hashes = append(hashes, hash)
+	}
+	return hashes, nil
+}
+
+// Walk returns every object reachable from roots, each exactly once, in the
+// order they were discovered. A missing or unreadable object is an error:
+// callers rely on the result being the complete closure.
+func (w *Walker) Walk(ctx context.Context, roots []objects.ObjectHash) ([]ReachableObject, error) {
+	return w.walk(ctx, roots, nil, false)
+}
+
+// WalkPresent is like Walk but skips objects that are missing, returning
+// whatever part of the closure exists. Objects in skip, and everything only
+// reachable through them, are not visited again.
+func (w *Walker) WalkPresent(ctx context.Context, roots []objects.ObjectHash, skip map[objects.ObjectHash]bool) ([]ReachableObject, error) {
+	return w.walk(ctx, roots, skip, true)
+}
+
+func (w *Walker) walk(ctx context.Context, roots []objects.ObjectHash, skip map[objects.ObjectHash]bool, allowMissing bool) ([]ReachableObject, error) {
+	type pending struct {
+		hash objects.ObjectHash
+		path string
+
+		// blob is set for objects a tree lists as files, which only need
+		// to exist: their content leads nowhere
+		blob bool
+	}
+
+	seen := make(map[objects.ObjectHash]bool)
+	var result []ReachableObject
+
+	stack := make([]pending, 0, len(roots))
+	for i := len(roots) - 1; i >= 0; i-- {
+		stack = append(stack, pending{hash: roots[i]})
+	}
+
+	for len(stack) > 0 {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		item := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[item.hash] || skip[item.hash] {
+			continue
+		}
+		seen[item.hash] = true
+
+		if item.blob {
+			ok, err := w.store.HasObject(item.hash)
+			if err != nil && !allowMissing {
+				return nil, fmt.Errorf("read object %s: %w", item.hash.Short(), err)
+			}
+			if !ok || err != nil {
+				if allowMissing {
+					continue
+				}
+				return nil, fmt.Errorf("missing object %s", item.hash)
+			}
+			result = append(result, ReachableObject{Hash: item.hash, Type: objects.BlobType, Path: item.path})
+			continue
+		}
+
+		obj, err := w.store.ReadObject(item.hash)
+		if err != nil && !allowMissing {
+			return nil, fmt.Errorf("read object %s: %w", item.hash.Short(), err)
+		}
+		if obj == nil || err != nil {
+			if allowMissing {
+				continue
+			}
+			return nil, fmt.Errorf("missing object %s", item.hash)
+		}
+
+		result = append(result, ReachableObject{Hash: item.hash, Type: obj.Type(), Path: item.path})
+
+		switch o := obj.(type) {
+		case *tagobj.Tag:
+			stack = append(stack, pending{hash: o.ObjectSHA})
+		case *commit.Commit:
+			for i := len(o.ParentSHAs) - 1; i >= 0; i-- {
+				stack = append(stack, pending{hash: o.ParentSHAs[i]})
+			}
+			stack = append(stack, pending{hash: o.TreeSHA})
+		case *tree.Tree:
+			entries := o.Entries()
+			for i := len(entries) - 1; i >= 0; i-- {
+				entry := entries[i]
+				if entry.IsSubmodule() {
+					continue
+				}
+				stack = append(stack, pending{
+					hash: entry.SHA(),
+					path: path.Join(item.path, entry.Name().String()),
+					blob: entry.IsFile() || entry.IsSymbolicLink(),
+				})
+			}
+		}
+	}
+
+	return result, nil
+}
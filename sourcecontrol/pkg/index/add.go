@@ -0,0 +1,430 @@
+package index
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/ignore"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+	"github.com/utkarsh5026/SourceControl/pkg/store"
+)
+
+// ErrNothingSpecified is returned by AddWithOptions when it is given no
+// pathspecs and neither All nor Update.
+var ErrNothingSpecified = errors.New("nothing specified, nothing added")
+
+// AddOptions configures AddWithOptions.
+type AddOptions struct {
+	// All stages every change in the working tree, new files and deletions
+	// included, when no pathspec is given (add -A). With pathspecs it
+	// changes nothing, since deletions of matching files are always staged.
+	All bool
+
+	// Update stages modifications and deletions of tracked files only,
+	// never adding new ones (add -u)
+	Update bool
+
+	// Force adds files even if they are ignored (add -f)
+	Force bool
+
+	// DryRun reports what would be staged without writing objects or the
+	// index (add --dry-run)
+	DryRun bool
+}
+
+// AddWithOptions stages the files that pathspecs select, like git add.
+//
+// A pathspec is a file, a directory, whose files are added recursively, or
+// a glob such as "*.go" or "src/*_test.go". Untracked files matching the
+// repository's ignore rules are skipped unless opts.Force is set; those a
+// pathspec names directly are reported in AddResult.Ignored. Tracked files
+// that a pathspec selects but that no longer exist are removed from the
+// index, and files whose content is unchanged are not reported. Except with
+// opts.Update, a pathspec that selects nothing is reported in
+// AddResult.Failed.
+//
+// Parameters:
+//   - ctx: Cancels hashing the selected files; the index is then left as it was
+//   - pathspecs: Paths or globs, relative to the repository root or absolute
+//   - objectStore: Where the blobs of staged files are written
+//   - opts: Which changes to stage, and whether to write anything
+//
+// Returns:
+//   - *AddResult: The paths added, modified, removed, ignored and failed
+//   - error: ErrNothingSpecified, the context's error, or if the index cannot
+//     be locked or saved
+func (m *Manager) AddWithOptions(ctx context.Context, pathspecs []string, objectStore store.ObjectStore, opts AddOptions) (*AddResult, error) {
+	if len(pathspecs) == 0 && !opts.All && !opts.Update {
+		return nil, ErrNothingSpecified
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var lock *Lock
+	if opts.DryRun {
+		index, err := Read(m.indexPath.ToAbsolutePath())
+		if err != nil {
+			return nil, fmt.Errorf("failed to load index: %w", err)
+		}
+		m.index = index
+	} else {
+		var err error
+		if lock, err = m.lockIndex(); err != nil {
+			return nil, err
+		}
+		defer lock.Rollback()
+	}
+
+	result := &AddResult{
+		Added:    make([]string, 0),
+		Modified: make([]string, 0),
+		Removed:  make([]string, 0),
+		Ignored:  make([]string, 0),
+		Failed:   make([]AddFailureResult, 0),
+	}
+
+	specs := make([]*pathspec, 0, len(pathspecs))
+	for _, original := range pathspecs {
+		spec, err := m.parsePathspec(original)
+		if err != nil {
+			result.Failed = append(result.Failed, AddFailureResult{Path: original, Reason: err.Error()})
+			continue
+		}
+		specs = append(specs, spec)
+	}
+	if len(pathspecs) > 0 && len(specs) == 0 {
+		return result, nil
+	}
+
+	files, removed := m.selectFiles(specs, opts, result)
+	conflicted := make(map[scpath.RelativePath]bool)
+	for _, path := range m.index.GetConflictedPaths() {
+		conflicted[path] = true
+	}
+
+	pending := make([]*pendingFile, 0, len(files))
+	for _, path := range files {
+		file := &pendingFile{path: path, conflicted: conflicted[path]}
+		if existing, ok := m.index.Get(path); ok {
+			file.existing = existing
+		}
+		pending = append(pending, file)
+	}
+	modes := LoadModeSettings(m.repoRoot)
+	wp := newWorkerPool[*pendingFile, *pendingFile](m.repoRoot)
+	err := wp.ProcessOrdered(ctx, pending, func(_ context.Context, file *pendingFile) (*pendingFile, error) {
+		m.hashFile(file, objectStore, modes, opts.DryRun)
+		return file, nil
+	}, func(_ int, file *pendingFile) error {
+		if err := m.stageFile(file, opts.DryRun, result); err != nil {
+			result.Failed = append(result.Failed, AddFailureResult{Path: file.path.String(), Reason: err.Error()})
+		}
+		return nil
+	})
+	if err != nil {
+		// Only some files were staged; the deferred Rollback keeps the index
+		return nil, fmt.Errorf("failed to stage files: %w", err)
+	}
+	for _, path := range removed {
+		if !opts.DryRun {
+			m.index.Remove(path)
+			m.index.RemoveConflict(path)
+		}
+		result.Removed = append(result.Removed, path.String())
+	}
+
+	for _, spec := range specs {
+		if !spec.matched && !opts.Update {
+			result.Failed = append(result.Failed, AddFailureResult{Path: spec.original, Reason: "pathspec did not match any files"})
+		}
+	}
+
+	if opts.DryRun {
+		return result, nil
+	}
+	if err := lock.Commit(m.index); err != nil {
+		return result, fmt.Errorf("failed to save index: %w", err)
+	}
+	return result, nil
+}
+
+// parsePathspec resolves a pathspec against the repository root.
+func (m *Manager) parsePathspec(original string) (*pathspec, error) {
+	_, relPath, err := m.resolvePaths(original)
+	if err != nil {
+		return nil, err
+	}
+	if !scpath.IsPathSafe(relPath.String()) {
+		return nil, fmt.Errorf("'%s' is outside repository", original)
+	}
+	return newPathspec(original, relPath)
+}
+
+// selectFiles finds the files to stage and the tracked paths to remove,
+// both sorted. Untracked files come from walking the working tree, unless
+// opts.Update is set; tracked files are taken from the index, so that they
+// are updated even if ignore rules or an ignored directory would hide them.
+func (m *Manager) selectFiles(specs []*pathspec, opts AddOptions, result *AddResult) (files, removed []scpath.RelativePath) {
+	selected := make(map[scpath.RelativePath]bool)
+
+	if !opts.Update {
+		matcher := ignore.NewMatcher(m.repoRoot)
+		if len(specs) == 0 {
+			m.walkWorktree("", matcher, opts.Force, func(path scpath.RelativePath, ignored bool) {
+				if !ignored || m.index.Has(path) {
+					selected[path] = true
+				}
+			})
+		}
+		for _, spec := range specs {
+			m.selectUntracked(spec, matcher, opts.Force, selected, result)
+		}
+	}
+
+	for _, entry := range m.index.Entries {
+		path := entry.Path
+		if !matchAny(specs, path.String()) {
+			continue
+		}
+		for _, spec := range specs {
+			if spec.matches(path.String()) {
+				spec.matched = true
+			}
+		}
+
+		absPath := m.repoRoot.Join(path.String())
+		info, err := os.Lstat(absPath.String())
+		switch {
+		case err == nil && !info.IsDir():
+			selected[path] = true
+		case err == nil && (entry.TreeMode().IsGitlink() || isNestedRepository(absPath)):
+			selected[path] = true
+		case err != nil && os.IsNotExist(err) || err == nil && info.IsDir():
+			removed = append(removed, path)
+		}
+	}
+
+	for path := range selected {
+		files = append(files, path)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i] < files[j] })
+	removed = uniquePaths(removed)
+	return files, removed
+}
+
+// selectUntracked adds the working tree files that spec selects to
+// selected. An ignored file or directory it names directly is reported in
+// result instead; ignored files found inside a directory or matched by a
+// glob are skipped.
+func (m *Manager) selectUntracked(spec *pathspec, matcher *ignore.Matcher, force bool, selected map[scpath.RelativePath]bool, result *AddResult) {
+	if spec.glob != nil {
+		m.walkWorktree(spec.base(), matcher, force, func(path scpath.RelativePath, ignored bool) {
+			if spec.matches(path.String()) && (!ignored || m.index.Has(path)) {
+				spec.matched = true
+				selected[path] = true
+			}
+		})
+		return
+	}
+
+	info, err := os.Lstat(m.repoRoot.Join(spec.path).String())
+	if err != nil {
+		return
+	}
+	spec.matched = true
+	path := scpath.RelativePath(spec.path)
+	ignored := !force && spec.path != "" && matcher.IsIgnored(spec.path, info.IsDir())
+
+	switch {
+	case ignored && !(info.Mode().IsRegular() && m.index.Has(path)):
+		result.Ignored = append(result.Ignored, spec.path)
+	case !info.IsDir() || spec.path != "" && isNestedRepository(m.repoRoot.Join(spec.path)):
+		selected[path] = true
+	default:
+		m.walkWorktree(spec.path, matcher, force, func(path scpath.RelativePath, ignored bool) {
+			if !ignored {
+				selected[path] = true
+			}
+		})
+	}
+}
+
+// walkWorktree calls visit for every file below dir, a repository-relative
+// directory ("" for the root), telling it whether the file is ignored.
+// Repository directories are never entered, nor are ignored directories
+// unless force is set; a nested repository is visited as a single path,
+// to be staged as a gitlink. Entries that cannot be read are skipped.
+func (m *Manager) walkWorktree(dir string, matcher *ignore.Matcher, force bool, visit func(path scpath.RelativePath, ignored bool)) {
+	root := m.repoRoot.String()
+	start := filepath.Join(root, filepath.FromSlash(dir))
+
+	filepath.WalkDir(start, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			if d.Name() == scpath.SourceDir {
+				return filepath.SkipDir
+			}
+			if p != start && !force && matcher.IsIgnored(rel, true) {
+				return filepath.SkipDir
+			}
+			if p != root && isNestedRepository(scpath.AbsolutePath(p)) {
+				visit(scpath.RelativePath(rel), !force && matcher.IsIgnored(rel, true))
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		visit(scpath.RelativePath(rel), !force && matcher.IsIgnored(rel, false))
+		return nil
+	})
+}
+
+// pendingFile is a working tree file selected for staging. Its stat data
+// and blob hash are read by a worker of the pool in hashFile, and then
+// stageFile records it in the index, one file at a time.
+type pendingFile struct {
+	path       scpath.RelativePath
+	existing   *Entry // the stage 0 entry of a tracked file, or nil
+	conflicted bool
+
+	info os.FileInfo
+	mode FileMode           // the mode to stage, see ModeSettings.WorktreeMode
+	hash objects.ObjectHash // empty when the stat data shows no change
+	err  error
+}
+
+// tracked reports whether the file has a stage 0 entry that is neither
+// conflicted nor only intended to be added, whose stat data can be
+// compared with the file.
+func (f *pendingFile) tracked() bool {
+	return f.existing != nil && !f.conflicted && !f.existing.IntentToAdd
+}
+
+// hashFile reads the stat data of file and, unless it shows a tracked file
+// to be unchanged, hashes it, writing its blob unless dryRun is set. A
+// symbolic link is not followed: its blob is the path it points to. A
+// directory is a nested repository, see hashGitlink. It does not change
+// the index, so files can be hashed in parallel.
+func (m *Manager) hashFile(file *pendingFile, objectStore store.ObjectStore, modes ModeSettings, dryRun bool) {
+	absPath := m.repoRoot.Join(file.path.String())
+	info, err := os.Lstat(absPath.String())
+	if err != nil {
+		file.err = fmt.Errorf("failed to stat file: %w", err)
+		return
+	}
+	file.info = info
+	if info.IsDir() {
+		m.hashGitlink(file, absPath)
+		return
+	}
+	file.mode = modes.WorktreeMode(info, file.existing)
+
+	if file.tracked() && m.index.MatchesStat(file.existing, info) && file.existing.TreeMode() == file.mode {
+		return
+	}
+
+	if dryRun {
+		file.hash, file.err = HashWorktree(absPath, info, objectStore.HashAlgorithm())
+	} else {
+		file.hash, file.err = writeBlob(absPath, info, objectStore)
+	}
+}
+
+// hashGitlink sets the hash of file, the directory of a nested repository,
+// to the commit checked out in it. A tracked submodule that is not checked
+// out is left as staged.
+func (m *Manager) hashGitlink(file *pendingFile, absPath scpath.AbsolutePath) {
+	file.mode = FileModeGitlink
+	commit, err := gitlinkCommit(absPath)
+	switch {
+	case err == nil:
+		file.hash = commit
+	case file.existing != nil && file.existing.TreeMode().IsGitlink() && !isNestedRepository(absPath):
+		// not checked out, so there is nothing to stage
+	default:
+		file.err = fmt.Errorf("'%s' does not have a commit checked out", file.path)
+	}
+}
+
+// stageFile stages a file hashed by hashFile, recording it in result as
+// added or modified. A tracked file whose stat data shows it unchanged was
+// not read, and one whose content turns out to be unchanged only has its
+// entry's stat data refreshed.
+func (m *Manager) stageFile(file *pendingFile, dryRun bool, result *AddResult) error {
+	if file.err != nil {
+		return file.err
+	}
+	if file.hash == "" {
+		return nil
+	}
+
+	var entry *Entry
+	if file.mode.IsGitlink() {
+		entry = NewEntry(file.path)
+		entry.BlobHash = file.hash
+	} else {
+		var err error
+		if entry, err = NewEntryFromFileInfo(file.path, file.info, file.hash); err != nil {
+			return fmt.Errorf("failed to create entry: %w", err)
+		}
+	}
+	entry.Mode = file.mode
+
+	if file.tracked() && file.existing.BlobHash == file.hash && file.existing.TreeMode() == entry.TreeMode() {
+		if !dryRun && !file.mode.IsGitlink() {
+			file.existing.updateStat(file.info)
+		}
+		return nil
+	}
+
+	if !dryRun {
+		if file.conflicted {
+			m.index.RemoveConflict(file.path)
+		}
+		m.index.Add(entry)
+	}
+	if file.existing != nil || file.conflicted {
+		result.Modified = append(result.Modified, file.path.String())
+	} else {
+		result.Added = append(result.Added, file.path.String())
+	}
+	return nil
+}
+
+// hashBlob computes the hash the file at absPath would have as a blob,
+// without storing it.
+func hashBlob(absPath scpath.AbsolutePath, info os.FileInfo, algorithm objects.HashAlgorithm) (objects.ObjectHash, error) {
+	file, err := os.Open(absPath.String())
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+	defer file.Close()
+	return algorithm.HashObjectFromReader(objects.BlobType, file, info.Size())
+}
+
+// uniquePaths sorts paths and drops duplicates, which the index holds for
+// each stage of a conflict.
+func uniquePaths(paths []scpath.RelativePath) []scpath.RelativePath {
+	sort.Slice(paths, func(i, j int) bool { return paths[i] < paths[j] })
+	unique := paths[:0]
+	for i, path := range paths {
+		if i == 0 || path != paths[i-1] {
+			unique = append(unique, path)
+		}
+	}
+	return unique
+}
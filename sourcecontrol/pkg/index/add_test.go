@@ -0,0 +1,213 @@
+package index
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+	"github.com/utkarsh5026/SourceControl/pkg/store"
+)
+
+// writeWorktree creates files (slash-separated path -> content) under root.
+func writeWorktree(t *testing.T, root scpath.RepositoryPath, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		full := filepath.Join(root.String(), filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// stagedPaths returns the paths in the index at indexPath.
+func stagedPaths(t *testing.T, indexPath scpath.AbsolutePath) []string {
+	t.Helper()
+	idx, err := Read(indexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := make([]string, 0, idx.Count())
+	for _, path := range idx.Paths() {
+		paths = append(paths, path.String())
+	}
+	return paths
+}
+
+func setupAddTest(t *testing.T) (*Manager, scpath.RepositoryPath, scpath.AbsolutePath, store.ObjectStore) {
+	t.Helper()
+	root, indexPath := setupLockTestRepo(t)
+	writeWorktree(t, root, map[string]string{
+		".gitignore":       "*.log\nbuild/\n",
+		"README.md":        "readme",
+		"src/main.go":      "package main",
+		"src/util/util.go": "package util",
+		"src/debug.log":    "log",
+		"build/out.bin":    "binary",
+	})
+	return NewManager(root), root, indexPath, store.NewMemoryObjectStore()
+}
+
+func TestManager_AddDirectory(t *testing.T) {
+	m, _, indexPath, objectStore := setupAddTest(t)
+
+	result, err := m.Add([]string{"."}, objectStore)
+	if err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+
+	want := []string{".gitignore", "README.md", "src/main.go", "src/util/util.go"}
+	if !reflect.DeepEqual(result.Added, want) {
+		t.Errorf("Added = %v, want %v", result.Added, want)
+	}
+	if got := stagedPaths(t, indexPath); !reflect.DeepEqual(got, want) {
+		t.Errorf("staged = %v, want %v", got, want)
+	}
+	if len(result.Failed) != 0 || len(result.Ignored) != 0 {
+		t.Errorf("Failed = %v, Ignored = %v", result.Failed, result.Ignored)
+	}
+
+	// Adding again changes nothing
+	result, err = m.Add([]string{"."}, objectStore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Added)+len(result.Modified)+len(result.Removed) != 0 {
+		t.Errorf("second Add() reported changes: %+v", result)
+	}
+}
+
+func TestManager_AddPathspecs(t *testing.T) {
+	m, _, indexPath, objectStore := setupAddTest(t)
+
+	result, err := m.Add([]string{"*.go", "missing.txt", "src/debug.log"}, objectStore)
+	if err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+
+	if want := []string{"src/main.go", "src/util/util.go"}; !reflect.DeepEqual(stagedPaths(t, indexPath), want) {
+		t.Errorf("staged = %v, want %v", stagedPaths(t, indexPath), want)
+	}
+	if want := []string{"src/debug.log"}; !reflect.DeepEqual(result.Ignored, want) {
+		t.Errorf("Ignored = %v, want %v", result.Ignored, want)
+	}
+	if len(result.Failed) != 1 || result.Failed[0].Path != "missing.txt" {
+		t.Errorf("Failed = %v, want missing.txt", result.Failed)
+	}
+}
+
+func TestManager_AddForce(t *testing.T) {
+	m, _, indexPath, objectStore := setupAddTest(t)
+
+	if _, err := m.AddWithOptions(context.Background(), []string{"build", "src/debug.log"}, objectStore, AddOptions{Force: true}); err != nil {
+		t.Fatalf("AddWithOptions() failed: %v", err)
+	}
+	if want := []string{"build/out.bin", "src/debug.log"}; !reflect.DeepEqual(stagedPaths(t, indexPath), want) {
+		t.Errorf("staged = %v, want %v", stagedPaths(t, indexPath), want)
+	}
+}
+
+func TestManager_AddAllAndUpdate(t *testing.T) {
+	m, root, indexPath, objectStore := setupAddTest(t)
+
+	if _, err := m.Add([]string{"README.md", "src"}, objectStore); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(root.String(), "src", "main.go")); err != nil {
+		t.Fatal(err)
+	}
+	writeWorktree(t, root, map[string]string{"README.md": "changed readme", "NEW.md": "new"})
+
+	if _, err := m.AddWithOptions(context.Background(), nil, objectStore, AddOptions{}); !errors.Is(err, ErrNothingSpecified) {
+		t.Errorf("AddWithOptions() with nothing err = %v, want ErrNothingSpecified", err)
+	}
+
+	// -u stages the change and the deletion, but not the new file
+	result, err := m.AddWithOptions(context.Background(), nil, objectStore, AddOptions{Update: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result.Modified, []string{"README.md"}) || !reflect.DeepEqual(result.Removed, []string{"src/main.go"}) || len(result.Added) != 0 {
+		t.Errorf("Update result = %+v", result)
+	}
+	if want := []string{"README.md", "src/util/util.go"}; !reflect.DeepEqual(stagedPaths(t, indexPath), want) {
+		t.Errorf("staged = %v, want %v", stagedPaths(t, indexPath), want)
+	}
+
+	// -A also adds new files
+	result, err = m.AddWithOptions(context.Background(), nil, objectStore, AddOptions{All: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{".gitignore", "NEW.md"}; !reflect.DeepEqual(result.Added, want) {
+		t.Errorf("All Added = %v, want %v", result.Added, want)
+	}
+}
+
+func TestManager_AddDryRun(t *testing.T) {
+	m, _, indexPath, objectStore := setupAddTest(t)
+
+	result, err := m.AddWithOptions(context.Background(), []string{"src"}, objectStore, AddOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"src/main.go", "src/util/util.go"}; !reflect.DeepEqual(result.Added, want) {
+		t.Errorf("Added = %v, want %v", result.Added, want)
+	}
+	if _, err := os.Stat(indexPath.String()); !os.IsNotExist(err) {
+		t.Error("dry run should not write the index")
+	}
+}
+
+func TestPathspec_Matches(t *testing.T) {
+	tests := []struct {
+		spec string
+		path string
+		want bool
+	}{
+		{".", "a/b.go", true},
+		{"src", "src/a.go", true},
+		{"src", "srcs/a.go", false},
+		{"*.go", "src/deep/a.go", true},
+		{"*.go", "a.txt", false},
+		{"src/*_test.go", "src/a_test.go", true},
+		{"src/?.go", "src/a.go", true},
+		{"src/?.go", "src/ab.go", false},
+		{"[ab].txt", "b.txt", true},
+		{"[!ab].txt", "b.txt", false},
+		{"sr*", "src/a.go", true},
+	}
+	for _, tt := range tests {
+		spec, err := newPathspec(tt.spec, scpath.RelativePath(tt.spec))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := spec.matches(tt.path); got != tt.want {
+			t.Errorf("pathspec %q matches(%q) = %v, want %v", tt.spec, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestManager_AddCanceled(t *testing.T) {
+	m, _, indexPath, objectStore := setupAddTest(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := m.AddWithOptions(ctx, []string{"README.md", "src"}, objectStore, AddOptions{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("AddWithOptions() err = %v, want context.Canceled", err)
+	}
+	if staged := stagedPaths(t, indexPath); len(staged) != 0 {
+		t.Errorf("staged = %v after a canceled add, want nothing", staged)
+	}
+
+	// The lock was released, so a later add goes through
+	if _, err := m.Add([]string{"README.md"}, objectStore); err != nil {
+		t.Fatalf("Add() after a canceled add failed: %v", err)
+	}
+}
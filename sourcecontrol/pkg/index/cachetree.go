@@ -0,0 +1,181 @@
+package index
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+)
+
+// CacheTree is one directory of the cache-tree (TREE) index extension: the
+// tree object last written for that directory and how many index entries it
+// covers.
+//
+// Building a commit only has to write trees for directories whose entries
+// changed since the cache tree was recorded; every other directory reuses
+// its cached hash. Changing an entry invalidates the directories on its
+// path, up to the root, but leaves their other subdirectories intact.
+//
+// On disk each node is written as
+//
+//	<name> NUL <entry count> SP <subtree count> LF [<hash>]
+//
+// followed by its subtrees, where an invalid node has an entry count of -1
+// and no hash.
+type CacheTree struct {
+	// Name is the directory's name within its parent; empty for the root
+	Name string
+
+	// EntryCount is the number of index entries below the directory, or -1
+	// once the cached tree is out of date
+	EntryCount int
+
+	// Hash is the tree object written for the directory, when valid
+	Hash objects.ObjectHash
+
+	// Subtrees holds the cached subdirectories, ordered as Git writes them:
+	// shorter names first, then by bytes
+	Subtrees []*CacheTree
+}
+
+// NewCacheTree creates a cache tree node for a directory whose tree, built
+// from entryCount index entries, has the given hash.
+func NewCacheTree(name string, entryCount int, hash objects.ObjectHash) *CacheTree {
+	return &CacheTree{Name: name, EntryCount: entryCount, Hash: hash}
+}
+
+// Valid reports whether the node's tree hash can still be used.
+func (t *CacheTree) Valid() bool {
+	return t != nil && t.EntryCount >= 0
+}
+
+// Subtree returns the cached subdirectory with the given name, or nil.
+// It is safe to call on a nil tree.
+func (t *CacheTree) Subtree(name string) *CacheTree {
+	if t == nil {
+		return nil
+	}
+	if i, found := t.find(name); found {
+		return t.Subtrees[i]
+	}
+	return nil
+}
+
+// AddSubtree adds sub as a subdirectory, replacing any with the same name.
+func (t *CacheTree) AddSubtree(sub *CacheTree) {
+	i, found := t.find(sub.Name)
+	if found {
+		t.Subtrees[i] = sub
+		return
+	}
+	t.Subtrees = append(t.Subtrees, nil)
+	copy(t.Subtrees[i+1:], t.Subtrees[i:])
+	t.Subtrees[i] = sub
+}
+
+// find returns the position of the subtree with the given name, or where it
+// would be inserted.
+func (t *CacheTree) find(name string) (int, bool) {
+	i := sort.Search(len(t.Subtrees), func(i int) bool {
+		return compareSubtreeNames(t.Subtrees[i].Name, name) >= 0
+	})
+	return i, i < len(t.Subtrees) && t.Subtrees[i].Name == name
+}
+
+// compareSubtreeNames orders subtree names the way Git's cache-tree does:
+// by length, then bytewise.
+func compareSubtreeNames(a, b string) int {
+	if len(a) != len(b) {
+		return len(a) - len(b)
+	}
+	return strings.Compare(a, b)
+}
+
+// invalidate marks every directory on path, a slash-separated path relative
+// to t, as out of date. A subtree named by the final component is dropped,
+// since a file now takes its place.
+func (t *CacheTree) invalidate(path string) {
+	t.EntryCount = -1
+	name, rest, isDir := strings.Cut(path, "/")
+	i, found := t.find(name)
+	if !found {
+		return
+	}
+	if !isDir {
+		t.Subtrees = append(t.Subtrees[:i], t.Subtrees[i+1:]...)
+		return
+	}
+	t.Subtrees[i].invalidate(rest)
+}
+
+// serialize writes the node and its subtrees in the extension's format.
+func (t *CacheTree) serialize(buf *bytes.Buffer) {
+	buf.WriteString(t.Name)
+	buf.WriteByte(0)
+	fmt.Fprintf(buf, "%d %d\n", t.EntryCount, len(t.Subtrees))
+	if t.Valid() {
+		raw, _ := t.Hash.Raw()
+		buf.Write(raw)
+	}
+	for _, sub := range t.Subtrees {
+		sub.serialize(buf)
+	}
+}
+
+// readCacheTree parses the data of a TREE extension.
+func readCacheTree(data []byte, algorithm objects.HashAlgorithm) (*CacheTree, error) {
+	t, rest, err := readCacheTreeNode(data, algorithm)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, fmt.Errorf("%d bytes of trailing data", len(rest))
+	}
+	return t, nil
+}
+
+// readCacheTreeNode parses one node and its subtrees from the start of data,
+// returning the bytes that follow them.
+func readCacheTreeNode(data []byte, algorithm objects.HashAlgorithm) (*CacheTree, []byte, error) {
+	nul := bytes.IndexByte(data, 0)
+	newline := bytes.IndexByte(data, '\n')
+	if nul < 0 || newline < nul {
+		return nil, nil, fmt.Errorf("malformed cache tree node")
+	}
+
+	counts := strings.Fields(string(data[nul+1 : newline]))
+	if len(counts) != 2 {
+		return nil, nil, fmt.Errorf("malformed cache tree counts %q", data[nul+1:newline])
+	}
+	entryCount, err := strconv.Atoi(counts[0])
+	if err != nil || entryCount < -1 {
+		return nil, nil, fmt.Errorf("invalid cache tree entry count %q", counts[0])
+	}
+	subtreeCount, err := strconv.Atoi(counts[1])
+	if err != nil || subtreeCount < 0 {
+		return nil, nil, fmt.Errorf("invalid cache tree subtree count %q", counts[1])
+	}
+
+	t := NewCacheTree(string(data[:nul]), entryCount, "")
+	data = data[newline+1:]
+	if t.Valid() {
+		if len(data) < algorithm.Size() {
+			return nil, nil, fmt.Errorf("truncated cache tree hash")
+		}
+		t.Hash = objects.NewObjectHashFromRaw(data[:algorithm.Size()])
+		data = data[algorithm.Size():]
+	}
+
+	for range subtreeCount {
+		var sub *CacheTree
+		sub, data, err = readCacheTreeNode(data, algorithm)
+		if err != nil {
+			return nil, nil, err
+		}
+		t.AddSubtree(sub)
+	}
+	return t, data, nil
+}
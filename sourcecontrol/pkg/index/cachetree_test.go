@@ -0,0 +1,174 @@
+package index
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+)
+
+// testCacheTree returns a valid cache tree for the directories of
+// README, aa/x, b/c/y and b/z.
+func testCacheTree() *CacheTree {
+	hash := func(seed string) objects.ObjectHash {
+		h, _ := objects.ParseObjectHash(createTestHash(seed))
+		return h
+	}
+	root := NewCacheTree("", 4, hash("root"))
+	b := NewCacheTree("b", 2, hash("b"))
+	b.AddSubtree(NewCacheTree("c", 1, hash("c")))
+	root.AddSubtree(NewCacheTree("aa", 1, hash("aa")))
+	root.AddSubtree(b)
+	return root
+}
+
+func TestCacheTree_SubtreeOrder(t *testing.T) {
+	root := testCacheTree()
+
+	// Git orders subtrees by name length before comparing bytes
+	var names []string
+	for _, sub := range root.Subtrees {
+		names = append(names, sub.Name)
+	}
+	if len(names) != 2 || names[0] != "b" || names[1] != "aa" {
+		t.Errorf("subtrees = %v, want [b aa]", names)
+	}
+	if root.Subtree("b").Subtree("c") == nil || root.Subtree("missing") != nil {
+		t.Error("Subtree() lookup failed")
+	}
+}
+
+func TestCacheTree_Invalidate(t *testing.T) {
+	root := testCacheTree()
+	root.invalidate("b/c/y")
+
+	if root.Valid() || root.Subtree("b").Valid() || root.Subtree("b").Subtree("c").Valid() {
+		t.Error("directories on the path should be invalid")
+	}
+	if !root.Subtree("aa").Valid() {
+		t.Error("aa is not on the path and should stay valid")
+	}
+
+	// A file replacing a directory drops the directory's subtree
+	root.invalidate("aa")
+	if root.Subtree("aa") != nil {
+		t.Error("invalidating aa as a file should drop its subtree")
+	}
+}
+
+func TestCacheTree_Serialize(t *testing.T) {
+	root := testCacheTree()
+	root.invalidate("b/z")
+
+	var buf bytes.Buffer
+	root.serialize(&buf)
+
+	aa, _ := root.Subtree("aa").Hash.Raw()
+	c, _ := root.Subtree("b").Subtree("c").Hash.Raw()
+	var want bytes.Buffer
+	want.WriteString("\x00-1 2\nb\x00-1 1\nc\x001 0\n")
+	want.Write(c)
+	want.WriteString("aa\x001 0\n")
+	want.Write(aa)
+	if !bytes.Equal(buf.Bytes(), want.Bytes()) {
+		t.Errorf("serialize() = %q, want %q", buf.Bytes(), want.Bytes())
+	}
+
+	read, err := readCacheTree(buf.Bytes(), objects.SHA1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var again bytes.Buffer
+	read.serialize(&again)
+	if !bytes.Equal(again.Bytes(), buf.Bytes()) {
+		t.Errorf("round trip = %q, want %q", again.Bytes(), buf.Bytes())
+	}
+}
+
+func TestIndex_CacheTreeExtension(t *testing.T) {
+	idx := NewIndex()
+	for _, path := range []string{"README", "aa/x", "b/c/y", "b/z"} {
+		idx.Add(createTestEntry(path, createTestHash(path)))
+	}
+	idx.CacheTree = testCacheTree()
+
+	var buf bytes.Buffer
+	if err := idx.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	read := NewIndex()
+	if err := read.Deserialize(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	if !read.CacheTree.Valid() || read.CacheTree.Subtree("b").Subtree("c").Hash != idx.CacheTree.Subtree("b").Subtree("c").Hash {
+		t.Fatalf("cache tree not read back: %+v", read.CacheTree)
+	}
+
+	read.Add(createTestEntry("b/z", createTestHash("changed")))
+	if read.CacheTree.Valid() || read.CacheTree.Subtree("b").Valid() {
+		t.Error("Add() should invalidate the directories of the path")
+	}
+	if !read.CacheTree.Subtree("aa").Valid() || !read.CacheTree.Subtree("b").Subtree("c").Valid() {
+		t.Error("Add() should leave other directories valid")
+	}
+
+	read.Remove(mustRelativePath("aa/x"))
+	if read.CacheTree.Subtree("aa").Valid() {
+		t.Error("Remove() should invalidate aa")
+	}
+}
+
+func TestIndex_ReplaceKeepsUnchangedCacheTree(t *testing.T) {
+	idx := NewIndex()
+	for _, path := range []string{"README", "aa/x", "b/c/y", "b/z"} {
+		idx.Add(createTestEntry(path, createTestHash(path)))
+	}
+	idx.CacheTree = testCacheTree()
+
+	entries := make([]*Entry, 0, len(idx.Entries))
+	for _, e := range idx.Entries {
+		if e.Path != "b/z" {
+			entries = append(entries, e)
+		}
+	}
+	idx.Replace(entries)
+
+	if idx.CacheTree.Valid() || idx.CacheTree.Subtree("b").Valid() {
+		t.Error("directories of the dropped entry should be invalid")
+	}
+	if !idx.CacheTree.Subtree("aa").Valid() || !idx.CacheTree.Subtree("b").Subtree("c").Valid() {
+		t.Error("directories whose entries were kept should stay valid")
+	}
+}
+
+func TestIndex_Extensions(t *testing.T) {
+	serialize := func(signature string) []byte {
+		idx := NewIndex()
+		idx.Add(createTestEntry("file", createTestHash("file")))
+		var buf bytes.Buffer
+		if err := idx.Serialize(&buf); err != nil {
+			t.Fatal(err)
+		}
+
+		content := buf.Bytes()[:buf.Len()-SHALength]
+		content = append(content, signature...)
+		content = binary.BigEndian.AppendUint32(content, 3)
+		content = append(content, "abc"...)
+		checksum := objects.SHA1.New()
+		checksum.Write(content)
+		return checksum.Sum(content)
+	}
+
+	idx := NewIndex()
+	if err := idx.Deserialize(bytes.NewReader(serialize("UNTR"))); err != nil {
+		t.Errorf("optional extension should be skipped: %v", err)
+	}
+	if idx.Count() != 1 || idx.CacheTree != nil {
+		t.Errorf("read %d entries, cache tree %v", idx.Count(), idx.CacheTree)
+	}
+
+	if err := NewIndex().Deserialize(bytes.NewReader(serialize("link"))); err == nil {
+		t.Error("required extension should be rejected")
+	}
+}
@@ -29,6 +29,7 @@ func (idx *Index) AddConflict(path scpath.RelativePath, base, ours, theirs objec
 
 	// Remove any existing conflict entries for this path
 	idx.RemoveConflict(normalizedPath)
+	idx.invalidate(normalizedPath)
 
 	// Create base entry (stage 1) if it exists
 	if !base.IsZero() {
@@ -77,6 +78,7 @@ func (idx *Index) RemoveConflict(path scpath.RelativePath) {
 	for _, entry := range idx.Entries {
 		if entry.Path.Normalize() == normalizedPath && entry.Stage >= 1 && entry.Stage <= 3 {
 			// Skip conflict entries
+			idx.invalidate(normalizedPath)
 			continue
 		}
 		filtered = append(filtered, entry)
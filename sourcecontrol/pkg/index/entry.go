@@ -17,7 +17,8 @@ import (
 // Entry represents a single file entry in the Git index (staging area).
 //
 // Binary Layout:
-//   - Fixed header: 62 bytes (timestamps, metadata, hash, flags)
+//   - Fixed header: 62 bytes (timestamps, metadata, hash, flags), or 74
+//     bytes in SHA-256 repositories where the hash is 32 bytes wide
 //   - Variable path: null-terminated file path relative to repository root
 //   - Padding: Aligned to 8-byte boundary for efficient disk I/O
 //
@@ -57,7 +58,7 @@ type Entry struct {
 	// Used as a quick check to detect content changes.
 	SizeInBytes uint32
 
-	// BlobHash is the SHA-1 hash of the file's content.
+	// BlobHash is the hash of the file's content.
 	// This hash references the blob object stored in Git's object database.
 	BlobHash objects.ObjectHash
 
@@ -66,6 +67,16 @@ type Entry struct {
 	// Set with: git update-index --assume-unchanged <file>
 	AssumeValid bool
 
+	// SkipWorktree marks a path the working directory does not have, as in
+	// a sparse checkout, so it is not reported as deleted. Stored in the
+	// extended flags of index version 3 and later.
+	SkipWorktree bool
+
+	// IntentToAdd marks a path recorded with add -N: it is known to the
+	// index but its content is not staged yet. Stored in the extended flags
+	// of index version 3 and later.
+	IntentToAdd bool
+
 	// Stage indicates the merge conflict state:
 	//   - 0: Normal entry (no conflict)
 	//   - 1: Base/ancestor version (merge base)
@@ -98,7 +109,8 @@ func NewEntry(path scpath.RelativePath) *Entry {
 //
 // Parameters:
 //   - path: Relative path to the file from repository root
-//   - info: File system information from os.Stat() or os.Lstat()
+//   - info: File system information from os.Lstat(); the entry takes the
+//     file's mode as a tree records it, e.g. 100755 or 120000
 //   - hash: SHA-1 hash of the file's content (blob object hash)
 func NewEntryFromFileInfo(path scpath.RelativePath, info os.FileInfo, hash objects.ObjectHash) (*Entry, error) {
 	if !path.IsValid() {
@@ -106,18 +118,23 @@ func NewEntryFromFileInfo(path scpath.RelativePath, info os.FileInfo, hash objec
 	}
 
 	e := NewEntry(path)
-	e.SizeInBytes = uint32(info.Size())
-	e.Mode = FileMode(info.Mode())
+	e.Mode = objects.FromOSFileMode(info.Mode())
 	e.BlobHash = hash
+	e.updateStat(info)
+
+	return e, nil
+}
+
+// updateStat records the file's size, times and platform-specific metadata
+// (device, inode, uid, gid), which later tell whether it has changed.
+func (e *Entry) updateStat(info os.FileInfo) {
+	e.SizeInBytes = uint32(info.Size())
 
 	modTime := info.ModTime()
 	e.ModificationTime = common.NewTimestampFromTime(modTime)
 	e.CreationTime = common.NewTimestampFromTime(modTime)
 
-	// Extract platform-specific metadata (device, inode, uid, gid)
 	e.DeviceID, e.Inode, e.UserID, e.GroupID = extractSystemMetadata(info)
-
-	return e, nil
 }
 
 // IsModified checks if the entry has been modified compared to file stats.
@@ -143,23 +160,31 @@ func (e *Entry) IsModified(info os.FileInfo) bool {
 
 // CompareTo compares this entry with another for sorting.
 //
-// Git maintains the index in sorted order for efficient binary search
-// and directory-tree optimizations. The sorting rules are:
-//  1. Entries are sorted lexicographically by path
-//  2. Directories are treated as having a trailing '/' character
-//  3. This ensures proper tree structure: parent directories sort before files
+// Git keeps the index sorted by the bytes of the path, and entries for the
+// same path (the stages of a conflict) by stage number. Directories never
+// appear in the index, so unlike tree entries no trailing '/' is involved.
 func (e *Entry) CompareTo(other *Entry) int {
-	thisKey := e.Path.String()
-	otherKey := other.Path.String()
-
-	if e.Mode.IsDirectory() {
-		thisKey += "/"
-	}
-	if other.Mode.IsDirectory() {
-		otherKey += "/"
+	if c := strings.Compare(e.Path.String(), other.Path.String()); c != 0 {
+		return c
 	}
+	return int(e.Stage) - int(other.Stage)
+}
 
-	return strings.Compare(thisKey, otherKey)
+// TreeMode returns the mode the entry is recorded with in a tree object.
+//
+// Index files written by older versions may hold a file's permission bits
+// only (e.g. 0644 or 0755); these become a regular or executable file.
+func (e *Entry) TreeMode() FileMode {
+	switch {
+	case e.Mode.IsSymlink():
+		return FileModeSymlink
+	case e.Mode.IsGitlink():
+		return FileModeGitlink
+	case e.Mode.IsExecutable():
+		return FileModeExecutable
+	default:
+		return FileModeRegular
+	}
 }
 
 // Serialize writes the entry in Git's index binary format.
@@ -168,18 +193,34 @@ func (e *Entry) CompareTo(other *Entry) int {
 //
 //	[62 bytes: header][variable: path\0][0-7 bytes: padding to 8-byte boundary]
 //
+// The header is 12 bytes longer when BlobHash is a SHA-256 hash.
+//
 // Parameters:
 //   - w: Writer to output the serialized data
 //
 // Returns an error if any write operation fails.
 func (e *Entry) Serialize(w io.Writer) error {
+	return e.serialize(w, IndexVersionExtended, "")
+}
+
+// serialize writes the entry as it appears in an index of the given
+// version. Version 4 stores the path relative to prevPath, the path of the
+// entry before it, and has no padding.
+func (e *Entry) serialize(w io.Writer, version uint32, prevPath string) error {
 	buf := new(bytes.Buffer)
 
 	if err := e.writeFixedFields(buf); err != nil {
 		return fmt.Errorf("failed to write fixed fields: %w", err)
 	}
 
-	if _, err := buf.WriteString(e.Path.String()); err != nil {
+	path := e.Path.String()
+	if version >= IndexVersionCompressed {
+		common := commonPrefixLength(prevPath, path)
+		buf.Write(encodeVarint(uint64(len(prevPath) - common)))
+		path = path[common:]
+	}
+
+	if _, err := buf.WriteString(path); err != nil {
 		return fmt.Errorf("failed to write path: %w", err)
 	}
 
@@ -187,14 +228,15 @@ func (e *Entry) Serialize(w io.Writer) error {
 		return fmt.Errorf("failed to write null terminator: %w", err)
 	}
 
-	pathLen := len(e.Path.String())
-	entrySize := FixedHeaderSize + pathLen + 1
-	paddedSize := (entrySize + AlignmentBoundary - 1) / AlignmentBoundary * AlignmentBoundary
-	padding := paddedSize - entrySize
+	if version < IndexVersionCompressed {
+		entrySize := buf.Len()
+		paddedSize := (entrySize + AlignmentBoundary - 1) / AlignmentBoundary * AlignmentBoundary
+		padding := paddedSize - entrySize
 
-	for range padding {
-		if err := buf.WriteByte(0); err != nil {
-			return fmt.Errorf("failed to write padding: %w", err)
+		for range padding {
+			if err := buf.WriteByte(0); err != nil {
+				return fmt.Errorf("failed to write padding: %w", err)
+			}
 		}
 	}
 
@@ -205,7 +247,15 @@ func (e *Entry) Serialize(w io.Writer) error {
 	return nil
 }
 
-// writeFixedFields writes the 62-byte fixed header portion of the entry.
+// HasExtendedFlags reports whether the entry sets a flag that only index
+// version 3 and later can store.
+func (e *Entry) HasExtendedFlags() bool {
+	return e.SkipWorktree || e.IntentToAdd
+}
+
+// writeFixedFields writes the fixed header portion of the entry (62 bytes
+// for a SHA-1 BlobHash, 74 for SHA-256, and 2 more when the entry has
+// extended flags).
 //
 // All multi-byte integers are written in big-endian (network) byte order
 // for cross-platform compatibility.
@@ -220,15 +270,25 @@ func (e *Entry) writeFixedFields(w io.Writer) error {
 	if err != nil {
 		return fmt.Errorf("failed to get hash bytes: %w", err)
 	}
-	if _, err := buf.Write(hashBytes[:]); err != nil {
+	if _, err := buf.Write(hashBytes); err != nil {
 		return fmt.Errorf("failed to write hash: %w", err)
 	}
 
 	flags := NewEntryFlags(e.AssumeValid, e.Stage, len(e.Path.String()))
+	if e.HasExtendedFlags() {
+		flags |= FlagExtendedMask
+	}
 	if err := binary.Write(buf, binary.BigEndian, flags); err != nil {
 		return fmt.Errorf("failed to write flags: %w", err)
 	}
 
+	if e.HasExtendedFlags() {
+		extended := NewExtendedFlags(e.SkipWorktree, e.IntentToAdd)
+		if err := binary.Write(buf, binary.BigEndian, extended); err != nil {
+			return fmt.Errorf("failed to write extended flags: %w", err)
+		}
+	}
+
 	if _, err := w.Write(buf.Bytes()); err != nil {
 		return fmt.Errorf("failed to write fixed fields: %w", err)
 	}
@@ -282,56 +342,126 @@ func (e *Entry) writeTimestampsAndMetadata(w io.Writer) error {
 //   - Total bytes read (including padding)
 //   - Error if data is invalid or incomplete
 func (e *Entry) Deserialize(r io.Reader) (int, error) {
-	fixedData := make([]byte, FixedHeaderSize)
+	return e.DeserializeWithAlgorithm(r, objects.SHA1)
+}
+
+// DeserializeWithAlgorithm reads an entry whose blob hash has the width of
+// the given algorithm, as found in the index of a repository using it.
+func (e *Entry) DeserializeWithAlgorithm(r io.Reader, algorithm objects.HashAlgorithm) (int, error) {
+	br, ok := r.(byteReader)
+	if !ok {
+		br = oneByteReader{r}
+	}
+	return e.deserialize(br, algorithm, IndexVersionExtended, "")
+}
+
+// deserialize reads an entry from an index of the given version. In
+// version 4 the path is stored relative to prevPath, the path of the entry
+// before it.
+func (e *Entry) deserialize(r byteReader, algorithm objects.HashAlgorithm, version uint32, prevPath string) (int, error) {
+	fixedData := make([]byte, fixedHeaderSize(algorithm))
 	if _, err := io.ReadFull(r, fixedData); err != nil {
 		return 0, fmt.Errorf("failed to read fixed header: %w", err)
 	}
 
-	if err := e.readFixedFields(fixedData); err != nil {
+	extended, err := e.readFixedFields(fixedData, algorithm)
+	if err != nil {
 		return 0, fmt.Errorf("failed to parse fixed fields: %w", err)
 	}
 
+	headerSize := len(fixedData)
+	if extended {
+		if version < IndexVersionExtended {
+			return 0, fmt.Errorf("extended flags not supported in index version %d", version)
+		}
+		if err := e.readExtendedFlags(r); err != nil {
+			return 0, err
+		}
+		headerSize += ExtendedFlagsLength
+	}
+
+	if version >= IndexVersionCompressed {
+		return e.readCompressedPath(r, headerSize, prevPath)
+	}
+
 	if err := e.readFilePath(r); err != nil {
 		return 0, err
 	}
 
-	return e.calculatePadding(r)
+	return e.calculatePadding(r, headerSize)
 }
 
-// readFixedFields parses the 62-byte fixed header from raw bytes.
-//
-// Validates that extended flags are not set, as this implementation
-// only supports Git index version 2.
-func (e *Entry) readFixedFields(data []byte) error {
-	if len(data) < FixedHeaderSize {
-		return fmt.Errorf("insufficient data for fixed header: got %d bytes, need %d", len(data), FixedHeaderSize)
+// byteReader is what entries are read from: paths are read a byte at a
+// time.
+type byteReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+// oneByteReader reads single bytes from a reader without buffering, so
+// nothing past the entry is consumed.
+type oneByteReader struct {
+	io.Reader
+}
+
+func (r oneByteReader) ReadByte() (byte, error) {
+	var b [1]byte
+	if _, err := io.ReadFull(r.Reader, b[:]); err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+// fixedHeaderSize returns the size of an entry's fixed header when hashes
+// are produced by algorithm.
+func fixedHeaderSize(algorithm objects.HashAlgorithm) int {
+	return FixedHeaderSize - SHALength + algorithm.Size()
+}
+
+// readFixedFields parses the fixed header from raw bytes and reports
+// whether the entry's extended flags follow it.
+func (e *Entry) readFixedFields(data []byte, algorithm objects.HashAlgorithm) (bool, error) {
+	if need := fixedHeaderSize(algorithm); len(data) < need {
+		return false, fmt.Errorf("insufficient data for fixed header: got %d bytes, need %d", len(data), need)
 	}
 
 	buf := bytes.NewReader(data)
 
 	if err := e.readTimestamp(buf); err != nil {
-		return err
+		return false, err
 	}
 
 	if err := e.readMetadata(buf); err != nil {
-		return err
+		return false, err
 	}
 
-	if err := e.readHash(buf); err != nil {
-		return err
+	if err := e.readHash(buf, algorithm.Size()); err != nil {
+		return false, err
 	}
 
 	var flags EntryFlags
 	if err := binary.Read(buf, binary.BigEndian, &flags); err != nil {
-		return err
-	}
-
-	if flags.Extended() {
-		return fmt.Errorf("extended flags not supported in index version 2")
+		return false, err
 	}
 
 	e.AssumeValid = flags.AssumeValid()
 	e.Stage = flags.Stage()
+	return flags.Extended(), nil
+}
+
+// readExtendedFlags reads the 16 bits of extended flags that follow the
+// fixed header in index version 3 and later.
+func (e *Entry) readExtendedFlags(r io.Reader) error {
+	var extended ExtendedFlags
+	if err := binary.Read(r, binary.BigEndian, &extended); err != nil {
+		return fmt.Errorf("failed to read extended flags: %w", err)
+	}
+	if extended&ExtendedFlagReservedMask != 0 {
+		return fmt.Errorf("unknown extended index flags %#04x", uint16(extended))
+	}
+
+	e.SkipWorktree = extended.SkipWorktree()
+	e.IntentToAdd = extended.IntentToAdd()
 	return nil
 }
 
@@ -392,13 +522,14 @@ func (e *Entry) readMetadata(r io.Reader) error {
 	return nil
 }
 
-// readHash reads and parses the 20-byte SHA-1 hash from the header.
+// readHash reads and parses the hash from the header: 20 bytes for SHA-1,
+// 32 for SHA-256.
 //
 // The hash is stored as raw bytes and converted to a hex string
 // for internal representation. It represents the object ID of
 // the blob object containing the file's contents.
-func (e *Entry) readHash(r io.Reader) error {
-	hashBytes := make([]byte, SHALength)
+func (e *Entry) readHash(r io.Reader, size int) error {
+	hashBytes := make([]byte, size)
 	if _, err := io.ReadFull(r, hashBytes); err != nil {
 		return fmt.Errorf("failed to read hash: %w", err)
 	}
@@ -421,20 +552,40 @@ func (e *Entry) readHash(r io.Reader) error {
 //  2. Normalized to use forward slashes and relative format
 //
 // Returns an error if the path is invalid or cannot be read.
-func (e *Entry) readFilePath(r io.Reader) error {
-	pathBytes := make([]byte, 0, 256) // Start with reasonable capacity
-	for {
-		b := make([]byte, 1)
-		if _, err := r.Read(b); err != nil {
-			return fmt.Errorf("failed to read path: %w", err)
-		}
-		if b[0] == 0 {
-			break
-		}
-		pathBytes = append(pathBytes, b[0])
+func (e *Entry) readFilePath(r io.ByteReader) error {
+	pathBytes, err := readNulTerminated(r)
+	if err != nil {
+		return fmt.Errorf("failed to read path: %w", err)
+	}
+
+	return e.setPath(string(pathBytes))
+}
+
+// readCompressedPath reads a version 4 path: the number of bytes to drop
+// from the end of prevPath, then the NUL-terminated bytes to append to
+// what is left. Version 4 entries are not padded.
+func (e *Entry) readCompressedPath(r byteReader, headerSize int, prevPath string) (int, error) {
+	strip, n, err := decodeVarint(r)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read path prefix length: %w", err)
+	}
+	if strip > uint64(len(prevPath)) {
+		return 0, fmt.Errorf("invalid path prefix length %d after %q", strip, prevPath)
+	}
+
+	suffix, err := readNulTerminated(r)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read path: %w", err)
 	}
 
-	pathStr := string(pathBytes)
+	if err := e.setPath(prevPath[:len(prevPath)-int(strip)] + string(suffix)); err != nil {
+		return 0, err
+	}
+	return headerSize + n + len(suffix) + 1, nil
+}
+
+// setPath validates a path read from the index and stores it.
+func (e *Entry) setPath(pathStr string) error {
 	relativePath, err := scpath.NewRelativePath(pathStr)
 	if err != nil {
 		return fmt.Errorf("invalid path in index: %w", err)
@@ -444,6 +595,21 @@ func (e *Entry) readFilePath(r io.Reader) error {
 	return nil
 }
 
+// readNulTerminated reads bytes up to and excluding a NUL byte.
+func readNulTerminated(r io.ByteReader) ([]byte, error) {
+	data := make([]byte, 0, 256) // Start with reasonable capacity
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if b == 0 {
+			return data, nil
+		}
+		data = append(data, b)
+	}
+}
+
 // calculatePadding reads padding bytes to reach 8-byte alignment.
 //
 // After the null-terminated path, Git pads the entry with zeros
@@ -454,9 +620,9 @@ func (e *Entry) readFilePath(r io.Reader) error {
 //   - Cache line efficiency
 //
 // Returns the total size of the entry including padding.
-func (e *Entry) calculatePadding(r io.Reader) (int, error) {
+func (e *Entry) calculatePadding(r io.Reader, headerSize int) (int, error) {
 	pathLen := len(e.Path.String())
-	bytesRead := FixedHeaderSize + pathLen + 1 // +1 for null terminator
+	bytesRead := headerSize + pathLen + 1 // +1 for null terminator
 
 	paddedSize := (bytesRead + AlignmentBoundary - 1) / AlignmentBoundary * AlignmentBoundary
 	padding := paddedSize - bytesRead
@@ -457,6 +457,30 @@ func TestEntryCompareTo(t *testing.T) {
 			},
 			expected: -1,
 		},
+		{
+			name: "file sorts before same-prefix file with dot",
+			entry1: &Entry{
+				Path: mustCreatePath(t, "a.txt"),
+				Mode: FileModeRegular,
+			},
+			entry2: &Entry{
+				Path: mustCreatePath(t, "a/b.txt"),
+				Mode: FileModeRegular,
+			},
+			expected: -1,
+		},
+		{
+			name: "same path orders by stage",
+			entry1: &Entry{
+				Path:  mustCreatePath(t, "file.txt"),
+				Stage: 3,
+			},
+			entry2: &Entry{
+				Path:  mustCreatePath(t, "file.txt"),
+				Stage: 1,
+			},
+			expected: 1,
+		},
 	}
 
 	for _, tt := range tests {
@@ -480,6 +504,29 @@ func TestEntryCompareTo(t *testing.T) {
 	}
 }
 
+// TestEntryTreeMode tests the mode entries are written to trees with
+func TestEntryTreeMode(t *testing.T) {
+	tests := []struct {
+		mode FileMode
+		want FileMode
+	}{
+		{FileModeRegular, FileModeRegular},
+		{FileModeExecutable, FileModeExecutable},
+		{FileModeSymlink, FileModeSymlink},
+		{FileModeGitlink, FileModeGitlink},
+		{FileMode(0644), FileModeRegular},
+		{FileMode(0755), FileModeExecutable},
+		{0, FileModeRegular},
+	}
+
+	for _, tt := range tests {
+		e := &Entry{Mode: tt.mode}
+		if got := e.TreeMode(); got != tt.want {
+			t.Errorf("TreeMode() for %o = %o, want %o", tt.mode, got, tt.want)
+		}
+	}
+}
+
 // TestEntryString tests the String method
 func TestEntryString(t *testing.T) {
 	entry := &Entry{
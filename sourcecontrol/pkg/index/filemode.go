@@ -0,0 +1,83 @@
+package index
+
+import (
+	"os"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/gitconfig"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+)
+
+// ModeSettings tells whether the modes of working tree files can be trusted
+// on the filesystem a repository lives on.
+type ModeSettings struct {
+	// FileMode is core.fileMode: the executable bit of files is tracked
+	FileMode bool
+
+	// Symlinks is core.symlinks: symbolic links are checked out as links,
+	// rather than as plain files holding the path they point to
+	Symlinks bool
+}
+
+// LoadModeSettings reads core.fileMode and core.symlinks from the .git/config
+// of the repository at repoRoot. Both are trusted when they are not set or
+// the file cannot be read.
+func LoadModeSettings(repoRoot scpath.RepositoryPath) ModeSettings {
+	settings := ModeSettings{FileMode: true, Symlinks: true}
+
+	file, err := os.Open(repoRoot.SourcePath().ConfigPath().String())
+	if err != nil {
+		return settings
+	}
+	defer file.Close()
+	entries, err := gitconfig.Parse(file)
+	if err != nil {
+		return settings
+	}
+
+	// The last value of a key wins; one that is not a boolean is ignored
+	for _, e := range entries {
+		var setting *bool
+		switch e.Name() {
+		case "core.filemode":
+			setting = &settings.FileMode
+		case "core.symlinks":
+			setting = &settings.Symlinks
+		default:
+			continue
+		}
+		if value, err := gitconfig.ParseBool(e.Value); err == nil {
+			*setting = value
+		}
+	}
+	return settings
+}
+
+// WorktreeMode returns the mode to stage a working tree file described by
+// info with, given existing, its stage 0 entry or nil. As in Git, what the
+// filesystem cannot be trusted with is taken from the entry instead:
+//   - without core.symlinks, a plain file staged as a symbolic link stays
+//     one, since checkout wrote the link's target into it
+//   - without core.fileMode, a file keeps its executable bit, and new files
+//     are never executable
+func (s ModeSettings) WorktreeMode(info os.FileInfo, existing *Entry) FileMode {
+	mode := objects.FromOSFileMode(info.Mode())
+	if !mode.IsRegular() {
+		return mode
+	}
+
+	var staged FileMode
+	if existing != nil {
+		staged = existing.TreeMode()
+	}
+	switch {
+	case !s.Symlinks && staged.IsSymlink():
+		return staged
+	case s.FileMode:
+		return mode
+	case staged.IsRegular():
+		return staged
+	default:
+		return FileModeRegular
+	}
+}
@@ -0,0 +1,175 @@
+package index
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+	"github.com/utkarsh5026/SourceControl/pkg/store"
+)
+
+func TestModeSettings_WorktreeMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the executable bit and symbolic links need a Unix filesystem")
+	}
+	dir := t.TempDir()
+	infos := make(map[string]os.FileInfo)
+	for name, perm := range map[string]os.FileMode{"plain": 0644, "exec": 0755} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, nil, perm); err != nil {
+			t.Fatal(err)
+		}
+		infos[name], _ = os.Lstat(path)
+	}
+	if err := os.Symlink("plain", filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+	infos["link"], _ = os.Lstat(filepath.Join(dir, "link"))
+
+	trusted := ModeSettings{FileMode: true, Symlinks: true}
+	tests := []struct {
+		name     string
+		settings ModeSettings
+		file     string
+		staged   FileMode // 0 for no entry
+		want     FileMode
+	}{
+		{"executable", trusted, "exec", 0, FileModeExecutable},
+		{"symlink", trusted, "link", FileModeRegular, FileModeSymlink},
+		{"mode change", trusted, "plain", FileModeExecutable, FileModeRegular},
+		{"link replaced by a file", trusted, "plain", FileModeSymlink, FileModeRegular},
+		{"no filemode, new file", ModeSettings{Symlinks: true}, "exec", 0, FileModeRegular},
+		{"no filemode, keeps exec bit", ModeSettings{Symlinks: true}, "plain", FileModeExecutable, FileModeExecutable},
+		{"no filemode, keeps no exec bit", ModeSettings{Symlinks: true}, "exec", FileModeRegular, FileModeRegular},
+		{"no symlinks, file holding a link", ModeSettings{FileMode: true}, "plain", FileModeSymlink, FileModeSymlink},
+		{"no symlinks, real link", ModeSettings{FileMode: true}, "link", FileModeRegular, FileModeSymlink},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var existing *Entry
+			if tt.staged != 0 {
+				existing = &Entry{Mode: tt.staged}
+			}
+			if got := tt.settings.WorktreeMode(infos[tt.file], existing); got != tt.want {
+				t.Errorf("WorktreeMode() = %s, want %s", got.ToOctalString(), tt.want.ToOctalString())
+			}
+		})
+	}
+}
+
+// unstagedCodes returns the working tree status code of each path.
+func unstagedCodes(t *testing.T, m *Manager) map[string]byte {
+	t.Helper()
+	if err := m.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	result, err := m.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	codes := make(map[string]byte)
+	for _, entry := range result.Entries {
+		codes[entry.Path] = entry.Unstaged
+	}
+	return codes
+}
+
+func TestManager_AddSymlinksAndExecutables(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the executable bit and symbolic links need a Unix filesystem")
+	}
+	root, indexPath := setupLockTestRepo(t)
+	writeWorktree(t, root, map[string]string{"target.txt": "target", "sub/file.txt": "file", "run.sh": "#!/bin/sh"})
+	abs := func(name string) string { return filepath.Join(root.String(), name) }
+	if err := os.Chmod(abs("run.sh"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for link, target := range map[string]string{"link": "target.txt", "dirlink": "sub", "broken": "missing"} {
+		if err := os.Symlink(target, abs(link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := NewManager(root)
+	objectStore := store.NewMemoryObjectStore()
+	paths := []string{"target.txt", "sub", "run.sh", "link", "dirlink", "broken"}
+	if _, err := m.Add(paths, objectStore); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+
+	idx, err := Read(indexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	algorithm := objectStore.HashAlgorithm()
+	want := map[string]struct {
+		mode    FileMode
+		content string
+	}{
+		"run.sh":       {FileModeExecutable, "#!/bin/sh"},
+		"link":         {FileModeSymlink, "target.txt"},
+		"dirlink":      {FileModeSymlink, "sub"},
+		"broken":       {FileModeSymlink, "missing"},
+		"sub/file.txt": {FileModeRegular, "file"},
+	}
+	for path, w := range want {
+		entry, ok := idx.Get(scpath.RelativePath(path))
+		if !ok {
+			t.Errorf("%s is not staged", path)
+			continue
+		}
+		if entry.Mode != w.mode || entry.BlobHash != algorithm.HashObject(objects.BlobType, []byte(w.content)) {
+			t.Errorf("%s staged as %s %s, want %s with %q", path, entry.Mode.ToOctalString(), entry.BlobHash.Short(), w.mode.ToOctalString(), w.content)
+		}
+	}
+	codes := unstagedCodes(t, m)
+	for _, path := range []string{"run.sh", "link", "dirlink", "broken"} {
+		if code, ok := codes[path]; !ok || code != StatusUnmodified {
+			t.Errorf("%s has working tree status %q, want unmodified", path, code)
+		}
+	}
+
+	// The executable bit is dropped and the link replaced by a file with
+	// the same content
+	if err := os.Chmod(abs("run.sh"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(abs("link")); err != nil {
+		t.Fatal(err)
+	}
+	writeWorktree(t, root, map[string]string{"link": "target.txt"})
+	codes = unstagedCodes(t, m)
+	if codes["run.sh"] != StatusModified || codes["link"] != StatusTypeChanged {
+		t.Errorf("run.sh %c and link %c, want M and T", codes["run.sh"], codes["link"])
+	}
+
+	// Neither can be trusted on this filesystem
+	config := "[core]\n\tfilemode = false\n\tsymlinks = no\n"
+	if err := os.WriteFile(root.SourcePath().ConfigPath().String(), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	codes = unstagedCodes(t, m)
+	if codes["run.sh"] != StatusUnmodified || codes["link"] != StatusUnmodified {
+		t.Errorf("run.sh %c and link %c, want both unmodified", codes["run.sh"], codes["link"])
+	}
+
+	writeWorktree(t, root, map[string]string{"new.sh": "new"})
+	if err := os.Chmod(abs("new.sh"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeWorktree(t, root, map[string]string{"run.sh": "#!/bin/sh\nexit 0"})
+	if _, err := m.Add([]string{"new.sh", "run.sh", "link"}, objectStore); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+	if idx, err = Read(indexPath); err != nil {
+		t.Fatal(err)
+	}
+	for path, mode := range map[string]FileMode{"new.sh": FileModeRegular, "run.sh": FileModeExecutable, "link": FileModeSymlink} {
+		if entry, ok := idx.Get(scpath.RelativePath(path)); !ok || entry.Mode != mode {
+			t.Errorf("%s staged as %v, want %s", path, entry, mode.ToOctalString())
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package index
+
+import (
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/refs"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/sourcerepo"
+)
+
+// Submodules
+//
+// A repository nested in the working tree is not staged file by file: as
+// in Git, its directory is staged as a gitlink, an entry with mode 160000
+// whose hash names the commit checked out in it. That commit belongs to the
+// nested repository, so it is never looked up in this one. An empty
+// directory at a gitlink's path is a submodule that was not checked out,
+// and is left as it is.
+
+// isNestedRepository reports whether the directory at absPath is the top
+// of a repository of its own.
+func isNestedRepository(absPath scpath.AbsolutePath) bool {
+	exists, err := sourcerepo.RepositoryExists(scpath.RepositoryPath(absPath.String()))
+	return err == nil && exists
+}
+
+// gitlinkCommit returns the commit checked out in the nested repository at
+// absPath, or an error if it is not a repository or has no commits.
+func gitlinkCommit(absPath scpath.AbsolutePath) (objects.ObjectHash, error) {
+	return refs.HeadCommit(scpath.RepositoryPath(absPath.String()))
+}
@@ -2,13 +2,13 @@ package index
 
 import (
 	"bytes"
-	"crypto/sha1"
 	"encoding/binary"
 	"fmt"
 	"io"
-	"os"
 	"sort"
+	"time"
 
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
 	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
 )
 
@@ -22,7 +22,7 @@ import (
 //	┌────────────────────────────────────────┐
 //	│ Header (12 bytes)                      │
 //	│   Signature: "DIRC" (4 bytes)          │
-//	│   Version: 2 (4 bytes)                 │
+//	│   Version: 2, 3 or 4 (4 bytes)         │
 //	│   Entry Count: N (4 bytes)             │
 //	├────────────────────────────────────────┤
 //	│ Entries (variable length)              │
@@ -31,19 +31,50 @@ import (
 //	│   ...                                  │
 //	│   Entry N                              │
 //	├────────────────────────────────────────┤
-//	│ Extensions (optional)                  │
+//	│ Extensions (optional, e.g. TREE)       │
 //	├────────────────────────────────────────┤
-//	│ SHA-1 Checksum (20 bytes)              │
+//	│ Checksum (20 bytes SHA-1 / 32 SHA-256) │
 //	└────────────────────────────────────────┘
+//
+// Entry hashes and the trailing checksum use the repository's hash algorithm.
+//
+// Version 3 entries may carry a second flags field (skip-worktree and
+// intent-to-add), and version 4 stores each path as the number of bytes to
+// drop from the previous entry's path plus the bytes to append, without
+// padding.
+//
+// The only extension read and written is the cache tree (TREE); other
+// optional extensions are skipped when reading and dropped when writing.
 type Index struct {
-	// Version is the index file format version (typically 2)
+	// Version is the index file format version to write (2, 3 or 4). The
+	// index.version setting overrides it when the index is written to a
+	// repository with Write.
 	Version uint32
 
+	// HashAlgorithm is the repository's object format. When empty it is
+	// inferred from the entries, falling back to SHA-1.
+	HashAlgorithm objects.HashAlgorithm
+
 	// Entries contains all staged files, sorted by path
 	Entries []*Entry
 
+	// CacheTree holds the tree hashes last written for the index's
+	// directories, or nil when the index has no TREE extension
+	CacheTree *CacheTree
+
+	// Timestamp is the modification time of the index file when it was
+	// read or last written, zero for an index that has not been; entries
+	// modified at or after it are racy (see IsRacy)
+	Timestamp time.Time
+
 	// entryMap provides O(1) lookup by path
 	entryMap map[scpath.RelativePath]*Entry
+
+	// versionSetting caches the index.version setting of the repository
+	// at versionPath, once versionLoaded (see configuredVersion)
+	versionSetting uint32
+	versionPath    scpath.AbsolutePath
+	versionLoaded  bool
 }
 
 // NewIndex creates a new empty index with the default version.
@@ -55,27 +86,50 @@ func NewIndex() *Index {
 	}
 }
 
+// NewIndexWithAlgorithm creates a new empty index for a repository whose
+// objects are named with the given hash algorithm.
+func NewIndexWithAlgorithm(algorithm objects.HashAlgorithm) *Index {
+	idx := NewIndex()
+	idx.HashAlgorithm = algorithm
+	return idx
+}
+
+// hashAlgorithm returns the algorithm used for the checksum and for reading
+// entry hashes.
+func (idx *Index) hashAlgorithm() objects.HashAlgorithm {
+	if idx.HashAlgorithm != "" {
+		return idx.HashAlgorithm
+	}
+	if len(idx.Entries) > 0 && idx.Entries[0] != nil {
+		return idx.Entries[0].BlobHash.Algorithm()
+	}
+	return objects.DefaultHashAlgorithm
+}
+
 // Write persists the index to disk at the specified path.
-// The index is serialized in Git's binary format and includes a SHA-1 checksum.
+// The index is serialized in Git's binary format and includes a checksum,
+// in the version chosen by the repository's index.version setting if set.
+// It is written to index.lock and renamed into place, so a concurrent
+// reader never sees a partial file.
+//
+// Write takes and releases the lock itself; to read, modify and write the
+// index without racing another process, use LockIndex or Update instead.
 //
 // Parameters:
 //   - path: Absolute path where the index file should be written (typically .git/index)
 //
 // Returns an error if:
+//   - The index is locked by another process (wrapping ErrIndexLocked)
 //   - Serialization fails
 //   - File cannot be written (permissions, disk full, etc.)
 func (idx *Index) Write(path scpath.AbsolutePath) error {
-	buf := new(bytes.Buffer)
-
-	if err := idx.Serialize(buf); err != nil {
-		return fmt.Errorf("failed to serialize index: %w", err)
-	}
-
-	if err := os.WriteFile(path.String(), buf.Bytes(), 0644); err != nil {
-		return fmt.Errorf("failed to write index file: %w", err)
+	lock, err := LockIndex(path)
+	if err != nil {
+		return err
 	}
+	defer lock.Rollback()
 
-	return nil
+	return lock.Commit(idx)
 }
 
 // Add stages a new file or updates an existing entry in the index.
@@ -87,6 +141,7 @@ func (idx *Index) Write(path scpath.AbsolutePath) error {
 func (idx *Index) Add(entry *Entry) {
 	pathKey := entry.Path.Normalize()
 
+	idx.invalidate(pathKey)
 	if existingEntry, exists := idx.entryMap[pathKey]; exists {
 		*existingEntry = *entry
 		idx.entryMap[pathKey] = existingEntry
@@ -114,6 +169,7 @@ func (idx *Index) Remove(path scpath.RelativePath) bool {
 	}
 
 	delete(idx.entryMap, normalizedPath)
+	idx.invalidate(normalizedPath)
 	for i, e := range idx.Entries {
 		if e == entry { // pointer comparison
 			idx.Entries = append(idx.Entries[:i], idx.Entries[i+1:]...)
@@ -152,6 +208,48 @@ func (idx *Index) Has(path scpath.RelativePath) bool {
 func (idx *Index) Clear() {
 	idx.Entries = make([]*Entry, 0)
 	idx.entryMap = make(map[scpath.RelativePath]*Entry)
+	idx.CacheTree = nil
+}
+
+// Replace discards every entry in the index and stages the given ones
+// instead. Unlike Add it accepts several entries for one path, one per
+// conflict stage; only stage 0 entries can be looked up with Get.
+//
+// Entries are compared by identity: the cache tree stays valid for
+// directories whose entries are all passed in again unchanged.
+func (idx *Index) Replace(entries []*Entry) {
+	previous := make(map[*Entry]bool, len(idx.Entries))
+	for _, entry := range idx.Entries {
+		previous[entry] = true
+	}
+	cacheTree := idx.CacheTree
+
+	idx.Clear()
+	idx.CacheTree = cacheTree
+	for _, entry := range entries {
+		entry.Path = entry.Path.Normalize()
+		idx.Entries = append(idx.Entries, entry)
+		if entry.Stage == 0 {
+			idx.entryMap[entry.Path] = entry
+		}
+		if previous[entry] {
+			delete(previous, entry)
+		} else {
+			idx.invalidate(entry.Path)
+		}
+	}
+	for entry := range previous {
+		idx.invalidate(entry.Path)
+	}
+	idx.sort()
+}
+
+// invalidate marks the cached trees of the directories containing path as
+// out of date.
+func (idx *Index) invalidate(path scpath.RelativePath) {
+	if idx.CacheTree != nil {
+		idx.CacheTree.invalidate(path.String())
+	}
 }
 
 // Paths returns a slice of all staged file paths.
@@ -169,12 +267,13 @@ func (idx *Index) Count() int {
 }
 
 // Serialize writes the index in Git's binary format to the provided writer.
-// The output includes a SHA-1 checksum of all content for integrity verification.
+// The output includes a checksum of all content for integrity verification.
 //
 // Format:
 //  1. Header (12 bytes)
 //  2. All entries (variable length)
-//  3. SHA-1 checksum (20 bytes)
+//  3. The TREE extension, if the index has a cache tree
+//  4. Checksum (20 bytes for SHA-1, 32 for SHA-256)
 //
 // Parameters:
 //   - w: Writer to output the serialized index
@@ -182,24 +281,35 @@ func (idx *Index) Count() int {
 // Returns an error if writing fails at any stage.
 func (idx *Index) Serialize(w io.Writer) error {
 	buf := new(bytes.Buffer)
+	version := idx.writeVersion()
 
 	if err := idx.writeHeader(buf); err != nil {
 		return fmt.Errorf("failed to write header: %w", err)
 	}
 
+	prevPath := ""
 	for _, entry := range idx.Entries {
-		if err := entry.Serialize(buf); err != nil {
+		if err := entry.serialize(buf, version, prevPath); err != nil {
 			return fmt.Errorf("failed to serialize entry %s: %w", entry.Path, err)
 		}
+		prevPath = entry.Path.String()
+	}
+
+	if idx.CacheTree != nil {
+		if err := writeExtension(buf, CacheTreeSignature, idx.CacheTree.serialize); err != nil {
+			return fmt.Errorf("failed to write cache tree: %w", err)
+		}
 	}
 
 	content := buf.Bytes()
-	checksum := sha1.Sum(content)
+	hasher := idx.hashAlgorithm().New()
+	hasher.Write(content)
+	checksum := hasher.Sum(nil)
 
 	if _, err := w.Write(content); err != nil {
 		return fmt.Errorf("failed to write content: %w", err)
 	}
-	if _, err := w.Write(checksum[:]); err != nil {
+	if _, err := w.Write(checksum); err != nil {
 		return fmt.Errorf("failed to write checksum: %w", err)
 	}
 
@@ -217,7 +327,7 @@ func (idx *Index) writeHeader(w io.Writer) error {
 		return fmt.Errorf("failed to write signature: %w", err)
 	}
 
-	if err := binary.Write(w, binary.BigEndian, idx.Version); err != nil {
+	if err := binary.Write(w, binary.BigEndian, idx.writeVersion()); err != nil {
 		return fmt.Errorf("failed to write version: %w", err)
 	}
 
@@ -230,7 +340,8 @@ func (idx *Index) writeHeader(w io.Writer) error {
 }
 
 // Deserialize reads an index from binary data in Git's format.
-// The data must include a valid header, entries, and matching SHA-1 checksum.
+// The data must include a valid header, entries, and matching checksum.
+// Hashes are read with idx.HashAlgorithm, which defaults to SHA-1.
 //
 // Parameters:
 //   - r: Reader containing the serialized index data
@@ -246,47 +357,103 @@ func (idx *Index) Deserialize(r io.Reader) error {
 		return fmt.Errorf("failed to read data: %w", err)
 	}
 
-	if len(data) < IndexHeaderSize+IndexChecksumSize {
-		return fmt.Errorf("invalid index file: too small")
+	algorithm := idx.HashAlgorithm
+	if algorithm == "" {
+		algorithm = objects.DefaultHashAlgorithm
 	}
 
-	if err := validateChecksum(data); err != nil {
+	if err := validateChecksum(data, algorithm); err != nil {
 		return err
 	}
 
-	content := data[:len(data)-IndexChecksumSize]
+	content := data[:len(data)-algorithm.Size()]
 	buf := bytes.NewReader(content)
 	if err := idx.readHeader(buf); err != nil {
 		return fmt.Errorf("failed to read header: %w", err)
 	}
 
 	idx.entryMap = make(map[scpath.RelativePath]*Entry, len(idx.Entries))
+	idx.CacheTree = nil
 
+	prevPath := ""
 	for i := range idx.Entries {
 		entry := &Entry{}
-		if _, err := entry.Deserialize(buf); err != nil {
+		if _, err := entry.deserialize(buf, algorithm, idx.Version, prevPath); err != nil {
 			return fmt.Errorf("failed to deserialize entry %d: %w", i, err)
 		}
 		idx.Entries[i] = entry
 		idx.entryMap[entry.Path.Normalize()] = entry
+		prevPath = entry.Path.String()
+	}
+
+	if err := idx.readExtensions(buf, algorithm); err != nil {
+		return fmt.Errorf("failed to read extensions: %w", err)
 	}
 
 	return nil
 }
 
-// validateChecksum verifies the SHA-1 checksum of the index data.
+// writeExtension writes an extension's header followed by the data that
+// write produces.
+func writeExtension(buf *bytes.Buffer, signature string, write func(*bytes.Buffer)) error {
+	data := new(bytes.Buffer)
+	write(data)
+
+	buf.WriteString(signature)
+	if err := binary.Write(buf, binary.BigEndian, uint32(data.Len())); err != nil {
+		return err
+	}
+	_, err := buf.Write(data.Bytes())
+	return err
+}
+
+// readExtensions reads the extensions between the last entry and the
+// checksum, keeping the cache tree and skipping other optional extensions.
+func (idx *Index) readExtensions(r *bytes.Reader, algorithm objects.HashAlgorithm) error {
+	for r.Len() > 0 {
+		if r.Len() < ExtensionHeaderSize {
+			return fmt.Errorf("truncated extension header")
+		}
+		signature := make([]byte, 4)
+		var size uint32
+		r.Read(signature)
+		binary.Read(r, binary.BigEndian, &size)
+		if int64(size) > int64(r.Len()) {
+			return fmt.Errorf("extension %s: size %d exceeds index", signature, size)
+		}
+		data := make([]byte, size)
+		r.Read(data)
+
+		switch {
+		case string(signature) == CacheTreeSignature:
+			cacheTree, err := readCacheTree(data, algorithm)
+			if err != nil {
+				return fmt.Errorf("extension %s: %w", signature, err)
+			}
+			idx.CacheTree = cacheTree
+		case signature[0] < 'A' || signature[0] > 'Z':
+			return fmt.Errorf("unsupported required extension %q", signature)
+		}
+	}
+	return nil
+}
+
+// validateChecksum verifies the checksum of the index data, computed with the
+// repository's hash algorithm.
 // This ensures the index file hasn't been corrupted or tampered with.
-func validateChecksum(data []byte) error {
-	if len(data) < IndexHeaderSize+IndexChecksumSize {
+func validateChecksum(data []byte, algorithm objects.HashAlgorithm) error {
+	if len(data) < IndexHeaderSize+algorithm.Size() {
 		return fmt.Errorf("invalid index file: too small")
 	}
 
-	contentSize := len(data) - IndexChecksumSize
+	contentSize := len(data) - algorithm.Size()
 	content := data[:contentSize]
 	expectedChecksum := data[contentSize:]
-	actualChecksum := sha1.Sum(content)
+	hasher := algorithm.New()
+	hasher.Write(content)
+	actualChecksum := hasher.Sum(nil)
 
-	if !bytes.Equal(expectedChecksum, actualChecksum[:]) {
+	if !bytes.Equal(expectedChecksum, actualChecksum) {
 		return fmt.Errorf("index checksum mismatch")
 	}
 	return nil
@@ -306,7 +473,7 @@ func (idx *Index) readHeader(r io.Reader) error {
 	if err := binary.Read(r, binary.BigEndian, &idx.Version); err != nil {
 		return fmt.Errorf("failed to read version: %w", err)
 	}
-	if idx.Version != IndexVersion {
+	if idx.Version < IndexVersion || idx.Version > IndexVersionCompressed {
 		return fmt.Errorf("unsupported index version: %d", idx.Version)
 	}
 
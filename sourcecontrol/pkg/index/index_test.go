@@ -3,6 +3,7 @@ package index
 import (
 	"bytes"
 	"crypto/sha1"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"os"
@@ -294,6 +295,36 @@ func TestIndexClear(t *testing.T) {
 	}
 }
 
+// TestIndexReplace tests replacing the index with entries that include conflict stages
+func TestIndexReplace(t *testing.T) {
+	idx := NewIndex()
+	idx.Add(createTestEntry("old.txt", createTestHash("old")))
+
+	var entries []*Entry
+	for _, stage := range []uint8{3, 1, 2} {
+		e := createTestEntry("conflict.txt", createTestHash(fmt.Sprint(stage)))
+		e.Stage = stage
+		entries = append(entries, e)
+	}
+	entries = append(entries, createTestEntry("a.txt", createTestHash("a")))
+	idx.Replace(entries)
+
+	if idx.Count() != 4 {
+		t.Fatalf("expected 4 entries, got %d", idx.Count())
+	}
+	if idx.Has(mustRelativePath("old.txt")) {
+		t.Error("old entry should have been discarded")
+	}
+	if _, ok := idx.Get(mustRelativePath("conflict.txt")); ok {
+		t.Error("Get() should not return conflict stages")
+	}
+	for i, want := range []uint8{0, 1, 2, 3} {
+		if got := idx.Entries[i].Stage; got != want {
+			t.Errorf("entry %d has stage %d, want %d", i, got, want)
+		}
+	}
+}
+
 // TestIndexPaths tests retrieving all paths from the index
 func TestIndexPaths(t *testing.T) {
 	idx := NewIndex()
@@ -422,6 +453,47 @@ func TestIndexSerializeDeserialize(t *testing.T) {
 	}
 }
 
+// TestIndexSerializeDeserializeSHA256 tests an index of a SHA-256 repository,
+// whose entries hold 32-byte hashes and whose checksum is SHA-256
+func TestIndexSerializeDeserializeSHA256(t *testing.T) {
+	originalIdx := NewIndexWithAlgorithm(objects.SHA256)
+	for _, name := range []string{"a.txt", "dir/b.txt", "dir/sub/c.txt"} {
+		hash := objects.SHA256.HashObject(objects.BlobType, objects.ObjectContent(name))
+		originalIdx.Add(createTestEntry(name, hash.String()))
+	}
+
+	buf := new(bytes.Buffer)
+	if err := originalIdx.Serialize(buf); err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+
+	// The trailing checksum is 32 bytes of SHA-256
+	data := buf.Bytes()
+	checksum := sha256.Sum256(data[:len(data)-sha256.Size])
+	if !bytes.Equal(data[len(data)-sha256.Size:], checksum[:]) {
+		t.Error("checksum is not the SHA-256 of the content")
+	}
+
+	deserializedIdx := NewIndexWithAlgorithm(objects.SHA256)
+	if err := deserializedIdx.Deserialize(bytes.NewReader(data)); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+
+	if deserializedIdx.Count() != originalIdx.Count() {
+		t.Fatalf("entry count mismatch: expected %d, got %d", originalIdx.Count(), deserializedIdx.Count())
+	}
+	for i, origEntry := range originalIdx.Entries {
+		got := deserializedIdx.Entries[i]
+		if got.Path != origEntry.Path || got.BlobHash != origEntry.BlobHash {
+			t.Errorf("entry %d = %s %s, want %s %s", i, got.Path, got.BlobHash, origEntry.Path, origEntry.BlobHash)
+		}
+	}
+
+	if err := NewIndex().Deserialize(bytes.NewReader(data)); err == nil {
+		t.Error("reading a SHA-256 index as SHA-1 should fail")
+	}
+}
+
 // TestIndexSerializeEmpty tests serializing an empty index
 func TestIndexSerializeEmpty(t *testing.T) {
 	idx := NewIndex()
@@ -595,21 +667,21 @@ func TestValidateChecksum(t *testing.T) {
 	validData := append(content, checksum[:]...)
 
 	// Test valid checksum
-	err := validateChecksum(validData)
+	err := validateChecksum(validData, objects.SHA1)
 	if err != nil {
 		t.Errorf("expected no error for valid checksum, got: %v", err)
 	}
 
 	// Test invalid checksum
 	invalidData := append(content, []byte("invalid checksum data")...)
-	err = validateChecksum(invalidData)
+	err = validateChecksum(invalidData, objects.SHA1)
 	if err == nil {
 		t.Error("expected error for invalid checksum, got nil")
 	}
 
 	// Test data too small
 	smallData := []byte("small")
-	err = validateChecksum(smallData)
+	err = validateChecksum(smallData, objects.SHA1)
 	if err == nil {
 		t.Error("expected error for data too small, got nil")
 	}
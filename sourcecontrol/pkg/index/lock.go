@@ -0,0 +1,130 @@
+package index
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+)
+
+// ErrIndexLocked is returned when index.lock already exists, which means
+// another process is changing the index.
+var ErrIndexLocked = errors.New("another srcc process is running")
+
+// LockSuffix is appended to the index path to name its lock file.
+const LockSuffix = ".lock"
+
+// Lock is an exclusive lock on an index file, held by creating index.lock
+// next to it. The new index is written to the lock file and renamed over the
+// index, so readers see either the old or the new content, never a partial
+// write, and two writers cannot interleave.
+//
+// Callers that read, modify and write the index should take the lock before
+// reading, so that changes made by another process in between are not lost:
+//
+//	lock, err := index.LockIndex(path)
+//	if err != nil {
+//	    return err
+//	}
+//	defer lock.Rollback()
+//	idx, err := index.Read(path)
+//	...
+//	return lock.Commit(idx)
+type Lock struct {
+	path scpath.AbsolutePath
+	file *os.File
+}
+
+// LockIndex takes the lock on the index at path.
+//
+// Returns an error wrapping ErrIndexLocked if the lock is already held.
+func LockIndex(path scpath.AbsolutePath) (*Lock, error) {
+	lockPath := path.String() + LockSuffix
+
+	file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if errors.Is(err, os.ErrExist) {
+		return nil, fmt.Errorf("unable to create '%s': %w; if it has exited, remove the file and try again", lockPath, ErrIndexLocked)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to create '%s': %w", lockPath, err)
+	}
+
+	return &Lock{path: path, file: file}, nil
+}
+
+// Path returns the path of the lock file.
+func (l *Lock) Path() string {
+	return l.path.String() + LockSuffix
+}
+
+// Commit writes idx to the lock file and renames it over the index, which
+// releases the lock. The version written follows the repository's
+// index.version setting, as for Index.Write. Racy entries whose files have
+// changed are smudged first, and idx takes the new file's timestamp.
+func (l *Lock) Commit(idx *Index) error {
+	if l.file == nil {
+		return fmt.Errorf("index lock '%s' is no longer held", l.Path())
+	}
+
+	if version := idx.configuredVersion(l.path); version != 0 {
+		idx.Version = version
+	}
+	idx.smudgeRacyEntries(worktreeRoot(l.path))
+	buf := new(bytes.Buffer)
+	if err := idx.Serialize(buf); err != nil {
+		l.Rollback()
+		return fmt.Errorf("failed to serialize index: %w", err)
+	}
+
+	_, err := l.file.Write(buf.Bytes())
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+	l.file = nil
+	if err == nil {
+		err = os.Rename(l.Path(), l.path.String())
+	}
+	if err != nil {
+		os.Remove(l.Path())
+		return fmt.Errorf("failed to write index file: %w", err)
+	}
+	idx.Timestamp = indexTimestamp(l.path)
+	return nil
+}
+
+// Rollback releases the lock without changing the index. It does nothing
+// once the lock has been committed or rolled back, so it can be deferred.
+func (l *Lock) Rollback() error {
+	if l.file == nil {
+		return nil
+	}
+	l.file.Close()
+	l.file = nil
+
+	if err := os.Remove(l.Path()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove index lock: %w", err)
+	}
+	return nil
+}
+
+// Update locks the index at path, reads it, lets fn change it and writes it
+// back, holding the lock throughout. If fn returns an error the index is
+// left unchanged.
+func Update(path scpath.AbsolutePath, fn func(idx *Index) error) error {
+	lock, err := LockIndex(path)
+	if err != nil {
+		return err
+	}
+	defer lock.Rollback()
+
+	idx, err := Read(path)
+	if err != nil {
+		return err
+	}
+	if err := fn(idx); err != nil {
+		return err
+	}
+	return lock.Commit(idx)
+}
@@ -0,0 +1,134 @@
+package index
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+	"github.com/utkarsh5026/SourceControl/pkg/store"
+)
+
+// setupLockTestRepo returns the root of a repository with an empty .git
+// directory, and the path of its index.
+func setupLockTestRepo(t *testing.T) (scpath.RepositoryPath, scpath.AbsolutePath) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", t.TempDir())
+
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, scpath.SourceDir), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return scpath.RepositoryPath(root), scpath.AbsolutePath(filepath.Join(root, scpath.SourceDir, "index"))
+}
+
+func TestLockIndex(t *testing.T) {
+	_, indexPath := setupLockTestRepo(t)
+
+	lock, err := LockIndex(indexPath)
+	if err != nil {
+		t.Fatalf("LockIndex() failed: %v", err)
+	}
+	if _, err := LockIndex(indexPath); !errors.Is(err, ErrIndexLocked) {
+		t.Errorf("second LockIndex() err = %v, want ErrIndexLocked", err)
+	}
+
+	idx := NewIndex()
+	if err := idx.Write(indexPath); !errors.Is(err, ErrIndexLocked) {
+		t.Errorf("Write() while locked err = %v, want ErrIndexLocked", err)
+	}
+
+	idx.Add(createTestEntry("file", createTestHash("file")))
+	if err := lock.Commit(idx); err != nil {
+		t.Fatalf("Commit() failed: %v", err)
+	}
+	if _, err := os.Stat(lock.Path()); !os.IsNotExist(err) {
+		t.Error("Commit() should remove index.lock")
+	}
+	if err := lock.Rollback(); err != nil {
+		t.Errorf("Rollback() after Commit() failed: %v", err)
+	}
+
+	read, err := Read(indexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !read.Has("file") {
+		t.Error("committed index was not written")
+	}
+}
+
+func TestLockIndex_Rollback(t *testing.T) {
+	_, indexPath := setupLockTestRepo(t)
+
+	idx := NewIndex()
+	idx.Add(createTestEntry("kept", createTestHash("kept")))
+	if err := idx.Write(indexPath); err != nil {
+		t.Fatal(err)
+	}
+
+	err := Update(indexPath, func(idx *Index) error {
+		idx.Add(createTestEntry("dropped", createTestHash("dropped")))
+		return fmt.Errorf("abort")
+	})
+	if err == nil {
+		t.Fatal("Update() should return fn's error")
+	}
+
+	read, err := Read(indexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !read.Has("kept") || read.Has("dropped") {
+		t.Errorf("a failed Update() changed the index: %v", read.Paths())
+	}
+	if _, err := LockIndex(indexPath); err != nil {
+		t.Errorf("lock not released after failed Update(): %v", err)
+	}
+}
+
+func TestManager_ConcurrentAdds(t *testing.T) {
+	root, indexPath := setupLockTestRepo(t)
+	objectStore := store.NewMemoryObjectStore()
+
+	const writers = 8
+	for i := range writers {
+		name := filepath.Join(root.String(), fmt.Sprintf("file%d.txt", i))
+		if err := os.WriteFile(name, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Each writer has its own manager, as separate processes would, and
+	// retries while another holds the lock
+	var wg sync.WaitGroup
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m := NewManager(root)
+			for {
+				_, err := m.Add([]string{fmt.Sprintf("file%d.txt", i)}, objectStore)
+				if !errors.Is(err, ErrIndexLocked) {
+					if err != nil {
+						t.Error(err)
+					}
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	idx, err := Read(indexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if idx.Count() != writers {
+		t.Errorf("index has %d entries after %d concurrent adds: %v", idx.Count(), writers, idx.Paths())
+	}
+}
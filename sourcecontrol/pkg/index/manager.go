@@ -2,13 +2,16 @@ package index
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sync"
 
 	"github.com/utkarsh5026/SourceControl/pkg/common/fileops"
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
 	"github.com/utkarsh5026/SourceControl/pkg/objects/blob"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/repoformat"
 	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
 	"github.com/utkarsh5026/SourceControl/pkg/store"
 )
@@ -50,6 +53,7 @@ func (m *Manager) Initialize() error {
 type AddResult struct {
 	Added    []string           // New files added to index
 	Modified []string           // Existing files updated in index
+	Removed  []string           // Tracked files deleted from the working directory
 	Ignored  []string           // Files skipped due to ignore patterns
 	Failed   []AddFailureResult // Files that failed to add
 }
@@ -60,85 +64,59 @@ type AddFailureResult struct {
 	Reason string
 }
 
-// Add adds files to the index (like git add).
+// Add adds files to the index (like git add). It is AddWithOptions with
+// the default options: directories are added recursively, ignored files are
+// skipped and deletions of tracked files are staged.
 //
 // This operation:
 // 1. Reads the file content from the working directory
 // 2. Creates a blob object and stores it in the repository
 // 3. Updates the index entry with the file's metadata and blob SHA
 func (m *Manager) Add(paths []string, objectStore store.ObjectStore) (*AddResult, error) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	result := &AddResult{
-		Added:    make([]string, 0),
-		Modified: make([]string, 0),
-		Ignored:  make([]string, 0),
-		Failed:   make([]AddFailureResult, 0),
-	}
-
-	for _, path := range paths {
-		if err := m.addFile(path, objectStore, result); err != nil {
-			result.Failed = append(result.Failed, AddFailureResult{
-				Path:   path,
-				Reason: err.Error(),
-			})
-		}
-	}
-
-	if err := m.saveIndex(); err != nil {
-		return result, fmt.Errorf("failed to save index: %w", err)
-	}
-
-	return result, nil
+	return m.AddWithOptions(context.Background(), paths, objectStore, AddOptions{})
 }
 
-// addFile adds a single file to the index.
-func (m *Manager) addFile(path string, objectStore store.ObjectStore, result *AddResult) error {
-	absPath, relPath, err := m.resolvePaths(path)
-	if err != nil {
-		return err
+// writeBlob stores the content of the file at absPath as a blob, or for a
+// symbolic link the path it points to. Files larger than
+// store.BlobStreamThreshold are streamed into the store so that adding them
+// does not need memory proportional to their size.
+func writeBlob(absPath scpath.AbsolutePath, info os.FileInfo, objectStore store.ObjectStore) (objects.ObjectHash, error) {
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(absPath.String())
+		if err != nil {
+			return "", fmt.Errorf("failed to read link: %w", err)
+		}
+		hash, err := objectStore.WriteObject(blob.NewBlob([]byte(filepath.ToSlash(target))))
+		if err != nil {
+			return "", fmt.Errorf("failed to store blob: %w", err)
+		}
+		return hash, nil
 	}
 
-	info, err := os.Stat(absPath.String())
-	if err != nil {
-		return fmt.Errorf("failed to stat file: %w", err)
-	}
+	if info.Size() > store.BlobStreamThreshold {
+		file, err := os.Open(absPath.String())
+		if err != nil {
+			return "", fmt.Errorf("failed to read file: %w", err)
+		}
+		defer file.Close()
 
-	if info.IsDir() {
-		return fmt.Errorf("cannot add directory (use files within it)")
+		hash, err := objectStore.WriteBlobStream(file, info.Size())
+		if err != nil {
+			return "", fmt.Errorf("failed to store blob: %w", err)
+		}
+		return hash, nil
 	}
 
-	// Read file content
 	content, err := fileops.ReadBytesStrict(absPath)
 	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
+		return "", fmt.Errorf("failed to read file: %w", err)
 	}
 
-	// Create blob and store it
-	b := blob.NewBlob(content)
-	hash, err := objectStore.WriteObject(b)
-	if err != nil {
-		return fmt.Errorf("failed to store blob: %w", err)
-	}
-
-	// Create or update index entry
-	isNew := !m.index.Has(relPath)
-
-	entry, err := NewEntryFromFileInfo(relPath, info, hash)
+	hash, err := objectStore.WriteObject(blob.NewBlob(content))
 	if err != nil {
-		return fmt.Errorf("failed to create entry: %w", err)
+		return "", fmt.Errorf("failed to store blob: %w", err)
 	}
-
-	m.index.Add(entry)
-
-	if isNew {
-		result.Added = append(result.Added, relPath.String())
-	} else {
-		result.Modified = append(result.Modified, relPath.String())
-	}
-
-	return nil
+	return hash, nil
 }
 
 // RemoveResult represents the result of removing files from the index.
@@ -158,6 +136,12 @@ func (m *Manager) Remove(paths []string, deleteFromDisk bool) (*RemoveResult, er
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	lock, err := m.lockIndex()
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Rollback()
+
 	result := &RemoveResult{
 		Removed: make([]string, 0),
 		Failed:  make([]RemoveFailureResult, 0),
@@ -194,93 +178,26 @@ func (m *Manager) Remove(paths []string, deleteFromDisk bool) (*RemoveResult, er
 	}
 
 	// Save index after all removals
-	if err := m.saveIndex(); err != nil {
+	if err := lock.Commit(m.index); err != nil {
 		return result, fmt.Errorf("failed to save index: %w", err)
 	}
 
 	return result, nil
 }
 
-// StatusResult represents the repository status.
-type StatusResult struct {
-	Staged    StagedChanges
-	Unstaged  UnstagedChanges
-	Untracked []string
-	Ignored   []string
-}
-
-// StagedChanges represents changes that are staged (in index but differ from HEAD).
-type StagedChanges struct {
-	Added    []string // New files in index (not in HEAD)
-	Modified []string // Files modified in index (different from HEAD)
-	Deleted  []string // Files deleted from index (present in HEAD)
-}
-
-// UnstagedChanges represents changes in working directory (differ from index).
-type UnstagedChanges struct {
-	Modified []string // Files modified in working dir (different from index)
-	Deleted  []string // Files deleted from working dir (present in index)
-}
-
-// Status returns the current repository status (like git status).
-// Note: This is a simplified version. A complete implementation would:
-// - Compare index with HEAD commit for staged changes
-// - Use ignore patterns to filter untracked files
-// - Potentially use goroutines for parallel file checking
-func (m *Manager) Status() (*StatusResult, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	result := &StatusResult{
-		Staged: StagedChanges{
-			Added:    make([]string, 0),
-			Modified: make([]string, 0),
-			Deleted:  make([]string, 0),
-		},
-		Unstaged: UnstagedChanges{
-			Modified: make([]string, 0),
-			Deleted:  make([]string, 0),
-		},
-		Untracked: make([]string, 0),
-		Ignored:   make([]string, 0),
-	}
-
-	// Check indexed files for modifications
-	for _, entry := range m.index.Entries {
-		absPath := filepath.Join(m.repoRoot.String(), entry.Path.String())
-		info, err := os.Stat(absPath)
-
-		if os.IsNotExist(err) {
-			// File exists in index but not in working directory
-			result.Unstaged.Deleted = append(result.Unstaged.Deleted, entry.Path.String())
-			continue
-		}
-
-		if err != nil {
-			// Can't check file - skip it
-			continue
-		}
-
-		// Check if file is modified
-		if entry.IsModified(info) {
-			result.Unstaged.Modified = append(result.Unstaged.Modified, entry.Path.String())
-		}
-	}
-
-	// Find untracked files (simplified - just checking working directory)
-	// A complete implementation would walk the directory tree
-	// and check against .sourceignore patterns
-
-	return result, nil
-}
-
 // Clear removes all entries from the index.
 func (m *Manager) Clear() error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	lock, err := m.lockIndex()
+	if err != nil {
+		return err
+	}
+	defer lock.Rollback()
+
 	m.index.Clear()
-	return m.saveIndex()
+	return lock.Commit(m.index)
 }
 
 // GetIndex returns a read-only copy of the index.
@@ -292,9 +209,25 @@ func (m *Manager) GetIndex() *Index {
 	return m.index
 }
 
-// saveIndex writes the index to disk (caller must hold lock).
-func (m *Manager) saveIndex() error {
-	return m.index.Write(m.indexPath.ToAbsolutePath())
+// lockIndex takes index.lock and reloads the index from disk, so that a
+// change made while holding the lock applies to the latest staging area
+// rather than to a copy another process has since replaced (caller must
+// hold mu).
+func (m *Manager) lockIndex() (*Lock, error) {
+	lock, err := LockIndex(m.indexPath.ToAbsolutePath())
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := Read(m.indexPath.ToAbsolutePath())
+	if err != nil {
+		lock.Rollback()
+		return nil, fmt.Errorf("failed to load index: %w", err)
+	}
+
+	index.keepConfiguredVersion(m.index)
+	m.index = index
+	return lock, nil
 }
 
 // resolvePaths converts a path to absolute and relative forms.
@@ -316,7 +249,15 @@ func (m *Manager) resolvePaths(path string) (scpath.AbsolutePath, scpath.Relativ
 }
 
 // Read reads an index file from disk.
+//
+// The index lives directly in the .git directory, so the hash algorithm its
+// entries use is taken from the config file next to it.
 func Read(path scpath.AbsolutePath) (*Index, error) {
+	algorithm, err := repoformat.ReadObjectFormat(scpath.SourcePath(path.Dir()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index file: %w", err)
+	}
+
 	data, err := fileops.ReadBytes(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read index file: %w", err)
@@ -324,13 +265,14 @@ func Read(path scpath.AbsolutePath) (*Index, error) {
 
 	// If file doesn't exist, return empty index
 	if data == nil {
-		return NewIndex(), nil
+		return NewIndexWithAlgorithm(algorithm), nil
 	}
 
-	index := NewIndex()
+	index := NewIndexWithAlgorithm(algorithm)
 	if err := index.Deserialize(bytes.NewReader(data)); err != nil {
 		return nil, fmt.Errorf("failed to deserialize index: %w", err)
 	}
+	index.Timestamp = indexTimestamp(path)
 
 	return index, nil
 }
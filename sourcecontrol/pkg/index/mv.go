@@ -0,0 +1,238 @@
+package index
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+)
+
+// ErrBadMove is wrapped by the errors Move returns for a move that cannot
+// be made, such as one whose source is not tracked.
+var ErrBadMove = errors.New("bad move")
+
+// MoveOptions configures Move.
+type MoveOptions struct {
+	// Force lets a moved file replace an existing file (mv -f)
+	Force bool
+
+	// DryRun reports what would be moved without changing anything
+	// (mv --dry-run)
+	DryRun bool
+}
+
+// MoveResult represents the result of moving files.
+type MoveResult struct {
+	Moved []Rename // Sources and where they were moved, in the order given
+}
+
+// Rename is one source of a move and its new path.
+type Rename struct {
+	From string
+	To   string
+}
+
+// plannedMove is a validated move: the rename to make in the working tree
+// and the index entries it carries along.
+type plannedMove struct {
+	from, to scpath.RelativePath
+	entries  []*Entry // tracked entries at from, or below it for a directory
+}
+
+// Move renames tracked files or directories in the working tree and the
+// index, like git mv. With one source, destination is its new path, or a
+// directory to move it into if one exists there; with several, destination
+// must be an existing directory. Moved entries keep their blob hash, mode
+// and stat data, so the files are not staged anew.
+//
+// Every move is checked before anything changes, and the index stays
+// locked while the working tree is renamed; if a rename or saving the index
+// fails, the renames already made are undone, so that the working tree and
+// the index change together or not at all.
+//
+// Parameters:
+//   - sources: Tracked files or directories, relative to the repository root
+//   - destination: The new path, or the directory to move sources into
+//   - opts: Whether to overwrite files, and whether to change anything
+//
+// Returns:
+//   - *MoveResult: The renames made
+//   - error: Wrapping ErrBadMove if a move cannot be made, or if the index
+//     cannot be locked or saved
+func (m *Manager) Move(sources []string, destination string, opts MoveOptions) (*MoveResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var lock *Lock
+	if opts.DryRun {
+		index, err := Read(m.indexPath.ToAbsolutePath())
+		if err != nil {
+			return nil, fmt.Errorf("failed to load index: %w", err)
+		}
+		m.index = index
+	} else {
+		var err error
+		if lock, err = m.lockIndex(); err != nil {
+			return nil, err
+		}
+		defer lock.Rollback()
+	}
+
+	moves, err := m.planMoves(sources, destination, opts.Force)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MoveResult{Moved: make([]Rename, 0, len(moves))}
+	for _, move := range moves {
+		result.Moved = append(result.Moved, Rename{From: move.from.String(), To: move.to.String()})
+	}
+	if opts.DryRun {
+		return result, nil
+	}
+
+	var done []plannedMove
+	undo := func() {
+		for i := len(done) - 1; i >= 0; i-- {
+			os.Rename(m.absolute(done[i].to), m.absolute(done[i].from))
+		}
+	}
+	for _, move := range moves {
+		if err := os.Rename(m.absolute(move.from), m.absolute(move.to)); err != nil {
+			undo()
+			return nil, fmt.Errorf("renaming '%s' failed: %w", move.from, err)
+		}
+		done = append(done, move)
+	}
+
+	for _, move := range moves {
+		m.moveEntries(move)
+	}
+	if err := lock.Commit(m.index); err != nil {
+		undo()
+		return nil, fmt.Errorf("failed to save index: %w", err)
+	}
+	return result, nil
+}
+
+// planMoves checks each source and works out where it goes.
+func (m *Manager) planMoves(sources []string, destination string, force bool) ([]plannedMove, error) {
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("%w: no source given", ErrBadMove)
+	}
+
+	_, dest, err := m.resolvePaths(destination)
+	if err != nil || !scpath.IsPathSafe(dest.String()) {
+		return nil, fmt.Errorf("%w: '%s' is outside repository", ErrBadMove, destination)
+	}
+	dest = dest.Normalize()
+	if dest == "." {
+		dest = ""
+	}
+
+	info, err := os.Lstat(m.absolute(dest))
+	intoDir := dest == "" || err == nil && info.IsDir()
+	if len(sources) > 1 && !intoDir {
+		return nil, fmt.Errorf("%w: destination '%s' is not a directory", ErrBadMove, destination)
+	}
+
+	moves := make([]plannedMove, 0, len(sources))
+	targets := make(map[scpath.RelativePath]bool)
+	for _, source := range sources {
+		_, from, err := m.resolvePaths(source)
+		if err != nil || !scpath.IsPathSafe(from.String()) {
+			return nil, fmt.Errorf("%w: '%s' is outside repository", ErrBadMove, source)
+		}
+		from = from.Normalize()
+
+		to := dest
+		if intoDir {
+			to = scpath.RelativePath(path.Join(dest.String(), path.Base(from.String())))
+		}
+
+		move, err := m.planMove(from, to, force)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v, source=%s, destination=%s", ErrBadMove, err, from, to)
+		}
+		if targets[to] {
+			return nil, fmt.Errorf("%w: multiple sources for the same target, source=%s, destination=%s", ErrBadMove, from, to)
+		}
+		targets[to] = true
+		moves = append(moves, move)
+	}
+	return moves, nil
+}
+
+// planMove checks that from can be moved to to.
+func (m *Manager) planMove(from, to scpath.RelativePath, force bool) (plannedMove, error) {
+	move := plannedMove{from: from, to: to}
+	if from == "." || from == "" {
+		return move, errors.New("bad source")
+	}
+
+	info, err := os.Lstat(m.absolute(from))
+	if err != nil {
+		return move, errors.New("bad source")
+	}
+	if from == to {
+		return move, errors.New("can not move to itself")
+	}
+
+	if info.IsDir() {
+		if strings.HasPrefix(to.String()+"/", from.String()+"/") {
+			return move, errors.New("can not move directory into itself")
+		}
+		for _, entry := range m.index.Entries {
+			if strings.HasPrefix(entry.Path.String(), from.String()+"/") {
+				if entry.Stage != 0 {
+					return move, errors.New("conflicted")
+				}
+				move.entries = append(move.entries, entry)
+			}
+		}
+		if len(move.entries) == 0 {
+			return move, errors.New("source directory is empty")
+		}
+	} else {
+		if m.index.IsConflicted(from) {
+			return move, errors.New("conflicted")
+		}
+		entry, ok := m.index.Get(from)
+		if !ok {
+			return move, errors.New("not under version control")
+		}
+		move.entries = []*Entry{entry}
+	}
+
+	if parent := path.Dir(to.String()); parent != "." {
+		if parentInfo, err := os.Stat(m.absolute(scpath.RelativePath(parent))); err != nil || !parentInfo.IsDir() {
+			return move, errors.New("destination directory does not exist")
+		}
+	}
+	if targetInfo, err := os.Lstat(m.absolute(to)); err == nil {
+		if !force || info.IsDir() || targetInfo.IsDir() {
+			return move, errors.New("destination exists")
+		}
+	}
+	return move, nil
+}
+
+// moveEntries re-records the entries of a move under their new paths,
+// replacing any entry a forced move overwrote.
+func (m *Manager) moveEntries(move plannedMove) {
+	m.index.Remove(move.to)
+	for _, entry := range move.entries {
+		moved := *entry
+		moved.Path = move.to + entry.Path[len(move.from):]
+		m.index.Remove(entry.Path)
+		m.index.Add(&moved)
+	}
+}
+
+// absolute returns the working tree path of a repository-relative path.
+func (m *Manager) absolute(relPath scpath.RelativePath) string {
+	return m.repoRoot.Join(relPath.String()).String()
+}
@@ -0,0 +1,151 @@
+package index
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+)
+
+// blobHashes returns the staged blob hash of each path in the index.
+func blobHashes(t *testing.T, indexPath scpath.AbsolutePath) map[string]objects.ObjectHash {
+	t.Helper()
+	idx, err := Read(indexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashes := make(map[string]objects.ObjectHash)
+	for _, entry := range idx.Entries {
+		hashes[entry.Path.String()] = entry.BlobHash
+	}
+	return hashes
+}
+
+func assertWorktree(t *testing.T, root scpath.RepositoryPath, present, absent []string) {
+	t.Helper()
+	for _, name := range present {
+		if _, err := os.Lstat(filepath.Join(root.String(), filepath.FromSlash(name))); err != nil {
+			t.Errorf("%s should exist: %v", name, err)
+		}
+	}
+	for _, name := range absent {
+		if _, err := os.Lstat(filepath.Join(root.String(), filepath.FromSlash(name))); !os.IsNotExist(err) {
+			t.Errorf("%s should not exist", name)
+		}
+	}
+}
+
+func TestManager_MoveFile(t *testing.T) {
+	m, root, indexPath, _ := setupRemoveTest(t)
+	before := blobHashes(t, indexPath)
+
+	result, err := m.Move([]string{"README.md"}, "NOTES.md", MoveOptions{})
+	if err != nil {
+		t.Fatalf("Move() failed: %v", err)
+	}
+
+	if want := []Rename{{From: "README.md", To: "NOTES.md"}}; !reflect.DeepEqual(result.Moved, want) {
+		t.Errorf("Moved = %v, want %v", result.Moved, want)
+	}
+	after := blobHashes(t, indexPath)
+	if _, ok := after["README.md"]; ok {
+		t.Error("README.md should no longer be staged")
+	}
+	if after["NOTES.md"] != before["README.md"] {
+		t.Errorf("NOTES.md hash = %s, want %s", after["NOTES.md"], before["README.md"])
+	}
+	assertWorktree(t, root, []string{"NOTES.md"}, []string{"README.md"})
+}
+
+func TestManager_MoveIntoDirectory(t *testing.T) {
+	m, root, indexPath, _ := setupRemoveTest(t)
+	before := blobHashes(t, indexPath)
+	writeWorktree(t, root, map[string]string{"docs/index.txt": "docs"})
+
+	if _, err := m.Move([]string{"README.md", "src/util"}, "docs", MoveOptions{}); err != nil {
+		t.Fatalf("Move() failed: %v", err)
+	}
+
+	want := []string{"docs/README.md", "docs/util/util.go", "src/main.go"}
+	if got := stagedPaths(t, indexPath); !reflect.DeepEqual(got, want) {
+		t.Errorf("staged = %v, want %v", got, want)
+	}
+	if blobHashes(t, indexPath)["docs/util/util.go"] != before["src/util/util.go"] {
+		t.Error("moved directory entries should keep their hash")
+	}
+	assertWorktree(t, root, []string{"docs/README.md", "docs/util/util.go"}, []string{"README.md", "src/util"})
+
+	// A directory can be renamed as a whole, untracked files included
+	if _, err := m.Move([]string{"src"}, "lib", MoveOptions{}); err != nil {
+		t.Fatalf("Move() failed: %v", err)
+	}
+	want = []string{"docs/README.md", "docs/util/util.go", "lib/main.go"}
+	if got := stagedPaths(t, indexPath); !reflect.DeepEqual(got, want) {
+		t.Errorf("staged = %v, want %v", got, want)
+	}
+	assertWorktree(t, root, []string{"lib/main.go", "lib/debug.log"}, []string{"src"})
+}
+
+func TestManager_MoveErrors(t *testing.T) {
+	m, root, indexPath, _ := setupRemoveTest(t)
+	writeWorktree(t, root, map[string]string{"untracked.txt": "new", "empty/.keep": ""})
+	before := stagedPaths(t, indexPath)
+
+	tests := []struct {
+		name        string
+		sources     []string
+		destination string
+		want        string
+	}{
+		{"missing source", []string{"missing.txt"}, "other.txt", "bad source"},
+		{"untracked", []string{"untracked.txt"}, "other.txt", "not under version control"},
+		{"untracked directory", []string{"empty"}, "other", "source directory is empty"},
+		{"destination exists", []string{"README.md"}, "untracked.txt", "destination exists"},
+		{"missing parent", []string{"README.md"}, "nowhere/README.md", "destination directory does not exist"},
+		{"into itself", []string{"src"}, "src/util", "can not move directory into itself"},
+		{"several to a file", []string{"README.md", "src/main.go"}, "new.txt", "is not a directory"},
+		{"same target", []string{"README.md", "README.md"}, "src", "multiple sources for the same target"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := m.Move(tt.sources, tt.destination, MoveOptions{})
+			if !errors.Is(err, ErrBadMove) || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Move() error = %v, want %q", err, tt.want)
+			}
+			if got := stagedPaths(t, indexPath); !reflect.DeepEqual(got, before) {
+				t.Errorf("staged = %v, want %v", got, before)
+			}
+		})
+	}
+	assertWorktree(t, root, []string{"README.md", "src/main.go", "untracked.txt"}, nil)
+}
+
+func TestManager_MoveForceAndDryRun(t *testing.T) {
+	m, root, indexPath, _ := setupRemoveTest(t)
+	before := blobHashes(t, indexPath)
+
+	result, err := m.Move([]string{"README.md"}, "src/main.go", MoveOptions{Force: true, DryRun: true})
+	if err != nil || len(result.Moved) != 1 {
+		t.Fatalf("Move(DryRun) = %+v, %v", result, err)
+	}
+	if got := blobHashes(t, indexPath); !reflect.DeepEqual(got, before) {
+		t.Errorf("dry run changed the index: %v", got)
+	}
+
+	if _, err := m.Move([]string{"README.md"}, "src/main.go", MoveOptions{Force: true}); err != nil {
+		t.Fatalf("Move(Force) failed: %v", err)
+	}
+	after := blobHashes(t, indexPath)
+	if len(after) != 2 || after["src/main.go"] != before["README.md"] {
+		t.Errorf("index after forced move = %v", after)
+	}
+	content, err := os.ReadFile(filepath.Join(root.String(), "src", "main.go"))
+	if err != nil || string(content) != "readme" {
+		t.Errorf("src/main.go = %q, %v; want the moved README", content, err)
+	}
+}
@@ -0,0 +1,27 @@
+package index
+
+import (
+	"context"
+
+	pool "github.com/utkarsh5026/SourceControl/pkg/common/concurrency"
+	"github.com/utkarsh5026/SourceControl/pkg/config"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+)
+
+// configuredParallelism returns the core.parallelism setting of the
+// repository at repoRoot, or 0 when it is unset, which leaves the number of
+// workers to the pool: one per CPU.
+func configuredParallelism(repoRoot scpath.RepositoryPath) int {
+	configMgr := config.NewManager(repoRoot)
+	if err := configMgr.Load(context.Background()); err != nil {
+		return 0
+	}
+	return config.NewTypedConfig(configMgr).Parallelism()
+}
+
+// newWorkerPool returns a pool for checking or hashing the working tree
+// files of the repository at repoRoot, with as many workers as its
+// core.parallelism setting allows.
+func newWorkerPool[T any, R any](repoRoot scpath.RepositoryPath) *pool.WorkerPool[T, R] {
+	return pool.NewWorkerPool[T, R](pool.WithWorkerCount(configuredParallelism(repoRoot)))
+}
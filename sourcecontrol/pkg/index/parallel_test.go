@@ -0,0 +1,74 @@
+package index
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/utkarsh5026/SourceControl/pkg/store"
+)
+
+func TestManager_ParallelAddAndStatus(t *testing.T) {
+	root, _ := setupLockTestRepo(t)
+	config := `{"core": {"parallelism": "3"}}`
+	if err := os.WriteFile(filepath.Join(root.String(), "config.json"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := configuredParallelism(root); got != 3 {
+		t.Fatalf("configuredParallelism() = %d, want 3", got)
+	}
+
+	files := make(map[string]string)
+	var want []string
+	for i := range 200 {
+		name := fmt.Sprintf("dir%d/file%03d.txt", i%7, i)
+		files[name] = name
+	}
+	writeWorktree(t, root, files)
+	// The repository configuration lives at the top of the working tree
+	want = append(want, "config.json")
+	for name := range files {
+		want = append(want, name)
+	}
+	sort.Strings(want)
+
+	m := NewManager(root)
+	result, err := m.AddWithOptions(context.Background(), []string{"."}, store.NewMemoryObjectStore(), AddOptions{})
+	if err != nil {
+		t.Fatalf("AddWithOptions() failed: %v", err)
+	}
+	if !reflect.DeepEqual(result.Added, want) {
+		t.Errorf("Added is not every file in path order: got %d paths from %v", len(result.Added), result.Added[:3])
+	}
+
+	// Change every third file, at a different size so the stat data shows it
+	var changed []string
+	for i, name := range want {
+		if i%3 == 0 {
+			writeWorktree(t, root, map[string]string{name: name + " changed"})
+			changed = append(changed, name)
+		}
+	}
+	status, err := m.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(status.Unstaged.Modified, changed) {
+		t.Errorf("Unstaged.Modified = %d paths, want %d in path order", len(status.Unstaged.Modified), len(changed))
+	}
+	if len(status.Entries) != len(want) || status.Entries[0].Path != want[0] {
+		t.Errorf("Entries = %d paths from %q, want %d from %q", len(status.Entries), status.Entries[0].Path, len(want), want[0])
+	}
+
+	result, err = m.AddWithOptions(context.Background(), []string{"."}, store.NewMemoryObjectStore(), AddOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result.Modified, changed) || len(result.Added) != 0 {
+		t.Errorf("re-add: Added = %v, Modified = %v, want Modified = %v", result.Added, result.Modified, changed)
+	}
+}
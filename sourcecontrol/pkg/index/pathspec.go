@@ -0,0 +1,153 @@
+package index
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+)
+
+// pathspec selects paths in the working tree and index, as Git pathspecs
+// do. A literal pathspec names a file or directory; one holding *, ? or
+// [...] is a glob matched against the whole repository-relative path, in
+// which * also matches '/', so "*.go" selects Go files at any depth.
+type pathspec struct {
+	original string         // as given by the user
+	path     string         // repository-relative, "" for the root
+	glob     *regexp.Regexp // nil for a literal pathspec
+	matched  bool           // whether anything was selected
+}
+
+// newPathspec parses a pathspec already made relative to the repository.
+func newPathspec(original string, path scpath.RelativePath) (*pathspec, error) {
+	p := &pathspec{original: original, path: string(path.Normalize())}
+	if p.path == "." {
+		p.path = ""
+	}
+	if !isGlob(p.path) {
+		return p, nil
+	}
+
+	glob, err := compileGlob(p.path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pathspec '%s': %w", original, err)
+	}
+	p.glob = glob
+	return p, nil
+}
+
+// matches reports whether the pathspec selects path, or a directory that
+// contains it.
+func (p *pathspec) matches(path string) bool {
+	if p.glob != nil {
+		return p.glob.MatchString(path)
+	}
+	return p.path == "" || path == p.path || strings.HasPrefix(path, p.path+"/")
+}
+
+// base returns the deepest directory that holds every path the pathspec
+// can match, where a walk of the working tree for it should start.
+func (p *pathspec) base() string {
+	if p.glob == nil {
+		return p.path
+	}
+	components := strings.Split(p.path, "/")
+	for i, component := range components {
+		if isGlob(component) {
+			return strings.Join(components[:i], "/")
+		}
+	}
+	return p.path
+}
+
+// Pathspecs is a list of parsed pathspecs, for commands that select index
+// entries or working tree files the way add does.
+type Pathspecs []*pathspec
+
+// ParsePathspecs parses pathspecs given relative to the repository root.
+func ParsePathspecs(pathspecs []string) (Pathspecs, error) {
+	specs := make(Pathspecs, 0, len(pathspecs))
+	for _, original := range pathspecs {
+		spec, err := newPathspec(original, scpath.RelativePath(original))
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// Match reports whether any of the pathspecs selects path, or a directory
+// that contains it. An empty list selects every path.
+func (p Pathspecs) Match(path string) bool {
+	return matchAny(p, path)
+}
+
+// Unmatched returns, as they were given, the pathspecs that select none of
+// paths.
+func (p Pathspecs) Unmatched(paths []string) []string {
+	var unmatched []string
+	for _, spec := range p {
+		if !slices.ContainsFunc(paths, spec.matches) {
+			unmatched = append(unmatched, spec.original)
+		}
+	}
+	return unmatched
+}
+
+// matchAny reports whether any of specs selects path; no pathspecs select
+// everything.
+func matchAny(specs []*pathspec, path string) bool {
+	if len(specs) == 0 {
+		return true
+	}
+	for _, spec := range specs {
+		if spec.matches(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// isGlob reports whether s contains glob wildcards.
+func isGlob(s string) bool {
+	return strings.ContainsAny(s, "*?[")
+}
+
+// compileGlob converts a glob to a regular expression matching the paths
+// it selects and every path below them.
+func compileGlob(glob string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if rest, negated := strings.CutPrefix(class, "!"); negated {
+				class = "^" + rest
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+			}
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("(/|$)")
+	return regexp.Compile(b.String())
+}
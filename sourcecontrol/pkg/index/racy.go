@@ -0,0 +1,157 @@
+package index
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+)
+
+// Racy entries
+//
+// An entry's stat data says a file is unchanged if its size and mtime are
+// what they were when it was staged. Timestamps have limited precision, so
+// a file changed again within the same second as it was staged, without
+// changing size, looks unchanged. Such an entry is "racily clean", and, as
+// in Git, it is recognized by comparing its mtime with the index file's:
+// only an entry whose file was modified at or after the index was written
+// can be racy, and its content is hashed rather than trusted.
+//
+// A racy entry stops looking racy once the index is written again, later.
+// So before writing, each racy entry whose file has in fact changed is
+// smudged: its recorded size is set to zero, which no longer matches the
+// file and so forces the content to be compared next time.
+
+// IsRacy reports whether entry's stat data cannot be trusted, because its
+// file was modified no earlier than the index was last written. An index
+// that was not read from disk has no entries that are racy.
+func (idx *Index) IsRacy(entry *Entry) bool {
+	if idx.Timestamp.IsZero() {
+		return false
+	}
+	return int64(entry.ModificationTime.Seconds) >= idx.Timestamp.Unix()
+}
+
+// MatchesStat reports whether the working tree file described by info is
+// known to be unchanged since entry was staged from its stat data alone.
+// When it returns false the file may or may not have changed, and its
+// content has to be compared with the entry's blob. It only reads entry and
+// the index timestamp, so it is safe to call while other entries change.
+func (idx *Index) MatchesStat(entry *Entry, info os.FileInfo) bool {
+	if entry.IsModified(info) || idx.IsRacy(entry) {
+		return false
+	}
+	// A smudged entry records size zero; only an empty blob really has it
+	return entry.SizeInBytes != 0 || entry.BlobHash == entry.BlobHash.Algorithm().HashObject(objects.BlobType, nil)
+}
+
+// smudgeRacyEntries smudges each racy entry whose file under root has
+// changed since it was staged, so that the change is still seen once the
+// index is rewritten with a later timestamp.
+func (idx *Index) smudgeRacyEntries(root scpath.RepositoryPath) {
+	for _, entry := range idx.Entries {
+		if entry.Stage != 0 || !idx.IsRacy(entry) {
+			continue
+		}
+		absPath := root.Join(entry.Path.String())
+		info, err := os.Lstat(absPath.String())
+		if err != nil || entry.IsModified(info) {
+			continue
+		}
+		if hash, err := HashWorktree(absPath, info, idx.hashAlgorithm()); err != nil || hash != entry.BlobHash {
+			entry.SizeInBytes = 0
+		}
+	}
+}
+
+// indexTimestamp returns the modification time of the index file at path,
+// or the zero time if there is none.
+func indexTimestamp(path scpath.AbsolutePath) time.Time {
+	info, err := os.Stat(path.String())
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// worktreeRoot returns the working tree of the index at path. The index
+// lives directly in the .git directory, which is at the top of the working
+// tree.
+func worktreeRoot(path scpath.AbsolutePath) scpath.RepositoryPath {
+	return scpath.RepositoryPath(filepath.Dir(filepath.Dir(path.String())))
+}
+
+// HashWorktree computes the blob hash of the working tree file at absPath,
+// whose Lstat result is info: its content, or for a symbolic link the path
+// it points to. Nothing is written to the object store.
+func HashWorktree(absPath scpath.AbsolutePath, info os.FileInfo, algorithm objects.HashAlgorithm) (objects.ObjectHash, error) {
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(absPath.String())
+		if err != nil {
+			return "", err
+		}
+		return algorithm.HashObject(objects.BlobType, []byte(filepath.ToSlash(target))), nil
+	}
+	return hashBlob(absPath, info, algorithm)
+}
+
+// RefreshResult reports what Refresh found.
+type RefreshResult struct {
+	Refreshed   []string // Entries whose stat data was brought up to date
+	NeedsUpdate []string // Files that changed or are missing from the working tree
+	NeedsMerge  []string // Paths with conflict stages
+}
+
+// Refresh brings the stat data of the index up to date with the working
+// tree at root, like update-index --refresh. An entry whose stat data does
+// not match its file, or is racy, has the file hashed: if the content and
+// mode are unchanged the entry takes the file's current stat data, so that
+// later checks can trust it without reading the file once the index is
+// written; otherwise the path needs updating and is left alone. Nothing is
+// staged.
+//
+// Entries marked assume-valid and intent-to-add entries are skipped.
+func (idx *Index) Refresh(root scpath.RepositoryPath) *RefreshResult {
+	result := &RefreshResult{
+		Refreshed:   make([]string, 0),
+		NeedsUpdate: make([]string, 0),
+		NeedsMerge:  make([]string, 0),
+	}
+
+	modes := LoadModeSettings(root)
+	for _, entry := range idx.Entries {
+		path := entry.Path.String()
+		if entry.Stage != 0 {
+			if n := len(result.NeedsMerge); n == 0 || result.NeedsMerge[n-1] != path {
+				result.NeedsMerge = append(result.NeedsMerge, path)
+			}
+			continue
+		}
+		if entry.AssumeValid || entry.IntentToAdd || entry.TreeMode().IsGitlink() {
+			continue
+		}
+
+		absPath := root.Join(path)
+		info, err := os.Lstat(absPath.String())
+		if err != nil || info.IsDir() {
+			result.NeedsUpdate = append(result.NeedsUpdate, path)
+			continue
+		}
+		if idx.MatchesStat(entry, info) && modes.WorktreeMode(info, entry) == entry.TreeMode() {
+			continue
+		}
+
+		hash, err := HashWorktree(absPath, info, idx.hashAlgorithm())
+		if err != nil || hash != entry.BlobHash || modes.WorktreeMode(info, entry) != entry.TreeMode() {
+			result.NeedsUpdate = append(result.NeedsUpdate, path)
+			continue
+		}
+		if entry.IsModified(info) {
+			entry.updateStat(info)
+			result.Refreshed = append(result.Refreshed, path)
+		}
+	}
+	return result
+}
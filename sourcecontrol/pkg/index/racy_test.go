@@ -0,0 +1,166 @@
+package index
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+	"github.com/utkarsh5026/SourceControl/pkg/store"
+)
+
+// setMtime sets the modification time of the file at path.
+func setMtime(t *testing.T, path string, mtime time.Time) {
+	t.Helper()
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// setupRacyTest stages README.md and src/main.go with an mtime of stamp,
+// then rewrites README.md with content of the same size and the same mtime,
+// so that only its content tells that it changed.
+func setupRacyTest(t *testing.T, stamp time.Time) (*Manager, scpath.RepositoryPath, scpath.AbsolutePath) {
+	t.Helper()
+	root, indexPath := setupLockTestRepo(t)
+	writeWorktree(t, root, map[string]string{"README.md": "readme", "src/main.go": "package main"})
+	readme := filepath.Join(root.String(), "README.md")
+	setMtime(t, readme, stamp)
+	setMtime(t, filepath.Join(root.String(), "src", "main.go"), stamp)
+
+	m := NewManager(root)
+	if _, err := m.Add([]string{"README.md", "src/main.go"}, store.NewMemoryObjectStore()); err != nil {
+		t.Fatal(err)
+	}
+	writeWorktree(t, root, map[string]string{"README.md": "README"})
+	setMtime(t, readme, stamp)
+	return m, root, indexPath
+}
+
+func TestIndex_IsRacy(t *testing.T) {
+	stamp := time.Now().Add(-time.Hour).Truncate(time.Second)
+	m, root, indexPath := setupRacyTest(t, stamp)
+
+	// An index written in the same second as the file was modified
+	setMtime(t, indexPath.String(), stamp)
+	idx, err := Read(indexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, _ := idx.Get("README.md")
+	info, err := os.Lstat(filepath.Join(root.String(), "README.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !idx.IsRacy(entry) {
+		t.Error("IsRacy() = false for an entry modified when the index was written")
+	}
+	if idx.MatchesStat(entry, info) {
+		t.Error("MatchesStat() = true for a racy entry")
+	}
+	if err := m.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	result, err := m.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"README.md": "AM", "src/main.go": "A "}; !reflect.DeepEqual(statusCodes(result), want) {
+		t.Errorf("status = %v, want %v", statusCodes(result), want)
+	}
+
+	// An index written later trusts the stat data
+	setMtime(t, indexPath.String(), stamp.Add(10*time.Second))
+	if idx, err = Read(indexPath); err != nil {
+		t.Fatal(err)
+	}
+	entry, _ = idx.Get("README.md")
+	if idx.IsRacy(entry) {
+		t.Error("IsRacy() = true for an entry modified before the index was written")
+	}
+	if !idx.MatchesStat(entry, info) {
+		t.Error("MatchesStat() = false for an entry whose stat data matches")
+	}
+	if (&Index{}).IsRacy(entry) {
+		t.Error("IsRacy() = true for an index not read from disk")
+	}
+}
+
+func TestLock_CommitSmudgesRacyEntries(t *testing.T) {
+	stamp := time.Now().Truncate(time.Second)
+	m, root, indexPath := setupRacyTest(t, stamp)
+
+	// Rewrite the index while README.md is still racy
+	writeWorktree(t, root, map[string]string{"new.txt": "new"})
+	if _, err := m.Add([]string{"new.txt"}, store.NewMemoryObjectStore()); err != nil {
+		t.Fatal(err)
+	}
+	idx, err := Read(indexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry, _ := idx.Get("README.md"); entry.SizeInBytes != 0 {
+		t.Errorf("README.md size = %d, want 0 (smudged)", entry.SizeInBytes)
+	}
+	if entry, _ := idx.Get("src/main.go"); entry.SizeInBytes != uint32(len("package main")) {
+		t.Errorf("src/main.go size = %d, want it kept", entry.SizeInBytes)
+	}
+
+	// Once the index is no longer racy, the smudged entry is still compared
+	setMtime(t, indexPath.String(), stamp.Add(10*time.Second))
+	if err := m.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	result, err := m.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"README.md": "AM", "new.txt": "A ", "src/main.go": "A "}
+	if !reflect.DeepEqual(statusCodes(result), want) {
+		t.Errorf("status = %v, want %v", statusCodes(result), want)
+	}
+}
+
+func TestIndex_Refresh(t *testing.T) {
+	stamp := time.Now().Add(-time.Hour).Truncate(time.Second)
+	_, root, indexPath := setupRacyTest(t, stamp)
+	writeWorktree(t, root, map[string]string{"README.md": "readme", "gone.txt": "gone"})
+	if _, err := NewManager(root).Add([]string{"gone.txt"}, store.NewMemoryObjectStore()); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(root.String(), "gone.txt")); err != nil {
+		t.Fatal(err)
+	}
+	// README.md is back to its staged content, but with a new mtime
+	later := stamp.Add(time.Minute)
+	setMtime(t, filepath.Join(root.String(), "README.md"), later)
+	writeWorktree(t, root, map[string]string{"src/main.go": "package util"})
+
+	var result *RefreshResult
+	err := Update(indexPath, func(idx *Index) error {
+		result = idx.Refresh(root)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Update() failed: %v", err)
+	}
+	if want := []string{"README.md"}; !reflect.DeepEqual(result.Refreshed, want) {
+		t.Errorf("Refreshed = %v, want %v", result.Refreshed, want)
+	}
+	if want := []string{"gone.txt", "src/main.go"}; !reflect.DeepEqual(result.NeedsUpdate, want) {
+		t.Errorf("NeedsUpdate = %v, want %v", result.NeedsUpdate, want)
+	}
+
+	idx, err := Read(indexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry, _ := idx.Get("README.md"); int64(entry.ModificationTime.Seconds) != later.Unix() {
+		t.Errorf("README.md mtime = %d, want %d", entry.ModificationTime.Seconds, later.Unix())
+	}
+	if entry, _ := idx.Get("src/main.go"); int64(entry.ModificationTime.Seconds) != stamp.Unix() {
+		t.Errorf("src/main.go mtime = %d, want it left alone", entry.ModificationTime.Seconds)
+	}
+}
@@ -0,0 +1,234 @@
+package index
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+)
+
+// ErrNoPathspec is returned by RemoveWithOptions when it is given no
+// pathspecs.
+var ErrNoPathspec = errors.New("no pathspec was given, which files should be removed?")
+
+// Reasons a path cannot be removed, reported in RemoveFailureResult.Reason.
+const (
+	ReasonUnmatched      = "pathspec did not match any files"
+	ReasonNotRecursive   = "not removing directory recursively without -r"
+	ReasonLocalChanges   = "has local modifications"
+	ReasonStagedChanges  = "has changes staged in the index"
+	ReasonStagedAndLocal = "has staged content different from both the file and the HEAD"
+)
+
+// RemoveOptions configures RemoveWithOptions.
+type RemoveOptions struct {
+	// Cached removes paths from the index only, keeping the working tree
+	// files (rm --cached)
+	Cached bool
+
+	// Recursive allows a pathspec naming a directory to remove everything
+	// tracked below it (rm -r)
+	Recursive bool
+
+	// Force skips the checks that protect changes not yet committed
+	// (rm -f)
+	Force bool
+
+	// DryRun reports what would be removed without changing anything
+	// (rm --dry-run)
+	DryRun bool
+
+	// Head holds the entries of the tree HEAD points to, keyed by path, as
+	// the safety checks compare the index against it; nil or empty on an
+	// unborn branch
+	Head map[string]*Entry
+}
+
+// RemoveWithOptions removes the tracked files that pathspecs select from
+// the index and, unless opts.Cached is set, from the working tree, like git
+// rm. Directories left empty in the working tree are removed too.
+//
+// Nothing is removed if any pathspec fails to match a tracked file, names a
+// directory without opts.Recursive, or, unless opts.Force is set, selects a
+// file whose changes would be lost: one whose working tree copy differs
+// from the index or whose staged content differs from HEAD. With
+// opts.Cached the working tree copy is kept, so only a file that differs
+// from both is refused. Each such path is reported in RemoveResult.Failed.
+//
+// Parameters:
+//   - pathspecs: Files, directories or globs, relative to the repository root
+//   - opts: Where to remove files from, and which checks to make
+//
+// Returns:
+//   - *RemoveResult: The paths removed, or the reasons nothing was
+//   - error: ErrNoPathspec, or if the index cannot be locked or saved
+func (m *Manager) RemoveWithOptions(pathspecs []string, opts RemoveOptions) (*RemoveResult, error) {
+	if len(pathspecs) == 0 {
+		return nil, ErrNoPathspec
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var lock *Lock
+	if opts.DryRun {
+		index, err := Read(m.indexPath.ToAbsolutePath())
+		if err != nil {
+			return nil, fmt.Errorf("failed to load index: %w", err)
+		}
+		m.index = index
+	} else {
+		var err error
+		if lock, err = m.lockIndex(); err != nil {
+			return nil, err
+		}
+		defer lock.Rollback()
+	}
+
+	result := &RemoveResult{
+		Removed: make([]string, 0),
+		Failed:  make([]RemoveFailureResult, 0),
+	}
+
+	paths := m.selectRemovals(pathspecs, opts.Recursive, result)
+	if !opts.Force && len(result.Failed) == 0 {
+		for _, path := range paths {
+			if reason := m.checkRemoval(path, opts); reason != "" {
+				result.Failed = append(result.Failed, RemoveFailureResult{Path: path.String(), Reason: reason})
+			}
+		}
+	}
+	if len(result.Failed) > 0 || opts.DryRun {
+		if len(result.Failed) == 0 {
+			for _, path := range paths {
+				result.Removed = append(result.Removed, path.String())
+			}
+		}
+		return result, nil
+	}
+
+	for _, path := range paths {
+		m.index.Remove(path)
+		m.index.RemoveConflict(path)
+	}
+	if !opts.Cached {
+		for _, path := range paths {
+			if err := m.removeWorktreeFile(path); err != nil {
+				return result, fmt.Errorf("unable to remove '%s': %w", path, err)
+			}
+		}
+	}
+	for _, path := range paths {
+		result.Removed = append(result.Removed, path.String())
+	}
+
+	if err := lock.Commit(m.index); err != nil {
+		return result, fmt.Errorf("failed to save index: %w", err)
+	}
+	return result, nil
+}
+
+// selectRemovals returns the sorted, tracked paths that pathspecs select,
+// conflicted ones included, reporting in result each pathspec that cannot
+// be used.
+func (m *Manager) selectRemovals(pathspecs []string, recursive bool, result *RemoveResult) []scpath.RelativePath {
+	tracked := make([]scpath.RelativePath, 0, len(m.index.Entries))
+	for _, entry := range m.index.Entries {
+		tracked = append(tracked, entry.Path)
+	}
+	tracked = uniquePaths(tracked)
+
+	selected := make(map[scpath.RelativePath]bool)
+	for _, original := range pathspecs {
+		spec, err := m.parsePathspec(original)
+		if err != nil {
+			result.Failed = append(result.Failed, RemoveFailureResult{Path: original, Reason: err.Error()})
+			continue
+		}
+
+		reason := ReasonUnmatched
+		for _, path := range tracked {
+			if !spec.matches(path.String()) {
+				continue
+			}
+			if spec.glob == nil && path.String() != spec.path && !recursive {
+				reason, spec.matched = ReasonNotRecursive, false
+				break
+			}
+			spec.matched = true
+			selected[path] = true
+		}
+		if !spec.matched {
+			result.Failed = append(result.Failed, RemoveFailureResult{Path: original, Reason: reason})
+		}
+	}
+
+	paths := make([]scpath.RelativePath, 0, len(selected))
+	for path := range selected {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool { return paths[i] < paths[j] })
+	return paths
+}
+
+// checkRemoval returns why removing path would lose changes, or "" if it
+// would not. Conflicted paths are always removable.
+func (m *Manager) checkRemoval(path scpath.RelativePath, opts RemoveOptions) string {
+	entry, ok := m.index.Get(path)
+	if !ok || m.index.IsConflicted(path) {
+		return ""
+	}
+
+	head, inHead := opts.Head[path.String()]
+	stagedChanges := !inHead || head.BlobHash != entry.BlobHash || head.TreeMode() != entry.TreeMode()
+	localChanges := m.hasLocalChanges(entry)
+
+	switch {
+	case localChanges && stagedChanges:
+		if !opts.Cached || !entry.IntentToAdd {
+			return ReasonStagedAndLocal
+		}
+	case opts.Cached:
+	case stagedChanges:
+		return ReasonStagedChanges
+	case localChanges:
+		return ReasonLocalChanges
+	}
+	return ""
+}
+
+// hasLocalChanges reports whether the working tree copy of entry's file
+// differs from the staged content. A missing file has none to lose.
+func (m *Manager) hasLocalChanges(entry *Entry) bool {
+	absPath := m.repoRoot.Join(entry.Path.String())
+	info, err := os.Lstat(absPath.String())
+	if err != nil || info.IsDir() {
+		return false
+	}
+	if entry.IntentToAdd {
+		return true
+	}
+	if m.index.MatchesStat(entry, info) {
+		return false
+	}
+
+	hash, err := HashWorktree(absPath, info, m.index.hashAlgorithm())
+	return err != nil || hash != entry.BlobHash
+}
+
+// removeWorktreeFile deletes path from the working tree, if it is there,
+// and then each parent directory it leaves empty.
+func (m *Manager) removeWorktreeFile(relPath scpath.RelativePath) error {
+	if err := os.Remove(m.repoRoot.Join(relPath.String()).String()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for dir := path.Dir(relPath.String()); dir != "."; dir = path.Dir(dir) {
+		if os.Remove(m.repoRoot.Join(dir).String()) != nil {
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package index
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+)
+
+// setupRemoveTest stages a few files and returns the index as HEAD, as if
+// they had just been committed.
+func setupRemoveTest(t *testing.T) (*Manager, scpath.RepositoryPath, scpath.AbsolutePath, map[string]*Entry) {
+	t.Helper()
+	m, root, indexPath, objectStore := setupAddTest(t)
+	if _, err := m.Add([]string{"README.md", "src"}, objectStore); err != nil {
+		t.Fatal(err)
+	}
+
+	idx, err := Read(indexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head := make(map[string]*Entry)
+	for _, entry := range idx.Entries {
+		head[entry.Path.String()] = entry
+	}
+	return m, root, indexPath, head
+}
+
+func TestManager_RemoveWithOptions(t *testing.T) {
+	m, root, indexPath, head := setupRemoveTest(t)
+
+	result, err := m.RemoveWithOptions([]string{"src"}, RemoveOptions{Recursive: true, Head: head})
+	if err != nil {
+		t.Fatalf("RemoveWithOptions() failed: %v", err)
+	}
+
+	if want := []string{"src/main.go", "src/util/util.go"}; !reflect.DeepEqual(result.Removed, want) {
+		t.Errorf("Removed = %v, want %v", result.Removed, want)
+	}
+	if want := []string{"README.md"}; !reflect.DeepEqual(stagedPaths(t, indexPath), want) {
+		t.Errorf("staged = %v, want %v", stagedPaths(t, indexPath), want)
+	}
+	if _, err := os.Stat(filepath.Join(root.String(), "src", "util")); !os.IsNotExist(err) {
+		t.Error("src/util should be removed once empty")
+	}
+	if _, err := os.Stat(filepath.Join(root.String(), "src", "debug.log")); err != nil {
+		t.Error("untracked src/debug.log should be kept")
+	}
+}
+
+func TestManager_RemoveRefusals(t *testing.T) {
+	m, root, indexPath, head := setupRemoveTest(t)
+	before := stagedPaths(t, indexPath)
+
+	tests := []struct {
+		name      string
+		pathspecs []string
+		opts      RemoveOptions
+		want      RemoveFailureResult
+	}{
+		{"unmatched", []string{"README.md", "missing.txt"}, RemoveOptions{}, RemoveFailureResult{"missing.txt", ReasonUnmatched}},
+		{"directory without -r", []string{"src"}, RemoveOptions{}, RemoveFailureResult{"src", ReasonNotRecursive}},
+		{"local changes", []string{"src/main.go"}, RemoveOptions{}, RemoveFailureResult{"src/main.go", ReasonLocalChanges}},
+		{"staged changes", []string{"README.md"}, RemoveOptions{}, RemoveFailureResult{"README.md", ReasonStagedChanges}},
+	}
+
+	writeWorktree(t, root, map[string]string{"src/main.go": "package main // edited"})
+	headWithOldReadme := make(map[string]*Entry, len(head))
+	for path, entry := range head {
+		headWithOldReadme[path] = entry
+	}
+	old := *head["README.md"]
+	old.BlobHash = objects.ObjectHash(createTestHash("old readme"))
+	headWithOldReadme["README.md"] = &old
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Head = headWithOldReadme
+			result, err := m.RemoveWithOptions(tt.pathspecs, tt.opts)
+			if err != nil {
+				t.Fatalf("RemoveWithOptions() failed: %v", err)
+			}
+			if !reflect.DeepEqual(result.Failed, []RemoveFailureResult{tt.want}) {
+				t.Errorf("Failed = %v, want %v", result.Failed, tt.want)
+			}
+			if len(result.Removed) != 0 {
+				t.Errorf("Removed = %v, want nothing", result.Removed)
+			}
+			if got := stagedPaths(t, indexPath); !reflect.DeepEqual(got, before) {
+				t.Errorf("staged = %v, want %v", got, before)
+			}
+		})
+	}
+
+	// Both kinds of change at once are refused even with --cached
+	writeWorktree(t, root, map[string]string{"README.md": "edited readme"})
+	result, err := m.RemoveWithOptions([]string{"README.md"}, RemoveOptions{Cached: true, Head: headWithOldReadme})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Failed) != 1 || result.Failed[0].Reason != ReasonStagedAndLocal {
+		t.Errorf("Failed = %v, want %q", result.Failed, ReasonStagedAndLocal)
+	}
+
+	// --force removes them anyway
+	result, err = m.RemoveWithOptions([]string{"README.md", "src/main.go"}, RemoveOptions{Force: true, Head: headWithOldReadme})
+	if err != nil || len(result.Removed) != 2 {
+		t.Fatalf("RemoveWithOptions(Force) = %+v, %v", result, err)
+	}
+	if want := []string{"src/util/util.go"}; !reflect.DeepEqual(stagedPaths(t, indexPath), want) {
+		t.Errorf("staged = %v, want %v", stagedPaths(t, indexPath), want)
+	}
+}
+
+func TestManager_RemoveCachedAndDryRun(t *testing.T) {
+	m, root, indexPath, head := setupRemoveTest(t)
+
+	// --cached keeps locally modified files, which remain in the working tree
+	writeWorktree(t, root, map[string]string{"src/main.go": "package main // edited"})
+	result, err := m.RemoveWithOptions([]string{"*.go"}, RemoveOptions{Cached: true, DryRun: true, Head: head})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"src/main.go", "src/util/util.go"}; !reflect.DeepEqual(result.Removed, want) {
+		t.Errorf("dry run Removed = %v, want %v", result.Removed, want)
+	}
+	if got := stagedPaths(t, indexPath); len(got) != 3 {
+		t.Errorf("dry run changed the index: %v", got)
+	}
+
+	if _, err := m.RemoveWithOptions([]string{"*.go"}, RemoveOptions{Cached: true, Head: head}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"README.md"}; !reflect.DeepEqual(stagedPaths(t, indexPath), want) {
+		t.Errorf("staged = %v, want %v", stagedPaths(t, indexPath), want)
+	}
+	if _, err := os.Stat(filepath.Join(root.String(), "src", "main.go")); err != nil {
+		t.Error("--cached should keep the working tree file")
+	}
+
+	if _, err := m.RemoveWithOptions(nil, RemoveOptions{}); !errors.Is(err, ErrNoPathspec) {
+		t.Errorf("RemoveWithOptions(nil) error = %v, want ErrNoPathspec", err)
+	}
+}
@@ -0,0 +1,451 @@
+package index
+
+import (
+	"context"
+	"os"
+	"path"
+	"sort"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/ignore"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+)
+
+// Status codes of a StatusEntry, as git status --short prints them.
+const (
+	StatusUnmodified  byte = ' '
+	StatusModified    byte = 'M'
+	StatusTypeChanged byte = 'T'
+	StatusAdded       byte = 'A'
+	StatusDeleted     byte = 'D'
+	StatusUnmerged    byte = 'U'
+)
+
+// UntrackedMode selects how StatusWithOptions reports untracked files.
+type UntrackedMode int
+
+const (
+	// UntrackedNormal lists untracked files, showing a directory that holds
+	// no tracked files as the directory alone (-unormal, the default)
+	UntrackedNormal UntrackedMode = iota
+
+	// UntrackedNo lists no untracked or ignored files (-uno)
+	UntrackedNo
+
+	// UntrackedAll lists every untracked file, inside untracked
+	// directories too (-uall)
+	UntrackedAll
+)
+
+// StatusOptions configures StatusWithOptions.
+type StatusOptions struct {
+	// Head holds the entries of the tree HEAD points to, keyed by path, that
+	// the index is compared with; nil or empty on an unborn branch
+	Head map[string]*Entry
+
+	// Untracked selects how untracked files are reported
+	Untracked UntrackedMode
+
+	// Ignored also lists ignored files (--ignored)
+	Ignored bool
+}
+
+// StatusResult represents the repository status.
+type StatusResult struct {
+	Staged    StagedChanges
+	Unstaged  UnstagedChanges
+	Unmerged  []string // Paths with conflict stages in the index
+	Untracked []string // Untracked files; directories end in '/'
+	Ignored   []string // Ignored files, if asked for; directories end in '/'
+
+	// Entries holds every tracked path that differs between HEAD, the index
+	// and the working tree, sorted by path
+	Entries []StatusEntry
+}
+
+// StagedChanges represents changes that are staged (in index but differ from HEAD).
+type StagedChanges struct {
+	Added    []string // New files in index (not in HEAD)
+	Modified []string // Files modified in index (different from HEAD)
+	Deleted  []string // Files deleted from index (present in HEAD)
+}
+
+// UnstagedChanges represents changes in working directory (differ from index).
+type UnstagedChanges struct {
+	Modified []string // Files modified in working dir (different from index)
+	Deleted  []string // Files deleted from working dir (present in index)
+}
+
+// StatusEntry is the state of one path in HEAD, the index and the working
+// tree. Modes are tree modes, zero where the path is missing, and hashes are
+// empty where it is missing.
+type StatusEntry struct {
+	Path string
+
+	// Staged is how the index differs from HEAD and Unstaged how the
+	// working tree differs from the index, as status codes. For an unmerged
+	// path they say which side added, deleted or modified it, as in "UU" or
+	// "AA".
+	Staged   byte
+	Unstaged byte
+
+	HeadMode     FileMode
+	IndexMode    FileMode
+	WorktreeMode FileMode
+	HeadHash     objects.ObjectHash
+	IndexHash    objects.ObjectHash
+
+	// Stages holds the base, ours and theirs entries of an unmerged path;
+	// nil for a side that does not have it
+	Stages [3]*Entry
+}
+
+// IsUnmerged reports whether the path has conflict stages in the index.
+func (e StatusEntry) IsUnmerged() bool {
+	return e.Stages[0] != nil || e.Stages[1] != nil || e.Stages[2] != nil
+}
+
+// Status returns the current repository status (like git status), with
+// every index entry counted as staged. It is StatusWithOptions with the
+// default options.
+func (m *Manager) Status() (*StatusResult, error) {
+	return m.StatusWithOptions(StatusOptions{})
+}
+
+// StatusWithOptions compares HEAD, the index and the working tree, like git
+// status. Each tracked path that differs is reported in Entries and in the
+// Staged, Unstaged and Unmerged lists; untracked and ignored files are
+// found by walking the working tree with the repository's ignore rules.
+//
+// A working tree file is only read when its stat data does not show it to
+// be unchanged, or its entry is racy (see Index.IsRacy).
+//
+// Parameters:
+//   - opts: The HEAD entries, and which untracked and ignored files to list
+//
+// Returns:
+//   - *StatusResult: The differences, with every list sorted by path
+//   - error: Never returned at present; files that cannot be read count as
+//     modified
+func (m *Manager) StatusWithOptions(opts StatusOptions) (*StatusResult, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := &StatusResult{
+		Staged: StagedChanges{
+			Added:    make([]string, 0),
+			Modified: make([]string, 0),
+			Deleted:  make([]string, 0),
+		},
+		Unstaged: UnstagedChanges{
+			Modified: make([]string, 0),
+			Deleted:  make([]string, 0),
+		},
+		Unmerged:  make([]string, 0),
+		Untracked: make([]string, 0),
+		Ignored:   make([]string, 0),
+		Entries:   make([]StatusEntry, 0),
+	}
+
+	for _, entry := range m.trackedStatus(opts.Head) {
+		result.add(entry)
+	}
+
+	if opts.Untracked != UntrackedNo {
+		scan := &untrackedScan{
+			m:          m,
+			matcher:    ignore.NewMatcher(m.repoRoot),
+			opts:       opts,
+			dirs:       m.trackedDirectories(),
+			conflicted: m.index.GetConflicts(),
+			result:     result,
+		}
+		scan.walk("", false, opts.Untracked == UntrackedAll)
+		sort.Strings(result.Untracked)
+		sort.Strings(result.Ignored)
+	}
+	return result, nil
+}
+
+// add records entry in Entries and in the lists it belongs to.
+func (r *StatusResult) add(entry StatusEntry) {
+	r.Entries = append(r.Entries, entry)
+	if entry.IsUnmerged() {
+		r.Unmerged = append(r.Unmerged, entry.Path)
+		return
+	}
+
+	switch entry.Staged {
+	case StatusAdded:
+		r.Staged.Added = append(r.Staged.Added, entry.Path)
+	case StatusModified, StatusTypeChanged:
+		r.Staged.Modified = append(r.Staged.Modified, entry.Path)
+	case StatusDeleted:
+		r.Staged.Deleted = append(r.Staged.Deleted, entry.Path)
+	}
+	switch entry.Unstaged {
+	case StatusModified, StatusTypeChanged:
+		r.Unstaged.Modified = append(r.Unstaged.Modified, entry.Path)
+	case StatusDeleted:
+		r.Unstaged.Deleted = append(r.Unstaged.Deleted, entry.Path)
+	}
+}
+
+// trackedStatus returns the status of each path in HEAD or the index that
+// differs anywhere, sorted by path. Working tree files are checked by a
+// worker pool (see core.parallelism), and the paths are collected in order
+// as their checks finish.
+func (m *Manager) trackedStatus(head map[string]*Entry) []StatusEntry {
+	byPath := make(map[string]*StatusEntry)
+	get := func(path string) *StatusEntry {
+		if e, ok := byPath[path]; ok {
+			return e
+		}
+		e := &StatusEntry{Path: path, Staged: StatusUnmodified, Unstaged: StatusUnmodified}
+		byPath[path] = e
+		return e
+	}
+
+	for _, entry := range m.index.Entries {
+		e := get(entry.Path.String())
+		if entry.Stage == 0 {
+			e.IndexMode, e.IndexHash = entry.TreeMode(), entry.BlobHash
+		} else if entry.Stage <= 3 {
+			e.Stages[entry.Stage-1] = entry
+		}
+	}
+	for path, entry := range head {
+		e := get(path)
+		e.HeadMode, e.HeadHash = entry.TreeMode(), entry.BlobHash
+	}
+
+	all := make([]*StatusEntry, 0, len(byPath))
+	for _, e := range byPath {
+		all = append(all, e)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Path < all[j].Path })
+
+	entries := make([]StatusEntry, 0)
+	modes := LoadModeSettings(m.repoRoot)
+	wp := newWorkerPool[*StatusEntry, *StatusEntry](m.repoRoot)
+	wp.ProcessOrdered(context.Background(), all, func(_ context.Context, e *StatusEntry) (*StatusEntry, error) {
+		m.pathStatus(e, modes)
+		return e, nil
+	}, func(_ int, e *StatusEntry) error {
+		if e.IsUnmerged() || e.Staged != StatusUnmodified || e.Unstaged != StatusUnmodified {
+			entries = append(entries, *e)
+		}
+		return nil
+	})
+	return entries
+}
+
+// pathStatus fills in the status codes of e, whose modes and hashes in
+// HEAD and the index are already set, checking its working tree file.
+func (m *Manager) pathStatus(e *StatusEntry, modes ModeSettings) {
+	if e.IsUnmerged() {
+		e.Staged, e.Unstaged = unmergedCodes(e.Stages)
+		e.WorktreeMode = m.worktreeMode(e.Path, nil, modes)
+		return
+	}
+
+	entry, inIndex := m.index.Get(scpath.RelativePath(e.Path))
+	switch {
+	case !inIndex:
+		e.Staged = StatusDeleted
+	case entry.IntentToAdd:
+		e.IndexMode, e.IndexHash = 0, ""
+		e.Unstaged = StatusAdded
+	case e.HeadMode == 0:
+		e.Staged = StatusAdded
+	default:
+		e.Staged = compareModes(e.HeadMode, e.IndexMode, e.HeadHash != e.IndexHash)
+	}
+	if inIndex {
+		e.WorktreeMode = m.worktreeMode(e.Path, entry, modes)
+		if !entry.IntentToAdd {
+			e.Unstaged = m.worktreeChange(entry, e.WorktreeMode)
+		}
+	}
+}
+
+// unmergedCodes returns the two-letter code of an unmerged path from which
+// of its base, ours and theirs stages exist, as git status does.
+func unmergedCodes(stages [3]*Entry) (byte, byte) {
+	switch base, ours, theirs := stages[0] != nil, stages[1] != nil, stages[2] != nil; {
+	case base && !ours && !theirs:
+		return StatusDeleted, StatusDeleted
+	case !base && ours && !theirs:
+		return StatusAdded, StatusUnmerged
+	case base && !ours && theirs:
+		return StatusDeleted, StatusUnmerged
+	case !base && !ours && theirs:
+		return StatusUnmerged, StatusAdded
+	case base && ours && !theirs:
+		return StatusUnmerged, StatusDeleted
+	case !base && ours && theirs:
+		return StatusAdded, StatusAdded
+	default:
+		return StatusUnmerged, StatusUnmerged
+	}
+}
+
+// compareModes returns the status code of a path whose mode went from old
+// to new: type changed if it became or stopped being a symbolic link or
+// submodule, modified if the content or the mode changed otherwise.
+func compareModes(old, new FileMode, contentChanged bool) byte {
+	switch {
+	case old.Type() != new.Type():
+		return StatusTypeChanged
+	case old != new || contentChanged:
+		return StatusModified
+	default:
+		return StatusUnmodified
+	}
+}
+
+// worktreeMode returns the mode the working tree file at path would be
+// staged with over entry, which may be nil, or zero if there is no file.
+// A directory is a submodule if entry is one or it is a nested repository.
+func (m *Manager) worktreeMode(path string, entry *Entry, modes ModeSettings) FileMode {
+	absPath := m.repoRoot.Join(path)
+	info, err := os.Lstat(absPath.String())
+	switch {
+	case err != nil:
+		return 0
+	case !info.IsDir():
+		return modes.WorktreeMode(info, entry)
+	case entry != nil && entry.TreeMode().IsGitlink() || isNestedRepository(absPath):
+		return FileModeGitlink
+	default:
+		return 0
+	}
+}
+
+// worktreeChange returns how the working tree file of entry, whose tree
+// mode is mode, differs from it. A submodule is modified when another
+// commit is checked out in it (new commits); its files are not looked into,
+// and one that is not checked out is unmodified.
+func (m *Manager) worktreeChange(entry *Entry, mode FileMode) byte {
+	if mode == 0 {
+		return StatusDeleted
+	}
+	if code := compareModes(entry.TreeMode(), mode, false); code != StatusUnmodified {
+		return code
+	}
+	if mode.IsGitlink() {
+		commit, err := gitlinkCommit(m.repoRoot.Join(entry.Path.String()))
+		if err == nil && commit != entry.BlobHash {
+			return StatusModified
+		}
+		return StatusUnmodified
+	}
+
+	if m.hasLocalChanges(entry) {
+		return StatusModified
+	}
+	return StatusUnmodified
+}
+
+// trackedDirectories returns every directory that holds an index entry,
+// at any depth.
+func (m *Manager) trackedDirectories() map[string]bool {
+	dirs := make(map[string]bool)
+	for _, entry := range m.index.Entries {
+		for dir := path.Dir(entry.Path.String()); dir != "." && !dirs[dir]; dir = path.Dir(dir) {
+			dirs[dir] = true
+		}
+	}
+	return dirs
+}
+
+// untrackedScan walks the working tree for StatusWithOptions, collecting
+// the untracked and ignored files.
+type untrackedScan struct {
+	m          *Manager
+	matcher    *ignore.Matcher
+	opts       StatusOptions
+	dirs       map[string]bool                        // directories holding tracked files
+	conflicted map[scpath.RelativePath]*ConflictEntry // paths with conflict stages
+	result     *StatusResult
+}
+
+// tracked reports whether rel is in the index, merged or conflicted.
+func (s *untrackedScan) tracked(rel string) bool {
+	path := scpath.RelativePath(rel)
+	return s.m.index.Has(path) || s.conflicted[path] != nil
+}
+
+// walk reports the untracked and ignored files below dir, a
+// repository-relative directory ("" for the root); everything below it
+// counts as ignored if ignored is set. Unless each is set, a directory
+// without tracked files is reported as a whole, as untracked if it holds an
+// untracked file and as ignored if it holds only ignored ones, and an
+// ignored directory is reported as a whole too. Submodules are skipped,
+// and a nested repository that is not one is an untracked directory. It
+// returns whether anything untracked was found.
+func (s *untrackedScan) walk(dir string, ignored, each bool) bool {
+	entries, err := os.ReadDir(s.m.repoRoot.Join(dir).String())
+	if err != nil {
+		return false
+	}
+
+	found := false
+	for _, d := range entries {
+		rel := path.Join(dir, d.Name())
+		if !d.IsDir() {
+			switch {
+			case s.tracked(rel):
+			case ignored || s.matcher.IsIgnored(rel, false):
+				s.ignore(rel)
+			default:
+				s.result.Untracked = append(s.result.Untracked, rel)
+				found = true
+			}
+			continue
+		}
+		if d.Name() == scpath.SourceDir {
+			continue
+		}
+
+		dirIgnored := ignored || s.matcher.IsIgnored(rel, true)
+		switch {
+		case s.tracked(rel):
+			// a submodule
+		case !dirIgnored && isNestedRepository(s.m.repoRoot.Join(rel)):
+			s.result.Untracked = append(s.result.Untracked, rel+"/")
+			found = true
+		case s.dirs[rel]:
+			found = s.walk(rel, dirIgnored, each) || found
+		case dirIgnored && !s.opts.Ignored:
+		case dirIgnored && !each:
+			s.ignore(rel + "/")
+		case dirIgnored || each:
+			found = s.walk(rel, dirIgnored, each) || found
+		default:
+			found = s.collapse(rel) || found
+		}
+	}
+	return found
+}
+
+// collapse reports the untracked directory rel as a whole: as untracked if
+// it holds an untracked file, alongside any ignored files in it, and as
+// ignored if it holds only ignored ones.
+func (s *untrackedScan) collapse(rel string) bool {
+	untracked, ignored := len(s.result.Untracked), len(s.result.Ignored)
+	if s.walk(rel, false, false) {
+		s.result.Untracked = append(s.result.Untracked[:untracked], rel+"/")
+		return true
+	}
+	if len(s.result.Ignored) > ignored {
+		s.result.Ignored = append(s.result.Ignored[:ignored], rel+"/")
+	}
+	return false
+}
+
+func (s *untrackedScan) ignore(rel string) {
+	if s.opts.Ignored {
+		s.result.Ignored = append(s.result.Ignored, rel)
+	}
+}
@@ -0,0 +1,166 @@
+package index
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/store"
+)
+
+// statusCodes returns the two-letter status code of each path in result.
+func statusCodes(result *StatusResult) map[string]string {
+	codes := make(map[string]string)
+	for _, entry := range result.Entries {
+		codes[entry.Path] = string([]byte{entry.Staged, entry.Unstaged})
+	}
+	return codes
+}
+
+func TestManager_StatusWithOptions(t *testing.T) {
+	m, root, _, head := setupRemoveTest(t)
+
+	result, err := m.StatusWithOptions(StatusOptions{Head: head})
+	if err != nil {
+		t.Fatalf("StatusWithOptions() failed: %v", err)
+	}
+	if len(result.Entries) != 0 {
+		t.Errorf("Entries = %v, want none", statusCodes(result))
+	}
+	if want := []string{".gitignore"}; !reflect.DeepEqual(result.Untracked, want) {
+		t.Errorf("Untracked = %v, want %v", result.Untracked, want)
+	}
+
+	// Stage a new file and a deletion, then change the working tree
+	writeWorktree(t, root, map[string]string{"new.txt": "new"})
+	if _, err := m.Add([]string{"new.txt"}, store.NewMemoryObjectStore()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.RemoveWithOptions([]string{"src/main.go"}, RemoveOptions{Cached: true, Head: head}); err != nil {
+		t.Fatal(err)
+	}
+	writeWorktree(t, root, map[string]string{"README.md": "changed", "new.txt": "newer"})
+	if err := os.Remove(filepath.Join(root.String(), "src", "util", "util.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	result, err = m.StatusWithOptions(StatusOptions{Head: head})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"README.md":        " M",
+		"new.txt":          "AM",
+		"src/main.go":      "D ",
+		"src/util/util.go": " D",
+	}
+	if got := statusCodes(result); !reflect.DeepEqual(got, want) {
+		t.Errorf("codes = %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(result.Staged, StagedChanges{Added: []string{"new.txt"}, Modified: []string{}, Deleted: []string{"src/main.go"}}) {
+		t.Errorf("Staged = %+v", result.Staged)
+	}
+	if !reflect.DeepEqual(result.Unstaged, UnstagedChanges{Modified: []string{"README.md", "new.txt"}, Deleted: []string{"src/util/util.go"}}) {
+		t.Errorf("Unstaged = %+v", result.Unstaged)
+	}
+
+	entry := result.Entries[0]
+	if entry.HeadHash != head["README.md"].BlobHash || entry.IndexHash != entry.HeadHash || entry.WorktreeMode != objects.FileModeRegular {
+		t.Errorf("README.md entry = %+v", entry)
+	}
+	if want := []string{".gitignore", "src/main.go"}; !reflect.DeepEqual(result.Untracked, want) {
+		t.Errorf("Untracked = %v, want %v", result.Untracked, want)
+	}
+}
+
+func TestManager_StatusUnmerged(t *testing.T) {
+	m, _, indexPath, head := setupRemoveTest(t)
+
+	idx, err := Read(indexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := objects.ObjectHash(createTestHash("base"))
+	ours := objects.ObjectHash(createTestHash("ours"))
+	theirs := objects.ObjectHash(createTestHash("theirs"))
+	idx.AddConflict("README.md", base, ours, theirs)
+	idx.AddConflict("src/main.go", base, ours, objects.ZeroHash())
+	idx.AddConflict("added.txt", objects.ZeroHash(), ours, theirs)
+	if err := idx.Write(indexPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := m.StatusWithOptions(StatusOptions{Head: head, Untracked: UntrackedNo})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"README.md": "UU", "added.txt": "AA", "src/main.go": "UD"}
+	if got := statusCodes(result); !reflect.DeepEqual(got, want) {
+		t.Errorf("codes = %v, want %v", got, want)
+	}
+	if want := []string{"README.md", "added.txt", "src/main.go"}; !reflect.DeepEqual(result.Unmerged, want) {
+		t.Errorf("Unmerged = %v, want %v", result.Unmerged, want)
+	}
+	if len(result.Staged.Deleted)+len(result.Unstaged.Modified) != 0 {
+		t.Errorf("unmerged paths should only be listed as unmerged: %+v %+v", result.Staged, result.Unstaged)
+	}
+	if len(result.Untracked) != 0 {
+		t.Errorf("Untracked = %v, want none with UntrackedNo", result.Untracked)
+	}
+}
+
+func TestManager_StatusUntracked(t *testing.T) {
+	m, root, _, head := setupRemoveTest(t)
+	writeWorktree(t, root, map[string]string{
+		"src/new.go":        "package main",
+		"docs/a.md":         "a",
+		"docs/deep/b.md":    "b",
+		"docs/notes.log":    "log",
+		"logs/only.log":     "log",
+		"build/sub/out.bin": "binary",
+	})
+	if err := os.Mkdir(filepath.Join(root.String(), "empty"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		opts      StatusOptions
+		untracked []string
+		ignored   []string
+	}{
+		{"normal", StatusOptions{},
+			[]string{".gitignore", "docs/", "src/new.go"}, []string{}},
+		{"normal ignored", StatusOptions{Ignored: true},
+			[]string{".gitignore", "docs/", "src/new.go"},
+			[]string{"build/", "docs/notes.log", "logs/", "src/debug.log"}},
+		{"all ignored", StatusOptions{Untracked: UntrackedAll, Ignored: true},
+			[]string{".gitignore", "docs/a.md", "docs/deep/b.md", "src/new.go"},
+			[]string{"build/out.bin", "build/sub/out.bin", "docs/notes.log", "logs/only.log", "src/debug.log"}},
+		{"no", StatusOptions{Untracked: UntrackedNo, Ignored: true}, []string{}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Head = head
+			result, err := m.StatusWithOptions(tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(result.Untracked, tt.untracked) {
+				t.Errorf("Untracked = %v, want %v", result.Untracked, tt.untracked)
+			}
+			if !reflect.DeepEqual(result.Ignored, tt.ignored) {
+				t.Errorf("Ignored = %v, want %v", result.Ignored, tt.ignored)
+			}
+		})
+	}
+}
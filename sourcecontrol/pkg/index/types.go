@@ -88,19 +88,72 @@ func (f EntryFlags) FilenameLength() int {
 	return int(f & FlagFilenameLengthMask)
 }
 
+// ExtendedFlags represents the second flags field that follows the flags of
+// an entry whose extended bit is set, in index version 3 and later:
+// - Bit 15: reserved for future use, must be 0
+// - Bit 14: skip-worktree flag (sparse checkout)
+// - Bit 13: intent-to-add flag (add -N)
+// - Bits 12-0: unused, must be 0
+type ExtendedFlags uint16
+
+const (
+	ExtendedFlagSkipWorktreeMask ExtendedFlags = 0x4000
+	ExtendedFlagIntentToAddMask  ExtendedFlags = 0x2000
+	ExtendedFlagReservedMask     ExtendedFlags = ^(ExtendedFlagSkipWorktreeMask | ExtendedFlagIntentToAddMask)
+)
+
+// NewExtendedFlags creates ExtendedFlags from components.
+func NewExtendedFlags(skipWorktree, intentToAdd bool) ExtendedFlags {
+	var flags ExtendedFlags
+	if skipWorktree {
+		flags |= ExtendedFlagSkipWorktreeMask
+	}
+	if intentToAdd {
+		flags |= ExtendedFlagIntentToAddMask
+	}
+	return flags
+}
+
+// SkipWorktree returns the skip-worktree flag.
+func (f ExtendedFlags) SkipWorktree() bool {
+	return (f & ExtendedFlagSkipWorktreeMask) != 0
+}
+
+// IntentToAdd returns the intent-to-add flag.
+func (f ExtendedFlags) IntentToAdd() bool {
+	return (f & ExtendedFlagIntentToAddMask) != 0
+}
+
 // Binary layout constants for index entries
 const (
-	FixedHeaderSize   = 62 // Everything before filename
-	SHALength         = 20 // SHA-1 is always 20 bytes
-	FlagsLength       = 2  // Flags are 2 bytes
-	FieldSize         = 4  // Most fields are 4 bytes
-	AlignmentBoundary = 8  // Entries are padded to 8-byte boundaries
+	FixedHeaderSize     = 62 // Everything before filename (SHA-1; 74 with SHA-256)
+	SHALength           = 20 // SHA-1 hashes are 20 bytes, SHA-256 hashes 32
+	FlagsLength         = 2  // Flags are 2 bytes
+	ExtendedFlagsLength = 2  // Extended flags (version 3+) are 2 more bytes
+	FieldSize           = 4  // Most fields are 4 bytes
+	AlignmentBoundary   = 8  // Entries are padded to 8-byte boundaries
 )
 
 // Index file format constants
 const (
-	IndexSignature    = "DIRC"
-	IndexVersion      = 2
+	IndexSignature = "DIRC"
+
+	// IndexVersion is the version written by default. Version 3 adds
+	// extended entry flags and is written instead when an entry needs
+	// them; version 4 also prefix-compresses paths.
+	IndexVersion           = 2
+	IndexVersionExtended   = 3
+	IndexVersionCompressed = 4
+
 	IndexHeaderSize   = 12 // Signature (4) + Version (4) + Entry count (4)
-	IndexChecksumSize = 20 // SHA-1 checksum
+	IndexChecksumSize = 20 // SHA-1 checksum (SHA-256 repositories use 32 bytes)
+)
+
+// Index extension constants. Each extension follows the entries as a 4-byte
+// signature, a 4-byte big-endian size and its data. Extensions whose
+// signature starts with an uppercase letter are optional and may be skipped
+// by readers that do not understand them.
+const (
+	CacheTreeSignature  = "TREE"
+	ExtensionHeaderSize = 8 // Signature (4) + Size (4)
 )
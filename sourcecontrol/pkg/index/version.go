@@ -0,0 +1,116 @@
+package index
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/utkarsh5026/SourceControl/pkg/config"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+)
+
+// writeVersion returns the version the index is written in: idx.Version,
+// raised to 3 when an entry has flags version 2 cannot store.
+func (idx *Index) writeVersion() uint32 {
+	version := idx.Version
+	if version == 0 {
+		version = IndexVersion
+	}
+	if version >= IndexVersionExtended {
+		return version
+	}
+	for _, entry := range idx.Entries {
+		if entry.HasExtendedFlags() {
+			return IndexVersionExtended
+		}
+	}
+	return version
+}
+
+// configuredVersion returns the index.version setting of the repository
+// whose index file is at path, or 0 when it is unset or not a version this
+// package can write. The setting is loaded the first time the index is
+// written to path and remembered, so writing the same index again does not
+// read the configuration again.
+func (idx *Index) configuredVersion(path scpath.AbsolutePath) uint32 {
+	if !idx.versionLoaded || idx.versionPath != path {
+		idx.versionSetting = loadConfiguredVersion(path)
+		idx.versionPath = path
+		idx.versionLoaded = true
+	}
+	return idx.versionSetting
+}
+
+// keepConfiguredVersion gives idx the index.version setting already loaded
+// for prev, for an index that replaces prev after re-reading the file.
+func (idx *Index) keepConfiguredVersion(prev *Index) {
+	idx.versionSetting = prev.versionSetting
+	idx.versionPath = prev.versionPath
+	idx.versionLoaded = prev.versionLoaded
+}
+
+// loadConfiguredVersion reads index.version from the configuration of the
+// repository whose index file is at path.
+func loadConfiguredVersion(path scpath.AbsolutePath) uint32 {
+	repoRoot := scpath.RepositoryPath(path.Dir().Dir())
+	configMgr := config.NewManager(repoRoot)
+	if err := configMgr.Load(context.Background()); err != nil {
+		return 0
+	}
+
+	version := config.NewTypedConfig(configMgr).IndexVersion()
+	if version < IndexVersion || version > IndexVersionCompressed {
+		return 0
+	}
+	return uint32(version)
+}
+
+// commonPrefixLength returns the number of leading bytes a and b share.
+func commonPrefixLength(a, b string) int {
+	n := min(len(a), len(b))
+	for i := range n {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return n
+}
+
+// encodeVarint encodes value in the variable-length format of version 4
+// paths (the same as pack offset deltas): big-endian groups of 7 bits, with
+// the high bit set on all but the last byte and each continuation offset by
+// one so that every value has exactly one encoding.
+func encodeVarint(value uint64) []byte {
+	var buf [10]byte
+	pos := len(buf) - 1
+	buf[pos] = byte(value & 0x7f)
+	for value >>= 7; value != 0; value >>= 7 {
+		value--
+		pos--
+		buf[pos] = 0x80 | byte(value&0x7f)
+	}
+	return buf[pos:]
+}
+
+// decodeVarint reads a value written by encodeVarint and returns it with
+// the number of bytes read.
+func decodeVarint(r io.ByteReader) (uint64, int, error) {
+	c, err := r.ReadByte()
+	if err != nil {
+		return 0, 0, err
+	}
+	n := 1
+	value := uint64(c & 0x7f)
+	for c&0x80 != 0 {
+		if value+1 > math.MaxUint64>>7 {
+			return 0, n, fmt.Errorf("varint overflows 64 bits")
+		}
+		if c, err = r.ReadByte(); err != nil {
+			return 0, n, err
+		}
+		n++
+		value = (value+1)<<7 | uint64(c&0x7f)
+	}
+	return value, n, nil
+}
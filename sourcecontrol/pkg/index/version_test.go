@@ -0,0 +1,168 @@
+package index
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+)
+
+// flaggedIndex returns an index whose entries use both extended flags and
+// share path prefixes.
+func flaggedIndex() *Index {
+	idx := NewIndex()
+	readme := createTestEntry("README", createTestHash("README"))
+	readme.SkipWorktree = true
+	added := createTestEntry("new", "e69de29bb2d1d6434b8b29ae775ad8c2e48c5391")
+	added.IntentToAdd = true
+	idx.Add(readme)
+	idx.Add(added)
+	idx.Add(createTestEntry("src/a/x.go", createTestHash("x")))
+	idx.Add(createTestEntry("src/a/y.go", createTestHash("y")))
+	return idx
+}
+
+func TestIndexVersions_RoundTrip(t *testing.T) {
+	for _, version := range []uint32{IndexVersion, IndexVersionExtended, IndexVersionCompressed} {
+		idx := flaggedIndex()
+		idx.Version = version
+
+		var buf bytes.Buffer
+		if err := idx.Serialize(&buf); err != nil {
+			t.Fatalf("v%d: Serialize() failed: %v", version, err)
+		}
+
+		// Version 2 cannot store extended flags, so version 3 is written
+		wantVersion := max(version, IndexVersionExtended)
+		if got := binary.BigEndian.Uint32(buf.Bytes()[4:8]); got != wantVersion {
+			t.Errorf("v%d: header version = %d, want %d", version, got, wantVersion)
+		}
+
+		read := NewIndex()
+		if err := read.Deserialize(bytes.NewReader(buf.Bytes())); err != nil {
+			t.Fatalf("v%d: Deserialize() failed: %v", version, err)
+		}
+		if read.Version != wantVersion || read.Count() != 4 {
+			t.Fatalf("v%d: read version %d with %d entries", version, read.Version, read.Count())
+		}
+		for i, e := range read.Entries {
+			want := idx.Entries[i]
+			if e.Path != want.Path || e.BlobHash != want.BlobHash ||
+				e.SkipWorktree != want.SkipWorktree || e.IntentToAdd != want.IntentToAdd {
+				t.Errorf("v%d: entry %d = %+v, want %+v", version, i, e, want)
+			}
+		}
+	}
+}
+
+func TestIndexVersion4_PathCompression(t *testing.T) {
+	idx := flaggedIndex()
+	idx.Version = IndexVersionCompressed
+
+	var buf bytes.Buffer
+	if err := idx.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each path is the number of bytes to drop from the previous path and
+	// the bytes to append, as git writes them
+	for _, encoded := range []string{"\x00README\x00", "\x06new\x00", "\x03src/a/x.go\x00", "\x04y.go\x00"} {
+		if !bytes.Contains(buf.Bytes(), []byte(encoded)) {
+			t.Errorf("serialized index does not contain %q", encoded)
+		}
+	}
+	if bytes.Contains(buf.Bytes(), []byte("src/a/y.go")) {
+		t.Error("version 4 should not store the full path of src/a/y.go")
+	}
+}
+
+func TestIndexDeserialize_RejectsUnknownExtendedFlags(t *testing.T) {
+	idx := NewIndex()
+	entry := createTestEntry("file", createTestHash("file"))
+	entry.IntentToAdd = true
+	idx.Add(entry)
+
+	var buf bytes.Buffer
+	if err := idx.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	// Set the reserved bit of the extended flags and fix up the checksum
+	flagsOffset := IndexHeaderSize + FixedHeaderSize
+	data[flagsOffset] |= 0x80
+	content := data[:len(data)-SHALength]
+	checksum := objects.SHA1.New()
+	checksum.Write(content)
+	copy(data[len(content):], checksum.Sum(nil))
+
+	if err := NewIndex().Deserialize(bytes.NewReader(data)); err == nil {
+		t.Error("Deserialize() should reject reserved extended flags")
+	}
+}
+
+func TestIndexWrite_ConfiguredVersion(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", t.TempDir())
+
+	root := t.TempDir()
+	config := `{"index": {"version": "4"}}`
+	if err := os.WriteFile(filepath.Join(root, "config.json"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	indexPath := scpath.AbsolutePath(filepath.Join(root, ".git", "index"))
+
+	idx := NewIndex()
+	idx.Add(createTestEntry("file", createTestHash("file")))
+	if err := idx.Write(indexPath); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(indexPath.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := binary.BigEndian.Uint32(data[4:8]); got != IndexVersionCompressed {
+		t.Errorf("written version = %d, want index.version 4", got)
+	}
+
+	// The setting is read once per index, not on every write
+	if err := os.Remove(filepath.Join(root, "config.json")); err != nil {
+		t.Fatal(err)
+	}
+	idx.Version = IndexVersion
+	if err := idx.Write(indexPath); err != nil {
+		t.Fatal(err)
+	}
+	if idx.Version != IndexVersionCompressed {
+		t.Errorf("rewritten version = %d, want the index.version already loaded", idx.Version)
+	}
+}
+
+func TestVarint(t *testing.T) {
+	tests := []struct {
+		value   uint64
+		encoded []byte
+	}{
+		{0, []byte{0x00}},
+		{127, []byte{0x7f}},
+		{128, []byte{0x80, 0x00}},
+		{16511, []byte{0xff, 0x7f}},
+		{16512, []byte{0x80, 0x80, 0x00}},
+	}
+	for _, tt := range tests {
+		if got := encodeVarint(tt.value); !bytes.Equal(got, tt.encoded) {
+			t.Errorf("encodeVarint(%d) = %x, want %x", tt.value, got, tt.encoded)
+		}
+		got, n, err := decodeVarint(bytes.NewReader(tt.encoded))
+		if err != nil || got != tt.value || n != len(tt.encoded) {
+			t.Errorf("decodeVarint(%x) = %d, %d, %v; want %d", tt.encoded, got, n, err, tt.value)
+		}
+	}
+}
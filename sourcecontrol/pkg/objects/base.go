@@ -34,10 +34,11 @@ type BaseObject interface {
 	// Content returns the raw content of the object
 	Content() (ObjectContent, error)
 
-	// Hash returns the SHA-1 hash of the object
+	// Hash returns the hash of the object (SHA-1 unless the object was
+	// parsed from, or refers to, a SHA-256 repository)
 	Hash() (ObjectHash, error)
 
-	// RawHash returns the SHA-1 hash as a 20-byte array
+	// RawHash returns the hash as raw bytes
 	RawHash() (RawHash, error)
 
 	// Size returns the size of the content in bytes
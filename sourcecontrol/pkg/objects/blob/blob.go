@@ -10,11 +10,16 @@ import (
 )
 
 // Blob represents a Git blob object, which stores the content of a file.
-// It contains the raw content and a lazily-computed SHA-1 hash.
+// It contains the raw content and a lazily-computed hash.
 // Blobs are immutable once created and are identified by their content hash.
+//
+// A blob's content says nothing about the repository's object format, so
+// blobs created with NewBlob hash with SHA-1. Blobs read from a SHA-256
+// repository are parsed with ParseBlobWithAlgorithm and keep that algorithm.
 type Blob struct {
-	content objects.ObjectContent
-	hash    *objects.ObjectHash
+	content   objects.ObjectContent
+	algorithm objects.HashAlgorithm
+	hash      *objects.ObjectHash
 }
 
 // NewBlob creates a new Blob object from raw data.
@@ -43,15 +48,22 @@ func NewBlob(data []byte) *Blob {
 //   - A pointer to the parsed Blob instance
 //   - An error if the data is invalid or doesn't represent a blob
 func ParseBlob(data []byte) (*Blob, error) {
+	return ParseBlobWithAlgorithm(data, objects.SHA1)
+}
+
+// ParseBlobWithAlgorithm parses a blob like ParseBlob, hashing it with the
+// given algorithm instead of SHA-1.
+func ParseBlobWithAlgorithm(data []byte, algorithm objects.HashAlgorithm) (*Blob, error) {
 	content, err := objects.ParseSerializedObject(data, objects.BlobType)
 	if err != nil {
 		return nil, err
 	}
 
-	hash := objects.NewObjectHash(objects.SerializedObject(data))
+	hash := algorithm.Sum(data)
 	return &Blob{
-		content: content,
-		hash:    &hash,
+		content:   content,
+		algorithm: algorithm,
+		hash:      &hash,
 	}, nil
 }
 
@@ -74,33 +86,37 @@ func (b *Blob) Content() (objects.ObjectContent, error) {
 	return b.content, nil
 }
 
-// Hash returns the SHA-1 hash of the blob.
+// Hash returns the hash of the blob.
 // The hash is computed lazily on first access and then cached.
 // The hash is computed over the serialized object format: "<type> <size>\0<content>"
 //
 // Returns:
-//   - The 40-character hexadecimal SHA-1 hash
+//   - The hexadecimal hash (40 characters for SHA-1, 64 for SHA-256)
 //   - An error if hash computation fails
 func (b *Blob) Hash() (objects.ObjectHash, error) {
 	if b.hash != nil {
 		return *b.hash, nil
 	}
 
-	hash := objects.ComputeObjectHash(objects.BlobType, b.content)
+	algorithm := b.algorithm
+	if algorithm == "" {
+		algorithm = objects.SHA1
+	}
+	hash := algorithm.HashObject(objects.BlobType, b.content)
 	b.hash = &hash
 	return hash, nil
 }
 
-// RawHash returns the SHA-1 hash as a 20-byte array.
+// RawHash returns the hash as raw bytes.
 // This is useful for compact storage or binary operations.
 //
 // Returns:
-//   - A 20-byte array containing the raw SHA-1 hash
+//   - The raw hash (20 bytes for SHA-1, 32 for SHA-256)
 //   - An error if hash computation fails
 func (b *Blob) RawHash() (objects.RawHash, error) {
 	hash, err := b.Hash()
 	if err != nil {
-		return objects.RawHash{}, err
+		return nil, err
 	}
 	return hash.Raw()
 }
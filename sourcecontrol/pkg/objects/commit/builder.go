@@ -95,6 +95,13 @@ func (b *CommitBuilder) Message(message string) *CommitBuilder {
 	return b
 }
 
+// ExtraHeader appends a header such as "encoding" or "gpgsig" after the
+// committer line. Multi-line values are written as continuation lines.
+func (b *CommitBuilder) ExtraHeader(key, value string) *CommitBuilder {
+	b.commit.ExtraHeaders = append(b.commit.ExtraHeaders, ExtraHeader{Key: key, Value: value})
+	return b
+}
+
 // Build creates the Commit, returning an error if validation fails
 func (b *CommitBuilder) Build() (*Commit, error) {
 	if len(b.errs) > 0 {
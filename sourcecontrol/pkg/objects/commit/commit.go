@@ -25,6 +25,7 @@ import (
 // │ "parent" SPACE parent-sha LF (zero or more)                     │
 // │ "author" SPACE name SPACE email SPACE timestamp SPACE tz LF     │
 // │ "committer" SPACE name SPACE email SPACE timestamp SPACE tz LF  │
+// │ key SPACE value LF (zero or more extra headers)                 │
 // │ LF                                                              │
 // │ commit-message                                                  │
 // └─────────────────────────────────────────────────────────────────┘
@@ -36,6 +37,10 @@ import (
 //
 // # Initial commit
 //
+// Headers other than tree, parent, author and committer (for example
+// "encoding", "mergetag" or a "gpgsig" signature) are kept in ExtraHeaders so
+// that a parsed commit serializes back to exactly the same bytes and hash.
+//
 // Commits form a directed acyclic graph (DAG) where:
 // - Each commit points to its parent(s)
 // - Most commits have exactly one parent
@@ -43,12 +48,68 @@ import (
 // - The initial commit has no parents
 // - The graph represents the complete history of the repository
 type Commit struct {
-	TreeSHA    objects.ObjectHash
-	ParentSHAs []objects.ObjectHash
-	Author     *CommitPerson
-	Committer  *CommitPerson
-	Message    string
-	hash       *objects.ObjectHash // cached hash
+	TreeSHA      objects.ObjectHash
+	ParentSHAs   []objects.ObjectHash
+	Author       *CommitPerson
+	Committer    *CommitPerson
+	ExtraHeaders []ExtraHeader
+	Message      string
+	hash         *objects.ObjectHash // cached hash
+}
+
+// Well-known extra header keys written by Git.
+const (
+	HeaderEncoding     = "encoding"
+	HeaderMergeTag     = "mergetag"
+	HeaderGPGSig       = "gpgsig"
+	HeaderGPGSigSHA256 = "gpgsig-sha256"
+)
+
+// ExtraHeader is a commit header other than tree, parent, author and
+// committer.
+//
+// Multi-line values are stored with their lines joined by "\n". In the object
+// every line after the first is written as a continuation line starting with
+// a single space:
+//
+//	gpgsig -----BEGIN PGP SIGNATURE-----
+//	 <blank line of the signature>
+//	 iQEzBAABCAAdFiEE...
+//	 -----END PGP SIGNATURE-----
+type ExtraHeader struct {
+	Key   string
+	Value string
+
+	// KeyOnly records a header line that has no space after the key, so
+	// that it is written back the same way. The first line of Value is
+	// then empty.
+	KeyOnly bool
+}
+
+// Header returns the value of the first extra header with the given key.
+func (c *Commit) Header(key string) (string, bool) {
+	for _, h := range c.ExtraHeaders {
+		if h.Key == key {
+			return h.Value, true
+		}
+	}
+	return "", false
+}
+
+// Encoding returns the value of the "encoding" header, or "" when the message
+// is UTF-8 (Git's default).
+func (c *Commit) Encoding() string {
+	enc, _ := c.Header(HeaderEncoding)
+	return enc
+}
+
+// Signature returns the commit's signature from the "gpgsig" (or
+// "gpgsig-sha256") header, if it is signed.
+func (c *Commit) Signature() (string, bool) {
+	if sig, ok := c.Header(HeaderGPGSig); ok {
+		return sig, true
+	}
+	return c.Header(HeaderGPGSigSHA256)
 }
 
 // CommitBuilder provides a fluent interface for building commits
@@ -64,6 +125,10 @@ func (c *Commit) Validate() error {
 		if err := parent.Validate(); err != nil {
 			return fmt.Errorf("invalid parent SHA at index %d: %w", i, err)
 		}
+		if parent.Algorithm() != c.TreeSHA.Algorithm() {
+			return fmt.Errorf("parent SHA at index %d is %s but tree SHA is %s",
+				i, parent.Algorithm(), c.TreeSHA.Algorithm())
+		}
 	}
 	if c.Author == nil {
 		return fmt.Errorf("author is required")
@@ -71,6 +136,14 @@ func (c *Commit) Validate() error {
 	if c.Committer == nil {
 		return fmt.Errorf("committer is required")
 	}
+	for _, h := range c.ExtraHeaders {
+		if h.Key == "" || strings.ContainsAny(h.Key, " \n") {
+			return fmt.Errorf("invalid extra header key: %q", h.Key)
+		}
+		if h.KeyOnly && h.Value != "" && !strings.HasPrefix(h.Value, "\n") {
+			return fmt.Errorf("extra header %q has a value on its first line", h.Key)
+		}
+	}
 	return nil
 }
 
@@ -105,6 +178,11 @@ func (c *Commit) Content() (objects.ObjectContent, error) {
 	buf.WriteString(c.Committer.FormatForGit())
 	buf.WriteString("\n")
 
+	// Extra headers, in their original order
+	for _, h := range c.ExtraHeaders {
+		writeExtraHeader(&buf, h)
+	}
+
 	// Blank line before message
 	buf.WriteString("\n")
 
@@ -114,7 +192,7 @@ func (c *Commit) Content() (objects.ObjectContent, error) {
 	return objects.ObjectContent(buf.String()), nil
 }
 
-// Hash returns the SHA-1 hash of the commit
+// Hash returns the hash of the commit, using the algorithm of its tree hash
 func (c *Commit) Hash() (objects.ObjectHash, error) {
 	if c.hash != nil {
 		return *c.hash, nil
@@ -126,16 +204,16 @@ func (c *Commit) Hash() (objects.ObjectHash, error) {
 		return "", fmt.Errorf("failed to get content: %w", err)
 	}
 
-	hash := objects.ComputeObjectHash(objects.CommitType, content)
+	hash := c.TreeSHA.Algorithm().HashObject(objects.CommitType, content)
 	c.hash = &hash
 	return hash, nil
 }
 
-// RawHash returns the SHA-1 hash as a 20-byte array
+// RawHash returns the hash as raw bytes
 func (c *Commit) RawHash() (objects.RawHash, error) {
 	hash, err := c.Hash()
 	if err != nil {
-		return objects.RawHash{}, err
+		return nil, err
 	}
 	return hash.Raw()
 }
@@ -192,7 +270,7 @@ func ParseCommit(data []byte) (*Commit, error) {
 		return nil, err
 	}
 
-	hash := objects.NewObjectHash(objects.SerializedObject(data))
+	hash := commit.TreeSHA.Algorithm().Sum(data)
 	commit.hash = &hash
 	return commit, nil
 }
@@ -205,17 +283,31 @@ func parseCommitContent(content string) (*Commit, error) {
 	}
 
 	messageStartIndex := -1
+	inExtraHeader := false
 
 	for i, line := range lines {
-		// Empty line indicates start of message
-		if strings.TrimSpace(line) == "" {
+		// Empty line indicates start of message. Lines holding only spaces are
+		// not the separator: signatures use " " for their own blank lines.
+		if line == "" {
 			messageStartIndex = i + 1
 			break
 		}
 
+		// Continuation of the previous extra header's value
+		if strings.HasPrefix(line, " ") {
+			if !inExtraHeader {
+				return nil, fmt.Errorf("unexpected continuation line: %q", line)
+			}
+			last := &commit.ExtraHeaders[len(commit.ExtraHeaders)-1]
+			last.Value += "\n" + line[1:]
+			continue
+		}
+
+		extras := len(commit.ExtraHeaders)
 		if err := parseCommitLine(commit, line); err != nil {
 			return nil, err
 		}
+		inExtraHeader = len(commit.ExtraHeaders) > extras
 	}
 
 	if err := commit.Validate(); err != nil {
@@ -275,12 +367,24 @@ func parseCommitLine(commit *Commit, line string) error {
 		commit.Committer = committer
 
 	default:
-		return fmt.Errorf("unknown header line: %s", line)
+		key, value, found := strings.Cut(line, " ")
+		commit.ExtraHeaders = append(commit.ExtraHeaders, ExtraHeader{Key: key, Value: value, KeyOnly: !found})
 	}
 
 	return nil
 }
 
+// writeExtraHeader writes an extra header, turning each newline in its value
+// into a continuation line.
+func writeExtraHeader(buf io.StringWriter, h ExtraHeader) {
+	buf.WriteString(h.Key)
+	if !h.KeyOnly {
+		buf.WriteString(" ")
+	}
+	buf.WriteString(strings.ReplaceAll(h.Value, "\n", "\n "))
+	buf.WriteString("\n")
+}
+
 // IsInitialCommit returns true if this commit has no parents
 func (c *Commit) IsInitialCommit() bool {
 	return len(c.ParentSHAs) == 0
@@ -328,6 +432,16 @@ func (c *Commit) Equal(other *Commit) bool {
 		return false
 	}
 
+	if len(c.ExtraHeaders) != len(other.ExtraHeaders) {
+		return false
+	}
+
+	for i, h := range c.ExtraHeaders {
+		if h != other.ExtraHeaders[i] {
+			return false
+		}
+	}
+
 	return c.Message == other.Message
 }
 
@@ -341,6 +455,10 @@ func (c *Commit) Clone() *Commit {
 		Message:    c.Message,
 	}
 	copy(clone.ParentSHAs, c.ParentSHAs)
+	if len(c.ExtraHeaders) > 0 {
+		clone.ExtraHeaders = make([]ExtraHeader, len(c.ExtraHeaders))
+		copy(clone.ExtraHeaders, c.ExtraHeaders)
+	}
 	return clone
 }
 
@@ -363,6 +481,9 @@ func (c *Commit) HeaderSize() int {
 	}
 	fmt.Fprintf(&buf, "author %s\n", c.Author.FormatForGit())
 	fmt.Fprintf(&buf, "committer %s\n", c.Committer.FormatForGit())
+	for _, h := range c.ExtraHeaders {
+		writeExtraHeader(&buf, h)
+	}
 	buf.WriteString("\n")
 	return buf.Len()
 }
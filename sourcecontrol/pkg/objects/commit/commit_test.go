@@ -2,6 +2,7 @@ package commit
 
 import (
 	"bytes"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -311,6 +312,149 @@ func TestParseCommit(t *testing.T) {
 	}
 }
 
+// signedCommitContent is a commit as Git writes it with an encoding header, a
+// mergetag and a signature. Blank lines inside the signature and the embedded
+// tag are stored as a single space.
+const signedCommitContent = "tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n" +
+	"parent a1b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b0\n" +
+	"parent b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b0c1\n" +
+	"author John Doe <john@example.com> 1609459200 +0530\n" +
+	"committer Jane Smith <jane@example.com> 1609462800 -0800\n" +
+	"encoding ISO-8859-1\n" +
+	"mergetag object b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b0c1\n" +
+	" type commit\n" +
+	" tag v1.0\n" +
+	" tagger Jane Smith <jane@example.com> 1609462000 -0800\n" +
+	" \n" +
+	" Release 1.0\n" +
+	"gpgsig -----BEGIN PGP SIGNATURE-----\n" +
+	" \n" +
+	" iQEzBAABCAAdFiEEabcdefghijklmnopqrstuvwxyz0123456789ABCDEF\n" +
+	" =XyZ1\n" +
+	" -----END PGP SIGNATURE-----\n" +
+	"\n" +
+	"Merge tag 'v1.0'\n\n  indented body line\n"
+
+func TestParseCommit_ExtraHeaders(t *testing.T) {
+	data := objects.NewSerializedObject(objects.CommitType, objects.ObjectContent(signedCommitContent)).Bytes()
+
+	parsed, err := ParseCommit(data)
+	if err != nil {
+		t.Fatalf("ParseCommit() error = %v", err)
+	}
+
+	wantKeys := []string{HeaderEncoding, HeaderMergeTag, HeaderGPGSig}
+	if len(parsed.ExtraHeaders) != len(wantKeys) {
+		t.Fatalf("ExtraHeaders = %+v, want keys %v", parsed.ExtraHeaders, wantKeys)
+	}
+	for i, key := range wantKeys {
+		if parsed.ExtraHeaders[i].Key != key {
+			t.Errorf("ExtraHeaders[%d].Key = %q, want %q", i, parsed.ExtraHeaders[i].Key, key)
+		}
+	}
+
+	if parsed.Encoding() != "ISO-8859-1" {
+		t.Errorf("Encoding() = %q, want ISO-8859-1", parsed.Encoding())
+	}
+	sig, ok := parsed.Signature()
+	if !ok || !strings.HasPrefix(sig, "-----BEGIN PGP SIGNATURE-----\n\niQEz") || !strings.HasSuffix(sig, "-----END PGP SIGNATURE-----") {
+		t.Errorf("Signature() = %q, %v", sig, ok)
+	}
+	if tag, _ := parsed.Header(HeaderMergeTag); !strings.HasSuffix(tag, "-0800\n\nRelease 1.0") {
+		t.Errorf("mergetag value = %q", tag)
+	}
+	if parsed.Message != "Merge tag 'v1.0'\n\n  indented body line\n" {
+		t.Errorf("Message = %q", parsed.Message)
+	}
+
+	// Re-serializing must reproduce the original object byte for byte. Clone
+	// drops the cached hash so it is recomputed from the content.
+	var buf bytes.Buffer
+	if err := parsed.Clone().Serialize(&buf); err != nil {
+		t.Fatalf("Serialize() error = %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Errorf("re-serialized commit differs:\n got: %q\nwant: %q", buf.Bytes(), data)
+	}
+
+	rehashed, _ := parsed.Clone().Hash()
+	if want := objects.NewObjectHash(objects.SerializedObject(data)); rehashed != want {
+		t.Errorf("Hash() = %s, want %s", rehashed, want)
+	}
+	if !parsed.Equal(parsed.Clone()) {
+		t.Error("Clone() is not Equal to the original")
+	}
+	if parsed.HeaderSize() != strings.Index(signedCommitContent, "\n\n")+2 {
+		t.Errorf("HeaderSize() = %d", parsed.HeaderSize())
+	}
+}
+
+func TestParseCommit_KeyOnlyHeader(t *testing.T) {
+	content := "tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n" +
+		"author John Doe <john@example.com> 1609459200 +0000\n" +
+		"committer John Doe <john@example.com> 1609459200 +0000\n" +
+		"x-flag\n" +
+		"x-empty \n" +
+		"x-block\n" +
+		" first\n" +
+		"\n" +
+		"message\n"
+	data := objects.NewSerializedObject(objects.CommitType, objects.ObjectContent(content)).Bytes()
+
+	parsed, err := ParseCommit(data)
+	if err != nil {
+		t.Fatalf("ParseCommit() error = %v", err)
+	}
+	want := []ExtraHeader{
+		{Key: "x-flag", KeyOnly: true},
+		{Key: "x-empty"},
+		{Key: "x-block", Value: "\nfirst", KeyOnly: true},
+	}
+	if !reflect.DeepEqual(parsed.ExtraHeaders, want) {
+		t.Errorf("ExtraHeaders = %+v, want %+v", parsed.ExtraHeaders, want)
+	}
+
+	var buf bytes.Buffer
+	if err := parsed.Clone().Serialize(&buf); err != nil {
+		t.Fatalf("Serialize() error = %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Errorf("re-serialized commit differs:\n got: %q\nwant: %q", buf.Bytes(), data)
+	}
+	if parsed.HeaderSize() != strings.Index(content, "\n\n")+2 {
+		t.Errorf("HeaderSize() = %d", parsed.HeaderSize())
+	}
+}
+
+func TestCommitBuilder_ExtraHeader(t *testing.T) {
+	person := createTestPerson("John Doe", "john@example.com")
+
+	c, err := NewCommitBuilder().
+		Tree("4b825dc642cb6eb9a060e54bf8d69288fbee4904").
+		Author(person).
+		Committer(person).
+		ExtraHeader(HeaderGPGSig, "line one\n\nline three").
+		Message("signed\n").
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	content, _ := c.Content()
+	if !strings.Contains(content.String(), "\ngpgsig line one\n \n line three\n\nsigned\n") {
+		t.Errorf("Content() = %q", content)
+	}
+
+	if _, err := NewCommitBuilder().
+		Tree("4b825dc642cb6eb9a060e54bf8d69288fbee4904").
+		Author(person).
+		Committer(person).
+		ExtraHeader("bad key", "x").
+		Build(); err == nil {
+		t.Error("Build() accepted a header key containing a space")
+	}
+}
+
 func TestParseCommit_InvalidData(t *testing.T) {
 	tests := []struct {
 		name string
@@ -332,6 +476,10 @@ func TestParseCommit_InvalidData(t *testing.T) {
 			name: "missing committer",
 			data: []byte("commit 50\x00tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\nauthor John <j@e.com> 123 +0000\n\nTest"),
 		},
+		{
+			name: "continuation without extra header",
+			data: []byte("commit 50\x00tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n continued\nauthor J <j@e.com> 123 +0000\ncommitter J <j@e.com> 123 +0000\n\nTest"),
+		},
 		{
 			name: "duplicate tree",
 			data: []byte("commit 50\x00tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\ntree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\nauthor J <j@e.com> 123 +0000\ncommitter J <j@e.com> 123 +0000\n\nTest"),
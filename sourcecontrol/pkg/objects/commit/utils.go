@@ -1,5 +1,7 @@
 package commit
 
+import "github.com/utkarsh5026/SourceControl/pkg/objects"
+
 // isHexString checks if a string contains only hexadecimal characters
 func isHexString(s string) bool {
 	if len(s) == 0 {
@@ -15,11 +17,11 @@ func isHexString(s string) bool {
 }
 
 // looksLikeCommitSHA returns true if the string looks like it could be a commit SHA
-// This includes both full (40 char) and short (7+ char) SHAs
+// This includes full (40 char SHA-1, 64 char SHA-256) and short (4+ char) SHAs
 func LooksLikeCommitSHA(s string) bool {
 	if !isHexString(s) {
 		return false
 	}
 	length := len(s)
-	return length >= 4 && length <= 40
+	return length >= 4 && length <= objects.SHA256.HexSize()
 }
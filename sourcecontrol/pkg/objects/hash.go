@@ -1,13 +1,15 @@
 package objects
 
 import (
+	"bytes"
 	"crypto/sha1"
 	"encoding/hex"
 	"fmt"
 	"strings"
 )
 
-// ObjectHash represents a SHA-1 hash of a Git object (40-character hex string)
+// ObjectHash represents the hash of a Git object as a hex string: 40
+// characters in SHA-1 repositories, 64 in SHA-256 ones (see HashAlgorithm).
 // Example: "e69de29bb2d1d6434b8b29ae775ad8c2e48c5391"
 type ObjectHash string
 
@@ -15,8 +17,8 @@ type ObjectHash string
 // Example: "e69de29"
 type ShortHash string
 
-// RawHash represents a SHA-1 hash as a 20-byte array
-type RawHash [20]byte
+// RawHash represents a hash as raw bytes: 20 for SHA-1, 32 for SHA-256
+type RawHash []byte
 
 const (
 	// HashLength is the length of a full SHA-1 hash in hex (40 characters)
@@ -27,20 +29,21 @@ const (
 	RawHashLength = 20
 )
 
-// ZeroHash returns an all-zero hash (used for uninitialized or null references)
+// ZeroHash returns an all-zero SHA-1 hash (used for uninitialized or null
+// references). Use HashAlgorithm.ZeroHash for other object formats.
 func ZeroHash() ObjectHash {
-	return ObjectHash("0000000000000000000000000000000000000000")
+	return SHA1.ZeroHash()
 }
 
-// NewObjectHash creates a new ObjectHash from a byte slice
+// NewObjectHash computes the SHA-1 hash of a byte slice.
+// Use HashAlgorithm.Sum in code that must respect the repository's format.
 func NewObjectHash(data []byte) ObjectHash {
-	hash := sha1.Sum(data)
-	return ObjectHash(hex.EncodeToString(hash[:]))
+	return SHA1.Sum(data)
 }
 
-// NewObjectHashFromRaw creates an ObjectHash from a 20-byte array
+// NewObjectHashFromRaw creates an ObjectHash from raw hash bytes
 func NewObjectHashFromRaw(raw RawHash) ObjectHash {
-	return ObjectHash(hex.EncodeToString(raw[:]))
+	return ObjectHash(hex.EncodeToString(raw))
 }
 
 // NewObjectHashFromString creates an ObjectHash from a hex string
@@ -63,15 +66,16 @@ func (h ObjectHash) String() string {
 	return string(h)
 }
 
-// IsValid returns true if this is a valid SHA-1 hash
+// IsValid returns true if this is a valid SHA-1 or SHA-256 hash
 func (h ObjectHash) IsValid() bool {
 	return h.Validate() == nil
 }
 
 // Validate checks if the hash is valid
 func (h ObjectHash) Validate() error {
-	if len(h) != HashLength {
-		return fmt.Errorf("hash must be %d characters long, got %d", HashLength, len(h))
+	if _, ok := hashAlgorithmForHexSize(len(h)); !ok {
+		return fmt.Errorf("hash must be %d or %d characters long, got %d",
+			SHA1.HexSize(), SHA256.HexSize(), len(h))
 	}
 
 	for _, c := range h {
@@ -83,9 +87,18 @@ func (h ObjectHash) Validate() error {
 	return nil
 }
 
-// IsZero returns true if this is the zero hash
+// Algorithm returns the hash algorithm implied by the hash's width, or
+// DefaultHashAlgorithm if the width matches none.
+func (h ObjectHash) Algorithm() HashAlgorithm {
+	if algo, ok := hashAlgorithmForHexSize(len(h)); ok {
+		return algo
+	}
+	return DefaultHashAlgorithm
+}
+
+// IsZero returns true if this is the zero hash of either width
 func (h ObjectHash) IsZero() bool {
-	return h == ZeroHash()
+	return len(h) > 0 && h == h.Algorithm().ZeroHash()
 }
 
 // Short returns the abbreviated version of the hash
@@ -115,16 +128,13 @@ func (h ObjectHash) Bytes() ([]byte, error) {
 	return hex.DecodeString(string(h))
 }
 
-// Raw returns the hash as a 20-byte array
+// Raw returns the hash as raw bytes
 func (h ObjectHash) Raw() (RawHash, error) {
-	bytes, err := h.Bytes()
+	raw, err := h.Bytes()
 	if err != nil {
-		return RawHash{}, err
+		return nil, err
 	}
-
-	var raw RawHash
-	copy(raw[:], bytes)
-	return raw, nil
+	return RawHash(raw), nil
 }
 
 // Equal compares two hashes for equality (case-insensitive)
@@ -144,7 +154,7 @@ func (sh ShortHash) String() string {
 
 // IsValid returns true if this is a valid short hash (hex characters only)
 func (sh ShortHash) IsValid() bool {
-	if len(sh) == 0 || len(sh) > HashLength {
+	if len(sh) == 0 || len(sh) > SHA256.HexSize() {
 		return false
 	}
 	for _, c := range sh {
@@ -174,7 +184,7 @@ func (rh RawHash) Hash() ObjectHash {
 
 // String returns the hash as a hex string
 func (rh RawHash) String() string {
-	return hex.EncodeToString(rh[:])
+	return hex.EncodeToString(rh)
 }
 
 // Short returns the abbreviated version
@@ -184,6 +194,9 @@ func (rh RawHash) Short() ShortHash {
 
 // IsZero returns true if this is a zero hash
 func (rh RawHash) IsZero() bool {
+	if len(rh) == 0 {
+		return false
+	}
 	for _, b := range rh {
 		if b != 0 {
 			return false
@@ -194,7 +207,7 @@ func (rh RawHash) IsZero() bool {
 
 // Equal compares two raw hashes for equality
 func (rh RawHash) Equal(other RawHash) bool {
-	return rh == other
+	return bytes.Equal(rh, other)
 }
 
 // Helper functions
@@ -206,12 +219,12 @@ func isHexChar(c rune) bool {
 
 // ComputeHash computes the SHA-1 hash of the given data
 func ComputeHash(data []byte) RawHash {
-	return sha1.Sum(data)
+	sum := sha1.Sum(data)
+	return sum[:]
 }
 
-// ComputeObjectHash computes the object hash from type and content
+// ComputeObjectHash computes the SHA-1 object hash from type and content
 // This follows Git's format: hash("<type> <size>\0<content>")
 func ComputeObjectHash(objType ObjectType, content ObjectContent) ObjectHash {
-	serialized := NewSerializedObject(objType, content)
-	return NewObjectHash(serialized.Bytes())
+	return SHA1.HashObject(objType, content)
 }
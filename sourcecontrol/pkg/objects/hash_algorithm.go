@@ -0,0 +1,114 @@
+package objects
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+)
+
+// HashAlgorithm identifies the hash function a repository uses to name its
+// objects. It is a property of the repository, recorded in .git/config as
+// extensions.objectFormat, and decides the width of every object ID: in
+// ObjectHash values, tree entries, index entries and ref files.
+//
+//	┌──────────┬───────────┬─────────────┐
+//	│ Format   │ Raw bytes │ Hex digits  │
+//	├──────────┼───────────┼─────────────┤
+//	│ sha1     │ 20        │ 40          │
+//	│ sha256   │ 32        │ 64          │
+//	└──────────┴───────────┴─────────────┘
+type HashAlgorithm string
+
+const (
+	// SHA1 is Git's original object format and the default for new repositories
+	SHA1 HashAlgorithm = "sha1"
+	// SHA256 is the object format selected with init --object-format=sha256
+	SHA256 HashAlgorithm = "sha256"
+)
+
+// DefaultHashAlgorithm is used for repositories that do not record an
+// object format.
+const DefaultHashAlgorithm = SHA1
+
+// ParseHashAlgorithm converts an object format name, as used in
+// extensions.objectFormat and --object-format, to a HashAlgorithm.
+func ParseHashAlgorithm(name string) (HashAlgorithm, error) {
+	switch algo := HashAlgorithm(strings.ToLower(strings.TrimSpace(name))); algo {
+	case SHA1, SHA256:
+		return algo, nil
+	default:
+		return "", fmt.Errorf("unknown object format: %q (use sha1 or sha256)", name)
+	}
+}
+
+// hashAlgorithmForHexSize returns the algorithm whose hashes have n hex digits.
+func hashAlgorithmForHexSize(n int) (HashAlgorithm, bool) {
+	switch n {
+	case SHA1.HexSize():
+		return SHA1, true
+	case SHA256.HexSize():
+		return SHA256, true
+	default:
+		return "", false
+	}
+}
+
+// String returns the object format name ("sha1" or "sha256").
+func (a HashAlgorithm) String() string {
+	return string(a)
+}
+
+// Size returns the length of a raw hash in bytes.
+func (a HashAlgorithm) Size() int {
+	if a == SHA256 {
+		return sha256.Size
+	}
+	return sha1.Size
+}
+
+// HexSize returns the length of a hash in hex digits.
+func (a HashAlgorithm) HexSize() int {
+	return a.Size() * 2
+}
+
+// New returns a hash.Hash computing this algorithm.
+func (a HashAlgorithm) New() hash.Hash {
+	if a == SHA256 {
+		return sha256.New()
+	}
+	return sha1.New()
+}
+
+// Sum hashes data, which must be a complete serialized object
+// ("<type> <size>\0<content>"), and returns its object ID.
+func (a HashAlgorithm) Sum(data []byte) ObjectHash {
+	h := a.New()
+	h.Write(data)
+	return ObjectHash(hex.EncodeToString(h.Sum(nil)))
+}
+
+// ZeroHash returns the all-zero hash of this algorithm's width.
+func (a HashAlgorithm) ZeroHash() ObjectHash {
+	return ObjectHash(strings.Repeat("0", a.HexSize()))
+}
+
+// HashObject computes the object ID of an object from its type and content.
+func (a HashAlgorithm) HashObject(objType ObjectType, content ObjectContent) ObjectHash {
+	return a.Sum(NewSerializedObject(objType, content).Bytes())
+}
+
+// HashObjectFromReader computes the object ID of size bytes of content read
+// from r, without holding the content in memory.
+// Returns an error if r yields fewer than size bytes.
+func (a HashAlgorithm) HashObjectFromReader(objType ObjectType, r io.Reader, size int64) (ObjectHash, error) {
+	h := a.New()
+	h.Write(CreateHeader(objType, size))
+	if _, err := io.CopyN(h, r, size); err != nil {
+		return "", fmt.Errorf("failed to hash content: %w", err)
+	}
+	return ObjectHash(hex.EncodeToString(h.Sum(nil))), nil
+}
@@ -40,7 +40,7 @@ import (
 //
 // Release version 1.0.0
 type Tag struct {
-	ObjectSHA  objects.ObjectHash    // SHA-1 hash of the tagged object
+	ObjectSHA  objects.ObjectHash    // Hash of the tagged object
 	ObjectType objects.ObjectType    // Type of the tagged object (commit, tree, blob)
 	Name       string                // Tag name (e.g., "v1.0.0")
 	Tagger     *commit.CommitPerson  // Person who created the tag
@@ -118,7 +118,8 @@ func (t *Tag) Content() (objects.ObjectContent, error) {
 	return objects.ObjectContent(buf.String()), nil
 }
 
-// Hash returns the SHA-1 hash of the tag
+// Hash returns the hash of the tag, using the algorithm of the tagged
+// object's hash
 func (t *Tag) Hash() (objects.ObjectHash, error) {
 	if t.hash != nil {
 		return *t.hash, nil
@@ -130,16 +131,16 @@ func (t *Tag) Hash() (objects.ObjectHash, error) {
 		return "", fmt.Errorf("failed to get content: %w", err)
 	}
 
-	hash := objects.ComputeObjectHash(objects.TagType, content)
+	hash := t.ObjectSHA.Algorithm().HashObject(objects.TagType, content)
 	t.hash = &hash
 	return hash, nil
 }
 
-// RawHash returns the SHA-1 hash as a 20-byte array
+// RawHash returns the hash as raw bytes
 func (t *Tag) RawHash() (objects.RawHash, error) {
 	hash, err := t.Hash()
 	if err != nil {
-		return objects.RawHash{}, err
+		return nil, err
 	}
 	return hash.Raw()
 }
@@ -196,7 +197,7 @@ func ParseTag(data []byte) (*Tag, error) {
 		return nil, err
 	}
 
-	hash := objects.NewObjectHash(objects.SerializedObject(data))
+	hash := tag.ObjectSHA.Algorithm().Sum(data)
 	tag.hash = &hash
 	return tag, nil
 }
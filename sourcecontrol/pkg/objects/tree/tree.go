@@ -12,7 +12,7 @@ import (
 // Tree represents a Git tree object implementation
 //
 // A tree object represents a directory snapshot in Git. It contains entries for
-// files and subdirectories, each with their mode, name, and SHA-1 hash.
+// files and subdirectories, each with their mode, name, and hash.
 //
 // Tree Object Structure:
 // ┌─────────────────────────────────────────────────────────────────┐
@@ -23,6 +23,11 @@ import (
 // │ Entry N: mode SPACE name NULL [20-byte SHA-1]                   │
 // └─────────────────────────────────────────────────────────────────┘
 //
+// In SHA-256 repositories the binary hashes are 32 bytes. The width cannot
+// be recovered from the bytes themselves, so such trees are read with
+// ParseTreeWithAlgorithm. A tree built in memory hashes with the algorithm
+// of its entries.
+//
 // Example tree object content (without header):
 // "100644 README.md\0[20 bytes]040000 src\0[20 bytes]100755 build.sh\0[20 bytes]"
 //
@@ -38,8 +43,9 @@ import (
 // - Directories are treated as if they have a trailing "/"
 // - This ensures that "file" comes before "file.txt" and "dir/" comes before "dir2"
 type Tree struct {
-	entries []*TreeEntry
-	hash    *objects.ObjectHash
+	entries   []*TreeEntry
+	algorithm objects.HashAlgorithm
+	hash      *objects.ObjectHash
 }
 
 // NewTree creates a new Tree object with the given entries
@@ -59,25 +65,32 @@ func NewEmptyTree() *Tree {
 	}
 }
 
-// ParseTree parses a tree object from serialized data (with header)
+// ParseTree parses a SHA-1 tree object from serialized data (with header)
 func ParseTree(data []byte) (*Tree, error) {
+	return ParseTreeWithAlgorithm(data, objects.SHA1)
+}
+
+// ParseTreeWithAlgorithm parses a tree object whose entry hashes, and own
+// hash, use the given algorithm
+func ParseTreeWithAlgorithm(data []byte, algorithm objects.HashAlgorithm) (*Tree, error) {
 	content, err := objects.ParseSerializedObject(data, objects.TreeType)
 	if err != nil {
 		return nil, err
 	}
 
-	entries, err := parseEntries(content.Bytes())
+	entries, err := parseEntries(content.Bytes(), algorithm)
 	if err != nil {
 		return nil, err
 	}
 
 	tree := &Tree{
-		entries: entries,
-		hash:    nil,
+		entries:   entries,
+		algorithm: algorithm,
+		hash:      nil,
 	}
 	tree.sortEntries()
 
-	hash := objects.NewObjectHash(objects.SerializedObject(data))
+	hash := algorithm.Sum(data)
 	tree.hash = &hash
 
 	return tree, nil
@@ -97,7 +110,7 @@ func (t *Tree) Content() (objects.ObjectContent, error) {
 	return objects.ObjectContent(data), nil
 }
 
-// Hash returns the SHA-1 hash of the tree
+// Hash returns the hash of the tree
 func (t *Tree) Hash() (objects.ObjectHash, error) {
 	if t.hash != nil {
 		return *t.hash, nil
@@ -108,16 +121,28 @@ func (t *Tree) Hash() (objects.ObjectHash, error) {
 		return "", fmt.Errorf("failed to get content: %w", err)
 	}
 
-	hash := objects.ComputeObjectHash(objects.TreeType, content)
+	hash := t.hashAlgorithm().HashObject(objects.TreeType, content)
 	t.hash = &hash
 	return hash, nil
 }
 
-// RawHash returns the SHA-1 hash as a 20-byte array
+// hashAlgorithm returns the algorithm the tree was parsed with, or else the
+// one its entries use. An empty tree built in memory hashes with SHA-1.
+func (t *Tree) hashAlgorithm() objects.HashAlgorithm {
+	if t.algorithm != "" {
+		return t.algorithm
+	}
+	if len(t.entries) > 0 {
+		return t.entries[0].sha.Algorithm()
+	}
+	return objects.SHA1
+}
+
+// RawHash returns the hash as raw bytes
 func (t *Tree) RawHash() (objects.RawHash, error) {
 	hash, err := t.Hash()
 	if err != nil {
-		return objects.RawHash{}, err
+		return nil, err
 	}
 	return hash.Raw()
 }
@@ -169,10 +194,12 @@ func (t *Tree) IsEmpty() bool {
 	return len(t.entries) == 0
 }
 
-// sortEntries sorts the entries according to Git's sorting rules
+// sortEntries sorts the entries according to Git's sorting rules: by name,
+// with directory names compared as if they ended in '/', so that the file
+// "foo.c" comes before the directory "foo".
 func (t *Tree) sortEntries() {
-	sort.Slice(t.entries, func(i, j int) bool {
-		return t.entries[i].CompareTo(t.entries[j]) < 0
+	sort.SliceStable(t.entries, func(i, j int) bool {
+		return t.entries[i].sortKey() < t.entries[j].sortKey()
 	})
 }
 
@@ -192,13 +219,13 @@ func (t *Tree) serializeContent() ([]byte, error) {
 }
 
 // parseEntries parses tree entries from content
-func parseEntries(content []byte) ([]*TreeEntry, error) {
+func parseEntries(content []byte, algorithm objects.HashAlgorithm) ([]*TreeEntry, error) {
 	var entries []*TreeEntry
 	reader := bytes.NewReader(content)
 
 	for reader.Len() > 0 {
 		e := &TreeEntry{}
-		err := e.Deserialize(reader)
+		err := e.DeserializeWithAlgorithm(reader, algorithm)
 		if err != nil {
 			if err == io.EOF {
 				break
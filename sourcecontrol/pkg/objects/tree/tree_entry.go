@@ -15,7 +15,7 @@ import (
 // Each entry contains:
 // - mode: File permissions and type (FileMode)
 // - name: Filename or directory name (RelativePath)
-// - sha: SHA-1 hash of the referenced object (ObjectHash)
+// - sha: Hash of the referenced object (ObjectHash)
 //
 // Entry types by mode:
 // - 040000: Directory (tree object)
@@ -25,7 +25,9 @@ import (
 // - 160000: Git submodule (commit object)
 //
 // Serialized format in tree object:
-// [mode] [space] [filename] [null byte] [20-byte SHA-1 binary]
+// [mode] [space] [filename] [null byte] [binary hash]
+//
+// The binary hash is 20 bytes in SHA-1 repositories and 32 in SHA-256 ones.
 //
 // Example serialized entry for "hello.txt" file:
 // "100644 hello.txt\0[20 bytes of SHA-1]"
@@ -98,7 +100,7 @@ func (e *TreeEntry) Path() scpath.RelativePath {
 	return e.name
 }
 
-// SHA returns the entry SHA-1 hash
+// SHA returns the hash of the referenced object
 func (e *TreeEntry) SHA() objects.ObjectHash {
 	return e.sha
 }
@@ -129,9 +131,12 @@ func (e *TreeEntry) IsSubmodule() bool {
 }
 
 // Serialize writes the serialized entry to the provided writer
-// Format: [mode] [space] [filename] [null byte] [20-byte SHA-1 binary]
+// Format: [mode] [space] [filename] [null byte] [binary hash]
+//
+// Modes are written in octal without padding, as Git writes them: a
+// directory is "40000", not the "040000" that ls-tree displays.
 func (e *TreeEntry) Serialize(w io.Writer) error {
-	if _, err := fmt.Fprintf(w, "%s %s%c", e.mode.ToOctalString(), e.name.String(), objects.NullByte); err != nil {
+	if _, err := fmt.Fprintf(w, "%o %s%c", uint32(e.mode), e.name.String(), objects.NullByte); err != nil {
 		return fmt.Errorf("failed to write entry header: %w", err)
 	}
 
@@ -167,9 +172,23 @@ func (e *TreeEntry) CompareTo(other *TreeEntry) int {
 	return 1
 }
 
+// sortKey is the name Git orders tree entries by.
+func (e *TreeEntry) sortKey() string {
+	if e.IsDirectory() {
+		return e.name.String() + "/"
+	}
+	return e.name.String()
+}
+
 // Deserialize reads and creates a TreeEntry from an io.Reader
 // Returns the created entry or an error if parsing fails
 func (e *TreeEntry) Deserialize(r io.Reader) error {
+	return e.DeserializeWithAlgorithm(r, objects.SHA1)
+}
+
+// DeserializeWithAlgorithm reads a TreeEntry whose binary hash has the width
+// of the given algorithm
+func (e *TreeEntry) DeserializeWithAlgorithm(r io.Reader, algorithm objects.HashAlgorithm) error {
 	mode, err := readUntil(r, objects.SpaceByte)
 	if err != nil {
 		return fmt.Errorf("invalid tree entry: failed to read mode: %w", err)
@@ -180,7 +199,7 @@ func (e *TreeEntry) Deserialize(r io.Reader) error {
 		return fmt.Errorf("invalid tree entry: failed to read name: %w", err)
 	}
 
-	shaBytes := make([]byte, objects.RawHashLength)
+	shaBytes := make([]byte, algorithm.Size())
 	if _, err := io.ReadFull(r, shaBytes); err != nil {
 		return fmt.Errorf("invalid tree entry: incomplete SHA: %w", err)
 	}
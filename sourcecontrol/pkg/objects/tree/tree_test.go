@@ -271,8 +271,9 @@ func TestTreeEntrySorting(t *testing.T) {
 
 	tree := NewTree(entries)
 
-	// Verify sorted order: a, a.sh, b.txt, c, z.txt
-	expectedOrder := []string{"a", "a.sh", "b.txt", "c", "z.txt"}
+	// Verify Git's order, which compares the directory "a" as "a/" and so
+	// puts it after "a.sh": a.sh, a, b.txt, c, z.txt
+	expectedOrder := []string{"a.sh", "a", "b.txt", "c", "z.txt"}
 	for i, expectedName := range expectedOrder {
 		if tree.Entries()[i].Name().String() != expectedName {
 			t.Errorf("Entry %d name = %v, want %v", i, tree.Entries()[i].Name(), expectedName)
@@ -392,6 +393,39 @@ func TestTreeRoundTrip(t *testing.T) {
 	}
 }
 
+func TestTreeRoundTripSHA256(t *testing.T) {
+	blobSHA := objects.SHA256.HashObject(objects.BlobType, objects.ObjectContent("hello\n"))
+	entry, err := NewTreeEntryFromStrings("100644", "hello.txt", blobSHA.String())
+	if err != nil {
+		t.Fatalf("NewTreeEntryFromStrings() error = %v", err)
+	}
+	original := NewTree([]*TreeEntry{entry})
+
+	var buf bytes.Buffer
+	if err := original.Serialize(&buf); err != nil {
+		t.Fatalf("Serialize() error = %v", err)
+	}
+
+	parsed, err := ParseTreeWithAlgorithm(buf.Bytes(), objects.SHA256)
+	if err != nil {
+		t.Fatalf("ParseTreeWithAlgorithm() error = %v", err)
+	}
+	if got := parsed.Entries()[0].SHA(); got != blobSHA {
+		t.Errorf("entry SHA = %s, want %s", got, blobSHA)
+	}
+
+	originalHash, _ := original.Hash()
+	parsedHash, _ := parsed.Hash()
+	if len(originalHash) != 64 || parsedHash != originalHash {
+		t.Errorf("Hash() = %s after parsing, want SHA-256 %s", parsedHash, originalHash)
+	}
+
+	// Reading 32-byte hashes as 20-byte ones must not silently succeed
+	if _, err := ParseTree(buf.Bytes()); err == nil {
+		t.Error("ParseTree() should fail on a SHA-256 tree")
+	}
+}
+
 func TestTreeEmptySerialization(t *testing.T) {
 	tree := NewTree([]*TreeEntry{})
 
@@ -471,7 +505,7 @@ func TestParseEntriesWithInvalidData(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := parseEntries(tt.data)
+			_, err := parseEntries(tt.data, objects.SHA1)
 			if err == nil {
 				t.Error("parseEntries() expected error, got nil")
 			}
@@ -3,6 +3,7 @@ package objects
 import (
 	"bytes"
 	"compress/flate"
+	"compress/zlib"
 	"fmt"
 	"io"
 )
@@ -12,8 +13,11 @@ import (
 // for a tree it's the serialized entries, for a commit it's the commit metadata.
 type ObjectContent []byte
 
-// CompressedData represents DEFLATE-compressed data
-// Git stores all objects in compressed form using DEFLATE algorithm
+// CompressedData represents zlib-compressed data
+// Git stores all loose objects as zlib streams (RFC 1950): a two byte header,
+// the DEFLATE payload and an Adler-32 trailer. Older repositories written by
+// this tool contain raw DEFLATE data without the zlib wrapper; those are still
+// accepted by Decompress so they can be read and migrated.
 type CompressedData []byte
 
 // SerializedObject represents an object in Git's serialized format (with header)
@@ -44,7 +48,8 @@ func (oc ObjectContent) IsEmpty() bool {
 	return len(oc) == 0
 }
 
-// Compress compresses the content using DEFLATE algorithm
+// Compress compresses the content into a zlib stream, the format git uses
+// for loose objects.
 // Returns the compressed data or an error if compression fails
 func (oc ObjectContent) Compress() (CompressedData, error) {
 	if oc.IsEmpty() {
@@ -52,7 +57,7 @@ func (oc ObjectContent) Compress() (CompressedData, error) {
 	}
 
 	var buf bytes.Buffer
-	w, err := flate.NewWriter(&buf, flate.BestCompression)
+	w, err := zlib.NewWriterLevel(&buf, zlib.BestCompression)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create compressor: %w", err)
 	}
@@ -84,21 +89,88 @@ func (cd CompressedData) IsEmpty() bool {
 	return len(cd) == 0
 }
 
-// Decompress decompresses the DEFLATE-compressed data
+// HasZlibHeader reports whether the data starts with a valid zlib header:
+// compression method 8 (DEFLATE), a window size of at most 32K, no preset
+// dictionary, and a header checksum that is a multiple of 31.
+//
+// A raw DEFLATE stream can satisfy these checks by coincidence, so a true
+// result is a strong hint rather than a guarantee; Decompress falls back to
+// raw DEFLATE when the zlib stream turns out to be invalid.
+func (cd CompressedData) HasZlibHeader() bool {
+	if len(cd) < 2 {
+		return false
+	}
+
+	cmf, flg := cd[0], cd[1]
+	if cmf&0x0f != 8 || cmf>>4 > 7 {
+		return false
+	}
+	if flg&0x20 != 0 {
+		return false
+	}
+	return (uint16(cmf)<<8|uint16(flg))%31 == 0
+}
+
+// IsLegacyDeflate reports whether the data is a raw DEFLATE stream written by
+// older versions of this tool rather than a zlib stream.
+func (cd CompressedData) IsLegacyDeflate() bool {
+	if cd.IsEmpty() {
+		return false
+	}
+	if !cd.HasZlibHeader() {
+		return true
+	}
+	_, err := cd.inflateZlib()
+	return err != nil
+}
+
+// Decompress decompresses the zlib-compressed data.
+// Raw DEFLATE data from legacy repositories is detected and decompressed as well.
 // Returns the original content or an error if decompression fails
 func (cd CompressedData) Decompress() (ObjectContent, error) {
 	if cd.IsEmpty() {
 		return ObjectContent{}, nil
 	}
 
-	r := flate.NewReader(bytes.NewReader(cd))
+	if cd.HasZlibHeader() {
+		data, err := cd.inflateZlib()
+		if err == nil {
+			return data, nil
+		}
+	}
+
+	data, err := cd.inflateRaw()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress data: %w", err)
+	}
+
+	return data, nil
+}
+
+// inflateZlib decompresses the data as a zlib stream, verifying the Adler-32 trailer.
+func (cd CompressedData) inflateZlib() (ObjectContent, error) {
+	r, err := zlib.NewReader(bytes.NewReader(cd))
+	if err != nil {
+		return nil, err
+	}
 	defer r.Close()
 
 	data, err := io.ReadAll(r)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decompress data: %w", err)
+		return nil, err
 	}
+	return ObjectContent(data), nil
+}
+
+// inflateRaw decompresses the data as a raw DEFLATE stream without a zlib wrapper.
+func (cd CompressedData) inflateRaw() (ObjectContent, error) {
+	r := flate.NewReader(bytes.NewReader(cd))
+	defer r.Close()
 
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
 	return ObjectContent(data), nil
 }
 
@@ -0,0 +1,226 @@
+package patch
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/utkarsh5026/SourceControl/pkg/diff"
+	"github.com/utkarsh5026/SourceControl/pkg/index"
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/plumbing"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+	"github.com/utkarsh5026/SourceControl/pkg/store"
+)
+
+// binaryCheckSize is how much of a file is searched for a NUL byte to
+// decide whether it is binary, as Git does.
+const binaryCheckSize = 8000
+
+// Add runs patch mode over the differences between idx and the working
+// tree at root, staging the chosen hunks in idx, as add -p does.
+//
+// Only tracked files are offered: a modified file hunk by hunk and a
+// deleted one as a whole. Binary files, symbolic links and conflicted paths
+// are left out. Staged content is written to s; the caller saves idx.
+func Add(idx *index.Index, root scpath.RepositoryPath, s store.ObjectStore, opts Options) (*Result, error) {
+	var changes []*change
+	for _, entry := range idx.Entries {
+		if entry.Stage != 0 || idx.IsConflicted(entry.Path) || !opts.Pathspecs.Match(entry.Path.String()) {
+			continue
+		}
+		c, err := worktreeChange(idx, entry, root, s, opts.contextLines())
+		if err != nil {
+			return nil, err
+		}
+		if c != nil {
+			changes = append(changes, c)
+		}
+	}
+	return newSession(Stage, idx, s, opts).run(changes)
+}
+
+// worktreeChange compares a tracked file with its entry in idx, returning
+// nil if it is unchanged or cannot be shown as text.
+func worktreeChange(idx *index.Index, entry *index.Entry, root scpath.RepositoryPath, s store.ObjectStore, context int) (*change, error) {
+	path := entry.Path.String()
+	absPath := root.Join(path).String()
+
+	var old []byte
+	if !entry.IntentToAdd {
+		var err error
+		if old, err = readBlob(s, entry.BlobHash); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	info, err := os.Lstat(absPath)
+	if os.IsNotExist(err) || err == nil && info.IsDir() {
+		if isBinary(old) {
+			return nil, nil
+		}
+		return &change{
+			path:  path,
+			kind:  deleted,
+			mode:  entry.TreeMode(),
+			hunks: wholeFileHunks(old, nil, context),
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() || !entry.IntentToAdd && idx.MatchesStat(entry, info) {
+		return nil, nil
+	}
+
+	current, err := os.ReadFile(absPath)
+	if err != nil {
+		return nil, err
+	}
+	if !entry.IntentToAdd && s.HashAlgorithm().HashObject(objects.BlobType, current) == entry.BlobHash {
+		return nil, nil
+	}
+	return modifiedChange(path, old, current, old, entry, context), nil
+}
+
+// Reset runs patch mode over the differences between the tree HEAD points
+// to and idx, removing the chosen hunks from idx, as reset -p does. An
+// empty tree hash stands for an unborn branch, where everything in the
+// index is an addition.
+//
+// A modified file is offered hunk by hunk, and a file only one side has as
+// a whole. Binary files and conflicted paths are left out. Content is read
+// from and written to s; the caller saves idx.
+func Reset(idx *index.Index, s store.ObjectStore, treeHash objects.ObjectHash, opts Options) (*Result, error) {
+	head := make(map[string]*index.Entry)
+	if treeHash != "" {
+		var err error
+		if head, err = plumbing.FlattenTree(s, treeHash); err != nil {
+			return nil, fmt.Errorf("failed to read HEAD tree: %w", err)
+		}
+	}
+
+	paths := make(map[string]bool)
+	for path := range head {
+		paths[path] = true
+	}
+	for _, entry := range idx.Entries {
+		paths[entry.Path.String()] = true
+	}
+	sorted := make([]string, 0, len(paths))
+	for path := range paths {
+		if opts.Pathspecs.Match(path) && !idx.IsConflicted(scpath.RelativePath(path)) {
+			sorted = append(sorted, path)
+		}
+	}
+	sort.Strings(sorted)
+
+	var changes []*change
+	for _, path := range sorted {
+		entry, _ := idx.Get(scpath.RelativePath(path))
+		c, err := stagedChange(path, head[path], entry, s, opts.contextLines())
+		if err != nil {
+			return nil, err
+		}
+		if c != nil {
+			changes = append(changes, c)
+		}
+	}
+	return newSession(Unstage, idx, s, opts).run(changes)
+}
+
+// stagedChange compares the HEAD and index versions of a path, either of
+// which may be missing, returning nil if they match or cannot be shown as
+// text.
+func stagedChange(path string, headEntry, entry *index.Entry, s store.ObjectStore, context int) (*change, error) {
+	if entry != nil && (entry.Stage != 0 || entry.IntentToAdd) {
+		return nil, nil
+	}
+	if headEntry != nil && entry != nil && headEntry.BlobHash == entry.BlobHash {
+		return nil, nil
+	}
+
+	var old, staged []byte
+	var err error
+	if headEntry != nil {
+		if old, err = readBlob(s, headEntry.BlobHash); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if entry != nil {
+		if staged, err = readBlob(s, entry.BlobHash); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if isBinary(old) || isBinary(staged) {
+		return nil, nil
+	}
+
+	switch {
+	case headEntry == nil:
+		return &change{
+			path:  path,
+			kind:  added,
+			mode:  entry.TreeMode(),
+			hunks: wholeFileHunks(nil, staged, context),
+		}, nil
+	case entry == nil:
+		return &change{
+			path:  path,
+			kind:  deleted,
+			mode:  headEntry.TreeMode(),
+			hunks: wholeFileHunks(old, nil, context),
+			entry: headEntry,
+		}, nil
+	default:
+		return modifiedChange(path, old, staged, staged, entry, context), nil
+	}
+}
+
+// modifiedChange builds the change for a file whose content went from old
+// to new, with hunks to be applied to base and stored in entry.
+func modifiedChange(path string, old, new, base []byte, entry *index.Entry, context int) *change {
+	if isBinary(old) || isBinary(new) {
+		return nil
+	}
+	oldLines, newLines := diff.SplitLines(string(old)), diff.SplitLines(string(new))
+	hunks := diff.Hunks(oldLines, newLines, context)
+	if len(hunks) == 0 {
+		return nil
+	}
+	return &change{
+		path:  path,
+		kind:  modified,
+		hunks: hunks,
+		base:  diff.SplitLines(string(base)),
+		entry: entry,
+	}
+}
+
+// wholeFileHunks returns the hunks of a file's addition or deletion. An
+// empty file still gets one, with no lines, so that it is asked about.
+func wholeFileHunks(old, new []byte, context int) []*diff.Hunk {
+	hunks := diff.Hunks(diff.SplitLines(string(old)), diff.SplitLines(string(new)), context)
+	if len(hunks) == 0 {
+		return []*diff.Hunk{{}}
+	}
+	return hunks
+}
+
+// readBlob returns the content of a blob.
+func readBlob(s store.ObjectStore, hash objects.ObjectHash) ([]byte, error) {
+	r, _, err := s.OpenBlob(hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob %s: %w", hash.Short(), err)
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// isBinary reports whether content looks binary, holding a NUL byte near
+// its start.
+func isBinary(content []byte) bool {
+	return bytes.IndexByte(content[:min(len(content), binaryCheckSize)], 0) >= 0
+}
@@ -0,0 +1,73 @@
+package patch
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/utkarsh5026/SourceControl/pkg/diff"
+)
+
+// edit lets the user edit hunk h of c and returns the edited hunk, or nil
+// if the edit was abandoned, in which case h is asked about again. An
+// edited hunk that does not apply can be edited again.
+func (s *session) edit(c *change, h *diff.Hunk) (*diff.Hunk, error) {
+	text := h.String()
+	for {
+		edited, err := s.opts.Edit(s.editText(text))
+		if err != nil {
+			return nil, fmt.Errorf("failed to edit hunk: %w", err)
+		}
+
+		text = stripComments(edited)
+		hunk, err := diff.ParseHunk(text)
+		if err == nil && len(hunk.Lines) == 0 {
+			return nil, nil
+		}
+		if err == nil {
+			_, err = diff.Apply(c.base, s.oriented([]*diff.Hunk{hunk}))
+		}
+		if err == nil {
+			return hunk, nil
+		}
+
+		fmt.Fprintf(s.opts.Output, "error: %v\n", err)
+		answer, ok := s.prompt(`Your edited hunk does not apply. Edit again (saying "no" discards!) [y/n]? `)
+		if !ok || answer != 'y' {
+			return nil, nil
+		}
+	}
+}
+
+// editText returns the text the user edits for a hunk: the hunk followed
+// by a short guide in comment lines.
+func (s *session) editText(hunk string) string {
+	removable, droppable := "-", "+"
+	if s.mode == Unstage {
+		removable, droppable = "+", "-"
+	}
+
+	var b strings.Builder
+	b.WriteString("# Manual hunk edit mode -- see bottom for a quick guide.\n")
+	b.WriteString(hunk)
+	b.WriteString("# ---\n")
+	fmt.Fprintf(&b, "# To remove '%s' lines, make them ' ' lines (context).\n", removable)
+	fmt.Fprintf(&b, "# To remove '%s' lines, delete them.\n", droppable)
+	b.WriteString("# Lines starting with # will be removed.\n")
+	b.WriteString("#\n")
+	fmt.Fprintf(&b, "# If the patch applies cleanly, the edited hunk will immediately be\n# marked for %sing.\n", s.verb())
+	b.WriteString("# If it does not apply cleanly, you will be given an opportunity to\n")
+	b.WriteString("# edit again.  If all lines of the hunk are removed, then the edit is\n")
+	b.WriteString("# aborted and the hunk is left unchanged.\n")
+	return b.String()
+}
+
+// stripComments removes the lines starting with '#' from text.
+func stripComments(text string) string {
+	var b strings.Builder
+	for _, line := range diff.SplitLines(text) {
+		if !strings.HasPrefix(line, "#") {
+			b.WriteString(line)
+		}
+	}
+	return b.String()
+}
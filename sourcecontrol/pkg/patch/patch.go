@@ -0,0 +1,335 @@
+// Package patch implements patch mode, in which the changes to files are
+// presented hunk by hunk and only the chosen ones are staged (add -p) or
+// unstaged (reset -p).
+//
+// Choices are read a line at a time from an io.Reader, so a session can be
+// driven from a terminal or by a script of answers, one per line.
+package patch
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/utkarsh5026/SourceControl/pkg/diff"
+	"github.com/utkarsh5026/SourceControl/pkg/index"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+	"github.com/utkarsh5026/SourceControl/pkg/store"
+)
+
+// DefaultContextLines is the number of unchanged lines shown around each
+// change unless Options.ContextLines says otherwise.
+const DefaultContextLines = 3
+
+// Mode selects what a session does with the hunks that are chosen.
+type Mode int
+
+const (
+	// Stage applies chosen hunks of the working tree's changes to the index
+	Stage Mode = iota
+
+	// Unstage removes chosen hunks of the index's changes from the index,
+	// restoring what HEAD has
+	Unstage
+)
+
+// Options configures a patch mode session.
+type Options struct {
+	// Pathspecs limits the session to the files they select
+	Pathspecs index.Pathspecs
+
+	// Input supplies the answers to the session's prompts, one per line.
+	// The session ends, as if told to quit, when it runs out.
+	Input io.Reader
+
+	// Output receives the hunks and prompts
+	Output io.Writer
+
+	// Edit lets the user edit a hunk for the e action: it is given the text
+	// to edit and returns the edited text. The e action is not offered when
+	// Edit is nil.
+	Edit func(text string) (string, error)
+
+	// ContextLines is the number of unchanged lines around each change;
+	// zero means DefaultContextLines
+	ContextLines int
+}
+
+// Result reports what a session did.
+type Result struct {
+	// Offered is the number of files that had changes to choose from
+	Offered int
+
+	// Updated holds the paths whose index entry was changed
+	Updated []string
+}
+
+// changeKind is how a file differs between the two sides being compared.
+type changeKind int
+
+const (
+	modified changeKind = iota
+	added
+	deleted
+)
+
+// change is one file's difference, shown as a diff from the old side to
+// the new one.
+type change struct {
+	path  string
+	kind  changeKind
+	mode  index.FileMode // tree mode, for the header of whole-file changes
+	hunks []*diff.Hunk
+
+	// base holds the lines of the index version, which chosen hunks are
+	// applied to; the old side when staging, the new side when unstaging
+	base []string
+
+	// entry is the index entry to update for a modified file, or the entry
+	// to put in the index when a whole-file change is chosen (nil to remove
+	// the path)
+	entry *index.Entry
+}
+
+// session runs patch mode over a list of changes.
+type session struct {
+	mode  Mode
+	idx   *index.Index
+	store store.ObjectStore
+	opts  Options
+	in    *bufio.Reader
+	quit  bool
+}
+
+func newSession(mode Mode, idx *index.Index, s store.ObjectStore, opts Options) *session {
+	return &session{
+		mode:  mode,
+		idx:   idx,
+		store: s,
+		opts:  opts,
+		in:    bufio.NewReader(opts.Input),
+	}
+}
+
+// contextLines returns the number of context lines hunks are computed with.
+func (o Options) contextLines() int {
+	if o.ContextLines <= 0 {
+		return DefaultContextLines
+	}
+	return o.ContextLines
+}
+
+// run asks which hunks of each change to apply and updates the index with
+// them, stopping early if the user quits.
+func (s *session) run(changes []*change) (*Result, error) {
+	result := &Result{Offered: len(changes), Updated: make([]string, 0)}
+	for _, c := range changes {
+		if s.quit {
+			break
+		}
+
+		chosen, err := s.choose(c)
+		if err != nil {
+			return result, err
+		}
+		if len(chosen) == 0 {
+			continue
+		}
+		if err := s.apply(c, chosen); err != nil {
+			return result, fmt.Errorf("%s: %w", c.path, err)
+		}
+		result.Updated = append(result.Updated, c.path)
+	}
+	return result, nil
+}
+
+// choose shows the change hunk by hunk and returns the hunks chosen, edited
+// ones included.
+func (s *session) choose(c *change) ([]*diff.Hunk, error) {
+	s.writeFileHeader(c)
+
+	hunks := append([]*diff.Hunk(nil), c.hunks...)
+	var chosen []*diff.Hunk
+	for i := 0; i < len(hunks); i++ {
+		for decided := false; !decided; {
+			h := hunks[i]
+			if len(h.Lines) > 0 {
+				fmt.Fprint(s.opts.Output, h.String())
+			}
+
+			answer, ok := s.prompt(fmt.Sprintf("(%d/%d) %s [%s]? ", i+1, len(hunks), s.question(c), s.actions(c, h)))
+			if !ok {
+				s.quit = true
+				return chosen, nil
+			}
+
+			switch answer {
+			case 'y':
+				chosen = append(chosen, h)
+				decided = true
+			case 'n':
+				decided = true
+			case 'a':
+				return append(chosen, hunks[i:]...), nil
+			case 'd':
+				return chosen, nil
+			case 'q':
+				s.quit = true
+				return chosen, nil
+			case 's':
+				if !s.canSplit(c, h) {
+					fmt.Fprintln(s.opts.Output, "Sorry, cannot split this hunk")
+					continue
+				}
+				parts := h.Split()
+				fmt.Fprintf(s.opts.Output, "Split into %d hunks.\n", len(parts))
+				hunks = append(hunks[:i], append(parts, hunks[i+1:]...)...)
+			case 'e':
+				if !s.canEdit(c) {
+					fmt.Fprintln(s.opts.Output, "Sorry, cannot edit this hunk")
+					continue
+				}
+				edited, err := s.edit(c, h)
+				if err != nil {
+					return nil, err
+				}
+				if edited != nil {
+					chosen = append(chosen, edited)
+					decided = true
+				}
+			default:
+				s.writeHelp()
+			}
+		}
+	}
+	return chosen, nil
+}
+
+// prompt asks question and returns the first character of the answer,
+// lowercased, skipping empty answers. It reports false at the end of input.
+func (s *session) prompt(question string) (byte, bool) {
+	for {
+		fmt.Fprint(s.opts.Output, question)
+		line, err := s.in.ReadString('\n')
+		if answer := strings.TrimSpace(line); answer != "" {
+			return strings.ToLower(answer)[0], true
+		}
+		if err != nil {
+			fmt.Fprintln(s.opts.Output)
+			return 0, false
+		}
+	}
+}
+
+func (s *session) verb() string {
+	if s.mode == Unstage {
+		return "unstage"
+	}
+	return "stage"
+}
+
+// question returns the prompt for a hunk of c, such as "Stage this hunk".
+func (s *session) question(c *change) string {
+	prefix := "Stage"
+	if s.mode == Unstage {
+		prefix = "Unstage"
+	}
+	switch c.kind {
+	case added:
+		return prefix + " addition"
+	case deleted:
+		return prefix + " deletion"
+	default:
+		return prefix + " this hunk"
+	}
+}
+
+// actions lists the answers available for hunk h of c.
+func (s *session) actions(c *change, h *diff.Hunk) string {
+	actions := "y,n,q,a,d"
+	if s.canSplit(c, h) {
+		actions += ",s"
+	}
+	if s.canEdit(c) {
+		actions += ",e"
+	}
+	return actions + ",?"
+}
+
+func (s *session) canSplit(c *change, h *diff.Hunk) bool {
+	return c.kind == modified && h.CanSplit()
+}
+
+func (s *session) canEdit(c *change) bool {
+	return c.kind == modified && s.opts.Edit != nil
+}
+
+func (s *session) writeHelp() {
+	verb := s.verb()
+	fmt.Fprintf(s.opts.Output, `y - %[1]s this hunk
+n - do not %[1]s this hunk
+q - quit; do not %[1]s this hunk or any of the remaining ones
+a - %[1]s this hunk and all later hunks in the file
+d - do not %[1]s this hunk or any of the later hunks in the file
+s - split the current hunk into smaller hunks
+e - manually edit the current hunk
+? - print help
+`, verb)
+}
+
+// writeFileHeader prints the diff header of c's file.
+func (s *session) writeFileHeader(c *change) {
+	oldName, newName := "a/"+c.path, "b/"+c.path
+	fmt.Fprintf(s.opts.Output, "diff --git %s %s\n", oldName, newName)
+	switch c.kind {
+	case added:
+		fmt.Fprintf(s.opts.Output, "new file mode %s\n", c.mode.ToOctalString())
+		oldName = "/dev/null"
+	case deleted:
+		fmt.Fprintf(s.opts.Output, "deleted file mode %s\n", c.mode.ToOctalString())
+		newName = "/dev/null"
+	}
+	fmt.Fprintf(s.opts.Output, "--- %s\n+++ %s\n", oldName, newName)
+}
+
+// apply updates the index with the chosen hunks of c.
+func (s *session) apply(c *change, chosen []*diff.Hunk) error {
+	if c.kind != modified {
+		if c.entry == nil {
+			s.idx.Remove(scpath.RelativePath(c.path))
+		} else {
+			s.idx.Add(c.entry)
+		}
+		return nil
+	}
+
+	lines, err := diff.Apply(c.base, s.oriented(chosen))
+	if err != nil {
+		return err
+	}
+	content := strings.Join(lines, "")
+	hash, err := s.store.WriteBlobStream(strings.NewReader(content), int64(len(content)))
+	if err != nil {
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+
+	entry := index.NewEntry(c.entry.Path)
+	entry.Mode = c.entry.TreeMode()
+	entry.BlobHash = hash
+	s.idx.Add(entry)
+	return nil
+}
+
+// oriented returns hunks as they apply to a change's base: unchanged when
+// staging, reversed when unstaging.
+func (s *session) oriented(hunks []*diff.Hunk) []*diff.Hunk {
+	if s.mode == Stage {
+		return hunks
+	}
+	reversed := make([]*diff.Hunk, len(hunks))
+	for i, h := range hunks {
+		reversed[i] = h.Reverse()
+	}
+	return reversed
+}
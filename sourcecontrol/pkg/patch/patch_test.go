@@ -0,0 +1,265 @@
+package patch
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/utkarsh5026/SourceControl/pkg/index"
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/tree"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+	"github.com/utkarsh5026/SourceControl/pkg/store"
+)
+
+const original = "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+
+type testRepo struct {
+	t     *testing.T
+	root  scpath.RepositoryPath
+	store store.ObjectStore
+	idx   *index.Index
+}
+
+func newTestRepo(t *testing.T) *testRepo {
+	t.Helper()
+	return &testRepo{
+		t:     t,
+		root:  scpath.RepositoryPath(t.TempDir()),
+		store: store.NewMemoryObjectStore(),
+		idx:   index.NewIndex(),
+	}
+}
+
+func (r *testRepo) writeBlob(content string) objects.ObjectHash {
+	r.t.Helper()
+	hash, err := r.store.WriteBlobStream(strings.NewReader(content), int64(len(content)))
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	return hash
+}
+
+// stage records content for path in the index.
+func (r *testRepo) stage(path, content string) {
+	entry := index.NewEntry(scpath.RelativePath(path))
+	entry.BlobHash = r.writeBlob(content)
+	r.idx.Add(entry)
+}
+
+func (r *testRepo) writeFile(path, content string) {
+	r.t.Helper()
+	if err := os.WriteFile(filepath.Join(r.root.String(), path), []byte(content), 0644); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+// staged returns the index content of path, or "<none>" if not staged.
+func (r *testRepo) staged(path string) string {
+	r.t.Helper()
+	entry, ok := r.idx.Get(scpath.RelativePath(path))
+	if !ok {
+		return "<none>"
+	}
+	content, err := readBlob(r.store, entry.BlobHash)
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	return string(content)
+}
+
+// tree writes a flat tree of files and returns its hash.
+func (r *testRepo) tree(files map[string]string) objects.ObjectHash {
+	r.t.Helper()
+	var entries []*tree.TreeEntry
+	for name, content := range files {
+		entry, err := tree.NewTreeEntry(objects.FileModeRegular, scpath.RelativePath(name), r.writeBlob(content))
+		if err != nil {
+			r.t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+	hash, err := r.store.WriteObject(tree.NewTree(entries))
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	return hash
+}
+
+func options(script string, output io.Writer) Options {
+	return Options{Input: strings.NewReader(script), Output: output}
+}
+
+func TestAdd_ChosenHunks(t *testing.T) {
+	r := newTestRepo(t)
+	r.stage("file.txt", original)
+	r.writeFile("file.txt", strings.Replace(strings.Replace(original, "1\n", "one\n", 1), "12\n", "twelve\n", 1))
+
+	var out strings.Builder
+	result, err := Add(r.idx, r.root, r.store, options("y\nn\n", &out))
+	if err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+
+	if want := strings.Replace(original, "1\n", "one\n", 1); r.staged("file.txt") != want {
+		t.Errorf("staged %q, want %q", r.staged("file.txt"), want)
+	}
+	if result.Offered != 1 || !reflect.DeepEqual(result.Updated, []string{"file.txt"}) {
+		t.Errorf("result = %+v", result)
+	}
+	for _, want := range []string{"diff --git a/file.txt b/file.txt", "@@ -1,4 +1,4 @@", "(1/2) Stage this hunk [y,n,q,a,d,?]? ", "(2/2)"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output lacks %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestAdd_Split(t *testing.T) {
+	r := newTestRepo(t)
+	r.stage("file.txt", original)
+	r.writeFile("file.txt", strings.Replace(strings.Replace(original, "2\n", "two\n", 1), "6\n", "six\n", 1))
+
+	var out strings.Builder
+	if _, err := Add(r.idx, r.root, r.store, options("s\nn\ny\n", &out)); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+
+	if want := strings.Replace(original, "6\n", "six\n", 1); r.staged("file.txt") != want {
+		t.Errorf("staged %q, want %q", r.staged("file.txt"), want)
+	}
+	if !strings.Contains(out.String(), "[y,n,q,a,d,s,?]") || !strings.Contains(out.String(), "Split into 2 hunks.") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+}
+
+func TestAdd_Edit(t *testing.T) {
+	r := newTestRepo(t)
+	r.stage("file.txt", "a\nb\n")
+	r.writeFile("file.txt", "a\nfirst\nsecond\nb\n")
+
+	var edited string
+	opts := options("e\n", io.Discard)
+	opts.Edit = func(text string) (string, error) {
+		edited = text
+		return strings.Replace(text, "+second\n", "", 1), nil
+	}
+	if _, err := Add(r.idx, r.root, r.store, opts); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+
+	if got, want := r.staged("file.txt"), "a\nfirst\nb\n"; got != want {
+		t.Errorf("staged %q, want %q", got, want)
+	}
+	if !strings.HasPrefix(edited, "# Manual hunk edit mode") || !strings.Contains(edited, "@@ -1,2 +1,4 @@\n a\n+first\n+second\n b\n") {
+		t.Errorf("unexpected edit text:\n%s", edited)
+	}
+}
+
+func TestAdd_EditThatDoesNotApply(t *testing.T) {
+	r := newTestRepo(t)
+	r.stage("file.txt", "a\nb\n")
+	r.writeFile("file.txt", "a\nnew\nb\n")
+
+	edits := 0
+	opts := options("e\ny\nn\n", io.Discard)
+	opts.Edit = func(text string) (string, error) {
+		edits++
+		return strings.Replace(text, " a\n", " x\n", 1), nil
+	}
+	if _, err := Add(r.idx, r.root, r.store, opts); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+
+	// Edited twice, then the hunk is declined
+	if edits != 2 {
+		t.Errorf("edited %d times, want 2", edits)
+	}
+	if got := r.staged("file.txt"); got != "a\nb\n" {
+		t.Errorf("staged %q, want it unchanged", got)
+	}
+}
+
+func TestAdd_DeletionQuitAndPathspecs(t *testing.T) {
+	r := newTestRepo(t)
+	r.stage("a.txt", "gone\n")
+	r.stage("b.txt", "old\n")
+	r.stage("c.txt", "old\n")
+	r.writeFile("b.txt", "new\n")
+	r.writeFile("c.txt", "new\n")
+
+	var out strings.Builder
+	result, err := Add(r.idx, r.root, r.store, options("y\nq\n", &out))
+	if err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+	if r.staged("a.txt") != "<none>" || r.staged("b.txt") != "old\n" || r.staged("c.txt") != "old\n" {
+		t.Errorf("unexpected index after quitting: a=%q b=%q", r.staged("a.txt"), r.staged("b.txt"))
+	}
+	if !strings.Contains(out.String(), "deleted file mode 100644") || !strings.Contains(out.String(), "Stage deletion [y,n,q,a,d,?]") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+	if result.Offered != 3 {
+		t.Errorf("Offered = %d, want 3", result.Offered)
+	}
+
+	// Running out of answers ends the session like q
+	specs, _ := index.ParsePathspecs([]string{"c.txt"})
+	opts := options("", io.Discard)
+	opts.Pathspecs = specs
+	if result, err = Add(r.idx, r.root, r.store, opts); err != nil || result.Offered != 1 || len(result.Updated) != 0 {
+		t.Errorf("Add() with no answers = %+v, %v", result, err)
+	}
+}
+
+func TestReset(t *testing.T) {
+	r := newTestRepo(t)
+	head := r.tree(map[string]string{"file.txt": original, "removed.txt": "x\n"})
+	staged := strings.Replace(strings.Replace(original, "1\n", "one\n", 1), "12\n", "twelve\n", 1)
+	r.stage("file.txt", staged)
+	r.stage("added.txt", "new\n")
+
+	var out strings.Builder
+	result, err := Reset(r.idx, r.store, head, options("y\nn\ny\ny\n", &out))
+	if err != nil {
+		t.Fatalf("Reset() failed: %v", err)
+	}
+
+	if r.staged("added.txt") != "<none>" {
+		t.Error("addition should be unstaged")
+	}
+	if want := strings.Replace(original, "1\n", "one\n", 1); r.staged("file.txt") != want {
+		t.Errorf("staged %q, want %q", r.staged("file.txt"), want)
+	}
+	if r.staged("removed.txt") != "x\n" {
+		t.Error("deletion should be unstaged")
+	}
+	if want := []string{"added.txt", "file.txt", "removed.txt"}; !reflect.DeepEqual(result.Updated, want) {
+		t.Errorf("Updated = %v, want %v", result.Updated, want)
+	}
+	for _, want := range []string{"Unstage addition", "Unstage this hunk", "Unstage deletion"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output lacks %q", want)
+		}
+	}
+}
+
+func TestReset_Edit(t *testing.T) {
+	r := newTestRepo(t)
+	head := r.tree(map[string]string{"file.txt": "a\nb\n"})
+	r.stage("file.txt", "a\nfirst\nsecond\nb\n")
+
+	opts := options("e\n", io.Discard)
+	opts.Edit = func(text string) (string, error) {
+		// Keep "first" staged by making it context
+		return strings.Replace(text, "+first\n", " first\n", 1), nil
+	}
+	if _, err := Reset(r.idx, r.store, head, opts); err != nil {
+		t.Fatalf("Reset() failed: %v", err)
+	}
+	if got, want := r.staged("file.txt"), "a\nfirst\nb\n"; got != want {
+		t.Errorf("staged %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,181 @@
+package plumbing
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/store"
+)
+
+// ObjectInfo is what cat-file -t, -s and --batch-check report.
+type ObjectInfo struct {
+	Hash objects.ObjectHash
+	Type objects.ObjectType
+	Size objects.ObjectSize
+}
+
+// Stat returns an object's type and size. Blobs are only opened, not read,
+// so stat-ing a large file is cheap.
+//
+// Returns store.ErrObjectNotFound if the object does not exist.
+func Stat(s store.ObjectStore, hash objects.ObjectHash) (ObjectInfo, error) {
+	rc, size, err := s.OpenBlob(hash)
+	switch {
+	case err == nil:
+		rc.Close()
+		return ObjectInfo{Hash: hash, Type: objects.BlobType, Size: objects.ObjectSize(size)}, nil
+	case !errors.Is(err, store.ErrNotBlob):
+		return ObjectInfo{}, err
+	}
+
+	serialized, err := readSerialized(s, hash)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	objType, size64, _, err := serialized.ParseHeader()
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	return ObjectInfo{Hash: hash, Type: objType, Size: size64}, nil
+}
+
+// readSerialized reads an object's stored bytes, turning a missing object
+// into store.ErrObjectNotFound.
+func readSerialized(s store.ObjectStore, hash objects.ObjectHash) (objects.SerializedObject, error) {
+	serialized, err := store.ReadSerialized(s, hash)
+	if err != nil {
+		return nil, err
+	}
+	if serialized == nil {
+		return nil, fmt.Errorf("%w: %s", store.ErrObjectNotFound, hash)
+	}
+	return serialized, nil
+}
+
+// WriteContent copies an object's content, exactly as stored and without its
+// header, to w. Blobs are streamed.
+func WriteContent(w io.Writer, s store.ObjectStore, hash objects.ObjectHash) error {
+	rc, _, err := s.OpenBlob(hash)
+	switch {
+	case err == nil:
+		defer rc.Close()
+		_, err = io.Copy(w, rc)
+		return err
+	case !errors.Is(err, store.ErrNotBlob):
+		return err
+	}
+
+	serialized, err := readSerialized(s, hash)
+	if err != nil {
+		return err
+	}
+	_, _, contentStart, err := serialized.ParseHeader()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(serialized[contentStart:])
+	return err
+}
+
+// PrettyPrint writes an object the way cat-file -p shows it: trees as one
+// "<mode> <type> <hash>\t<name>" line per entry, everything else as its
+// raw content.
+func PrettyPrint(w io.Writer, s store.ObjectStore, hash objects.ObjectHash) error {
+	info, err := Stat(s, hash)
+	if err != nil {
+		return err
+	}
+	if info.Type != objects.TreeType {
+		return WriteContent(w, s, hash)
+	}
+
+	serialized, err := readSerialized(s, hash)
+	if err != nil {
+		return err
+	}
+	_, _, contentStart, err := serialized.ParseHeader()
+	if err != nil {
+		return err
+	}
+	entries, err := ParseTreeEntries(serialized[contentStart:], s.HashAlgorithm())
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, entry := range entries {
+		fmt.Fprintln(bw, entry.String())
+	}
+	return bw.Flush()
+}
+
+// BatchOptions configures Batch.
+type BatchOptions struct {
+	// Contents writes each object's content after its info line (--batch);
+	// otherwise only the info line is written (--batch-check)
+	Contents bool
+}
+
+// Batch reads object names from r, one per line, and answers each on w:
+//
+//	<hash> <type> <size>\n            for every object found
+//	<content>\n                       followed by its content with --batch
+//	<name> missing\n                  when the name does not resolve
+//	<name> ambiguous\n                when an abbreviated hash is ambiguous
+//
+// Output is flushed after every object so that a caller can write a name
+// and wait for its answer over a pipe.
+func Batch(r io.Reader, w io.Writer, resolver *Resolver, opts BatchOptions) error {
+	bw := bufio.NewWriter(w)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), 1<<20)
+
+	for scanner.Scan() {
+		name := strings.TrimSpace(scanner.Text())
+		if err := batchOne(bw, resolver, name, opts); err != nil {
+			return err
+		}
+		if err := bw.Flush(); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// batchOne answers a single --batch or --batch-check request.
+func batchOne(w io.Writer, resolver *Resolver, name string, opts BatchOptions) error {
+	hash, err := resolver.Resolve(name)
+	if errors.Is(err, ErrAmbiguousRevision) {
+		_, err = fmt.Fprintf(w, "%s ambiguous\n", name)
+		return err
+	}
+	if err != nil {
+		_, err = fmt.Fprintf(w, "%s missing\n", name)
+		return err
+	}
+
+	info, err := Stat(resolver.store, hash)
+	if errors.Is(err, store.ErrObjectNotFound) {
+		_, err = fmt.Fprintf(w, "%s missing\n", name)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "%s %s %d\n", info.Hash, info.Type, info.Size); err != nil {
+		return err
+	}
+	if !opts.Contents {
+		return nil
+	}
+	if err := WriteContent(w, resolver.store, hash); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,115 @@
+package plumbing
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/store"
+)
+
+func TestStat(t *testing.T) {
+	repo := setupTestRepo(t)
+	h := writeTestHistory(t, repo)
+	s := repo.ObjectStore()
+
+	tests := []struct {
+		hash     objects.ObjectHash
+		wantType objects.ObjectType
+		wantSize objects.ObjectSize
+	}{
+		{h.readme, objects.BlobType, 6},
+		{h.subtree, objects.TreeType, objects.ObjectSize(len("100644 main.go\x00") + 20)},
+		{h.second, objects.CommitType, -1},
+		{h.tag, objects.TagType, -1},
+	}
+	for _, tt := range tests {
+		info, err := Stat(s, tt.hash)
+		if err != nil {
+			t.Fatalf("Stat(%s) failed: %v", tt.hash.Short(), err)
+		}
+		if info.Type != tt.wantType {
+			t.Errorf("Stat(%s).Type = %s, want %s", tt.hash.Short(), info.Type, tt.wantType)
+		}
+		if tt.wantSize >= 0 && info.Size != tt.wantSize {
+			t.Errorf("Stat(%s).Size = %d, want %d", tt.hash.Short(), info.Size, tt.wantSize)
+		}
+
+		var content bytes.Buffer
+		if err := WriteContent(&content, s, tt.hash); err != nil {
+			t.Fatal(err)
+		}
+		if objects.ObjectSize(content.Len()) != info.Size {
+			t.Errorf("content of %s has %d bytes, Stat reported %d", tt.hash.Short(), content.Len(), info.Size)
+		}
+	}
+
+	missing := objects.ObjectHash(strings.Repeat("1", 40))
+	if _, err := Stat(s, missing); !errors.Is(err, store.ErrObjectNotFound) {
+		t.Errorf("Stat(missing) err = %v, want ErrObjectNotFound", err)
+	}
+}
+
+func TestPrettyPrint(t *testing.T) {
+	repo := setupTestRepo(t)
+	h := writeTestHistory(t, repo)
+	s := repo.ObjectStore()
+
+	var out bytes.Buffer
+	if err := PrettyPrint(&out, s, h.tree); err != nil {
+		t.Fatal(err)
+	}
+	want := fmt.Sprintf("100644 blob %s\tREADME\n040000 tree %s\tsrc\n", h.readme, h.subtree)
+	if out.String() != want {
+		t.Errorf("PrettyPrint(tree) =\n%s\nwant\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	if err := PrettyPrint(&out, s, h.readme); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "hello\n" {
+		t.Errorf("PrettyPrint(blob) = %q, want %q", out.String(), "hello\n")
+	}
+
+	out.Reset()
+	if err := PrettyPrint(&out, s, h.second); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.String(), "tree "+h.tree.String()+"\nparent "+h.first.String()+"\n") {
+		t.Errorf("PrettyPrint(commit) = %q", out.String())
+	}
+}
+
+func TestBatch(t *testing.T) {
+	repo := setupTestRepo(t)
+	h := writeTestHistory(t, repo)
+	r := NewResolver(repo)
+
+	input := "HEAD:README\nnope\n" + h.tree.String() + "\n"
+
+	var check bytes.Buffer
+	if err := Batch(strings.NewReader(input), &check, r, BatchOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	info, err := Stat(repo.ObjectStore(), h.tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := fmt.Sprintf("%s blob 6\nnope missing\n%s tree %d\n", h.readme, h.tree, info.Size)
+	if check.String() != want {
+		t.Errorf("--batch-check output =\n%q\nwant\n%q", check.String(), want)
+	}
+
+	var full bytes.Buffer
+	if err := Batch(strings.NewReader("HEAD:README\nnope\n"), &full, r, BatchOptions{Contents: true}); err != nil {
+		t.Fatal(err)
+	}
+	want = fmt.Sprintf("%s blob 6\nhello\n\nnope missing\n", h.readme)
+	if full.String() != want {
+		t.Errorf("--batch output =\n%q\nwant\n%q", full.String(), want)
+	}
+}
@@ -0,0 +1,84 @@
+package plumbing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/commit"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/sourcerepo"
+)
+
+// CommitTreeOptions configures CommitTree.
+type CommitTreeOptions struct {
+	// Parents are the new commit's parents, in order (-p)
+	Parents []objects.ObjectHash
+
+	// Message is the commit message, stored as given (-m)
+	Message string
+
+	// Author and Committer default to Identity's answer when nil
+	Author    *commit.CommitPerson
+	Committer *commit.CommitPerson
+}
+
+// CommitTree creates a commit object for an existing tree, as commit-tree
+// does, and returns its hash. No ref is updated.
+//
+// The tree must be a tree and each parent a commit. A parent given twice
+// is only recorded once.
+func CommitTree(ctx context.Context, repo *sourcerepo.SourceRepository, treeHash objects.ObjectHash, opts CommitTreeOptions) (objects.ObjectHash, error) {
+	if err := expectType(repo, treeHash, objects.TreeType); err != nil {
+		return "", err
+	}
+
+	var parents []objects.ObjectHash
+	seen := make(map[objects.ObjectHash]bool, len(opts.Parents))
+	for _, parent := range opts.Parents {
+		if seen[parent] {
+			continue
+		}
+		seen[parent] = true
+		if err := expectType(repo, parent, objects.CommitType); err != nil {
+			return "", err
+		}
+		parents = append(parents, parent)
+	}
+
+	author, committer := opts.Author, opts.Committer
+	var err error
+	if author == nil {
+		if author, err = Identity(ctx, repo, Author); err != nil {
+			return "", err
+		}
+	}
+	if committer == nil {
+		if committer, err = Identity(ctx, repo, Committer); err != nil {
+			return "", err
+		}
+	}
+
+	c, err := commit.NewCommitBuilder().
+		TreeHash(treeHash).
+		ParentHashes(parents...).
+		Author(author).
+		Committer(committer).
+		Message(opts.Message).
+		Build()
+	if err != nil {
+		return "", fmt.Errorf("failed to build commit: %w", err)
+	}
+	return repo.WriteObject(c)
+}
+
+// expectType checks that hash names an object of the given type.
+func expectType(repo *sourcerepo.SourceRepository, hash objects.ObjectHash, want objects.ObjectType) error {
+	info, err := Stat(repo.ObjectStore(), hash)
+	if err != nil {
+		return err
+	}
+	if info.Type != want {
+		return fmt.Errorf("%s is not a valid '%s' object", hash, want)
+	}
+	return nil
+}
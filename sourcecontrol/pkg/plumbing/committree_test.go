@@ -0,0 +1,109 @@
+package plumbing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/utkarsh5026/SourceControl/pkg/index"
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+)
+
+func setIdentity(t *testing.T) {
+	t.Helper()
+	for _, role := range []string{"AUTHOR", "COMMITTER"} {
+		t.Setenv("GIT_"+role+"_NAME", "Test User")
+		t.Setenv("GIT_"+role+"_EMAIL", "test@example.com")
+		t.Setenv("GIT_"+role+"_DATE", "1700000000 +0000")
+	}
+}
+
+func TestCommitTree(t *testing.T) {
+	repo := setupTestRepo(t)
+	setIdentity(t)
+	writeWorktreeFile(t, repo, "README", "hello\n", 0644)
+
+	idx := index.NewIndex()
+	if err := UpdateIndex(repo, idx, relPaths("README"), UpdateIndexOptions{Add: true}); err != nil {
+		t.Fatal(err)
+	}
+	treeHash, err := WriteTree(context.Background(), repo, idx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// git commit-tree gives the same hash for this tree, identity and message
+	first, err := CommitTree(context.Background(), repo, treeHash, CommitTreeOptions{Message: "first\n"})
+	if err != nil {
+		t.Fatalf("CommitTree() failed: %v", err)
+	}
+	if first != "490aac634642812b41999faa6a524774536c6e4f" {
+		t.Errorf("CommitTree() = %s, want 490aac634642812b41999faa6a524774536c6e4f", first)
+	}
+
+	second, err := CommitTree(context.Background(), repo, treeHash, CommitTreeOptions{
+		Parents: []objects.ObjectHash{first, first},
+		Message: "second\n",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := repo.ReadCommitObject(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.ParentSHAs) != 1 || c.ParentSHAs[0] != first {
+		t.Errorf("parents = %v, want [%s]", c.ParentSHAs, first)
+	}
+
+	if _, err := CommitTree(context.Background(), repo, first, CommitTreeOptions{Message: "x\n"}); err == nil {
+		t.Error("CommitTree() should reject a commit as its tree")
+	}
+	if _, err := CommitTree(context.Background(), repo, treeHash, CommitTreeOptions{Parents: []objects.ObjectHash{treeHash}, Message: "x\n"}); err == nil {
+		t.Error("CommitTree() should reject a tree as a parent")
+	}
+}
+
+func TestWriteTree_Unmerged(t *testing.T) {
+	repo := setupTestRepo(t)
+	h := writeTestHistory(t, repo)
+
+	idx := index.NewIndex()
+	if err := idx.AddConflict("file", h.readme, h.main, h.readme); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := WriteTree(context.Background(), repo, idx); !errors.Is(err, ErrUnmergedEntries) {
+		t.Errorf("err = %v, want ErrUnmergedEntries", err)
+	}
+
+	idx = index.NewIndex()
+	if err := CacheInfo(repo.ObjectStore(), idx, objects.FileModeRegular, objects.ObjectHash("1234567890123456789012345678901234567890"), "missing", UpdateIndexOptions{Add: true, InfoOnly: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := WriteTree(context.Background(), repo, idx); err == nil {
+		t.Error("WriteTree() should reject an entry whose blob is missing")
+	}
+}
+
+func TestParseIdentityDate(t *testing.T) {
+	for _, value := range []string{
+		"1700000000 +0100",
+		"@1700000000 +0100",
+		"Tue, 14 Nov 2023 23:13:20 +0100",
+		"2023-11-14T23:13:20+01:00",
+		"2023-11-14 23:13:20 +0100",
+	} {
+		when, err := parseIdentityDate(value)
+		if err != nil {
+			t.Errorf("parseIdentityDate(%q) failed: %v", value, err)
+			continue
+		}
+		if _, offset := when.Zone(); when.Unix() != 1700000000 || offset != 3600 {
+			t.Errorf("parseIdentityDate(%q) = %v", value, when)
+		}
+	}
+
+	if _, err := parseIdentityDate("yesterday"); err == nil {
+		t.Error("parseIdentityDate() should reject an unknown format")
+	}
+}
@@ -0,0 +1,125 @@
+package plumbing
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/commit"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/tag"
+	"github.com/utkarsh5026/SourceControl/pkg/store"
+)
+
+// HashOptions configures HashObject.
+type HashOptions struct {
+	// Type is the type of object to create; empty means blob
+	Type objects.ObjectType
+
+	// Write stores the object in addition to hashing it (hash-object -w)
+	Write bool
+}
+
+// HashObject computes the hash of the object with the given content, as
+// hash-object does, and optionally writes it to s.
+//
+// Blobs of known size are streamed: pass the file size, or -1 if it is not
+// known (e.g. for stdin), in which case the content is read into memory.
+// Trees, commits and tags are parsed first and rejected if malformed, so a
+// script cannot store an object Git would refuse to read; their bytes are
+// stored exactly as given.
+//
+// Parameters:
+//   - s: The store to write to, and whose hash algorithm is used
+//   - r: The object's content, without a header
+//   - size: The content length, or -1 if unknown
+//   - opts: The object type and whether to write it
+//
+// Returns:
+//   - objects.ObjectHash: The object's hash
+//   - error: If the content is invalid for its type or cannot be stored
+func HashObject(s store.ObjectStore, r io.Reader, size int64, opts HashOptions) (objects.ObjectHash, error) {
+	objType := opts.Type
+	if objType == "" {
+		objType = objects.BlobType
+	}
+	if _, err := objects.ParseObjectType(objType.String()); err != nil {
+		return "", err
+	}
+	algorithm := s.HashAlgorithm()
+
+	if objType == objects.BlobType && size >= 0 {
+		if opts.Write {
+			return s.WriteBlobStream(r, size)
+		}
+		return algorithm.HashObjectFromReader(objType, r, size)
+	}
+
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to read content: %w", err)
+	}
+	if err := checkContent(objType, content, algorithm); err != nil {
+		return "", fmt.Errorf("corrupt %s: %w", objType, err)
+	}
+
+	obj := &rawObject{objType: objType, content: content, algorithm: algorithm}
+	if !opts.Write {
+		return obj.Hash()
+	}
+	return s.WriteObject(obj)
+}
+
+// checkContent parses content as an object of the given type.
+func checkContent(objType objects.ObjectType, content []byte, algorithm objects.HashAlgorithm) error {
+	serialized := objects.NewSerializedObject(objType, content)
+
+	var err error
+	switch objType {
+	case objects.TreeType:
+		_, err = ParseTreeEntries(content, algorithm)
+	case objects.CommitType:
+		_, err = commit.ParseCommit(serialized)
+	case objects.TagType:
+		_, err = tag.ParseTag(serialized)
+	}
+	return err
+}
+
+// rawObject is an object of any type whose content is written out exactly
+// as given, without being parsed and serialized again.
+type rawObject struct {
+	objType   objects.ObjectType
+	content   objects.ObjectContent
+	algorithm objects.HashAlgorithm
+}
+
+func (o *rawObject) Type() objects.ObjectType {
+	return o.objType
+}
+
+func (o *rawObject) Content() (objects.ObjectContent, error) {
+	return o.content, nil
+}
+
+func (o *rawObject) Hash() (objects.ObjectHash, error) {
+	return o.algorithm.HashObject(o.objType, o.content), nil
+}
+
+func (o *rawObject) RawHash() (objects.RawHash, error) {
+	hash, _ := o.Hash()
+	return hash.Raw()
+}
+
+func (o *rawObject) Size() (objects.ObjectSize, error) {
+	return o.content.Size(), nil
+}
+
+func (o *rawObject) Serialize(w io.Writer) error {
+	_, err := w.Write(objects.NewSerializedObject(o.objType, o.content))
+	return err
+}
+
+func (o *rawObject) String() string {
+	hash, _ := o.Hash()
+	return fmt.Sprintf("%s %s", o.objType, hash.Short())
+}
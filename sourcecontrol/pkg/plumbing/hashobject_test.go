@@ -0,0 +1,71 @@
+package plumbing
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+)
+
+func TestHashObject(t *testing.T) {
+	commitContent := "tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n" +
+		"author A <a@b> 1700000000 +0000\n" +
+		"committer A <a@b> 1700000000 +0000\n\nmsg\n"
+
+	// Hashes computed with git hash-object
+	tests := []struct {
+		name    string
+		content string
+		size    int64
+		opts    HashOptions
+		want    objects.ObjectHash
+	}{
+		{"blob with size", "hello\n", 6, HashOptions{}, "ce013625030ba8dba906f756967f9e9ca394464a"},
+		{"blob from stdin", "hello\n", -1, HashOptions{}, "ce013625030ba8dba906f756967f9e9ca394464a"},
+		{"empty tree", "", -1, HashOptions{Type: objects.TreeType}, "4b825dc642cb6eb9a060e54bf8d69288fbee4904"},
+		{"commit", commitContent, -1, HashOptions{Type: objects.CommitType}, "13f1928a1227b158b5a71b030f7c9dc79d102a5c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := setupTestRepo(t).ObjectStore()
+			got, err := HashObject(s, strings.NewReader(tt.content), tt.size, tt.opts)
+			if err != nil {
+				t.Fatalf("HashObject() failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("HashObject() = %s, want %s", got, tt.want)
+			}
+			if has, _ := s.HasObject(got); has {
+				t.Errorf("HashObject() without Write stored %s", got)
+			}
+
+			tt.opts.Write = true
+			written, err := HashObject(s, strings.NewReader(tt.content), tt.size, tt.opts)
+			if err != nil || written != tt.want {
+				t.Fatalf("HashObject(write) = %s, %v; want %s", written, err, tt.want)
+			}
+			var stored bytes.Buffer
+			if err := WriteContent(&stored, s, written); err != nil {
+				t.Fatal(err)
+			}
+			if stored.String() != tt.content {
+				t.Errorf("stored content = %q, want %q", stored.String(), tt.content)
+			}
+		})
+	}
+}
+
+func TestHashObject_RejectsMalformed(t *testing.T) {
+	repo := setupTestRepo(t)
+
+	for _, objType := range []objects.ObjectType{objects.TreeType, objects.CommitType, objects.TagType} {
+		if _, err := HashObject(repo.ObjectStore(), strings.NewReader("garbage"), -1, HashOptions{Type: objType}); err == nil {
+			t.Errorf("HashObject(-t %s) accepted malformed content", objType)
+		}
+	}
+	if _, err := HashObject(repo.ObjectStore(), strings.NewReader("x"), -1, HashOptions{Type: "bogus"}); err == nil {
+		t.Error("HashObject accepted an unknown type")
+	}
+}
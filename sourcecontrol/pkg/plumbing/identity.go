@@ -0,0 +1,120 @@
+package plumbing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/utkarsh5026/SourceControl/pkg/config"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/commit"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/sourcerepo"
+)
+
+// ErrUnknownIdentity is returned when neither the environment nor the
+// configuration names the author or committer.
+var ErrUnknownIdentity = errors.New("identity unknown: set user.name and user.email")
+
+// Role selects the person Identity describes.
+type Role string
+
+const (
+	Author    Role = "AUTHOR"
+	Committer Role = "COMMITTER"
+)
+
+// Identity returns the author or committer to record in a new object. As in
+// Git, GIT_<ROLE>_NAME, GIT_<ROLE>_EMAIL and GIT_<ROLE>_DATE take precedence
+// over user.name and user.email, and the date defaults to now.
+//
+// GIT_<ROLE>_DATE may be "<unix seconds> <+hhmm>" (optionally prefixed by
+// '@'), RFC 2822 or ISO 8601.
+func Identity(ctx context.Context, repo *sourcerepo.SourceRepository, role Role) (*commit.CommitPerson, error) {
+	name := os.Getenv("GIT_" + string(role) + "_NAME")
+	email := os.Getenv("GIT_" + string(role) + "_EMAIL")
+
+	if name == "" || email == "" {
+		configMgr := config.NewManager(repo.WorkingDirectory())
+		if err := configMgr.Load(ctx); err != nil {
+			return nil, fmt.Errorf("failed to load config: %w", err)
+		}
+		typedConfig := config.NewTypedConfig(configMgr)
+		if name == "" {
+			name = typedConfig.UserName()
+		}
+		if email == "" {
+			email = typedConfig.UserEmail()
+		}
+	}
+	if name == "" || email == "" {
+		return nil, fmt.Errorf("%s %w", strings.ToLower(string(role)), ErrUnknownIdentity)
+	}
+
+	when := time.Now()
+	if date := os.Getenv("GIT_" + string(role) + "_DATE"); date != "" {
+		parsed, err := parseIdentityDate(date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid GIT_%s_DATE: %w", role, err)
+		}
+		when = parsed
+	}
+	return commit.NewCommitPerson(name, email, when)
+}
+
+// identityDateLayouts are the textual date formats parseIdentityDate accepts.
+var identityDateLayouts = []string{
+	time.RFC1123Z,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	time.RFC3339,
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02T15:04:05 -0700",
+}
+
+// parseIdentityDate parses a date in one of the formats Git accepts for
+// GIT_AUTHOR_DATE and GIT_COMMITTER_DATE.
+func parseIdentityDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	fields := strings.Fields(strings.TrimPrefix(value, "@"))
+
+	if len(fields) >= 1 && len(fields) <= 2 {
+		if seconds, err := strconv.ParseInt(fields[0], 10, 64); err == nil {
+			when := time.Unix(seconds, 0)
+			if len(fields) == 1 {
+				return when, nil
+			}
+			zone, err := parseZone(fields[1])
+			if err != nil {
+				return time.Time{}, err
+			}
+			return when.In(zone), nil
+		}
+	}
+
+	for _, layout := range identityDateLayouts {
+		if when, err := time.Parse(layout, value); err == nil {
+			return when, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date %q", value)
+}
+
+// parseZone parses a "+hhmm" or "-hhmm" offset.
+func parseZone(offset string) (*time.Location, error) {
+	if len(offset) != 5 || (offset[0] != '+' && offset[0] != '-') {
+		return nil, fmt.Errorf("invalid time zone %q", offset)
+	}
+	hours, err1 := strconv.Atoi(offset[1:3])
+	minutes, err2 := strconv.Atoi(offset[3:5])
+	if err1 != nil || err2 != nil {
+		return nil, fmt.Errorf("invalid time zone %q", offset)
+	}
+
+	seconds := hours*3600 + minutes*60
+	if offset[0] == '-' {
+		seconds = -seconds
+	}
+	return time.FixedZone("", seconds), nil
+}
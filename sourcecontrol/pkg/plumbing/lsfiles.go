@@ -0,0 +1,46 @@
+package plumbing
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/utkarsh5026/SourceControl/pkg/index"
+)
+
+// LsFilesOptions configures ListFiles.
+type LsFilesOptions struct {
+	// Stage shows each entry's mode, hash and stage number (--stage)
+	Stage bool
+
+	// Unmerged shows only the conflict stages of unmerged paths; it implies
+	// Stage (--unmerged)
+	Unmerged bool
+
+	// NullTerminated ends records with NUL and leaves paths unquoted (-z)
+	NullTerminated bool
+}
+
+// ListFiles writes the entries of idx to w in index order, as ls-files does.
+// With Stage each line is
+//
+//	<mode> <hash> <stage>\t<path>
+//
+// otherwise it is just the path. An unmerged path is listed once per stage.
+func ListFiles(w io.Writer, idx *index.Index, opts LsFilesOptions) error {
+	bw := bufio.NewWriter(w)
+	for _, entry := range idx.Entries {
+		if opts.Unmerged && entry.Stage == 0 {
+			continue
+		}
+
+		prefix := ""
+		if opts.Stage || opts.Unmerged {
+			prefix = fmt.Sprintf("%s %s %d\t", entry.TreeMode().ToOctalString(), entry.BlobHash, entry.Stage)
+		}
+		if err := writeRecord(bw, prefix, entry.Path.String(), opts.NullTerminated); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,63 @@
+package plumbing
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/utkarsh5026/SourceControl/pkg/index"
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+)
+
+func TestListFiles(t *testing.T) {
+	repo := setupTestRepo(t)
+	h := writeTestHistory(t, repo)
+
+	idx := index.NewIndex()
+	input := "100644 " + h.readme.String() + "\tREADME\n" +
+		"100644 " + h.readme.String() + " 1\tboth\n" +
+		"100644 " + h.main.String() + " 2\tboth\n" +
+		"100644 " + h.readme.String() + "\ttab\there\n"
+	if err := IndexInfo(idx, strings.NewReader(input), objects.SHA1); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		opts LsFilesOptions
+		want string
+	}{
+		{"plain", LsFilesOptions{}, "README\nboth\nboth\n\"tab\\there\"\n"},
+		{"zero", LsFilesOptions{NullTerminated: true}, "README\x00both\x00both\x00tab\there\x00"},
+		{"unmerged", LsFilesOptions{Unmerged: true},
+			"100644 " + h.readme.String() + " 1\tboth\n" +
+				"100644 " + h.main.String() + " 2\tboth\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			if err := ListFiles(&out, idx, tt.opts); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("ListFiles() = %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestQuotePath(t *testing.T) {
+	tests := map[string]string{
+		"plain.txt":   "plain.txt",
+		"with space":  "with space",
+		"q\"t":        `"q\"t"`,
+		"back\\slash": `"back\\slash"`,
+		"new\nline":   `"new\nline"`,
+		"é":           `"\303\251"`,
+		"bell\a\x01":  `"bell\a\001"`,
+	}
+	for in, want := range tests {
+		if got := QuotePath(in); got != want {
+			t.Errorf("QuotePath(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package plumbing
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/store"
+)
+
+// LsTreeOptions configures ListTree.
+type LsTreeOptions struct {
+	// Recursive descends into subtrees, listing the blobs below them (-r)
+	Recursive bool
+
+	// ShowTrees also lists the subtrees being descended into (-t)
+	ShowTrees bool
+
+	// NameOnly lists only the paths (--name-only)
+	NameOnly bool
+
+	// NullTerminated ends records with NUL and leaves paths unquoted (-z)
+	NullTerminated bool
+}
+
+// ListTree writes the entries of a tree to w in storage order, as ls-tree
+// does, one "<mode> <type> <hash>\t<path>" line per entry.
+//
+// Without Recursive only the tree's own entries are listed. With it the
+// subtrees are replaced by their contents, with paths relative to the root,
+// and listed themselves only if ShowTrees is set.
+func ListTree(w io.Writer, s store.ObjectStore, treeHash objects.ObjectHash, opts LsTreeOptions) error {
+	bw := bufio.NewWriter(w)
+	if err := listTree(bw, s, treeHash, "", opts); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+func listTree(w io.Writer, s store.ObjectStore, treeHash objects.ObjectHash, prefix string, opts LsTreeOptions) error {
+	entries, err := readTreeEntries(s, treeHash)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		path := prefix + entry.Name
+		descend := opts.Recursive && entry.Type == objects.TreeType
+
+		if !descend || opts.ShowTrees {
+			if err := writeTreeEntry(w, entry, path, opts); err != nil {
+				return err
+			}
+		}
+		if descend {
+			if err := listTree(w, s, entry.Hash, path+"/", opts); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func writeTreeEntry(w io.Writer, entry TreeEntry, path string, opts LsTreeOptions) error {
+	prefix := ""
+	if !opts.NameOnly {
+		prefix = fmt.Sprintf("%s %s %s\t", entry.Mode.ToOctalString(), entry.Type, entry.Hash)
+	}
+	return writeRecord(w, prefix, path, opts.NullTerminated)
+}
+
+// readTreeEntries reads a tree object's entries in storage order.
+func readTreeEntries(s store.ObjectStore, treeHash objects.ObjectHash) ([]TreeEntry, error) {
+	serialized, err := readSerialized(s, treeHash)
+	if err != nil {
+		return nil, err
+	}
+	objType, _, contentStart, err := serialized.ParseHeader()
+	if err != nil {
+		return nil, err
+	}
+	if objType != objects.TreeType {
+		return nil, fmt.Errorf("%s is a %s, not a tree", treeHash, objType)
+	}
+	return ParseTreeEntries(serialized[contentStart:], s.HashAlgorithm())
+}
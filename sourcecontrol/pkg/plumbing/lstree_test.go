@@ -0,0 +1,42 @@
+package plumbing
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestListTree(t *testing.T) {
+	repo := setupTestRepo(t)
+	h := writeTestHistory(t, repo)
+
+	readme := "100644 blob " + h.readme.String() + "\tREADME\n"
+	src := "040000 tree " + h.subtree.String() + "\tsrc\n"
+	main := "100644 blob " + h.main.String() + "\tsrc/main.go\n"
+
+	tests := []struct {
+		name string
+		opts LsTreeOptions
+		want string
+	}{
+		{"top level", LsTreeOptions{}, readme + src},
+		{"recursive", LsTreeOptions{Recursive: true}, readme + main},
+		{"recursive with trees", LsTreeOptions{Recursive: true, ShowTrees: true}, readme + src + main},
+		{"name only", LsTreeOptions{Recursive: true, NameOnly: true}, "README\nsrc/main.go\n"},
+		{"zero", LsTreeOptions{NameOnly: true, NullTerminated: true}, "README\x00src\x00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			if err := ListTree(&out, repo.ObjectStore(), h.tree, tt.opts); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("ListTree() =\n%q\nwant\n%q", out.String(), tt.want)
+			}
+		})
+	}
+
+	if err := ListTree(&strings.Builder{}, repo.ObjectStore(), h.readme, LsTreeOptions{}); err == nil {
+		t.Error("ListTree() of a blob should fail")
+	}
+}
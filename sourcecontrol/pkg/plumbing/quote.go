@@ -0,0 +1,62 @@
+package plumbing
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// cEscapes are the characters QuotePath writes as a backslash escape.
+var cEscapes = map[byte]byte{
+	'\a': 'a', '\b': 'b', '\t': 't', '\n': 'n',
+	'\v': 'v', '\f': 'f', '\r': 'r', '"': '"', '\\': '\\',
+}
+
+// QuotePath quotes a path the way Git prints it when core.quotePath is on,
+// its default: a path holding a double quote, backslash, control character
+// or any non-ASCII byte is written in double quotes with C-style escapes,
+// e.g. "tab\there" or "\303\251". Other paths are returned unchanged.
+//
+// Output terminated with NUL (-z) is never quoted.
+func QuotePath(path string) string {
+	if !needsQuoting(path) {
+		return path
+	}
+
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		switch esc, ok := cEscapes[c]; {
+		case ok:
+			sb.WriteByte('\\')
+			sb.WriteByte(esc)
+		case c < 0x20 || c >= 0x7f:
+			fmt.Fprintf(&sb, "\\%03o", c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+func needsQuoting(path string) bool {
+	for i := 0; i < len(path); i++ {
+		if c := path[i]; c < 0x20 || c >= 0x7f || c == '"' || c == '\\' {
+			return true
+		}
+	}
+	return false
+}
+
+// writeRecord writes one line of plumbing output, quoting its path unless
+// records are NUL-terminated.
+func writeRecord(w io.Writer, prefix, path string, nulTerminated bool) error {
+	if nulTerminated {
+		_, err := fmt.Fprintf(w, "%s%s\x00", prefix, path)
+		return err
+	}
+	_, err := fmt.Fprintf(w, "%s%s\n", prefix, QuotePath(path))
+	return err
+}
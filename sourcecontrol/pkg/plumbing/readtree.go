@@ -0,0 +1,240 @@
+package plumbing
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/utkarsh5026/SourceControl/pkg/index"
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+	"github.com/utkarsh5026/SourceControl/pkg/store"
+)
+
+var (
+	// ErrUnmergedIndex is returned when merging into an index that still
+	// has conflict stages.
+	ErrUnmergedIndex = errors.New("you need to resolve your current index first")
+
+	// ErrWouldOverwrite is returned when a merge would lose a change that
+	// is staged in the index.
+	ErrWouldOverwrite = errors.New("would be overwritten by merge")
+)
+
+// ReadTreeOptions configures ReadTree.
+type ReadTreeOptions struct {
+	// Merge merges the trees into the index instead of replacing it (-m)
+	Merge bool
+}
+
+// ReadTree reads trees into idx, as read-tree does.
+//
+// Without Merge the index is replaced by the contents of a single tree.
+// With Merge the number of trees selects the kind of merge:
+//
+//	1 tree     the index becomes the tree, but entries that did not change
+//	           keep their cached stat information
+//	2 trees    a fast-forward from the first tree (the current HEAD) to the
+//	           second, carrying over changes staged in the index
+//	3 trees    a three-way merge of base, ours and theirs: paths changed
+//	           on one side only are resolved, everything else is left as
+//	           conflict stages 1, 2 and 3
+//
+// A merge fails with ErrUnmergedIndex if the index has conflicts, and with
+// ErrWouldOverwrite if it would discard a staged change. idx is only
+// modified when ReadTree succeeds.
+func ReadTree(s store.ObjectStore, idx *index.Index, trees []objects.ObjectHash, opts ReadTreeOptions) error {
+	if !opts.Merge && len(trees) != 1 {
+		return fmt.Errorf("read-tree without merging takes exactly one tree, got %d", len(trees))
+	}
+	if len(trees) < 1 || len(trees) > 3 {
+		return fmt.Errorf("read-tree -m takes one to three trees, got %d", len(trees))
+	}
+	if opts.Merge && idx.HasConflicts() {
+		return ErrUnmergedIndex
+	}
+
+	flattened := make([]map[string]*index.Entry, len(trees))
+	for i, treeHash := range trees {
+		entries, err := FlattenTree(s, treeHash)
+		if err != nil {
+			return fmt.Errorf("failed to read tree %s: %w", treeHash, err)
+		}
+		flattened[i] = entries
+	}
+
+	current := make(map[string]*index.Entry, len(idx.Entries))
+	if opts.Merge {
+		for _, entry := range idx.Entries {
+			current[entry.Path.String()] = entry
+		}
+	}
+
+	var result []*index.Entry
+	for _, path := range unionPaths(current, flattened) {
+		cur := current[path]
+		var merged []*index.Entry
+		var err error
+
+		switch len(trees) {
+		case 1:
+			if entry := flattened[0][path]; entry != nil {
+				merged = []*index.Entry{keepStat(entry, cur)}
+			}
+		case 2:
+			merged, err = twoWayMerge(path, cur, flattened[0][path], flattened[1][path])
+		case 3:
+			merged, err = threeWayMerge(path, cur, flattened[0][path], flattened[1][path], flattened[2][path])
+		}
+		if err != nil {
+			return err
+		}
+		result = append(result, merged...)
+	}
+
+	idx.Replace(result)
+	return nil
+}
+
+// twoWayMerge moves a path from tree old to tree new, keeping the index
+// entry when it already holds new or when the path did not change between
+// the trees. Any other difference between the index and old is a staged
+// change the merge would lose.
+func twoWayMerge(path string, cur, old, new *index.Entry) ([]*index.Entry, error) {
+	if cur == nil {
+		switch {
+		case new == nil:
+			return nil, nil
+		case old == nil:
+			return []*index.Entry{new}, nil
+		case sameEntry(old, new):
+			// The path's removal is staged and the trees agree on it
+			return nil, nil
+		default:
+			return nil, wouldOverwrite(path)
+		}
+	}
+
+	switch {
+	case old == nil && new == nil,
+		old == nil && sameEntry(cur, new),
+		old != nil && new != nil && sameEntry(old, new),
+		old != nil && new != nil && sameEntry(cur, new):
+		return []*index.Entry{cur}, nil
+	case old != nil && new == nil && sameEntry(cur, old):
+		return nil, nil
+	case old != nil && new != nil && sameEntry(cur, old):
+		return []*index.Entry{new}, nil
+	default:
+		return nil, wouldOverwrite(path)
+	}
+}
+
+// threeWayMerge merges a path from the trees base, ours and theirs. A path
+// only one side changed (or that both changed the same way) takes that
+// side's version; otherwise each version that exists is recorded in its
+// stage. The index entry, if any, must match ours.
+func threeWayMerge(path string, cur, base, ours, theirs *index.Entry) ([]*index.Entry, error) {
+	oursUnchanged := sameEntry(base, ours)
+	theirsUnchanged := sameEntry(base, theirs)
+
+	if theirs != nil && oursUnchanged && !theirsUnchanged {
+		// The index may already hold their version
+		if cur != nil && !sameEntry(cur, theirs) && !sameEntry(cur, ours) {
+			return nil, wouldOverwrite(path)
+		}
+		return []*index.Entry{keepStat(theirs, cur)}, nil
+	}
+	if cur != nil && !sameEntry(cur, ours) {
+		return nil, wouldOverwrite(path)
+	}
+	if ours != nil && (sameEntry(ours, theirs) || theirsUnchanged && !oursUnchanged) {
+		return []*index.Entry{keepStat(ours, cur)}, nil
+	}
+
+	var stages []*index.Entry
+	for stage, entry := range []*index.Entry{base, ours, theirs} {
+		if entry == nil {
+			continue
+		}
+		staged := *entry
+		staged.Stage = uint8(stage + 1)
+		stages = append(stages, &staged)
+	}
+	return stages, nil
+}
+
+func wouldOverwrite(path string) error {
+	return fmt.Errorf("entry '%s' %w", path, ErrWouldOverwrite)
+}
+
+// sameEntry reports whether two entries record the same content and mode.
+// Two missing entries are the same.
+func sameEntry(a, b *index.Entry) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.BlobHash == b.BlobHash && a.TreeMode() == b.TreeMode()
+}
+
+// keepStat returns the index entry when it records the same content as
+// entry, so that its cached stat information survives, and entry otherwise.
+func keepStat(entry, cur *index.Entry) *index.Entry {
+	if cur != nil && sameEntry(entry, cur) {
+		return cur
+	}
+	return entry
+}
+
+// unionPaths returns every path present in the index or in one of the
+// trees, sorted.
+func unionPaths(current map[string]*index.Entry, trees []map[string]*index.Entry) []string {
+	seen := make(map[string]bool, len(current))
+	for path := range current {
+		seen[path] = true
+	}
+	for _, entries := range trees {
+		for path := range entries {
+			seen[path] = true
+		}
+	}
+
+	paths := make([]string, 0, len(seen))
+	for path := range seen {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// FlattenTree returns an index entry, without stat information, for every
+// blob and gitlink below a tree, keyed by its full path.
+func FlattenTree(s store.ObjectStore, treeHash objects.ObjectHash) (map[string]*index.Entry, error) {
+	entries := make(map[string]*index.Entry)
+	if err := flattenInto(s, treeHash, "", entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func flattenInto(s store.ObjectStore, treeHash objects.ObjectHash, prefix string, entries map[string]*index.Entry) error {
+	treeEntries, err := readTreeEntries(s, treeHash)
+	if err != nil {
+		return err
+	}
+
+	for _, te := range treeEntries {
+		path := prefix + te.Name
+		if te.Type == objects.TreeType {
+			if err := flattenInto(s, te.Hash, path+"/", entries); err != nil {
+				return err
+			}
+			continue
+		}
+		entry := index.NewEntry(scpath.RelativePath(path))
+		entry.Mode = te.Mode
+		entry.BlobHash = te.Hash
+		entries[path] = entry
+	}
+	return nil
+}
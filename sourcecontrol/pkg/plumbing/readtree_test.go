@@ -0,0 +1,127 @@
+package plumbing
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/utkarsh5026/SourceControl/pkg/common"
+	"github.com/utkarsh5026/SourceControl/pkg/index"
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/blob"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/tree"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/sourcerepo"
+)
+
+// writeFlatTree writes a tree of regular files whose names map to contents.
+func writeFlatTree(t *testing.T, repo *sourcerepo.SourceRepository, files map[string]string) objects.ObjectHash {
+	t.Helper()
+	var entries []*tree.TreeEntry
+	for name, content := range files {
+		entries = append(entries, entry(t, objects.FileModeRegular, name, write(t, repo, blob.NewBlob([]byte(content)))))
+	}
+	return write(t, repo, tree.NewTree(entries))
+}
+
+func stagedFiles(t *testing.T, idx *index.Index) string {
+	t.Helper()
+	var out strings.Builder
+	for _, e := range idx.Entries {
+		out.WriteString(e.Path.String())
+		if e.Stage != 0 {
+			out.WriteString(":" + string(rune('0'+e.Stage)))
+		}
+		out.WriteString(" ")
+	}
+	return strings.TrimSpace(out.String())
+}
+
+func TestReadTree_Single(t *testing.T) {
+	repo := setupTestRepo(t)
+	h := writeTestHistory(t, repo)
+
+	idx := index.NewIndex()
+	if err := ReadTree(repo.ObjectStore(), idx, []objects.ObjectHash{h.tree}, ReadTreeOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := stagedFiles(t, idx); got != "README src/main.go" {
+		t.Errorf("index = %q", got)
+	}
+
+	// -m with one tree keeps the stat information of unchanged entries
+	readme, _ := idx.Get("README")
+	readme.ModificationTime = common.NewTimestamp(1234, 0)
+	if err := ReadTree(repo.ObjectStore(), idx, []objects.ObjectHash{h.tree}, ReadTreeOptions{Merge: true}); err != nil {
+		t.Fatal(err)
+	}
+	if readme, _ := idx.Get("README"); readme.ModificationTime.Seconds != 1234 {
+		t.Error("read-tree -m discarded the stat information of an unchanged entry")
+	}
+}
+
+func TestReadTree_TwoWay(t *testing.T) {
+	repo := setupTestRepo(t)
+	head := writeFlatTree(t, repo, map[string]string{"same": "1", "changed": "1", "deleted": "1"})
+	next := writeFlatTree(t, repo, map[string]string{"same": "1", "changed": "2", "added": "1"})
+	store := repo.ObjectStore()
+
+	idx := index.NewIndex()
+	if err := ReadTree(store, idx, []objects.ObjectHash{head}, ReadTreeOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	staged := write(t, repo, blob.NewBlob([]byte("staged")))
+	if err := CacheInfo(store, idx, objects.FileModeRegular, staged, "same", UpdateIndexOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ReadTree(store, idx, []objects.ObjectHash{head, next}, ReadTreeOptions{Merge: true}); err != nil {
+		t.Fatalf("two-way merge failed: %v", err)
+	}
+	if got := stagedFiles(t, idx); got != "added changed same" {
+		t.Errorf("index = %q", got)
+	}
+	if e, _ := idx.Get("same"); e.BlobHash != staged {
+		t.Error("two-way merge lost a change staged to a path the trees agree on")
+	}
+
+	// A staged change to a path the merge updates cannot be carried over
+	if err := CacheInfo(store, idx, objects.FileModeRegular, staged, "changed", UpdateIndexOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	other := writeFlatTree(t, repo, map[string]string{"same": "1", "changed": "3", "added": "1"})
+	err := ReadTree(store, idx, []objects.ObjectHash{next, other}, ReadTreeOptions{Merge: true})
+	if !errors.Is(err, ErrWouldOverwrite) {
+		t.Errorf("err = %v, want ErrWouldOverwrite", err)
+	}
+}
+
+func TestReadTree_ThreeWay(t *testing.T) {
+	repo := setupTestRepo(t)
+	base := writeFlatTree(t, repo, map[string]string{
+		"same": "1", "modours": "1", "modthem": "1", "modboth": "1", "conf": "1",
+		"delboth": "1", "delours": "1", "delthem": "1",
+	})
+	ours := writeFlatTree(t, repo, map[string]string{
+		"same": "1", "modours": "2", "modthem": "1", "modboth": "2", "conf": "2",
+		"delthem": "1", "addours": "1",
+	})
+	theirs := writeFlatTree(t, repo, map[string]string{
+		"same": "1", "modours": "1", "modthem": "2", "modboth": "2", "conf": "3",
+		"delours": "1", "addthem": "1",
+	})
+
+	idx := index.NewIndex()
+	if err := ReadTree(repo.ObjectStore(), idx, []objects.ObjectHash{base, ours, theirs}, ReadTreeOptions{Merge: true}); err != nil {
+		t.Fatalf("three-way merge failed: %v", err)
+	}
+
+	// The same result git read-tree -m gives for these trees
+	want := "addours addthem conf:1 conf:2 conf:3 delboth:1 delours:1 delours:3 delthem:1 delthem:2 modboth modours modthem same"
+	if got := stagedFiles(t, idx); got != want {
+		t.Errorf("index =\n%s\nwant\n%s", got, want)
+	}
+
+	if err := ReadTree(repo.ObjectStore(), idx, []objects.ObjectHash{base, ours, theirs}, ReadTreeOptions{Merge: true}); !errors.Is(err, ErrUnmergedIndex) {
+		t.Errorf("merging into an unmerged index: err = %v, want ErrUnmergedIndex", err)
+	}
+}
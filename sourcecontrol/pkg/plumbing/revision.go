@@ -0,0 +1,308 @@
+// Package plumbing implements the low-level commands scripts use to read and
+// write the object database directly, with output that matches Git's.
+package plumbing
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/commit"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/tag"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/refs"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/sourcerepo"
+	"github.com/utkarsh5026/SourceControl/pkg/store"
+)
+
+var (
+	// ErrUnknownRevision is returned when a name matches no ref or object.
+	ErrUnknownRevision = errors.New("not a valid object name")
+
+	// ErrAmbiguousRevision is returned when an abbreviated hash matches more
+	// than one object.
+	ErrAmbiguousRevision = errors.New("short object ID is ambiguous")
+)
+
+// minAbbrev is the shortest hash prefix accepted as an object name.
+const minAbbrev = 4
+
+// refSearchOrder lists the places a short ref name is looked up, in Git's
+// order: "v1" is a tag before it is a branch.
+var refSearchOrder = []string{
+	"%s",
+	"refs/%s",
+	"refs/tags/%s",
+	"refs/heads/%s",
+	"refs/remotes/%s",
+	"refs/remotes/%s/HEAD",
+}
+
+// prefixFinder is implemented by stores that can look objects up by an
+// abbreviated hash.
+type prefixFinder interface {
+	FindObjectsByPrefix(prefix string) ([]objects.ObjectHash, error)
+}
+
+// Resolver turns object names into hashes. It understands the subset of
+// Git's revision syntax that scripts commonly pass to plumbing commands:
+//
+//	<hash>, <abbreviated hash>      a8f3c21, a8f3c21e9b...
+//	<ref>                           HEAD, main, v1.0, refs/heads/main
+//	<rev>~<n>, <rev>^<n>            first-parent ancestor, n-th parent
+//	<rev>^{<type>}, <rev>^{}        peel tags (and commits) to a type
+//	<rev>:<path>                    an entry of the revision's tree
+type Resolver struct {
+	repo       *sourcerepo.SourceRepository
+	store      store.ObjectStore
+	refManager *refs.RefManager
+}
+
+// NewResolver creates a resolver reading refs and objects from repo.
+func NewResolver(repo *sourcerepo.SourceRepository) *Resolver {
+	return &Resolver{
+		repo:       repo,
+		store:      repo.ObjectStore(),
+		refManager: refs.NewRefManager(repo),
+	}
+}
+
+// Resolve returns the hash of the object name refers to. A full hash is
+// returned as is, whether or not the object exists.
+func (r *Resolver) Resolve(name string) (objects.ObjectHash, error) {
+	if name == "" {
+		return "", fmt.Errorf("%w: empty name", ErrUnknownRevision)
+	}
+
+	if rev, path, ok := strings.Cut(name, ":"); ok {
+		if rev == "" {
+			return "", fmt.Errorf("%w: %s (index paths are not supported)", ErrUnknownRevision, name)
+		}
+		return r.resolveTreePath(rev, path)
+	}
+
+	if base, ok := strings.CutSuffix(name, "}"); ok {
+		if i := strings.LastIndex(base, "^{"); i > 0 {
+			hash, err := r.Resolve(base[:i])
+			if err != nil {
+				return "", err
+			}
+			return r.Peel(hash, objects.ObjectType(base[i+2:]))
+		}
+	}
+
+	if i := strings.LastIndexAny(name, "~^"); i > 0 {
+		n := 1
+		if digits := name[i+1:]; digits != "" {
+			parsed, err := strconv.Atoi(digits)
+			if err != nil || parsed < 0 {
+				return "", fmt.Errorf("%w: %s", ErrUnknownRevision, name)
+			}
+			n = parsed
+		}
+		hash, err := r.Resolve(name[:i])
+		if err != nil {
+			return "", err
+		}
+		if name[i] == '~' {
+			return r.ancestor(hash, n)
+		}
+		return r.parent(hash, n)
+	}
+
+	return r.resolveName(name)
+}
+
+// resolveName resolves a name without any suffix: a full or abbreviated
+// hash, or a ref.
+func (r *Resolver) resolveName(name string) (objects.ObjectHash, error) {
+	algorithm := r.store.HashAlgorithm()
+	if len(name) == algorithm.HexSize() {
+		if hash, err := objects.NewObjectHashFromString(name); err == nil {
+			return hash, nil
+		}
+	}
+
+	for _, pattern := range refSearchOrder {
+		ref := refs.RefPath(fmt.Sprintf(pattern, name))
+		exists, err := r.refManager.Exists(ref)
+		if err != nil || !exists {
+			continue
+		}
+		hash, err := r.refManager.ResolveToSHA(ref)
+		if err != nil {
+			return "", err
+		}
+		return hash, nil
+	}
+
+	if len(name) >= minAbbrev && commit.LooksLikeCommitSHA(name) {
+		return r.resolveAbbrev(name)
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnknownRevision, name)
+}
+
+// resolveAbbrev expands an abbreviated hash.
+func (r *Resolver) resolveAbbrev(prefix string) (objects.ObjectHash, error) {
+	var s any = r.store
+	for {
+		if finder, ok := s.(prefixFinder); ok {
+			matches, err := finder.FindObjectsByPrefix(prefix)
+			if err != nil {
+				return "", err
+			}
+			switch len(matches) {
+			case 0:
+				return "", fmt.Errorf("%w: %s", ErrUnknownRevision, prefix)
+			case 1:
+				return matches[0], nil
+			default:
+				return "", fmt.Errorf("%w: %s", ErrAmbiguousRevision, prefix)
+			}
+		}
+		wrapper, ok := s.(interface{ Base() store.ObjectStore })
+		if !ok {
+			return "", fmt.Errorf("%w: %s (abbreviated hashes are not supported by this store)", ErrUnknownRevision, prefix)
+		}
+		s = wrapper.Base()
+	}
+}
+
+// read returns an object's type and content.
+func (r *Resolver) read(hash objects.ObjectHash) (objects.ObjectType, []byte, error) {
+	serialized, err := store.ReadSerialized(r.store, hash)
+	if err != nil {
+		return "", nil, err
+	}
+	if serialized == nil {
+		return "", nil, fmt.Errorf("%w: %s", store.ErrObjectNotFound, hash)
+	}
+	objType, _, contentStart, err := serialized.ParseHeader()
+	if err != nil {
+		return "", nil, err
+	}
+	return objType, serialized[contentStart:], nil
+}
+
+// Peel follows tags, and from commits to their trees, until it reaches an
+// object of type want. An empty want peels tags only.
+func (r *Resolver) Peel(hash objects.ObjectHash, want objects.ObjectType) (objects.ObjectHash, error) {
+	if want != "" {
+		if _, err := objects.ParseObjectType(string(want)); err != nil && want != "object" {
+			return "", fmt.Errorf("%w: ^{%s}", ErrUnknownRevision, want)
+		}
+	}
+
+	for {
+		objType, content, err := r.read(hash)
+		if err != nil {
+			return "", err
+		}
+		if objType == want || want == "object" || (want == "" && objType != objects.TagType) {
+			return hash, nil
+		}
+
+		switch {
+		case objType == objects.TagType:
+			t, err := tag.ParseTag(objects.NewSerializedObject(objType, content))
+			if err != nil {
+				return "", err
+			}
+			hash = t.ObjectSHA
+		case objType == objects.CommitType && want == objects.TreeType:
+			c, err := commit.ParseCommit(objects.NewSerializedObject(objType, content))
+			if err != nil {
+				return "", err
+			}
+			hash = c.TreeSHA
+		default:
+			return "", fmt.Errorf("%s %s cannot be peeled to a %s", objType, hash.Short(), want)
+		}
+	}
+}
+
+// readCommit peels hash to a commit and parses it.
+func (r *Resolver) readCommit(hash objects.ObjectHash) (*commit.Commit, error) {
+	hash, err := r.Peel(hash, objects.CommitType)
+	if err != nil {
+		return nil, err
+	}
+	_, content, err := r.read(hash)
+	if err != nil {
+		return nil, err
+	}
+	return commit.ParseCommit(objects.NewSerializedObject(objects.CommitType, content))
+}
+
+// ancestor follows first parents n times.
+func (r *Resolver) ancestor(hash objects.ObjectHash, n int) (objects.ObjectHash, error) {
+	for range n {
+		var err error
+		if hash, err = r.parent(hash, 1); err != nil {
+			return "", err
+		}
+	}
+	return r.Peel(hash, objects.CommitType)
+}
+
+// parent returns the n-th parent of a commit; the 0th is the commit itself.
+func (r *Resolver) parent(hash objects.ObjectHash, n int) (objects.ObjectHash, error) {
+	c, err := r.readCommit(hash)
+	if err != nil {
+		return "", err
+	}
+	if n == 0 {
+		return r.Peel(hash, objects.CommitType)
+	}
+	if n > len(c.ParentSHAs) {
+		return "", fmt.Errorf("%w: commit %s has no parent %d", ErrUnknownRevision, hash.Short(), n)
+	}
+	return c.ParentSHAs[n-1], nil
+}
+
+// resolveTreePath finds path in the tree of rev.
+func (r *Resolver) resolveTreePath(rev, path string) (objects.ObjectHash, error) {
+	hash, err := r.Resolve(rev)
+	if err != nil {
+		return "", err
+	}
+	hash, err = r.Peel(hash, objects.TreeType)
+	if err != nil {
+		return "", err
+	}
+
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return hash, nil
+	}
+
+	// Every component but the last must name a subtree
+	objType := objects.TreeType
+	for _, part := range strings.Split(path, "/") {
+		if objType != objects.TreeType {
+			return "", fmt.Errorf("%w: path '%s' does not exist in '%s'", ErrUnknownRevision, path, rev)
+		}
+		_, content, err := r.read(hash)
+		if err != nil {
+			return "", err
+		}
+		entries, err := ParseTreeEntries(content, r.store.HashAlgorithm())
+		if err != nil {
+			return "", err
+		}
+
+		found := false
+		for _, entry := range entries {
+			if entry.Name == part {
+				hash, objType, found = entry.Hash, entry.Type, true
+				break
+			}
+		}
+		if !found {
+			return "", fmt.Errorf("%w: path '%s' does not exist in '%s'", ErrUnknownRevision, path, rev)
+		}
+	}
+	return hash, nil
+}
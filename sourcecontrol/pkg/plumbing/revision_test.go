@@ -0,0 +1,180 @@
+package plumbing
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/blob"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/commit"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/tag"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/tree"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/refs"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/sourcerepo"
+)
+
+func setupTestRepo(t *testing.T) *sourcerepo.SourceRepository {
+	t.Helper()
+
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", t.TempDir())
+
+	repo := sourcerepo.NewSourceRepository()
+	if err := repo.Initialize(scpath.RepositoryPath(t.TempDir())); err != nil {
+		t.Fatalf("failed to initialize repo: %v", err)
+	}
+	return repo
+}
+
+func write(t *testing.T, repo *sourcerepo.SourceRepository, obj objects.BaseObject) objects.ObjectHash {
+	t.Helper()
+	hash, err := repo.WriteObject(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
+
+func entry(t *testing.T, mode objects.FileMode, name string, hash objects.ObjectHash) *tree.TreeEntry {
+	t.Helper()
+	e, err := tree.NewTreeEntry(mode, scpath.RelativePath(name), hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+// history is a two-commit history, "first" and "second", whose trees hold
+// README and src/main.go, with a branch, an annotated tag and HEAD.
+type history struct {
+	first, second objects.ObjectHash
+	tree, subtree objects.ObjectHash
+	readme, main  objects.ObjectHash
+	tag           objects.ObjectHash
+}
+
+func writeTestHistory(t *testing.T, repo *sourcerepo.SourceRepository) history {
+	t.Helper()
+	var h history
+
+	h.readme = write(t, repo, blob.NewBlob([]byte("hello\n")))
+	h.main = write(t, repo, blob.NewBlob([]byte("package main\n")))
+	h.subtree = write(t, repo, tree.NewTree([]*tree.TreeEntry{entry(t, objects.FileModeRegular, "main.go", h.main)}))
+	h.tree = write(t, repo, tree.NewTree([]*tree.TreeEntry{
+		entry(t, objects.FileModeRegular, "README", h.readme),
+		entry(t, objects.FileModeDirectory, "src", h.subtree),
+	}))
+
+	person, err := commit.NewCommitPerson("Test User", "test@example.com", time.Unix(1700000000, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	build := func(message string, parents ...objects.ObjectHash) objects.ObjectHash {
+		c, err := commit.NewCommitBuilder().
+			TreeHash(h.tree).
+			ParentHashes(parents...).
+			Author(person).
+			Committer(person).
+			Message(message).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return write(t, repo, c)
+	}
+	h.first = build("first")
+	h.second = build("second", h.first)
+
+	h.tag = write(t, repo, &tag.Tag{
+		ObjectSHA:  h.second,
+		ObjectType: objects.CommitType,
+		Name:       "v1.0",
+		Tagger:     person,
+		Message:    "release\n",
+	})
+
+	refManager := refs.NewRefManager(repo)
+	if err := refManager.UpdateRef("refs/heads/master", h.second); err != nil {
+		t.Fatal(err)
+	}
+	if err := refManager.UpdateRef("refs/tags/v1.0", h.tag); err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	repo := setupTestRepo(t)
+	h := writeTestHistory(t, repo)
+	r := NewResolver(repo)
+
+	tests := []struct {
+		name string
+		want objects.ObjectHash
+	}{
+		{"HEAD", h.second},
+		{"master", h.second},
+		{"refs/heads/master", h.second},
+		{h.first.String(), h.first},
+		{h.first.String()[:8], h.first},
+		{"HEAD~1", h.first},
+		{"HEAD^", h.first},
+		{"master^0", h.second},
+		{"v1.0", h.tag},
+		{"v1.0^{}", h.second},
+		{"v1.0^{commit}", h.second},
+		{"v1.0^{tree}", h.tree},
+		{"HEAD:README", h.readme},
+		{"HEAD:src", h.subtree},
+		{"HEAD:src/main.go", h.main},
+		{"v1.0~1:src/main.go", h.main},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.Resolve(tt.name)
+			if err != nil {
+				t.Fatalf("Resolve(%q) failed: %v", tt.name, err)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve(%q) = %s, want %s", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolver_ResolveErrors(t *testing.T) {
+	repo := setupTestRepo(t)
+	writeTestHistory(t, repo)
+	r := NewResolver(repo)
+
+	for _, name := range []string{"nope", "HEAD~5", "HEAD:missing", "HEAD:README/x", "HEAD^{blob}", "beef"} {
+		t.Run(name, func(t *testing.T) {
+			if got, err := r.Resolve(name); err == nil {
+				t.Errorf("Resolve(%q) = %s, want error", name, got)
+			}
+		})
+	}
+}
+
+func TestResolver_Ambiguous(t *testing.T) {
+	repo := setupTestRepo(t)
+
+	// Write blobs until two share a four character prefix
+	seen := make(map[string]bool)
+	var prefix string
+	for i := 0; prefix == ""; i++ {
+		hash := write(t, repo, blob.NewBlob([]byte{byte(i), byte(i >> 8)}))
+		short := hash.String()[:4]
+		if seen[short] {
+			prefix = short
+		}
+		seen[short] = true
+	}
+
+	if _, err := NewResolver(repo).Resolve(prefix); !errors.Is(err, ErrAmbiguousRevision) {
+		t.Errorf("Resolve(%q) err = %v, want ErrAmbiguousRevision", prefix, err)
+	}
+}
@@ -0,0 +1,183 @@
+package plumbing
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/utkarsh5026/SourceControl/pkg/index"
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+)
+
+// StatusFormat selects the machine-readable layout WriteStatus uses.
+type StatusFormat int
+
+const (
+	// StatusShort writes one "XY <path>" line per path (--short)
+	StatusShort StatusFormat = iota
+
+	// StatusPorcelainV1 writes the same lines as StatusShort, a layout that
+	// is kept stable for scripts (--porcelain, --porcelain=v1)
+	StatusPorcelainV1
+
+	// StatusPorcelainV2 writes one line per path with its modes and hashes
+	// in HEAD and the index (--porcelain=v2)
+	StatusPorcelainV2
+)
+
+// StatusBranch describes HEAD for the branch header of WriteStatus.
+type StatusBranch struct {
+	// Name is the checked out branch, or empty when HEAD is detached
+	Name string
+
+	// Commit is the commit HEAD points to, or empty on an unborn branch
+	Commit objects.ObjectHash
+}
+
+// StatusOptions configures WriteStatus.
+type StatusOptions struct {
+	Format StatusFormat
+
+	// Branch, when set, is described in a header before the paths (--branch)
+	Branch *StatusBranch
+
+	// NullTerminated ends records with NUL and leaves paths unquoted (-z)
+	NullTerminated bool
+
+	// HashAlgorithm gives the length of the all-zero hash written for a
+	// side that lacks a path in the v2 format; empty means SHA-1
+	HashAlgorithm objects.HashAlgorithm
+}
+
+// WriteStatus writes status to w in a format meant for scripts and
+// editors, as git status --short and --porcelain do. Tracked paths come
+// first, then untracked paths ("??" or "?") and ignored ones ("!!" or "!").
+//
+// In the short and v1 formats each line is "XY <path>": X is how the index
+// differs from HEAD and Y how the working tree differs from the index, or
+// both say how an unmerged path conflicts. The v2 format writes
+//
+//	1 <XY> <sub> <mH> <mI> <mW> <hH> <hI> <path>
+//	u <XY> <sub> <m1> <m2> <m3> <mW> <h1> <h2> <h3> <path>
+//
+// for changed and unmerged paths, with '.' for an unchanged side.
+func WriteStatus(w io.Writer, status *index.StatusResult, opts StatusOptions) error {
+	bw := bufio.NewWriter(w)
+	if opts.Branch != nil {
+		if err := writeStatusBranch(bw, opts); err != nil {
+			return err
+		}
+	}
+
+	for _, entry := range status.Entries {
+		var prefix string
+		if opts.Format == StatusPorcelainV2 {
+			prefix = statusV2Prefix(entry, opts.zeroHash())
+		} else {
+			prefix = string([]byte{entry.Staged, entry.Unstaged, ' '})
+		}
+		if err := writeRecord(bw, prefix, entry.Path, opts.NullTerminated); err != nil {
+			return err
+		}
+	}
+
+	untracked, ignored := "?? ", "!! "
+	if opts.Format == StatusPorcelainV2 {
+		untracked, ignored = "? ", "! "
+	}
+	for _, path := range status.Untracked {
+		if err := writeRecord(bw, untracked, path, opts.NullTerminated); err != nil {
+			return err
+		}
+	}
+	for _, path := range status.Ignored {
+		if err := writeRecord(bw, ignored, path, opts.NullTerminated); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// writeStatusBranch writes the branch header: "## <branch>" in the short
+// and v1 formats, "# branch.oid" and "# branch.head" lines in v2.
+func writeStatusBranch(w io.Writer, opts StatusOptions) error {
+	branch := opts.Branch
+	end := "\n"
+	if opts.NullTerminated {
+		end = "\x00"
+	}
+
+	if opts.Format == StatusPorcelainV2 {
+		oid, head := "(initial)", "(detached)"
+		if branch.Commit != "" {
+			oid = branch.Commit.String()
+		}
+		if branch.Name != "" {
+			head = branch.Name
+		}
+		_, err := fmt.Fprintf(w, "# branch.oid %s%s# branch.head %s%s", oid, end, head, end)
+		return err
+	}
+
+	var line string
+	switch {
+	case branch.Name == "":
+		line = "HEAD (no branch)"
+	case branch.Commit == "":
+		line = "No commits yet on " + branch.Name
+	default:
+		line = branch.Name
+	}
+	_, err := fmt.Fprintf(w, "## %s%s", line, end)
+	return err
+}
+
+// statusV2Prefix returns the fields of a v2 line before the path.
+func statusV2Prefix(entry index.StatusEntry, zero string) string {
+	xy := string([]byte{v2Code(entry.Staged), v2Code(entry.Unstaged)})
+	sub := "N..."
+	switch {
+	case entry.IndexMode.IsGitlink() && entry.WorktreeMode.IsGitlink() && entry.Unstaged == index.StatusModified:
+		sub = "SC.." // another commit is checked out
+	case entry.HeadMode.IsGitlink() || entry.IndexMode.IsGitlink():
+		sub = "S..."
+	}
+
+	if entry.IsUnmerged() {
+		var modes, hashes [3]string
+		for i, stage := range entry.Stages {
+			modes[i], hashes[i] = "000000", zero
+			if stage != nil {
+				modes[i], hashes[i] = stage.TreeMode().ToOctalString(), stage.BlobHash.String()
+			}
+		}
+		return fmt.Sprintf("u %s %s %s %s %s %s %s %s %s ", xy, sub,
+			modes[0], modes[1], modes[2], entry.WorktreeMode.ToOctalString(),
+			hashes[0], hashes[1], hashes[2])
+	}
+
+	return fmt.Sprintf("1 %s %s %s %s %s %s %s ", xy, sub,
+		entry.HeadMode.ToOctalString(), entry.IndexMode.ToOctalString(), entry.WorktreeMode.ToOctalString(),
+		hashOrZero(entry.HeadHash, zero), hashOrZero(entry.IndexHash, zero))
+}
+
+// v2Code returns a status code as the v2 format writes it, with '.' for
+// an unchanged side.
+func v2Code(code byte) byte {
+	if code == index.StatusUnmodified {
+		return '.'
+	}
+	return code
+}
+
+func hashOrZero(hash objects.ObjectHash, zero string) string {
+	if hash == "" {
+		return zero
+	}
+	return hash.String()
+}
+
+// zeroHash returns the all-zero hash of the configured algorithm.
+func (o StatusOptions) zeroHash() string {
+	return o.HashAlgorithm.ZeroHash().String()
+}
@@ -0,0 +1,80 @@
+package plumbing
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/utkarsh5026/SourceControl/pkg/index"
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+)
+
+func TestWriteStatus(t *testing.T) {
+	head := objects.ObjectHash(strings.Repeat("a", 40))
+	staged := objects.ObjectHash(strings.Repeat("b", 40))
+	zero := strings.Repeat("0", 40)
+
+	status := &index.StatusResult{
+		Entries: []index.StatusEntry{
+			{Path: "both", Staged: 'U', Unstaged: 'U', WorktreeMode: objects.FileModeRegular, Stages: [3]*index.Entry{
+				{Path: "both", Stage: 1, Mode: objects.FileModeRegular, BlobHash: head},
+				{Path: "both", Stage: 2, Mode: objects.FileModeRegular, BlobHash: staged},
+				nil,
+			}},
+			{Path: "changed", Staged: 'M', Unstaged: ' ',
+				HeadMode: objects.FileModeRegular, IndexMode: objects.FileModeExecutable, WorktreeMode: objects.FileModeExecutable,
+				HeadHash: head, IndexHash: staged},
+			{Path: "new file", Staged: 'A', Unstaged: 'D',
+				IndexMode: objects.FileModeRegular, IndexHash: staged},
+		},
+		Untracked: []string{"dir/", "tab\there"},
+		Ignored:   []string{"debug.log"},
+	}
+
+	tests := []struct {
+		name string
+		opts StatusOptions
+		want string
+	}{
+		{"short", StatusOptions{},
+			"UU both\nM  changed\nAD new file\n?? dir/\n?? \"tab\\there\"\n!! debug.log\n"},
+		{"v1 with branch, zero", StatusOptions{Format: StatusPorcelainV1, NullTerminated: true, Branch: &StatusBranch{Name: "main", Commit: head}},
+			"## main\x00UU both\x00M  changed\x00AD new file\x00?? dir/\x00?? tab\there\x00!! debug.log\x00"},
+		{"v2", StatusOptions{Format: StatusPorcelainV2, Branch: &StatusBranch{Name: "main"}},
+			"# branch.oid (initial)\n# branch.head main\n" +
+				"u UU N... 100644 100644 000000 100644 " + head.String() + " " + staged.String() + " " + zero + " both\n" +
+				"1 M. N... 100644 100755 100755 " + head.String() + " " + staged.String() + " changed\n" +
+				"1 AD N... 000000 100644 000000 " + zero + " " + staged.String() + " new file\n" +
+				"? dir/\n? \"tab\\there\"\n! debug.log\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			if err := WriteStatus(&out, status, tt.opts); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("WriteStatus() =\n%q\nwant\n%q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteStatus_BranchHeader(t *testing.T) {
+	commit := objects.ObjectHash(strings.Repeat("c", 40))
+	tests := []struct {
+		branch StatusBranch
+		want   string
+	}{
+		{StatusBranch{Name: "main"}, "## No commits yet on main\n"},
+		{StatusBranch{Commit: commit}, "## HEAD (no branch)\n"},
+	}
+	for _, tt := range tests {
+		var out strings.Builder
+		if err := WriteStatus(&out, &index.StatusResult{}, StatusOptions{Branch: &tt.branch}); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("WriteStatus(%+v) = %q, want %q", tt.branch, out.String(), tt.want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package plumbing
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+)
+
+// TreeEntry is a tree entry as stored in the object, in storage order.
+type TreeEntry struct {
+	Mode objects.FileMode
+	Type objects.ObjectType
+	Hash objects.ObjectHash
+	Name string
+}
+
+// String formats the entry the way cat-file -p and ls-tree print it:
+//
+//	100644 blob e69de29bb2d1d6434b8b29ae775ad8c2e48c5391	README
+func (e TreeEntry) String() string {
+	return fmt.Sprintf("%s %s %s\t%s", e.Mode.ToOctalString(), e.Type, e.Hash, e.Name)
+}
+
+// ParseTreeEntries parses the content of a tree object (without its header).
+// Unlike tree.ParseTree it keeps the entries in the order they are stored,
+// which is what plumbing output must reproduce.
+func ParseTreeEntries(content []byte, algorithm objects.HashAlgorithm) ([]TreeEntry, error) {
+	var entries []TreeEntry
+	for len(content) > 0 {
+		space := bytes.IndexByte(content, objects.SpaceByte)
+		nul := bytes.IndexByte(content, objects.NullByte)
+		if space <= 0 || nul < space || nul+1+algorithm.Size() > len(content) {
+			return nil, fmt.Errorf("malformed tree entry")
+		}
+
+		mode, err := objects.FromOctalString(string(content[:space]))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, TreeEntry{
+			Mode: mode,
+			Type: entryType(mode),
+			Hash: objects.NewObjectHashFromRaw(content[nul+1 : nul+1+algorithm.Size()]),
+			Name: string(content[space+1 : nul]),
+		})
+		content = content[nul+1+algorithm.Size():]
+	}
+	return entries, nil
+}
+
+// entryType returns the type of object a tree entry with the given mode
+// points to.
+func entryType(mode objects.FileMode) objects.ObjectType {
+	switch {
+	case mode.IsGitlink():
+		return objects.CommitType
+	case mode == objects.FileModeDirectory:
+		return objects.TreeType
+	default:
+		return objects.BlobType
+	}
+}
@@ -0,0 +1,321 @@
+package plumbing
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/utkarsh5026/SourceControl/pkg/index"
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/blob"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/sourcerepo"
+	"github.com/utkarsh5026/SourceControl/pkg/store"
+)
+
+// UpdateIndexOptions configures UpdateIndex.
+type UpdateIndexOptions struct {
+	// Add allows paths that are not in the index yet to be added (--add)
+	Add bool
+
+	// Remove removes paths that no longer exist in the working directory
+	// instead of failing (--remove)
+	Remove bool
+
+	// ForceRemove removes the paths even if the files still exist
+	// (--force-remove)
+	ForceRemove bool
+
+	// InfoOnly records object IDs without touching the object store
+	// (--info-only): files are hashed but not written, and CacheInfo does
+	// not check that the object exists
+	InfoOnly bool
+}
+
+// UpdateIndex stages the working directory's version of each path, as
+// update-index <path>... does: the file is hashed into the object store
+// and its entry, including stat information, replaces the one in idx and
+// any conflict stages for the path.
+//
+// Paths are relative to the repository root. A path that is not in the
+// index is only added with Add, and a path whose file is gone is only
+// removed with Remove. Modes follow core.fileMode and core.symlinks, as
+// index.ModeSettings describes. If any path fails, idx is left unchanged.
+func UpdateIndex(repo *sourcerepo.SourceRepository, idx *index.Index, paths []scpath.RelativePath, opts UpdateIndexOptions) error {
+	modes := index.LoadModeSettings(repo.WorkingDirectory())
+	edit := newIndexEdit(idx)
+	for _, path := range paths {
+		if err := updateIndexPath(repo, edit, path.Normalize(), modes, opts); err != nil {
+			return err
+		}
+	}
+	edit.apply()
+	return nil
+}
+
+func updateIndexPath(repo *sourcerepo.SourceRepository, edit *indexEdit, path scpath.RelativePath, modes index.ModeSettings, opts UpdateIndexOptions) error {
+	if opts.ForceRemove {
+		edit.unstage(path)
+		return nil
+	}
+
+	absPath := filepath.Join(repo.WorkingDirectory().String(), filepath.FromSlash(path.String()))
+	info, err := os.Lstat(absPath)
+	if errors.Is(err, os.ErrNotExist) {
+		if !opts.Remove {
+			return fmt.Errorf("%s: does not exist and --remove not passed", path)
+		}
+		edit.unstage(path)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s: is a directory - add files inside instead", path)
+	}
+	if !opts.Add && !edit.has(path) {
+		return fmt.Errorf("%s: cannot add to the index - missing --add option?", path)
+	}
+
+	hash, err := hashWorktreeFile(repo, absPath, info, opts.InfoOnly)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	entry, err := index.NewEntryFromFileInfo(path, info, hash)
+	if err != nil {
+		return err
+	}
+	entry.Mode = modes.WorktreeMode(info, edit.merged(path))
+	edit.stage(entry)
+	return nil
+}
+
+// hashWorktreeFile writes the blob for a file in the working directory: the
+// file's content, or for a symbolic link the path it points to. With
+// infoOnly the blob is hashed but not written.
+func hashWorktreeFile(repo *sourcerepo.SourceRepository, absPath string, info os.FileInfo, infoOnly bool) (objects.ObjectHash, error) {
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(absPath)
+		if err != nil {
+			return "", err
+		}
+		content := []byte(filepath.ToSlash(target))
+		if infoOnly {
+			return repo.HashAlgorithm().HashObject(objects.BlobType, content), nil
+		}
+		return repo.ObjectStore().WriteObject(blob.NewBlob(content))
+	}
+
+	file, err := os.Open(absPath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	if infoOnly {
+		return repo.HashAlgorithm().HashObjectFromReader(objects.BlobType, file, info.Size())
+	}
+	return repo.ObjectStore().WriteBlobStream(file, info.Size())
+}
+
+// CacheInfo stages an object under path, as update-index --cacheinfo
+// <mode>,<hash>,<path> does. The mode must be one a tree can record for a
+// file: 100644, 100755, 120000 or 160000.
+//
+// As with UpdateIndex, a path that is not in the index is only added with
+// opts.Add. Unless opts.InfoOnly is set the object must be in s; a gitlink
+// names a commit in the submodule, so it is never looked up.
+func CacheInfo(s store.ObjectStore, idx *index.Index, mode objects.FileMode, hash objects.ObjectHash, path scpath.RelativePath, opts UpdateIndexOptions) error {
+	if !isFileMode(mode) {
+		return fmt.Errorf("invalid mode %s for '%s'", mode.ToOctalString(), path)
+	}
+	if !path.IsValid() {
+		return fmt.Errorf("invalid path '%s'", path)
+	}
+
+	edit := newIndexEdit(idx)
+	if !opts.Add && !edit.has(path) {
+		return fmt.Errorf("%s: cannot add to the index - missing --add option?", path)
+	}
+	if !opts.InfoOnly && mode != objects.FileModeGitlink {
+		exists, err := s.HasObject(hash)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("%s: object %s does not exist", path, hash)
+		}
+	}
+
+	entry := index.NewEntry(path)
+	entry.Mode = mode
+	entry.BlobHash = hash
+	edit.stage(entry)
+	edit.apply()
+	return nil
+}
+
+// IndexInfo applies the lines of r to idx, as update-index --index-info
+// does. Each line has one of the forms
+//
+//	<mode> SP <hash> TAB <path>
+//	<mode> SP <type> SP <hash> TAB <path>      (ls-tree output)
+//	<mode> SP <hash> SP <stage> TAB <path>     (ls-files --stage output)
+//
+// A mode of 0 removes every stage of the path; otherwise the entry replaces
+// the one with the same path and stage. The lines are applied to idx
+// together once all of them have been read; if any is malformed, idx is
+// left unchanged.
+func IndexInfo(idx *index.Index, r io.Reader, algorithm objects.HashAlgorithm) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), 1<<20)
+
+	edit := newIndexEdit(idx)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if text == "" {
+			continue
+		}
+		if err := indexInfoLine(edit, text, algorithm); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	edit.apply()
+	return nil
+}
+
+func indexInfoLine(edit *indexEdit, line string, algorithm objects.HashAlgorithm) error {
+	meta, path, ok := strings.Cut(line, "\t")
+	if !ok {
+		return fmt.Errorf("malformed index info %q", line)
+	}
+	fields := strings.Fields(meta)
+	if len(fields) < 2 || len(fields) > 3 {
+		return fmt.Errorf("malformed index info %q", line)
+	}
+
+	mode, err := objects.FromOctalString(fields[0])
+	if err != nil {
+		return err
+	}
+	relPath := scpath.RelativePath(path).Normalize()
+	if mode == 0 {
+		edit.unstage(relPath)
+		return nil
+	}
+
+	hashField, stage := fields[1], uint8(0)
+	if len(fields) == 3 {
+		if _, err := objects.ParseObjectType(fields[1]); err == nil {
+			hashField = fields[2]
+		} else {
+			n, err := strconv.ParseUint(fields[2], 10, 8)
+			if err != nil || n > 3 {
+				return fmt.Errorf("invalid stage %q", fields[2])
+			}
+			stage = uint8(n)
+		}
+	}
+
+	hash, err := objects.ParseObjectHash(hashField)
+	if err != nil {
+		return err
+	}
+	if hash.Algorithm() != algorithm {
+		return fmt.Errorf("%s is not a %s hash", hash, algorithm)
+	}
+	if !isFileMode(mode) {
+		return fmt.Errorf("invalid mode %s for '%s'", mode.ToOctalString(), path)
+	}
+
+	entry := index.NewEntry(relPath)
+	entry.Mode = mode
+	entry.BlobHash = hash
+	entry.Stage = stage
+	edit.stage(entry)
+	return nil
+}
+
+// isFileMode reports whether a tree can record a file with this mode.
+func isFileMode(mode objects.FileMode) bool {
+	switch mode {
+	case objects.FileModeRegular, objects.FileModeExecutable, objects.FileModeSymlink, objects.FileModeGitlink:
+		return true
+	}
+	return false
+}
+
+// indexEdit collects changes to an index by path, so that a batch of them
+// costs one pass over the entries instead of one per change.
+type indexEdit struct {
+	idx     *index.Index
+	paths   map[scpath.RelativePath][]*index.Entry
+	changed bool
+}
+
+func newIndexEdit(idx *index.Index) *indexEdit {
+	paths := make(map[scpath.RelativePath][]*index.Entry, len(idx.Entries))
+	for _, e := range idx.Entries {
+		paths[e.Path] = append(paths[e.Path], e)
+	}
+	return &indexEdit{idx: idx, paths: paths}
+}
+
+// has reports whether the path has an entry at any stage.
+func (e *indexEdit) has(path scpath.RelativePath) bool {
+	return len(e.paths[path]) > 0
+}
+
+// merged returns the stage 0 entry for path, or nil if it has none.
+func (e *indexEdit) merged(path scpath.RelativePath) *index.Entry {
+	for _, entry := range e.paths[path] {
+		if entry.Stage == 0 {
+			return entry
+		}
+	}
+	return nil
+}
+
+// stage puts entry in place of the entry with the same path and stage. As
+// in Git a path is either merged or conflicted, so a stage 0 entry also
+// replaces the path's conflict stages and a conflict stage replaces its
+// stage 0 entry.
+func (e *indexEdit) stage(entry *index.Entry) {
+	var kept []*index.Entry
+	for _, existing := range e.paths[entry.Path] {
+		if existing.Stage != entry.Stage && existing.Stage != 0 && entry.Stage != 0 {
+			kept = append(kept, existing)
+		}
+	}
+	e.paths[entry.Path] = append(kept, entry)
+	e.changed = true
+}
+
+// unstage removes every stage of path.
+func (e *indexEdit) unstage(path scpath.RelativePath) {
+	if _, ok := e.paths[path]; ok {
+		delete(e.paths, path)
+		e.changed = true
+	}
+}
+
+// apply replaces the entries of the index with the edited ones.
+func (e *indexEdit) apply() {
+	if !e.changed {
+		return
+	}
+	entries := make([]*index.Entry, 0, len(e.paths))
+	for _, staged := range e.paths {
+		entries = append(entries, staged...)
+	}
+	e.idx.Replace(entries)
+	e.changed = false
+}
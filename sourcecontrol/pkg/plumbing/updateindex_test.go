@@ -0,0 +1,174 @@
+package plumbing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/utkarsh5026/SourceControl/pkg/index"
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/sourcerepo"
+)
+
+func writeWorktreeFile(t *testing.T, repo *sourcerepo.SourceRepository, path, content string, perm os.FileMode) {
+	t.Helper()
+	abs := filepath.Join(repo.WorkingDirectory().String(), path)
+	if err := os.MkdirAll(filepath.Dir(abs), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(abs, []byte(content), perm); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func relPaths(paths ...string) []scpath.RelativePath {
+	rel := make([]scpath.RelativePath, len(paths))
+	for i, p := range paths {
+		rel[i] = scpath.RelativePath(p)
+	}
+	return rel
+}
+
+func TestUpdateIndex_MatchesGit(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs executable bits and symbolic links")
+	}
+	repo := setupTestRepo(t)
+	writeWorktreeFile(t, repo, "README", "hello\n", 0644)
+	writeWorktreeFile(t, repo, "src/main.go", "package main\n", 0644)
+	writeWorktreeFile(t, repo, "run.sh", "#!/bin/sh\n", 0755)
+	if err := os.Symlink("README", filepath.Join(repo.WorkingDirectory().String(), "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	idx := index.NewIndex()
+	paths := relPaths("README", "src/main.go", "run.sh", "link")
+	if err := UpdateIndex(repo, idx, paths, UpdateIndexOptions{}); err == nil {
+		t.Fatal("UpdateIndex() without Add should refuse new paths")
+	}
+	if err := UpdateIndex(repo, idx, paths, UpdateIndexOptions{Add: true}); err != nil {
+		t.Fatalf("UpdateIndex() failed: %v", err)
+	}
+
+	// Expected output and tree hash come from git on the same files
+	var out strings.Builder
+	if err := ListFiles(&out, idx, LsFilesOptions{Stage: true}); err != nil {
+		t.Fatal(err)
+	}
+	want := "100644 ce013625030ba8dba906f756967f9e9ca394464a 0\tREADME\n" +
+		"120000 100b93820ade4c16225673b4ca62bb3ade63c313 0\tlink\n" +
+		"100755 1a2485251c33a70432394c93fb89330ef214bfc9 0\trun.sh\n" +
+		"100644 06ab7d0f9a35a7d1070711496d6ca1cb892a258f 0\tsrc/main.go\n"
+	if out.String() != want {
+		t.Errorf("ListFiles() =\n%s\nwant\n%s", out.String(), want)
+	}
+
+	treeHash, err := WriteTree(context.Background(), repo, idx)
+	if err != nil {
+		t.Fatalf("WriteTree() failed: %v", err)
+	}
+	if treeHash != "097bf51396d5cc7e54f1773ccba0484417ddf5a1" {
+		t.Errorf("WriteTree() = %s, want git's 097bf51396d5cc7e54f1773ccba0484417ddf5a1", treeHash)
+	}
+}
+
+func TestUpdateIndex_Remove(t *testing.T) {
+	repo := setupTestRepo(t)
+	writeWorktreeFile(t, repo, "a.txt", "a\n", 0644)
+	writeWorktreeFile(t, repo, "b.txt", "b\n", 0644)
+
+	idx := index.NewIndex()
+	if err := UpdateIndex(repo, idx, relPaths("a.txt", "b.txt"), UpdateIndexOptions{Add: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Remove(filepath.Join(repo.WorkingDirectory().String(), "a.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := UpdateIndex(repo, idx, relPaths("a.txt"), UpdateIndexOptions{}); err == nil {
+		t.Error("UpdateIndex() of a deleted file without Remove should fail")
+	}
+	if err := UpdateIndex(repo, idx, relPaths("a.txt"), UpdateIndexOptions{Remove: true}); err != nil {
+		t.Fatal(err)
+	}
+	if idx.Has("a.txt") {
+		t.Error("Remove should unstage a deleted file")
+	}
+
+	// --remove keeps files that still exist; --force-remove does not
+	if err := UpdateIndex(repo, idx, relPaths("b.txt"), UpdateIndexOptions{Remove: true}); err != nil || !idx.Has("b.txt") {
+		t.Errorf("Remove dropped an existing file (err %v)", err)
+	}
+	if err := UpdateIndex(repo, idx, relPaths("b.txt"), UpdateIndexOptions{ForceRemove: true}); err != nil || idx.Has("b.txt") {
+		t.Errorf("ForceRemove kept an existing file (err %v)", err)
+	}
+}
+
+func TestCacheInfoAndIndexInfo(t *testing.T) {
+	repo := setupTestRepo(t)
+	h := writeTestHistory(t, repo)
+	idx := index.NewIndex()
+
+	store := repo.ObjectStore()
+	add := UpdateIndexOptions{Add: true}
+
+	if err := CacheInfo(store, idx, objects.FileModeExecutable, h.main, "bin/tool", UpdateIndexOptions{}); err == nil {
+		t.Error("CacheInfo() should need Add for a path not in the index")
+	}
+	if err := CacheInfo(store, idx, objects.FileModeExecutable, h.main, "bin/tool", add); err != nil {
+		t.Fatal(err)
+	}
+	if err := CacheInfo(store, idx, objects.FileModeDirectory, h.subtree, "dir", add); err == nil {
+		t.Error("CacheInfo() should reject a directory mode")
+	}
+	missing := objects.ObjectHash(strings.Repeat("1", 40))
+	if err := CacheInfo(store, idx, objects.FileModeRegular, missing, "missing", add); err == nil {
+		t.Error("CacheInfo() should reject an object that is not in the store")
+	}
+	if err := CacheInfo(store, idx, objects.FileModeGitlink, missing, "sub", add); err != nil {
+		t.Errorf("CacheInfo() of a gitlink failed: %v", err)
+	}
+
+	input := "100644 " + h.readme.String() + "\tREADME\n" +
+		"100644 blob " + h.main.String() + "\tsrc/main.go\n" +
+		"100644 " + h.readme.String() + " 1\tconflict\n" +
+		"100644 " + h.main.String() + " 3\tconflict\n" +
+		"0 " + strings.Repeat("0", 40) + "\tbin/tool\n" +
+		"0 " + strings.Repeat("0", 40) + "\tsub\n"
+	if err := IndexInfo(idx, strings.NewReader(input), objects.SHA1); err != nil {
+		t.Fatalf("IndexInfo() failed: %v", err)
+	}
+
+	var out strings.Builder
+	if err := ListFiles(&out, idx, LsFilesOptions{Stage: true}); err != nil {
+		t.Fatal(err)
+	}
+	want := "100644 " + h.readme.String() + " 0\tREADME\n" +
+		"100644 " + h.readme.String() + " 1\tconflict\n" +
+		"100644 " + h.main.String() + " 3\tconflict\n" +
+		"100644 " + h.main.String() + " 0\tsrc/main.go\n"
+	if out.String() != want {
+		t.Errorf("index after IndexInfo() =\n%s\nwant\n%s", out.String(), want)
+	}
+
+	// Staging a resolution replaces the conflict stages
+	if err := CacheInfo(store, idx, objects.FileModeRegular, h.main, "conflict", UpdateIndexOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if idx.HasConflicts() || idx.Count() != 3 {
+		t.Errorf("resolving the conflict left %d entries, conflicts %v", idx.Count(), idx.HasConflicts())
+	}
+
+	// A malformed line leaves the index as it was
+	input = "0 " + strings.Repeat("0", 40) + "\tREADME\n100644 nothex\tx\n"
+	if err := IndexInfo(idx, strings.NewReader(input), objects.SHA1); err == nil {
+		t.Error("IndexInfo() should reject a malformed hash")
+	}
+	if !idx.Has("README") {
+		t.Error("IndexInfo() applied the lines before a malformed one")
+	}
+}
@@ -0,0 +1,54 @@
+package plumbing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/utkarsh5026/SourceControl/pkg/commitmanager"
+	"github.com/utkarsh5026/SourceControl/pkg/index"
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/sourcerepo"
+)
+
+// ErrUnmergedEntries is returned by WriteTree for an index with conflicts.
+var ErrUnmergedEntries = errors.New("index has unmerged entries")
+
+// WriteTree writes the tree objects for the contents of idx, as write-tree
+// does, and returns the root tree's hash.
+//
+// The index must have no conflict stages, and every blob it names must be
+// in the object store, since a tree pointing at a missing blob cannot be
+// checked out. Gitlinks name commits in another repository and are not
+// checked, and intent-to-add entries are left out of the tree.
+//
+// Trees for directories that are unchanged in idx.CacheTree are reused, and
+// the trees written are recorded there; write the index to keep them.
+func WriteTree(ctx context.Context, repo *sourcerepo.SourceRepository, idx *index.Index) (objects.ObjectHash, error) {
+	if idx.HasConflicts() {
+		var paths []string
+		for _, path := range idx.GetConflictedPaths() {
+			paths = append(paths, path.String())
+		}
+		sort.Strings(paths)
+		return "", fmt.Errorf("%w: %s", ErrUnmergedEntries, strings.Join(paths, ", "))
+	}
+
+	for _, entry := range idx.Entries {
+		mode := entry.TreeMode()
+		if mode.IsGitlink() || entry.IntentToAdd {
+			continue
+		}
+		exists, err := repo.ObjectStore().HasObject(entry.BlobHash)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return "", fmt.Errorf("invalid object %s %s for '%s'", mode.ToOctalString(), entry.BlobHash, entry.Path)
+		}
+	}
+
+	return commitmanager.NewTreeBuilder(repo).BuildFromIndex(ctx, idx)
+}
@@ -144,7 +144,7 @@ func ResolveRefOrCommit(
 			return newResolveResult(sha, false, false), nil
 		}
 
-		if len(target) >= 4 && len(target) < 40 {
+		if len(target) >= 4 && len(target) < repo.HashAlgorithm().HexSize() {
 			return nil, err
 		}
 	}
@@ -0,0 +1,316 @@
+// Package gitconfig reads and writes files in Git's config format, such as
+// .git/config and .gitmodules:
+//
+//	# a comment
+//	[core]
+//	    repositoryformatversion = 0
+//	[submodule "libs/lib"]
+//	    path = libs/lib
+//	    url = "../lib.git" ; a comment
+//
+// Only the syntax is handled here; what the keys mean is up to the caller.
+package gitconfig
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Entry is one key set in a config file.
+type Entry struct {
+	// Section is the section name, in lower case
+	Section string
+
+	// Subsection is the quoted part of a [section "subsection"] header,
+	// exactly as written; empty if the header has none
+	Subsection string
+
+	// Key is the variable name, in lower case
+	Key string
+
+	// Value is the value with quotes, escapes and comments resolved; a key
+	// written without "=" is a boolean set to "true"
+	Value string
+}
+
+// Name returns the entry's full name, "section.key" or
+// "section.subsection.key".
+func (e Entry) Name() string {
+	if e.Subsection == "" {
+		return e.Section + "." + e.Key
+	}
+	return e.Section + "." + e.Subsection + "." + e.Key
+}
+
+// Parse reads every entry of a config file, in the order they appear.
+//
+// As in Git, a value may be double-quoted in whole or in part, "#" and ";"
+// start a comment outside quotes, the escapes \" \\ \n \t and \b are
+// recognized, and a backslash at the end of a line continues the value on
+// the next one. Whitespace around a value is dropped unless quoted.
+//
+// Parameters:
+//   - r: The file's content
+//
+// Returns:
+//   - []Entry: The entries; a key set more than once appears once per
+//     assignment
+//   - error: A syntax error, naming its line, or a read error
+func Parse(r io.Reader) ([]Entry, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{data: data, line: 1}
+	entries, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("bad config line %d: %w", p.line, err)
+	}
+	return entries, nil
+}
+
+// parser walks a config file one byte at a time.
+type parser struct {
+	data []byte
+	pos  int
+	line int
+}
+
+func (p *parser) parse() ([]Entry, error) {
+	var entries []Entry
+	var section, subsection string
+	for {
+		c, ok := p.skipSpace(true)
+		switch {
+		case !ok:
+			return entries, nil
+		case c == '#' || c == ';':
+			p.skipLine()
+		case c == '[':
+			var err error
+			if section, subsection, err = p.header(); err != nil {
+				return nil, err
+			}
+		case isKeyChar(c):
+			if section == "" {
+				return nil, fmt.Errorf("key outside of a section")
+			}
+			key, value, err := p.variable()
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, Entry{Section: section, Subsection: subsection, Key: key, Value: value})
+		default:
+			return nil, fmt.Errorf("unexpected %q", c)
+		}
+	}
+}
+
+// header reads a section header, the opening bracket already seen.
+func (p *parser) header() (section, subsection string, err error) {
+	p.pos++
+	start := p.pos
+	for p.pos < len(p.data) && (isKeyChar(p.data[p.pos]) || p.data[p.pos] == '.') {
+		p.pos++
+	}
+	section = strings.ToLower(string(p.data[start:p.pos]))
+	if section == "" {
+		return "", "", fmt.Errorf("malformed section header")
+	}
+
+	if c, _ := p.peek(); c == ' ' || c == '\t' {
+		if c, _ = p.skipSpace(false); c != '"' {
+			return "", "", fmt.Errorf("malformed section header")
+		}
+		if subsection, err = p.subsection(); err != nil {
+			return "", "", err
+		}
+	}
+	if c, _ := p.peek(); c != ']' {
+		return "", "", fmt.Errorf("malformed section header")
+	}
+	p.pos++
+	return section, subsection, nil
+}
+
+// subsection reads the quoted subsection name of a header, in which a
+// backslash escapes the next character.
+func (p *parser) subsection() (string, error) {
+	var b strings.Builder
+	for p.pos++; p.pos < len(p.data); p.pos++ {
+		switch c := p.data[p.pos]; c {
+		case '\n':
+			return "", fmt.Errorf("malformed section header")
+		case '"':
+			p.pos++
+			return b.String(), nil
+		case '\\':
+			if p.pos++; p.pos == len(p.data) || p.data[p.pos] == '\n' {
+				return "", fmt.Errorf("malformed section header")
+			}
+			b.WriteByte(p.data[p.pos])
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", fmt.Errorf("malformed section header")
+}
+
+// variable reads a "key = value" line, or a key on its own.
+func (p *parser) variable() (key, value string, err error) {
+	start := p.pos
+	for p.pos < len(p.data) && isKeyChar(p.data[p.pos]) {
+		p.pos++
+	}
+	key = strings.ToLower(string(p.data[start:p.pos]))
+
+	c, ok := p.skipSpace(false)
+	switch {
+	case !ok || c == '\n' || c == '#' || c == ';':
+		return key, "true", nil
+	case c != '=':
+		return "", "", fmt.Errorf("invalid key %q", key+string(c))
+	}
+	p.pos++
+	value, err = p.value()
+	return key, value, err
+}
+
+// value reads the rest of a line after "=".
+func (p *parser) value() (string, error) {
+	var b strings.Builder
+	quoted, spaces := false, 0
+	for ; p.pos < len(p.data); p.pos++ {
+		c := p.data[p.pos]
+		if c == '\n' {
+			if quoted {
+				return "", fmt.Errorf("unterminated quote")
+			}
+			break
+		}
+		if !quoted && (c == ' ' || c == '\t' || c == '\r') {
+			if b.Len() > 0 {
+				spaces++
+			}
+			continue
+		}
+		if !quoted && (c == '#' || c == ';') {
+			p.skipLine()
+			break
+		}
+		for ; spaces > 0; spaces-- {
+			b.WriteByte(' ')
+		}
+
+		switch c {
+		case '"':
+			quoted = !quoted
+		case '\\':
+			p.pos++
+			if p.pos == len(p.data) {
+				return "", fmt.Errorf("incomplete escape")
+			}
+			switch e := p.data[p.pos]; e {
+			case '\n':
+				p.line++
+			case '\r':
+				if next, _ := p.peekAt(p.pos + 1); next == '\n' {
+					p.pos++
+					p.line++
+				}
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'b':
+				b.WriteByte('\b')
+			case '"', '\\':
+				b.WriteByte(e)
+			default:
+				return "", fmt.Errorf("invalid escape \\%c", e)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	if quoted {
+		return "", fmt.Errorf("unterminated quote")
+	}
+	return b.String(), nil
+}
+
+// skipSpace moves past blanks, and line breaks too if newlines is set,
+// returning the byte it stops at; ok is false at the end of the data.
+func (p *parser) skipSpace(newlines bool) (c byte, ok bool) {
+	for ; p.pos < len(p.data); p.pos++ {
+		switch c = p.data[p.pos]; c {
+		case ' ', '\t', '\r':
+		case '\n':
+			if !newlines {
+				return c, true
+			}
+			p.line++
+		default:
+			return c, true
+		}
+	}
+	return 0, false
+}
+
+// skipLine moves to the line break ending the current line.
+func (p *parser) skipLine() {
+	for p.pos < len(p.data) && p.data[p.pos] != '\n' {
+		p.pos++
+	}
+}
+
+func (p *parser) peek() (byte, bool) {
+	return p.peekAt(p.pos)
+}
+
+func (p *parser) peekAt(pos int) (byte, bool) {
+	if pos < len(p.data) {
+		return p.data[pos], true
+	}
+	return 0, false
+}
+
+// isKeyChar reports whether c may appear in a section or key name.
+func isKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-'
+}
+
+// QuoteSubsection returns name quoted for a [section "name"] header.
+func QuoteSubsection(name string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(name) + `"`
+}
+
+// FormatValue returns value as it should be written after "key = ", quoted
+// and escaped where Parse would otherwise read it differently.
+func FormatValue(value string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\b", `\b`).Replace(value)
+	if value != strings.TrimSpace(value) || strings.ContainsAny(value, "#;") {
+		return `"` + escaped + `"`
+	}
+	return escaped
+}
+
+// ParseBool interprets a value as Git does a boolean: "true", "yes", "on"
+// and non-zero integers are true, "false", "no", "off", zero and the empty
+// string are false, in any case.
+func ParseBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "true", "yes", "on":
+		return true, nil
+	case "false", "no", "off", "":
+		return false, nil
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return false, fmt.Errorf("bad boolean value %q", value)
+	}
+	return n != 0, nil
+}
@@ -0,0 +1,92 @@
+package gitconfig
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	content := "# comment\n" +
+		"[Core]\n" +
+		"\tRepositoryFormatVersion = 1 ; trailing comment\n" +
+		"\tbare\n" +
+		"[remote \"Origin\"]\n" +
+		"\turl = \"/srv/a#b;c\" # comment\n" +
+		"[submodule \"a]b \\\"c\\\\\"]\n" +
+		"\tpath = two  words \n" +
+		"\tmsg = \"say \\\"hi\\\"\\tnow\"\\n\n" +
+		"\tlong = first \\\n" +
+		"second\n"
+	got, err := Parse(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	want := []Entry{
+		{Section: "core", Key: "repositoryformatversion", Value: "1"},
+		{Section: "core", Key: "bare", Value: "true"},
+		{Section: "remote", Subsection: "Origin", Key: "url", Value: "/srv/a#b;c"},
+		{Section: "submodule", Subsection: `a]b "c\`, Key: "path", Value: "two  words"},
+		{Section: "submodule", Subsection: `a]b "c\`, Key: "msg", Value: "say \"hi\"\tnow\n"},
+		{Section: "submodule", Subsection: `a]b "c\`, Key: "long", Value: "first second"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() =\n%+v\nwant\n%+v", got, want)
+	}
+	if name := got[2].Name(); name != "remote.Origin.url" {
+		t.Errorf("Name() = %q, want remote.Origin.url", name)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, content := range []string{
+		"[core\n",
+		"[submodule \"x]\n",
+		"[submodule x]\n",
+		"key = value\n",
+		"[core]\n\turl = \"open\n",
+		"[core]\n\turl = bad \\q escape\n",
+		"[core]\n\tkey?= value\n",
+	} {
+		if _, err := Parse(strings.NewReader(content)); err == nil {
+			t.Errorf("Parse(%q) should fail", content)
+		}
+	}
+}
+
+func TestQuoteAndFormat_RoundTrip(t *testing.T) {
+	name := `odd "name" \ here]`
+	values := []string{"plain", " padded ", "a#b", "semi;colon", `back\slash`, `"quoted"`, "tab\there"}
+	var b strings.Builder
+	b.WriteString("[section " + QuoteSubsection(name) + "]\n")
+	for _, v := range values {
+		b.WriteString("\tkey = " + FormatValue(v) + "\n")
+	}
+
+	entries, err := Parse(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatalf("Parse() failed: %v\n%s", err, b.String())
+	}
+	if len(entries) != len(values) {
+		t.Fatalf("Parse() returned %d entries, want %d", len(entries), len(values))
+	}
+	for i, e := range entries {
+		if e.Subsection != name || e.Value != values[i] {
+			t.Errorf("entry %d = %q %q, want %q %q", i, e.Subsection, e.Value, name, values[i])
+		}
+	}
+}
+
+func TestParseBool(t *testing.T) {
+	for value, want := range map[string]bool{
+		"true": true, "Yes": true, "ON": true, "1": true, "-2": true,
+		"false": false, "no": false, "Off": false, "0": false, "": false,
+	} {
+		if got, err := ParseBool(value); err != nil || got != want {
+			t.Errorf("ParseBool(%q) = %v, %v; want %v", value, got, err, want)
+		}
+	}
+	if _, err := ParseBool("maybe"); err == nil {
+		t.Error(`ParseBool("maybe") should fail`)
+	}
+}
@@ -0,0 +1,100 @@
+package ignore
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+)
+
+// GitIgnoreFile is the name of Git's per-directory ignore file, read
+// alongside DefaultSource so that existing repositories keep their rules.
+const GitIgnoreFile = ".gitignore"
+
+// Matcher decides whether paths in a working tree are ignored, using the
+// ignore files found in the tree and the repository's info/exclude file.
+//
+// The ignore files of a directory apply to everything below it, and a path
+// inside an ignored directory is ignored too. As in Git, the ignore files
+// nearest the path take precedence: they are consulted from the path's own
+// directory up to the root, then info/exclude, and the first whose
+// patterns match the path, positive or negated, decides. Files are read the first time
+// a directory is consulted, so a matcher should not outlive the operation
+// it was created for. A Matcher is safe for concurrent use.
+type Matcher struct {
+	root scpath.RepositoryPath
+
+	mu   sync.Mutex
+	sets map[string]*PatternSet // directory (relative, "" for the root) -> its patterns
+}
+
+// NewMatcher creates a matcher for the working tree at root.
+func NewMatcher(root scpath.RepositoryPath) *Matcher {
+	return &Matcher{
+		root: root,
+		sets: make(map[string]*PatternSet),
+	}
+}
+
+// IsIgnored reports whether the file or directory at relPath, relative to
+// the repository root and slash-separated, is ignored.
+func (m *Matcher) IsIgnored(relPath string, isDirectory bool) bool {
+	relPath = string(scpath.RelativePath(relPath).Normalize())
+	if relPath == "" || relPath == "." {
+		return false
+	}
+
+	dir := path.Dir(relPath)
+	if dir == "." {
+		dir = ""
+	} else if m.IsIgnored(dir, true) {
+		return true
+	}
+
+	for d := dir; ; d = path.Dir(d) {
+		if d == "." {
+			d = ""
+		}
+		if ignored, matched := m.directorySet(d).Match(relPath, isDirectory, d); matched {
+			return ignored
+		}
+		if d == "" {
+			break
+		}
+	}
+	ignored, _ := m.excludes().Match(relPath, isDirectory, "")
+	return ignored
+}
+
+// excludes returns the patterns of the repository's info/exclude file.
+func (m *Matcher) excludes() *PatternSet {
+	return m.load("\x00exclude", filepath.Join(m.root.SourcePath().String(), "info", "exclude"))
+}
+
+// directorySet returns the patterns of the ignore files in dir.
+func (m *Matcher) directorySet(dir string) *PatternSet {
+	base := filepath.Join(m.root.String(), filepath.FromSlash(dir))
+	return m.load(dir, filepath.Join(base, GitIgnoreFile), filepath.Join(base, DefaultSource))
+}
+
+// load returns the pattern set cached under key, reading it from files the
+// first time. Files that cannot be read contribute no patterns.
+func (m *Matcher) load(key string, files ...string) *PatternSet {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if set, ok := m.sets[key]; ok {
+		return set
+	}
+
+	set := NewPatternSet()
+	for _, file := range files {
+		if content, err := os.ReadFile(file); err == nil {
+			set.AddPatternsFromText(string(content), filepath.Base(file))
+		}
+	}
+	m.sets[key] = set
+	return set
+}
@@ -0,0 +1,61 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+)
+
+func TestMatcher_IsIgnored(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".gitignore":           "*.log\nbuild/\n",
+		"src/.sourceignore":    "/generated.go\n",
+		"docs/.gitignore":      "*.pdf\n!keep.pdf\n",
+		".git/info/exclude":    "secret.txt\n*.tmp\n",
+		"sub/.gitignore":       "!keep.log\n!notes.tmp\n",
+		"src/pkg/.placeholder": "",
+	}
+	for name, content := range files {
+		full := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := NewMatcher(scpath.RepositoryPath(root))
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"app.log", false, true},
+		{"src/deep/trace.log", false, true},
+		{"build", true, true},
+		{"build/out.bin", false, true},
+		{"src/build/out.bin", false, true},
+		{"src/generated.go", false, true},
+		{"src/pkg/generated.go", false, false},
+		{"generated.go", false, false},
+		{"docs/manual.pdf", false, true},
+		{"docs/keep.pdf", false, false},
+		{"manual.pdf", false, false},
+		{"secret.txt", false, true},
+		{"sub/keep.log", false, false},
+		{"sub/other.log", false, true},
+		{"sub/notes.tmp", false, false},
+		{"sub/scratch.tmp", false, true},
+		{"src/main.go", false, false},
+		{".", true, false},
+	}
+	for _, tt := range tests {
+		if got := m.IsIgnored(tt.path, tt.isDir); got != tt.want {
+			t.Errorf("IsIgnored(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+		}
+	}
+}
@@ -145,8 +145,13 @@ func (ip *IgnorePattern) Matches(filePath string, isDirectory bool, fromDirector
 		}
 	}
 
-	// Rooted patterns match from the base directory
+	// Rooted patterns match from the base directory, so without wildcards
+	// they name one path rather than any file with that base name
 	if ip.IsRooted {
+		if !containsWildcard(ip.Pattern) {
+			return string(testPath) == ip.Pattern ||
+				(ip.IsDirOnly && strings.HasPrefix(string(testPath), ip.Pattern+"/"))
+		}
 		return matchPattern(string(testPath), ip.Pattern, ip.IsDirOnly)
 	}
 
@@ -9,6 +9,7 @@ import (
 type PatternSet struct {
 	patterns         []*IgnorePattern
 	negationPatterns []*IgnorePattern
+	ordered          []*IgnorePattern // every pattern, in the order added
 }
 
 // NewPatternSet creates a new empty pattern set
@@ -18,6 +19,7 @@ func NewPatternSet() *PatternSet {
 
 // Add adds a pattern to the set
 func (ps *PatternSet) Add(pattern *IgnorePattern) {
+	ps.ordered = append(ps.ordered, pattern)
 	if pattern.IsNegation {
 		ps.negationPatterns = append(ps.negationPatterns, pattern)
 	} else {
@@ -59,10 +61,24 @@ func (ps *PatternSet) IsIgnored(filePath string, isDirectory bool, fromDirectory
 	})
 }
 
+// Match applies the patterns as Git does: the last pattern matching the
+// path decides whether it is ignored. matched is false when no pattern,
+// positive or negated, matches, so the caller can fall back to the next
+// set.
+func (ps *PatternSet) Match(filePath string, isDirectory bool, fromDirectory string) (ignored, matched bool) {
+	for i := len(ps.ordered) - 1; i >= 0; i-- {
+		if p := ps.ordered[i]; p.Matches(filePath, isDirectory, fromDirectory) {
+			return !p.IsNegation, true
+		}
+	}
+	return false, false
+}
+
 // Clear removes all patterns from the set
 func (ps *PatternSet) Clear() {
 	ps.patterns = nil
 	ps.negationPatterns = nil
+	ps.ordered = nil
 }
 
 // IgnoredPatterns returns all ignore patterns (non-negation patterns)
@@ -13,4 +13,12 @@ const (
 
 	// RefHEAD is the HEAD reference
 	RefHEAD RefPath = "HEAD"
+
+	// RefOrigHead records where HEAD was before a merge, reset or revert
+	// moved it, so the operation can be undone
+	RefOrigHead RefPath = "ORIG_HEAD"
+
+	// RefMergeHead lists the commits being merged while a merge is in
+	// progress, one per line
+	RefMergeHead RefPath = "MERGE_HEAD"
 )
@@ -0,0 +1,79 @@
+package refs
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+)
+
+// PackedRef is a reference stored in the packed-refs file rather than in a
+// file of its own under refs/.
+type PackedRef struct {
+	Ref  RefPath
+	Hash objects.ObjectHash
+
+	// Peeled is the object an annotated tag points to, when the file
+	// records it; empty otherwise
+	Peeled objects.ObjectHash
+}
+
+// PackedRefs returns the references listed in .git/packed-refs, in the
+// order of the file. A reference that also has a loose file is left out,
+// since the loose file takes precedence, as in Git. A missing packed-refs
+// file lists nothing.
+//
+// The file holds one "<hash> <ref>" line per reference, optionally followed
+// by a "^<hash>" line naming the object an annotated tag peels to; lines
+// starting with "#" are comments:
+//
+//	# pack-refs with: peeled fully-peeled sorted
+//	3f2a... refs/heads/master
+//	9b1c... refs/tags/v1.0
+//	^3f2a...
+//
+// Returns:
+//   - The packed references
+//   - An error if the file cannot be read or a line is malformed
+func (rm *RefManager) PackedRefs() ([]PackedRef, error) {
+	data, err := os.ReadFile(rm.packedRefsPath.String())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read packed refs: %w", err)
+	}
+
+	var packed []PackedRef
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if peeled, ok := strings.CutPrefix(line, "^"); ok {
+			hash, err := objects.NewObjectHashFromString(peeled)
+			if err != nil || len(packed) == 0 {
+				return nil, fmt.Errorf("invalid packed refs line %d: %q", i+1, line)
+			}
+			packed[len(packed)-1].Peeled = hash
+			continue
+		}
+
+		hexHash, name, ok := strings.Cut(line, " ")
+		hash, err := objects.NewObjectHashFromString(hexHash)
+		if !ok || err != nil || !RefPath(name).IsValid() {
+			return nil, fmt.Errorf("invalid packed refs line %d: %q", i+1, line)
+		}
+		packed = append(packed, PackedRef{Ref: RefPath(name), Hash: hash})
+	}
+
+	result := packed[:0]
+	for _, p := range packed {
+		if _, err := os.Lstat(rm.resolveReferencePath(p.Ref).String()); os.IsNotExist(err) {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
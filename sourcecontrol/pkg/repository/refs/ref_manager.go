@@ -2,6 +2,10 @@ package refs
 
 import (
 	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/utkarsh5026/SourceControl/pkg/common/fileops"
@@ -30,8 +34,9 @@ const (
 // content being either a 40-character SHA-1 hash or a symbolic reference
 // starting with "ref: ".
 type RefManager struct {
-	refsPath scpath.SourcePath // Path to the refs directory (.git/refs)
-	headPath scpath.SourcePath // Path to the HEAD file (.git/HEAD)
+	refsPath       scpath.SourcePath // Path to the refs directory (.git/refs)
+	headPath       scpath.SourcePath // Path to the HEAD file (.git/HEAD)
+	packedRefsPath scpath.SourcePath // Path to the packed-refs file (.git/packed-refs)
 }
 
 // NewRefManager creates a new reference manager for the given repository.
@@ -46,9 +51,29 @@ type RefManager struct {
 func NewRefManager(repo sourcerepo.Repository) *RefManager {
 	sourceDir := repo.SourceDirectory()
 	return &RefManager{
+		refsPath:       sourceDir.RefsPath(),
+		headPath:       sourceDir.HeadPath(),
+		packedRefsPath: sourceDir.PackedRefsPath(),
+	}
+}
+
+// HeadCommit returns the commit that HEAD of the repository at root points
+// to, without opening the repository. It is used for repositories nested in
+// another one's working tree, such as submodules.
+//
+// Parameters:
+//   - root: The working directory of the repository
+//
+// Returns:
+//   - The hash of the commit checked out in the repository
+//   - An error if root is not a repository or HEAD names no commit yet
+func HeadCommit(root scpath.RepositoryPath) (objects.ObjectHash, error) {
+	sourceDir := root.SourcePath()
+	rm := &RefManager{
 		refsPath: sourceDir.RefsPath(),
 		headPath: sourceDir.HeadPath(),
 	}
+	return rm.ResolveToSHA(scpath.HeadFile)
 }
 
 // Init initializes the reference manager by creating necessary directory
@@ -250,6 +275,43 @@ func (rm *RefManager) GetRefsPath() scpath.SourcePath {
 	return rm.refsPath
 }
 
+// ListRefs returns every reference stored under the refs directory
+// (branches, tags, remotes and any other namespace), sorted by name.
+// HEAD is not included.
+//
+// Returns:
+//   - Full reference paths such as "refs/heads/master" or "refs/tags/v1.0"
+//   - An error if the refs directory cannot be walked
+func (rm *RefManager) ListRefs() ([]RefPath, error) {
+	root := rm.refsPath.String()
+
+	var result []RefPath
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() || strings.HasSuffix(d.Name(), ".lock") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		result = append(result, RefPath(scpath.RefsDir+"/"+filepath.ToSlash(rel)))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refs: %w", err)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result, nil
+}
+
 // resolveReferencePath resolves a RefPath to its full filesystem path.
 // This handles special cases like HEAD and properly joins relative paths.
 //
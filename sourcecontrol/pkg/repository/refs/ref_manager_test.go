@@ -28,6 +28,10 @@ func (m *mockRepository) SourceDirectory() scpath.SourcePath {
 	return m.sourceDir
 }
 
+func (m *mockRepository) HashAlgorithm() objects.HashAlgorithm {
+	return objects.SHA1
+}
+
 func (m *mockRepository) ObjectStore() store.ObjectStore {
 	return nil
 }
@@ -451,3 +455,59 @@ func TestRefManager_ResolveReferencePath(t *testing.T) {
 		})
 	}
 }
+
+func TestRefManager_PackedRefs(t *testing.T) {
+	rm, tempDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	if err := rm.Init(); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+
+	packed, err := rm.PackedRefs()
+	if err != nil || len(packed) != 0 {
+		t.Fatalf("PackedRefs() without a file = %v, %v; want none", packed, err)
+	}
+
+	main := "1111111111111111111111111111111111111111"
+	tag := "2222222222222222222222222222222222222222"
+	peeled := "3333333333333333333333333333333333333333"
+	content := "# pack-refs with: peeled fully-peeled sorted \n" +
+		main + " refs/heads/main\n" +
+		main + " refs/heads/shadowed\n" +
+		tag + " refs/tags/v1.0\n" +
+		"^" + peeled + "\n"
+	packedPath := filepath.Join(tempDir, scpath.SourceDir, scpath.PackedRefsFile)
+	if err := os.WriteFile(packedPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A loose ref takes precedence over its packed entry
+	if err := rm.UpdateRef("refs/heads/shadowed", objects.ObjectHash(tag)); err != nil {
+		t.Fatal(err)
+	}
+
+	packed, err = rm.PackedRefs()
+	if err != nil {
+		t.Fatalf("PackedRefs() failed: %v", err)
+	}
+	want := []PackedRef{
+		{Ref: "refs/heads/main", Hash: objects.ObjectHash(main)},
+		{Ref: "refs/tags/v1.0", Hash: objects.ObjectHash(tag), Peeled: objects.ObjectHash(peeled)},
+	}
+	if len(packed) != len(want) {
+		t.Fatalf("PackedRefs() = %v, want %v", packed, want)
+	}
+	for i := range want {
+		if packed[i] != want[i] {
+			t.Errorf("PackedRefs()[%d] = %v, want %v", i, packed[i], want[i])
+		}
+	}
+
+	if err := os.WriteFile(packedPath, []byte("not a hash refs/heads/main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rm.PackedRefs(); err == nil {
+		t.Error("PackedRefs() should reject a malformed line")
+	}
+}
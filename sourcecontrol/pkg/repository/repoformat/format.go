@@ -0,0 +1,123 @@
+// Package repoformat reads the repository format recorded in .git/config:
+// the format version and the extensions that change how on-disk data must be
+// interpreted, most importantly the object format (hash algorithm).
+package repoformat
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/gitconfig"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+)
+
+// ErrUnsupportedVersion is returned for repositories whose
+// core.repositoryformatversion is newer than this implementation understands.
+var ErrUnsupportedVersion = errors.New("unsupported repository format version")
+
+// Format describes a repository's on-disk format.
+//
+// Git only honours extensions when core.repositoryformatversion is 1, so a
+// SHA-256 repository is recorded as:
+//
+//	[core]
+//	    repositoryformatversion = 1
+//	[extensions]
+//	    objectformat = sha256
+type Format struct {
+	// Version is core.repositoryformatversion (0 or 1)
+	Version int
+
+	// ObjectFormat is extensions.objectFormat, the algorithm naming objects
+	ObjectFormat objects.HashAlgorithm
+}
+
+// Default returns the format of a repository with no extensions: version 0
+// and SHA-1 object names.
+func Default() Format {
+	return Format{Version: 0, ObjectFormat: objects.DefaultHashAlgorithm}
+}
+
+// ForObjectFormat returns the format a new repository using the given hash
+// algorithm should be created with.
+func ForObjectFormat(algorithm objects.HashAlgorithm) Format {
+	if algorithm == objects.DefaultHashAlgorithm {
+		return Default()
+	}
+	return Format{Version: 1, ObjectFormat: algorithm}
+}
+
+// Read returns the format recorded in sourceDir's config file. A missing
+// config file describes a default (SHA-1) repository.
+//
+// Parameters:
+//   - sourceDir: The repository's .git directory
+//
+// Returns:
+//   - Format: The repository format
+//   - error: ErrUnsupportedVersion, an unknown object format, or an I/O error
+func Read(sourceDir scpath.SourcePath) (Format, error) {
+	file, err := os.Open(sourceDir.ConfigPath().String())
+	if os.IsNotExist(err) {
+		return Default(), nil
+	}
+	if err != nil {
+		return Format{}, fmt.Errorf("failed to read repository config: %w", err)
+	}
+	defer file.Close()
+
+	values, err := scanConfig(file)
+	if err != nil {
+		return Format{}, fmt.Errorf("failed to read repository config: %w", err)
+	}
+
+	format := Default()
+	if v, ok := values["core.repositoryformatversion"]; ok {
+		format.Version, err = strconv.Atoi(v)
+		if err != nil {
+			return Format{}, fmt.Errorf("invalid core.repositoryformatversion: %q", v)
+		}
+	}
+	if format.Version > 1 {
+		return Format{}, fmt.Errorf("%w: %d", ErrUnsupportedVersion, format.Version)
+	}
+
+	// Extensions are ignored in version 0 repositories, as in Git
+	if v, ok := values["extensions.objectformat"]; ok && format.Version == 1 {
+		format.ObjectFormat, err = objects.ParseHashAlgorithm(v)
+		if err != nil {
+			return Format{}, fmt.Errorf("invalid extensions.objectformat: %w", err)
+		}
+	}
+
+	return format, nil
+}
+
+// ReadObjectFormat returns just the hash algorithm of the repository whose
+// .git directory is sourceDir.
+func ReadObjectFormat(sourceDir scpath.SourcePath) (objects.HashAlgorithm, error) {
+	format, err := Read(sourceDir)
+	if err != nil {
+		return "", err
+	}
+	return format.ObjectFormat, nil
+}
+
+// scanConfig collects the "section.key" values of a Git-style INI file.
+// Section and key names are case-insensitive and returned in lower case;
+// subsections are kept as "section.sub.key". The last value of a key wins.
+func scanConfig(file *os.File) (map[string]string, error) {
+	entries, err := gitconfig.Parse(file)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(entries))
+	for _, e := range entries {
+		values[e.Name()] = e.Value
+	}
+	return values, nil
+}
@@ -0,0 +1,83 @@
+package repoformat
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+)
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		want    Format
+		wantErr error
+	}{
+		{
+			name:   "default repository",
+			config: "[core]\n\trepositoryformatversion = 0\n\tbare = false\n",
+			want:   Default(),
+		},
+		{
+			name:   "sha256 repository",
+			config: "[core]\n\trepositoryformatversion = 1\n[extensions]\n\tobjectFormat = sha256\n",
+			want:   Format{Version: 1, ObjectFormat: objects.SHA256},
+		},
+		{
+			name:   "extensions ignored in version 0",
+			config: "[core]\n\trepositoryformatversion = 0\n[extensions]\n\tobjectformat = sha256\n",
+			want:   Default(),
+		},
+		{
+			name:    "future version",
+			config:  "[core]\n\trepositoryformatversion = 2\n",
+			wantErr: ErrUnsupportedVersion,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "config"), []byte(tt.config), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := Read(scpath.SourcePath(dir))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Read() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Read() failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Read() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRead_MissingConfig(t *testing.T) {
+	got, err := Read(scpath.SourcePath(t.TempDir()))
+	if err != nil || got != Default() {
+		t.Errorf("Read() = %+v, %v; want default format", got, err)
+	}
+}
+
+func TestRead_UnknownObjectFormat(t *testing.T) {
+	dir := t.TempDir()
+	config := "[core]\n\trepositoryformatversion = 1\n[extensions]\n\tobjectformat = md5\n"
+	if err := os.WriteFile(filepath.Join(dir, "config"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Read(scpath.SourcePath(dir)); err == nil {
+		t.Error("Read() should reject an unknown object format")
+	}
+}
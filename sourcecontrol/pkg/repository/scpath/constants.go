@@ -7,6 +7,15 @@ const (
 	// ObjectsDir is the name of the objects directory
 	ObjectsDir = "objects"
 
+	// PackDir is the name of the packfile directory inside objects
+	PackDir = "pack"
+
+	// InfoDir is the name of the info directory inside objects
+	InfoDir = "info"
+
+	// AlternatesFile lists borrowed object directories, inside objects/info
+	AlternatesFile = "alternates"
+
 	// RefsDir is the name of the refs directory
 	RefsDir = "refs"
 
@@ -24,4 +33,7 @@ const (
 
 	// HeadFile is the name of the HEAD file
 	HeadFile = "HEAD"
+
+	// PackedRefsFile is the name of the file holding packed references
+	PackedRefsFile = "packed-refs"
 )
@@ -58,15 +58,21 @@ func (sp SourcePath) ConfigPath() SourcePath {
 	return sp.Join(ConfigFile)
 }
 
+// PackedRefsPath returns the path to the packed-refs file
+func (sp SourcePath) PackedRefsPath() SourcePath {
+	return sp.Join(PackedRefsFile)
+}
+
 // TagsPath returns the path to the tags directory
 func (sp SourcePath) TagsPath() SourcePath {
 	return sp.Join(RefsDir, TagsDir)
 }
 
 // ObjectFilePath returns the path to an object file given its hash
+// (40 hex characters for SHA-1, 64 for SHA-256)
 // Example: hash "abcdef..." returns ".source/objects/ab/cdef..."
 func (sp SourcePath) ObjectFilePath(hash string) SourcePath {
-	if len(hash) != 40 {
+	if len(hash) != 40 && len(hash) != 64 {
 		return ""
 	}
 	prefix := hash[:2]
@@ -19,10 +19,13 @@ type Repository interface {
 	// SourceDirectory returns the path to the .source directory (equivalent to .git)
 	SourceDirectory() scpath.SourcePath
 
+	// HashAlgorithm returns the hash algorithm naming the repository's objects
+	HashAlgorithm() objects.HashAlgorithm
+
 	// ObjectStore returns the object store for this repository
 	ObjectStore() store.ObjectStore
 
-	// ReadObject reads a Git object by its SHA-1 hash
+	// ReadObject reads a Git object by its hash
 	ReadObject(hash objects.ObjectHash) (objects.BaseObject, error)
 
 	// WriteObject writes a Git object to the repository
@@ -2,12 +2,14 @@ package sourcerepo
 
 import (
 	"fmt"
+	"runtime"
 
 	"github.com/utkarsh5026/SourceControl/pkg/common/fileops"
 	"github.com/utkarsh5026/SourceControl/pkg/objects"
 	"github.com/utkarsh5026/SourceControl/pkg/objects/blob"
 	"github.com/utkarsh5026/SourceControl/pkg/objects/commit"
 	"github.com/utkarsh5026/SourceControl/pkg/objects/tree"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/repoformat"
 	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
 	"github.com/utkarsh5026/SourceControl/pkg/store"
 )
@@ -63,6 +65,22 @@ func NewSourceRepository() *SourceRepository {
 // Parameters:
 //   - path: The directory path where the repository should be initialized
 func (sr *SourceRepository) Initialize(path scpath.RepositoryPath) error {
+	return sr.InitializeWithHashAlgorithm(path, objects.DefaultHashAlgorithm)
+}
+
+// InitializeWithHashAlgorithm creates a new repository whose objects are
+// named with the given hash algorithm (git init --object-format). A SHA-256
+// repository records extensions.objectFormat in its config, which requires
+// repository format version 1.
+//
+// Parameters:
+//   - path: The directory path where the repository should be initialized
+//   - algorithm: The object format, objects.SHA1 or objects.SHA256
+func (sr *SourceRepository) InitializeWithHashAlgorithm(path scpath.RepositoryPath, algorithm objects.HashAlgorithm) error {
+	if _, err := objects.ParseHashAlgorithm(algorithm.String()); err != nil {
+		return err
+	}
+
 	exists, err := RepositoryExists(path)
 	if err != nil {
 		return fmt.Errorf("failed to check if repository exists: %w", err)
@@ -78,12 +96,13 @@ func (sr *SourceRepository) Initialize(path scpath.RepositoryPath) error {
 		return fmt.Errorf("failed to create directories: %w", err)
 	}
 
-	if err := sr.objectStore.Initialize(sr.workingDir); err != nil {
-		return fmt.Errorf("failed to initialize object store: %w", err)
+	// The config must exist before the object store reads the object format from it
+	if err := sr.createInitialFiles(repoformat.ForObjectFormat(algorithm)); err != nil {
+		return fmt.Errorf("failed to create initial files: %w", err)
 	}
 
-	if err := sr.createInitialFiles(); err != nil {
-		return fmt.Errorf("failed to create initial files: %w", err)
+	if err := sr.objectStore.Initialize(sr.workingDir); err != nil {
+		return fmt.Errorf("failed to initialize object store: %w", err)
 	}
 
 	sr.initialized = true
@@ -121,6 +140,12 @@ func (sr *SourceRepository) SourceDirectory() scpath.SourcePath {
 	return sr.sourceDir
 }
 
+// HashAlgorithm returns the repository's object format, the hash algorithm
+// naming its objects.
+func (sr *SourceRepository) HashAlgorithm() objects.HashAlgorithm {
+	return sr.objectStore.HashAlgorithm()
+}
+
 // ObjectStore returns the object store for this repository.
 //
 // The object store provides the interface for reading and writing Git objects
@@ -132,6 +157,48 @@ func (sr *SourceRepository) ObjectStore() store.ObjectStore {
 	return sr.objectStore
 }
 
+// EnableObjectCache puts an LRU cache of parsed objects in front of the
+// repository's object store, for commands that read the same commits and
+// trees many times. Calling it again returns the existing cache.
+//
+// Parameters:
+//   - capacity: Maximum number of cached objects (<= 0 selects the default)
+//
+// Returns:
+//   - *store.CachedObjectStore: The cache, e.g. to log its statistics
+func (sr *SourceRepository) EnableObjectCache(capacity int) *store.CachedObjectStore {
+	if cached, ok := sr.objectStore.(*store.CachedObjectStore); ok {
+		return cached
+	}
+
+	cached := store.NewCachedObjectStore(sr.objectStore, capacity)
+	sr.objectStore = cached
+	return cached
+}
+
+// WithObjectStore returns a copy of the repository that reads and writes
+// objects through objectStore instead of the repository's own store. Paths,
+// refs and the index are shared with the original.
+//
+// Combined with a store.OverlayObjectStore this allows speculative
+// operations, such as a trial merge, whose objects can be discarded:
+//
+//	overlay := store.NewOverlayObjectStore(repo.ObjectStore())
+//	trial := repo.WithObjectStore(overlay)
+//	// ... build trees and commits through trial ...
+//	overlay.Discard()
+//
+// Parameters:
+//   - objectStore: An initialized object store
+//
+// Returns:
+//   - *SourceRepository: A repository sharing this one's paths
+func (sr *SourceRepository) WithObjectStore(objectStore store.ObjectStore) *SourceRepository {
+	clone := *sr
+	clone.objectStore = objectStore
+	return &clone
+}
+
 // ReadObject reads a Git object by its SHA-1 hash from the object store.
 //
 // This method retrieves and deserializes a Git object (blob, tree, commit, or tag)
@@ -234,15 +301,26 @@ func (sr *SourceRepository) createDirectories() error {
 //  3. config: Contains the repository configuration in Git INI format
 //
 // The config file includes:
-//   - repositoryformatversion = 0 (Git object format version)
-//   - filemode = false (disable executable bit tracking on Windows)
+//   - repositoryformatversion = 0 (1 when an extension is needed)
+//   - filemode = true, or false on Windows, whose filesystems have no
+//     executable bit to track
 //   - bare = false (this is a repository with a working directory)
+//   - extensions.objectformat = sha256 (SHA-256 repositories only)
 //
 // All files are created with permissions 0644 (rw-r--r--).
 //
 // Returns:
 //   - error: nil on success, or an error if any file creation fails
-func (sr *SourceRepository) createInitialFiles() error {
+func (sr *SourceRepository) createInitialFiles(format repoformat.Format) error {
+	config := fmt.Sprintf(`[core]
+    repositoryformatversion = %d
+    filemode = %t
+    bare = false
+`, format.Version, runtime.GOOS != "windows")
+	if format.ObjectFormat != objects.DefaultHashAlgorithm {
+		config += fmt.Sprintf("[extensions]\n    objectformat = %s\n", format.ObjectFormat)
+	}
+
 	files := []struct {
 		path    scpath.SourcePath
 		content string
@@ -259,13 +337,9 @@ func (sr *SourceRepository) createInitialFiles() error {
 			name:    "description",
 		},
 		{
-			path: sr.sourceDir.ConfigPath(),
-			content: `[core]
-    repositoryformatversion = 0
-    filemode = false
-    bare = false
-`,
-			name: "config",
+			path:    sr.sourceDir.ConfigPath(),
+			content: config,
+			name:    "config",
 		},
 	}
 
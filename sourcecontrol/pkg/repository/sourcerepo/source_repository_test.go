@@ -3,10 +3,13 @@ package sourcerepo
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
 	"github.com/utkarsh5026/SourceControl/pkg/objects/blob"
 	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+	"github.com/utkarsh5026/SourceControl/pkg/store"
 )
 
 // setupTestDirectory creates a temporary test directory
@@ -241,6 +244,42 @@ func TestSourceRepository_WriteAndReadObject(t *testing.T) {
 	}
 }
 
+func TestSourceRepository_WithObjectStore(t *testing.T) {
+	repoPath, cleanup := setupTestDirectory(t)
+	defer cleanup()
+
+	repo := NewSourceRepository()
+	if err := repo.Initialize(repoPath); err != nil {
+		t.Fatalf("Initialize() failed: %v", err)
+	}
+
+	overlay := store.NewOverlayObjectStore(repo.ObjectStore())
+	trial := repo.WithObjectStore(overlay)
+
+	if trial.WorkingDirectory() != repo.WorkingDirectory() {
+		t.Errorf("WithObjectStore() changed the working directory to %s", trial.WorkingDirectory())
+	}
+
+	hash, err := trial.WriteObject(blob.NewBlob([]byte("speculative")))
+	if err != nil {
+		t.Fatalf("WriteObject() failed: %v", err)
+	}
+
+	if obj, _ := repo.ReadObject(hash); obj != nil {
+		t.Error("object written through the overlay is visible in the original repository")
+	}
+	if obj, _ := trial.ReadObject(hash); obj == nil {
+		t.Error("object written through the overlay is not readable through it")
+	}
+
+	if err := overlay.Commit(); err != nil {
+		t.Fatalf("Commit() failed: %v", err)
+	}
+	if obj, _ := repo.ReadObject(hash); obj == nil {
+		t.Error("committed object is not visible in the original repository")
+	}
+}
+
 func TestRepositoryExists(t *testing.T) {
 	repoPath, cleanup := setupTestDirectory(t)
 	defer cleanup()
@@ -421,3 +460,70 @@ func containsSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestSourceRepository_EnableObjectCache(t *testing.T) {
+	repoPath, cleanup := setupTestDirectory(t)
+	defer cleanup()
+
+	repo := NewSourceRepository()
+	if err := repo.Initialize(repoPath); err != nil {
+		t.Fatalf("Initialize() failed: %v", err)
+	}
+
+	hash, err := repo.WriteObject(blob.NewBlob([]byte("cached")))
+	if err != nil {
+		t.Fatalf("WriteObject() failed: %v", err)
+	}
+
+	cache := repo.EnableObjectCache(0)
+	if repo.EnableObjectCache(0) != cache {
+		t.Error("EnableObjectCache() wrapped the store twice")
+	}
+
+	repo.ReadObject(hash)
+	repo.ReadObject(hash)
+	if stats := cache.Stats(); stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("Stats() = %+v, want 1 hit and 1 miss", stats)
+	}
+}
+
+func TestSourceRepository_InitializeSHA256(t *testing.T) {
+	repoPath, cleanup := setupTestDirectory(t)
+	defer cleanup()
+
+	repo := NewSourceRepository()
+	if err := repo.InitializeWithHashAlgorithm(repoPath, objects.SHA256); err != nil {
+		t.Fatalf("InitializeWithHashAlgorithm() failed: %v", err)
+	}
+
+	config, err := os.ReadFile(repoPath.SourcePath().ConfigPath().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"repositoryformatversion = 1", "[extensions]", "objectformat = sha256"} {
+		if !strings.Contains(string(config), want) {
+			t.Errorf("config missing %q:\n%s", want, config)
+		}
+	}
+
+	// Matches git hash-object --object-format=sha256 /dev/null
+	const emptyBlob = "473a0f4c3be8a93681a267e3b1e9a7dcda1185436fe141f7749120a303721813"
+	hash, err := repo.WriteObject(blob.NewBlob(nil))
+	if err != nil {
+		t.Fatalf("WriteObject() failed: %v", err)
+	}
+	if hash.String() != emptyBlob {
+		t.Errorf("WriteObject() = %s, want %s", hash, emptyBlob)
+	}
+
+	reopened, err := Open(repoPath)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	if reopened.HashAlgorithm() != objects.SHA256 {
+		t.Errorf("HashAlgorithm() = %s after reopening, want sha256", reopened.HashAlgorithm())
+	}
+	if _, err := reopened.ReadBlobObject(hash); err != nil {
+		t.Errorf("ReadBlobObject() failed: %v", err)
+	}
+}
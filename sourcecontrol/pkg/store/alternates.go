@@ -0,0 +1,256 @@
+package store
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/utkarsh5026/SourceControl/pkg/common/fileops"
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+)
+
+// Alternates let a repository borrow objects from other object directories
+// instead of storing its own copies. They are listed one per line in
+// objects/info/alternates:
+//
+//	┌─ .git/objects/info/alternates ───────────┐
+//	│ /srv/mirrors/upstream.git/objects        │ ← absolute path
+//	│ ../../shared/.git/objects                │ ← relative to .git/objects
+//	└──────────────────────────────────────────┘
+//
+// Reads that miss the local loose objects and packs fall through to each
+// alternate in order; an alternate's own alternates are followed too. Writes
+// always go to the local directory, and objects an alternate already has are
+// not written again.
+//
+// The borrowing repository depends on the alternates: if an object is pruned
+// from one of them, repositories that borrowed it lose it as well.
+
+// maxAlternateDepth bounds how many alternates files are followed in a
+// chain (an alternate's own alternates, and so on), as in Git.
+const maxAlternateDepth = 5
+
+// AlternatesPath returns the path of the alternates file of the object
+// directory objectsDir.
+func AlternatesPath(objectsDir scpath.AbsolutePath) scpath.AbsolutePath {
+	return objectsDir.Join(scpath.InfoDir, scpath.AlternatesFile)
+}
+
+// Alternates returns the object directories this store borrows from,
+// including those reached through other alternates, in search order.
+func (f *FileObjectStore) Alternates() []scpath.AbsolutePath {
+	dirs := make([]scpath.AbsolutePath, len(f.alternates))
+	for i, alt := range f.alternates {
+		dirs[i] = alt.objectsPath.ToAbsolutePath()
+	}
+	return dirs
+}
+
+// AddAlternate appends objectsDir to objects/info/alternates and starts
+// reading from it. Directories already listed are not added twice.
+//
+// Like Initialize, AddAlternate must not be called concurrently with other
+// methods.
+//
+// Parameters:
+//   - objectsDir: The objects directory of the repository to borrow from
+//
+// Returns:
+//   - error: If objectsDir is not a directory or the file cannot be written
+func (f *FileObjectStore) AddAlternate(objectsDir scpath.AbsolutePath) error {
+	if !f.IsInitialized() {
+		return fmt.Errorf("object store not initialized")
+	}
+
+	info, err := os.Stat(objectsDir.String())
+	if err != nil || !info.IsDir() {
+		return fmt.Errorf("alternate object directory %s does not exist", objectsDir)
+	}
+
+	own := f.objectsPath.ToAbsolutePath()
+	path := AlternatesPath(own)
+	listed, err := readAlternatesFile(own)
+	if err != nil {
+		return err
+	}
+	for _, dir := range listed {
+		if sameDir(dir, objectsDir.String()) {
+			return nil
+		}
+	}
+
+	if err := fileops.EnsureDir(path.Dir()); err != nil {
+		return fmt.Errorf("failed to create %s: %w", scpath.InfoDir, err)
+	}
+	file, err := os.OpenFile(path.String(), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open alternates file: %w", err)
+	}
+	if _, err := fmt.Fprintln(file, filepath.Clean(objectsDir.String())); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write alternates file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write alternates file: %w", err)
+	}
+
+	return f.loadAlternates()
+}
+
+// HasLocalObject reports whether the object is stored in this repository's
+// own object directory, as a loose object or in one of its packs, ignoring
+// alternates.
+func (f *FileObjectStore) HasLocalObject(hash objects.ObjectHash) (bool, error) {
+	filePath, err := f.validateAndResolvePath(hash)
+	if err != nil {
+		return false, err
+	}
+
+	exists, err := fileops.Exists(filePath.ToAbsolutePath())
+	if err != nil || exists {
+		return exists, err
+	}
+
+	p, err := f.findPack(hash)
+	if err != nil {
+		return false, fmt.Errorf("failed to search packs: %w", err)
+	}
+	return p != nil, nil
+}
+
+// loadAlternates reads objects/info/alternates and, recursively, the
+// alternates of every listed directory. Each directory is opened once no
+// matter how often it is listed, so cycles (a repository borrowing from one
+// that borrows back) terminate. Missing directories are skipped, as Git
+// only warns about them.
+func (f *FileObjectStore) loadAlternates() error {
+	own := f.objectsPath.ToAbsolutePath()
+	seen := map[string]bool{canonicalDir(own.String()): true}
+
+	var alternates []*FileObjectStore
+	var visit func(dir scpath.AbsolutePath, depth int) error
+	visit = func(dir scpath.AbsolutePath, depth int) error {
+		if depth > maxAlternateDepth {
+			return nil
+		}
+
+		listed, err := readAlternatesFile(dir)
+		if err != nil {
+			return err
+		}
+		for _, alt := range listed {
+			key := canonicalDir(alt)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			if info, err := os.Stat(alt); err != nil || !info.IsDir() {
+				continue
+			}
+
+			alternates = append(alternates, &FileObjectStore{
+				objectsPath: scpath.SourcePath(alt),
+				algorithm:   f.algorithm,
+			})
+			if err := visit(scpath.AbsolutePath(alt), depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := visit(own, 1); err != nil {
+		return err
+	}
+
+	f.alternates = alternates
+	return nil
+}
+
+// readAlternatesFile returns the directories listed in objectsDir's
+// alternates file as absolute paths. Relative entries are resolved against
+// objectsDir; blank lines and '#' comments are ignored. A missing file lists
+// nothing.
+func readAlternatesFile(objectsDir scpath.AbsolutePath) ([]string, error) {
+	file, err := os.Open(AlternatesPath(objectsDir).String())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alternates file: %w", err)
+	}
+	defer file.Close()
+
+	var dirs []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !filepath.IsAbs(line) {
+			line = filepath.Join(objectsDir.String(), line)
+		}
+		dirs = append(dirs, filepath.Clean(line))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read alternates file: %w", err)
+	}
+	return dirs, nil
+}
+
+// canonicalDir returns a key identifying a directory regardless of how the
+// path to it is spelled.
+func canonicalDir(dir string) string {
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		return resolved
+	}
+	return filepath.Clean(dir)
+}
+
+// sameDir reports whether a and b name the same directory.
+func sameDir(a, b string) bool {
+	return canonicalDir(a) == canonicalDir(b)
+}
+
+// readAlternate returns the object from the first alternate that has it, or
+// nil if none does.
+func (f *FileObjectStore) readAlternate(hash objects.ObjectHash) (objects.SerializedObject, error) {
+	for _, alt := range f.alternates {
+		serialized, err := alt.readSerialized(hash)
+		if err != nil {
+			return nil, fmt.Errorf("alternate %s: %w", alt.objectsPath, err)
+		}
+		if serialized != nil {
+			return serialized, nil
+		}
+	}
+	return nil, nil
+}
+
+// hasAlternateObject reports whether any alternate has the object.
+func (f *FileObjectStore) hasAlternateObject(hash objects.ObjectHash) (bool, error) {
+	for _, alt := range f.alternates {
+		if ok, err := alt.HasLocalObject(hash); err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+// openAlternateBlob serves OpenBlob for blobs found only in an alternate.
+func (f *FileObjectStore) openAlternateBlob(hash objects.ObjectHash) (io.ReadCloser, int64, error) {
+	for _, alt := range f.alternates {
+		if ok, err := alt.HasLocalObject(hash); err != nil {
+			return nil, 0, err
+		} else if ok {
+			return alt.OpenBlob(hash)
+		}
+	}
+	return nil, 0, fmt.Errorf("%w: %s", ErrObjectNotFound, hash)
+}
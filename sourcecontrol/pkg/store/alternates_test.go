@@ -0,0 +1,167 @@
+package store
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects/blob"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+)
+
+// newAlternateStore creates an initialized store in a fresh temporary repository.
+func newAlternateStore(t *testing.T) *FileObjectStore {
+	t.Helper()
+
+	repoPath, cleanup := setupTestRepo(t)
+	t.Cleanup(cleanup)
+
+	s := NewFileObjectStore()
+	if err := s.Initialize(repoPath); err != nil {
+		t.Fatalf("Initialize() failed: %v", err)
+	}
+	return s
+}
+
+func objectsDir(s *FileObjectStore) scpath.AbsolutePath {
+	return s.GetObjectsPath().ToAbsolutePath()
+}
+
+func TestFileObjectStore_ReadThroughAlternate(t *testing.T) {
+	base := newAlternateStore(t)
+	borrower := newAlternateStore(t)
+
+	hash, err := base.WriteObject(blob.NewBlob([]byte("shared content")))
+	if err != nil {
+		t.Fatalf("WriteObject() failed: %v", err)
+	}
+
+	if err := borrower.AddAlternate(objectsDir(base)); err != nil {
+		t.Fatalf("AddAlternate() failed: %v", err)
+	}
+	// Adding the same directory again must not list it twice
+	if err := borrower.AddAlternate(objectsDir(base)); err != nil {
+		t.Fatalf("second AddAlternate() failed: %v", err)
+	}
+	if got := borrower.Alternates(); len(got) != 1 {
+		t.Fatalf("Alternates() = %v, want one entry", got)
+	}
+
+	obj, err := borrower.ReadObject(hash)
+	if err != nil || obj == nil {
+		t.Fatalf("ReadObject() through alternate = %v, %v", obj, err)
+	}
+	if has, _ := borrower.HasObject(hash); !has {
+		t.Error("HasObject() = false for an object in an alternate")
+	}
+	if local, _ := borrower.HasLocalObject(hash); local {
+		t.Error("HasLocalObject() = true for a borrowed object")
+	}
+
+	rc, size, err := borrower.OpenBlob(hash)
+	if err != nil {
+		t.Fatalf("OpenBlob() through alternate failed: %v", err)
+	}
+	content, _ := io.ReadAll(rc)
+	rc.Close()
+	if size != int64(len("shared content")) || string(content) != "shared content" {
+		t.Errorf("OpenBlob() = %q (%d bytes)", content, size)
+	}
+
+	// Writing a borrowed object must not copy it into the local directory
+	if _, err := borrower.WriteObject(blob.NewBlob([]byte("shared content"))); err != nil {
+		t.Fatalf("WriteObject() failed: %v", err)
+	}
+	if count, _ := borrower.ObjectCount(); count != 0 {
+		t.Errorf("borrower has %d local objects, want 0", count)
+	}
+
+	// New objects are written locally, never into the alternate
+	own, err := borrower.WriteObject(blob.NewBlob([]byte("local content")))
+	if err != nil {
+		t.Fatalf("WriteObject() failed: %v", err)
+	}
+	if local, _ := borrower.HasLocalObject(own); !local {
+		t.Error("new object was not written locally")
+	}
+	if has, _ := base.HasObject(own); has {
+		t.Error("new object was written to the alternate")
+	}
+}
+
+func TestFileObjectStore_RecursiveAlternates(t *testing.T) {
+	a := newAlternateStore(t)
+	b := newAlternateStore(t)
+	c := newAlternateStore(t)
+
+	hash, err := a.WriteObject(blob.NewBlob([]byte("deep")))
+	if err != nil {
+		t.Fatalf("WriteObject() failed: %v", err)
+	}
+
+	// c -> b -> a, and a -> c to close a loop
+	if err := b.AddAlternate(objectsDir(a)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.AddAlternate(objectsDir(b)); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.AddAlternate(objectsDir(c)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopen c so its alternates are loaded from disk
+	reopened := NewFileObjectStore()
+	if err := reopened.Initialize(scpath.RepositoryPath(filepath.Dir(filepath.Dir(objectsDir(c).String())))); err != nil {
+		t.Fatalf("Initialize() failed: %v", err)
+	}
+	if got := reopened.Alternates(); len(got) != 2 {
+		t.Fatalf("Alternates() = %v, want b and a", got)
+	}
+
+	obj, err := reopened.ReadObject(hash)
+	if err != nil || obj == nil {
+		t.Fatalf("ReadObject() through nested alternate = %v, %v", obj, err)
+	}
+}
+
+func TestFileObjectStore_AlternatesFile(t *testing.T) {
+	base := newAlternateStore(t)
+	borrower := newAlternateStore(t)
+
+	hash, err := base.WriteObject(blob.NewBlob([]byte("relative")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rel, err := filepath.Rel(objectsDir(borrower).String(), objectsDir(base).String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := AlternatesPath(objectsDir(borrower)).String()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := "# comment\n\n/does/not/exist\n" + rel + "\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := borrower.loadAlternates(); err != nil {
+		t.Fatalf("loadAlternates() failed: %v", err)
+	}
+	if got := borrower.Alternates(); len(got) != 1 {
+		t.Fatalf("Alternates() = %v, want only the existing relative entry", got)
+	}
+	if has, _ := borrower.HasObject(hash); !has {
+		t.Error("object in relative alternate not found")
+	}
+}
+
+func TestFileObjectStore_AddAlternateMissingDir(t *testing.T) {
+	s := newAlternateStore(t)
+	if err := s.AddAlternate(scpath.AbsolutePath(filepath.Join(t.TempDir(), "missing"))); err == nil {
+		t.Error("AddAlternate() succeeded for a missing directory")
+	}
+}
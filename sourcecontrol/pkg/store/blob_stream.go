@@ -0,0 +1,309 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+)
+
+// BlobStreamThreshold is the size above which callers should move blob
+// content through WriteBlobStream and OpenBlob instead of loading it into a
+// blob.Blob. Below it the in-memory path is simpler and just as fast.
+const BlobStreamThreshold int64 = 8 << 20
+
+// maxStreamHeaderSize bounds the "<type> <size>\0" header read from a stream
+// so a corrupt object cannot make us buffer an unbounded line.
+const maxStreamHeaderSize = 64
+
+var (
+	// ErrObjectNotFound is returned by OpenBlob when no loose or packed
+	// object has the requested hash.
+	ErrObjectNotFound = errors.New("object not found")
+
+	// ErrNotBlob is returned by OpenBlob when the object exists but is not a blob.
+	ErrNotBlob = errors.New("object is not a blob")
+
+	// ErrSizeMismatch is returned by WriteBlobStream when the reader yields
+	// more or fewer bytes than the declared size.
+	ErrSizeMismatch = errors.New("stream size does not match declared size")
+)
+
+// WriteBlobStream stores a blob whose content is read from r without holding
+// it in memory. size must be the exact number of bytes r will produce, since
+// it is part of the object header that is hashed before any content.
+//
+// The content is hashed and zlib-compressed in a single pass into a temporary
+// file under objects/, which is then renamed to its final location. If the
+// object already exists the temporary file is discarded.
+//
+// Parameters:
+//   - r: The blob content
+//   - size: The number of bytes r yields
+//
+// Returns:
+//   - objects.ObjectHash: The hash of the stored blob
+//   - error: ErrSizeMismatch if r does not yield exactly size bytes, or an I/O error
+func (f *FileObjectStore) WriteBlobStream(r io.Reader, size int64) (objects.ObjectHash, error) {
+	if !f.IsInitialized() {
+		return "", fmt.Errorf("object store not initialized")
+	}
+	if size < 0 {
+		return "", fmt.Errorf("invalid blob size: %d", size)
+	}
+
+	tmp, err := os.CreateTemp(f.objectsPath.String(), tempObjectPattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary object: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer func() {
+		tmp.Close()
+		os.Remove(tmpPath)
+	}()
+
+	hasher := f.HashAlgorithm().New()
+	buffered := bufio.NewWriter(tmp)
+	zw := zlib.NewWriter(buffered)
+	w := io.MultiWriter(hasher, zw)
+
+	if _, err := w.Write(objects.CreateHeader(objects.BlobType, size)); err != nil {
+		return "", fmt.Errorf("failed to write object header: %w", err)
+	}
+	if err := copyExactly(w, r, size); err != nil {
+		return "", err
+	}
+
+	if err := zw.Close(); err != nil {
+		return "", fmt.Errorf("failed to compress object: %w", err)
+	}
+	if err := buffered.Flush(); err != nil {
+		return "", fmt.Errorf("failed to write object: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to write object: %w", err)
+	}
+
+	hash := objects.NewObjectHashFromRaw(hasher.Sum(nil))
+
+	filePath, err := f.resolveObjectPath(hash)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve object path: %w", err)
+	}
+	absPath := filePath.ToAbsolutePath()
+	if _, err := os.Stat(absPath.String()); err == nil {
+		freshenLooseObject(absPath)
+		return hash, nil
+	}
+	if packed, err := f.knownPack(hash); err != nil {
+		return "", fmt.Errorf("failed to search packs: %w", err)
+	} else if packed != nil {
+		return hash, nil
+	}
+	if borrowed, err := f.hasAlternateObject(hash); err != nil {
+		return "", fmt.Errorf("failed to search alternates: %w", err)
+	} else if borrowed {
+		return hash, nil
+	}
+
+	if err := installLooseObject(tmpPath, absPath); err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+// copyExactly copies exactly size bytes from r to w and fails if r ends early
+// or still has data afterwards (e.g. a file that grew while being read).
+func copyExactly(w io.Writer, r io.Reader, size int64) error {
+	n, err := io.CopyN(w, r, size)
+	if err == io.EOF {
+		return fmt.Errorf("%w: expected %d bytes, got %d", ErrSizeMismatch, size, n)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read blob content: %w", err)
+	}
+
+	var extra [1]byte
+	if n, _ := r.Read(extra[:]); n > 0 {
+		return fmt.Errorf("%w: more than %d bytes", ErrSizeMismatch, size)
+	}
+	return nil
+}
+
+// OpenBlob returns a reader over a blob's content along with its size, without
+// loading the whole blob into memory.
+//
+// Loose blobs are inflated as they are read, and the object hash is verified
+// once the reader reaches the end. Packed blobs are reconstructed in memory
+// first, since delta resolution needs the complete base anyway.
+//
+// Parameters:
+//   - hash: The hash of the blob to open
+//
+// Returns:
+//   - io.ReadCloser: The blob content; the caller must close it
+//   - int64: The content size in bytes
+//   - error: ErrObjectNotFound, ErrNotBlob, or an I/O error
+func (f *FileObjectStore) OpenBlob(hash objects.ObjectHash) (io.ReadCloser, int64, error) {
+	filePath, err := f.validateAndResolvePath(hash)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rc, objType, size, err := openLooseStream(filePath.String(), hash)
+	if errors.Is(err, os.ErrNotExist) {
+		return f.openPackedBlob(hash)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if objType != objects.BlobType {
+		rc.Close()
+		return nil, 0, fmt.Errorf("%w: %s is a %s", ErrNotBlob, hash.Short(), objType)
+	}
+	return rc, size, nil
+}
+
+// openPackedBlob serves OpenBlob for objects that are not stored loose,
+// looking in the packs and then in the alternates.
+func (f *FileObjectStore) openPackedBlob(hash objects.ObjectHash) (io.ReadCloser, int64, error) {
+	serialized, err := f.readPacked(hash)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read packed object: %w", err)
+	}
+	if serialized == nil {
+		return f.openAlternateBlob(hash)
+	}
+
+	objType, size, contentStart, err := serialized.ParseHeader()
+	if err != nil {
+		return nil, 0, err
+	}
+	if objType != objects.BlobType {
+		return nil, 0, fmt.Errorf("%w: %s is a %s", ErrNotBlob, hash.Short(), objType)
+	}
+
+	return io.NopCloser(bytes.NewReader(serialized[contentStart:])), size.Int64(), nil
+}
+
+// looseStream reads the content of a loose object after its header, hashing
+// everything it inflates so corruption is reported at EOF instead of being
+// passed on silently.
+type looseStream struct {
+	file      *os.File
+	inflater  io.ReadCloser
+	reader    *bufio.Reader
+	hasher    hash.Hash
+	expected  objects.ObjectHash
+	remaining int64
+}
+
+// openLooseStream opens a loose object file and consumes its header. Legacy
+// objects stored as raw DEFLATE are handled like CompressedData.Decompress
+// does: zlib is tried first when the data looks like zlib, with raw DEFLATE as
+// the fallback.
+func openLooseStream(path string, expected objects.ObjectHash) (*looseStream, objects.ObjectType, int64, error) {
+	s, objType, size, err := newLooseStream(path, expected, true)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		s, objType, size, err = newLooseStream(path, expected, false)
+	}
+	return s, objType, size, err
+}
+
+func newLooseStream(path string, expected objects.ObjectHash, tryZlib bool) (*looseStream, objects.ObjectType, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, "", 0, err
+	}
+
+	s := &looseStream{file: file, hasher: expected.Algorithm().New(), expected: expected}
+	compressed := bufio.NewReader(file)
+
+	useZlib := false
+	if tryZlib {
+		magic, _ := compressed.Peek(2)
+		useZlib = objects.CompressedData(magic).HasZlibHeader()
+	}
+	if useZlib {
+		s.inflater, err = zlib.NewReader(compressed)
+	} else {
+		s.inflater = flate.NewReader(compressed)
+	}
+	if err != nil {
+		file.Close()
+		return nil, "", 0, fmt.Errorf("failed to decompress object: %w", err)
+	}
+	s.reader = bufio.NewReader(s.inflater)
+
+	objType, size, err := s.readHeader()
+	if err != nil {
+		s.Close()
+		return nil, "", 0, err
+	}
+	s.remaining = size
+	return s, objType, size, nil
+}
+
+// readHeader consumes and parses the "<type> <size>\0" object header.
+func (s *looseStream) readHeader() (objects.ObjectType, int64, error) {
+	header := make([]byte, 0, maxStreamHeaderSize)
+	for len(header) < maxStreamHeaderSize {
+		b, err := s.reader.ReadByte()
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to read object header: %w", err)
+		}
+		header = append(header, b)
+		if b == objects.NullByte {
+			break
+		}
+	}
+
+	objType, size, _, err := objects.SerializedObject(header).ParseHeader()
+	if err != nil {
+		return "", 0, err
+	}
+	s.hasher.Write(header)
+	return objType, size.Int64(), nil
+}
+
+func (s *looseStream) Read(p []byte) (int, error) {
+	if s.remaining <= 0 {
+		return 0, s.finish()
+	}
+	if int64(len(p)) > s.remaining {
+		p = p[:s.remaining]
+	}
+
+	n, err := s.reader.Read(p)
+	s.hasher.Write(p[:n])
+	s.remaining -= int64(n)
+
+	if err == io.EOF {
+		if s.remaining > 0 {
+			return n, fmt.Errorf("object %s is truncated: %w", s.expected.Short(), io.ErrUnexpectedEOF)
+		}
+		err = nil
+	}
+	return n, err
+}
+
+// finish verifies the object once all of its content has been read.
+func (s *looseStream) finish() error {
+	if got := objects.NewObjectHashFromRaw(s.hasher.Sum(nil)); got != s.expected {
+		return fmt.Errorf("object %s is corrupt: content hashes to %s", s.expected.Short(), got.Short())
+	}
+	return io.EOF
+}
+
+func (s *looseStream) Close() error {
+	s.inflater.Close()
+	return s.file.Close()
+}
@@ -0,0 +1,226 @@
+package store
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"io"
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/blob"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/tree"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+)
+
+func newTestStore(t *testing.T) *FileObjectStore {
+	t.Helper()
+
+	repoPath, cleanup := setupTestRepo(t)
+	t.Cleanup(cleanup)
+
+	fs := NewFileObjectStore()
+	if err := fs.Initialize(repoPath); err != nil {
+		t.Fatalf("Initialize() failed: %v", err)
+	}
+	return fs
+}
+
+func TestWriteBlobStream_MatchesWriteObject(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	large := make([]byte, 3<<20)
+	rng.Read(large)
+
+	tests := []struct {
+		name    string
+		content []byte
+	}{
+		{"empty", []byte{}},
+		{"small text", []byte("hello, streaming world\n")},
+		{"large random", large},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := newTestStore(t)
+
+			hash, err := fs.WriteBlobStream(bytes.NewReader(tt.content), int64(len(tt.content)))
+			if err != nil {
+				t.Fatalf("WriteBlobStream() failed: %v", err)
+			}
+
+			want, _ := blob.NewBlob(tt.content).Hash()
+			if hash != want {
+				t.Fatalf("WriteBlobStream() hash = %s, want %s", hash, want)
+			}
+
+			// The loose object must be readable by the in-memory path too
+			obj, err := fs.ReadObject(hash)
+			if err != nil || obj == nil {
+				t.Fatalf("ReadObject() = %v, %v", obj, err)
+			}
+			content, _ := obj.Content()
+			if !bytes.Equal(content.Bytes(), tt.content) {
+				t.Error("ReadObject() content differs from streamed content")
+			}
+
+			// Writing the same content again is a no-op
+			again, err := fs.WriteBlobStream(bytes.NewReader(tt.content), int64(len(tt.content)))
+			if err != nil || again != hash {
+				t.Errorf("second WriteBlobStream() = %s, %v", again, err)
+			}
+
+			entries, _ := os.ReadDir(fs.GetObjectsPath().String())
+			for _, e := range entries {
+				if strings.HasPrefix(e.Name(), "tmp_obj_") {
+					t.Errorf("temporary file %s left behind", e.Name())
+				}
+			}
+		})
+	}
+}
+
+func TestWriteBlobStream_SizeMismatch(t *testing.T) {
+	fs := newTestStore(t)
+
+	if _, err := fs.WriteBlobStream(strings.NewReader("short"), 10); !errors.Is(err, ErrSizeMismatch) {
+		t.Errorf("short reader: err = %v, want ErrSizeMismatch", err)
+	}
+	if _, err := fs.WriteBlobStream(strings.NewReader("too long"), 3); !errors.Is(err, ErrSizeMismatch) {
+		t.Errorf("long reader: err = %v, want ErrSizeMismatch", err)
+	}
+
+	if n, _ := fs.LooseObjectCount(); n != 0 {
+		t.Errorf("failed writes left %d objects in the store", n)
+	}
+}
+
+func TestOpenBlob(t *testing.T) {
+	fs := newTestStore(t)
+
+	content := []byte(strings.Repeat("streamed line\n", 10000))
+	hash, err := fs.WriteObject(blob.NewBlob(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rc, size, err := fs.OpenBlob(hash)
+	if err != nil {
+		t.Fatalf("OpenBlob() failed: %v", err)
+	}
+	defer rc.Close()
+
+	if size != int64(len(content)) {
+		t.Errorf("size = %d, want %d", size, len(content))
+	}
+	got, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("reading blob failed: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Error("OpenBlob() content differs")
+	}
+
+	t.Run("missing object", func(t *testing.T) {
+		_, _, err := fs.OpenBlob(objects.ObjectHash(strings.Repeat("ab", 20)))
+		if !errors.Is(err, ErrObjectNotFound) {
+			t.Errorf("err = %v, want ErrObjectNotFound", err)
+		}
+	})
+
+	t.Run("not a blob", func(t *testing.T) {
+		entry, _ := tree.NewTreeEntry(objects.FileModeRegular, "file.txt", hash)
+		treeHash, err := fs.WriteObject(tree.NewTree([]*tree.TreeEntry{entry}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := fs.OpenBlob(treeHash); !errors.Is(err, ErrNotBlob) {
+			t.Errorf("err = %v, want ErrNotBlob", err)
+		}
+	})
+}
+
+func TestOpenBlob_LegacyDeflate(t *testing.T) {
+	fs := newTestStore(t)
+
+	content := []byte("written by an older version\n")
+	serialized := objects.NewSerializedObject(objects.BlobType, content)
+	hash := objects.NewObjectHash(serialized)
+
+	var buf bytes.Buffer
+	w, _ := flate.NewWriter(&buf, flate.BestCompression)
+	w.Write(serialized)
+	w.Close()
+
+	path, _ := fs.resolveObjectPath(hash)
+	os.MkdirAll(path.ToAbsolutePath().Dir().String(), 0755)
+	if err := os.WriteFile(path.String(), buf.Bytes(), 0444); err != nil {
+		t.Fatal(err)
+	}
+
+	rc, _, err := fs.OpenBlob(hash)
+	if err != nil {
+		t.Fatalf("OpenBlob() failed: %v", err)
+	}
+	defer rc.Close()
+
+	got, err := io.ReadAll(rc)
+	if err != nil || !bytes.Equal(got, content) {
+		t.Errorf("OpenBlob() = %q, %v; want %q", got, err, content)
+	}
+}
+
+func TestOpenBlob_DetectsCorruption(t *testing.T) {
+	fs := newTestStore(t)
+
+	content := []byte("original content\n")
+	hash, _ := blob.NewBlob(content).Hash()
+
+	// Store different content of the same length under the original hash
+	forged, _ := objects.NewSerializedObject(objects.BlobType, []byte("tampered content\n")).Compress()
+	path, _ := fs.resolveObjectPath(hash)
+	os.MkdirAll(path.ToAbsolutePath().Dir().String(), 0755)
+	if err := os.WriteFile(path.String(), forged.Bytes(), 0444); err != nil {
+		t.Fatal(err)
+	}
+
+	rc, _, err := fs.OpenBlob(hash)
+	if err != nil {
+		t.Fatalf("OpenBlob() failed: %v", err)
+	}
+	defer rc.Close()
+
+	if _, err := io.ReadAll(rc); err == nil || !strings.Contains(err.Error(), "corrupt") {
+		t.Errorf("reading a corrupt blob: err = %v, want corruption error", err)
+	}
+}
+
+func TestOpenBlob_Packed(t *testing.T) {
+	fs := newTestStore(t)
+
+	content := []byte(strings.Repeat("packed blob content\n", 100))
+	hash, _ := fs.WriteObject(blob.NewBlob(content))
+
+	packDir := fs.GetObjectsPath().Join(scpath.PackDir).ToAbsolutePath()
+	packObjects := []*PackObject{{Hash: hash, Type: objects.BlobType, Data: content}}
+	if _, err := WritePack(packDir, packObjects, nil, DefaultPackWriterOptions()); err != nil {
+		t.Fatalf("WritePack() failed: %v", err)
+	}
+	if _, err := fs.PrunePacked(); err != nil {
+		t.Fatal(err)
+	}
+
+	rc, size, err := fs.OpenBlob(hash)
+	if err != nil {
+		t.Fatalf("OpenBlob() failed: %v", err)
+	}
+	defer rc.Close()
+
+	got, _ := io.ReadAll(rc)
+	if size != int64(len(content)) || !bytes.Equal(got, content) {
+		t.Errorf("packed OpenBlob() returned %d bytes (size %d), want %d", len(got), size, len(content))
+	}
+}
@@ -0,0 +1,235 @@
+package store
+
+import (
+	"container/list"
+	"io"
+	"log/slog"
+	"sync"
+
+	"github.com/utkarsh5026/SourceControl/pkg/common/logger"
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+)
+
+const (
+	// DefaultObjectCacheSize is the number of parsed objects kept by a
+	// CachedObjectStore when no capacity is given.
+	DefaultObjectCacheSize = 4096
+
+	// maxCachedBlobSize keeps large blobs out of the cache: the capacity is
+	// counted in objects, so a few big files could otherwise pin a lot of memory.
+	maxCachedBlobSize = 64 << 10
+)
+
+// CacheStats is a snapshot of a CachedObjectStore's counters.
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Entries   int
+	Capacity  int
+}
+
+// HitRate returns the fraction of reads served from the cache, or 0 if there
+// have been no reads.
+func (s CacheStats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// LogValue implements slog.LogValuer so the stats can be passed directly as
+// a log attribute.
+func (s CacheStats) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Uint64("hits", s.Hits),
+		slog.Uint64("misses", s.Misses),
+		slog.Uint64("evictions", s.Evictions),
+		slog.Int("entries", s.Entries),
+		slog.Int("capacity", s.Capacity),
+		slog.Float64("hit_rate", s.HitRate()),
+	)
+}
+
+// CachedObjectStore puts a bounded LRU cache of parsed objects in front of
+// another ObjectStore.
+//
+// Commands such as log, blame and diff read the same commits and trees over
+// and over; with the cache each is read from disk and inflated once. Writes
+// and blob streams go straight to the underlying store.
+//
+// Objects returned by ReadObject are shared between callers and must be
+// treated as read-only.
+//
+// Thread Safety:
+// All methods are safe for concurrent use, provided the base store is.
+type CachedObjectStore struct {
+	base     ObjectStore
+	capacity int
+	logger   *slog.Logger
+
+	mu        sync.Mutex
+	entries   map[objects.ObjectHash]*list.Element
+	lru       *list.List // front is most recently used
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+// cacheEntry is the value stored in the LRU list.
+type cacheEntry struct {
+	hash objects.ObjectHash
+	obj  objects.BaseObject
+}
+
+// NewCachedObjectStore wraps base with an LRU cache holding up to capacity
+// parsed objects. A capacity of zero or less selects DefaultObjectCacheSize.
+func NewCachedObjectStore(base ObjectStore, capacity int) *CachedObjectStore {
+	if capacity <= 0 {
+		capacity = DefaultObjectCacheSize
+	}
+
+	return &CachedObjectStore{
+		base:     base,
+		capacity: capacity,
+		logger:   logger.With("component", "object-cache"),
+		entries:  make(map[objects.ObjectHash]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+// Base returns the store the cache reads through to.
+func (c *CachedObjectStore) Base() ObjectStore {
+	return c.base
+}
+
+// Initialize initializes the underlying store.
+func (c *CachedObjectStore) Initialize(repoPath scpath.RepositoryPath) error {
+	return c.base.Initialize(repoPath)
+}
+
+// HashAlgorithm returns the underlying store's hash algorithm.
+func (c *CachedObjectStore) HashAlgorithm() objects.HashAlgorithm {
+	return c.base.HashAlgorithm()
+}
+
+// WriteObject writes through to the underlying store. Written objects are not
+// cached because the caller still holds, and may modify, the instance.
+func (c *CachedObjectStore) WriteObject(obj objects.BaseObject) (objects.ObjectHash, error) {
+	return c.base.WriteObject(obj)
+}
+
+// ReadObject returns the object from the cache, reading it from the
+// underlying store on a miss.
+func (c *CachedObjectStore) ReadObject(hash objects.ObjectHash) (objects.BaseObject, error) {
+	if obj, ok := c.lookup(hash); ok {
+		return obj, nil
+	}
+
+	obj, err := c.base.ReadObject(hash)
+	if err != nil || obj == nil {
+		return obj, err
+	}
+
+	if cacheable(obj) {
+		c.insert(hash, obj)
+	}
+	return obj, nil
+}
+
+// ReadSerialized reads the stored bytes from the underlying store. The cache
+// holds parsed objects only, so it is bypassed.
+func (c *CachedObjectStore) ReadSerialized(hash objects.ObjectHash) (objects.SerializedObject, error) {
+	return ReadSerialized(c.base, hash)
+}
+
+// HasObject answers from the cache when possible.
+func (c *CachedObjectStore) HasObject(hash objects.ObjectHash) (bool, error) {
+	c.mu.Lock()
+	_, ok := c.entries[hash]
+	c.mu.Unlock()
+
+	if ok {
+		return true, nil
+	}
+	return c.base.HasObject(hash)
+}
+
+// WriteBlobStream writes through to the underlying store.
+func (c *CachedObjectStore) WriteBlobStream(r io.Reader, size int64) (objects.ObjectHash, error) {
+	return c.base.WriteBlobStream(r, size)
+}
+
+// OpenBlob streams from the underlying store; streamed blobs are not cached.
+func (c *CachedObjectStore) OpenBlob(hash objects.ObjectHash) (io.ReadCloser, int64, error) {
+	return c.base.OpenBlob(hash)
+}
+
+// Stats returns a snapshot of the cache counters.
+func (c *CachedObjectStore) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return CacheStats{
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+		Entries:   c.lru.Len(),
+		Capacity:  c.capacity,
+	}
+}
+
+// LogStats logs the cache counters at debug level.
+func (c *CachedObjectStore) LogStats() {
+	c.logger.Debug("object cache stats", "stats", c.Stats())
+}
+
+// lookup returns a cached object and marks it as recently used.
+func (c *CachedObjectStore) lookup(hash objects.ObjectHash) (objects.BaseObject, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[hash]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+
+	c.hits++
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*cacheEntry).obj, true
+}
+
+// insert adds an object, evicting the least recently used one if the cache
+// is full. Two goroutines missing on the same hash both read it; the second
+// insert keeps the first instance.
+func (c *CachedObjectStore) insert(hash objects.ObjectHash, obj objects.BaseObject) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[hash]; ok {
+		c.lru.MoveToFront(elem)
+		return
+	}
+
+	c.entries[hash] = c.lru.PushFront(&cacheEntry{hash: hash, obj: obj})
+
+	for c.lru.Len() > c.capacity {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).hash)
+		c.evictions++
+	}
+}
+
+// cacheable reports whether an object is worth caching: everything except
+// blobs above maxCachedBlobSize.
+func cacheable(obj objects.BaseObject) bool {
+	if obj.Type() != objects.BlobType {
+		return true
+	}
+	size, err := obj.Size()
+	return err == nil && size.Int64() <= maxCachedBlobSize
+}
@@ -0,0 +1,179 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/blob"
+)
+
+var _ ObjectStore = (*CachedObjectStore)(nil)
+
+func writeBlobs(t *testing.T, s ObjectStore, n int) []objects.ObjectHash {
+	t.Helper()
+
+	hashes := make([]objects.ObjectHash, n)
+	for i := range hashes {
+		hash, err := s.WriteObject(blob.NewBlob([]byte(fmt.Sprintf("blob %d\n", i))))
+		if err != nil {
+			t.Fatal(err)
+		}
+		hashes[i] = hash
+	}
+	return hashes
+}
+
+func TestCachedObjectStore_HitsAndEviction(t *testing.T) {
+	base := NewMemoryObjectStore()
+	hashes := writeBlobs(t, base, 3)
+
+	cache := NewCachedObjectStore(base, 2)
+
+	first, _ := cache.ReadObject(hashes[0])
+	again, _ := cache.ReadObject(hashes[0])
+	if first == nil || first != again {
+		t.Error("second read should return the cached instance")
+	}
+
+	cache.ReadObject(hashes[1])
+	cache.ReadObject(hashes[0]) // hashes[1] is now least recently used
+	cache.ReadObject(hashes[2]) // evicts hashes[1]
+
+	stats := cache.Stats()
+	want := CacheStats{Hits: 2, Misses: 3, Evictions: 1, Entries: 2, Capacity: 2}
+	if stats != want {
+		t.Errorf("Stats() = %+v, want %+v", stats, want)
+	}
+
+	cache.ReadObject(hashes[0])
+	if cache.Stats().Hits != 3 {
+		t.Error("hashes[0] should have survived eviction")
+	}
+	cache.ReadObject(hashes[1])
+	if cache.Stats().Misses != 4 {
+		t.Error("hashes[1] should have been evicted")
+	}
+
+	if obj, err := cache.ReadObject(objects.ObjectHash(strings.Repeat("0", 40))); obj != nil || err != nil {
+		t.Errorf("ReadObject(missing) = %v, %v; want nil, nil", obj, err)
+	}
+}
+
+func TestCachedObjectStore_SkipsLargeBlobs(t *testing.T) {
+	base := NewMemoryObjectStore()
+	hash, _ := base.WriteObject(blob.NewBlob(bytes.Repeat([]byte("x"), maxCachedBlobSize+1)))
+
+	cache := NewCachedObjectStore(base, 0)
+	cache.ReadObject(hash)
+	cache.ReadObject(hash)
+
+	if stats := cache.Stats(); stats.Hits != 0 || stats.Entries != 0 {
+		t.Errorf("large blob was cached: %+v", stats)
+	}
+	if cache.Stats().Capacity != DefaultObjectCacheSize {
+		t.Errorf("Capacity = %d, want default %d", cache.Stats().Capacity, DefaultObjectCacheSize)
+	}
+}
+
+func TestCacheStats_LogValue(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(slog.NewTextHandler(&buf, nil))
+
+	stats := CacheStats{Hits: 3, Misses: 1, Capacity: 10, Entries: 1}
+	log.LogAttrs(context.Background(), slog.LevelInfo, "cache", slog.Any("stats", stats))
+
+	out := buf.String()
+	for _, want := range []string{"stats.hits=3", "stats.misses=1", "stats.hit_rate=0.75"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log output %q does not contain %q", out, want)
+		}
+	}
+}
+
+func TestCachedObjectStore_ConcurrentReads(t *testing.T) {
+	base := NewMemoryObjectStore()
+	hashes := writeBlobs(t, base, 64)
+	cache := NewCachedObjectStore(base, 16)
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				hash := hashes[(worker*7+i)%len(hashes)]
+				obj, err := cache.ReadObject(hash)
+				if err != nil || obj == nil {
+					t.Errorf("ReadObject(%s) = %v, %v", hash.Short(), obj, err)
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	stats := cache.Stats()
+	if stats.Hits+stats.Misses != 8*500 {
+		t.Errorf("recorded %d reads, want %d", stats.Hits+stats.Misses, 8*500)
+	}
+	if stats.Entries > 16 {
+		t.Errorf("cache holds %d entries, capacity is 16", stats.Entries)
+	}
+}
+
+func TestFileObjectStore_ConcurrentWrites(t *testing.T) {
+	fs := newTestStore(t)
+
+	const workers = 8
+	const objectsPerWorker = 40
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < objectsPerWorker; i++ {
+				// Half the content is shared so writers race on the same object
+				content := fmt.Sprintf("worker %d object %d\n", worker, i)
+				if i%2 == 0 {
+					content = fmt.Sprintf("shared object %d\n", i)
+				}
+
+				hash, err := fs.WriteObject(blob.NewBlob([]byte(content)))
+				if err != nil {
+					t.Errorf("WriteObject() failed: %v", err)
+					return
+				}
+				obj, err := fs.ReadObject(hash)
+				if err != nil || obj == nil {
+					t.Errorf("ReadObject(%s) right after writing = %v, %v", hash.Short(), obj, err)
+					return
+				}
+				if _, err := fs.Packs(); err != nil {
+					t.Errorf("Packs() failed: %v", err)
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	want := objectsPerWorker/2 + workers*objectsPerWorker/2
+	if n, _ := fs.LooseObjectCount(); n != want {
+		t.Errorf("LooseObjectCount() = %d, want %d", n, want)
+	}
+
+	entries, _ := os.ReadDir(fs.GetObjectsPath().String())
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), "tmp_obj_") {
+			t.Errorf("temporary file %s left behind", e.Name())
+		}
+	}
+}
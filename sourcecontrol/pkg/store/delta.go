@@ -0,0 +1,247 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidDelta is returned when a delta instruction stream is malformed
+// or does not fit the base object it is applied to.
+var ErrInvalidDelta = errors.New("invalid delta")
+
+// applyDelta reconstructs an object from a base and a git delta.
+//
+// A delta starts with the expected base size and the resulting target size,
+// both encoded as little-endian base-128 varints, followed by a stream of
+// instructions:
+//
+//	1xxxxxxx  copy:   the low 4 bits select which offset bytes follow and
+//	                  bits 4-6 which size bytes follow; copies a range of
+//	                  the base (a size of 0 means 0x10000)
+//	0nnnnnnn  insert: the next n bytes (1-127) are literal target data
+//	00000000  reserved, rejected
+func applyDelta(base, delta []byte) ([]byte, error) {
+	pos := 0
+
+	srcSize, n := readDeltaSize(delta[pos:])
+	if n == 0 {
+		return nil, fmt.Errorf("%w: truncated source size", ErrInvalidDelta)
+	}
+	pos += n
+	if srcSize != uint64(len(base)) {
+		return nil, fmt.Errorf("%w: base size %d, delta expects %d", ErrInvalidDelta, len(base), srcSize)
+	}
+
+	dstSize, n := readDeltaSize(delta[pos:])
+	if n == 0 {
+		return nil, fmt.Errorf("%w: truncated target size", ErrInvalidDelta)
+	}
+	pos += n
+
+	out := make([]byte, 0, dstSize)
+	for pos < len(delta) {
+		op := delta[pos]
+		pos++
+
+		switch {
+		case op&0x80 != 0:
+			var offset, size uint64
+			for i := 0; i < 4; i++ {
+				if op&(1<<i) != 0 {
+					if pos >= len(delta) {
+						return nil, fmt.Errorf("%w: truncated copy offset", ErrInvalidDelta)
+					}
+					offset |= uint64(delta[pos]) << (8 * i)
+					pos++
+				}
+			}
+			for i := 0; i < 3; i++ {
+				if op&(0x10<<i) != 0 {
+					if pos >= len(delta) {
+						return nil, fmt.Errorf("%w: truncated copy size", ErrInvalidDelta)
+					}
+					size |= uint64(delta[pos]) << (8 * i)
+					pos++
+				}
+			}
+			if size == 0 {
+				size = 0x10000
+			}
+			if offset+size > uint64(len(base)) {
+				return nil, fmt.Errorf("%w: copy [%d,%d) outside base of %d bytes", ErrInvalidDelta, offset, offset+size, len(base))
+			}
+			out = append(out, base[offset:offset+size]...)
+
+		case op != 0:
+			end := pos + int(op)
+			if end > len(delta) {
+				return nil, fmt.Errorf("%w: truncated insert", ErrInvalidDelta)
+			}
+			out = append(out, delta[pos:end]...)
+			pos = end
+
+		default:
+			return nil, fmt.Errorf("%w: reserved opcode 0", ErrInvalidDelta)
+		}
+	}
+
+	if uint64(len(out)) != dstSize {
+		return nil, fmt.Errorf("%w: produced %d bytes, expected %d", ErrInvalidDelta, len(out), dstSize)
+	}
+
+	return out, nil
+}
+
+// readDeltaSize decodes a little-endian base-128 varint from the delta header.
+// It returns the value and the number of bytes consumed (0 if truncated).
+func readDeltaSize(data []byte) (uint64, int) {
+	var size uint64
+	var shift uint
+	for i, b := range data {
+		size |= uint64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return size, i + 1
+		}
+		shift += 7
+		if shift > 63 {
+			return 0, 0
+		}
+	}
+	return 0, 0
+}
+
+const (
+	// deltaBlockSize is the granularity at which the base is indexed when
+	// searching for copyable regions
+	deltaBlockSize = 16
+
+	// maxDeltaCopySize is the largest range a single copy instruction
+	// encodes; larger matches are split into several copies
+	maxDeltaCopySize = 0x10000
+
+	// maxDeltaInsertSize is the largest literal run an insert instruction holds
+	maxDeltaInsertSize = 0x7f
+)
+
+// deltaIndex maps every deltaBlockSize-aligned block of a base object to its
+// offset, so that matching regions in a target can be found quickly. It is
+// built once per base and reused for every target tried against it.
+type deltaIndex struct {
+	base   []byte
+	blocks map[string]int
+}
+
+// newDeltaIndex indexes base for use with createDelta.
+func newDeltaIndex(base []byte) *deltaIndex {
+	blocks := make(map[string]int, len(base)/deltaBlockSize)
+	for off := 0; off+deltaBlockSize <= len(base); off += deltaBlockSize {
+		key := string(base[off : off+deltaBlockSize])
+		if _, seen := blocks[key]; !seen {
+			blocks[key] = off
+		}
+	}
+	return &deltaIndex{base: base, blocks: blocks}
+}
+
+// createDelta encodes target as a delta against the indexed base.
+//
+// The target is scanned left to right; wherever a block from the base
+// appears, the match is grown in both directions and emitted as a copy,
+// everything else is emitted as literal inserts. The result is abandoned
+// (nil, false) as soon as it grows beyond maxSize, which lets callers cheaply
+// reject poor bases.
+func (di *deltaIndex) createDelta(target []byte, maxSize int) ([]byte, bool) {
+	base := di.base
+
+	out := make([]byte, 0, 64)
+	out = appendDeltaSize(out, uint64(len(base)))
+	out = appendDeltaSize(out, uint64(len(target)))
+
+	insertStart := 0
+	i := 0
+	for i+deltaBlockSize <= len(target) {
+		off, ok := di.blocks[string(target[i:i+deltaBlockSize])]
+		if !ok {
+			i++
+			continue
+		}
+
+		// Grow the match backwards into the pending literal run...
+		for off > 0 && i > insertStart && base[off-1] == target[i-1] {
+			off--
+			i--
+		}
+		// ...and forwards as far as both buffers agree.
+		n := 0
+		for off+n < len(base) && i+n < len(target) && base[off+n] == target[i+n] {
+			n++
+		}
+
+		out = appendDeltaInsert(out, target[insertStart:i])
+		out = appendDeltaCopy(out, off, n)
+		i += n
+		insertStart = i
+
+		if len(out) > maxSize {
+			return nil, false
+		}
+	}
+
+	out = appendDeltaInsert(out, target[insertStart:])
+	if len(out) > maxSize {
+		return nil, false
+	}
+	return out, true
+}
+
+// appendDeltaSize appends a little-endian base-128 varint.
+func appendDeltaSize(out []byte, size uint64) []byte {
+	for size >= 0x80 {
+		out = append(out, byte(size)|0x80)
+		size >>= 7
+	}
+	return append(out, byte(size))
+}
+
+// appendDeltaInsert appends literal data as one or more insert instructions.
+func appendDeltaInsert(out []byte, data []byte) []byte {
+	for len(data) > 0 {
+		n := min(len(data), maxDeltaInsertSize)
+		out = append(out, byte(n))
+		out = append(out, data[:n]...)
+		data = data[n:]
+	}
+	return out
+}
+
+// appendDeltaCopy appends copy instructions for base[off:off+size], omitting
+// zero bytes of the offset and size as the format allows.
+func appendDeltaCopy(out []byte, off, size int) []byte {
+	for size > 0 {
+		n := min(size, maxDeltaCopySize)
+
+		op := byte(0x80)
+		args := make([]byte, 0, 7)
+		for i := 0; i < 4; i++ {
+			if b := byte(off >> (8 * i)); b != 0 {
+				op |= 1 << i
+				args = append(args, b)
+			}
+		}
+		// A size of 0x10000 is encoded as no size bytes at all.
+		if n != maxDeltaCopySize {
+			for i := 0; i < 3; i++ {
+				if b := byte(n >> (8 * i)); b != 0 {
+					op |= 0x10 << i
+					args = append(args, b)
+				}
+			}
+		}
+
+		out = append(out, op)
+		out = append(out, args...)
+		off += n
+		size -= n
+	}
+	return out
+}
@@ -2,9 +2,11 @@ package store
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/utkarsh5026/SourceControl/pkg/common/fileops"
 	"github.com/utkarsh5026/SourceControl/pkg/objects"
@@ -12,16 +14,21 @@ import (
 	"github.com/utkarsh5026/SourceControl/pkg/objects/commit"
 	tagobj "github.com/utkarsh5026/SourceControl/pkg/objects/tag"
 	"github.com/utkarsh5026/SourceControl/pkg/objects/tree"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/repoformat"
 	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
 )
 
+// tempObjectPattern names temporary files for objects being written. They
+// live directly in objects/, where they cannot be mistaken for loose objects.
+const tempObjectPattern = "tmp_obj_*"
+
 // FileObjectStore is a file-based implementation of Git object storage that mimics Git's internal
 // object database.
 //
 // This implementation stores Git objects in a directory structure where each object is:
 // 1. Serialized to Git's standard format (header + content)
-// 2. Compressed using DEFLATE algorithm
-// 3. Stored in a file named by its SHA-1 hash
+// 2. Compressed as a zlib stream (readable by git itself)
+// 3. Stored in a file named by its hash (SHA-1, or SHA-256 if the repository uses it)
 //
 // Directory Structure:
 //
@@ -35,11 +42,30 @@ import (
 // Example for SHA "abcdef1234567890abcdef1234567890abcdef12":
 // File path: .source/objects/ab/cdef1234567890abcdef1234567890abcdef12
 //
+// Objects may also live in packfiles under objects/pack (written by git gc,
+// git clone or a repack). Reads look at loose objects first and then search
+// every pack; writes always create loose objects. Finally, objects may be
+// borrowed from other repositories through alternates (see alternates.go).
+//
 // Thread Safety:
-// This implementation is NOT thread-safe. External synchronization is required
-// for concurrent access.
+// Once initialized, a FileObjectStore is safe for concurrent use, including by
+// several processes sharing one repository. Loose objects are written to a
+// temporary file and renamed into place, so readers never observe a partial
+// object, and two writers racing on the same object both succeed since they
+// produce identical content. The list of open packs is guarded by a mutex.
+// Initialize must not be called concurrently with other methods.
 type FileObjectStore struct {
 	objectsPath scpath.SourcePath
+	algorithm   objects.HashAlgorithm
+
+	packMu      sync.RWMutex
+	packs       []*Packfile
+	packsLoaded bool
+	packDirMod  time.Time // modification time of objects/pack at the last scan
+
+	// alternates are the object directories listed in objects/info/alternates
+	// (and their own alternates), searched after the local objects
+	alternates []*FileObjectStore
 }
 
 // NewFileObjectStore creates a new FileObjectStore instance.
@@ -51,7 +77,8 @@ func NewFileObjectStore() *FileObjectStore {
 }
 
 // Initialize sets up the object store by creating the objects directory structure.
-// It creates the .source/objects directory if it doesn't exist.
+// It creates the .source/objects directory if it doesn't exist, and reads the
+// repository's object format so objects are named with the right hash.
 //
 // This method must be called before any other operations on the store.
 // Calling Initialize multiple times is safe and will update the objectsPath.
@@ -62,20 +89,39 @@ func NewFileObjectStore() *FileObjectStore {
 // Returns:
 //   - error: Returns an error if directory creation fails
 func (f *FileObjectStore) Initialize(repoPath scpath.RepositoryPath) error {
+	algorithm, err := repoformat.ReadObjectFormat(repoPath.SourcePath())
+	if err != nil {
+		return fmt.Errorf("failed to initialize object store: %w", err)
+	}
+
 	f.objectsPath = repoPath.SourcePath().ObjectsPath()
+	f.algorithm = algorithm
 
 	if err := fileops.EnsureDir(f.objectsPath.ToAbsolutePath()); err != nil {
 		return fmt.Errorf("failed to initialize object store: %w", err)
 	}
 
+	if err := f.loadAlternates(); err != nil {
+		return fmt.Errorf("failed to initialize object store: %w", err)
+	}
+
 	return nil
 }
 
+// HashAlgorithm returns the hash algorithm naming this store's objects.
+func (f *FileObjectStore) HashAlgorithm() objects.HashAlgorithm {
+	if f.algorithm == "" {
+		return objects.DefaultHashAlgorithm
+	}
+	return f.algorithm
+}
+
 // WriteObject stores a Git object in the object store.
 //
-// If the object already exists (based on content hash), it returns the SHA-1 hash
-// without rewriting the file. This implements Git's content-addressable storage,
-// where identical content always produces the same hash.
+// If the object already exists (based on content hash), loose, packed or in
+// an alternate, it returns the hash without rewriting it. This implements
+// Git's content-addressable storage, where identical content always produces
+// the same hash.
 func (f *FileObjectStore) WriteObject(obj objects.BaseObject) (objects.ObjectHash, error) {
 	if f.objectsPath == "" {
 		return "", fmt.Errorf("object store not initialized")
@@ -86,12 +132,31 @@ func (f *FileObjectStore) WriteObject(obj objects.BaseObject) (objects.ObjectHas
 		return "", err
 	}
 
-	hash := objects.NewObjectHash(serialized)
+	hash := f.HashAlgorithm().Sum(serialized)
 	filePath, err := f.resolveObjectPath(hash)
 	if err != nil {
 		return "", fmt.Errorf("failed to resolve object path: %w", err)
 	}
 
+	// A packed or borrowed object is not written again as a loose copy. The
+	// loose file is looked for first, and only packs already known are
+	// searched: missing a pack another process just wrote costs no more
+	// than a redundant loose copy.
+	if _, err := os.Stat(filePath.String()); err == nil {
+		freshenLooseObject(filePath.ToAbsolutePath())
+		return hash, nil
+	}
+	if packed, err := f.knownPack(hash); err != nil {
+		return "", fmt.Errorf("failed to search packs: %w", err)
+	} else if packed != nil {
+		return hash, nil
+	}
+	if borrowed, err := f.hasAlternateObject(hash); err != nil {
+		return "", fmt.Errorf("failed to search alternates: %w", err)
+	} else if borrowed {
+		return hash, nil
+	}
+
 	if err := f.writeObjectToDisk(serialized, filePath); err != nil {
 		return "", fmt.Errorf("failed to write object to disk: %w", err)
 	}
@@ -112,10 +177,10 @@ func (f *FileObjectStore) serializeObject(obj objects.BaseObject) (objects.Seria
 
 // writeObjectToDisk writes the serialized and compressed Git object to disk at the specified file path.
 //
-// This function compresses the provided object data using DEFLATE,
-// ensures the parent directory exists, and writes the compressed data to a file
-// with read-only permissions. If the file already exists (object is already stored),
-// the function returns early without error to avoid redundant writes.
+// This function compresses the provided object data into a zlib stream and
+// writes it to a temporary file that is then renamed to filePath, so the
+// object appears atomically. If the file already exists (object is already
+// stored), it is only freshened instead of being written again.
 func (f *FileObjectStore) writeObjectToDisk(obj objects.SerializedObject, filePath scpath.SourcePath) error {
 	absPath := filePath.ToAbsolutePath()
 
@@ -124,6 +189,7 @@ func (f *FileObjectStore) writeObjectToDisk(obj objects.SerializedObject, filePa
 		return fmt.Errorf("failed to check object existence: %w", err)
 	}
 	if exists {
+		freshenLooseObject(absPath)
 		return nil
 	}
 
@@ -132,19 +198,63 @@ func (f *FileObjectStore) writeObjectToDisk(obj objects.SerializedObject, filePa
 		return fmt.Errorf("failed to compress object: %w", err)
 	}
 
-	if err := fileops.WriteReadOnly(absPath, compressed.Bytes()); err != nil {
-		return err
+	tmp, err := os.CreateTemp(f.objectsPath.String(), tempObjectPattern)
+	if err != nil {
+		return fmt.Errorf("failed to create temporary object: %w", err)
+	}
+	defer func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}()
+
+	if _, err := tmp.Write(compressed.Bytes()); err != nil {
+		return fmt.Errorf("failed to write temporary object: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write temporary object: %w", err)
 	}
 
+	return installLooseObject(tmp.Name(), absPath)
+}
+
+// freshenLooseObject sets an existing object's modification time to now.
+// Writing an object that is already stored counts as writing it again, so
+// that prune's grace period starts over for objects that are about to be
+// referenced. Failing to update the time is not an error.
+func freshenLooseObject(path scpath.AbsolutePath) {
+	now := time.Now()
+	os.Chtimes(path.String(), now, now)
+}
+
+// installLooseObject moves a fully written temporary object file to its final
+// location with read-only permissions.
+//
+// Another writer may install the same object concurrently. On POSIX systems
+// the rename simply replaces it with identical content; where rename refuses
+// to overwrite (Windows), an existing target is treated as success.
+func installLooseObject(tmpPath string, target scpath.AbsolutePath) error {
+	if err := os.MkdirAll(target.Dir().String(), 0755); err != nil {
+		return fmt.Errorf("failed to create object directory: %w", err)
+	}
+	if err := os.Chmod(tmpPath, 0444); err != nil {
+		return fmt.Errorf("failed to set object permissions: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, target.String()); err != nil {
+		if _, statErr := os.Stat(target.String()); statErr == nil {
+			return nil
+		}
+		return fmt.Errorf("failed to move object into place: %w", err)
+	}
 	return nil
 }
 
 // ReadObject retrieves and reconstructs a Git object from storage using its SHA-1 hash.
 //
 // The method performs the reverse of WriteObject:
-// 1. Validates the hash format (40 hex characters)
+// 1. Validates the hash format (40 or 64 hex characters)
 // 2. Reads the compressed data from disk
-// 3. Decompresses it using DEFLATE
+// 3. Decompresses it (zlib, or raw DEFLATE for legacy objects)
 // 4. Parses the header to determine object type
 // 5. Creates the appropriate object instance (Blob, Tree, or Commit)
 // 6. Deserializes the data into that object
@@ -152,13 +262,100 @@ func (f *FileObjectStore) writeObjectToDisk(obj objects.SerializedObject, filePa
 // Parameters:
 //   - hash: The SHA-1 hash of the object to retrieve
 func (f *FileObjectStore) ReadObject(hash objects.ObjectHash) (objects.BaseObject, error) {
+	serialized, err := f.readSerialized(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if serialized == nil {
+		return nil, nil
+	}
+
+	obj, err := createObjectFromHeader(objects.ObjectContent(serialized), f.HashAlgorithm())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create object from header: %w", err)
+	}
+
+	return obj, nil
+}
+
+// ReadSerialized returns the object exactly as stored, from a loose file, a
+// pack or an alternate, or nil if it does not exist.
+func (f *FileObjectStore) ReadSerialized(hash objects.ObjectHash) (objects.SerializedObject, error) {
+	if err := hash.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid hash: %w", err)
+	}
+	return f.readSerialized(hash)
+}
+
+// ReadObjectInfo returns the type and content size of an object, from a
+// loose file, a pack or an alternate, reading no more of it than its header.
+//
+// Returns:
+//   - objects.ObjectType: The object's type
+//   - int64: The content size in bytes
+//   - error: ErrObjectNotFound if no source has the object, or an I/O error
+func (f *FileObjectStore) ReadObjectInfo(hash objects.ObjectHash) (objects.ObjectType, int64, error) {
+	filePath, err := f.validateAndResolvePath(hash)
+	if err != nil {
+		return "", 0, err
+	}
+
+	s, objType, size, err := openLooseStream(filePath.String(), hash)
+	if err == nil {
+		s.Close()
+		return objType, size, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return "", 0, err
+	}
+
+	if p, err := f.findPack(hash); err != nil {
+		return "", 0, err
+	} else if p != nil {
+		objType, size, found, err := p.ObjectInfo(hash, f.ReadObjectInfo)
+		if errors.Is(err, os.ErrNotExist) {
+			// Removed by a repack since it was loaded, as in readPacked
+			if p, err = f.refreshPacks(hash, true); err != nil {
+				return "", 0, err
+			}
+			if p != nil {
+				objType, size, found, err = p.ObjectInfo(hash, f.ReadObjectInfo)
+			}
+		}
+		if err != nil || found {
+			return objType, size, err
+		}
+	}
+
+	for _, alt := range f.alternates {
+		if ok, err := alt.HasLocalObject(hash); err != nil {
+			return "", 0, err
+		} else if ok {
+			return alt.ReadObjectInfo(hash)
+		}
+	}
+	return "", 0, fmt.Errorf("%w: %s", ErrObjectNotFound, hash)
+}
+
+// readSerialized returns the object in its serialized form ("<type> <size>\0<content>"),
+// looking at the loose object first, then at the packs and finally at the
+// alternates. It returns (nil, nil) if the object does not exist anywhere.
+func (f *FileObjectStore) readSerialized(hash objects.ObjectHash) (objects.SerializedObject, error) {
 	compressed, err := f.readFromDisk(hash)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read object file: %w", err)
 	}
 
 	if compressed == nil {
-		return nil, nil
+		serialized, err := f.readPacked(hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read packed object: %w", err)
+		}
+		if serialized == nil {
+			return f.readAlternate(hash)
+		}
+		return serialized, nil
 	}
 
 	decompressed, err := compressed.Decompress()
@@ -166,12 +363,7 @@ func (f *FileObjectStore) ReadObject(hash objects.ObjectHash) (objects.BaseObjec
 		return nil, fmt.Errorf("failed to decompress object: %w", err)
 	}
 
-	obj, err := f.createObjectFromHeader(decompressed)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create object from header: %w", err)
-	}
-
-	return obj, nil
+	return objects.SerializedObject(decompressed), nil
 }
 
 // readFromDisk retrieves the raw compressed data for a Git object from disk.
@@ -192,20 +384,36 @@ func (f *FileObjectStore) readFromDisk(hash objects.ObjectHash) (objects.Compres
 	return objects.CompressedData(compressed), nil
 }
 
+// ReadLooseObject returns the loose copy of an object in serialized form,
+// ignoring packs and alternates. It returns nil if there is no loose file
+// for the hash. The content is not checked against the hash.
+func (f *FileObjectStore) ReadLooseObject(hash objects.ObjectHash) (objects.SerializedObject, error) {
+	compressed, err := f.readFromDisk(hash)
+	if err != nil || compressed == nil {
+		return nil, err
+	}
+
+	decompressed, err := compressed.Decompress()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress object: %w", err)
+	}
+	return objects.SerializedObject(decompressed), nil
+}
+
 // HasObject checks if a Git object exists in the object store.
 //
 // This is more efficient than ReadObject when you only need to verify existence,
-// as it doesn't read or decompress the file contents.
+// as it doesn't read or decompress the file contents. Packed objects are found
+// through the pack indexes, and objects borrowed from alternates count too.
 //
 // Parameters:
-//   - hash: The SHA-1 hash of the object to check
+//   - hash: The hash of the object to check
 func (f *FileObjectStore) HasObject(hash objects.ObjectHash) (bool, error) {
-	filePath, err := f.validateAndResolvePath(hash)
-	if err != nil {
-		return false, err
+	exists, err := f.HasLocalObject(hash)
+	if err != nil || exists {
+		return exists, err
 	}
-
-	return fileops.Exists(filePath.ToAbsolutePath())
+	return f.hasAlternateObject(hash)
 }
 
 // resolveObjectPath converts a SHA-1 hash to the corresponding file path in Git's object storage
@@ -213,7 +421,8 @@ func (f *FileObjectStore) HasObject(hash objects.ObjectHash) (bool, error) {
 //
 // Git uses a two-level directory structure to avoid having too many files in a single
 // directory, which can cause filesystem performance issues. The first two characters of the
-// hash become a directory name, and the remaining 38 characters become the filename.
+// hash become a directory name, and the remaining characters (38 for SHA-1, 62 for
+// SHA-256) become the filename.
 //
 // Example:
 //
@@ -221,8 +430,8 @@ func (f *FileObjectStore) HasObject(hash objects.ObjectHash) (bool, error) {
 //	returns: ".source/objects/ab/cdef1234567890abcdef1234567890abcdef12"
 func (f *FileObjectStore) resolveObjectPath(hash objects.ObjectHash) (scpath.SourcePath, error) {
 	hashStr := hash.String()
-	if len(hashStr) != 40 {
-		return "", fmt.Errorf("invalid hash length: %d", len(hashStr))
+	if len(hashStr) != f.HashAlgorithm().HexSize() {
+		return "", fmt.Errorf("invalid hash length: %d (repository uses %s)", len(hashStr), f.HashAlgorithm())
 	}
 
 	objPath := f.objectsPath.ObjectFilePath(hashStr)
@@ -242,7 +451,9 @@ func (f *FileObjectStore) resolveObjectPath(hash objects.ObjectHash) (scpath.Sou
 //
 // Parameters:
 //   - data: The decompressed object data including header and content
-func (f *FileObjectStore) createObjectFromHeader(data objects.ObjectContent) (objects.BaseObject, error) {
+//   - algorithm: The repository's hash algorithm, which blobs and trees cannot
+//     infer from their content
+func createObjectFromHeader(data objects.ObjectContent, algorithm objects.HashAlgorithm) (objects.BaseObject, error) {
 	serialized := objects.SerializedObject(data)
 	objType, _, _, err := serialized.ParseHeader()
 	if err != nil {
@@ -253,9 +464,9 @@ func (f *FileObjectStore) createObjectFromHeader(data objects.ObjectContent) (ob
 
 	switch objType {
 	case objects.BlobType:
-		return blob.ParseBlob(fullData)
+		return blob.ParseBlobWithAlgorithm(fullData, algorithm)
 	case objects.TreeType:
-		return tree.ParseTree(fullData)
+		return tree.ParseTreeWithAlgorithm(fullData, algorithm)
 	case objects.CommitType:
 		return commit.ParseCommit(fullData)
 	case objects.TagType:
@@ -278,30 +489,28 @@ func (f *FileObjectStore) GetObjectsPath() scpath.SourcePath {
 	return f.objectsPath
 }
 
-// ObjectCount returns the total number of objects in the store.
+// ObjectCount returns the total number of objects in the store, counting
+// both loose objects and objects stored in packs.
 //
-// This method walks the entire objects directory tree and counts all files.
-// This can be slow for large repositories with many objects.
+// An object that is both loose and packed is counted twice; run PrunePacked
+// first for an exact figure.
 func (f *FileObjectStore) ObjectCount() (int, error) {
 	if !f.IsInitialized() {
 		return 0, fmt.Errorf("object store not initialized")
 	}
 
-	count := 0
-	err := filepath.Walk(f.objectsPath.String(), func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if !info.IsDir() {
-			count++
-		}
-		return nil
-	})
+	count, err := f.LooseObjectCount()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count objects: %w", err)
+	}
 
+	packs, err := f.Packs()
 	if err != nil {
 		return 0, fmt.Errorf("failed to count objects: %w", err)
 	}
+	for _, p := range packs {
+		count += p.Index().Count()
+	}
 
 	return count, nil
 }
@@ -1,8 +1,10 @@
 package store
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -248,6 +250,79 @@ func TestFileObjectStore_WriteAndReadCommit(t *testing.T) {
 	}
 }
 
+func TestFileObjectStore_SHA256(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	sourceDir := repoPath.SourcePath()
+	if err := os.MkdirAll(sourceDir.String(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	config := "[core]\n\trepositoryformatversion = 1\n[extensions]\n\tobjectformat = sha256\n"
+	if err := os.WriteFile(sourceDir.ConfigPath().String(), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	store := NewFileObjectStore()
+	if err := store.Initialize(repoPath); err != nil {
+		t.Fatalf("Initialize() failed: %v", err)
+	}
+	if store.HashAlgorithm() != objects.SHA256 {
+		t.Fatalf("HashAlgorithm() = %s, want sha256", store.HashAlgorithm())
+	}
+
+	blobHash, err := store.WriteObject(blob.NewBlob([]byte("sha256 content")))
+	if err != nil {
+		t.Fatalf("WriteObject() failed for blob: %v", err)
+	}
+	if len(blobHash) != 64 {
+		t.Fatalf("blob hash %s is not a SHA-256 hash", blobHash)
+	}
+
+	entry, err := tree.NewTreeEntryFromStrings(objects.FileModeRegular.ToOctalString(), "file.txt", blobHash.String())
+	if err != nil {
+		t.Fatalf("failed to create tree entry: %v", err)
+	}
+	treeHash, err := store.WriteObject(tree.NewTree([]*tree.TreeEntry{entry}))
+	if err != nil {
+		t.Fatalf("WriteObject() failed for tree: %v", err)
+	}
+
+	readObj, err := store.ReadObject(treeHash)
+	if err != nil {
+		t.Fatalf("ReadObject() failed for tree: %v", err)
+	}
+	readTree := readObj.(*tree.Tree)
+	if entries := readTree.Entries(); len(entries) != 1 || entries[0].SHA() != blobHash {
+		t.Errorf("tree entries = %v, want one entry pointing at %s", entries, blobHash)
+	}
+	if h, _ := readTree.Hash(); h != treeHash {
+		t.Errorf("re-hashing the tree gave %s, want %s", h, treeHash)
+	}
+
+	person, _ := commit.NewCommitPerson("John Doe", "john@example.com", time.Unix(1700000000, 0))
+	c, err := commit.NewCommitBuilder().Tree(treeHash.String()).Author(person).Committer(person).Message("sha256").Build()
+	if err != nil {
+		t.Fatalf("failed to build commit: %v", err)
+	}
+	commitHash, err := store.WriteObject(c)
+	if err != nil {
+		t.Fatalf("WriteObject() failed for commit: %v", err)
+	}
+	readObj, err = store.ReadObject(commitHash)
+	if err != nil {
+		t.Fatalf("ReadObject() failed for commit: %v", err)
+	}
+	if h, _ := readObj.Hash(); h != commitHash {
+		t.Errorf("re-hashing the commit gave %s, want %s", h, commitHash)
+	}
+
+	// SHA-1 names cannot refer to objects in a SHA-256 store
+	if _, err := store.HasObject(objects.NewObjectHash([]byte("x"))); err == nil {
+		t.Error("HasObject() should reject a SHA-1 hash")
+	}
+}
+
 func TestFileObjectStore_HasObject(t *testing.T) {
 	repoPath, cleanup := setupTestRepo(t)
 	defer cleanup()
@@ -519,3 +594,102 @@ func TestFileObjectStore_CompressedStorage(t *testing.T) {
 		t.Logf("Warning: compression may not be working effectively. File size: %d bytes", info.Size())
 	}
 }
+
+func TestFileObjectStore_WriteFreshensExisting(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	store := NewFileObjectStore()
+	if err := store.Initialize(repoPath); err != nil {
+		t.Fatalf("Initialize() failed: %v", err)
+	}
+
+	hash, err := store.WriteObject(blob.NewBlob([]byte("rewritten")))
+	if err != nil {
+		t.Fatalf("WriteObject() failed: %v", err)
+	}
+	path, err := store.resolveObjectPath(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-30 * 24 * time.Hour)
+	if err := os.Chtimes(path.String(), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	// Writing the same content again must restart prune's grace period
+	if _, err := store.WriteObject(blob.NewBlob([]byte("rewritten"))); err != nil {
+		t.Fatalf("second WriteObject() failed: %v", err)
+	}
+
+	modTime, err := store.LooseObjectModTime(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Since(modTime) > time.Hour {
+		t.Errorf("rewriting an object left its mtime at %v", modTime)
+	}
+}
+
+func TestFileObjectStore_FindObjectsByPrefix(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	store := NewFileObjectStore()
+	if err := store.Initialize(repoPath); err != nil {
+		t.Fatalf("Initialize() failed: %v", err)
+	}
+
+	hash, err := store.WriteObject(blob.NewBlob([]byte("hello\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, prefix := range []string{hash.String()[:1], hash.String()[:4], strings.ToUpper(hash.String()[:10]), hash.String()} {
+		matches, err := store.FindObjectsByPrefix(prefix)
+		if err != nil {
+			t.Fatalf("FindObjectsByPrefix(%q) failed: %v", prefix, err)
+		}
+		if len(matches) != 1 || matches[0] != hash {
+			t.Errorf("FindObjectsByPrefix(%q) = %v, want [%s]", prefix, matches, hash)
+		}
+	}
+
+	if matches, err := store.FindObjectsByPrefix("0000"); err != nil || len(matches) != 0 {
+		t.Errorf("FindObjectsByPrefix(0000) = %v, %v; want no matches", matches, err)
+	}
+}
+
+func TestFileObjectStore_ReadSerialized(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	store := NewFileObjectStore()
+	if err := store.Initialize(repoPath); err != nil {
+		t.Fatalf("Initialize() failed: %v", err)
+	}
+
+	hash, err := store.WriteObject(blob.NewBlob([]byte("hello\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serialized, err := ReadSerialized(store, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(serialized) != "blob 6\x00hello\n" {
+		t.Errorf("ReadSerialized() = %q", serialized)
+	}
+
+	// Wrapped stores read through to the file store
+	cached, err := ReadSerialized(NewCachedObjectStore(store, 0), hash)
+	if err != nil || !bytes.Equal(cached, serialized) {
+		t.Errorf("ReadSerialized(cached) = %q, %v", cached, err)
+	}
+
+	missing, err := ReadSerialized(store, objects.ObjectHash(strings.Repeat("1", 40)))
+	if err != nil || missing != nil {
+		t.Errorf("ReadSerialized(missing) = %q, %v; want nil, nil", missing, err)
+	}
+}
@@ -0,0 +1,206 @@
+package store
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+)
+
+// MemoryObjectStore is an ObjectStore that keeps objects in memory.
+//
+// Objects are held in their serialized form ("<type> <size>\0<content>"), so
+// reads return fresh instances exactly as FileObjectStore does and callers
+// cannot modify stored objects by mutating what they read.
+//
+// It is intended for unit tests that do not need a repository on disk and as
+// the write buffer of an OverlayObjectStore.
+//
+// Thread Safety:
+// All methods are safe for concurrent use.
+type MemoryObjectStore struct {
+	mu        sync.RWMutex
+	objects   map[objects.ObjectHash]objects.SerializedObject
+	order     []objects.ObjectHash
+	algorithm objects.HashAlgorithm
+}
+
+// NewMemoryObjectStore creates an empty in-memory store naming objects with
+// SHA-1. It is ready to use without calling Initialize.
+func NewMemoryObjectStore() *MemoryObjectStore {
+	return NewMemoryObjectStoreWithAlgorithm(objects.DefaultHashAlgorithm)
+}
+
+// NewMemoryObjectStoreWithAlgorithm creates an empty in-memory store naming
+// objects with the given hash algorithm.
+func NewMemoryObjectStoreWithAlgorithm(algorithm objects.HashAlgorithm) *MemoryObjectStore {
+	return &MemoryObjectStore{
+		objects:   make(map[objects.ObjectHash]objects.SerializedObject),
+		algorithm: algorithm,
+	}
+}
+
+// Initialize exists to satisfy ObjectStore; an in-memory store has no
+// directory to create, so the path is ignored.
+func (m *MemoryObjectStore) Initialize(repoPath scpath.RepositoryPath) error {
+	return nil
+}
+
+// HashAlgorithm returns the hash algorithm naming this store's objects.
+func (m *MemoryObjectStore) HashAlgorithm() objects.HashAlgorithm {
+	return m.algorithm
+}
+
+// WriteObject stores a Git object and returns its hash. Writing an object
+// that is already stored is a no-op.
+func (m *MemoryObjectStore) WriteObject(obj objects.BaseObject) (objects.ObjectHash, error) {
+	var buf bytes.Buffer
+	if err := obj.Serialize(&buf); err != nil {
+		return "", fmt.Errorf("failed to serialize object: %w", err)
+	}
+
+	serialized := objects.SerializedObject(buf.Bytes())
+	hash := m.algorithm.Sum(serialized)
+	m.put(hash, serialized)
+	return hash, nil
+}
+
+// put stores a serialized object under hash unless it is already present.
+func (m *MemoryObjectStore) put(hash objects.ObjectHash, serialized objects.SerializedObject) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.objects[hash]; exists {
+		return
+	}
+	m.objects[hash] = serialized
+	m.order = append(m.order, hash)
+}
+
+// ReadObject returns the object with the given hash, or nil if it is not stored.
+func (m *MemoryObjectStore) ReadObject(hash objects.ObjectHash) (objects.BaseObject, error) {
+	if err := hash.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid hash: %w", err)
+	}
+
+	serialized := m.get(hash)
+	if serialized == nil {
+		return nil, nil
+	}
+
+	obj, err := createObjectFromHeader(objects.ObjectContent(serialized), m.algorithm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create object from header: %w", err)
+	}
+	return obj, nil
+}
+
+// ReadSerialized returns the object as it was written, or nil if it is not stored.
+func (m *MemoryObjectStore) ReadSerialized(hash objects.ObjectHash) (objects.SerializedObject, error) {
+	if err := hash.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid hash: %w", err)
+	}
+	return m.get(hash), nil
+}
+
+func (m *MemoryObjectStore) get(hash objects.ObjectHash) objects.SerializedObject {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.objects[hash]
+}
+
+// HasObject reports whether an object with the given hash is stored.
+func (m *MemoryObjectStore) HasObject(hash objects.ObjectHash) (bool, error) {
+	if err := hash.Validate(); err != nil {
+		return false, fmt.Errorf("invalid hash: %w", err)
+	}
+	return m.get(hash) != nil, nil
+}
+
+// WriteBlobStream stores a blob read from r. The content necessarily ends up
+// in memory; the method exists so callers can use the same code path for every
+// store.
+func (m *MemoryObjectStore) WriteBlobStream(r io.Reader, size int64) (objects.ObjectHash, error) {
+	if size < 0 {
+		return "", fmt.Errorf("invalid blob size: %d", size)
+	}
+
+	var buf bytes.Buffer
+	buf.Write(objects.CreateHeader(objects.BlobType, size))
+	if err := copyExactly(&buf, r, size); err != nil {
+		return "", err
+	}
+
+	serialized := objects.SerializedObject(buf.Bytes())
+	hash := m.algorithm.Sum(serialized)
+	m.put(hash, serialized)
+	return hash, nil
+}
+
+// OpenBlob returns a reader over a stored blob's content and its size.
+func (m *MemoryObjectStore) OpenBlob(hash objects.ObjectHash) (io.ReadCloser, int64, error) {
+	if err := hash.Validate(); err != nil {
+		return nil, 0, fmt.Errorf("invalid hash: %w", err)
+	}
+
+	serialized := m.get(hash)
+	if serialized == nil {
+		return nil, 0, fmt.Errorf("%w: %s", ErrObjectNotFound, hash)
+	}
+
+	objType, size, contentStart, err := serialized.ParseHeader()
+	if err != nil {
+		return nil, 0, err
+	}
+	if objType != objects.BlobType {
+		return nil, 0, fmt.Errorf("%w: %s is a %s", ErrNotBlob, hash.Short(), objType)
+	}
+
+	return io.NopCloser(bytes.NewReader(serialized[contentStart:])), size.Int64(), nil
+}
+
+// Hashes returns the hashes of all stored objects in the order they were
+// first written.
+func (m *MemoryObjectStore) Hashes() []objects.ObjectHash {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]objects.ObjectHash(nil), m.order...)
+}
+
+// Len returns the number of stored objects.
+func (m *MemoryObjectStore) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.order)
+}
+
+// remove deletes the given objects, keeping the rest in write order.
+func (m *MemoryObjectStore) remove(hashes []objects.ObjectHash) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	removed := make(map[objects.ObjectHash]bool, len(hashes))
+	for _, hash := range hashes {
+		delete(m.objects, hash)
+		removed[hash] = true
+	}
+
+	kept := m.order[:0]
+	for _, hash := range m.order {
+		if !removed[hash] {
+			kept = append(kept, hash)
+		}
+	}
+	m.order = kept
+}
+
+// Clear removes every object from the store.
+func (m *MemoryObjectStore) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects = make(map[objects.ObjectHash]objects.SerializedObject)
+	m.order = nil
+}
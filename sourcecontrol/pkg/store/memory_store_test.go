@@ -0,0 +1,128 @@
+package store
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/blob"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/tree"
+)
+
+// Both in-memory stores must be usable wherever an ObjectStore is expected
+var (
+	_ ObjectStore = (*MemoryObjectStore)(nil)
+	_ ObjectStore = (*OverlayObjectStore)(nil)
+)
+
+func TestMemoryObjectStore_WriteAndRead(t *testing.T) {
+	m := NewMemoryObjectStore()
+
+	b := blob.NewBlob([]byte("in memory\n"))
+	hash, err := m.WriteObject(b)
+	if err != nil {
+		t.Fatalf("WriteObject() failed: %v", err)
+	}
+
+	want, _ := b.Hash()
+	if hash != want {
+		t.Errorf("WriteObject() hash = %s, want %s", hash, want)
+	}
+
+	entry, _ := tree.NewTreeEntry(objects.FileModeRegular, "file.txt", hash)
+	treeHash, err := m.WriteObject(tree.NewTree([]*tree.TreeEntry{entry}))
+	if err != nil {
+		t.Fatalf("WriteObject(tree) failed: %v", err)
+	}
+
+	obj, err := m.ReadObject(treeHash)
+	if err != nil || obj == nil {
+		t.Fatalf("ReadObject() = %v, %v", obj, err)
+	}
+	if obj.Type() != objects.TreeType {
+		t.Errorf("ReadObject() type = %s, want tree", obj.Type())
+	}
+
+	if has, _ := m.HasObject(hash); !has {
+		t.Error("HasObject() = false for a stored blob")
+	}
+
+	missing := objects.ObjectHash(strings.Repeat("0", 40))
+	if obj, err := m.ReadObject(missing); obj != nil || err != nil {
+		t.Errorf("ReadObject(missing) = %v, %v; want nil, nil", obj, err)
+	}
+	if _, err := m.ReadObject("not-a-hash"); err == nil {
+		t.Error("ReadObject() should reject an invalid hash")
+	}
+
+	// Duplicate writes do not add entries
+	m.WriteObject(b)
+	if m.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", m.Len())
+	}
+}
+
+func TestMemoryObjectStore_Streams(t *testing.T) {
+	m := NewMemoryObjectStore()
+
+	content := []byte(strings.Repeat("stream me\n", 100))
+	hash, err := m.WriteBlobStream(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatalf("WriteBlobStream() failed: %v", err)
+	}
+	if want, _ := blob.NewBlob(content).Hash(); hash != want {
+		t.Errorf("WriteBlobStream() hash = %s, want %s", hash, want)
+	}
+
+	rc, size, err := m.OpenBlob(hash)
+	if err != nil {
+		t.Fatalf("OpenBlob() failed: %v", err)
+	}
+	got, _ := io.ReadAll(rc)
+	rc.Close()
+	if size != int64(len(content)) || !bytes.Equal(got, content) {
+		t.Error("OpenBlob() returned different content")
+	}
+
+	if _, err := m.WriteBlobStream(strings.NewReader("abc"), 4); !errors.Is(err, ErrSizeMismatch) {
+		t.Errorf("WriteBlobStream(short) err = %v, want ErrSizeMismatch", err)
+	}
+	if _, _, err := m.OpenBlob(objects.ObjectHash(strings.Repeat("1", 40))); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("OpenBlob(missing) err = %v, want ErrObjectNotFound", err)
+	}
+}
+
+func TestMemoryObjectStore_Concurrent(t *testing.T) {
+	m := NewMemoryObjectStore()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				// Workers overlap on content so duplicate writes race too
+				b := blob.NewBlob([]byte(fmt.Sprintf("object %d", (worker*50+j)%400)))
+				hash, err := m.WriteObject(b)
+				if err != nil {
+					t.Errorf("WriteObject() failed: %v", err)
+					return
+				}
+				if obj, err := m.ReadObject(hash); err != nil || obj == nil {
+					t.Errorf("ReadObject(%s) = %v, %v", hash.Short(), obj, err)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if m.Len() != 400 {
+		t.Errorf("Len() = %d, want 400", m.Len())
+	}
+}
@@ -0,0 +1,147 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/utkarsh5026/SourceControl/pkg/common/fileops"
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+)
+
+// MigrationFailure describes a loose object that could not be migrated.
+type MigrationFailure struct {
+	Hash  objects.ObjectHash
+	Error error
+}
+
+// MigrationResult summarizes a MigrateLegacyObjects run.
+type MigrationResult struct {
+	// Scanned is the number of loose object files examined
+	Scanned int
+	// Migrated lists objects that were (or, in dry-run mode, would be)
+	// rewritten from raw DEFLATE to zlib
+	Migrated []objects.ObjectHash
+	// Failed lists objects that could not be decoded or failed verification
+	Failed []MigrationFailure
+}
+
+// MigrateLegacyObjects rewrites loose objects stored as raw DEFLATE streams
+// into the zlib format used by git.
+//
+// Before this tool wrote zlib streams, objects were compressed with a bare
+// DEFLATE encoder, which git refuses to read. This walks every loose object
+// under objects/xx/, leaves zlib objects untouched and re-encodes the legacy
+// ones in place. The object content is re-hashed before writing so a corrupt
+// file is reported instead of being silently "fixed" under the wrong name.
+//
+// Each rewrite goes through a temporary file and rename, so an interrupted
+// migration leaves every object either in its old or its new encoding; both
+// remain readable and the migration can simply be run again.
+//
+// Parameters:
+//   - dryRun: when true, report what would be migrated without touching disk
+//
+// Returns:
+//   - *MigrationResult: counts of scanned, migrated and failed objects
+//   - error: if the store is not initialized or the objects directory cannot be read
+func (f *FileObjectStore) MigrateLegacyObjects(dryRun bool) (*MigrationResult, error) {
+	if !f.IsInitialized() {
+		return nil, fmt.Errorf("object store not initialized")
+	}
+
+	result := &MigrationResult{}
+
+	dirs, err := os.ReadDir(f.objectsPath.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read objects directory: %w", err)
+	}
+
+	for _, dir := range dirs {
+		if !dir.IsDir() || !isFanoutDirName(dir.Name()) {
+			continue
+		}
+
+		dirPath := filepath.Join(f.objectsPath.String(), dir.Name())
+		files, err := os.ReadDir(dirPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read object directory %s: %w", dir.Name(), err)
+		}
+
+		for _, file := range files {
+			if file.IsDir() {
+				continue
+			}
+
+			hash, err := objects.NewObjectHashFromString(dir.Name() + file.Name())
+			if err != nil {
+				continue
+			}
+
+			result.Scanned++
+			migrated, err := f.migrateObject(hash, scpath.AbsolutePath(filepath.Join(dirPath, file.Name())), dryRun)
+			if err != nil {
+				result.Failed = append(result.Failed, MigrationFailure{Hash: hash, Error: err})
+				continue
+			}
+			if migrated {
+				result.Migrated = append(result.Migrated, hash)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// migrateObject re-encodes a single loose object if it is stored as raw DEFLATE.
+// It returns true when the object needed (or, in dry-run mode, would need) migration.
+func (f *FileObjectStore) migrateObject(hash objects.ObjectHash, path scpath.AbsolutePath, dryRun bool) (bool, error) {
+	data, err := fileops.ReadBytesStrict(path)
+	if err != nil {
+		return false, err
+	}
+
+	compressed := objects.CompressedData(data)
+	if !compressed.IsLegacyDeflate() {
+		return false, nil
+	}
+
+	content, err := compressed.Decompress()
+	if err != nil {
+		return false, fmt.Errorf("failed to decompress legacy object: %w", err)
+	}
+
+	if actual := hash.Algorithm().Sum(content); actual != hash {
+		return false, fmt.Errorf("hash mismatch: content hashes to %s", actual)
+	}
+
+	if dryRun {
+		return true, nil
+	}
+
+	recompressed, err := content.Compress()
+	if err != nil {
+		return false, fmt.Errorf("failed to compress object: %w", err)
+	}
+
+	if err := fileops.AtomicWrite(path, recompressed.Bytes(), 0444); err != nil {
+		return false, fmt.Errorf("failed to rewrite object: %w", err)
+	}
+
+	return true, nil
+}
+
+// isFanoutDirName reports whether name is a two-character hex directory
+// (objects/00 through objects/ff), as opposed to objects/info or objects/pack.
+func isFanoutDirName(name string) bool {
+	if len(name) != 2 {
+		return false
+	}
+	for _, c := range name {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,232 @@
+package store
+
+import (
+	"bytes"
+	"compress/flate"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/blob"
+)
+
+// writeLegacyObject stores content the way older releases did: raw DEFLATE
+// without the zlib wrapper.
+func writeLegacyObject(t *testing.T, store *FileObjectStore, content []byte) objects.ObjectHash {
+	t.Helper()
+
+	b := blob.NewBlob(content)
+	var serialized bytes.Buffer
+	if err := b.Serialize(&serialized); err != nil {
+		t.Fatalf("Serialize() failed: %v", err)
+	}
+
+	var compressed bytes.Buffer
+	w, err := flate.NewWriter(&compressed, flate.BestCompression)
+	if err != nil {
+		t.Fatalf("flate.NewWriter() failed: %v", err)
+	}
+	w.Write(serialized.Bytes())
+	w.Close()
+
+	hash := objects.NewObjectHash(serialized.Bytes())
+	path, err := store.resolveObjectPath(hash)
+	if err != nil {
+		t.Fatalf("resolveObjectPath() failed: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path.String()), 0755); err != nil {
+		t.Fatalf("failed to create object dir: %v", err)
+	}
+	if err := os.WriteFile(path.String(), compressed.Bytes(), 0444); err != nil {
+		t.Fatalf("failed to write legacy object: %v", err)
+	}
+
+	return hash
+}
+
+func readObjectFile(t *testing.T, store *FileObjectStore, hash objects.ObjectHash) objects.CompressedData {
+	t.Helper()
+
+	path, err := store.resolveObjectPath(hash)
+	if err != nil {
+		t.Fatalf("resolveObjectPath() failed: %v", err)
+	}
+	data, err := os.ReadFile(path.String())
+	if err != nil {
+		t.Fatalf("failed to read object file: %v", err)
+	}
+	return objects.CompressedData(data)
+}
+
+func TestFileObjectStore_WritesZlib(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	store := NewFileObjectStore()
+	if err := store.Initialize(repoPath); err != nil {
+		t.Fatalf("Initialize() failed: %v", err)
+	}
+
+	hash, err := store.WriteObject(blob.NewBlob([]byte("hello zlib\n")))
+	if err != nil {
+		t.Fatalf("WriteObject() failed: %v", err)
+	}
+
+	data := readObjectFile(t, store, hash)
+	if !data.HasZlibHeader() {
+		t.Fatalf("object file does not start with a zlib header: % x", data[:2])
+	}
+	if data.IsLegacyDeflate() {
+		t.Error("freshly written object reported as legacy")
+	}
+}
+
+func TestFileObjectStore_ReadsLegacyDeflate(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	store := NewFileObjectStore()
+	if err := store.Initialize(repoPath); err != nil {
+		t.Fatalf("Initialize() failed: %v", err)
+	}
+
+	hash := writeLegacyObject(t, store, []byte("legacy content\n"))
+
+	obj, err := store.ReadObject(hash)
+	if err != nil {
+		t.Fatalf("ReadObject() failed: %v", err)
+	}
+	if obj == nil {
+		t.Fatal("ReadObject() returned nil for legacy object")
+	}
+
+	content, _ := obj.Content()
+	if string(content) != "legacy content\n" {
+		t.Errorf("content = %q, want %q", content, "legacy content\n")
+	}
+}
+
+func TestFileObjectStore_MigrateLegacyObjects(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	store := NewFileObjectStore()
+	if err := store.Initialize(repoPath); err != nil {
+		t.Fatalf("Initialize() failed: %v", err)
+	}
+
+	legacy := writeLegacyObject(t, store, []byte("old format\n"))
+	current, err := store.WriteObject(blob.NewBlob([]byte("new format\n")))
+	if err != nil {
+		t.Fatalf("WriteObject() failed: %v", err)
+	}
+
+	dry, err := store.MigrateLegacyObjects(true)
+	if err != nil {
+		t.Fatalf("MigrateLegacyObjects(dry-run) failed: %v", err)
+	}
+	if len(dry.Migrated) != 1 || dry.Migrated[0] != legacy {
+		t.Fatalf("dry run migrated = %v, want [%s]", dry.Migrated, legacy)
+	}
+	if !readObjectFile(t, store, legacy).IsLegacyDeflate() {
+		t.Fatal("dry run rewrote the object")
+	}
+
+	result, err := store.MigrateLegacyObjects(false)
+	if err != nil {
+		t.Fatalf("MigrateLegacyObjects() failed: %v", err)
+	}
+	if result.Scanned != 2 {
+		t.Errorf("Scanned = %d, want 2", result.Scanned)
+	}
+	if len(result.Migrated) != 1 || result.Migrated[0] != legacy {
+		t.Errorf("Migrated = %v, want [%s]", result.Migrated, legacy)
+	}
+	if len(result.Failed) != 0 {
+		t.Errorf("Failed = %v, want none", result.Failed)
+	}
+
+	for _, hash := range []objects.ObjectHash{legacy, current} {
+		if readObjectFile(t, store, hash).IsLegacyDeflate() {
+			t.Errorf("object %s still in legacy format", hash)
+		}
+		if obj, err := store.ReadObject(hash); err != nil || obj == nil {
+			t.Errorf("ReadObject(%s) after migration = %v, %v", hash, obj, err)
+		}
+	}
+
+	again, err := store.MigrateLegacyObjects(false)
+	if err != nil {
+		t.Fatalf("second MigrateLegacyObjects() failed: %v", err)
+	}
+	if len(again.Migrated) != 0 {
+		t.Errorf("second run migrated %v, want nothing", again.Migrated)
+	}
+}
+
+func TestFileObjectStore_MigrateReportsCorruptObjects(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	store := NewFileObjectStore()
+	if err := store.Initialize(repoPath); err != nil {
+		t.Fatalf("Initialize() failed: %v", err)
+	}
+
+	hash := writeLegacyObject(t, store, []byte("original\n"))
+	wrongName := objects.ObjectHash(strings.Repeat("ab", 20))
+	from, _ := store.resolveObjectPath(hash)
+	to, _ := store.resolveObjectPath(wrongName)
+	os.MkdirAll(filepath.Dir(to.String()), 0755)
+	if err := os.Rename(from.String(), to.String()); err != nil {
+		t.Fatalf("failed to rename object: %v", err)
+	}
+
+	result, err := store.MigrateLegacyObjects(false)
+	if err != nil {
+		t.Fatalf("MigrateLegacyObjects() failed: %v", err)
+	}
+	if len(result.Failed) != 1 || result.Failed[0].Hash != wrongName {
+		t.Fatalf("Failed = %v, want [%s]", result.Failed, wrongName)
+	}
+	if !readObjectFile(t, store, wrongName).IsLegacyDeflate() {
+		t.Error("corrupt object should be left untouched")
+	}
+}
+
+func TestFileObjectStore_GitCanReadObjects(t *testing.T) {
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git not available")
+	}
+
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	if out, err := exec.Command(gitPath, "init", "-q", repoPath.String()).CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v: %s", err, out)
+	}
+
+	store := NewFileObjectStore()
+	if err := store.Initialize(repoPath); err != nil {
+		t.Fatalf("Initialize() failed: %v", err)
+	}
+
+	hash, err := store.WriteObject(blob.NewBlob([]byte("readable by git\n")))
+	if err != nil {
+		t.Fatalf("WriteObject() failed: %v", err)
+	}
+
+	cmd := exec.Command(gitPath, "cat-file", "-p", hash.String())
+	cmd.Dir = repoPath.String()
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git cat-file failed: %v: %s", err, out)
+	}
+	if string(out) != "readable by git\n" {
+		t.Errorf("git cat-file output = %q", out)
+	}
+}
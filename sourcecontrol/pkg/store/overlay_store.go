@@ -0,0 +1,182 @@
+package store
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+)
+
+// OverlayObjectStore layers an in-memory write buffer over another store.
+//
+// Reads see both layers, writes only go to memory. Nothing reaches the base
+// store until Commit is called, and Discard throws the buffered objects away.
+// This lets speculative work such as a trial merge or a dry-run rebase create
+// trees and commits without leaving objects behind in .git/objects.
+//
+//	          ┌──────────────────────┐
+//	write ──▶ │ pending (memory)     │ ── Commit() ──▶ base
+//	          └──────────────────────┘
+//	read  ──▶ pending, then base
+//
+// Thread Safety:
+// All methods are safe for concurrent use, provided the base store is.
+type OverlayObjectStore struct {
+	base    ObjectStore
+	pending *MemoryObjectStore
+
+	// commitMu serializes Commit and Discard so a commit never races with
+	// a discard of the objects it is writing
+	commitMu sync.Mutex
+}
+
+// NewOverlayObjectStore creates an overlay on top of base. The base store
+// must already be initialized.
+func NewOverlayObjectStore(base ObjectStore) *OverlayObjectStore {
+	return &OverlayObjectStore{
+		base:    base,
+		pending: NewMemoryObjectStoreWithAlgorithm(base.HashAlgorithm()),
+	}
+}
+
+// Initialize exists to satisfy ObjectStore; the base store is expected to be
+// initialized by its owner, so the path is ignored.
+func (o *OverlayObjectStore) Initialize(repoPath scpath.RepositoryPath) error {
+	return nil
+}
+
+// HashAlgorithm returns the base store's hash algorithm, which the write
+// buffer shares.
+func (o *OverlayObjectStore) HashAlgorithm() objects.HashAlgorithm {
+	return o.base.HashAlgorithm()
+}
+
+// WriteObject buffers an object in memory. Objects the base store already
+// has are not buffered, since committing them would be a no-op.
+func (o *OverlayObjectStore) WriteObject(obj objects.BaseObject) (objects.ObjectHash, error) {
+	var buf bytes.Buffer
+	if err := obj.Serialize(&buf); err != nil {
+		return "", fmt.Errorf("failed to serialize object: %w", err)
+	}
+	hash := o.HashAlgorithm().Sum(buf.Bytes())
+
+	if exists, err := o.base.HasObject(hash); err == nil && exists {
+		return hash, nil
+	}
+
+	return o.pending.WriteObject(obj)
+}
+
+// ReadObject returns the object from the write buffer or, failing that,
+// from the base store.
+func (o *OverlayObjectStore) ReadObject(hash objects.ObjectHash) (objects.BaseObject, error) {
+	obj, err := o.pending.ReadObject(hash)
+	if err != nil || obj != nil {
+		return obj, err
+	}
+	return o.base.ReadObject(hash)
+}
+
+// ReadSerialized returns the stored bytes from the write buffer or, failing
+// that, from the base store.
+func (o *OverlayObjectStore) ReadSerialized(hash objects.ObjectHash) (objects.SerializedObject, error) {
+	serialized, err := o.pending.ReadSerialized(hash)
+	if err != nil || serialized != nil {
+		return serialized, err
+	}
+	return ReadSerialized(o.base, hash)
+}
+
+// HasObject reports whether either layer has the object.
+func (o *OverlayObjectStore) HasObject(hash objects.ObjectHash) (bool, error) {
+	exists, err := o.pending.HasObject(hash)
+	if err != nil || exists {
+		return exists, err
+	}
+	return o.base.HasObject(hash)
+}
+
+// WriteBlobStream buffers a blob read from r in memory. Large blobs should
+// be written to the base store directly if they are going to be kept anyway.
+func (o *OverlayObjectStore) WriteBlobStream(r io.Reader, size int64) (objects.ObjectHash, error) {
+	return o.pending.WriteBlobStream(r, size)
+}
+
+// OpenBlob opens the blob from the write buffer or, failing that, from the
+// base store.
+func (o *OverlayObjectStore) OpenBlob(hash objects.ObjectHash) (io.ReadCloser, int64, error) {
+	rc, size, err := o.pending.OpenBlob(hash)
+	if errors.Is(err, ErrObjectNotFound) {
+		return o.base.OpenBlob(hash)
+	}
+	return rc, size, err
+}
+
+// Pending returns the hashes of the buffered objects in the order they were
+// written.
+func (o *OverlayObjectStore) Pending() []objects.ObjectHash {
+	return o.pending.Hashes()
+}
+
+// Commit writes every buffered object to the base store, in the order they
+// were written, and removes them from the buffer. Since trees are normally
+// written before the commits that reference them, an interrupted Commit does
+// not leave a commit in the base store whose tree is missing. Objects written
+// while Commit runs stay buffered for the next one.
+//
+// If a write fails the buffer is left untouched, so Commit can be retried;
+// objects already copied are harmless duplicates in a content-addressed store.
+func (o *OverlayObjectStore) Commit() error {
+	o.commitMu.Lock()
+	defer o.commitMu.Unlock()
+
+	hashes := o.pending.Hashes()
+	for _, hash := range hashes {
+		if err := o.commitObject(hash); err != nil {
+			return fmt.Errorf("commit object %s: %w", hash.Short(), err)
+		}
+	}
+
+	o.pending.remove(hashes)
+	return nil
+}
+
+// commitObject copies one buffered object to the base store.
+func (o *OverlayObjectStore) commitObject(hash objects.ObjectHash) error {
+	serialized := o.pending.get(hash)
+
+	objType, size, contentStart, err := serialized.ParseHeader()
+	if err != nil {
+		return err
+	}
+
+	var written objects.ObjectHash
+	if objType == objects.BlobType {
+		written, err = o.base.WriteBlobStream(bytes.NewReader(serialized[contentStart:]), size.Int64())
+	} else {
+		var obj objects.BaseObject
+		if obj, err = createObjectFromHeader(objects.ObjectContent(serialized), o.pending.algorithm); err != nil {
+			return err
+		}
+		written, err = o.base.WriteObject(obj)
+	}
+	if err != nil {
+		return err
+	}
+
+	if written != hash {
+		return fmt.Errorf("base store wrote it as %s", written.Short())
+	}
+	return nil
+}
+
+// Discard drops every buffered object.
+func (o *OverlayObjectStore) Discard() {
+	o.commitMu.Lock()
+	defer o.commitMu.Unlock()
+	o.pending.Clear()
+}
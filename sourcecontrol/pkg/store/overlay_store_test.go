@@ -0,0 +1,186 @@
+package store
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/blob"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/commit"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/tree"
+)
+
+// writeSpeculativeCommit writes a blob, a tree and a commit through s and
+// returns their hashes in that order.
+func writeSpeculativeCommit(t *testing.T, s ObjectStore, content string) []objects.ObjectHash {
+	t.Helper()
+
+	blobHash, err := s.WriteObject(blob.NewBlob([]byte(content)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, _ := tree.NewTreeEntry(objects.FileModeRegular, "file.txt", blobHash)
+	treeHash, err := s.WriteObject(tree.NewTree([]*tree.TreeEntry{entry}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	person, _ := commit.NewCommitPerson("Test", "test@example.com", time.Unix(1700000000, 0))
+	c, err := commit.NewCommitBuilder().
+		TreeHash(treeHash).
+		Author(person).
+		Committer(person).
+		Message("speculative").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitHash, err := s.WriteObject(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return []objects.ObjectHash{blobHash, treeHash, commitHash}
+}
+
+func TestOverlayObjectStore_Discard(t *testing.T) {
+	base := newTestStore(t)
+	overlay := NewOverlayObjectStore(base)
+
+	hashes := writeSpeculativeCommit(t, overlay, "trial merge result\n")
+
+	for _, hash := range hashes {
+		if obj, err := overlay.ReadObject(hash); err != nil || obj == nil {
+			t.Errorf("overlay ReadObject(%s) = %v, %v", hash.Short(), obj, err)
+		}
+		if has, _ := base.HasObject(hash); has {
+			t.Errorf("object %s reached the base store before Commit()", hash.Short())
+		}
+	}
+	if len(overlay.Pending()) != 3 {
+		t.Errorf("Pending() = %d objects, want 3", len(overlay.Pending()))
+	}
+
+	overlay.Discard()
+
+	if n, _ := base.LooseObjectCount(); n != 0 {
+		t.Errorf("base store has %d objects after Discard(), want 0", n)
+	}
+	if has, _ := overlay.HasObject(hashes[2]); has {
+		t.Error("discarded object is still visible through the overlay")
+	}
+}
+
+func TestOverlayObjectStore_Commit(t *testing.T) {
+	base := newTestStore(t)
+
+	existing, err := base.WriteObject(blob.NewBlob([]byte("already on disk\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	overlay := NewOverlayObjectStore(base)
+
+	// Objects the base already has are read through and not buffered
+	if obj, err := overlay.ReadObject(existing); err != nil || obj == nil {
+		t.Fatalf("overlay ReadObject(base object) = %v, %v", obj, err)
+	}
+	if _, err := overlay.WriteObject(blob.NewBlob([]byte("already on disk\n"))); err != nil {
+		t.Fatal(err)
+	}
+	if len(overlay.Pending()) != 0 {
+		t.Errorf("Pending() = %d after rewriting a base object, want 0", len(overlay.Pending()))
+	}
+
+	hashes := writeSpeculativeCommit(t, overlay, "kept result\n")
+
+	large := []byte(strings.Repeat("streamed into the overlay\n", 1000))
+	largeHash, err := overlay.WriteBlobStream(bytes.NewReader(large), int64(len(large)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashes = append(hashes, largeHash)
+
+	if err := overlay.Commit(); err != nil {
+		t.Fatalf("Commit() failed: %v", err)
+	}
+	if len(overlay.Pending()) != 0 {
+		t.Errorf("Pending() = %d after Commit(), want 0", len(overlay.Pending()))
+	}
+
+	for _, hash := range hashes {
+		obj, err := base.ReadObject(hash)
+		if err != nil || obj == nil {
+			t.Fatalf("base ReadObject(%s) after Commit() = %v, %v", hash.Short(), obj, err)
+		}
+		if got, _ := obj.Hash(); got != hash {
+			t.Errorf("committed object %s re-hashes to %s", hash.Short(), got.Short())
+		}
+	}
+
+	rc, _, err := overlay.OpenBlob(largeHash)
+	if err != nil {
+		t.Fatalf("OpenBlob() through overlay failed: %v", err)
+	}
+	defer rc.Close()
+	if got, _ := io.ReadAll(rc); !bytes.Equal(got, large) {
+		t.Error("OpenBlob() through overlay returned different content")
+	}
+}
+
+func TestOverlayObjectStore_ConcurrentCommit(t *testing.T) {
+	base := newTestStore(t)
+	overlay := NewOverlayObjectStore(base)
+
+	const writers, perWriter = 4, 50
+	hashes := make([][]objects.ObjectHash, writers)
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				hash, err := overlay.WriteObject(blob.NewBlob([]byte(fmt.Sprintf("writer %d object %d\n", w, i))))
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				hashes[w] = append(hashes[w], hash)
+			}
+		}(w)
+	}
+
+	// Commit while the writers are running; nothing written meanwhile may
+	// be dropped from the buffer without reaching the base store
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		default:
+		}
+		if err := overlay.Commit(); err != nil {
+			t.Fatalf("Commit() failed: %v", err)
+		}
+	}
+
+	for _, written := range hashes {
+		for _, hash := range written {
+			if has, err := base.HasObject(hash); err != nil || !has {
+				t.Fatalf("object %s written during Commit() is missing from the base store", hash.Short())
+			}
+		}
+	}
+	if len(overlay.Pending()) != 0 {
+		t.Errorf("Pending() = %d after the last Commit(), want 0", len(overlay.Pending()))
+	}
+}
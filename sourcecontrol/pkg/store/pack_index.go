@@ -0,0 +1,228 @@
+package store
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"github.com/utkarsh5026/SourceControl/pkg/common/fileops"
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+)
+
+const (
+	// packIndexVersion is the only pack index version we understand
+	packIndexVersion = 2
+
+	// packIndexHeaderSize is the magic number plus version
+	packIndexHeaderSize = 8
+
+	// packIndexFanoutSize is 256 big-endian uint32 cumulative counts
+	packIndexFanoutSize = 256 * 4
+
+	// packLargeOffsetFlag marks a 32-bit offset entry that indexes into the
+	// 64-bit offset table instead of holding the offset itself
+	packLargeOffsetFlag = 0x80000000
+)
+
+// packIndexMagic is the signature at the start of every v2 (and later) index: "\377tOc"
+var packIndexMagic = []byte{0xff, 't', 'O', 'c'}
+
+// PackIndex is an in-memory view of a version 2 pack index (.idx) file.
+//
+// The index maps object hashes to byte offsets inside the companion .pack
+// file. Its layout is:
+//
+//	┌───────────────────────────────┐
+//	│ magic "\377tOc" │ version (2) │  8 bytes
+//	├───────────────────────────────┤
+//	│ fan-out table                 │  256 × uint32 cumulative counts,
+//	│                               │  indexed by first hash byte
+//	├───────────────────────────────┤
+//	│ sorted object hashes          │  N × 20 bytes
+//	├───────────────────────────────┤
+//	│ CRC32 of packed data          │  N × uint32
+//	├───────────────────────────────┤
+//	│ 31-bit pack offsets           │  N × uint32 (MSB set → 64-bit table)
+//	├───────────────────────────────┤
+//	│ 64-bit pack offsets           │  M × uint64 (only for packs > 2GiB)
+//	├───────────────────────────────┤
+//	│ pack checksum │ idx checksum  │  20 + 20 bytes
+//	└───────────────────────────────┘
+//
+// Lookups use the fan-out table to narrow the search to hashes sharing the
+// first byte and then binary search within that range.
+type PackIndex struct {
+	fanout       [256]uint32
+	hashes       []byte
+	crcs         []byte
+	offsets      []byte
+	largeOffsets []byte
+	packChecksum objects.RawHash
+}
+
+// ReadPackIndex loads and validates a version 2 pack index from disk.
+func ReadPackIndex(path scpath.AbsolutePath) (*PackIndex, error) {
+	data, err := fileops.ReadBytesStrict(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pack index: %w", err)
+	}
+
+	return ParsePackIndex(data)
+}
+
+// ParsePackIndex decodes a version 2 pack index from its raw bytes.
+//
+// The trailing checksum is verified, so a truncated or corrupted index is
+// rejected rather than producing wrong offsets later on.
+func ParsePackIndex(data []byte) (*PackIndex, error) {
+	const hashLen = objects.RawHashLength
+	minSize := packIndexHeaderSize + packIndexFanoutSize + 2*hashLen
+	if len(data) < minSize {
+		return nil, fmt.Errorf("pack index too short: %d bytes", len(data))
+	}
+
+	if !bytes.Equal(data[:4], packIndexMagic) {
+		return nil, fmt.Errorf("unsupported pack index: missing v2 signature")
+	}
+	if version := binary.BigEndian.Uint32(data[4:8]); version != packIndexVersion {
+		return nil, fmt.Errorf("unsupported pack index version: %d", version)
+	}
+
+	body := data[:len(data)-hashLen]
+	sum := sha1.Sum(body)
+	if !bytes.Equal(sum[:], data[len(data)-hashLen:]) {
+		return nil, fmt.Errorf("pack index checksum mismatch")
+	}
+
+	idx := &PackIndex{}
+	pos := packIndexHeaderSize
+	for i := range idx.fanout {
+		idx.fanout[i] = binary.BigEndian.Uint32(data[pos:])
+		pos += 4
+		if i > 0 && idx.fanout[i] < idx.fanout[i-1] {
+			return nil, fmt.Errorf("pack index fan-out table is not monotonic")
+		}
+	}
+
+	count := int(idx.fanout[255])
+	fixed := count * (hashLen + 4 + 4)
+	if len(body)-pos < fixed+hashLen {
+		return nil, fmt.Errorf("pack index truncated: %d objects declared", count)
+	}
+
+	idx.hashes = data[pos : pos+count*hashLen]
+	pos += count * hashLen
+	idx.crcs = data[pos : pos+count*4]
+	pos += count * 4
+	idx.offsets = data[pos : pos+count*4]
+	pos += count * 4
+
+	// Whatever sits between the 32-bit offsets and the pack checksum is the
+	// 64-bit offset table.
+	largeEnd := len(body) - hashLen
+	if (largeEnd-pos)%8 != 0 {
+		return nil, fmt.Errorf("pack index has malformed 64-bit offset table")
+	}
+	idx.largeOffsets = data[pos:largeEnd]
+	idx.packChecksum = objects.RawHash(data[largeEnd:len(body)])
+
+	return idx, nil
+}
+
+// Count returns the number of objects described by the index.
+func (idx *PackIndex) Count() int {
+	return int(idx.fanout[255])
+}
+
+// PackChecksum returns the checksum of the pack file this index describes.
+func (idx *PackIndex) PackChecksum() objects.RawHash {
+	return idx.packChecksum
+}
+
+// HashAt returns the i-th object hash in sorted order.
+func (idx *PackIndex) HashAt(i int) objects.ObjectHash {
+	at := i * objects.RawHashLength
+	return objects.NewObjectHashFromRaw(idx.hashes[at : at+objects.RawHashLength])
+}
+
+// Hashes returns every object hash in the index, in sorted order.
+func (idx *PackIndex) Hashes() []objects.ObjectHash {
+	hashes := make([]objects.ObjectHash, idx.Count())
+	for i := range hashes {
+		hashes[i] = idx.HashAt(i)
+	}
+	return hashes
+}
+
+// Lookup returns the pack offset of the object with the given hash.
+// The boolean is false if the object is not in this pack.
+func (idx *PackIndex) Lookup(hash objects.ObjectHash) (int64, bool) {
+	raw, err := hash.Raw()
+	if err != nil {
+		return 0, false
+	}
+
+	i, ok := idx.find(raw)
+	if !ok {
+		return 0, false
+	}
+
+	offset, err := idx.offsetAt(i)
+	if err != nil {
+		return 0, false
+	}
+	return offset, true
+}
+
+// Contains reports whether the object is stored in this pack.
+func (idx *PackIndex) Contains(hash objects.ObjectHash) bool {
+	raw, err := hash.Raw()
+	if err != nil {
+		return false
+	}
+	_, ok := idx.find(raw)
+	return ok
+}
+
+// find locates raw in the sorted hash table using the fan-out table to
+// bound the binary search.
+func (idx *PackIndex) find(raw objects.RawHash) (int, bool) {
+	const hashLen = objects.RawHashLength
+	if len(raw) != hashLen {
+		return 0, false
+	}
+
+	lo := 0
+	if raw[0] > 0 {
+		lo = int(idx.fanout[raw[0]-1])
+	}
+	hi := int(idx.fanout[raw[0]])
+
+	i := lo + sort.Search(hi-lo, func(n int) bool {
+		at := (lo + n) * hashLen
+		return bytes.Compare(idx.hashes[at:at+hashLen], raw[:]) >= 0
+	})
+
+	if i < hi && bytes.Equal(idx.hashes[i*hashLen:(i+1)*hashLen], raw[:]) {
+		return i, true
+	}
+	return 0, false
+}
+
+// offsetAt decodes the pack offset of the i-th object, following the
+// indirection into the 64-bit table for packs larger than 2GiB.
+func (idx *PackIndex) offsetAt(i int) (int64, error) {
+	off := binary.BigEndian.Uint32(idx.offsets[i*4:])
+	if off&packLargeOffsetFlag == 0 {
+		return int64(off), nil
+	}
+
+	large := int(off &^ packLargeOffsetFlag)
+	if (large+1)*8 > len(idx.largeOffsets) {
+		return 0, fmt.Errorf("pack index 64-bit offset %d out of range", large)
+	}
+	return int64(binary.BigEndian.Uint64(idx.largeOffsets[large*8:])), nil
+}
@@ -0,0 +1,491 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/utkarsh5026/SourceControl/pkg/common/fileops"
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+)
+
+const (
+	// DefaultPackWindow is the number of preceding objects tried as delta bases
+	DefaultPackWindow = 10
+
+	// DefaultPackDepth is the maximum length of a delta chain
+	DefaultPackDepth = 50
+
+	// minDeltaObjectSize skips delta attempts for objects too small to benefit
+	minDeltaObjectSize = 50
+)
+
+// PackObject is an object queued for writing into a pack.
+type PackObject struct {
+	Hash objects.ObjectHash
+	Type objects.ObjectType
+
+	// Size is the content size in bytes; it is ignored when Data is set
+	Size int64
+
+	// Data is the content. It may be left nil, in which case WritePack
+	// loads it when needed and drops it again, so that only the objects in
+	// the delta window are held in memory at once.
+	Data []byte
+
+	// Path is the last path the object was seen at while walking trees. It
+	// only serves as a hint for grouping similar objects as delta candidates
+	// and may be empty.
+	Path string
+}
+
+// PackObjectLoader returns the content of an object queued without Data.
+type PackObjectLoader func(hash objects.ObjectHash) ([]byte, error)
+
+// ErrPackObjectFormat is returned when writing a pack of objects that are
+// not named by SHA-1. Only version 2 packs with SHA-1 indexes are supported,
+// so SHA-256 repositories keep all their objects loose.
+var ErrPackObjectFormat = errors.New("packfiles only support SHA-1 objects")
+
+// PackWriterOptions controls delta search when writing a pack.
+type PackWriterOptions struct {
+	// Window is how many preceding candidates are tried as a delta base
+	// for each object (0 disables deltas)
+	Window int
+
+	// Depth is the maximum delta chain length
+	Depth int
+}
+
+// DefaultPackWriterOptions returns the options used by gc and repack.
+func DefaultPackWriterOptions() PackWriterOptions {
+	return PackWriterOptions{Window: DefaultPackWindow, Depth: DefaultPackDepth}
+}
+
+// PackWriteResult describes a pack written by WritePack.
+type PackWriteResult struct {
+	PackPath  scpath.AbsolutePath
+	IndexPath scpath.AbsolutePath
+	Checksum  objects.ObjectHash
+	Objects   int
+	Deltas    int
+}
+
+// packSlot is the bookkeeping for one object while the pack is assembled.
+type packSlot struct {
+	obj      *PackObject
+	size     int64
+	nameHash uint32
+	base     int // index of the delta base in the write order, or -1
+	delta    []byte
+	depth    int
+	offset   int64
+	crc      uint32
+}
+
+// WritePack writes objs as a new pack and index into packDir.
+//
+// Delta bases are chosen the way git does it: objects are ordered by type,
+// then by a hash of their path name (so revisions of the same file are
+// adjacent), then by decreasing size (so the newer, usually larger, revision
+// becomes the base and older ones are deltas against it). Each object is
+// then tried against the previous Window objects of the same type and the
+// smallest sufficiently small delta is kept. Deltas are stored as OFS_DELTA
+// entries, so bases always precede the objects that depend on them.
+//
+// Objects queued without Data are read through load, once while they are in
+// the delta window and again when written.
+//
+// The pack is named pack-<checksum>.pack after the SHA-1 of its contents.
+// The .pack is moved into place before the .idx, so readers (which discover
+// packs through their index) never see a half-written pack.
+func WritePack(packDir scpath.AbsolutePath, objs []*PackObject, load PackObjectLoader, opts PackWriterOptions) (*PackWriteResult, error) {
+	for _, obj := range objs {
+		if len(obj.Hash) != objects.SHA1.HexSize() {
+			return nil, fmt.Errorf("%w: %s", ErrPackObjectFormat, obj.Hash)
+		}
+	}
+
+	if err := fileops.EnsureDir(packDir); err != nil {
+		return nil, err
+	}
+
+	slots := orderPackObjects(objs)
+	deltas, err := selectDeltaBases(slots, load, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp(packDir.String(), "tmp_pack_*")
+	if err != nil {
+		return nil, fmt.Errorf("create temp pack: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer func() {
+		tmp.Close()
+		os.Remove(tmpPath)
+	}()
+
+	checksum, err := writePackData(tmp, slots, load)
+	if err != nil {
+		return nil, fmt.Errorf("write pack: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return nil, fmt.Errorf("sync pack: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("close pack: %w", err)
+	}
+
+	name := "pack-" + hex.EncodeToString(checksum[:])
+	packPath := packDir.Join(name + ".pack")
+	indexPath := packDir.Join(name + ".idx")
+
+	if err := os.Chmod(tmpPath, 0444); err != nil {
+		return nil, fmt.Errorf("chmod pack: %w", err)
+	}
+	if err := os.Rename(tmpPath, packPath.String()); err != nil {
+		return nil, fmt.Errorf("rename pack: %w", err)
+	}
+
+	if err := fileops.AtomicWrite(indexPath, encodePackIndex(slots, checksum), 0444); err != nil {
+		return nil, fmt.Errorf("write pack index: %w", err)
+	}
+
+	return &PackWriteResult{
+		PackPath:  packPath,
+		IndexPath: indexPath,
+		Checksum:  objects.NewObjectHashFromRaw(checksum),
+		Objects:   len(slots),
+		Deltas:    deltas,
+	}, nil
+}
+
+// orderPackObjects removes duplicates and sorts objects into delta-search order.
+func orderPackObjects(objs []*PackObject) []*packSlot {
+	seen := make(map[objects.ObjectHash]bool, len(objs))
+	slots := make([]*packSlot, 0, len(objs))
+	for _, obj := range objs {
+		if seen[obj.Hash] {
+			continue
+		}
+		seen[obj.Hash] = true
+		size := obj.Size
+		if obj.Data != nil {
+			size = int64(len(obj.Data))
+		}
+		slots = append(slots, &packSlot{obj: obj, size: size, nameHash: packNameHash(obj.Path), base: -1})
+	}
+
+	typeRank := map[objects.ObjectType]int{
+		objects.CommitType: 0,
+		objects.TagType:    1,
+		objects.TreeType:   2,
+		objects.BlobType:   3,
+	}
+
+	sort.SliceStable(slots, func(i, j int) bool {
+		a, b := slots[i], slots[j]
+		if a.obj.Type != b.obj.Type {
+			return typeRank[a.obj.Type] < typeRank[b.obj.Type]
+		}
+		if a.nameHash != b.nameHash {
+			return a.nameHash < b.nameHash
+		}
+		if a.size != b.size {
+			return a.size > b.size
+		}
+		return a.obj.Hash < b.obj.Hash
+	})
+
+	return slots
+}
+
+// selectDeltaBases runs the sliding-window delta search over the ordered
+// slots and returns the number of objects stored as deltas.
+func selectDeltaBases(slots []*packSlot, load PackObjectLoader, opts PackWriterOptions) (int, error) {
+	if opts.Window <= 0 || opts.Depth <= 0 {
+		return 0, nil
+	}
+
+	// Contents and delta indexes are only needed for objects still inside
+	// the window.
+	contents := make(map[int][]byte)
+	indexes := make(map[int]*deltaIndex)
+	count := 0
+
+	for i, slot := range slots {
+		delete(contents, i-opts.Window-1)
+		delete(indexes, i-opts.Window-1)
+
+		if slot.size < minDeltaObjectSize {
+			continue
+		}
+		target, err := slot.content(load)
+		if err != nil {
+			return 0, err
+		}
+		contents[i] = target
+
+		// Like git, demand that a delta be well under half the object and
+		// shrink that budget as the chain gets deeper.
+		best := -1
+		for j := i - 1; j >= 0 && j >= i-opts.Window; j-- {
+			cand := slots[j]
+			if cand.obj.Type != slot.obj.Type || cand.depth >= opts.Depth {
+				continue
+			}
+			if cand.size < minDeltaObjectSize || cand.size < slot.size/32 {
+				continue
+			}
+
+			maxSize := (len(target)/2 - 20) * (opts.Depth - cand.depth) / (opts.Depth + 1)
+			if slot.delta != nil && len(slot.delta) < maxSize {
+				maxSize = len(slot.delta) - 1
+			}
+			if maxSize <= 0 {
+				continue
+			}
+
+			idx, ok := indexes[j]
+			if !ok {
+				idx = newDeltaIndex(contents[j])
+				indexes[j] = idx
+			}
+
+			if delta, ok := idx.createDelta(target, maxSize); ok {
+				slot.delta = delta
+				best = j
+			}
+		}
+
+		if best >= 0 {
+			slot.base = best
+			slot.depth = slots[best].depth + 1
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// content returns the slot's object content, loading it if it was queued
+// without Data.
+func (slot *packSlot) content(load PackObjectLoader) ([]byte, error) {
+	if slot.obj.Data != nil {
+		return slot.obj.Data, nil
+	}
+	if load == nil {
+		return nil, fmt.Errorf("no content for %s", slot.obj.Hash.Short())
+	}
+
+	data, err := load(slot.obj.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("load %s: %w", slot.obj.Hash.Short(), err)
+	}
+	if int64(len(data)) != slot.size {
+		return nil, fmt.Errorf("object %s is %d bytes, expected %d", slot.obj.Hash.Short(), len(data), slot.size)
+	}
+	return data, nil
+}
+
+// writePackData streams the pack header, entries and trailing checksum to w.
+func writePackData(w io.Writer, slots []*packSlot, load PackObjectLoader) (objects.RawHash, error) {
+	digest := sha1.New()
+	bw := bufio.NewWriter(io.MultiWriter(w, digest))
+	counter := &countingWriter{w: bw}
+
+	var header [packHeaderSize]byte
+	copy(header[:4], packSignature)
+	binary.BigEndian.PutUint32(header[4:8], 2)
+	binary.BigEndian.PutUint32(header[8:12], uint32(len(slots)))
+	if _, err := counter.Write(header[:]); err != nil {
+		return objects.RawHash{}, err
+	}
+
+	for _, slot := range slots {
+		slot.offset = counter.n
+		if err := writePackEntry(counter, slot, slots, load); err != nil {
+			return objects.RawHash{}, fmt.Errorf("write %s: %w", slot.obj.Hash.Short(), err)
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		return objects.RawHash{}, err
+	}
+
+	checksum := objects.RawHash(digest.Sum(nil))
+	if _, err := w.Write(checksum); err != nil {
+		return objects.RawHash{}, err
+	}
+
+	return checksum, nil
+}
+
+// writePackEntry writes one entry and records its CRC32 for the index.
+func writePackEntry(w io.Writer, slot *packSlot, slots []*packSlot, load PackObjectLoader) error {
+	crc := crc32.NewIEEE()
+	out := io.MultiWriter(w, crc)
+
+	kind, data := packObjOfsDelta, slot.delta
+	if slot.base < 0 {
+		var err error
+		if data, err = slot.content(load); err != nil {
+			return err
+		}
+		kind = packTypeFor(slot.obj.Type)
+	}
+
+	var hdr []byte
+	size := uint64(len(data))
+	c := byte(kind)<<4 | byte(size&0x0f)
+	size >>= 4
+	for size != 0 {
+		hdr = append(hdr, c|0x80)
+		c = byte(size & 0x7f)
+		size >>= 7
+	}
+	hdr = append(hdr, c)
+
+	if slot.base >= 0 {
+		hdr = append(hdr, encodeOffsetDelta(slot.offset-slots[slot.base].offset)...)
+	}
+
+	if _, err := out.Write(hdr); err != nil {
+		return err
+	}
+
+	zw := zlib.NewWriter(out)
+	if _, err := zw.Write(data); err != nil {
+		zw.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	slot.crc = crc.Sum32()
+	return nil
+}
+
+// encodeOffsetDelta encodes the OFS_DELTA base distance, the inverse of readOffsetDelta.
+func encodeOffsetDelta(rel int64) []byte {
+	var buf [10]byte
+	pos := len(buf) - 1
+	buf[pos] = byte(rel & 0x7f)
+	for rel >>= 7; rel != 0; rel >>= 7 {
+		rel--
+		pos--
+		buf[pos] = 0x80 | byte(rel&0x7f)
+	}
+	return buf[pos:]
+}
+
+// encodePackIndex builds a version 2 index for the written slots.
+func encodePackIndex(slots []*packSlot, packChecksum objects.RawHash) []byte {
+	type indexEntry struct {
+		raw    objects.RawHash
+		crc    uint32
+		offset int64
+	}
+
+	entries := make([]indexEntry, len(slots))
+	for i, slot := range slots {
+		raw, _ := slot.obj.Hash.Raw()
+		entries[i] = indexEntry{raw: raw, crc: slot.crc, offset: slot.offset}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].raw[:], entries[j].raw[:]) < 0
+	})
+
+	var buf bytes.Buffer
+	buf.Write(packIndexMagic)
+	binary.Write(&buf, binary.BigEndian, uint32(packIndexVersion))
+
+	var fanout [256]uint32
+	for _, e := range entries {
+		fanout[e.raw[0]]++
+	}
+	var total uint32
+	for i := range fanout {
+		total += fanout[i]
+		binary.Write(&buf, binary.BigEndian, total)
+	}
+
+	for _, e := range entries {
+		buf.Write(e.raw[:])
+	}
+	for _, e := range entries {
+		binary.Write(&buf, binary.BigEndian, e.crc)
+	}
+
+	var large []int64
+	for _, e := range entries {
+		if e.offset < packLargeOffsetFlag {
+			binary.Write(&buf, binary.BigEndian, uint32(e.offset))
+			continue
+		}
+		binary.Write(&buf, binary.BigEndian, uint32(packLargeOffsetFlag|len(large)))
+		large = append(large, e.offset)
+	}
+	for _, off := range large {
+		binary.Write(&buf, binary.BigEndian, uint64(off))
+	}
+
+	buf.Write(packChecksum[:])
+	sum := sha1.Sum(buf.Bytes())
+	buf.Write(sum[:])
+
+	return buf.Bytes()
+}
+
+// packTypeFor maps an object type to its pack type code.
+func packTypeFor(t objects.ObjectType) packObjectType {
+	switch t {
+	case objects.CommitType:
+		return packObjCommit
+	case objects.TreeType:
+		return packObjTree
+	case objects.TagType:
+		return packObjTag
+	default:
+		return packObjBlob
+	}
+}
+
+// packNameHash is git's pack name hash: it weighs the last characters of the
+// path most heavily so files with the same extension and basename sort
+// together, which is where good delta bases usually are.
+func packNameHash(name string) uint32 {
+	var h uint32
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c == ' ' || c == '\t' || c == '\n' {
+			continue
+		}
+		h = (h >> 2) + uint32(c)<<24
+	}
+	return h
+}
+
+// countingWriter tracks the number of bytes written, i.e. the current pack offset.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,220 @@
+package store
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/blob"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+)
+
+func TestCreateDelta_RoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	random := func(n int) []byte {
+		b := make([]byte, n)
+		rng.Read(b)
+		return b
+	}
+
+	base := random(200000)
+	tests := []struct {
+		name   string
+		target []byte
+	}{
+		{"identical", base},
+		{"prefix insert", append([]byte("header\n"), base...)},
+		{"middle edit", append(append(append([]byte{}, base[:1000]...), []byte("changed")...), base[1100:]...)},
+		{"truncated", base[:150000]},
+		{"reordered", append(append([]byte{}, base[100000:]...), base[:100000]...)},
+		{"unrelated", random(5000)},
+	}
+
+	idx := newDeltaIndex(base)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delta, ok := idx.createDelta(tt.target, len(tt.target)*2+64)
+			if !ok {
+				t.Fatal("createDelta() gave up despite a generous budget")
+			}
+
+			got, err := applyDelta(base, delta)
+			if err != nil {
+				t.Fatalf("applyDelta() failed: %v", err)
+			}
+			if !bytes.Equal(got, tt.target) {
+				t.Fatal("delta round trip produced different content")
+			}
+		})
+	}
+
+	if _, ok := idx.createDelta(random(5000), 100); ok {
+		t.Error("createDelta() should give up when the delta exceeds maxSize")
+	}
+}
+
+func TestEncodeOffsetDelta_RoundTrip(t *testing.T) {
+	for _, rel := range []int64{1, 127, 128, 129, 16383, 16384, 16511, 1 << 20, 1<<31 + 5} {
+		encoded := encodeOffsetDelta(rel)
+		got, err := readOffsetDelta(bytes.NewReader(encoded))
+		if err != nil {
+			t.Fatalf("readOffsetDelta(%d) failed: %v", rel, err)
+		}
+		if got != rel {
+			t.Errorf("offset %d round-tripped to %d", rel, got)
+		}
+	}
+}
+
+func TestWritePack_ReadBack(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	fs := NewFileObjectStore()
+	if err := fs.Initialize(repoPath); err != nil {
+		t.Fatalf("Initialize() failed: %v", err)
+	}
+
+	var body strings.Builder
+	for i := 0; i < 500; i++ {
+		fmt.Fprintf(&body, "line %d: some fairly ordinary file content\n", i)
+	}
+
+	var packObjects []*PackObject
+	var hashes []objects.ObjectHash
+	for rev := 0; rev < 8; rev++ {
+		content := []byte(body.String() + strings.Repeat(fmt.Sprintf("rev %d\n", rev), rev+1))
+		b := blob.NewBlob(content)
+		hash, err := fs.WriteObject(b)
+		if err != nil {
+			t.Fatalf("WriteObject() failed: %v", err)
+		}
+		hashes = append(hashes, hash)
+		packObjects = append(packObjects, &PackObject{Hash: hash, Type: objects.BlobType, Data: content, Path: "file.txt"})
+	}
+	small := blob.NewBlob([]byte("tiny"))
+	smallHash, _ := fs.WriteObject(small)
+	hashes = append(hashes, smallHash)
+	packObjects = append(packObjects, &PackObject{Hash: smallHash, Type: objects.BlobType, Data: []byte("tiny")})
+
+	packDir := fs.GetObjectsPath().Join(scpath.PackDir).ToAbsolutePath()
+	result, err := WritePack(packDir, packObjects, nil, DefaultPackWriterOptions())
+	if err != nil {
+		t.Fatalf("WritePack() failed: %v", err)
+	}
+	if result.Objects != len(packObjects) {
+		t.Errorf("Objects = %d, want %d", result.Objects, len(packObjects))
+	}
+	if result.Deltas == 0 {
+		t.Error("expected similar revisions to be stored as deltas")
+	}
+
+	removed, err := fs.PrunePacked()
+	if err != nil {
+		t.Fatalf("PrunePacked() failed: %v", err)
+	}
+	if removed != len(packObjects) {
+		t.Errorf("PrunePacked() removed %d, want %d", removed, len(packObjects))
+	}
+	if n, _ := fs.LooseObjectCount(); n != 0 {
+		t.Errorf("LooseObjectCount() = %d after prune, want 0", n)
+	}
+	if n, _ := fs.ObjectCount(); n != len(packObjects) {
+		t.Errorf("ObjectCount() = %d, want %d", n, len(packObjects))
+	}
+
+	for _, hash := range hashes {
+		obj, err := fs.ReadObject(hash)
+		if err != nil || obj == nil {
+			t.Fatalf("ReadObject(%s) = %v, %v", hash, obj, err)
+		}
+		if got, _ := obj.Hash(); got != hash {
+			t.Errorf("object %s re-hashes to %s", hash, got)
+		}
+	}
+
+	if _, err := exec.LookPath("git"); err == nil {
+		cmd := exec.Command("git", "verify-pack", "-v", result.IndexPath.String())
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git verify-pack failed: %v\n%s", err, out)
+		}
+		if !strings.Contains(string(out), "chain length = 1") {
+			t.Errorf("git verify-pack reports no delta chains:\n%s", out)
+		}
+	}
+}
+
+func TestWritePack_IndexMatchesGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	fs := NewFileObjectStore()
+	if err := fs.Initialize(repoPath); err != nil {
+		t.Fatalf("Initialize() failed: %v", err)
+	}
+
+	// Queued without Data, so the content goes through the loader
+	contents := make(map[objects.ObjectHash][]byte)
+	var packObjects []*PackObject
+	for i := 0; i < 20; i++ {
+		content := []byte(strings.Repeat(fmt.Sprintf("object %d\n", i%5), 20+i))
+		hash := objects.NewObjectHash(objects.NewSerializedObject(objects.BlobType, content))
+		contents[hash] = content
+		packObjects = append(packObjects, &PackObject{Hash: hash, Type: objects.BlobType, Size: int64(len(content))})
+	}
+	load := func(hash objects.ObjectHash) ([]byte, error) {
+		return contents[hash], nil
+	}
+
+	packDir := scpath.AbsolutePath(t.TempDir())
+	result, err := WritePack(packDir, packObjects, load, DefaultPackWriterOptions())
+	if err != nil {
+		t.Fatalf("WritePack() failed: %v", err)
+	}
+
+	ours, err := os.ReadFile(result.IndexPath.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gitIdx := filepath.Join(t.TempDir(), "git.idx")
+	cmd := exec.Command("git", "index-pack", "-o", gitIdx, result.PackPath.String())
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git index-pack failed: %v\n%s", err, out)
+	}
+	theirs, err := os.ReadFile(gitIdx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(ours, theirs) {
+		t.Error("our pack index differs from the one git index-pack builds")
+	}
+}
+
+func TestWritePack_RejectsSHA256(t *testing.T) {
+	content := objects.ObjectContent("sha256 blob")
+	obj := &PackObject{
+		Hash: objects.SHA256.HashObject(objects.BlobType, content),
+		Type: objects.BlobType,
+		Data: content,
+	}
+
+	packDir := scpath.AbsolutePath(t.TempDir())
+	_, err := WritePack(packDir, []*PackObject{obj}, nil, DefaultPackWriterOptions())
+	if !errors.Is(err, ErrPackObjectFormat) {
+		t.Fatalf("WritePack() error = %v, want ErrPackObjectFormat", err)
+	}
+}
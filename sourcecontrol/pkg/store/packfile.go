@@ -0,0 +1,460 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+)
+
+// packObjectType is the 3-bit type code stored in a pack entry header.
+type packObjectType byte
+
+const (
+	packObjCommit   packObjectType = 1
+	packObjTree     packObjectType = 2
+	packObjBlob     packObjectType = 3
+	packObjTag      packObjectType = 4
+	packObjOfsDelta packObjectType = 6
+	packObjRefDelta packObjectType = 7
+)
+
+const (
+	// packHeaderSize is "PACK", the version and the object count
+	packHeaderSize = 12
+
+	// maxDeltaChainDepth bounds delta resolution so a corrupt pack whose
+	// REF_DELTA entries point at each other cannot loop forever
+	maxDeltaChainDepth = 10000
+)
+
+var packSignature = []byte("PACK")
+
+// objectType maps a non-delta pack type code to its object type.
+func (t packObjectType) objectType() (objects.ObjectType, bool) {
+	switch t {
+	case packObjCommit:
+		return objects.CommitType, true
+	case packObjTree:
+		return objects.TreeType, true
+	case packObjBlob:
+		return objects.BlobType, true
+	case packObjTag:
+		return objects.TagType, true
+	default:
+		return "", false
+	}
+}
+
+// baseResolver looks up a REF_DELTA base that is not stored in the same pack.
+type baseResolver func(hash objects.ObjectHash) (objects.ObjectType, []byte, error)
+
+// infoResolver looks up the type and size of a REF_DELTA base that is not
+// stored in the same pack.
+type infoResolver func(hash objects.ObjectHash) (objects.ObjectType, int64, error)
+
+// packEntry is a single decoded entry of a pack file. For delta entries
+// data holds the delta instructions and exactly one of baseOffset or
+// baseHash identifies the base object.
+type packEntry struct {
+	kind       packObjectType
+	data       []byte
+	baseOffset int64
+	baseHash   objects.ObjectHash
+}
+
+// Packfile provides read access to a .pack file through its .idx index.
+//
+// Entries are located through the index and read with positional reads, so
+// the pack is never loaded into memory as a whole. Delta entries, both
+// OFS_DELTA (base given as a relative offset in the same pack) and REF_DELTA
+// (base given by hash), are resolved by walking the chain down to a full
+// object and then applying the deltas back up.
+type Packfile struct {
+	packPath scpath.AbsolutePath
+	index    *PackIndex
+}
+
+// OpenPackfile opens the pack described by the given .idx file. The .pack
+// file must sit next to it with the same base name.
+func OpenPackfile(idxPath scpath.AbsolutePath) (*Packfile, error) {
+	idx, err := ReadPackIndex(idxPath)
+	if err != nil {
+		return nil, err
+	}
+
+	packPath := scpath.AbsolutePath(strings.TrimSuffix(idxPath.String(), ".idx") + ".pack")
+	p := &Packfile{packPath: packPath, index: idx}
+
+	if err := p.verifyHeader(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Path returns the location of the .pack file.
+func (p *Packfile) Path() scpath.AbsolutePath {
+	return p.packPath
+}
+
+// Index returns the pack's index.
+func (p *Packfile) Index() *PackIndex {
+	return p.index
+}
+
+// Contains reports whether the pack holds the given object.
+func (p *Packfile) Contains(hash objects.ObjectHash) bool {
+	return p.index.Contains(hash)
+}
+
+// ReadObject returns the type and content of an object stored in the pack.
+//
+// REF_DELTA bases missing from this pack are requested from external, which
+// may be nil when no other source is available. The boolean result is false
+// if the object is not in this pack.
+func (p *Packfile) ReadObject(hash objects.ObjectHash, external baseResolver) (objects.ObjectType, []byte, bool, error) {
+	offset, ok := p.index.Lookup(hash)
+	if !ok {
+		return "", nil, false, nil
+	}
+
+	file, err := os.Open(p.packPath.String())
+	if err != nil {
+		return "", nil, false, fmt.Errorf("failed to open pack: %w", err)
+	}
+	defer file.Close()
+
+	objType, data, err := p.resolve(file, offset, external)
+	if err != nil {
+		return "", nil, false, fmt.Errorf("failed to read %s from %s: %w", hash.Short(), p.packPath.Base(), err)
+	}
+
+	return objType, data, true, nil
+}
+
+// ObjectInfo returns the type and size of an object stored in the pack,
+// without inflating its content.
+//
+// REF_DELTA bases missing from this pack are looked up through external,
+// which may be nil when no other source is available. The boolean result is
+// false if the object is not in this pack.
+func (p *Packfile) ObjectInfo(hash objects.ObjectHash, external infoResolver) (objects.ObjectType, int64, bool, error) {
+	offset, ok := p.index.Lookup(hash)
+	if !ok {
+		return "", 0, false, nil
+	}
+
+	file, err := os.Open(p.packPath.String())
+	if err != nil {
+		return "", 0, false, fmt.Errorf("failed to open pack: %w", err)
+	}
+	defer file.Close()
+
+	objType, size, err := p.objectInfo(file, offset, external)
+	if err != nil {
+		return "", 0, false, fmt.Errorf("failed to read %s from %s: %w", hash.Short(), p.packPath.Base(), err)
+	}
+
+	return objType, size, true, nil
+}
+
+// resolve reads the entry at offset and, if it is a delta, follows its
+// chain of bases until a full object is found.
+func (p *Packfile) resolve(file *os.File, offset int64, external baseResolver) (objects.ObjectType, []byte, error) {
+	var deltas [][]byte
+	var objType objects.ObjectType
+	var data []byte
+
+	for depth := 0; ; depth++ {
+		if depth > maxDeltaChainDepth {
+			return "", nil, fmt.Errorf("delta chain longer than %d", maxDeltaChainDepth)
+		}
+
+		entry, err := p.readEntry(file, offset)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if t, ok := entry.kind.objectType(); ok {
+			objType, data = t, entry.data
+			break
+		}
+
+		deltas = append(deltas, entry.data)
+
+		if entry.kind == packObjOfsDelta {
+			offset = entry.baseOffset
+			continue
+		}
+
+		if baseOffset, ok := p.index.Lookup(entry.baseHash); ok {
+			offset = baseOffset
+			continue
+		}
+		if external == nil {
+			return "", nil, fmt.Errorf("delta base %s not found", entry.baseHash.Short())
+		}
+		objType, data, err = external(entry.baseHash)
+		if err != nil {
+			return "", nil, fmt.Errorf("resolve delta base %s: %w", entry.baseHash.Short(), err)
+		}
+		break
+	}
+
+	for i := len(deltas) - 1; i >= 0; i-- {
+		var err error
+		data, err = applyDelta(data, deltas[i])
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	return objType, data, nil
+}
+
+// readEntry decodes the entry header at offset and inflates its payload.
+//
+// Entry header layout:
+//
+//	byte 0:   [more:1][type:3][size bits 0-3:4]
+//	byte 1..: [more:1][next 7 size bits]
+//	OFS_DELTA: followed by the negative base offset (big-endian base-128,
+//	           with an implicit +1 per continuation byte)
+//	REF_DELTA: followed by the 20-byte base hash
+//
+// The zlib-compressed payload follows immediately.
+func (p *Packfile) readEntry(file *os.File, offset int64) (*packEntry, error) {
+	entry, size, r, err := p.readEntryHeader(file, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.data, err = inflatePackData(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("inflate entry at %d: %w", offset, err)
+	}
+
+	return entry, nil
+}
+
+// readEntryHeader decodes the entry header at offset, as described for
+// readEntry. It returns the entry without its data, the size of the inflated
+// payload, and a reader positioned at the start of the compressed payload.
+func (p *Packfile) readEntryHeader(file *os.File, offset int64) (*packEntry, uint64, *bufio.Reader, error) {
+	if offset < packHeaderSize {
+		return nil, 0, nil, fmt.Errorf("invalid pack offset %d", offset)
+	}
+
+	r := bufio.NewReader(io.NewSectionReader(file, offset, 1<<62))
+
+	c, err := r.ReadByte()
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("read entry header at %d: %w", offset, err)
+	}
+
+	entry := &packEntry{kind: packObjectType((c >> 4) & 0x07)}
+	size := uint64(c & 0x0f)
+	shift := uint(4)
+	for c&0x80 != 0 {
+		if c, err = r.ReadByte(); err != nil {
+			return nil, 0, nil, fmt.Errorf("read entry size at %d: %w", offset, err)
+		}
+		size |= uint64(c&0x7f) << shift
+		shift += 7
+	}
+
+	switch entry.kind {
+	case packObjCommit, packObjTree, packObjBlob, packObjTag:
+	case packObjOfsDelta:
+		rel, err := readOffsetDelta(r)
+		if err != nil {
+			return nil, 0, nil, fmt.Errorf("read delta offset at %d: %w", offset, err)
+		}
+		if rel <= 0 || rel > offset-packHeaderSize {
+			return nil, 0, nil, fmt.Errorf("delta base offset out of range at %d", offset)
+		}
+		entry.baseOffset = offset - rel
+	case packObjRefDelta:
+		raw := make(objects.RawHash, objects.RawHashLength)
+		if _, err := io.ReadFull(r, raw); err != nil {
+			return nil, 0, nil, fmt.Errorf("read delta base at %d: %w", offset, err)
+		}
+		entry.baseHash = objects.NewObjectHashFromRaw(raw)
+	default:
+		return nil, 0, nil, fmt.Errorf("unknown pack object type %d at %d", entry.kind, offset)
+	}
+
+	return entry, size, r, nil
+}
+
+// objectInfo returns the type and size of the object at offset. Only entry
+// headers are read along a delta chain, plus the start of the top delta,
+// which records the size of its result.
+func (p *Packfile) objectInfo(file *os.File, offset int64, external infoResolver) (objects.ObjectType, int64, error) {
+	entry, size, r, err := p.readEntryHeader(file, offset)
+	if err != nil {
+		return "", 0, err
+	}
+	if t, ok := entry.kind.objectType(); ok {
+		return t, int64(size), nil
+	}
+
+	// The delta starts with the base size and then the result size, each a
+	// varint of at most 10 bytes
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return "", 0, fmt.Errorf("inflate entry at %d: %w", offset, err)
+	}
+	head := make([]byte, 20)
+	n, err := io.ReadFull(zr, head)
+	zr.Close()
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", 0, fmt.Errorf("inflate entry at %d: %w", offset, err)
+	}
+	_, used := readDeltaSize(head[:n])
+	resultSize, used2 := readDeltaSize(head[used:n])
+	if used == 0 || used2 == 0 {
+		return "", 0, fmt.Errorf("invalid delta header at %d", offset)
+	}
+
+	for depth := 0; ; depth++ {
+		if depth > maxDeltaChainDepth {
+			return "", 0, fmt.Errorf("delta chain longer than %d", maxDeltaChainDepth)
+		}
+
+		if entry.kind == packObjOfsDelta {
+			offset = entry.baseOffset
+		} else if baseOffset, ok := p.index.Lookup(entry.baseHash); ok {
+			offset = baseOffset
+		} else {
+			if external == nil {
+				return "", 0, fmt.Errorf("delta base %s not found", entry.baseHash.Short())
+			}
+			objType, _, err := external(entry.baseHash)
+			if err != nil {
+				return "", 0, fmt.Errorf("resolve delta base %s: %w", entry.baseHash.Short(), err)
+			}
+			return objType, int64(resultSize), nil
+		}
+
+		if entry, _, _, err = p.readEntryHeader(file, offset); err != nil {
+			return "", 0, err
+		}
+		if t, ok := entry.kind.objectType(); ok {
+			return t, int64(resultSize), nil
+		}
+	}
+}
+
+// readOffsetDelta decodes the OFS_DELTA base distance.
+func readOffsetDelta(r io.ByteReader) (int64, error) {
+	c, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+
+	rel := int64(c & 0x7f)
+	for c&0x80 != 0 {
+		if c, err = r.ReadByte(); err != nil {
+			return 0, err
+		}
+		if rel > (1<<55)-1 {
+			return 0, fmt.Errorf("offset overflow")
+		}
+		rel = ((rel + 1) << 7) | int64(c&0x7f)
+	}
+	return rel, nil
+}
+
+// inflatePackData decompresses a zlib stream and checks it yields exactly size bytes.
+func inflatePackData(r io.Reader, size uint64) ([]byte, error) {
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	buf := bytes.NewBuffer(make([]byte, 0, size))
+	if _, err := io.Copy(buf, io.LimitReader(zr, int64(size)+1)); err != nil {
+		return nil, err
+	}
+	if uint64(buf.Len()) != size {
+		return nil, fmt.Errorf("size mismatch: header says %d, got %d", size, buf.Len())
+	}
+	return buf.Bytes(), nil
+}
+
+// verifyHeader checks the pack signature, version and that the object count
+// matches the index.
+func (p *Packfile) verifyHeader() error {
+	file, err := os.Open(p.packPath.String())
+	if err != nil {
+		return fmt.Errorf("failed to open pack: %w", err)
+	}
+	defer file.Close()
+
+	var header [packHeaderSize]byte
+	if _, err := io.ReadFull(file, header[:]); err != nil {
+		return fmt.Errorf("failed to read pack header: %w", err)
+	}
+
+	if !bytes.Equal(header[:4], packSignature) {
+		return fmt.Errorf("%s is not a pack file", p.packPath.Base())
+	}
+	if version := binary.BigEndian.Uint32(header[4:8]); version != 2 && version != 3 {
+		return fmt.Errorf("unsupported pack version %d", version)
+	}
+	if count := binary.BigEndian.Uint32(header[8:12]); int(count) != p.index.Count() {
+		return fmt.Errorf("pack has %d objects but index lists %d", count, p.index.Count())
+	}
+
+	return nil
+}
+
+// VerifyChecksum checks the trailing checksum of the .pack file against its
+// content and against the checksum recorded in the index. Reading objects only
+// notices corruption in the entries it touches; this covers the whole file.
+func (p *Packfile) VerifyChecksum() error {
+	const hashLen = objects.RawHashLength
+
+	file, err := os.Open(p.packPath.String())
+	if err != nil {
+		return fmt.Errorf("failed to open pack: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat pack: %w", err)
+	}
+	if info.Size() < packHeaderSize+hashLen {
+		return fmt.Errorf("pack %s is truncated", p.packPath.Base())
+	}
+
+	h := sha1.New()
+	if _, err := io.CopyN(h, bufio.NewReader(file), info.Size()-hashLen); err != nil {
+		return fmt.Errorf("failed to read pack: %w", err)
+	}
+	trailer := make([]byte, hashLen)
+	if _, err := file.ReadAt(trailer, info.Size()-hashLen); err != nil {
+		return fmt.Errorf("failed to read pack checksum: %w", err)
+	}
+
+	if !bytes.Equal(h.Sum(nil), trailer) {
+		return fmt.Errorf("pack %s checksum mismatch", p.packPath.Base())
+	}
+	if !bytes.Equal(trailer, p.index.PackChecksum()) {
+		return fmt.Errorf("pack %s does not match its index", p.packPath.Base())
+	}
+	return nil
+}
@@ -0,0 +1,310 @@
+package store
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/blob"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+)
+
+// runGit runs git in dir and returns trimmed stdout, failing the test on error.
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Test Author",
+		"GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=Test Author",
+		"GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("git %s failed: %v: %s", strings.Join(args, " "), err, stderr.String())
+	}
+	return strings.TrimSpace(stdout.String())
+}
+
+// setupPackedGitRepo creates a git repository with several revisions of
+// similar files (so git produces delta chains) and packs everything.
+// refDeltas selects REF_DELTA instead of the default OFS_DELTA encoding.
+func setupPackedGitRepo(t *testing.T, refDeltas bool) scpath.RepositoryPath {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q")
+
+	var body strings.Builder
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(&body, "line %d of a file that changes a little every commit\n", i)
+	}
+	for rev := 0; rev < 5; rev++ {
+		content := body.String() + fmt.Sprintf("revision %d\n", rev)
+		if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Join(dir, "sub"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "sub", "copy.txt"), []byte(content+"copy\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		runGit(t, dir, "add", ".")
+		runGit(t, dir, "commit", "-q", "-m", fmt.Sprintf("revision %d", rev))
+	}
+	runGit(t, dir, "tag", "-a", "v1", "-m", "annotated tag")
+
+	args := []string{"-c", "repack.useDeltaBaseOffset=" + fmt.Sprint(!refDeltas), "repack", "-a", "-d", "-f", "-q"}
+	runGit(t, dir, args...)
+
+	repoPath, err := scpath.NewRepositoryPath(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repoPath
+}
+
+// allGitObjects lists every object in the repository with its type.
+func allGitObjects(t *testing.T, dir string) map[string]string {
+	t.Helper()
+
+	out := runGit(t, dir, "cat-file", "--batch-all-objects", "--batch-check=%(objectname) %(objecttype)")
+	result := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 {
+			result[fields[0]] = fields[1]
+		}
+	}
+	return result
+}
+
+func testReadAllPackedObjects(t *testing.T, refDeltas bool) {
+	repoPath := setupPackedGitRepo(t, refDeltas)
+
+	looseDirs, _ := filepath.Glob(filepath.Join(repoPath.String(), ".git", "objects", "??"))
+	if len(looseDirs) != 0 {
+		t.Fatalf("expected no loose objects after repack, found %v", looseDirs)
+	}
+
+	store := NewFileObjectStore()
+	if err := store.Initialize(repoPath); err != nil {
+		t.Fatalf("Initialize() failed: %v", err)
+	}
+
+	gitObjects := allGitObjects(t, repoPath.String())
+	if len(gitObjects) == 0 {
+		t.Fatal("git reported no objects")
+	}
+
+	for hashStr, objType := range gitObjects {
+		hash := objects.ObjectHash(hashStr)
+
+		has, err := store.HasObject(hash)
+		if err != nil || !has {
+			t.Errorf("HasObject(%s) = %v, %v", hashStr, has, err)
+			continue
+		}
+
+		obj, err := store.ReadObject(hash)
+		if err != nil {
+			t.Errorf("ReadObject(%s) failed: %v", hashStr, err)
+			continue
+		}
+		if obj == nil {
+			t.Errorf("ReadObject(%s) returned nil", hashStr)
+			continue
+		}
+		if string(obj.Type()) != objType {
+			t.Errorf("object %s type = %s, want %s", hashStr, obj.Type(), objType)
+		}
+
+		got, err := obj.Hash()
+		if err != nil {
+			t.Errorf("Hash(%s) failed: %v", hashStr, err)
+			continue
+		}
+		if got != hash {
+			t.Errorf("object %s re-hashes to %s", hashStr, got)
+		}
+
+		infoType, infoSize, err := store.ReadObjectInfo(hash)
+		if err != nil {
+			t.Errorf("ReadObjectInfo(%s) failed: %v", hashStr, err)
+			continue
+		}
+		size, _ := obj.Size()
+		if string(infoType) != objType || infoSize != size.Int64() {
+			t.Errorf("ReadObjectInfo(%s) = %s %d, want %s %d", hashStr, infoType, infoSize, objType, size.Int64())
+		}
+	}
+}
+
+func TestFileObjectStore_ReadPackedObjects_OfsDelta(t *testing.T) {
+	testReadAllPackedObjects(t, false)
+}
+
+func TestFileObjectStore_ReadPackedObjects_RefDelta(t *testing.T) {
+	testReadAllPackedObjects(t, true)
+}
+
+func TestFileObjectStore_PackedMissingObject(t *testing.T) {
+	repoPath := setupPackedGitRepo(t, false)
+
+	store := NewFileObjectStore()
+	if err := store.Initialize(repoPath); err != nil {
+		t.Fatalf("Initialize() failed: %v", err)
+	}
+
+	missing := objects.ObjectHash(strings.Repeat("0", 39) + "1")
+	has, err := store.HasObject(missing)
+	if err != nil || has {
+		t.Errorf("HasObject(missing) = %v, %v; want false, nil", has, err)
+	}
+
+	obj, err := store.ReadObject(missing)
+	if err != nil || obj != nil {
+		t.Errorf("ReadObject(missing) = %v, %v; want nil, nil", obj, err)
+	}
+}
+
+func TestFileObjectStore_LooseShadowsPacked(t *testing.T) {
+	repoPath := setupPackedGitRepo(t, false)
+
+	store := NewFileObjectStore()
+	if err := store.Initialize(repoPath); err != nil {
+		t.Fatalf("Initialize() failed: %v", err)
+	}
+
+	hash, err := store.WriteObject(blob.NewBlob([]byte("new loose object\n")))
+	if err != nil {
+		t.Fatalf("WriteObject() failed: %v", err)
+	}
+
+	obj, err := store.ReadObject(hash)
+	if err != nil || obj == nil {
+		t.Fatalf("ReadObject(loose) = %v, %v", obj, err)
+	}
+
+	packs, err := store.Packs()
+	if err != nil {
+		t.Fatalf("Packs() failed: %v", err)
+	}
+	if len(packs) != 1 {
+		t.Fatalf("Packs() returned %d packs, want 1", len(packs))
+	}
+	if packs[0].Contains(hash) {
+		t.Error("loose object unexpectedly found in pack")
+	}
+}
+
+func TestParsePackIndex_RejectsCorruption(t *testing.T) {
+	repoPath := setupPackedGitRepo(t, false)
+
+	idxFiles, _ := filepath.Glob(filepath.Join(repoPath.String(), ".git", "objects", "pack", "*.idx"))
+	if len(idxFiles) != 1 {
+		t.Fatalf("expected one index, found %v", idxFiles)
+	}
+
+	data, err := os.ReadFile(idxFiles[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ParsePackIndex(data); err != nil {
+		t.Fatalf("ParsePackIndex() on valid index failed: %v", err)
+	}
+
+	corrupt := append([]byte(nil), data...)
+	corrupt[len(corrupt)/2] ^= 0xff
+	if _, err := ParsePackIndex(corrupt); err == nil {
+		t.Error("ParsePackIndex() accepted corrupted index")
+	}
+
+	if _, err := ParsePackIndex(data[:100]); err == nil {
+		t.Error("ParsePackIndex() accepted truncated index")
+	}
+}
+
+func TestApplyDelta(t *testing.T) {
+	base := []byte("the quick brown fox jumps over the lazy dog")
+
+	// copy "the quick " (offset 0, size 10), insert "red", copy " fox" (offset 15, size 4)
+	delta := []byte{
+		byte(len(base)), // source size
+		17,              // target size
+		0x80 | 0x10, 10, // copy offset=0 (no offset bytes), size=10
+		3, 'r', 'e', 'd', // insert 3 bytes
+		0x80 | 0x01 | 0x10, 15, 4, // copy offset=15, size=4
+	}
+
+	got, err := applyDelta(base, delta)
+	if err != nil {
+		t.Fatalf("applyDelta() failed: %v", err)
+	}
+	if string(got) != "the quick red fox" {
+		t.Errorf("applyDelta() = %q, want %q", got, "the quick red fox")
+	}
+
+	tests := []struct {
+		name  string
+		delta []byte
+	}{
+		{"wrong base size", []byte{5, 1, 1, 'x'}},
+		{"copy out of range", []byte{byte(len(base)), 10, 0x80 | 0x01 | 0x10, 40, 10}},
+		{"truncated insert", []byte{byte(len(base)), 5, 5, 'a'}},
+		{"reserved opcode", []byte{byte(len(base)), 0, 0}},
+		{"wrong target size", []byte{byte(len(base)), 9, 1, 'x'}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := applyDelta(base, tt.delta); !errors.Is(err, ErrInvalidDelta) {
+				t.Errorf("applyDelta() error = %v, want ErrInvalidDelta", err)
+			}
+		})
+	}
+}
+
+func TestFileObjectStore_FindsPackAddedLater(t *testing.T) {
+	repoPath := setupPackedGitRepo(t, false)
+	dir := repoPath.String()
+
+	store := NewFileObjectStore()
+	if err := store.Initialize(repoPath); err != nil {
+		t.Fatalf("Initialize() failed: %v", err)
+	}
+
+	// Load the existing packs before git writes another one
+	if _, err := store.ReadObject(objects.ObjectHash(runGit(t, dir, "rev-parse", "HEAD"))); err != nil {
+		t.Fatalf("ReadObject(HEAD) failed: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "later.txt"), []byte("added after the first scan\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, dir, "add", "later.txt")
+	runGit(t, dir, "commit", "-q", "-m", "later")
+	runGit(t, dir, "repack", "-d", "-q")
+
+	hash := objects.ObjectHash(runGit(t, dir, "rev-parse", "HEAD:later.txt"))
+	obj, err := store.ReadObject(hash)
+	if err != nil || obj == nil {
+		t.Fatalf("ReadObject(%s) = %v, %v; want the newly packed blob", hash, obj, err)
+	}
+}
@@ -0,0 +1,410 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+)
+
+// Packs returns the pack files currently known to the store, rescanning
+// objects/pack first so packs written by other processes are included.
+func (f *FileObjectStore) Packs() ([]*Packfile, error) {
+	if !f.IsInitialized() {
+		return nil, fmt.Errorf("object store not initialized")
+	}
+
+	f.packMu.Lock()
+	defer f.packMu.Unlock()
+
+	if err := f.reloadPacks(); err != nil {
+		return nil, err
+	}
+	return append([]*Packfile(nil), f.packs...), nil
+}
+
+// reloadPacks synchronizes the list of open packs with the contents of
+// objects/pack: new .idx/.pack pairs are opened, packs that have been
+// removed (e.g. by a repack) are dropped, and already open packs are kept.
+// The caller must hold packMu for writing.
+func (f *FileObjectStore) reloadPacks() error {
+	packDir := f.objectsPath.Join(scpath.PackDir).String()
+
+	// Taken before listing, so that a pack added during the scan changes
+	// the time again and is picked up by the next one. A time too recent to
+	// tell apart from such a change is not kept, so the next miss rescans.
+	var modTime time.Time
+	if info, err := os.Stat(packDir); err == nil && time.Since(info.ModTime()) > time.Second {
+		modTime = info.ModTime()
+	}
+
+	entries, err := os.ReadDir(packDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			f.packs = nil
+			f.packsLoaded = true
+			f.packDirMod = time.Time{}
+			return nil
+		}
+		return fmt.Errorf("failed to read pack directory: %w", err)
+	}
+
+	current := make(map[string]*Packfile, len(f.packs))
+	for _, p := range f.packs {
+		current[p.Path().String()] = p
+	}
+
+	var packs []*Packfile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".idx") {
+			continue
+		}
+
+		packPath := filepath.Join(packDir, strings.TrimSuffix(name, ".idx")+".pack")
+		if p, ok := current[packPath]; ok {
+			packs = append(packs, p)
+			continue
+		}
+		if _, err := os.Stat(packPath); err != nil {
+			// An index without its pack is either being written or left
+			// over from an interrupted repack; neither is readable.
+			continue
+		}
+
+		p, err := OpenPackfile(scpath.AbsolutePath(filepath.Join(packDir, name)))
+		if err != nil {
+			return fmt.Errorf("failed to open pack %s: %w", name, err)
+		}
+		packs = append(packs, p)
+	}
+
+	// Deterministic search order regardless of directory listing order
+	sort.Slice(packs, func(i, j int) bool {
+		return packs[i].Path() < packs[j].Path()
+	})
+
+	f.packs = packs
+	f.packsLoaded = true
+	f.packDirMod = modTime
+	return nil
+}
+
+// knownPack returns the pack containing hash among those loaded so far,
+// scanning objects/pack only if it has never been scanned. It returns nil if
+// no known pack contains the object.
+func (f *FileObjectStore) knownPack(hash objects.ObjectHash) (*Packfile, error) {
+	f.packMu.RLock()
+	loaded := f.packsLoaded
+	if loaded {
+		defer f.packMu.RUnlock()
+		return f.searchPacks(hash), nil
+	}
+	f.packMu.RUnlock()
+
+	f.packMu.Lock()
+	defer f.packMu.Unlock()
+	if !f.packsLoaded {
+		if err := f.reloadPacks(); err != nil {
+			return nil, err
+		}
+	}
+	return f.searchPacks(hash), nil
+}
+
+// findPack returns the pack containing hash. If no known pack contains it,
+// objects/pack is rescanned once, and only if its modification time shows
+// that packs were added or removed since the last scan.
+func (f *FileObjectStore) findPack(hash objects.ObjectHash) (*Packfile, error) {
+	p, err := f.knownPack(hash)
+	if err != nil || p != nil {
+		return p, err
+	}
+	return f.refreshPacks(hash, false)
+}
+
+// refreshPacks rescans objects/pack, unconditionally if force is set and
+// otherwise only if the directory changed since the last scan, and returns
+// the pack containing hash.
+func (f *FileObjectStore) refreshPacks(hash objects.ObjectHash, force bool) (*Packfile, error) {
+	var modTime time.Time
+	if info, err := os.Stat(f.objectsPath.Join(scpath.PackDir).String()); err == nil {
+		modTime = info.ModTime()
+	}
+
+	f.packMu.Lock()
+	defer f.packMu.Unlock()
+	if force || !modTime.Equal(f.packDirMod) {
+		if err := f.reloadPacks(); err != nil {
+			return nil, err
+		}
+	}
+	return f.searchPacks(hash), nil
+}
+
+// searchPacks returns the loaded pack containing hash, or nil. The caller
+// must hold packMu.
+func (f *FileObjectStore) searchPacks(hash objects.ObjectHash) *Packfile {
+	for _, p := range f.packs {
+		if p.Contains(hash) {
+			return p
+		}
+	}
+	return nil
+}
+
+// readPacked reads an object from the packs. It returns a nil result if no
+// pack contains the object.
+func (f *FileObjectStore) readPacked(hash objects.ObjectHash) (objects.SerializedObject, error) {
+	p, err := f.findPack(hash)
+	if err != nil || p == nil {
+		return nil, err
+	}
+
+	objType, data, found, err := p.ReadObject(hash, f.readRaw)
+	if errors.Is(err, os.ErrNotExist) {
+		// The pack was removed by a repack since it was loaded; its objects
+		// now live in another pack. The removed pack is still listed, so
+		// the rescan is forced.
+		if p, err = f.refreshPacks(hash, true); err != nil || p == nil {
+			return nil, err
+		}
+		objType, data, found, err = p.ReadObject(hash, f.readRaw)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, nil
+	}
+
+	return objects.NewSerializedObject(objType, data), nil
+}
+
+// ReadPackedObject reads an object from the given pack in serialized form,
+// whether or not a loose copy also exists. Delta bases stored elsewhere are
+// looked up in the rest of the store. It returns nil if the pack does not
+// contain the object.
+func (f *FileObjectStore) ReadPackedObject(p *Packfile, hash objects.ObjectHash) (objects.SerializedObject, error) {
+	objType, data, found, err := p.ReadObject(hash, f.readRaw)
+	if err != nil || !found {
+		return nil, err
+	}
+	return objects.NewSerializedObject(objType, data), nil
+}
+
+// readRaw returns the type and content of an object from any source. It is
+// used to resolve REF_DELTA bases that live outside the pack being read.
+func (f *FileObjectStore) readRaw(hash objects.ObjectHash) (objects.ObjectType, []byte, error) {
+	serialized, err := f.readSerialized(hash)
+	if err != nil {
+		return "", nil, err
+	}
+	if serialized == nil {
+		return "", nil, fmt.Errorf("object %s not found", hash.Short())
+	}
+
+	objType, _, contentStart, err := serialized.ParseHeader()
+	if err != nil {
+		return "", nil, err
+	}
+	return objType, serialized[contentStart:], nil
+}
+
+// LooseObjectCount returns the number of loose objects under objects/xx/.
+func (f *FileObjectStore) LooseObjectCount() (int, error) {
+	hashes, err := f.LooseObjects()
+	if err != nil {
+		return 0, err
+	}
+	return len(hashes), nil
+}
+
+// LooseObjects lists the hashes of all loose objects.
+func (f *FileObjectStore) LooseObjects() ([]objects.ObjectHash, error) {
+	if !f.IsInitialized() {
+		return nil, fmt.Errorf("object store not initialized")
+	}
+
+	dirs, err := os.ReadDir(f.objectsPath.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read objects directory: %w", err)
+	}
+
+	var hashes []objects.ObjectHash
+	for _, dir := range dirs {
+		if !dir.IsDir() || !isFanoutDirName(dir.Name()) {
+			continue
+		}
+
+		files, err := os.ReadDir(filepath.Join(f.objectsPath.String(), dir.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read object directory %s: %w", dir.Name(), err)
+		}
+		for _, file := range files {
+			if hash, err := objects.NewObjectHashFromString(dir.Name() + file.Name()); err == nil && !file.IsDir() {
+				hashes = append(hashes, hash)
+			}
+		}
+	}
+
+	return hashes, nil
+}
+
+// FindObjectsByPrefix returns the hashes of all objects, loose, packed or
+// borrowed from an alternate, whose hex form starts with prefix. The result
+// is sorted and has no duplicates; more than one match means the prefix is
+// ambiguous.
+func (f *FileObjectStore) FindObjectsByPrefix(prefix string) ([]objects.ObjectHash, error) {
+	prefix = strings.ToLower(prefix)
+	seen := make(map[objects.ObjectHash]bool)
+	if err := f.findByPrefix(prefix, seen); err != nil {
+		return nil, err
+	}
+
+	matches := make([]objects.ObjectHash, 0, len(seen))
+	for hash := range seen {
+		matches = append(matches, hash)
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i] < matches[j] })
+	return matches, nil
+}
+
+func (f *FileObjectStore) findByPrefix(prefix string, seen map[objects.ObjectHash]bool) error {
+	if len(prefix) >= 2 {
+		dir := filepath.Join(f.objectsPath.String(), prefix[:2])
+		files, err := os.ReadDir(dir)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read object directory %s: %w", prefix[:2], err)
+		}
+		for _, file := range files {
+			name := prefix[:2] + file.Name()
+			if hash, err := objects.NewObjectHashFromString(name); err == nil && strings.HasPrefix(name, prefix) {
+				seen[hash] = true
+			}
+		}
+	} else {
+		loose, err := f.LooseObjects()
+		if err != nil {
+			return err
+		}
+		for _, hash := range loose {
+			if strings.HasPrefix(hash.String(), prefix) {
+				seen[hash] = true
+			}
+		}
+	}
+
+	packs, err := f.Packs()
+	if err != nil {
+		return err
+	}
+	for _, p := range packs {
+		for _, hash := range p.Index().Hashes() {
+			if strings.HasPrefix(hash.String(), prefix) {
+				seen[hash] = true
+			}
+		}
+	}
+
+	for _, alt := range f.alternates {
+		if err := alt.findByPrefix(prefix, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PrunePacked deletes loose objects that are also stored in a pack and
+// returns how many were removed. Empty fan-out directories are removed too.
+func (f *FileObjectStore) PrunePacked() (int, error) {
+	loose, err := f.LooseObjects()
+	if err != nil {
+		return 0, err
+	}
+
+	packs, err := f.Packs()
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, hash := range loose {
+		packed := false
+		for _, p := range packs {
+			if p.Contains(hash) {
+				packed = true
+				break
+			}
+		}
+		if !packed {
+			continue
+		}
+
+		if err := f.RemoveLooseObject(hash); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+
+	return removed, nil
+}
+
+// LooseObjectModTime returns when the loose copy of an object was written.
+func (f *FileObjectStore) LooseObjectModTime(hash objects.ObjectHash) (time.Time, error) {
+	path, err := f.validateAndResolvePath(hash)
+	if err != nil {
+		return time.Time{}, err
+	}
+	info, err := os.Stat(path.String())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to stat loose object %s: %w", hash.Short(), err)
+	}
+	return info.ModTime(), nil
+}
+
+// RemoveLooseObject deletes the loose copy of an object, and its fan-out
+// directory if that is left empty. A missing file is not an error.
+func (f *FileObjectStore) RemoveLooseObject(hash objects.ObjectHash) error {
+	path, err := f.validateAndResolvePath(hash)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path.String()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove loose object %s: %w", hash.Short(), err)
+	}
+
+	// Only succeeds once the directory is empty
+	os.Remove(path.Dir().String())
+	return nil
+}
+
+// RemovePack deletes a pack and its index. The index goes first so that
+// concurrent readers stop discovering the pack before its data disappears.
+func (f *FileObjectStore) RemovePack(p *Packfile) error {
+	idxPath := strings.TrimSuffix(p.Path().String(), ".pack") + ".idx"
+	if err := os.Remove(idxPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove pack index: %w", err)
+	}
+	if err := os.Remove(p.Path().String()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove pack: %w", err)
+	}
+
+	f.packMu.Lock()
+	defer f.packMu.Unlock()
+
+	kept := f.packs[:0]
+	for _, existing := range f.packs {
+		if existing != p {
+			kept = append(kept, existing)
+		}
+	}
+	f.packs = kept
+	return nil
+}
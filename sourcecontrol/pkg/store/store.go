@@ -1,6 +1,10 @@
 package store
 
 import (
+	"bytes"
+	"fmt"
+	"io"
+
 	"github.com/utkarsh5026/SourceControl/pkg/objects"
 	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
 )
@@ -12,15 +16,58 @@ type ObjectStore interface {
 	// Creates necessary directory structures if they don't exist
 	Initialize(repoPath scpath.RepositoryPath) error
 
-	// WriteObject stores a Git object and returns its SHA-1 hash
+	// HashAlgorithm returns the hash algorithm the store names objects with,
+	// which is the object format of the repository it belongs to
+	HashAlgorithm() objects.HashAlgorithm
+
+	// WriteObject stores a Git object and returns its hash
 	// If the object already exists, it returns the hash without rewriting
 	WriteObject(obj objects.BaseObject) (objects.ObjectHash, error)
 
-	// ReadObject retrieves a Git object by its SHA-1 hash
+	// ReadObject retrieves a Git object by its hash
 	// Returns nil if the object doesn't exist
 	ReadObject(hash objects.ObjectHash) (objects.BaseObject, error)
 
 	// HasObject checks if an object exists in the store
 	// Returns true if the object exists, false otherwise
 	HasObject(hash objects.ObjectHash) (bool, error)
+
+	// WriteBlobStream stores a blob of the given size read from r, hashing and
+	// compressing it incrementally instead of holding it in memory
+	WriteBlobStream(r io.Reader, size int64) (objects.ObjectHash, error)
+
+	// OpenBlob returns a reader over a blob's content and its size
+	// The caller must close the reader
+	OpenBlob(hash objects.ObjectHash) (io.ReadCloser, int64, error)
+}
+
+// SerializedReader is implemented by stores that can return an object exactly
+// as it is stored, header included. Parsing an object and serializing it
+// again is not guaranteed to give back the same bytes (a tree's entries are
+// re-sorted, for example), so commands that must reproduce objects byte for
+// byte read them through ReadSerialized.
+type SerializedReader interface {
+	// ReadSerialized returns the object in the form "<type> <size>\0<content>",
+	// or nil if it does not exist
+	ReadSerialized(hash objects.ObjectHash) (objects.SerializedObject, error)
+}
+
+// ReadSerialized returns an object's stored bytes from any ObjectStore. Stores
+// that do not implement SerializedReader are read through ReadObject and the
+// object is serialized again.
+func ReadSerialized(s ObjectStore, hash objects.ObjectHash) (objects.SerializedObject, error) {
+	if r, ok := s.(SerializedReader); ok {
+		return r.ReadSerialized(hash)
+	}
+
+	obj, err := s.ReadObject(hash)
+	if err != nil || obj == nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := obj.Serialize(&buf); err != nil {
+		return nil, fmt.Errorf("failed to serialize object: %w", err)
+	}
+	return objects.SerializedObject(buf.Bytes()), nil
 }
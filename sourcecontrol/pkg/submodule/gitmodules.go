@@ -0,0 +1,123 @@
+// Package submodule manages repositories nested in a working tree as
+// submodules: the .gitmodules file that names them, the gitlinks that record
+// the commit each one is at, and their clones.
+package submodule
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/utkarsh5026/SourceControl/pkg/repository/gitconfig"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+)
+
+// GitmodulesFile is the file at the root of the working tree that maps
+// submodule names to their paths and URLs.
+const GitmodulesFile = ".gitmodules"
+
+// Submodule is one [submodule "<name>"] section of .gitmodules.
+type Submodule struct {
+	// Name identifies the submodule in .gitmodules and the repository's
+	// configuration; it is the path it was added at
+	Name string
+
+	// Path is where the submodule is checked out, relative to the root
+	Path scpath.RelativePath
+
+	// URL is the repository the submodule is cloned from
+	URL string
+}
+
+// Parse reads the submodule sections of a Git-style config file, such as
+// .gitmodules, in the order they appear. Other sections and keys are
+// skipped; section and key names are case-insensitive, and the last value
+// of a key wins. The syntax is read with gitconfig.Parse.
+//
+// Parameters:
+//   - r: The config file's content
+//
+// Returns:
+//   - []Submodule: One entry per submodule section; Path or URL is empty
+//     if the section does not set it
+//   - error: A syntax error or a read error
+func Parse(r io.Reader) ([]Submodule, error) {
+	entries, err := gitconfig.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var subs []Submodule
+	for _, e := range entries {
+		if e.Section != "submodule" || e.Subsection == "" {
+			continue
+		}
+		switch e.Key {
+		case "path":
+			find(&subs, e.Subsection).Path = scpath.RelativePath(e.Value)
+		case "url":
+			find(&subs, e.Subsection).URL = e.Value
+		}
+	}
+	return subs, nil
+}
+
+// find returns the submodule called name in subs, appending it if a
+// section for it has not been seen yet.
+func find(subs *[]Submodule, name string) *Submodule {
+	for i := range *subs {
+		if (*subs)[i].Name == name {
+			return &(*subs)[i]
+		}
+	}
+	*subs = append(*subs, Submodule{Name: name})
+	return &(*subs)[len(*subs)-1]
+}
+
+// ReadGitmodules returns the submodules listed in the .gitmodules file of
+// the working tree at root, or none if it has no such file.
+func ReadGitmodules(root scpath.RepositoryPath) ([]Submodule, error) {
+	file, err := os.Open(root.Join(GitmodulesFile).String())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", GitmodulesFile, err)
+	}
+	defer file.Close()
+
+	subs, err := Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", GitmodulesFile, err)
+	}
+	return subs, nil
+}
+
+// appendSection adds a [submodule "<name>"] section setting keys, in
+// order, to the config file at path, creating the file if needed. The rest
+// of the file, comments included, is left as it is.
+func appendSection(path scpath.AbsolutePath, name string, keys ...[2]string) error {
+	var b strings.Builder
+	existing, err := os.ReadFile(path.String())
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(existing) > 0 && existing[len(existing)-1] != '\n' {
+		b.WriteByte('\n')
+	}
+	fmt.Fprintf(&b, "[submodule %s]\n", gitconfig.QuoteSubsection(name))
+	for _, kv := range keys {
+		fmt.Fprintf(&b, "\t%s = %s\n", kv[0], gitconfig.FormatValue(kv[1]))
+	}
+
+	file, err := os.OpenFile(path.String(), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.WriteString(b.String()); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,84 @@
+package submodule
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+)
+
+func TestParse(t *testing.T) {
+	content := `# submodules
+[submodule "lib"]
+	path = vendor/lib
+	url = ../lib.git
+[core]
+	url = ignored
+[Submodule "docs"]
+	URL = "/srv/docs"
+	path = docs ; a comment
+[submodule "lib"]
+	url = ../lib2.git
+[submodule "odd]\"name\""]
+	url = "/srv/odd#1;2" ; a comment
+`
+	got, err := Parse(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	want := []Submodule{
+		{Name: "lib", Path: "vendor/lib", URL: "../lib2.git"},
+		{Name: "docs", Path: "docs", URL: "/srv/docs"},
+		{Name: `odd]"name"`, URL: "/srv/odd#1;2"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() = %+v, want %+v", got, want)
+	}
+
+	if _, err := Parse(strings.NewReader("[submodule \"x\"\n")); err == nil {
+		t.Error("Parse() of a malformed header should fail")
+	}
+}
+
+func TestAppendSection(t *testing.T) {
+	root := t.TempDir()
+	if subs, err := ReadGitmodules(scpath.RepositoryPath(root)); err != nil || len(subs) != 0 {
+		t.Fatalf("ReadGitmodules() without a file = %v, %v; want none", subs, err)
+	}
+
+	path := filepath.Join(root, GitmodulesFile)
+	if err := os.WriteFile(path, []byte("# kept"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a", "b"} {
+		if err := appendSection(scpath.AbsolutePath(path), name, [2]string{"path", "libs/" + name}, [2]string{"url", "/src/" + name}); err != nil {
+			t.Fatalf("appendSection() failed: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# kept\n[submodule \"a\"]\n\tpath = libs/a\n\turl = /src/a\n[submodule \"b\"]\n\tpath = libs/b\n\turl = /src/b\n"
+	if string(data) != want {
+		t.Errorf(".gitmodules = %q, want %q", data, want)
+	}
+	subs, err := ReadGitmodules(scpath.RepositoryPath(root))
+	if err != nil || len(subs) != 2 || subs[1].Path != "libs/b" {
+		t.Errorf("ReadGitmodules() = %+v, %v", subs, err)
+	}
+
+	// Names and values are quoted where needed to read back unchanged
+	odd := Submodule{Name: `q"d\`, Path: "libs/q d", URL: "/src/#q;d "}
+	if err := appendSection(scpath.AbsolutePath(path), odd.Name, [2]string{"path", odd.Path.String()}, [2]string{"url", odd.URL}); err != nil {
+		t.Fatal(err)
+	}
+	subs, err = ReadGitmodules(scpath.RepositoryPath(root))
+	if err != nil || len(subs) != 3 || subs[2] != odd {
+		t.Errorf("ReadGitmodules() = %+v, %v; want %+v last", subs, err, odd)
+	}
+}
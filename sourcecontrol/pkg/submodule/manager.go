@@ -0,0 +1,541 @@
+package submodule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/utkarsh5026/SourceControl/pkg/clone"
+	"github.com/utkarsh5026/SourceControl/pkg/index"
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/refs/branch"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/gitconfig"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/refs"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/sourcerepo"
+)
+
+var (
+	// ErrAlreadyExists is returned by Add when the path is already in the
+	// index or the name in .gitmodules.
+	ErrAlreadyExists = errors.New("already exists")
+
+	// ErrUnsupportedURL is returned for submodule URLs that are not local
+	// paths, since only local repositories can be cloned.
+	ErrUnsupportedURL = errors.New("only local repositories are supported")
+
+	// ErrNoMapping is returned when a gitlink in the index has no section
+	// in .gitmodules.
+	ErrNoMapping = errors.New("no submodule mapping found in .gitmodules")
+)
+
+// Status codes, the first column of git submodule status.
+const (
+	StatusCurrent        byte = ' ' // the recorded commit is checked out
+	StatusModified       byte = '+' // another commit is checked out
+	StatusNotInitialized byte = '-' // the submodule is not checked out
+	StatusConflict       byte = 'U' // the gitlink is unmerged
+)
+
+// Status describes the checkout of one submodule.
+type Status struct {
+	Submodule
+
+	// Code is one of the Status codes
+	Code byte
+
+	// Recorded is the commit the index records for the submodule, empty
+	// if the gitlink is unmerged
+	Recorded objects.ObjectHash
+
+	// Head is the commit checked out in the submodule, empty if it is
+	// not checked out
+	Head objects.ObjectHash
+}
+
+// Manager adds, initializes, updates and reports on the submodules of a
+// repository.
+type Manager struct {
+	repo *sourcerepo.SourceRepository
+	root scpath.RepositoryPath
+}
+
+// NewManager creates a Manager for the submodules of repo.
+func NewManager(repo *sourcerepo.SourceRepository) *Manager {
+	return &Manager{repo: repo, root: repo.WorkingDirectory()}
+}
+
+// Add clones the repository at url into path and registers it as a
+// submodule, like git submodule add: a section is added to .gitmodules,
+// the URL to the repository's configuration, and .gitmodules and the
+// submodule's gitlink are staged. A repository already at path is added
+// as it is, without cloning. If registering the submodule fails,
+// .gitmodules and the configuration are left as they were, so Add can be
+// retried.
+//
+// Parameters:
+//   - ctx: Context for cancellation
+//   - url: A local repository, absolute or relative as resolveURL describes
+//   - subPath: Where to check the submodule out, relative to the root; if
+//     empty, the last component of url without ".git"
+//
+// Returns:
+//   - *Submodule: The submodule added
+//   - error: Wrapping ErrAlreadyExists or ErrUnsupportedURL, or the
+//     failure to clone or stage it
+func (m *Manager) Add(ctx context.Context, url, subPath string) (*Submodule, error) {
+	source, err := m.resolveURL(url)
+	if err != nil {
+		return nil, err
+	}
+	if subPath == "" {
+		subPath = strings.TrimSuffix(filepath.Base(source.String()), ".git")
+	}
+	subPath = path.Clean(filepath.ToSlash(subPath))
+	if !scpath.IsPathSafe(subPath) || subPath == "." {
+		return nil, fmt.Errorf("'%s' is outside repository", subPath)
+	}
+	sub := Submodule{Name: subPath, Path: scpath.RelativePath(subPath), URL: url}
+
+	idx, err := m.readIndex()
+	if err != nil {
+		return nil, err
+	}
+	if idx.Has(sub.Path) {
+		return nil, fmt.Errorf("'%s' %w in the index", sub.Path, ErrAlreadyExists)
+	}
+	subs, err := ReadGitmodules(m.root)
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range subs {
+		if existing.Name == sub.Name {
+			return nil, fmt.Errorf("submodule '%s' %w in %s", sub.Name, ErrAlreadyExists, GitmodulesFile)
+		}
+	}
+
+	dest := scpath.RepositoryPath(m.root.Join(subPath).String())
+	if exists, _ := sourcerepo.RepositoryExists(dest); !exists {
+		if _, err := clone.Clone(ctx, clone.Options{Source: source, Destination: dest}); err != nil {
+			return nil, fmt.Errorf("clone '%s' into submodule path '%s': %w", url, sub.Path, err)
+		}
+	}
+
+	if err := m.register(ctx, sub, source, idx); err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// register records sub in .gitmodules and the repository's configuration,
+// and stages .gitmodules and the gitlink. If a step fails, both files and
+// their index entries are put back as they were.
+func (m *Manager) register(ctx context.Context, sub Submodule, source scpath.RepositoryPath, before *index.Index) (err error) {
+	gitmodules := m.root.Join(GitmodulesFile)
+	configPath := m.repo.SourceDirectory().ConfigPath().ToAbsolutePath()
+	for _, file := range []scpath.AbsolutePath{gitmodules, configPath} {
+		restore, snapErr := snapshotFile(file)
+		if snapErr != nil {
+			return snapErr
+		}
+		defer func() {
+			if err != nil {
+				restore()
+			}
+		}()
+	}
+	defer func() {
+		if err != nil {
+			m.restoreEntries(before, GitmodulesFile, sub.Path)
+		}
+	}()
+
+	if err := appendSection(gitmodules, sub.Name, [2]string{"path", sub.Path.String()}, [2]string{"url", sub.URL}); err != nil {
+		return fmt.Errorf("write %s: %w", GitmodulesFile, err)
+	}
+	if err := m.configure(sub.Name, source.String()); err != nil {
+		return err
+	}
+
+	result, err := index.NewManager(m.root).AddWithOptions(ctx, []string{GitmodulesFile, sub.Path.String()}, m.repo.ObjectStore(), index.AddOptions{})
+	if err != nil {
+		return fmt.Errorf("stage submodule: %w", err)
+	}
+	if len(result.Failed) > 0 {
+		return fmt.Errorf("stage '%s': %s", result.Failed[0].Path, result.Failed[0].Reason)
+	}
+	return nil
+}
+
+// snapshotFile reads the file at path and returns a function that writes
+// that content back, or removes the file if it did not exist.
+func snapshotFile(path scpath.AbsolutePath) (func(), error) {
+	data, err := os.ReadFile(path.String())
+	if os.IsNotExist(err) {
+		return func() { os.Remove(path.String()) }, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return func() { os.WriteFile(path.String(), data, 0644) }, nil
+}
+
+// restoreEntries gives paths the stage 0 entries they have in before,
+// removing those it lacks. It is best effort: the index may be locked by
+// whatever made the change fail.
+func (m *Manager) restoreEntries(before *index.Index, paths ...scpath.RelativePath) {
+	index.Update(m.repo.SourceDirectory().IndexPath().ToAbsolutePath(), func(idx *index.Index) error {
+		for _, path := range paths {
+			idx.Remove(path)
+			if entry, ok := before.Get(path); ok {
+				idx.Add(entry)
+			}
+		}
+		return nil
+	})
+}
+
+// Init records the URLs of the submodules that paths select, or of every
+// submodule if paths is empty, in the repository's configuration, like git
+// submodule init. Submodules already initialized are left as they are.
+//
+// Returns:
+//   - []Submodule: The submodules initialized by this call
+//   - error: Wrapping ErrNoMapping or ErrUnsupportedURL, or a
+//     configuration error
+func (m *Manager) Init(paths []string) ([]Submodule, error) {
+	subs, err := m.list(paths)
+	if err != nil {
+		return nil, err
+	}
+	configured, err := m.configuredURLs()
+	if err != nil {
+		return nil, err
+	}
+
+	initialized := make([]Submodule, 0)
+	for _, s := range subs {
+		if configured[s.Name] != "" {
+			continue
+		}
+		source, err := m.resolveURL(s.URL)
+		if err != nil {
+			return initialized, fmt.Errorf("submodule '%s': %w", s.Name, err)
+		}
+		if err := m.configure(s.Name, source.String()); err != nil {
+			return initialized, err
+		}
+		initialized = append(initialized, s.Submodule)
+	}
+	return initialized, nil
+}
+
+// UpdateResult describes a submodule checked out by Update.
+type UpdateResult struct {
+	Submodule
+
+	// Commit is the commit checked out, with HEAD detached at it
+	Commit objects.ObjectHash
+
+	// Cloned tells whether the submodule had to be cloned first
+	Cloned bool
+}
+
+// Update checks out, with a detached HEAD, the commit recorded for each
+// initialized submodule that paths select (every one if paths is empty),
+// cloning it from its configured URL if it is not checked out yet, like git
+// submodule update. Submodules that are not initialized are skipped, and
+// those already at their recorded commit are left as they are.
+//
+// Parameters:
+//   - ctx: Context for cancellation
+//   - paths: Pathspecs selecting submodules
+//   - init: Initialize the selected submodules first (update --init)
+//
+// Returns:
+//   - []UpdateResult: The submodules whose checkout changed
+//   - error: The first submodule that could not be cloned or checked out
+func (m *Manager) Update(ctx context.Context, paths []string, init bool) ([]UpdateResult, error) {
+	if init {
+		if _, err := m.Init(paths); err != nil {
+			return nil, err
+		}
+	}
+	subs, err := m.list(paths)
+	if err != nil {
+		return nil, err
+	}
+	configured, err := m.configuredURLs()
+	if err != nil {
+		return nil, err
+	}
+
+	updated := make([]UpdateResult, 0)
+	for _, s := range subs {
+		url := configured[s.Name]
+		if url == "" || s.Code == StatusConflict || s.Code == StatusCurrent {
+			continue
+		}
+
+		result := UpdateResult{Submodule: s.Submodule, Commit: s.Recorded}
+		dest := scpath.RepositoryPath(m.root.Join(s.Path.String()).String())
+		if s.Code == StatusNotInitialized {
+			source, err := m.resolveURL(url)
+			if err != nil {
+				return updated, fmt.Errorf("submodule '%s': %w", s.Name, err)
+			}
+			if _, err := clone.Clone(ctx, clone.Options{Source: source, Destination: dest}); err != nil {
+				return updated, fmt.Errorf("clone '%s' into submodule path '%s': %w", url, s.Path, err)
+			}
+			result.Cloned = true
+		}
+
+		subRepo, err := sourcerepo.Open(dest)
+		if err != nil {
+			return updated, fmt.Errorf("submodule '%s': %w", s.Path, err)
+		}
+		if head, _ := refs.HeadCommit(dest); head != s.Recorded || result.Cloned {
+			err := branch.NewManager(subRepo).Checkout(ctx, s.Recorded.String(), branch.WithDetach())
+			if err != nil {
+				return updated, fmt.Errorf("unable to checkout '%s' in submodule path '%s': %w", s.Recorded.Short(), s.Path, err)
+			}
+		}
+		updated = append(updated, result)
+	}
+	return updated, nil
+}
+
+// Status reports the checkout of each submodule that paths select, or of
+// every submodule if paths is empty, sorted by path, like git submodule
+// status. The submodules are the gitlinks in the index.
+//
+// Returns:
+//   - []Status: One entry per submodule
+//   - error: Wrapping ErrNoMapping if a gitlink is not in .gitmodules
+func (m *Manager) Status(paths []string) ([]Status, error) {
+	return m.list(paths)
+}
+
+// ForeachOptions configures Foreach.
+type ForeachOptions struct {
+	// Stdout receives an "Entering '<path>'" line per submodule and the
+	// command's output
+	Stdout io.Writer
+
+	// Stderr receives the command's errors
+	Stderr io.Writer
+}
+
+// Foreach runs command with the shell in each submodule that is checked
+// out, in path order, like git submodule foreach. The command can use the
+// variables name, sm_path, displaypath, sha1 (the commit checked out) and
+// toplevel (the root of this repository). It stops at the first command
+// that fails.
+//
+// Parameters:
+//   - ctx: Context for cancellation; it kills the running command
+//   - command: A shell command
+//   - opts: Where output goes
+//
+// Returns:
+//   - error: The failure of a command, naming its submodule
+func (m *Manager) Foreach(ctx context.Context, command string, opts ForeachOptions) error {
+	subs, err := m.list(nil)
+	if err != nil {
+		return err
+	}
+	for _, s := range subs {
+		if s.Head == "" {
+			continue
+		}
+		if opts.Stdout != nil {
+			fmt.Fprintf(opts.Stdout, "Entering '%s'\n", s.Path)
+		}
+
+		cmd := exec.CommandContext(ctx, "sh", "-c", command)
+		cmd.Dir = m.root.Join(s.Path.String()).String()
+		cmd.Stdout, cmd.Stderr = opts.Stdout, opts.Stderr
+		cmd.Env = append(os.Environ(),
+			"name="+s.Name,
+			"sm_path="+s.Path.String(),
+			"displaypath="+s.Path.String(),
+			"sha1="+s.Head.String(),
+			"toplevel="+m.root.String(),
+		)
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("run command in submodule '%s': %w", s.Path, err)
+		}
+	}
+	return nil
+}
+
+// list returns the status of the gitlinks in the index that paths select,
+// sorted by path, with their .gitmodules sections.
+func (m *Manager) list(paths []string) ([]Status, error) {
+	specs, err := index.ParsePathspecs(paths)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := m.readIndex()
+	if err != nil {
+		return nil, err
+	}
+	subs, err := ReadGitmodules(m.root)
+	if err != nil {
+		return nil, err
+	}
+	byPath := make(map[scpath.RelativePath]Submodule)
+	for _, s := range subs {
+		byPath[s.Path] = s
+	}
+
+	statuses := make(map[scpath.RelativePath]*Status)
+	for _, entry := range idx.Entries {
+		if !entry.TreeMode().IsGitlink() || len(specs) > 0 && !specs.Match(entry.Path.String()) {
+			continue
+		}
+		st, ok := statuses[entry.Path]
+		if !ok {
+			sub, mapped := byPath[entry.Path]
+			if !mapped {
+				return nil, fmt.Errorf("%w for path '%s'", ErrNoMapping, entry.Path)
+			}
+			st = &Status{Submodule: sub}
+			st.Head, _ = refs.HeadCommit(scpath.RepositoryPath(m.root.Join(entry.Path.String()).String()))
+			statuses[entry.Path] = st
+		}
+		if entry.Stage == 0 {
+			st.Recorded = entry.BlobHash
+		}
+	}
+
+	result := make([]Status, 0, len(statuses))
+	for _, st := range statuses {
+		switch {
+		case st.Recorded == "":
+			st.Code = StatusConflict
+		case st.Head == "":
+			st.Code = StatusNotInitialized
+		case st.Head != st.Recorded:
+			st.Code = StatusModified
+		default:
+			st.Code = StatusCurrent
+		}
+		result = append(result, *st)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Path < result[j].Path })
+	return result, nil
+}
+
+func (m *Manager) readIndex() (*index.Index, error) {
+	idx, err := index.Read(m.repo.SourceDirectory().IndexPath().ToAbsolutePath())
+	if err != nil {
+		return nil, fmt.Errorf("read index: %w", err)
+	}
+	return idx, nil
+}
+
+// configuredURLs returns the URL of each submodule initialized in the
+// repository's configuration, by name.
+func (m *Manager) configuredURLs() (map[string]string, error) {
+	file, err := os.Open(m.repo.SourceDirectory().ConfigPath().String())
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+	defer file.Close()
+
+	subs, err := Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+	urls := make(map[string]string, len(subs))
+	for _, s := range subs {
+		urls[s.Name] = s.URL
+	}
+	return urls, nil
+}
+
+// configure records url as the URL of the submodule called name in the
+// repository's configuration, marking it active.
+func (m *Manager) configure(name, url string) error {
+	configPath := m.repo.SourceDirectory().ConfigPath().ToAbsolutePath()
+	err := appendSection(configPath, name, [2]string{"url", filepath.ToSlash(url)}, [2]string{"active", "true"})
+	if err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+	return nil
+}
+
+// resolveURL returns the repository a submodule URL names, which must be a
+// local path. As in Git, a URL starting with "./" or "../" is relative to
+// the superproject's remote.origin.url, or to the superproject itself if it
+// has no origin; Git would use the current branch's remote, which is origin
+// unless branch.<name>.remote says otherwise. Any other relative path is
+// relative to the root.
+func (m *Manager) resolveURL(url string) (scpath.RepositoryPath, error) {
+	if isRemoteURL(url) {
+		return "", fmt.Errorf("%w: '%s'", ErrUnsupportedURL, url)
+	}
+	p := filepath.FromSlash(url)
+	if filepath.IsAbs(p) {
+		return scpath.RepositoryPath(filepath.Clean(p)), nil
+	}
+
+	base := m.root.String()
+	if strings.HasPrefix(url, "./") || strings.HasPrefix(url, "../") {
+		origin, err := m.originURL()
+		if err != nil {
+			return "", err
+		}
+		if isRemoteURL(origin) {
+			return "", fmt.Errorf("%w: '%s' is relative to remote.origin.url '%s'", ErrUnsupportedURL, url, origin)
+		}
+		if origin != "" {
+			base = filepath.FromSlash(origin)
+			if !filepath.IsAbs(base) {
+				base = filepath.Join(m.root.String(), base)
+			}
+		}
+	}
+	return scpath.RepositoryPath(filepath.Join(base, p)), nil
+}
+
+// originURL returns remote.origin.url from the repository's configuration,
+// or "" if it is not set.
+func (m *Manager) originURL() (string, error) {
+	file, err := os.Open(m.repo.SourceDirectory().ConfigPath().String())
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("read config: %w", err)
+	}
+	defer file.Close()
+
+	entries, err := gitconfig.Parse(file)
+	if err != nil {
+		return "", fmt.Errorf("read config: %w", err)
+	}
+	url := ""
+	for _, e := range entries {
+		if e.Name() == "remote.origin.url" {
+			url = e.Value
+		}
+	}
+	return url, nil
+}
+
+// isRemoteURL reports whether url names a repository on another host.
+func isRemoteURL(url string) bool {
+	return strings.Contains(url, "://") || strings.HasPrefix(url, "git@")
+}
@@ -0,0 +1,328 @@
+package submodule
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/utkarsh5026/SourceControl/pkg/index"
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/blob"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/commit"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/tree"
+	"github.com/utkarsh5026/SourceControl/pkg/refs/branch"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/refs"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/sourcerepo"
+)
+
+func initRepo(t *testing.T) *sourcerepo.SourceRepository {
+	t.Helper()
+	repo := sourcerepo.NewSourceRepository()
+	if err := repo.Initialize(scpath.RepositoryPath(t.TempDir())); err != nil {
+		t.Fatalf("failed to initialize repo: %v", err)
+	}
+	return repo
+}
+
+// commitFile commits file.txt with the given content on master.
+func commitFile(t *testing.T, repo *sourcerepo.SourceRepository, content string, parent objects.ObjectHash) objects.ObjectHash {
+	t.Helper()
+	blobHash, err := repo.WriteObject(blob.NewBlob([]byte(content)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, err := tree.NewTreeEntry(objects.FileModeRegular, "file.txt", blobHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	treeHash, err := repo.WriteObject(tree.NewTree([]*tree.TreeEntry{entry}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	person, err := commit.NewCommitPerson("Test User", "test@example.com", time.Unix(1700000000, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	builder := commit.NewCommitBuilder().TreeHash(treeHash).Author(person).Committer(person).Message(content)
+	if parent != "" {
+		builder = builder.ParentHash(parent)
+	}
+	c, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := repo.WriteObject(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := refs.NewRefManager(repo).UpdateRef("refs/heads/master", hash); err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
+
+// setupSubmodule adds a library with two commits to a new repository as
+// the submodule libs/lib, and returns the commits.
+func setupSubmodule(t *testing.T) (*Manager, *sourcerepo.SourceRepository, [2]objects.ObjectHash) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", t.TempDir())
+
+	lib := initRepo(t)
+	var commits [2]objects.ObjectHash
+	commits[0] = commitFile(t, lib, "v1", "")
+	commits[1] = commitFile(t, lib, "v2", commits[0])
+
+	super := initRepo(t)
+	m := NewManager(super)
+	sub, err := m.Add(context.Background(), lib.WorkingDirectory().String(), "libs/lib")
+	if err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+	if sub.Name != "libs/lib" || sub.Path != "libs/lib" {
+		t.Errorf("Add() = %+v, want libs/lib", sub)
+	}
+	return m, super, commits
+}
+
+func assertStatus(t *testing.T, m *Manager, code byte, head objects.ObjectHash) {
+	t.Helper()
+	statuses, err := m.Status(nil)
+	if err != nil {
+		t.Fatalf("Status() failed: %v", err)
+	}
+	if len(statuses) != 1 || statuses[0].Code != code || statuses[0].Head != head {
+		t.Errorf("Status() = %+v, want %q at %s", statuses, code, head)
+	}
+}
+
+func TestManager_Add(t *testing.T) {
+	m, super, commits := setupSubmodule(t)
+	root := super.WorkingDirectory()
+
+	idx, err := index.Read(super.SourceDirectory().IndexPath().ToAbsolutePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, ok := idx.Get("libs/lib")
+	if !ok || !entry.TreeMode().IsGitlink() || entry.BlobHash != commits[1] {
+		t.Fatalf("libs/lib staged as %v, want a gitlink to %s", entry, commits[1].Short())
+	}
+	if !idx.Has(GitmodulesFile) || idx.Has("libs/lib/file.txt") {
+		t.Error("want .gitmodules staged, and the submodule's files not")
+	}
+	if data, err := os.ReadFile(root.Join("libs", "lib", "file.txt").String()); err != nil || string(data) != "v2" {
+		t.Errorf("libs/lib/file.txt = %q, %v; want the submodule checked out", data, err)
+	}
+	assertStatus(t, m, StatusCurrent, commits[1])
+
+	urls, err := m.configuredURLs()
+	if err != nil || urls["libs/lib"] == "" {
+		t.Errorf("config URLs = %v, %v; want libs/lib initialized", urls, err)
+	}
+
+	_, err = m.Add(context.Background(), urls["libs/lib"], "libs/lib")
+	if !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("Add() of an existing path = %v, want ErrAlreadyExists", err)
+	}
+	if _, err := m.Add(context.Background(), "https://example.com/lib.git", "web"); !errors.Is(err, ErrUnsupportedURL) {
+		t.Errorf("Add() of a remote URL = %v, want ErrUnsupportedURL", err)
+	}
+}
+
+func TestManager_AddRelativeURL(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", t.TempDir())
+
+	// As in Git, ../ is resolved against the superproject's origin
+	base := t.TempDir()
+	lib := sourcerepo.NewSourceRepository()
+	if err := lib.Initialize(scpath.RepositoryPath(filepath.Join(base, "libs", "lib"))); err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, lib, "v1", "")
+
+	super := initRepo(t)
+	configPath := super.SourceDirectory().ConfigPath().String()
+	config, err := os.OpenFile(configPath, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = config.WriteString("[remote \"origin\"]\n\turl = " + filepath.ToSlash(filepath.Join(base, "super.git")) + "\n")
+	config.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewManager(super)
+	if _, err := m.Add(context.Background(), "../libs/lib", "vendor/lib"); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+	if data, err := os.ReadFile(super.WorkingDirectory().Join("vendor", "lib", "file.txt").String()); err != nil || string(data) != "v1" {
+		t.Errorf("vendor/lib/file.txt = %q, %v; want the library cloned", data, err)
+	}
+	subs, err := ReadGitmodules(super.WorkingDirectory())
+	if err != nil || len(subs) != 1 || subs[0].URL != "../libs/lib" {
+		t.Errorf(".gitmodules = %+v, %v; want the URL as given", subs, err)
+	}
+}
+
+func TestManager_AddRollsBack(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", t.TempDir())
+	lib := initRepo(t)
+	commitFile(t, lib, "v1", "")
+	super := initRepo(t)
+	m := NewManager(super)
+
+	configPath := super.SourceDirectory().ConfigPath().String()
+	config, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Staging fails while another process holds the index lock
+	lockPath := super.SourceDirectory().IndexPath().String() + index.LockSuffix
+	if err := os.WriteFile(lockPath, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Add(context.Background(), lib.WorkingDirectory().String(), "lib"); !errors.Is(err, index.ErrIndexLocked) {
+		t.Fatalf("Add() with the index locked = %v, want ErrIndexLocked", err)
+	}
+	if _, err := os.Stat(filepath.Join(super.WorkingDirectory().String(), GitmodulesFile)); !os.IsNotExist(err) {
+		t.Errorf("failed Add() left %s behind (%v)", GitmodulesFile, err)
+	}
+	if after, _ := os.ReadFile(configPath); !bytes.Equal(after, config) {
+		t.Errorf("failed Add() changed the config to %q", after)
+	}
+
+	if err := os.Remove(lockPath); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Add(context.Background(), lib.WorkingDirectory().String(), "lib"); err != nil {
+		t.Fatalf("retried Add() failed: %v", err)
+	}
+	if urls, err := m.configuredURLs(); err != nil || len(urls) != 1 {
+		t.Errorf("config URLs = %v, %v; want lib once", urls, err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(super.WorkingDirectory().String(), GitmodulesFile)); strings.Count(string(data), "[submodule") != 1 {
+		t.Errorf(".gitmodules = %q, want one section", data)
+	}
+}
+
+func TestManager_StatusAndUpdate(t *testing.T) {
+	m, super, commits := setupSubmodule(t)
+	subPath := scpath.RepositoryPath(super.WorkingDirectory().Join("libs", "lib").String())
+	subRepo, err := sourcerepo.Open(subPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Checking out another commit in the submodule shows as new commits
+	if err := branch.NewManager(subRepo).Checkout(context.Background(), commits[0].String(), branch.WithDetach()); err != nil {
+		t.Fatal(err)
+	}
+	assertStatus(t, m, StatusModified, commits[0])
+	indexMgr := index.NewManager(super.WorkingDirectory())
+	if err := indexMgr.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	result, err := indexMgr.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Entries) != 2 || result.Entries[1].Path != "libs/lib" || result.Entries[1].Unstaged != index.StatusModified {
+		t.Errorf("Status() = %+v, want libs/lib modified in the working tree", result.Entries)
+	}
+
+	updated, err := m.Update(context.Background(), nil, false)
+	if err != nil {
+		t.Fatalf("Update() failed: %v", err)
+	}
+	if len(updated) != 1 || updated[0].Commit != commits[1] || updated[0].Cloned {
+		t.Errorf("Update() = %+v, want libs/lib checked out at %s", updated, commits[1].Short())
+	}
+	assertStatus(t, m, StatusCurrent, commits[1])
+
+	// A submodule that was never cloned is cloned
+	if err := os.RemoveAll(subPath.String()); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(subPath.String(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	assertStatus(t, m, StatusNotInitialized, "")
+	if updated, err = m.Update(context.Background(), []string{"libs"}, false); err != nil {
+		t.Fatalf("Update() failed: %v", err)
+	}
+	if len(updated) != 1 || !updated[0].Cloned {
+		t.Errorf("Update() = %+v, want libs/lib cloned", updated)
+	}
+	assertStatus(t, m, StatusCurrent, commits[1])
+	head, err := os.ReadFile(subPath.SourcePath().HeadPath().String())
+	if err != nil || strings.TrimSpace(string(head)) != commits[1].String() {
+		t.Errorf("submodule HEAD = %q, want detached at %s", head, commits[1])
+	}
+}
+
+func TestManager_Init(t *testing.T) {
+	m, super, _ := setupSubmodule(t)
+
+	// Drop the submodule's configuration, as a fresh clone would lack it
+	configPath := super.SourceDirectory().ConfigPath().String()
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before, _, _ := strings.Cut(string(data), "[submodule")
+	if err := os.WriteFile(configPath, []byte(before), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	initialized, err := m.Init(nil)
+	if err != nil {
+		t.Fatalf("Init() failed: %v", err)
+	}
+	if len(initialized) != 1 || initialized[0].Name != "libs/lib" {
+		t.Errorf("Init() = %+v, want libs/lib", initialized)
+	}
+	if initialized, err = m.Init(nil); err != nil || len(initialized) != 0 {
+		t.Errorf("second Init() = %+v, %v; want nothing to do", initialized, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(super.WorkingDirectory().String(), GitmodulesFile), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Status(nil); !errors.Is(err, ErrNoMapping) {
+		t.Errorf("Status() without .gitmodules = %v, want ErrNoMapping", err)
+	}
+}
+
+func TestManager_Foreach(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no shell")
+	}
+	m, super, commits := setupSubmodule(t)
+
+	var stdout bytes.Buffer
+	err := m.Foreach(context.Background(), `echo "$name $sm_path $sha1 $toplevel"; cat file.txt`, ForeachOptions{Stdout: &stdout})
+	if err != nil {
+		t.Fatalf("Foreach() failed: %v", err)
+	}
+	want := "Entering 'libs/lib'\nlibs/lib libs/lib " + commits[1].String() + " " + super.WorkingDirectory().String() + "\nv2"
+	if stdout.String() != want {
+		t.Errorf("Foreach() output = %q, want %q", stdout.String(), want)
+	}
+
+	if err := m.Foreach(context.Background(), "exit 3", ForeachOptions{}); err == nil || !strings.Contains(err.Error(), "libs/lib") {
+		t.Errorf("Foreach() of a failing command = %v, want an error naming libs/lib", err)
+	}
+}
@@ -18,6 +18,8 @@ const (
 	CodeValidationErr  = err.CodeValidation
 	CodeTransactionErr = err.CodeTransaction
 	CodeIndexErr       = "INDEX_ERROR"
+	CodeNoMatch        = "PATHSPEC_NO_MATCH"
+	CodeUnmerged       = "UNMERGED_PATH"
 )
 
 // Common error variables for type checking with errors.Is()
@@ -28,6 +30,10 @@ var (
 	ErrInvalidOperation = internal.ErrInvalidOperation
 	// ErrLockAcquisitionFailed is returned when unable to acquire repository lock
 	ErrLockAcquisitionFailed = internal.ErrLockAcquisitionFailed
+	// ErrPathspecNoMatch is returned when a pathspec selects no file known to the index or a tree
+	ErrPathspecNoMatch = err.New(pkgName, CodeNoMatch, "", "pathspec did not match any file(s) known to srcc", nil)
+	// ErrUnmergedPath is returned when a path that has conflict stages cannot be restored from the index
+	ErrUnmergedPath = err.New(pkgName, CodeUnmerged, "", "path is unmerged", nil)
 )
 
 // WorkdirError represents an error that occurred during working directory operations.
@@ -55,6 +55,11 @@ func (a *Analyzer) GetCommitFiles(ctx context.Context, commitSHA objects.ObjectH
 	return a.getTreeFiles(ctx, c.TreeSHA, scpath.RelativePath(""))
 }
 
+// GetTreeFiles retrieves all files from a tree and its subtrees.
+func (a *Analyzer) GetTreeFiles(ctx context.Context, treeSHA objects.ObjectHash) (FileMap, error) {
+	return a.getTreeFiles(ctx, treeSHA, scpath.RelativePath(""))
+}
+
 // getTreeFiles recursively walks a tree object and collects all files.
 // It handles nested trees (subdirectories) and builds the complete file map.
 func (a *Analyzer) getTreeFiles(ctx context.Context, treeSHA objects.ObjectHash, basePath scpath.RelativePath) (map[scpath.RelativePath]FileInfo, error) {
@@ -287,9 +292,10 @@ func (a *Analyzer) AreTreesIdentical(ctx context.Context, treeSHA1, treeSHA2 obj
 }
 
 // isSupportedFileType checks if a tree entry represents a supported file type.
-// We support regular files, executables, and symlinks (but not submodules for now).
+// We support regular files, executables, symlinks and submodules, whose
+// directories are created but never checked out.
 func (a *Analyzer) isSupportedFileType(entry *tree.TreeEntry) bool {
-	return entry.IsFile() || entry.IsExecutable() || entry.IsSymbolicLink()
+	return entry.IsFile() || entry.IsExecutable() || entry.IsSymbolicLink() || entry.IsSubmodule()
 }
 
 // hasChanged checks if a file has changed between two states.
@@ -8,18 +8,21 @@ import (
 	"strings"
 
 	"github.com/utkarsh5026/SourceControl/pkg/common/fileops"
+	"github.com/utkarsh5026/SourceControl/pkg/index"
 	"github.com/utkarsh5026/SourceControl/pkg/objects"
 	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
 	"github.com/utkarsh5026/SourceControl/pkg/repository/sourcerepo"
+	"github.com/utkarsh5026/SourceControl/pkg/store"
 )
 
 // FileOps implements the FileOperator interface for low-level file system operations.
 // It handles creating, modifying, and deleting files in the working directory.
 type FileOps struct {
-	repo    *sourcerepo.SourceRepository
-	workDir scpath.RepositoryPath
-	tempDir scpath.AbsolutePath // Directory for temporary files
-	dryRun  bool                // If true, operations are simulated
+	repo     *sourcerepo.SourceRepository
+	workDir  scpath.RepositoryPath
+	tempDir  scpath.AbsolutePath // Directory for temporary files
+	dryRun   bool                // If true, operations are simulated
+	symlinks bool                // core.symlinks: write symbolic links as links
 }
 
 // NewFileOps creates a new FileOps service
@@ -27,10 +30,11 @@ func NewFileOps(repo *sourcerepo.SourceRepository) *FileOps {
 	workDir := repo.WorkingDirectory()
 	tempDir := workDir.Join(scpath.SourceDir, "tmp")
 	return &FileOps{
-		repo:    repo,
-		workDir: workDir,
-		tempDir: tempDir,
-		dryRun:  false,
+		repo:     repo,
+		workDir:  workDir,
+		tempDir:  tempDir,
+		dryRun:   false,
+		symlinks: index.LoadModeSettings(workDir).Symlinks,
 	}
 }
 
@@ -57,11 +61,17 @@ func (f *FileOps) ApplyOperation(op Operation) error {
 }
 
 // writeFile creates or modifies a file by reading content from a blob object.
-// Uses atomic write pattern: write to temp file, then rename.
+// Uses atomic write pattern: write to temp file, then rename. Blobs larger
+// than store.BlobStreamThreshold are streamed from the object store instead
+// of being loaded into memory.
+//
+// A symbolic link is created as a link to the path its blob holds, unless
+// core.symlinks is false; then, as in Git, it is written as a plain file
+// holding that path. Files are executable only if their mode is 100755.
+// A submodule is not checked out: only its empty directory is created.
 func (f *FileOps) writeFile(op Operation) error {
-	content, err := f.readBlobContent(op)
-	if err != nil {
-		return fmt.Errorf("%s %s: get blob content: %w", op.Action.String(), op.Path, err)
+	if op.SHA == "" {
+		return fmt.Errorf("%s %s: %w: missing SHA", op.Action.String(), op.Path, ErrInvalidOperation)
 	}
 
 	fullPath := f.workDir.Join(op.Path.String())
@@ -69,29 +79,50 @@ func (f *FileOps) writeFile(op Operation) error {
 		return fmt.Errorf("%s %s: create parent directory: %w", op.Action.String(), op.Path, err)
 	}
 
-	if err := f.atomicWrite(fullPath, content, op.Mode.ToOSFileMode()); err != nil {
-		return fmt.Errorf("%s %s: write file: %w", op.Action.String(), op.Path, err)
+	// A file and a submodule directory replace each other; a directory
+	// is only removed if empty
+	if info, err := os.Lstat(fullPath.String()); err == nil && info.IsDir() != op.Mode.IsGitlink() {
+		if err := fileops.SafeRemove(fullPath); err != nil {
+			return fmt.Errorf("%s %s: %w", op.Action.String(), op.Path, err)
+		}
+	}
+	if op.Mode.IsGitlink() {
+		if err := fileops.EnsureDir(fullPath); err != nil {
+			return fmt.Errorf("%s %s: create submodule directory: %w", op.Action.String(), op.Path, err)
+		}
+		return nil
 	}
 
-	return nil
-}
+	content, size, err := f.repo.ObjectStore().OpenBlob(op.SHA)
+	if err != nil {
+		return fmt.Errorf("%s %s: open blob %s: %w", op.Action.String(), op.Path, op.SHA.Short(), err)
+	}
+	defer content.Close()
 
-func (f *FileOps) readBlobContent(op Operation) ([]byte, error) {
-	if op.SHA == "" {
-		return nil, fmt.Errorf("%s %s: %w: missing SHA", op.Action.String(), op.Path, ErrInvalidOperation)
+	if size > store.BlobStreamThreshold {
+		if err := f.atomicWriteFrom(fullPath, content, op.Mode.ToOSFileMode().Perm()); err != nil {
+			return fmt.Errorf("%s %s: write file: %w", op.Action.String(), op.Path, err)
+		}
+		return nil
 	}
 
-	blob, err := f.repo.ReadBlobObject(op.SHA)
+	data, err := io.ReadAll(content)
 	if err != nil {
-		return nil, fmt.Errorf("%s %s: object %s is not a blob", op.Action.String(), op.Path, op.SHA.Short())
+		return fmt.Errorf("%s %s: get blob content: %w", op.Action.String(), op.Path, err)
 	}
 
-	content, err := blob.Content()
-	if err != nil {
-		return nil, fmt.Errorf("%s %s: get blob content: %w", op.Action.String(), op.Path, err)
+	if op.Mode.IsSymlink() && f.symlinks {
+		if err := fileops.AtomicSymlink(fullPath, filepath.FromSlash(string(data))); err != nil {
+			return fmt.Errorf("%s %s: create symlink: %w", op.Action.String(), op.Path, err)
+		}
+		return nil
+	}
+
+	if err := f.atomicWrite(fullPath, data, op.Mode.ToOSFileMode().Perm()); err != nil {
+		return fmt.Errorf("%s %s: write file: %w", op.Action.String(), op.Path, err)
 	}
 
-	return content.Bytes(), nil
+	return nil
 }
 
 // atomicWrite writes data to a file atomically by using a temporary file and rename.
@@ -104,15 +135,34 @@ func (f *FileOps) atomicWrite(targetPath scpath.AbsolutePath, data []byte, mode
 	return fileops.AtomicWrite(targetPath, data, mode)
 }
 
-// deleteFile removes a file from the working directory and cleans up empty parent directories
+// atomicWriteFrom is atomicWrite for content streamed from a reader.
+func (f *FileOps) atomicWriteFrom(targetPath scpath.AbsolutePath, r io.Reader, mode os.FileMode) error {
+	if err := f.ensureTempDir(); err != nil {
+		return err
+	}
+
+	return fileops.AtomicWriteFrom(targetPath, r, mode)
+}
+
+// deleteFile removes a file from the working directory and cleans up empty parent directories.
+// The directory of a submodule is only removed if it is empty, so that a
+// checked out submodule is never lost.
 func (f *FileOps) deleteFile(path scpath.RelativePath) error {
 	fullPath := f.workDir.Join(path.String())
 
-	exists, err := fileops.Exists(fullPath)
+	// Lstat, so that a symbolic link to a missing file is still removed
+	info, err := os.Lstat(fullPath.String())
 	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
 		return fmt.Errorf("delete %s: check existence: %w", path, err)
 	}
-	if !exists {
+
+	if info.IsDir() {
+		if err := os.Remove(fullPath.String()); err == nil {
+			_ = f.cleanEmptyParents(fullPath.Dir())
+		}
 		return nil
 	}
 
@@ -159,10 +209,12 @@ func (f *FileOps) cleanEmptyParents(dir scpath.AbsolutePath) error {
 
 // CreateBackup creates a backup of a file before modification.
 // Returns a Backup struct that can be used to restore the file later.
+// A symbolic link is backed up as the path it points to, and the directory
+// of a submodule as a gitlink, with no content.
 func (f *FileOps) CreateBackup(path scpath.RelativePath) (*Backup, error) {
 	fullPath := f.workDir.Join(path.String())
 
-	info, err := os.Stat(fullPath.String())
+	info, err := os.Lstat(fullPath.String())
 	if os.IsNotExist(err) {
 		return &Backup{
 			Path:     path,
@@ -174,6 +226,13 @@ func (f *FileOps) CreateBackup(path scpath.RelativePath) (*Backup, error) {
 	if err != nil {
 		return nil, fmt.Errorf("backup %s: stat file: %w", path, err)
 	}
+	if info.IsDir() {
+		return &Backup{
+			Path:    path,
+			Existed: true,
+			Mode:    objects.FileModeGitlink,
+		}, nil
+	}
 
 	tmpFile, err := f.createTempBackupFile("backup-*")
 	if err != nil {
@@ -188,7 +247,7 @@ func (f *FileOps) CreateBackup(path scpath.RelativePath) (*Backup, error) {
 		}
 	}()
 
-	if err := writeToTemp(tmpFile, path, fullPath); err != nil {
+	if err := writeToTemp(tmpFile, path, fullPath, info); err != nil {
 		return nil, err
 	}
 
@@ -218,7 +277,18 @@ func (f *FileOps) ensureTempDir() error {
 	return fileops.EnsureDir(f.tempDir)
 }
 
-func writeToTemp(tmpFile *os.File, path scpath.RelativePath, fullPath scpath.AbsolutePath) error {
+func writeToTemp(tmpFile *os.File, path scpath.RelativePath, fullPath scpath.AbsolutePath, info os.FileInfo) error {
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(fullPath.String())
+		if err != nil {
+			return fmt.Errorf("backup %s: read link: %w", path, err)
+		}
+		if _, err := tmpFile.WriteString(target); err != nil {
+			return fmt.Errorf("backup %s: copy link: %w", path, err)
+		}
+		return nil
+	}
+
 	srcFile, err := os.Open(fullPath.String())
 	if err != nil {
 		return fmt.Errorf("backup %s: open source: %w", path, err)
@@ -251,6 +321,13 @@ func (f *FileOps) RestoreBackup(backup *Backup) error {
 		return nil
 	}
 
+	if backup.Mode.IsGitlink() {
+		if err := fileops.EnsureDir(fullPath); err != nil {
+			return fmt.Errorf("restore %s: %w", backup.Path, err)
+		}
+		return nil
+	}
+
 	if backup.TempFile == "" {
 		return fmt.Errorf("restore %s: backup has no temp file", backup.Path)
 	}
@@ -275,7 +352,14 @@ func (f *FileOps) writeFromBackup(backup *Backup) error {
 		return fmt.Errorf("restore %s: read backup: %w", backup.Path, err)
 	}
 
-	if err := f.atomicWrite(backupPath, data, backup.Mode.ToOSFileMode()); err != nil {
+	if backup.Mode.IsSymlink() {
+		if err := fileops.AtomicSymlink(backupPath, string(data)); err != nil {
+			return fmt.Errorf("restore %s: create symlink: %w", backup.Path, err)
+		}
+		return nil
+	}
+
+	if err := f.atomicWrite(backupPath, data, backup.Mode.ToOSFileMode().Perm()); err != nil {
 		return fmt.Errorf("restore %s: write file: %w", backup.Path, err)
 	}
 
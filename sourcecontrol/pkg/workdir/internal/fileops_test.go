@@ -1,15 +1,18 @@
 package internal
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
 
 	"github.com/utkarsh5026/SourceControl/pkg/objects"
 	"github.com/utkarsh5026/SourceControl/pkg/objects/blob"
 	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
 	"github.com/utkarsh5026/SourceControl/pkg/repository/sourcerepo"
+	"github.com/utkarsh5026/SourceControl/pkg/store"
 )
 
 // setupTestRepo creates a temporary repository for testing
@@ -550,6 +553,36 @@ func TestFileOps_LargeFiles(t *testing.T) {
 	}
 }
 
+// TestFileOps_StreamedBlob tests checking out a blob above the streaming threshold
+func TestFileOps_StreamedBlob(t *testing.T) {
+	repo, workDir := setupTestRepo(t)
+	service := NewFileOps(repo)
+
+	content := bytes.Repeat([]byte("0123456789abcdef"), int(store.BlobStreamThreshold/16)+256)
+	blobSHA, err := repo.ObjectStore().WriteBlobStream(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatalf("Failed to write blob: %v", err)
+	}
+
+	err = service.ApplyOperation(Operation{
+		Path:   scpath.RelativePath("assets/huge.bin"),
+		Action: ActionCreate,
+		SHA:    blobSHA,
+		Mode:   0644,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create streamed file: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(workDir, "assets", "huge.bin"))
+	if err != nil {
+		t.Fatalf("Failed to read streamed file: %v", err)
+	}
+	if !bytes.Equal(data, content) {
+		t.Errorf("Streamed file content differs (got %d bytes, want %d)", len(data), len(content))
+	}
+}
+
 // TestFileOps_SpecialCharacters tests files with special characters in names
 func TestFileOps_SpecialCharacters(t *testing.T) {
 	repo, workDir := setupTestRepo(t)
@@ -796,6 +829,122 @@ func TestFileOps_PermissionPreservation(t *testing.T) {
 	}
 }
 
+// TestFileOps_Symlinks tests that symbolic links are checked out and backed
+// up as links, or as plain files without core.symlinks
+func TestFileOps_Symlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping on Windows - creating symbolic links needs extra privileges")
+	}
+	repo, workDir := setupTestRepo(t)
+	service := NewFileOps(repo)
+	linkSHA := createTestBlob(t, repo, "dir/target.txt")
+	linkPath := filepath.Join(workDir, "link")
+
+	op := Operation{Path: "link", Action: ActionCreate, SHA: linkSHA, Mode: objects.FileModeSymlink}
+	if err := service.ApplyOperation(op); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	if target, err := os.Readlink(linkPath); err != nil || target != filepath.FromSlash("dir/target.txt") {
+		t.Fatalf("Readlink() = %q, %v; want dir/target.txt", target, err)
+	}
+
+	// A dangling link is backed up and restored as a link
+	backup, err := service.CreateBackup("link")
+	if err != nil {
+		t.Fatalf("CreateBackup() failed: %v", err)
+	}
+	defer service.CleanupBackup(backup)
+	if backup.Mode != objects.FileModeSymlink {
+		t.Errorf("Backup mode = %v, want symlink", backup.Mode)
+	}
+	fileSHA := createTestBlob(t, repo, "now a file")
+	if err := service.ApplyOperation(Operation{Path: "link", Action: ActionModify, SHA: fileSHA, Mode: objects.FileModeExecutable}); err != nil {
+		t.Fatalf("Failed to replace symlink: %v", err)
+	}
+	if info, err := os.Lstat(linkPath); err != nil || !info.Mode().IsRegular() || info.Mode()&0100 == 0 {
+		t.Fatalf("Lstat() = %v, %v; want an executable regular file", info.Mode(), err)
+	}
+	if err := service.RestoreBackup(backup); err != nil {
+		t.Fatalf("RestoreBackup() failed: %v", err)
+	}
+	if target, err := os.Readlink(linkPath); err != nil || target != filepath.FromSlash("dir/target.txt") {
+		t.Errorf("Readlink() after restore = %q, %v; want dir/target.txt", target, err)
+	}
+
+	// Deleting a dangling link removes it
+	if err := service.ApplyOperation(Operation{Path: "link", Action: ActionDelete}); err != nil {
+		t.Fatalf("Failed to delete symlink: %v", err)
+	}
+	if _, err := os.Lstat(linkPath); !os.IsNotExist(err) {
+		t.Errorf("Lstat() after delete = %v, want not exist", err)
+	}
+
+	// Without core.symlinks the link is a plain file holding its target
+	service.symlinks = false
+	if err := service.ApplyOperation(op); err != nil {
+		t.Fatalf("Failed to create symlink as a file: %v", err)
+	}
+	info, err := os.Lstat(linkPath)
+	if err != nil || !info.Mode().IsRegular() {
+		t.Fatalf("Lstat() = %v, %v; want a regular file", info, err)
+	}
+	if data, _ := os.ReadFile(linkPath); string(data) != "dir/target.txt" {
+		t.Errorf("Content = %q, want %q", data, "dir/target.txt")
+	}
+}
+
+func TestFileOps_Gitlinks(t *testing.T) {
+	repo, workDir := setupTestRepo(t)
+	service := NewFileOps(repo)
+	commitSHA := createTestBlob(t, repo, "stands in for a commit")
+	subPath := filepath.Join(workDir, "libs", "sub")
+
+	// Only the submodule's directory is created
+	op := Operation{Path: "libs/sub", Action: ActionCreate, SHA: commitSHA, Mode: objects.FileModeGitlink}
+	if err := service.ApplyOperation(op); err != nil {
+		t.Fatalf("Failed to create submodule directory: %v", err)
+	}
+	if entries, err := os.ReadDir(subPath); err != nil || len(entries) != 0 {
+		t.Fatalf("ReadDir() = %v, %v; want an empty directory", entries, err)
+	}
+
+	backup, err := service.CreateBackup("libs/sub")
+	if err != nil {
+		t.Fatalf("CreateBackup() failed: %v", err)
+	}
+	if !backup.Existed || backup.Mode != objects.FileModeGitlink || backup.TempFile != "" {
+		t.Errorf("Backup = %+v, want a gitlink without content", backup)
+	}
+
+	// A checked out submodule is never deleted
+	if err := os.WriteFile(filepath.Join(subPath, "file.txt"), []byte("content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.ApplyOperation(Operation{Path: "libs/sub", Action: ActionDelete}); err != nil {
+		t.Fatalf("Failed to delete submodule: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(subPath, "file.txt")); err != nil {
+		t.Errorf("Stat() after delete = %v, want the submodule kept", err)
+	}
+
+	// An empty one is, with its empty parents
+	if err := os.Remove(filepath.Join(subPath, "file.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.ApplyOperation(Operation{Path: "libs/sub", Action: ActionDelete}); err != nil {
+		t.Fatalf("Failed to delete submodule: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(workDir, "libs")); !os.IsNotExist(err) {
+		t.Errorf("Stat() after delete = %v, want libs removed", err)
+	}
+	if err := service.RestoreBackup(backup); err != nil {
+		t.Fatalf("RestoreBackup() failed: %v", err)
+	}
+	if info, err := os.Stat(subPath); err != nil || !info.IsDir() {
+		t.Errorf("Stat() after restore = %v, want the directory back", err)
+	}
+}
+
 // TestFileOps_BackupAndRestore tests backup and restore with edge cases
 func TestFileOps_BackupAndRestore(t *testing.T) {
 	repo, workDir := setupTestRepo(t)
@@ -8,6 +8,7 @@ import (
 
 	pool "github.com/utkarsh5026/SourceControl/pkg/common/concurrency"
 	"github.com/utkarsh5026/SourceControl/pkg/index"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/repoformat"
 	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
 )
 
@@ -27,8 +28,22 @@ func NewUpdater(workDir string, indexPath scpath.AbsolutePath) *IndexUpdater {
 
 // UpdateToMatch replaces the entire index to match the target files.
 // This is used after checking out a commit or branch.
+// The new index is written through index.lock, as every index write is.
 // Uses concurrent processing to create index entries for better performance.
 func (u *IndexUpdater) UpdateToMatch(targetFiles map[scpath.RelativePath]FileInfo) (IndexUpdateResult, error) {
+	return u.updateToMatch(targetFiles, func(idx *index.Index) error {
+		return idx.Write(u.indexPath)
+	})
+}
+
+// UpdateToMatchLocked is UpdateToMatch for a caller that already holds the
+// index lock, which the new index is committed through.
+func (u *IndexUpdater) UpdateToMatchLocked(lock *index.Lock, targetFiles map[scpath.RelativePath]FileInfo) (IndexUpdateResult, error) {
+	return u.updateToMatch(targetFiles, lock.Commit)
+}
+
+// updateToMatch builds the index for the target files and hands it to write.
+func (u *IndexUpdater) updateToMatch(targetFiles map[scpath.RelativePath]FileInfo, write func(idx *index.Index) error) (IndexUpdateResult, error) {
 	result := IndexUpdateResult{
 		Success:        true,
 		EntriesUpdated: 0,
@@ -36,17 +51,14 @@ func (u *IndexUpdater) UpdateToMatch(targetFiles map[scpath.RelativePath]FileInf
 		Errors:         []error{},
 	}
 
-	if len(targetFiles) == 0 {
-		newIndex := index.NewIndex()
-		if err := newIndex.Write(u.indexPath); err != nil {
-			result.Success = false
-			result.Errors = append(result.Errors, fmt.Errorf("index %s failed (%s): %w", "write", u.indexPath.String(), err))
-			return result, err
-		}
-		return result, nil
+	algorithm, err := repoformat.ReadObjectFormat(scpath.SourcePath(u.indexPath.Dir()))
+	if err != nil {
+		result.Success = false
+		result.Errors = append(result.Errors, err)
+		return result, err
 	}
 
-	newIndex := index.NewIndex()
+	newIndex := index.NewIndexWithAlgorithm(algorithm)
 
 	// Create entries concurrently
 	entries, errors := u.createEntries(targetFiles)
@@ -64,7 +76,7 @@ func (u *IndexUpdater) UpdateToMatch(targetFiles map[scpath.RelativePath]FileInf
 	}
 
 	if result.Success {
-		if err := newIndex.Write(u.indexPath); err != nil {
+		if err := write(newIndex); err != nil {
 			result.Success = false
 			result.Errors = append(result.Errors, fmt.Errorf("index %s failed (%s): %w", "write", u.indexPath.String(), err))
 			return result, err
@@ -128,6 +140,8 @@ func (u *IndexUpdater) createEntries(targetFiles map[scpath.RelativePath]FileInf
 
 // UpdateIncremental applies specific additions and removals to the existing index.
 // This is more efficient than replacing the entire index.
+// The index is locked from the time it is read until the result is written,
+// so changes another process makes meanwhile are not lost.
 // Uses concurrent processing to create new index entries for better performance.
 func (u *IndexUpdater) UpdateIncremental(toAdd FileMap, toRemove []scpath.RelativePath) (IndexUpdateResult, error) {
 	result := IndexUpdateResult{
@@ -137,6 +151,12 @@ func (u *IndexUpdater) UpdateIncremental(toAdd FileMap, toRemove []scpath.Relati
 		Errors:         []error{},
 	}
 
+	lock, err := index.LockIndex(u.indexPath)
+	if err != nil {
+		return result, fmt.Errorf("index %s failed (%s): %w", "lock", u.indexPath.String(), err)
+	}
+	defer lock.Rollback()
+
 	idx, err := index.Read(u.indexPath)
 	if err != nil {
 		return result, fmt.Errorf("index %s failed (%s): %w", "read", u.indexPath.String(), err)
@@ -163,7 +183,7 @@ func (u *IndexUpdater) UpdateIncremental(toAdd FileMap, toRemove []scpath.Relati
 	}
 
 	if result.Success {
-		if err := idx.Write(u.indexPath); err != nil {
+		if err := lock.Commit(idx); err != nil {
 			result.Success = false
 			result.Errors = append(result.Errors, fmt.Errorf("index %s failed (%s): %w", "write", u.indexPath.String(), err))
 			return result, err
@@ -175,10 +195,19 @@ func (u *IndexUpdater) UpdateIncremental(toAdd FileMap, toRemove []scpath.Relati
 
 // createIndexEntry creates an index entry from file information.
 // It stats the file to get metadata and combines it with the provided SHA and mode.
+// The mode comes from the tree, since the file may have been written without
+// its executable bit or as a plain file in place of a symbolic link.
+// A submodule has no stat data, since its directory is not a file.
 func (u *IndexUpdater) createIndexEntry(path scpath.RelativePath, info FileInfo) (*index.Entry, error) {
+	if info.Mode.IsGitlink() {
+		entry := index.NewEntry(path)
+		entry.Mode, entry.BlobHash = info.Mode, info.SHA
+		return entry, nil
+	}
+
 	fullPath := filepath.Join(u.workDir, path.String())
 
-	stats, err := os.Stat(fullPath)
+	stats, err := os.Lstat(fullPath)
 	if err != nil {
 		return nil, fmt.Errorf("stat file: %w", err)
 	}
@@ -187,6 +216,7 @@ func (u *IndexUpdater) createIndexEntry(path scpath.RelativePath, info FileInfo)
 	if err != nil {
 		return nil, fmt.Errorf("create entry: %w", err)
 	}
+	entry.Mode = info.Mode
 
 	return entry, nil
 }
@@ -687,6 +687,23 @@ func TestCreateIndexEntry_Success(t *testing.T) {
 	}
 }
 
+func TestCreateIndexEntry_ModeFromTree(t *testing.T) {
+	updater, workDir, _ := createTestIndexUpdater(t)
+
+	// A symbolic link checked out as a plain file, as with core.symlinks off
+	filePath := createTestFile(t, workDir, "link", "target.txt")
+	fileInfo := createFileInfo("abc123")
+	fileInfo.Mode = objects.FileModeSymlink
+
+	entry, err := updater.createIndexEntry(filePath, fileInfo)
+	if err != nil {
+		t.Fatalf("createIndexEntry failed: %v", err)
+	}
+	if entry.Mode != objects.FileModeSymlink {
+		t.Errorf("expected mode %s, got %s", objects.FileModeSymlink.ToOctalString(), entry.Mode.ToOctalString())
+	}
+}
+
 func TestCreateIndexEntry_FileNotFound(t *testing.T) {
 	updater, _, _ := createTestIndexUpdater(t)
 
@@ -2,52 +2,37 @@ package internal
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
 
+	"github.com/utkarsh5026/SourceControl/pkg/index"
 	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
 )
 
 // LockFile represents a file-based lock for repository operations.
-// It prevents concurrent modifications to the working directory.
+// It prevents concurrent modifications to the working directory, and is the
+// same index.lock that index writers take, so nothing can change the index
+// while files are being updated.
 type LockFile struct {
-	path string
-	file *os.File
+	lock *index.Lock
 }
 
 // AcquireLock attempts to acquire an exclusive lock on the index.
 // Returns an error if another process already holds the lock.
 func AcquireLock(sourceDir scpath.SourcePath) (*LockFile, error) {
-	lockPath := filepath.Join(sourceDir.String(), "index.lock")
-
-	file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	lock, err := index.LockIndex(sourceDir.IndexPath().ToAbsolutePath())
 	if err != nil {
-		if os.IsExist(err) {
-			return nil, fmt.Errorf("lock error (%s): another process holds the lock: %w", lockPath, ErrLockAcquisitionFailed)
-		}
-		return nil, fmt.Errorf("lock error (%s): failed to create lock file: %w", lockPath, err)
+		return nil, fmt.Errorf("%w: %w", ErrLockAcquisitionFailed, err)
 	}
 
-	return &LockFile{
-		path: lockPath,
-		file: file,
-	}, nil
+	return &LockFile{lock: lock}, nil
 }
 
-// Release releases the lock by closing and deleting the lock file
+// Release releases the lock by closing and deleting the lock file, leaving
+// the index unchanged
 func (l *LockFile) Release() error {
-	if err := l.file.Close(); err != nil {
-		return fmt.Errorf("close lock file: %w", err)
-	}
-
-	if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("remove lock file: %w", err)
-	}
-
-	return nil
+	return l.lock.Rollback()
 }
 
 // Path returns the lock file path
 func (l *LockFile) Path() string {
-	return l.path
+	return l.lock.Path()
 }
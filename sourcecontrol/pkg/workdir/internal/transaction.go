@@ -85,6 +85,17 @@ func (m *Manager) ExecuteAtomically(ctx context.Context, ops []Operation) Transa
 	}
 	defer lock.Release()
 
+	return m.ExecuteLocked(ctx, ops)
+}
+
+// ExecuteLocked is ExecuteAtomically for a caller that already holds the
+// index lock, so that it can read the index and write it back under the same
+// lock the files are changed under.
+func (m *Manager) ExecuteLocked(ctx context.Context, ops []Operation) TransactionResult {
+	if len(ops) == 0 {
+		return success(0, 0)
+	}
+
 	if err := m.validateOperations(ops); err != nil {
 		return failure(0, len(ops), err)
 	}
@@ -187,24 +198,19 @@ func (m *Manager) validateOperations(ops []Operation) error {
 	return nil
 }
 
-// createBackups creates backups for all modify/delete operations.
+// createBackups creates backups for all operations. A file that a create
+// operation writes usually does not exist yet, and its backup only records
+// that, so that a rollback removes it again.
 // Uses concurrent processing for better performance when backing up multiple files.
 func (m *Manager) createBackups(ops []Operation) ([]*Backup, error) {
-	var needsBackup []Operation
-	for _, op := range ops {
-		if op.Action == ActionModify || op.Action == ActionDelete {
-			needsBackup = append(needsBackup, op)
-		}
-	}
-
-	if len(needsBackup) == 0 {
+	if len(ops) == 0 {
 		return nil, nil
 	}
 
 	workerPool := pool.NewWorkerPool[Operation, *Backup]()
 	backups, err := workerPool.Process(
 		context.Background(),
-		needsBackup,
+		ops,
 		func(ctx context.Context, op Operation) (*Backup, error) {
 			backup, err := m.fileOps.CreateBackup(op.Path)
 			if err != nil {
@@ -401,6 +401,27 @@ func TestManager_ExecuteAtomically_OperationFailureWithRollback(t *testing.T) {
 	}
 }
 
+func TestManager_ExecuteAtomically_RollbackRemovesCreatedFiles(t *testing.T) {
+	repo, workDir := setupTestRepo(t)
+	fileOps := NewFileOps(repo)
+	manager := NewManager(fileOps, repo.SourceDirectory())
+
+	// The create succeeds before the second operation fails
+	ops := []Operation{
+		{Path: scpath.RelativePath("created.txt"), Action: ActionCreate, SHA: createTestBlob(t, repo, "created"), Mode: 0644},
+		{Path: scpath.RelativePath("failed.txt"), Action: ActionCreate, SHA: objects.ObjectHash("invalid_sha"), Mode: 0644},
+	}
+
+	result := manager.ExecuteAtomically(context.Background(), ops)
+
+	if result.Success {
+		t.Fatal("ExecuteAtomically() should fail when operation fails")
+	}
+	if _, err := os.Stat(filepath.Join(workDir, "created.txt")); !os.IsNotExist(err) {
+		t.Error("created.txt should not exist after rollback")
+	}
+}
+
 func TestManager_ExecuteAtomically_ContextCancellation(t *testing.T) {
 	repo, workDir := setupTestRepo(t)
 	fileOps := NewFileOps(repo)
@@ -7,10 +7,11 @@ import (
 	"path/filepath"
 
 	pool "github.com/utkarsh5026/SourceControl/pkg/common/concurrency"
+	"github.com/utkarsh5026/SourceControl/pkg/config"
 	"github.com/utkarsh5026/SourceControl/pkg/index"
 	"github.com/utkarsh5026/SourceControl/pkg/objects"
-	"github.com/utkarsh5026/SourceControl/pkg/objects/blob"
 	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/sourcerepo"
 )
 
 // FileStatus represents the status of a file in the working directory
@@ -64,13 +65,19 @@ func NewFSD(p scpath.RelativePath, st FileStatus, ish, wsh objects.ObjectHash) *
 // It checks for uncommitted changes and validates safe overwrite conditions.
 type Validator struct {
 	workDir scpath.RepositoryPath
+	workers int // core.parallelism, or 0 for one worker per CPU
 }
 
 // NewValidator creates a new working directory validator
 func NewValidator(workDir scpath.RepositoryPath) *Validator {
-	return &Validator{
+	v := &Validator{
 		workDir: workDir,
 	}
+	configMgr := config.NewManager(workDir)
+	if err := configMgr.Load(context.Background()); err == nil {
+		v.workers = config.NewTypedConfig(configMgr).Parallelism()
+	}
+	return v
 }
 
 // ValidateCleanState checks if the working directory has uncommitted changes.
@@ -85,30 +92,28 @@ func (v *Validator) ValidateCleanState(idx *index.Index) (Status, error) {
 		Details:        []FileStatusDetail{},
 	}
 
-	if len(idx.Entries) > 0 {
-		wp := pool.NewWorkerPool[*index.Entry, *FileStatusDetail]()
-
-		processFn := func(ctx context.Context, entry *index.Entry) (*FileStatusDetail, error) {
-			return v.checkFileStatus(entry)
-		}
+	wp := pool.NewWorkerPool[*index.Entry, *FileStatusDetail](pool.WithWorkerCount(v.workers))
 
-		results, err := wp.Process(context.Background(), idx.Entries, processFn)
-		if err != nil {
-			return status, err
-		}
+	processFn := func(ctx context.Context, entry *index.Entry) (*FileStatusDetail, error) {
+		return v.checkFileStatus(entry)
+	}
 
-		for _, detail := range results {
-			if detail != nil {
-				status.Clean = false
-				status.Details = append(status.Details, *detail)
+	// Results arrive in index order, and only the changed files are kept
+	err := wp.ProcessOrdered(context.Background(), idx.Entries, processFn, func(_ int, detail *FileStatusDetail) error {
+		if detail != nil {
+			status.Clean = false
+			status.Details = append(status.Details, *detail)
 
-				if detail.Status == FileDeleted {
-					status.DeletedFiles = append(status.DeletedFiles, detail.Path)
-				} else {
-					status.ModifiedFiles = append(status.ModifiedFiles, detail.Path)
-				}
+			if detail.Status == FileDeleted {
+				status.DeletedFiles = append(status.DeletedFiles, detail.Path)
+			} else {
+				status.ModifiedFiles = append(status.ModifiedFiles, detail.Path)
 			}
 		}
+		return nil
+	})
+	if err != nil {
+		return status, err
 	}
 
 	untrackedFiles, err := v.findUntrackedFiles(idx)
@@ -150,7 +155,7 @@ func (v *Validator) CanSafelyOverwrite(paths []scpath.RelativePath, idx *index.I
 		return nil
 	}
 
-	wp := pool.NewWorkerPool[checkTask, *FileStatusDetail]()
+	wp := pool.NewWorkerPool[checkTask, *FileStatusDetail](pool.WithWorkerCount(v.workers))
 
 	results, err := wp.Process(context.Background(), tasks, func(ctx context.Context, task checkTask) (*FileStatusDetail, error) {
 		return v.checkFileStatus(task.entry)
@@ -175,11 +180,16 @@ func (v *Validator) CanSafelyOverwrite(paths []scpath.RelativePath, idx *index.I
 	return nil
 }
 
-// checkFileStatus compares a file on disk with its index entry
+// checkFileStatus compares a file on disk with its index entry. Submodules
+// are never checked out or overwritten, so their changes do not matter.
 func (v *Validator) checkFileStatus(entry *index.Entry) (*FileStatusDetail, error) {
+	if entry.TreeMode().IsGitlink() {
+		return nil, nil
+	}
+
 	fullPath := v.workDir.Join(entry.Path.String())
 
-	stats, err := os.Stat(fullPath.String())
+	stats, err := os.Lstat(fullPath.String())
 	if os.IsNotExist(err) {
 		return NewFSD(entry.Path, FileDeleted, entry.BlobHash, ""), nil
 	}
@@ -226,20 +236,34 @@ func (v *Validator) compareWithIndex(entry *index.Entry, stats os.FileInfo) (*Fi
 func (v *Validator) isContentModified(entry *index.Entry) (bool, objects.ObjectHash, error) {
 	fullPath := v.workDir.Join(entry.Path.String())
 
-	data, err := os.ReadFile(fullPath.String())
+	data, err := worktreeContent(fullPath.String())
 	if err != nil {
 		return true, "", fmt.Errorf("read file: %w", err)
 	}
 
-	b := blob.NewBlob(data)
-	currentHash, err := b.Hash()
-	if err != nil {
-		return true, "", fmt.Errorf("compute hash: %w", err)
-	}
+	// Hash with the index entry's algorithm so SHA-256 repositories compare like with like
+	currentHash := entry.BlobHash.Algorithm().HashObject(objects.BlobType, data)
 
 	return currentHash != entry.BlobHash, currentHash, nil
 }
 
+// worktreeContent returns the blob content of the working tree file at
+// path: what it holds, or for a symbolic link the path it points to.
+func worktreeContent(path string) ([]byte, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		return os.ReadFile(path)
+	}
+	target, err := os.Readlink(path)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(filepath.ToSlash(target)), nil
+}
+
 // findUntrackedFiles scans the working directory for files not in the index
 func (v *Validator) findUntrackedFiles(idx *index.Index) ([]scpath.RelativePath, error) {
 	var untracked []scpath.RelativePath
@@ -260,7 +284,8 @@ func (v *Validator) findUntrackedFiles(idx *index.Index) ([]scpath.RelativePath,
 	return untracked, nil
 }
 
-// walkWorkingDir walks through the working directory, calling the callback for each file
+// walkWorkingDir walks through the working directory, calling the callback for each file.
+// A nested repository is passed as a single path and not entered.
 func (v *Validator) walkWorkingDir(callback func(scpath.RelativePath, os.FileInfo) error) error {
 	gitDir := v.workDir.SourcePath()
 
@@ -273,8 +298,13 @@ func (v *Validator) walkWorkingDir(callback func(scpath.RelativePath, os.FileInf
 			return filepath.SkipDir
 		}
 
+		nested := false
 		if info.IsDir() {
-			return nil
+			exists, err := sourcerepo.RepositoryExists(scpath.RepositoryPath(path))
+			if path == v.workDir.String() || err != nil || !exists {
+				return nil
+			}
+			nested = true
 		}
 
 		relPathStr, err := filepath.Rel(v.workDir.String(), path)
@@ -287,6 +317,9 @@ func (v *Validator) walkWorkingDir(callback func(scpath.RelativePath, os.FileInf
 			return err
 		}
 
-		return callback(relPath, info)
+		if err := callback(relPath, info); err != nil || !nested {
+			return err
+		}
+		return filepath.SkipDir
 	})
 }
@@ -2,6 +2,7 @@ package workdir
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"golang.org/x/sync/errgroup"
@@ -53,12 +54,30 @@ func NewManager(repo *sourcerepo.SourceRepository) *Manager {
 // UpdateToCommit updates the working directory to match a specific commit.
 // It performs safety checks, analyzes changes, executes operations atomically,
 // and updates the index.
+//
+// Unless it is a dry run, index.lock is held from before the index is read
+// until the new index is written, so the files are changed and the index
+// rewritten as one step that no other index writer can interleave with. A
+// held lock, like a failure to write the index, is returned as an error.
 func (m *Manager) UpdateToCommit(ctx context.Context, commitSHA objects.ObjectHash, opts ...Option) (UpdateResult, error) {
 	config := &updateConfig{}
 	for _, opt := range opts {
 		opt(config)
 	}
 
+	var lock *index.Lock
+	if !config.dryRun {
+		var err error
+		if lock, err = index.LockIndex(m.indexPath); err != nil {
+			err = NewLockError(m.indexPath.String()+index.LockSuffix, "", err)
+			return UpdateResult{
+				Success: false,
+				Err:     err,
+			}, err
+		}
+		defer lock.Rollback()
+	}
+
 	if !config.force {
 		if err := m.performSafetyChecks(); err != nil {
 			return UpdateResult{
@@ -88,7 +107,7 @@ func (m *Manager) UpdateToCommit(ctx context.Context, commitSHA objects.ObjectHa
 		return m.performDryRun(analysis.Operations), nil
 	}
 
-	txnResult := m.transaction.ExecuteAtomically(ctx, analysis.Operations)
+	txnResult := m.transaction.ExecuteLocked(ctx, analysis.Operations)
 	if !txnResult.Success {
 		return UpdateResult{
 			Success:      false,
@@ -98,19 +117,21 @@ func (m *Manager) UpdateToCommit(ctx context.Context, commitSHA objects.ObjectHa
 		}, txnResult.Err
 	}
 
-	internalResult, err := m.indexer.UpdateToMatch(analysis.TargetFiles)
-	if err != nil || !internalResult.Success {
-		indexResult := internalResult
+	indexResult, err := m.indexer.UpdateToMatchLocked(lock, analysis.TargetFiles)
+	if err == nil && !indexResult.Success {
+		err = errors.Join(indexResult.Errors...)
+	}
+	if err != nil {
+		err = NewIndexError("write", m.indexPath.String(), err)
 		return UpdateResult{
-			Success:      true,
+			Success:      false,
 			FilesChanged: txnResult.OperationsApplied,
 			Operations:   analysis.Operations,
 			IndexUpdate:  &indexResult,
-			Err:          nil, // Success despite index issue
-		}, nil
+			Err:          err,
+		}, err
 	}
 
-	indexResult := internalResult
 	return UpdateResult{
 		Success:      true,
 		FilesChanged: txnResult.OperationsApplied,
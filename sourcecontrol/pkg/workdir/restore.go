@@ -0,0 +1,282 @@
+package workdir
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/utkarsh5026/SourceControl/pkg/index"
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+	"github.com/utkarsh5026/SourceControl/pkg/workdir/internal"
+)
+
+// RestoreOptions configures Restore.
+type RestoreOptions struct {
+	// Source is the tree that files are restored from (restore --source).
+	// When empty, the working tree is restored from the index; restoring
+	// the index needs a tree, which is HEAD's unless the user names another.
+	Source objects.ObjectHash
+
+	// Staged restores the index entries of the selected paths (--staged)
+	Staged bool
+
+	// Worktree restores the working tree files of the selected paths
+	// (--worktree). It is implied when Staged is not set.
+	Worktree bool
+
+	// Overlay leaves alone tracked files that Source does not have, as
+	// checkout <tree-ish> -- <paths> does; otherwise they are removed
+	Overlay bool
+}
+
+// RestoreResult contains the outcome of Restore.
+type RestoreResult struct {
+	// Paths lists every path the pathspecs selected, sorted
+	Paths []scpath.RelativePath
+
+	// Operations lists the working tree changes that were made
+	Operations []Operation
+
+	// Staged lists the paths whose index entries were changed
+	Staged []scpath.RelativePath
+}
+
+// Restore restores the files that pathspecs select from a source, like git
+// restore and git checkout [<tree-ish>] -- <paths>. The working tree is
+// restored from opts.Source, or from the index, and with opts.Staged the
+// index is restored from opts.Source. Files that are already as in the
+// source are not rewritten.
+//
+// The working tree files are written in one transaction: they are backed
+// up first, and if any of them cannot be written, every file is put back
+// as it was, so that a failed restore leaves nothing half-written. The
+// index is locked before it is read and written back under the same lock,
+// after the files.
+//
+// Parameters:
+//   - ctx: Context for cancellation
+//   - pathspecs: Files, directories or globs, relative to the repository root
+//   - opts: The source, and whether to restore the index, the working tree
+//     or both
+//
+// Returns:
+//   - RestoreResult: The paths selected and what was changed
+//   - error: Wrapping ErrPathspecNoMatch if a pathspec selects nothing in
+//     the source or the index, ErrUnmergedPath if a path restored from the
+//     index is unmerged, or the error of the failed transaction
+func (m *Manager) Restore(ctx context.Context, pathspecs []string, opts RestoreOptions) (RestoreResult, error) {
+	result := RestoreResult{
+		Paths:      []scpath.RelativePath{},
+		Operations: []Operation{},
+		Staged:     []scpath.RelativePath{},
+	}
+	if len(pathspecs) == 0 {
+		return result, fmt.Errorf("%w: you must specify path(s) to restore", ErrInvalidOperation)
+	}
+	if opts.Staged && opts.Source == "" {
+		return result, fmt.Errorf("%w: restoring the index needs a source tree", ErrInvalidOperation)
+	}
+	if !opts.Staged {
+		opts.Worktree = true
+	}
+
+	specs, err := index.ParsePathspecs(pathspecs)
+	if err != nil {
+		return result, err
+	}
+
+	lock, err := index.LockIndex(m.indexPath)
+	if err != nil {
+		return result, NewLockError(m.indexPath.String()+index.LockSuffix, "", err)
+	}
+	defer lock.Rollback()
+
+	idx, err := index.Read(m.indexPath)
+	if err != nil {
+		return result, NewIndexError("read", m.indexPath.String(), err)
+	}
+
+	source, err := m.restoreSource(ctx, idx, opts.Source)
+	if err != nil {
+		return result, err
+	}
+	result.Paths = selectRestorePaths(specs, source, idx, opts.Overlay)
+	if unmatched := specs.Unmatched(pathStrings(result.Paths)); len(unmatched) > 0 {
+		return result, fmt.Errorf("%w: '%s'", ErrPathspecNoMatch, unmatched[0])
+	}
+	if opts.Source == "" {
+		for _, path := range result.Paths {
+			if idx.IsConflicted(path) {
+				return result, fmt.Errorf("%w: '%s'", ErrUnmergedPath, path)
+			}
+		}
+	}
+
+	if opts.Worktree {
+		ops := m.restoreOperations(result.Paths, source)
+		if len(ops) > 0 {
+			txnResult := m.transaction.ExecuteLocked(ctx, ops)
+			if !txnResult.Success {
+				return result, txnResult.Err
+			}
+		}
+		result.Operations = ops
+	}
+
+	if !opts.Staged && len(result.Operations) == 0 {
+		return result, nil
+	}
+	result.Staged = m.restoreIndex(idx, result.Paths, source, opts)
+	if err := lock.Commit(idx); err != nil {
+		return result, NewIndexError("write", m.indexPath.String(), err)
+	}
+	return result, nil
+}
+
+// restoreSource returns the files to restore from: those of the tree
+// source, or the stage 0 entries of idx when it is empty. Entries only
+// intended to be added have no content to restore.
+func (m *Manager) restoreSource(ctx context.Context, idx *index.Index, source objects.ObjectHash) (internal.FileMap, error) {
+	if source != "" {
+		files, err := m.treeAnalyzer.GetTreeFiles(ctx, source)
+		if err != nil {
+			return nil, fmt.Errorf("read source tree: %w", err)
+		}
+		return files, nil
+	}
+
+	files := make(internal.FileMap)
+	for _, entry := range idx.Entries {
+		if entry.Stage == 0 && !entry.IntentToAdd {
+			files[entry.Path] = FileInfo{SHA: entry.BlobHash, Mode: entry.TreeMode()}
+		}
+	}
+	return files, nil
+}
+
+// selectRestorePaths returns the paths in source, and unless overlay is set
+// the paths in idx, that specs select, sorted.
+func selectRestorePaths(specs index.Pathspecs, source internal.FileMap, idx *index.Index, overlay bool) []scpath.RelativePath {
+	selected := make(map[scpath.RelativePath]bool)
+	for path := range source {
+		if specs.Match(path.String()) {
+			selected[path] = true
+		}
+	}
+	if !overlay {
+		for _, entry := range idx.Entries {
+			if !entry.IntentToAdd && specs.Match(entry.Path.String()) {
+				selected[entry.Path] = true
+			}
+		}
+	}
+
+	paths := make([]scpath.RelativePath, 0, len(selected))
+	for path := range selected {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool { return paths[i] < paths[j] })
+	return paths
+}
+
+// restoreOperations returns the operations that make the working tree
+// files at paths match source: files that differ are written, and those
+// source does not have are deleted. Submodules are not touched. Modes are
+// compared as core.fileMode and core.symlinks allow.
+func (m *Manager) restoreOperations(paths []scpath.RelativePath, source internal.FileMap) []Operation {
+	algorithm := m.repo.ObjectStore().HashAlgorithm()
+	modes := index.LoadModeSettings(m.repo.WorkingDirectory())
+	ops := make([]Operation, 0)
+	for _, path := range paths {
+		absPath := filepath.Join(m.workDir, path.String())
+		info, err := os.Lstat(absPath)
+		exists := err == nil && !info.IsDir()
+
+		target, ok := source[path]
+		switch {
+		case ok && target.Mode.IsGitlink():
+			// a submodule's directory is left as it is
+		case !ok:
+			if exists {
+				ops = append(ops, Operation{Path: path, Action: ActionDelete})
+			}
+		case !exists:
+			ops = append(ops, Operation{Path: path, Action: ActionCreate, SHA: target.SHA, Mode: target.Mode})
+		case modes.WorktreeMode(info, &index.Entry{Mode: target.Mode}) != target.Mode:
+			ops = append(ops, Operation{Path: path, Action: ActionModify, SHA: target.SHA, Mode: target.Mode})
+		default:
+			// A file that cannot be read is rewritten
+			hash, err := index.HashWorktree(scpath.AbsolutePath(absPath), info, algorithm)
+			if err != nil || hash != target.SHA {
+				ops = append(ops, Operation{Path: path, Action: ActionModify, SHA: target.SHA, Mode: target.Mode})
+			}
+		}
+	}
+	return ops
+}
+
+// restoreIndex updates the entries of idx for a restore of paths from
+// source, and returns the paths whose entries were staged or removed. With
+// opts.Staged each entry is set to the source's, or removed if the source
+// does not have it; otherwise only the stat data of entries whose files
+// were just written is refreshed.
+func (m *Manager) restoreIndex(idx *index.Index, paths []scpath.RelativePath, source internal.FileMap, opts RestoreOptions) []scpath.RelativePath {
+	staged := make([]scpath.RelativePath, 0)
+	for _, path := range paths {
+		target, ok := source[path]
+		existing, inIndex := idx.Get(path)
+		unchanged := inIndex && ok && existing.BlobHash == target.SHA && existing.TreeMode() == target.Mode
+
+		switch {
+		case !opts.Staged:
+			if unchanged {
+				m.refreshEntry(idx, path, target)
+			}
+		case !ok:
+			if !opts.Overlay && (inIndex || idx.IsConflicted(path)) {
+				idx.Remove(path)
+				idx.RemoveConflict(path)
+				staged = append(staged, path)
+			}
+		case unchanged && !idx.IsConflicted(path):
+			if opts.Worktree {
+				m.refreshEntry(idx, path, target)
+			}
+		default:
+			entry := index.NewEntry(path)
+			entry.Mode, entry.BlobHash = target.Mode, target.SHA
+			idx.RemoveConflict(path)
+			idx.Add(entry)
+			if opts.Worktree {
+				m.refreshEntry(idx, path, target)
+			}
+			staged = append(staged, path)
+		}
+	}
+	return staged
+}
+
+// refreshEntry records the stat data of the working tree file at path in
+// its entry, which has target's content, so that the file is not read
+// again to tell that it is unchanged.
+func (m *Manager) refreshEntry(idx *index.Index, path scpath.RelativePath, target FileInfo) {
+	info, err := os.Lstat(filepath.Join(m.workDir, path.String()))
+	if err != nil || info.IsDir() {
+		return
+	}
+	if entry, err := index.NewEntryFromFileInfo(path, info, target.SHA); err == nil {
+		entry.Mode = target.Mode
+		idx.Add(entry)
+	}
+}
+
+func pathStrings(paths []scpath.RelativePath) []string {
+	strs := make([]string, len(paths))
+	for i, path := range paths {
+		strs[i] = path.String()
+	}
+	return strs
+}
@@ -0,0 +1,256 @@
+package workdir
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/utkarsh5026/SourceControl/pkg/index"
+	"github.com/utkarsh5026/SourceControl/pkg/objects"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/blob"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/commit"
+	"github.com/utkarsh5026/SourceControl/pkg/objects/tree"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/scpath"
+	"github.com/utkarsh5026/SourceControl/pkg/repository/sourcerepo"
+)
+
+// setupRestoreTest stages a.txt, dir/b.txt and dir/c.txt in a new
+// repository, and writes an older tree with other content for a.txt and
+// dir/b.txt and no dir/c.txt.
+func setupRestoreTest(t *testing.T) (*Manager, *sourcerepo.SourceRepository, objects.ObjectHash) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", t.TempDir())
+
+	root := t.TempDir()
+	repo := sourcerepo.NewSourceRepository()
+	if err := repo.Initialize(scpath.RepositoryPath(root)); err != nil {
+		t.Fatalf("Failed to initialize repository: %v", err)
+	}
+	writeFiles(t, root, map[string]string{"a.txt": "a v1", "dir/b.txt": "b v1", "dir/c.txt": "c v1"})
+	if _, err := index.NewManager(repo.WorkingDirectory()).Add([]string{"a.txt", "dir"}, repo.ObjectStore()); err != nil {
+		t.Fatal(err)
+	}
+
+	old := writeTree(t, repo, map[string]objects.ObjectHash{
+		"a.txt":     writeBlob(t, repo, "a v0"),
+		"dir/b.txt": writeBlob(t, repo, "b v0"),
+	})
+	return NewManager(repo), repo, old
+}
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		full := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func writeBlob(t *testing.T, repo *sourcerepo.SourceRepository, content string) objects.ObjectHash {
+	t.Helper()
+	hash, err := repo.WriteObject(blob.NewBlob([]byte(content)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
+
+// writeTree writes a tree of regular files with a single level of
+// directories, and returns its hash.
+func writeTree(t *testing.T, repo *sourcerepo.SourceRepository, files map[string]objects.ObjectHash) objects.ObjectHash {
+	t.Helper()
+	dirs := make(map[string][]*tree.TreeEntry)
+	var entries []*tree.TreeEntry
+	for name, hash := range files {
+		dir, base := filepath.Split(name)
+		entry, err := tree.NewTreeEntry(objects.FileModeRegular, scpath.RelativePath(base), hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dir == "" {
+			entries = append(entries, entry)
+		} else {
+			dirs[filepath.Clean(dir)] = append(dirs[filepath.Clean(dir)], entry)
+		}
+	}
+	for dir, dirEntries := range dirs {
+		hash, err := repo.WriteObject(tree.NewTree(dirEntries))
+		if err != nil {
+			t.Fatal(err)
+		}
+		entry, err := tree.NewTreeEntry(objects.FileModeDirectory, scpath.RelativePath(dir), hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+	hash, err := repo.WriteObject(tree.NewTree(entries))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
+
+// assertFiles checks the content of working tree files; an empty string
+// means the file must not exist.
+func assertFiles(t *testing.T, repo *sourcerepo.SourceRepository, want map[string]string) {
+	t.Helper()
+	for name, content := range want {
+		data, err := os.ReadFile(filepath.Join(repo.WorkingDirectory().String(), filepath.FromSlash(name)))
+		switch {
+		case content == "" && !os.IsNotExist(err):
+			t.Errorf("%s exists, want it removed", name)
+		case content != "" && string(data) != content:
+			t.Errorf("%s = %q, want %q", name, data, content)
+		}
+	}
+}
+
+// stagedHash returns the blob staged for path, or "" if it is not staged.
+func stagedHash(t *testing.T, m *Manager, path string) objects.ObjectHash {
+	t.Helper()
+	idx, err := index.Read(m.indexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry, ok := idx.Get(scpath.RelativePath(path)); ok {
+		return entry.BlobHash
+	}
+	return ""
+}
+
+func TestManager_RestoreFromIndex(t *testing.T) {
+	m, repo, _ := setupRestoreTest(t)
+	root := repo.WorkingDirectory().String()
+	writeFiles(t, root, map[string]string{"a.txt": "a edited", "dir/new.txt": "untracked"})
+	if err := os.Remove(filepath.Join(root, "dir", "b.txt")); err != nil {
+		t.Fatal(err)
+	}
+	staged := stagedHash(t, m, "a.txt")
+
+	result, err := m.Restore(context.Background(), []string{"a.txt", "dir"}, RestoreOptions{})
+	if err != nil {
+		t.Fatalf("Restore() failed: %v", err)
+	}
+	assertFiles(t, repo, map[string]string{"a.txt": "a v1", "dir/b.txt": "b v1", "dir/c.txt": "c v1", "dir/new.txt": "untracked"})
+	if len(result.Paths) != 3 || len(result.Operations) != 2 || len(result.Staged) != 0 {
+		t.Errorf("Restore() = %d paths, %d operations, %d staged; want 3, 2, 0",
+			len(result.Paths), len(result.Operations), len(result.Staged))
+	}
+	if got := stagedHash(t, m, "a.txt"); got != staged {
+		t.Errorf("a.txt staged %s, want the index left at %s", got, staged)
+	}
+
+	_, err = m.Restore(context.Background(), []string{"a.txt", "missing"}, RestoreOptions{})
+	if !errors.Is(err, ErrPathspecNoMatch) {
+		t.Errorf("Restore() of an unknown path = %v, want ErrPathspecNoMatch", err)
+	}
+}
+
+func TestManager_RestoreFromSource(t *testing.T) {
+	m, repo, old := setupRestoreTest(t)
+	indexed := stagedHash(t, m, "dir/b.txt")
+
+	// The working tree only, removing files the source does not have
+	if _, err := m.Restore(context.Background(), []string{"dir"}, RestoreOptions{Source: old}); err != nil {
+		t.Fatalf("Restore() failed: %v", err)
+	}
+	assertFiles(t, repo, map[string]string{"a.txt": "a v1", "dir/b.txt": "b v0", "dir/c.txt": ""})
+	if got := stagedHash(t, m, "dir/b.txt"); got != indexed {
+		t.Errorf("dir/b.txt staged %s, want the index left at %s", got, indexed)
+	}
+
+	// The index only
+	result, err := m.Restore(context.Background(), []string{"a.txt", "dir/c.txt"}, RestoreOptions{Source: old, Staged: true})
+	if err != nil {
+		t.Fatalf("Restore() failed: %v", err)
+	}
+	if len(result.Staged) != 2 || len(result.Operations) != 0 {
+		t.Errorf("Restore() staged %v with %d operations, want a.txt and dir/c.txt only", result.Staged, len(result.Operations))
+	}
+	if got, want := stagedHash(t, m, "a.txt"), writeBlob(t, repo, "a v0"); got != want {
+		t.Errorf("a.txt staged %s, want %s", got, want)
+	}
+	if got := stagedHash(t, m, "dir/c.txt"); got != "" {
+		t.Errorf("dir/c.txt staged %s, want it removed from the index", got)
+	}
+	assertFiles(t, repo, map[string]string{"a.txt": "a v1"})
+}
+
+func TestManager_RestoreOverlay(t *testing.T) {
+	m, repo, old := setupRestoreTest(t)
+
+	// checkout <tree-ish> -- dir keeps dir/c.txt, which the source lacks
+	result, err := m.Restore(context.Background(), []string{"dir"}, RestoreOptions{Source: old, Staged: true, Worktree: true, Overlay: true})
+	if err != nil {
+		t.Fatalf("Restore() failed: %v", err)
+	}
+	if len(result.Paths) != 1 {
+		t.Errorf("Paths = %v, want dir/b.txt only", result.Paths)
+	}
+	assertFiles(t, repo, map[string]string{"dir/b.txt": "b v0", "dir/c.txt": "c v1"})
+	if got, want := stagedHash(t, m, "dir/b.txt"), writeBlob(t, repo, "b v0"); got != want {
+		t.Errorf("dir/b.txt staged %s, want %s", got, want)
+	}
+	if stagedHash(t, m, "dir/c.txt") == "" {
+		t.Error("dir/c.txt was removed from the index")
+	}
+}
+
+func TestManager_RestoreRollsBack(t *testing.T) {
+	m, repo, _ := setupRestoreTest(t)
+
+	// dir/b.txt names a blob that is not in the object store
+	missing := repo.ObjectStore().HashAlgorithm().HashObject(objects.BlobType, []byte("never written"))
+	source := writeTree(t, repo, map[string]objects.ObjectHash{
+		"a.txt":     writeBlob(t, repo, "a v0"),
+		"dir/b.txt": missing,
+	})
+
+	if _, err := m.Restore(context.Background(), []string{"."}, RestoreOptions{Source: source}); err == nil {
+		t.Fatal("Restore() should fail when a blob is missing")
+	}
+	assertFiles(t, repo, map[string]string{"a.txt": "a v1", "dir/b.txt": "b v1", "dir/c.txt": "c v1"})
+}
+
+func TestManager_IndexLocked(t *testing.T) {
+	m, repo, old := setupRestoreTest(t)
+
+	lock, err := index.LockIndex(m.indexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lock.Rollback()
+
+	if _, err := m.Restore(context.Background(), []string{"a.txt"}, RestoreOptions{Source: old}); !errors.Is(err, index.ErrIndexLocked) {
+		t.Errorf("Restore() with index.lock held = %v, want ErrIndexLocked", err)
+	}
+
+	person, err := commit.NewCommitPerson("Test User", "test@example.com", time.Unix(1700000000, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := commit.NewCommitBuilder().TreeHash(old).Author(person).Committer(person).Message("old").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitHash, err := repo.WriteObject(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := m.UpdateToCommit(context.Background(), commitHash, WithForce())
+	if !errors.Is(err, index.ErrIndexLocked) || result.Success {
+		t.Errorf("UpdateToCommit() with index.lock held = %v, %v; want ErrIndexLocked", result.Success, err)
+	}
+
+	assertFiles(t, repo, map[string]string{"a.txt": "a v1", "dir/b.txt": "b v1", "dir/c.txt": "c v1"})
+}
@@ -140,6 +140,8 @@ type Validator interface {
 type TransactionManager interface {
 	// ExecuteAtomically executes all operations as a single atomic transaction
 	ExecuteAtomically(ctx context.Context, ops []Operation) TransactionResult
+	// ExecuteLocked is ExecuteAtomically under an index lock the caller holds
+	ExecuteLocked(ctx context.Context, ops []Operation) TransactionResult
 	// DryRun analyzes operations without executing them
 	DryRun(ops []Operation) DryRunResult
 }
@@ -148,6 +150,8 @@ type TransactionManager interface {
 type IndexUpdater interface {
 	// UpdateToMatch replaces the entire index to match the target files
 	UpdateToMatch(targetFiles map[scpath.RelativePath]FileInfo) (IndexUpdateResult, error)
+	// UpdateToMatchLocked is UpdateToMatch under an index lock the caller holds
+	UpdateToMatchLocked(lock *index.Lock, targetFiles map[scpath.RelativePath]FileInfo) (IndexUpdateResult, error)
 	// UpdateIncremental applies specific additions and removals to the index
 	UpdateIncremental(toAdd map[scpath.RelativePath]FileInfo, toRemove []scpath.RelativePath) (IndexUpdateResult, error)
 }